@@ -113,6 +113,10 @@ func initializeLocalApp() (*LocalApp, error) {
 		dynamoClient,
 		fmt.Sprintf("%s-favorites-%s", cfg.DynamoDBTablePrefix, cfg.APIStage),
 	)
+	selectionRepo := dynamodbRepo.NewSelectionRepository(
+		dynamoClient,
+		fmt.Sprintf("%s-selections-%s", cfg.DynamoDBTablePrefix, cfg.APIStage),
+	)
 	sessionRepo := dynamodbRepo.NewClientSessionRepository(
 		dynamoClient,
 		fmt.Sprintf("%s-sessions-%s", cfg.DynamoDBTablePrefix, cfg.APIStage),
@@ -127,8 +131,8 @@ func initializeLocalApp() (*LocalApp, error) {
 		time.Duration(cfg.SignedURLExpiration)*time.Hour,
 	)
 
-	galleryService := gallery.NewService(galleryRepo, photoRepo, storageService)
-	photoService := photo.NewService(photoRepo, galleryRepo, favoriteRepo, storageService)
+	galleryService := gallery.NewService(galleryRepo, photoRepo, storageService, nil)
+	photoService := photo.NewService(photoRepo, galleryRepo, favoriteRepo, selectionRepo, storageService)
 	authService := cognitoAuth.NewService(
 		cfg.CognitoUserPoolID,
 		cfg.CognitoClientID,
@@ -143,7 +147,7 @@ func initializeLocalApp() (*LocalApp, error) {
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(authService, cfg.JWTSecret)
-	sessionMiddleware := middleware.NewSessionMiddleware(clientAuthService)
+	sessionMiddleware := middleware.NewSessionMiddleware(clientAuthService, galleryService)
 
 	return &LocalApp{
 		galleryHandler:    galleryHandler,
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
 	"photographer-gallery/backend/internal/api"
 	"photographer-gallery/backend/internal/api/handlers"
@@ -23,10 +25,19 @@ import (
 	appConfig "photographer-gallery/backend/internal/config"
 	"photographer-gallery/backend/internal/domain/auth"
 	"photographer-gallery/backend/internal/domain/customdomain"
+	"photographer-gallery/backend/internal/domain/download"
 	"photographer-gallery/backend/internal/domain/gallery"
 	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/profile"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/domain/trash"
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/internal/domain/webhook"
 	dynamodbRepo "photographer-gallery/backend/internal/repository/dynamodb"
 	cognitoAuth "photographer-gallery/backend/internal/services/auth"
+	"photographer-gallery/backend/internal/services/processing"
 	"photographer-gallery/backend/internal/services/storage"
 	"photographer-gallery/backend/pkg/logger"
 )
@@ -59,12 +70,13 @@ func initializeApp() (*App, error) {
 	// Initialize infrastructure
 	dynamoClient := dynamodb.NewFromConfig(awsCfg)
 	s3Client := s3.NewFromConfig(awsCfg)
+	sqsClient := sqs.NewFromConfig(awsCfg)
 
 	// Initialize repositories
 	repos := initRepositories(dynamoClient, cfg)
 
 	// Initialize services
-	services := initServices(s3Client, repos, cfg)
+	services := initServices(s3Client, sqsClient, repos, cfg)
 
 	// Build router with routes and middleware
 	router := buildRouter(services, repos, cfg)
@@ -81,31 +93,53 @@ type repositories struct {
 	gallery      *dynamodbRepo.GalleryRepository
 	photo        *dynamodbRepo.PhotoRepository
 	favorite     *dynamodbRepo.FavoriteRepository
+	selection    *dynamodbRepo.SelectionRepository
 	session      *dynamodbRepo.ClientSessionRepository
 	photographer *dynamodbRepo.PhotographerRepository
+	outbox       *dynamodbRepo.OutboxRepository
+	webhook      *dynamodbRepo.WebhookRepository
+	delivery     *dynamodbRepo.WebhookDeliveryRepository
+	download     *dynamodbRepo.DownloadJobRepository
+	trash        *dynamodbRepo.TrashRepository
+	profile      *dynamodbRepo.ProcessingProfileRepository
 }
 
 func initRepositories(client *dynamodb.Client, cfg *appConfig.Config) *repositories {
 	prefix := cfg.DynamoDBTablePrefix
 	stage := cfg.APIStage
+	// Entity writes that emit domain events persist them to the outbox in the same transaction
+	outbox := dynamodbRepo.NewOutboxRepository(client, fmt.Sprintf("%s-outbox-%s", prefix, stage))
 	return &repositories{
-		gallery:      dynamodbRepo.NewGalleryRepository(client, fmt.Sprintf("%s-galleries-%s", prefix, stage)),
-		photo:        dynamodbRepo.NewPhotoRepository(client, fmt.Sprintf("%s-photos-%s", prefix, stage)),
-		favorite:     dynamodbRepo.NewFavoriteRepository(client, fmt.Sprintf("%s-favorites-%s", prefix, stage)),
+		gallery:      dynamodbRepo.NewGalleryRepository(client, fmt.Sprintf("%s-galleries-%s", prefix, stage)).WithOutbox(outbox),
+		photo:        dynamodbRepo.NewPhotoRepository(client, fmt.Sprintf("%s-photos-%s", prefix, stage)).WithOutbox(outbox),
+		favorite:     dynamodbRepo.NewFavoriteRepository(client, fmt.Sprintf("%s-favorites-%s", prefix, stage)).WithOutbox(outbox),
+		selection:    dynamodbRepo.NewSelectionRepository(client, fmt.Sprintf("%s-selections-%s", prefix, stage)).WithOutbox(outbox),
 		session:      dynamodbRepo.NewClientSessionRepository(client, fmt.Sprintf("%s-sessions-%s", prefix, stage)),
 		photographer: dynamodbRepo.NewPhotographerRepository(client, fmt.Sprintf("%s-photographers-%s", prefix, stage)),
+		outbox:       outbox,
+		webhook:      dynamodbRepo.NewWebhookRepository(client, fmt.Sprintf("%s-webhooks-%s", prefix, stage)),
+		delivery:     dynamodbRepo.NewWebhookDeliveryRepository(client, fmt.Sprintf("%s-webhook-deliveries-%s", prefix, stage)),
+		download:     dynamodbRepo.NewDownloadJobRepository(client, fmt.Sprintf("%s-download-jobs-%s", prefix, stage)),
+		trash:        dynamodbRepo.NewTrashRepository(client, fmt.Sprintf("%s-trash-%s", prefix, stage)),
+		profile:      dynamodbRepo.NewProcessingProfileRepository(client, fmt.Sprintf("%s-processing-profiles-%s", prefix, stage)),
 	}
 }
 
 type services struct {
-	gallery *gallery.Service
-	photo   *photo.Service
-	session *auth.SessionService
-	auth    *cognitoAuth.Service
-	domain  *customdomain.Service
+	gallery   *gallery.Service
+	photo     *photo.Service
+	session   *auth.SessionService
+	auth      *cognitoAuth.Service
+	domain    *customdomain.Service
+	webhook   *webhook.Service
+	download  *download.Service
+	trash     *trash.Service
+	watermark *watermark.Service
+	reprocess *reprocess.Service
+	profile   *profile.Service
 }
 
-func initServices(s3Client *s3.Client, repos *repositories, cfg *appConfig.Config) *services {
+func initServices(s3Client *s3.Client, sqsClient *sqs.Client, repos *repositories, cfg *appConfig.Config) *services {
 	storageService := storage.NewService(
 		s3Client,
 		cfg.S3BucketOriginal,
@@ -126,12 +160,36 @@ func initServices(s3Client *s3.Client, repos *repositories, cfg *appConfig.Confi
 		baseDomain = "photographergallery.com"
 	}
 
+	// Deleted galleries and photos go to the trash until the scheduler purges them
+	trashRetention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+	storageQuota := quota.NewService(repos.photographer, repos.gallery, repos.photo)
+	plans := plan.NewService(repos.photographer)
+	processingQueue := processing.NewQueue(sqsClient, cfg.ProcessingQueueURL, cfg.S3BucketOriginal)
+	reprocessService := reprocess.NewService(repos.gallery, repos.photo, processingQueue)
+	galleryService := gallery.NewService(
+		repos.gallery, repos.photo, storageService, processingQueue,
+	).WithTrash(repos.trash, trashRetention).WithQuota(storageQuota).WithPlans(plans).WithReprocessing(reprocessService).WithProfiles(repos.profile)
+	photoService := photo.NewService(repos.photo, repos.gallery, repos.favorite, repos.selection, storageService).
+		WithTrash(repos.trash, trashRetention).
+		WithQuota(storageQuota).
+		WithReprocessing(reprocessService)
+
 	return &services{
-		gallery: gallery.NewService(repos.gallery, repos.photo, storageService),
-		photo:   photo.NewService(repos.photo, repos.gallery, repos.favorite, storageService),
+		gallery: galleryService,
+		photo:   photoService,
 		session: auth.NewSessionService(repos.session, jwtSecret, cfg.SessionTTLHours),
 		auth:    cognitoAuth.NewService(cfg.CognitoUserPoolID, cfg.CognitoRegion),
-		domain:  customdomain.NewService(repos.photographer, baseDomain),
+		domain:  customdomain.NewService(repos.photographer, baseDomain).WithPlans(plans),
+		webhook: webhook.NewService(repos.webhook, repos.delivery, repos.gallery, webhook.NewHTTPSender(nil)),
+		download: download.NewService(
+			repos.download, repos.gallery, repos.photo, repos.favorite, storageService,
+			download.NewSQSQueue(sqsClient, cfg.DownloadQueueURL),
+			download.Buckets{Original: cfg.S3BucketOriginal, Optimized: cfg.S3BucketOptimized, Archive: cfg.S3BucketDownloads},
+		),
+		trash:     trash.NewService(repos.trash, galleryService, photoService),
+		watermark: watermark.NewService(repos.photographer, storageService, reprocessService).WithPlans(plans),
+		reprocess: reprocessService,
+		profile:   profile.NewService(repos.profile, repos.gallery, reprocessService),
 	}
 }
 
@@ -146,13 +204,18 @@ func buildRouter(svc *services, repos *repositories, cfg *appConfig.Config) *api
 	authHandler := handlers.NewAuthHandler(repos.photographer)
 	galleryHandler := handlers.NewGalleryHandler(svc.gallery)
 	photoHandler := handlers.NewPhotoHandler(svc.photo)
-	clientHandler := handlers.NewClientHandler(svc.gallery, svc.photo, svc.session)
+	clientHandler := handlers.NewClientHandler(svc.gallery, svc.photo, svc.session, svc.download)
 	domainHandler := handlers.NewDomainHandler(svc.domain)
 	portalHandler := handlers.NewPortalHandler(svc.domain, svc.gallery, repos.photographer)
+	webhookHandler := handlers.NewWebhookHandler(svc.webhook)
+	trashHandler := handlers.NewTrashHandler(svc.trash)
+	watermarkHandler := handlers.NewWatermarkHandler(svc.watermark)
+	profileHandler := handlers.NewProfileHandler(svc.profile)
+	reprocessHandler := handlers.NewReprocessHandler(svc.reprocess)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(svc.auth)
-	sessionMiddleware := middleware.NewSessionMiddleware(svc.session)
+	sessionMiddleware := middleware.NewSessionMiddleware(svc.session, svc.gallery)
 	domainMiddleware := middleware.NewDomainMiddleware(svc.domain, baseDomain)
 
 	router := api.NewRouter()
@@ -173,10 +236,40 @@ func buildRouter(svc *services, repos *repositories, cfg *appConfig.Config) *api
 	photographerRoutes.PUT("/api/v1/galleries/{id}", wrapHandler(galleryHandler.UpdateGallery))
 	photographerRoutes.DELETE("/api/v1/galleries/{id}", wrapHandler(galleryHandler.DeleteGallery))
 	photographerRoutes.POST("/api/v1/galleries/{id}/expire", wrapHandler(galleryHandler.SetExpiration))
+	photographerRoutes.POST("/api/v1/galleries/{id}/restore", wrapHandler(galleryHandler.RestoreGallery))
 	photographerRoutes.POST("/api/v1/galleries/{id}/photos/upload-url", wrapHandler(photoHandler.GetUploadURL))
 	photographerRoutes.GET("/api/v1/galleries/{id}/photos", wrapHandler(photoHandler.ListPhotos))
 	photographerRoutes.DELETE("/api/v1/galleries/{galleryId}/photos/{photoId}", wrapHandler(photoHandler.DeletePhoto))
+	photographerRoutes.PUT("/api/v1/galleries/{galleryId}/photos/{photoId}/focal-point", wrapHandler(photoHandler.SetFocalPoint))
+	photographerRoutes.DELETE("/api/v1/galleries/{galleryId}/photos/{photoId}/focal-point", wrapHandler(photoHandler.ClearFocalPoint))
 	photographerRoutes.GET("/api/v1/galleries/{id}/favorites", wrapHandler(photoHandler.GetFavorites))
+	photographerRoutes.POST("/api/v1/galleries/{id}/selections/{sessionId}/reopen", wrapHandler(photoHandler.ReopenSelection))
+
+	// Trash routes (authenticated)
+	photographerRoutes.GET("/api/v1/trash", wrapHandler(trashHandler.ListTrash))
+	photographerRoutes.POST("/api/v1/trash/{id}/restore", wrapHandler(trashHandler.RestoreTrashItem))
+
+	// Watermark logo routes (authenticated)
+	photographerRoutes.POST("/api/v1/watermark/logo/upload-url", wrapHandler(watermarkHandler.GetLogoUploadURL))
+	photographerRoutes.PUT("/api/v1/watermark/logo", wrapHandler(watermarkHandler.SetLogo))
+	photographerRoutes.DELETE("/api/v1/watermark/logo", wrapHandler(watermarkHandler.DeleteLogo))
+
+	// Processing profile routes (authenticated)
+	photographerRoutes.POST("/api/v1/processing-profiles", wrapHandler(profileHandler.CreateProfile))
+	photographerRoutes.GET("/api/v1/processing-profiles", wrapHandler(profileHandler.ListProfiles))
+	photographerRoutes.GET("/api/v1/processing-profiles/{id}", wrapHandler(profileHandler.GetProfile))
+	photographerRoutes.PUT("/api/v1/processing-profiles/{id}", wrapHandler(profileHandler.UpdateProfile))
+	photographerRoutes.DELETE("/api/v1/processing-profiles/{id}", wrapHandler(profileHandler.DeleteProfile))
+
+	// Reprocessing routes (authenticated)
+	photographerRoutes.POST("/api/v1/galleries/{galleryId}/photos/{photoId}/reprocess", wrapHandler(reprocessHandler.ReprocessPhoto))
+	photographerRoutes.POST("/api/v1/galleries/{id}/reprocess", wrapHandler(reprocessHandler.ReprocessGallery))
+	photographerRoutes.GET("/api/v1/galleries/{id}/reprocess", wrapHandler(reprocessHandler.GetGalleryProgress))
+	photographerRoutes.POST("/api/v1/reprocess", wrapHandler(reprocessHandler.ReprocessAccount))
+
+	// Failed photo triage routes (authenticated)
+	photographerRoutes.GET("/api/v1/photos/failed", wrapHandler(reprocessHandler.ListFailedPhotos))
+	photographerRoutes.POST("/api/v1/galleries/{galleryId}/photos/{photoId}/retry", wrapHandler(reprocessHandler.RetryFailedPhoto))
 
 	// Domain management routes (authenticated)
 	photographerRoutes.GET("/api/v1/domain", wrapHandler(domainHandler.GetDomainConfig))
@@ -185,6 +278,17 @@ func buildRouter(svc *services, repos *repositories, cfg *appConfig.Config) *api
 	photographerRoutes.POST("/api/v1/domain/verify", wrapHandler(domainHandler.VerifyDomain))
 	photographerRoutes.DELETE("/api/v1/domain", wrapHandler(domainHandler.RemoveDomain))
 
+	// Webhook routes (authenticated)
+	photographerRoutes.POST("/api/v1/webhooks", wrapHandler(webhookHandler.CreateWebhook))
+	photographerRoutes.GET("/api/v1/webhooks", wrapHandler(webhookHandler.ListWebhooks))
+	photographerRoutes.GET("/api/v1/webhooks/{id}", wrapHandler(webhookHandler.GetWebhook))
+	photographerRoutes.PUT("/api/v1/webhooks/{id}", wrapHandler(webhookHandler.UpdateWebhook))
+	photographerRoutes.DELETE("/api/v1/webhooks/{id}", wrapHandler(webhookHandler.DeleteWebhook))
+	photographerRoutes.POST("/api/v1/webhooks/{id}/rotate-secret", wrapHandler(webhookHandler.RotateSecret))
+	photographerRoutes.GET("/api/v1/webhooks/{id}/deliveries", wrapHandler(webhookHandler.ListDeliveries))
+	photographerRoutes.GET("/api/v1/webhooks/{id}/deliveries/{deliveryId}", wrapHandler(webhookHandler.GetDelivery))
+	photographerRoutes.POST("/api/v1/webhooks/{id}/deliveries/{deliveryId}/replay", wrapHandler(webhookHandler.ReplayDelivery))
+
 	// Portal routes (accessed via custom domain, uses domain middleware)
 	portalRoutes := api.NewRouter()
 	portalRoutes.Use(domainMiddlewareWrapper(domainMiddleware))
@@ -202,6 +306,15 @@ func buildRouter(svc *services, repos *repositories, cfg *appConfig.Config) *api
 	clientRoutes.GET("/api/v1/client/photos/{photoId}/download-url", wrapHandler(clientHandler.GetDownloadURL))
 	clientRoutes.POST("/api/v1/client/photos/{photoId}/favorite", wrapHandler(clientHandler.ToggleFavorite))
 	clientRoutes.GET("/api/v1/client/session/favorites", wrapHandler(clientHandler.GetSessionFavorites))
+	clientRoutes.POST("/api/v1/client/session/selection/submit", wrapHandler(clientHandler.SubmitSelection))
+	clientRoutes.GET("/api/v1/client/session/lists", wrapHandler(clientHandler.ListFavoriteLists))
+	clientRoutes.POST("/api/v1/client/session/lists", wrapHandler(clientHandler.CreateFavoriteList))
+	clientRoutes.PUT("/api/v1/client/session/lists/{listId}", wrapHandler(clientHandler.RenameFavoriteList))
+	clientRoutes.DELETE("/api/v1/client/session/lists/{listId}", wrapHandler(clientHandler.DeleteFavoriteList))
+	clientRoutes.GET("/api/v1/client/session/lists/{listId}/favorites", wrapHandler(clientHandler.GetListFavorites))
+	clientRoutes.POST("/api/v1/client/session/lists/{listId}/photos/{photoId}/favorite", wrapHandler(clientHandler.ToggleFavorite))
+	clientRoutes.POST("/api/v1/client/downloads", wrapHandler(clientHandler.StartDownload))
+	clientRoutes.GET("/api/v1/client/downloads/{jobId}", wrapHandler(clientHandler.GetDownload))
 
 	// Mount sub-routers via notFound handler (middleware only applies to matched routes)
 	router.SetNotFound(func(req *api.Request) (*api.Response, error) {
@@ -254,6 +367,11 @@ func wrapHandler(fn http.HandlerFunc) api.Handler {
 		for k, v := range req.Headers {
 			httpReq.Header.Set(k, v)
 		}
+		query := url.Values{}
+		for k, v := range req.QueryParams {
+			query.Set(k, v)
+		}
+		httpReq.URL.RawQuery = query.Encode()
 		// Store path params in context
 		ctx := httpReq.Context()
 		for k, v := range req.PathParams {
@@ -18,6 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
+	photoDomain "photographer-gallery/backend/internal/domain/photo"
 	"photographer-gallery/backend/internal/repository"
 	dynamodbRepo "photographer-gallery/backend/internal/repository/dynamodb"
 )
@@ -272,14 +273,18 @@ func (app *App) markAsPermantlyFailed(ctx context.Context, messageBody string, m
 		now := time.Now()
 		photo.ProcessedAt = &now
 
-		// Store failure metadata
-		if photo.Metadata == nil {
-			photo.Metadata = make(map[string]string)
+		// Record the failure for the photographer, keeping the category of the last attempt
+		// the processor recorded
+		category, message := photoDomain.FailureInternal, fmt.Sprintf("Gave up after %d retries", metadata.AttemptNumber)
+		if photo.Failure != nil {
+			category = photo.Failure.Category
 		}
-		photo.Metadata["failureReason"] = metadata.ErrorMessage
-		photo.Metadata["failureAttempts"] = strconv.Itoa(metadata.AttemptNumber)
-		photo.Metadata["firstFailedAt"] = metadata.FirstFailedAt.Format(time.RFC3339)
-		photo.Metadata["lastRetryAt"] = metadata.LastRetryAt.Format(time.RFC3339)
+		if metadata.ErrorMessage != "" {
+			message += ": " + metadata.ErrorMessage
+		} else if photo.Failure != nil {
+			message += ": " + photo.Failure.Message
+		}
+		photoDomain.RecordFailure(photo, category, message, now)
 
 		// Save updated photo
 		if err := app.photoRepo.Update(ctx, photo); err != nil {
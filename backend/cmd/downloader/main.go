@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"photographer-gallery/backend/internal/domain/download"
+	dynamodbRepo "photographer-gallery/backend/internal/repository/dynamodb"
+	"photographer-gallery/backend/internal/services/storage"
+)
+
+// DownloaderApp builds client ZIP archives from the download queue
+type DownloaderApp struct {
+	downloadService *download.Service
+}
+
+func main() {
+	app, err := initializeApp()
+	if err != nil {
+		log.Fatalf("Failed to initialize app: %v", err)
+	}
+
+	lambda.Start(app.handleSQSEvent)
+}
+
+func initializeApp() (*DownloaderApp, error) {
+	ctx := context.Background()
+
+	// Load AWS config
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	// Initialize AWS clients
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+	s3Client := s3.NewFromConfig(cfg)
+	sqsClient := sqs.NewFromConfig(cfg)
+
+	// Get environment variables
+	tablePrefix := os.Getenv("DYNAMODB_TABLE_PREFIX")
+	stage := os.Getenv("STAGE")
+	originalBucket := os.Getenv("S3_BUCKET_ORIGINAL")
+	optimizedBucket := os.Getenv("S3_BUCKET_OPTIMIZED")
+	thumbnailBucket := os.Getenv("S3_BUCKET_THUMBNAIL")
+	downloadsBucket := os.Getenv("S3_BUCKET_DOWNLOADS")
+	queueURL := os.Getenv("DOWNLOAD_QUEUE_URL")
+
+	if tablePrefix == "" {
+		tablePrefix = "photographer-gallery"
+	}
+	if stage == "" {
+		stage = "dev"
+	}
+	if downloadsBucket == "" || queueURL == "" {
+		return nil, fmt.Errorf("S3_BUCKET_DOWNLOADS and DOWNLOAD_QUEUE_URL are required")
+	}
+
+	// Initialize repositories
+	jobRepo := dynamodbRepo.NewDownloadJobRepository(dynamoClient, fmt.Sprintf("%s-download-jobs-%s", tablePrefix, stage))
+	galleryRepo := dynamodbRepo.NewGalleryRepository(dynamoClient, fmt.Sprintf("%s-galleries-%s", tablePrefix, stage))
+	photoRepo := dynamodbRepo.NewPhotoRepository(dynamoClient, fmt.Sprintf("%s-photos-%s", tablePrefix, stage))
+	favoriteRepo := dynamodbRepo.NewFavoriteRepository(dynamoClient, fmt.Sprintf("%s-favorites-%s", tablePrefix, stage))
+
+	// Initialize storage service
+	storageService := storage.NewService(s3Client, originalBucket, optimizedBucket, thumbnailBucket, 15*time.Minute)
+
+	downloadService := download.NewService(
+		jobRepo, galleryRepo, photoRepo, favoriteRepo, storageService,
+		download.NewSQSQueue(sqsClient, queueURL),
+		download.Buckets{Original: originalBucket, Optimized: optimizedBucket, Archive: downloadsBucket},
+	)
+
+	return &DownloaderApp{downloadService: downloadService}, nil
+}
+
+// handleSQSEvent runs each queued job. Long jobs checkpoint and queue themselves again
+// before the invocation times out.
+func (app *DownloaderApp) handleSQSEvent(ctx context.Context, sqsEvent events.SQSEvent) error {
+	for _, record := range sqsEvent.Records {
+		var msg download.JobMessage
+		if err := json.Unmarshal([]byte(record.Body), &msg); err != nil || msg.JobID == "" {
+			log.Printf("ERROR: Invalid download job message %s: %s", record.MessageId, record.Body)
+			continue
+		}
+
+		log.Printf("Running download job %s", msg.JobID)
+		if err := app.downloadService.Run(ctx, msg.JobID); err != nil {
+			log.Printf("ERROR: Download job %s failed: %v", msg.JobID, err)
+			return fmt.Errorf("download job %s failed: %w", msg.JobID, err)
+		}
+	}
+
+	return nil
+}
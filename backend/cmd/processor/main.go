@@ -1,27 +1,30 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	imageType "image"
-	"io"
 	"log"
-	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/disintegration/imaging"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
+	"photographer-gallery/backend/internal/adapters"
 	appconfig "photographer-gallery/backend/internal/config"
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/internal/handlers"
 	"photographer-gallery/backend/internal/repository"
 	dynamodbRepo "photographer-gallery/backend/internal/repository/dynamodb"
 	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/internal/services/processing"
 	"photographer-gallery/backend/pkg/utils/s3key"
 )
 
@@ -33,11 +36,8 @@ type S3API interface {
 
 // App holds application dependencies.
 type App struct {
-	cfg         *appconfig.ProcessorConfig
-	s3Client    S3API
-	photoRepo   repository.PhotoRepository
-	galleryRepo repository.GalleryRepository
-	processor   *image.Processor
+	pipeline    *handlers.ProcessingPipeline
+	reprocessor *reprocess.Service // queues the photos of galleries being reprocessed, nil without a queue URL
 }
 
 func main() {
@@ -59,239 +59,127 @@ func initApp() (*App, error) {
 		return nil, err
 	}
 
-	return &App{
-		cfg:         cfg,
-		s3Client:    s3.NewFromConfig(awsCfg),
-		photoRepo:   dynamodbRepo.NewPhotoRepository(dynamodb.NewFromConfig(awsCfg), cfg.PhotosTableName()),
-		galleryRepo: dynamodbRepo.NewGalleryRepository(dynamodb.NewFromConfig(awsCfg), cfg.GalleriesTableName()),
-		processor:   image.NewProcessor(),
-	}, nil
-}
+	dynamoClient := dynamodb.NewFromConfig(awsCfg)
+	outbox := dynamodbRepo.NewOutboxRepository(dynamoClient, cfg.OutboxTableName())
 
-// handleS3Event processes S3 events when a photo is uploaded.
-func (app *App) handleS3Event(ctx context.Context, sqsEvent events.SQSEvent) error {
-	log.Printf("Processing %d SQS records", len(sqsEvent.Records))
+	photoRepo := dynamodbRepo.NewPhotoRepository(dynamoClient, cfg.PhotosTableName()).WithOutbox(outbox)
+	galleryRepo := dynamodbRepo.NewGalleryRepository(dynamoClient, cfg.GalleriesTableName())
+	photographerRepo := dynamodbRepo.NewPhotographerRepository(dynamoClient, cfg.PhotographersTableName())
 
-	for _, sqsRecord := range sqsEvent.Records {
-		var s3Event events.S3Event
-		if err := json.Unmarshal([]byte(sqsRecord.Body), &s3Event); err != nil {
-			log.Printf("Failed to unmarshal S3 event: %v", err)
-			continue
-		}
-
-		for _, record := range s3Event.Records {
-			bucket, key := record.S3.Bucket.Name, record.S3.Object.Key
-			log.Printf("Processing: %s/%s", bucket, key)
-
-			parsed, err := s3key.Parse(key)
-			if err != nil {
-				log.Printf("Invalid key %s: %v", key, err)
-				continue
-			}
-
-			if err := app.processPhoto(ctx, parsed, bucket, key); err != nil {
-				log.Printf("Failed to process %s: %v", parsed.PhotoID, err)
-				app.updatePhotoStatus(ctx, parsed.PhotoID, "failed")
-				continue
-			}
-			log.Printf("Processed photo %s", parsed.PhotoID)
-		}
-	}
-	return nil
-}
-
-// processPhoto downloads, processes, and stores the photo.
-func (app *App) processPhoto(ctx context.Context, key *s3key.Key, bucket, objectKey string) error {
-	// Download from S3
-	imageData, contentLength, err := app.downloadImage(ctx, bucket, objectKey)
+	app, err := newApp(cfg, s3.NewFromConfig(awsCfg), photoRepo, galleryRepo, photographerRepo)
 	if err != nil {
-		return err
-	}
-
-	// Get dimensions and metadata
-	width, height, _ := app.processor.GetImageDimensions(bytes.NewReader(imageData))
-	metadata, _ := app.processor.ExtractEXIF(bytes.NewReader(imageData))
-	if metadata == nil {
-		metadata = &image.ImageMetadata{}
-	}
-	if metadata.Width == 0 {
-		metadata.Width = width
-	}
-	if metadata.Height == 0 {
-		metadata.Height = height
-	}
-
-	// Fetch gallery for watermark settings
-	gallery, _ := app.galleryRepo.GetByID(ctx, key.GalleryID)
-
-	// Generate and upload thumbnail
-	thumbnailData, err := app.processor.GenerateThumbnail(bytes.NewReader(imageData))
-	if err != nil {
-		return fmt.Errorf("thumbnail generation failed: %w", err)
-	}
-	thumbnailKey := s3key.ChangeExtension(objectKey, ".jpg")
-	if err := app.uploadToS3(ctx, app.cfg.S3BucketThumbnail, thumbnailKey, thumbnailData, "image/jpeg"); err != nil {
-		return fmt.Errorf("thumbnail upload failed: %w", err)
-	}
-
-	// Generate and upload optimized version
-	optimizedData, err := app.generateOptimized(bytes.NewReader(imageData), gallery)
-	if err != nil {
-		return fmt.Errorf("optimization failed: %w", err)
+		return nil, err
 	}
-	optimizedKey := s3key.ChangeExtension(objectKey, ".jpg")
-	if err := app.uploadToS3(ctx, app.cfg.S3BucketOptimized, optimizedKey, optimizedData, "image/jpeg"); err != nil {
-		return fmt.Errorf("optimized upload failed: %w", err)
+	app.pipeline.WithProfiles(dynamodbRepo.NewProcessingProfileRepository(dynamoClient, cfg.ProcessingProfilesTableName()))
+	if cfg.ProcessingQueueURL != "" {
+		queue := processing.NewQueue(sqs.NewFromConfig(awsCfg), cfg.ProcessingQueueURL, cfg.S3BucketOriginal)
+		app.reprocessor = reprocess.NewService(galleryRepo, photoRepo, queue)
 	}
-
-	// Update database
-	return app.updatePhotoRecord(ctx, key, objectKey, thumbnailKey, optimizedKey, metadata, contentLength)
+	return app, nil
 }
 
-func (app *App) downloadImage(ctx context.Context, bucket, key string) ([]byte, int64, error) {
-	output, err := app.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
-	if err != nil {
-		return nil, 0, fmt.Errorf("S3 download failed: %w", err)
-	}
-	defer output.Body.Close()
-
-	data, err := io.ReadAll(output.Body)
+// newApp builds the processing pipeline the configuration describes.
+func newApp(cfg *appconfig.ProcessorConfig, s3Client S3API, photoRepo repository.PhotoRepository, galleryRepo repository.GalleryRepository, accounts quota.AccountStore) (*App, error) {
+	encoders, unavailable, err := image.RenditionEncoders(cfg.Formats, 85)
 	if err != nil {
-		return nil, 0, fmt.Errorf("read failed: %w", err)
+		return nil, err
 	}
-
-	var size int64
-	if output.ContentLength != nil {
-		size = *output.ContentLength
+	if len(unavailable) > 0 {
+		log.Printf("Skipping rendition formats without an encoder in this build: %v", unavailable)
+	}
+
+	storage := adapters.NewS3Adapter(s3Client)
+	pipeline := handlers.NewProcessingPipeline(
+		storage,
+		storage,
+		image.NewProcessor().WithMaxPixels(cfg.MaxPixels),
+		photoRepo,
+		galleryRepo,
+		cfg.S3BucketThumbnail,
+		cfg.S3BucketOptimized,
+		cfg.Renditions,
+		encoders,
+	).
+		WithLogos(watermark.NewLogoLoader(accounts, storage, cfg.S3BucketOriginal)).
+		WithPlans(plan.NewService(accounts)).
+		WithQuota(quota.NewService(accounts, galleryRepo, photoRepo))
+	if len(cfg.Stages) > 0 {
+		if pipeline, err = pipeline.WithStages(cfg.Stages...); err != nil {
+			return nil, err
+		}
 	}
-	return data, size, nil
+	return &App{pipeline: pipeline}, nil
 }
 
-func (app *App) updatePhotoRecord(ctx context.Context, key *s3key.Key, objectKey, thumbnailKey, optimizedKey string, metadata *image.ImageMetadata, size int64) error {
-	photo, _ := app.photoRepo.GetByID(ctx, key.PhotoID)
-	isNew := photo == nil
+// handleS3Event processes S3 events when a photo is uploaded. Messages with a photo that
+// failed for a reason that may pass, such as throttling, are reported as batch item failures
+// so SQS delivers them again and, once its retries are used up, moves them to the DLQ.
+func (app *App) handleS3Event(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	log.Printf("Processing %d SQS records", len(sqsEvent.Records))
 
-	if isNew {
-		now := time.Now()
-		photo = &repository.Photo{
-			PhotoID:          key.PhotoID,
-			GalleryID:        key.GalleryID,
-			FileName:         key.FileName,
-			OriginalKey:      objectKey,
-			MimeType:         s3key.GetMimeType(key.Extension),
-			Size:             size,
-			Width:            metadata.Width,
-			Height:           metadata.Height,
-			UploadedAt:       now,
-			ProcessingStatus: "processing",
-			Metadata:         make(map[string]string),
-		}
-		if err := app.photoRepo.Create(ctx, photo); err != nil {
-			return fmt.Errorf("create photo failed: %w", err)
+	var response events.SQSEventResponse
+	for _, sqsRecord := range sqsEvent.Records {
+		if err := app.handleMessage(ctx, sqsRecord); err != nil {
+			log.Printf("Returning message %s to the queue: %v", sqsRecord.MessageId, err)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: sqsRecord.MessageId,
+			})
 		}
 	}
-
-	// Update with processing results
-	photo.OptimizedKey = optimizedKey
-	photo.ThumbnailKey = thumbnailKey
-	photo.Width = metadata.Width
-	photo.Height = metadata.Height
-	photo.ProcessingStatus = "completed"
-	now := time.Now()
-	photo.ProcessedAt = &now
-	app.storeEXIFMetadata(photo, metadata)
-
-	if err := app.photoRepo.Update(ctx, photo); err != nil {
-		return fmt.Errorf("update photo failed: %w", err)
-	}
-
-	if isNew {
-		app.galleryRepo.UpdatePhotoCount(ctx, key.GalleryID, 1)
-		app.galleryRepo.UpdateTotalSize(ctx, key.GalleryID, size)
-	}
-	return nil
+	return response, nil
 }
 
-func (app *App) storeEXIFMetadata(photo *repository.Photo, m *image.ImageMetadata) {
-	if photo.Metadata == nil {
-		photo.Metadata = make(map[string]string)
-	}
-	if m.CameraModel != "" {
-		photo.Metadata["cameraModel"] = m.CameraModel
-	}
-	if m.DateTaken != "" {
-		photo.Metadata["dateTaken"] = m.DateTaken
-	}
-	if m.ISO > 0 {
-		photo.Metadata["iso"] = fmt.Sprintf("%d", m.ISO)
-	}
-	if m.Aperture != "" {
-		photo.Metadata["aperture"] = m.Aperture
-	}
-	if m.ShutterSpeed != "" {
-		photo.Metadata["shutterSpeed"] = m.ShutterSpeed
-	}
-	if m.FocalLength != "" {
-		photo.Metadata["focalLength"] = m.FocalLength
+// handleMessage processes the photos of one SQS message. Photos that can never be processed
+// are marked failed; the error returned is the last transient failure, if any, meaning the
+// message should be retried.
+func (app *App) handleMessage(ctx context.Context, sqsRecord events.SQSMessage) error {
+	if job, ok := processing.ParseGalleryJob(sqsRecord.Body); ok {
+		return app.handleGalleryJob(ctx, job)
 	}
-	if m.GPS != nil {
-		gpsData, _ := json.Marshal(m.GPS)
-		photo.Metadata["gps"] = string(gpsData)
-	}
-}
-
-func (app *App) uploadToS3(ctx context.Context, bucket, key string, data []byte, contentType string) error {
-	_, err := app.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
-	})
-	return err
-}
 
-func (app *App) updatePhotoStatus(ctx context.Context, photoID, status string) {
-	photo, err := app.photoRepo.GetByID(ctx, photoID)
-	if err != nil || photo == nil {
-		return
+	var s3Event events.S3Event
+	if err := json.Unmarshal([]byte(sqsRecord.Body), &s3Event); err != nil {
+		log.Printf("Failed to unmarshal S3 event: %v", err)
+		return nil
 	}
-	photo.ProcessingStatus = status
-	if status == "completed" || status == "failed" {
-		now := time.Now()
-		photo.ProcessedAt = &now
-	}
-	app.photoRepo.Update(ctx, photo)
-}
 
-func (app *App) generateOptimized(imageData io.Reader, gallery *repository.Gallery) ([]byte, error) {
-	img, _, err := imageType.Decode(imageData)
-	if err != nil {
-		return nil, fmt.Errorf("decode failed: %w", err)
-	}
+	var retryErr error
+	for _, record := range s3Event.Records {
+		bucket, key := record.S3.Bucket.Name, record.S3.Object.Key
+		log.Printf("Processing: %s/%s", bucket, key)
 
-	bounds := img.Bounds()
-	var result imageType.Image = img
-	if bounds.Dx() > image.OptimizedMaxWidth || bounds.Dy() > image.OptimizedMaxHeight {
-		result = imaging.Fit(img, image.OptimizedMaxWidth, image.OptimizedMaxHeight, imaging.Lanczos)
-	}
+		parsed, err := s3key.Parse(key)
+		if err != nil {
+			log.Printf("Invalid key %s: %v", key, err)
+			continue
+		}
 
-	if gallery != nil && gallery.EnableWatermark && gallery.WatermarkText != "" {
-		position := gallery.WatermarkPosition
-		if position == "" {
-			position = "bottom-right"
+		err = app.pipeline.Process(ctx, parsed, bucket, key, adapters.ObjectVersion(record.S3.Object.VersionID, record.S3.Object.ETag))
+		switch {
+		case err == nil:
+			log.Printf("Processed photo %s", parsed.PhotoID)
+		case photo.IsTransient(err):
+			// Left processing; the retry finishes it
+			log.Printf("Failed to process %s, will retry: %v", parsed.PhotoID, err)
+			retryErr = err
+		default:
+			log.Printf("Failed to process %s: %v", parsed.PhotoID, err)
 		}
-		result = app.processor.ApplyWatermark(result, image.WatermarkOptions{
-			Text:     gallery.WatermarkText,
-			Position: position,
-		})
 	}
+	return retryErr
+}
 
-	var buf bytes.Buffer
-	if err := imaging.Encode(&buf, result, imaging.JPEG); err != nil {
-		return nil, fmt.Errorf("encode failed: %w", err)
+// handleGalleryJob queues one page of a gallery's photos for reprocessing. An error returns the
+// job to the queue; photos it already queued are skipped when it is retried.
+func (app *App) handleGalleryJob(ctx context.Context, job *processing.GalleryJob) error {
+	if app.reprocessor == nil {
+		log.Printf("Dropping reprocess job for gallery %s: PROCESSING_QUEUE_URL is not set", job.GalleryID)
+		return nil
+	}
+	queued, err := app.reprocessor.QueueGalleryPage(ctx, job.GalleryID, job.Cursor)
+	if err != nil {
+		return fmt.Errorf("failed to reprocess gallery %s: %w", job.GalleryID, err)
 	}
-	return buf.Bytes(), nil
+	log.Printf("Queued %d photos of gallery %s for reprocessing", queued, job.GalleryID)
+	return nil
 }
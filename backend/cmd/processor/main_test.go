@@ -4,19 +4,35 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	stdimage "image"
 	"image/color"
 	"image/jpeg"
 	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
 
 	appconfig "photographer-gallery/backend/internal/config"
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/handlers"
 	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/internal/services/processing"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
 	"photographer-gallery/backend/pkg/utils/s3key"
 )
 
@@ -78,6 +94,9 @@ func (m *mockPhotoRepository) IncrementFavoriteCount(ctx context.Context, photoI
 func (m *mockPhotoRepository) IncrementDownloadCount(ctx context.Context, photoID string) error {
 	return nil
 }
+func (m *mockPhotoRepository) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	return nil, nil, nil
+}
 
 // Mock Gallery Repository
 type mockGalleryRepository struct{}
@@ -103,6 +122,9 @@ func (m *mockGalleryRepository) UpdateTotalSize(ctx context.Context, id string,
 func (m *mockGalleryRepository) ListExpired(ctx context.Context, limit int) ([]*repository.Gallery, error) {
 	return nil, nil
 }
+func (m *mockGalleryRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*repository.Gallery, error) {
+	return nil, nil
+}
 func (m *mockGalleryRepository) IncrementClientAccessCount(ctx context.Context, id string) error {
 	return nil
 }
@@ -117,6 +139,29 @@ func createTestImage(width, height int) stdimage.Image {
 	return img
 }
 
+// newTestApp builds the processor around the given S3 client and repositories. Photographers
+// are read from accounts, or an empty store if it's nil.
+func newTestApp(t *testing.T, cfg *appconfig.ProcessorConfig, s3Client S3API, photos repository.PhotoRepository, galleries repository.GalleryRepository, accounts quota.AccountStore) *App {
+	t.Helper()
+	if accounts == nil {
+		accounts = mocks.NewMockPhotographerStore()
+	}
+	app, err := newApp(cfg, s3Client, photos, galleries, accounts)
+	if err != nil {
+		t.Fatalf("newApp() error = %v", err)
+	}
+	return app
+}
+
+// processPhoto runs the original at objectKey through the app's pipeline
+func processPhoto(app *App, objectKey string) error {
+	key, err := s3key.Parse(objectKey)
+	if err != nil {
+		return err
+	}
+	return app.pipeline.Process(context.Background(), key, "test-original", objectKey, "")
+}
+
 // Tests for s3key package functions (moved from main)
 func TestS3KeyParse(t *testing.T) {
 	tests := []struct {
@@ -169,35 +214,36 @@ func TestS3KeyChangeExtension(t *testing.T) {
 	}
 }
 
-func TestUploadToS3(t *testing.T) {
+func TestProcessPhotoUploadFailure(t *testing.T) {
+	var imgBuf bytes.Buffer
+	jpeg.Encode(&imgBuf, createTestImage(800, 600), nil)
+
 	tests := []struct {
 		name      string
-		bucket    string
-		key       string
-		data      []byte
 		mockError error
 		wantErr   bool
 	}{
-		{"successful upload", "test-bucket", "test-key", []byte("data"), nil, false},
-		{"upload error", "test-bucket", "test-key", []byte("data"), fmt.Errorf("S3 error"), true},
+		{"successful upload", nil, false},
+		{"upload error", fmt.Errorf("S3 error"), true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockS3 := &mockS3Client{
+				getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+					return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(imgBuf.Bytes()))}, nil
+				},
 				putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
 					return &s3.PutObjectOutput{}, tt.mockError
 				},
 			}
 
-			app := &App{
-				cfg:      &appconfig.ProcessorConfig{S3BucketOriginal: "test"},
-				s3Client: mockS3,
-			}
+			app := newTestApp(t, &appconfig.ProcessorConfig{S3BucketOptimized: "test-optimized", S3BucketThumbnail: "test-thumbnail"},
+				mockS3, &mockPhotoRepository{}, &mockGalleryRepository{}, nil)
 
-			err := app.uploadToS3(context.Background(), tt.bucket, tt.key, tt.data, "image/jpeg")
+			err := processPhoto(app, "gal_abc123/photo_xyz789/original.jpg")
 			if (err != nil) != tt.wantErr {
-				t.Errorf("uploadToS3() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("processPhoto() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -239,20 +285,13 @@ func TestProcessPhoto(t *testing.T) {
 				updateFunc: func(ctx context.Context, photo *repository.Photo) error { return nil },
 			}
 
-			app := &App{
-				cfg: &appconfig.ProcessorConfig{
-					S3BucketOriginal:  "test-original",
-					S3BucketOptimized: "test-optimized",
-					S3BucketThumbnail: "test-thumbnail",
-				},
-				s3Client:    mockS3,
-				photoRepo:   mockPhoto,
-				galleryRepo: &mockGalleryRepository{},
-				processor:   image.NewProcessor(),
-			}
+			app := newTestApp(t, &appconfig.ProcessorConfig{
+				S3BucketOriginal:  "test-original",
+				S3BucketOptimized: "test-optimized",
+				S3BucketThumbnail: "test-thumbnail",
+			}, mockS3, mockPhoto, &mockGalleryRepository{}, nil)
 
-			parsed, _ := s3key.Parse(tt.objectKey)
-			err := app.processPhoto(context.Background(), parsed, "test-bucket", tt.objectKey)
+			err := processPhoto(app, tt.objectKey)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("processPhoto() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -260,6 +299,83 @@ func TestProcessPhoto(t *testing.T) {
 	}
 }
 
+func TestProcessPhotoHonorsOrientation(t *testing.T) {
+	// A portrait shot stored landscape by a camera held on its side
+	original := fixtures.JPEGWithOrientation(createTestImage(1200, 800), 6)
+
+	uploads := make(map[string][]byte)
+	var stored *repository.Photo
+	app := newTestApp(t, &appconfig.ProcessorConfig{
+		S3BucketOptimized: "test-optimized",
+		S3BucketThumbnail: "test-thumbnail",
+		Renditions:        image.DefaultRenditions,
+	},
+		&mockS3Client{
+			getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+				return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(original))}, nil
+			},
+			putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				data, _ := io.ReadAll(params.Body)
+				uploads[*params.Bucket+"/"+*params.Key] = data
+				return &s3.PutObjectOutput{}, nil
+			},
+		},
+		&mockPhotoRepository{
+			getByIDFunc: func(ctx context.Context, id string) (*repository.Photo, error) {
+				return &repository.Photo{PhotoID: id, Metadata: make(map[string]string)}, nil
+			},
+			updateFunc: func(ctx context.Context, photo *repository.Photo) error {
+				stored = photo
+				return nil
+			},
+		},
+		&mockGalleryRepository{}, nil)
+
+	objectKey := "gal_abc123/photo_xyz789/original.jpg"
+	if err := processPhoto(app, objectKey); err != nil {
+		t.Fatalf("processPhoto() error = %v", err)
+	}
+
+	if stored == nil || stored.Width != 800 || stored.Height != 1200 {
+		t.Fatalf("stored photo = %+v, want 800x1200", stored)
+	}
+	optimized, err := jpeg.DecodeConfig(bytes.NewReader(uploads["test-optimized/"+s3key.ChangeExtension(objectKey, ".jpg")]))
+	if err != nil || optimized.Width >= optimized.Height {
+		t.Errorf("optimized = %dx%d, %v; want portrait", optimized.Width, optimized.Height, err)
+	}
+	for _, r := range stored.Renditions {
+		if r.Width >= r.Height {
+			t.Errorf("rendition %s = %dx%d, want portrait", r.Name, r.Width, r.Height)
+		}
+	}
+}
+
+func TestProcessPhotoRejectsOversizedImages(t *testing.T) {
+	var imgBuf bytes.Buffer
+	jpeg.Encode(&imgBuf, createTestImage(1920, 1080), nil)
+
+	uploads := 0
+	app := newTestApp(t, &appconfig.ProcessorConfig{S3BucketOptimized: "test-optimized", S3BucketThumbnail: "test-thumbnail", MaxPixels: 2_000_000},
+		&mockS3Client{
+			getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+				return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(imgBuf.Bytes()))}, nil
+			},
+			putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				uploads++
+				return &s3.PutObjectOutput{}, nil
+			},
+		},
+		&mockPhotoRepository{}, &mockGalleryRepository{}, nil)
+
+	err := processPhoto(app, "gal_abc123/photo_xyz789/original.jpg")
+	if !errors.Is(err, image.ErrTooManyPixels) {
+		t.Errorf("processPhoto() error = %v, want ErrTooManyPixels", err)
+	}
+	if uploads != 0 {
+		t.Errorf("%d derivatives uploaded for a rejected photo", uploads)
+	}
+}
+
 func TestHandleS3Event(t *testing.T) {
 	testImg := createTestImage(800, 600)
 	var imgBuf bytes.Buffer
@@ -282,17 +398,11 @@ func TestHandleS3Event(t *testing.T) {
 		updateFunc: func(ctx context.Context, photo *repository.Photo) error { return nil },
 	}
 
-	app := &App{
-		cfg: &appconfig.ProcessorConfig{
-			S3BucketOriginal:  "test-original",
-			S3BucketOptimized: "test-optimized",
-			S3BucketThumbnail: "test-thumbnail",
-		},
-		s3Client:    mockS3,
-		photoRepo:   mockPhoto,
-		galleryRepo: &mockGalleryRepository{},
-		processor:   image.NewProcessor(),
-	}
+	app := newTestApp(t, &appconfig.ProcessorConfig{
+		S3BucketOriginal:  "test-original",
+		S3BucketOptimized: "test-optimized",
+		S3BucketThumbnail: "test-thumbnail",
+	}, mockS3, mockPhoto, &mockGalleryRepository{}, nil)
 
 	// Create S3 event wrapped in SQS event
 	s3Event := events.S3Event{
@@ -309,35 +419,568 @@ func TestHandleS3Event(t *testing.T) {
 
 	sqsEvent := events.SQSEvent{
 		Records: []events.SQSMessage{
-			{Body: string(s3EventJSON)},
+			{MessageId: "msg-1", Body: string(s3EventJSON)},
 		},
 	}
 
-	err := app.handleS3Event(context.Background(), sqsEvent)
+	response, err := app.handleS3Event(context.Background(), sqsEvent)
 	if err != nil {
 		t.Errorf("handleS3Event() error = %v", err)
 	}
+	if len(response.BatchItemFailures) != 0 {
+		t.Errorf("BatchItemFailures = %v, want none", response.BatchItemFailures)
+	}
 }
 
-func TestUpdatePhotoStatus(t *testing.T) {
-	mockPhoto := &mockPhotoRepository{
-		getByIDFunc: func(ctx context.Context, id string) (*repository.Photo, error) {
-			return &repository.Photo{PhotoID: id, ProcessingStatus: "pending"}, nil
+func TestHandleS3EventReportsTransientFailures(t *testing.T) {
+	s3EventJSON, _ := json.Marshal(events.S3Event{
+		Records: []events.S3EventRecord{{
+			S3: events.S3Entity{
+				Bucket: events.S3Bucket{Name: "test-original"},
+				Object: events.S3Object{Key: "gal_abc123/photo_xyz789/original.jpg"},
+			},
+		}},
+	})
+
+	tests := []struct {
+		name        string
+		body        string
+		getObject   func() (*s3.GetObjectOutput, error)
+		wantRetry   bool
+		wantStatus  string
+		wantFailure string // category of the failure recorded, if any
+	}{
+		{
+			name: "S3 throttling is retried",
+			body: string(s3EventJSON),
+			getObject: func() (*s3.GetObjectOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "SlowDown", Message: "Please reduce your request rate."}
+			},
+			wantRetry:   true,
+			wantStatus:  "processing",
+			wantFailure: photo.FailureInternal,
+		},
+		{
+			name: "corrupt original fails",
+			body: string(s3EventJSON),
+			getObject: func() (*s3.GetObjectOutput, error) {
+				return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte("not an image")))}, nil
+			},
+			wantStatus:  "failed",
+			wantFailure: photo.FailureUnsupportedFormat,
+		},
+		{
+			name: "deleted original fails",
+			body: string(s3EventJSON),
+			getObject: func() (*s3.GetObjectOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "NoSuchKey"}
+			},
+			wantStatus:  "failed",
+			wantFailure: photo.FailureInternal,
+		},
+		{
+			name:       "malformed message is dropped",
+			body:       "{not json",
+			getObject:  func() (*s3.GetObjectOutput, error) { return nil, nil },
+			wantStatus: "pending",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &repository.Photo{PhotoID: "photo_xyz789", ProcessingStatus: "pending"}
+			app := newTestApp(t, &appconfig.ProcessorConfig{S3BucketOptimized: "test-optimized", S3BucketThumbnail: "test-thumbnail"},
+				&mockS3Client{
+					getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+						return tt.getObject()
+					},
+				},
+				&mockPhotoRepository{
+					getByIDFunc: func(ctx context.Context, id string) (*repository.Photo, error) { return stored, nil },
+					updateFunc:  func(ctx context.Context, photo *repository.Photo) error { return nil },
+				},
+				&mockGalleryRepository{}, nil)
+
+			response, err := app.handleS3Event(context.Background(), events.SQSEvent{
+				Records: []events.SQSMessage{{MessageId: "msg-1", Body: tt.body}},
+			})
+			if err != nil {
+				t.Fatalf("handleS3Event() error = %v", err)
+			}
+			var want []events.SQSBatchItemFailure
+			if tt.wantRetry {
+				want = []events.SQSBatchItemFailure{{ItemIdentifier: "msg-1"}}
+			}
+			if !reflect.DeepEqual(response.BatchItemFailures, want) {
+				t.Errorf("BatchItemFailures = %v, want %v", response.BatchItemFailures, want)
+			}
+			if stored.ProcessingStatus != tt.wantStatus {
+				t.Errorf("ProcessingStatus = %q, want %q", stored.ProcessingStatus, tt.wantStatus)
+			}
+			switch {
+			case tt.wantFailure == "" && stored.Failure != nil:
+				t.Errorf("Failure = %+v, want none", stored.Failure)
+			case tt.wantFailure != "" && (stored.Failure == nil || stored.Failure.Category != tt.wantFailure || len(stored.Failure.Attempts) != 1):
+				t.Errorf("Failure = %+v, want one %s attempt", stored.Failure, tt.wantFailure)
+			}
+		})
+	}
+}
+
+func TestProcessPhotoStorageDelta(t *testing.T) {
+	var imgBuf bytes.Buffer
+	jpeg.Encode(&imgBuf, createTestImage(800, 600), nil)
+	original := imgBuf.Bytes()
+
+	tests := []struct {
+		name     string
+		existing *repository.Photo
+	}{
+		{"new photo counts original and derivatives", nil},
+		{"first processing counts derivatives", &repository.Photo{PhotoID: "photo_xyz789", Size: 5000}},
+		{"reprocessing counts the difference", &repository.Photo{PhotoID: "photo_xyz789", Size: 5000, ThumbnailSize: 80, OptimizedSize: 1000}},
+		{"retrying a failed photo", &repository.Photo{PhotoID: "photo_xyz789", Size: 5000, ProcessingStatus: "failed", Failure: &repository.ProcessingFailure{Category: photo.FailureInternal}}},
+		{"reprocessing replaces renditions", &repository.Photo{PhotoID: "photo_xyz789", Size: 5000, ThumbnailSize: 100, OptimizedSize: 900, Renditions: []repository.Rendition{{Name: "sm", Size: 300}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photos := newVersionedPhotoRepository()
+			if tt.existing != nil {
+				photos.Create(context.Background(), tt.existing)
+			}
+			accounts := mocks.NewMockPhotographerStore()
+			accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1"})
+			app := newTestApp(t, &appconfig.ProcessorConfig{S3BucketOptimized: "test-optimized", S3BucketThumbnail: "test-thumbnail"},
+				&mockS3Client{
+					getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+						return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(original))}, nil
+					},
+					putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+						return &s3.PutObjectOutput{}, nil
+					},
+				},
+				photos, &countingGalleryRepository{}, accounts)
+
+			if err := processPhoto(app, "gal_abc123/photo_xyz789/original.jpg"); err != nil {
+				t.Fatalf("processPhoto() error = %v", err)
+			}
+
+			updated, _ := photos.GetByID(context.Background(), "photo_xyz789")
+			want := quota.PhotoBytes(updated)
+			if tt.existing != nil {
+				want -= quota.PhotoBytes(tt.existing)
+			}
+			if used := accounts.StorageUsed("user_1"); used != want {
+				t.Errorf("storage used = %d, want %d", used, want)
+			}
+			if updated.ThumbnailSize == 0 || updated.OptimizedSize == 0 || len(updated.Renditions) != 0 {
+				t.Errorf("derivatives = %d, %d, %+v; want the new thumbnail and optimized sizes", updated.ThumbnailSize, updated.OptimizedSize, updated.Renditions)
+			}
+			if updated.BlurHash == "" || updated.DominantColor == "" {
+				t.Errorf("placeholder = %q, %q; want the generated placeholder", updated.BlurHash, updated.DominantColor)
+			}
+			if updated.ProcessingStatus != "completed" || updated.Failure != nil {
+				t.Errorf("status = %s, failure = %+v; want completed with the failure cleared", updated.ProcessingStatus, updated.Failure)
+			}
+		})
+	}
+}
+
+func TestOptimizedPlanRenditionSize(t *testing.T) {
+	var imgBuf bytes.Buffer
+	jpeg.Encode(&imgBuf, createTestImage(4000, 2000), nil)
+
+	tests := []struct {
+		plan      string
+		wantWidth int
+	}{
+		{plan.Free, 1920},
+		{plan.Pro, 3840},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plan, func(t *testing.T) {
+			accounts := mocks.NewMockPhotographerStore()
+			accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", Plan: tt.plan})
+			var optimized []byte
+			app := newTestApp(t, &appconfig.ProcessorConfig{
+				S3BucketOptimized: "test-optimized",
+				S3BucketThumbnail: "test-thumbnail",
+				Stages:            []string{handlers.StageOptimized},
+			},
+				&mockS3Client{
+					getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+						return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(imgBuf.Bytes()))}, nil
+					},
+					putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+						optimized, _ = io.ReadAll(params.Body)
+						return &s3.PutObjectOutput{}, nil
+					},
+				},
+				&mockPhotoRepository{}, &countingGalleryRepository{}, accounts)
+
+			if err := processPhoto(app, "gal_abc123/photo_xyz789/original.jpg"); err != nil {
+				t.Fatalf("processPhoto() error: %v", err)
+			}
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(optimized))
+			if err != nil {
+				t.Fatalf("decode rendition: %v", err)
+			}
+			if cfg.Width != tt.wantWidth {
+				t.Errorf("rendition width = %d, want %d", cfg.Width, tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestUploadRenditions(t *testing.T) {
+	encoders, _, err := image.RenditionEncoders([]string{image.FormatWebP}, 85)
+	if err != nil {
+		t.Fatalf("RenditionEncoders() error = %v", err)
+	}
+	var imgBuf bytes.Buffer
+	jpeg.Encode(&imgBuf, createTestImage(1000, 500), nil)
+
+	uploaded := make(map[string]string)
+	contentTypes := make(map[string]string)
+	var stored *repository.Photo
+	app := newTestApp(t, &appconfig.ProcessorConfig{
+		S3BucketOptimized: "test-optimized",
+		Renditions:        image.DefaultRenditions,
+		Formats:           []string{image.FormatWebP},
+		Stages:            []string{handlers.StageRenditions},
+	},
+		&mockS3Client{
+			getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+				return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(imgBuf.Bytes()))}, nil
+			},
+			putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				uploaded[*params.Key] = *params.Bucket
+				contentTypes[*params.Key] = *params.ContentType
+				return &s3.PutObjectOutput{}, nil
+			},
+		},
+		&mockPhotoRepository{
+			updateFunc: func(ctx context.Context, photo *repository.Photo) error {
+				stored = photo
+				return nil
+			},
 		},
-		updateFunc: func(ctx context.Context, photo *repository.Photo) error {
-			if photo.ProcessingStatus != "completed" {
-				return fmt.Errorf("expected status completed, got %s", photo.ProcessingStatus)
+		&mockGalleryRepository{}, nil)
+
+	if err := processPhoto(app, "gal_abc123/photo_xyz789/original.jpg"); err != nil {
+		t.Fatalf("processPhoto() error = %v", err)
+	}
+
+	// A 1000px image gets xs and sm, then md at its own width instead of upscaled
+	wantWidths := map[string]int{"xs": 400, "sm": 800, "md": 1000}
+	records := stored.Renditions
+	if len(records) != len(wantWidths) {
+		t.Fatalf("got %d renditions, want %d: %+v", len(records), len(wantWidths), records)
+	}
+	for _, r := range records {
+		if r.Width != wantWidths[r.Name] || r.Height != r.Width/2 {
+			t.Errorf("rendition %s = %dx%d, want %dx%d", r.Name, r.Width, r.Height, wantWidths[r.Name], wantWidths[r.Name]/2)
+		}
+		if uploaded[r.Key] != "test-optimized" {
+			t.Errorf("rendition %s not uploaded to the optimized bucket at %s", r.Name, r.Key)
+		}
+		if r.Size == 0 {
+			t.Errorf("rendition %s has no size", r.Name)
+		}
+		if len(r.Formats) != len(encoders)-1 {
+			t.Errorf("rendition %s has formats %+v, want one per alternate encoder", r.Name, r.Formats)
+		}
+		for _, f := range r.Formats {
+			if uploaded[f.Key] != "test-optimized" || contentTypes[f.Key] != "image/webp" || f.Size == 0 {
+				t.Errorf("rendition %s %s not uploaded as image/webp at %s", r.Name, f.Format, f.Key)
 			}
-			return nil
+		}
+	}
+}
+
+func TestHandleGalleryJob(t *testing.T) {
+	galleries := mocks.NewMockGalleryRepository()
+	photos := mocks.NewMockPhotoRepository()
+	queue := mocks.NewMockProcessingQueue()
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	photos.AddPhoto(&repository.Photo{PhotoID: "photo_1", GalleryID: "gal_1", OriginalKey: "gal_1/photo_1/a.jpg", ProcessingStatus: "completed"})
+
+	app := newTestApp(t, &appconfig.ProcessorConfig{}, &mockS3Client{}, photos, galleries, nil)
+	app.reprocessor = reprocess.NewService(galleries, photos, queue)
+
+	body, _ := json.Marshal(processing.GalleryJob{Type: processing.JobReprocessGallery, GalleryID: "gal_1"})
+	response, err := app.handleS3Event(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{{MessageId: "msg-1", Body: string(body)}},
+	})
+	if err != nil || len(response.BatchItemFailures) != 0 {
+		t.Fatalf("handleS3Event() = %+v, %v, want no failures", response, err)
+	}
+	if queued := queue.Enqueued(); len(queued) != 1 || queued[0] != "gal_1/photo_1/a.jpg" {
+		t.Errorf("queued = %v, want the gallery's photo", queued)
+	}
+
+	// Jobs that can't queue their page go back to the queue
+	queue.EnqueueErr = errors.New("throttled")
+	photos.AddPhoto(&repository.Photo{PhotoID: "photo_2", GalleryID: "gal_1", OriginalKey: "gal_1/photo_2/b.jpg", ProcessingStatus: "completed"})
+	response, _ = app.handleS3Event(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{{MessageId: "msg-2", Body: string(body)}},
+	})
+	if len(response.BatchItemFailures) != 1 || response.BatchItemFailures[0].ItemIdentifier != "msg-2" {
+		t.Errorf("BatchItemFailures = %v, want msg-2", response.BatchItemFailures)
+	}
+}
+
+func TestUnknownStage(t *testing.T) {
+	cfg := &appconfig.ProcessorConfig{Stages: []string{handlers.StageThumbnail, "sharpen"}}
+	if _, err := newApp(cfg, &mockS3Client{}, &mockPhotoRepository{}, &mockGalleryRepository{}, mocks.NewMockPhotographerStore()); err == nil {
+		t.Error("newApp() should fail for a stage that doesn't exist")
+	}
+}
+
+// versionedPhotoRepository stores photos the way DynamoDB does for the processor: reads return
+// copies, creates fail for photos already stored, and updates fail if the photo changed since
+// it was read. Reads take readLatency to return, so concurrent writers act on stale reads.
+type versionedPhotoRepository struct {
+	mockPhotoRepository
+	mu          sync.Mutex
+	photos      map[string]*repository.Photo
+	readLatency time.Duration
+}
+
+func newVersionedPhotoRepository() *versionedPhotoRepository {
+	return &versionedPhotoRepository{photos: make(map[string]*repository.Photo)}
+}
+
+func clonePhoto(p *repository.Photo) *repository.Photo {
+	c := *p
+	c.Metadata = make(map[string]string, len(p.Metadata))
+	for k, v := range p.Metadata {
+		c.Metadata[k] = v
+	}
+	if p.Failure != nil {
+		failure := *p.Failure
+		c.Failure = &failure
+	}
+	return &c
+}
+
+func (r *versionedPhotoRepository) GetByID(ctx context.Context, id string) (*repository.Photo, error) {
+	r.mu.Lock()
+	var p *repository.Photo
+	if stored := r.photos[id]; stored != nil {
+		p = clonePhoto(stored)
+	}
+	r.mu.Unlock()
+	time.Sleep(r.readLatency)
+	return p, nil
+}
+
+func (r *versionedPhotoRepository) Create(ctx context.Context, p *repository.Photo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.photos[p.PhotoID] != nil {
+		return repository.ErrAlreadyExists
+	}
+	r.photos[p.PhotoID] = clonePhoto(p)
+	return nil
+}
+
+func (r *versionedPhotoRepository) Update(ctx context.Context, p *repository.Photo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored := r.photos[p.PhotoID]; stored != nil && stored.Version != p.Version {
+		return repository.ErrConflict
+	}
+	p.Version++
+	r.photos[p.PhotoID] = clonePhoto(p)
+	return nil
+}
+
+// countingGalleryRepository counts the photos and bytes added to its gallery
+type countingGalleryRepository struct {
+	mockGalleryRepository
+	mu         sync.Mutex
+	photoCount int
+	totalSize  int64
+}
+
+func (r *countingGalleryRepository) GetByID(ctx context.Context, id string) (*repository.Gallery, error) {
+	return &repository.Gallery{GalleryID: id, PhotographerID: "user_1"}, nil
+}
+
+func (r *countingGalleryRepository) UpdatePhotoCount(ctx context.Context, id string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.photoCount += delta
+	return nil
+}
+
+func (r *countingGalleryRepository) UpdateTotalSize(ctx context.Context, id string, delta int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.totalSize += delta
+	return nil
+}
+
+// deliveryTest is a processor whose stores behave like DynamoDB's, receiving notifications of
+// an original whose ETag can change
+type deliveryTest struct {
+	app       *App
+	photos    *versionedPhotoRepository
+	galleries *countingGalleryRepository
+	accounts  *mocks.MockPhotographerStore
+	original  []byte
+	etag      atomic.Value // the original's current ETag, as GetObject quotes it
+	downloads atomic.Int32
+}
+
+func newDeliveryTest(t *testing.T) *deliveryTest {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, createTestImage(800, 600), nil); err != nil {
+		t.Fatal(err)
+	}
+	d := &deliveryTest{
+		photos:    newVersionedPhotoRepository(),
+		galleries: &countingGalleryRepository{},
+		accounts:  mocks.NewMockPhotographerStore(),
+		original:  buf.Bytes(),
+	}
+	d.etag.Store(`"etag-1"`)
+	d.accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1"})
+	d.app = newTestApp(t, &appconfig.ProcessorConfig{S3BucketOptimized: "test-optimized", S3BucketThumbnail: "test-thumbnail"},
+		&mockS3Client{
+			getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+				d.downloads.Add(1)
+				return &s3.GetObjectOutput{
+					Body: io.NopCloser(bytes.NewReader(d.original)),
+					ETag: aws.String(d.etag.Load().(string)),
+				}, nil
+			},
+			putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				return &s3.PutObjectOutput{}, nil
+			},
 		},
+		d.photos, d.galleries, d.accounts)
+	return d
+}
+
+// deliver handles a notification of the original with the given ETag, as S3 sends it unquoted,
+// returning whether the message is to be retried
+func (d *deliveryTest) deliver(t *testing.T, etag string) bool {
+	body, _ := json.Marshal(events.S3Event{
+		Records: []events.S3EventRecord{{
+			S3: events.S3Entity{
+				Bucket: events.S3Bucket{Name: "test-original"},
+				Object: events.S3Object{Key: "gal_abc123/photo_xyz789/original.jpg", ETag: etag},
+			},
+		}},
+	})
+	response, err := d.app.handleS3Event(context.Background(), events.SQSEvent{
+		Records: []events.SQSMessage{{MessageId: "msg-1", Body: string(body)}},
+	})
+	if err != nil {
+		t.Errorf("handleS3Event() error = %v", err)
+	}
+	return len(response.BatchItemFailures) > 0
+}
+
+// assertCountedOnce checks the photo was added to its gallery and the photographer's storage
+// exactly once. Photos recorded when uploaded were counted then, so only their derivatives are.
+func (d *deliveryTest) assertCountedOnce(t *testing.T, recordedAtUpload bool) {
+	t.Helper()
+	stored, _ := d.photos.GetByID(context.Background(), "photo_xyz789")
+	if stored == nil || stored.ProcessingStatus != "completed" {
+		t.Fatalf("photo = %+v, want it completed", stored)
+	}
+	wantPhotos, wantBytes := 1, int64(len(d.original))
+	if recordedAtUpload {
+		wantPhotos, wantBytes = 0, 0
+	}
+	if d.galleries.photoCount != wantPhotos || d.galleries.totalSize != wantBytes {
+		t.Errorf("gallery counted %d photos of %d bytes, want %d of %d", d.galleries.photoCount, d.galleries.totalSize, wantPhotos, wantBytes)
+	}
+	want := wantBytes + quota.PhotoBytes(stored) - stored.Size
+	if used := d.accounts.StorageUsed("user_1"); used != want {
+		t.Errorf("storage used = %d, want the photo counted once, %d", used, want)
+	}
+}
+
+func TestDuplicateDeliveries(t *testing.T) {
+	d := newDeliveryTest(t)
+
+	if d.deliver(t, "etag-1") {
+		t.Fatal("first delivery should succeed")
+	}
+	for i := 0; i < 2; i++ {
+		if d.deliver(t, "etag-1") {
+			t.Error("duplicate delivery should succeed")
+		}
+	}
+	if n := d.downloads.Load(); n != 1 {
+		t.Errorf("original downloaded %d times, want duplicates skipped", n)
 	}
+	if stored, _ := d.photos.GetByID(context.Background(), "photo_xyz789"); stored.SourceVersion != "etag-1" {
+		t.Errorf("SourceVersion = %q, want etag-1", stored.SourceVersion)
+	}
+	d.assertCountedOnce(t, false)
 
-	app := &App{
-		cfg:       &appconfig.ProcessorConfig{},
-		photoRepo: mockPhoto,
+	// The original replaced under the same key is a new version, processed but not counted again
+	d.etag.Store(`"etag-2"`)
+	if d.deliver(t, "etag-2") {
+		t.Fatal("delivery of a new version should succeed")
+	}
+	if n := d.downloads.Load(); n != 2 {
+		t.Errorf("original downloaded %d times, want the new version processed", n)
 	}
+	if stored, _ := d.photos.GetByID(context.Background(), "photo_xyz789"); stored.SourceVersion != "etag-2" {
+		t.Errorf("SourceVersion = %q, want etag-2", stored.SourceVersion)
+	}
+	d.assertCountedOnce(t, false)
+}
 
-	// updatePhotoStatus doesn't return an error, it handles errors internally
-	app.updatePhotoStatus(context.Background(), "photo-123", "completed")
-	// Test passes if no panic
+func TestConcurrentDeliveries(t *testing.T) {
+	for _, recordedAtUpload := range []bool{false, true} {
+		name := "new photo"
+		if recordedAtUpload {
+			name = "photo recorded at upload"
+		}
+		t.Run(name, func(t *testing.T) {
+			d := newDeliveryTest(t)
+			d.photos.readLatency = 20 * time.Millisecond
+			if recordedAtUpload {
+				d.photos.Create(context.Background(), &repository.Photo{
+					PhotoID:          "photo_xyz789",
+					GalleryID:        "gal_abc123",
+					Size:             int64(len(d.original)),
+					ProcessingStatus: "pending",
+				})
+			}
+
+			const deliveries = 8
+			var wg sync.WaitGroup
+			var retries atomic.Int32
+			start := make(chan struct{})
+			for i := 0; i < deliveries; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					if d.deliver(t, "etag-1") {
+						retries.Add(1)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			// Deliveries that kept losing are retried by SQS, and find the photo processed
+			for i := int32(0); i < retries.Load(); i++ {
+				if d.deliver(t, "etag-1") {
+					t.Error("retried delivery should succeed")
+				}
+			}
+			d.assertCountedOnce(t, recordedAtUpload)
+		})
+	}
 }
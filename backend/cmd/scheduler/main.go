@@ -11,14 +11,63 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
+	"photographer-gallery/backend/internal/adapters"
 	"photographer-gallery/backend/internal/domain/gallery"
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/placeholder"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/trash"
+	"photographer-gallery/backend/internal/domain/webhook"
 	dynamodbRepo "photographer-gallery/backend/internal/repository/dynamodb"
+	"photographer-gallery/backend/internal/services/outbox"
+	"photographer-gallery/backend/internal/services/processing"
 	"photographer-gallery/backend/internal/services/storage"
+	"photographer-gallery/backend/pkg/events"
 )
 
+// Scheduled task names, passed by EventBridge rules as the "task" field of the event
+const (
+	taskCleanupExpired   = "cleanup-expired"
+	taskRelayOutbox      = "relay-outbox"
+	taskRetryWebhooks    = "retry-webhooks"
+	taskRestoreGalleries = "restore-galleries"
+	taskPurgeTrash       = "purge-trash"
+	taskReconcileStorage = "reconcile-storage"
+	// taskMigrateFavorites is run once by hand after deploying named favorites lists
+	taskMigrateFavorites = "migrate-favorites"
+	// taskBackfillPlaceholders is run by hand after deploying photo placeholders, and again
+	// if it times out; photos already backfilled are skipped
+	taskBackfillPlaceholders = "backfill-placeholders"
+)
+
+// outboxBatchSize is the maximum number of outbox events relayed per invocation
+const outboxBatchSize = 100
+
+// restoreBatchSize is the maximum number of restoring galleries advanced per invocation
+const restoreBatchSize = 100
+
+// trashPurgeBatchSize is the maximum number of trash items purged per invocation
+const trashPurgeBatchSize = 100
+
+// webhookRetryBatchSize is the maximum number of due webhook deliveries sent per invocation
+const webhookRetryBatchSize = 100
+
 type SchedulerApp struct {
 	galleryService *gallery.Service
+	outboxRelay    *outbox.Relay
+	webhookService *webhook.Service
+	favoriteRepo   *dynamodbRepo.FavoriteRepository
+	trashService   *trash.Service
+	quotaService   *quota.Service
+	placeholders   *placeholder.Backfill
+}
+
+// ScheduledEvent is the input sent by EventBridge rules. An empty task runs the expired gallery cleanup.
+type ScheduledEvent struct {
+	Task string `json:"task"`
 }
 
 func main() {
@@ -42,6 +91,7 @@ func initializeApp() (*SchedulerApp, error) {
 	// Initialize AWS clients
 	dynamoClient := dynamodb.NewFromConfig(cfg)
 	s3Client := s3.NewFromConfig(cfg)
+	sqsClient := sqs.NewFromConfig(cfg)
 
 	// Get environment variables
 	tablePrefix := os.Getenv("DYNAMODB_TABLE_PREFIX")
@@ -49,6 +99,7 @@ func initializeApp() (*SchedulerApp, error) {
 	originalBucket := os.Getenv("S3_BUCKET_ORIGINAL")
 	optimizedBucket := os.Getenv("S3_BUCKET_OPTIMIZED")
 	thumbnailBucket := os.Getenv("S3_BUCKET_THUMBNAIL")
+	processingQueueURL := os.Getenv("PROCESSING_QUEUE_URL")
 
 	if tablePrefix == "" {
 		tablePrefix = "photographer-gallery"
@@ -60,27 +111,174 @@ func initializeApp() (*SchedulerApp, error) {
 	// Initialize repositories
 	galleriesTable := fmt.Sprintf("%s-galleries-%s", tablePrefix, stage)
 	photosTable := fmt.Sprintf("%s-photos-%s", tablePrefix, stage)
+	outboxTable := fmt.Sprintf("%s-outbox-%s", tablePrefix, stage)
+	webhooksTable := fmt.Sprintf("%s-webhooks-%s", tablePrefix, stage)
+	deliveriesTable := fmt.Sprintf("%s-webhook-deliveries-%s", tablePrefix, stage)
+	favoritesTable := fmt.Sprintf("%s-favorites-%s", tablePrefix, stage)
+	selectionsTable := fmt.Sprintf("%s-selections-%s", tablePrefix, stage)
+	trashTable := fmt.Sprintf("%s-trash-%s", tablePrefix, stage)
+	photographersTable := fmt.Sprintf("%s-photographers-%s", tablePrefix, stage)
 
-	galleryRepo := dynamodbRepo.NewGalleryRepository(dynamoClient, galleriesTable)
-	photoRepo := dynamodbRepo.NewPhotoRepository(dynamoClient, photosTable)
+	outboxRepo := dynamodbRepo.NewOutboxRepository(dynamoClient, outboxTable)
+	galleryRepo := dynamodbRepo.NewGalleryRepository(dynamoClient, galleriesTable).WithOutbox(outboxRepo)
+	photoRepo := dynamodbRepo.NewPhotoRepository(dynamoClient, photosTable).WithOutbox(outboxRepo)
+	webhookRepo := dynamodbRepo.NewWebhookRepository(dynamoClient, webhooksTable)
+	deliveryRepo := dynamodbRepo.NewWebhookDeliveryRepository(dynamoClient, deliveriesTable)
+	favoriteRepo := dynamodbRepo.NewFavoriteRepository(dynamoClient, favoritesTable)
+	selectionRepo := dynamodbRepo.NewSelectionRepository(dynamoClient, selectionsTable)
+	trashRepo := dynamodbRepo.NewTrashRepository(dynamoClient, trashTable)
+	photographerRepo := dynamodbRepo.NewPhotographerRepository(dynamoClient, photographersTable)
 
 	// Initialize storage service
 	presignExpiration := 15 * time.Minute
 	storageService := storage.NewService(s3Client, originalBucket, optimizedBucket, thumbnailBucket, presignExpiration)
 
-	// Initialize gallery service
-	galleryService := gallery.NewService(galleryRepo, photoRepo, storageService)
+	// Initialize storage accounting; archiving and purging free photographers' storage
+	quotaService := quota.NewService(photographerRepo, galleryRepo, photoRepo)
+
+	// Initialize gallery service; restored photos are sent back through image processing and
+	// reactivated galleries expire as their plan requires
+	processingQueue := processing.NewQueue(sqsClient, processingQueueURL, originalBucket)
+	galleryService := gallery.NewService(galleryRepo, photoRepo, storageService, processingQueue).
+		WithQuota(quotaService).
+		WithPlans(plan.NewService(photographerRepo))
+
+	// Initialize trash purging; items carry their purge time, so no retention is needed here
+	photoService := photo.NewService(photoRepo, galleryRepo, favoriteRepo, selectionRepo, storageService).WithQuota(quotaService)
+	trashService := trash.NewService(trashRepo, galleryService, photoService)
+
+	// Initialize webhook delivery
+	webhookService := webhook.NewService(webhookRepo, deliveryRepo, galleryRepo, webhook.NewHTTPSender(nil))
+
+	// Initialize outbox relay; relayed events fan out to photographer webhooks
+	eventBus := events.NewEventBus()
+	webhookService.Subscribe(eventBus)
+	outboxRelay := outbox.NewRelay(outboxRepo, eventBus, outbox.DefaultMaxAttempts)
 
 	return &SchedulerApp{
 		galleryService: galleryService,
+		outboxRelay:    outboxRelay,
+		webhookService: webhookService,
+		favoriteRepo:   favoriteRepo,
+		trashService:   trashService,
+		quotaService:   quotaService,
+		placeholders:   placeholder.NewBackfill(photographerRepo, galleryRepo, photoRepo, adapters.NewS3Adapter(s3Client), optimizedBucket),
 	}, nil
 }
 
-// handleScheduledEvent is triggered daily by EventBridge to clean up expired galleries
-func (app *SchedulerApp) handleScheduledEvent(ctx context.Context, event interface{}) error {
+// handleScheduledEvent dispatches EventBridge scheduled events to the requested task
+func (app *SchedulerApp) handleScheduledEvent(ctx context.Context, event ScheduledEvent) error {
+	switch event.Task {
+	case "", taskCleanupExpired:
+		return app.cleanupExpiredGalleries(ctx)
+	case taskRelayOutbox:
+		return app.relayOutbox(ctx)
+	case taskRetryWebhooks:
+		return app.retryWebhooks(ctx)
+	case taskRestoreGalleries:
+		return app.restoreGalleries(ctx)
+	case taskPurgeTrash:
+		return app.purgeTrash(ctx)
+	case taskReconcileStorage:
+		return app.reconcileStorage(ctx)
+	case taskMigrateFavorites:
+		return app.migrateFavorites(ctx)
+	case taskBackfillPlaceholders:
+		return app.backfillPlaceholders(ctx)
+	default:
+		return fmt.Errorf("unknown scheduled task: %s", event.Task)
+	}
+}
+
+// relayOutbox is triggered every minute to publish pending domain events
+func (app *SchedulerApp) relayOutbox(ctx context.Context) error {
+	result, err := app.outboxRelay.RelayPending(ctx, outboxBatchSize)
+	if err != nil {
+		log.Printf("ERROR: Failed to relay outbox events: %v", err)
+		return fmt.Errorf("failed to relay outbox events: %w", err)
+	}
+
+	log.Printf("Outbox relay completed: published=%d retrying=%d failed=%d", result.Published, result.Retrying, result.Failed)
+	return nil
+}
+
+// retryWebhooks is triggered every minute to send webhook deliveries that are due: new
+// deliveries recorded by the outbox relay and retries whose backoff has elapsed
+func (app *SchedulerApp) retryWebhooks(ctx context.Context) error {
+	attempted, err := app.webhookService.RetryDue(ctx, webhookRetryBatchSize)
+	if err != nil {
+		log.Printf("ERROR: Failed to retry webhook deliveries: %v", err)
+		return fmt.Errorf("failed to retry webhook deliveries: %w", err)
+	}
+
+	log.Printf("Webhook retry completed: attempted=%d", attempted)
+	return nil
+}
+
+// reconcileStorage is triggered daily to recompute photographers' storage usage and correct drift
+func (app *SchedulerApp) reconcileStorage(ctx context.Context) error {
+	corrected, err := app.quotaService.ReconcileAll(ctx)
+	if err != nil {
+		log.Printf("ERROR: Failed to reconcile storage usage after %d corrections: %v", corrected, err)
+		return fmt.Errorf("failed to reconcile storage usage: %w", err)
+	}
+
+	log.Printf("Storage reconciliation completed: corrected=%d", corrected)
+	return nil
+}
+
+// migrateFavorites moves favorites saved before named lists existed into each session's default list
+func (app *SchedulerApp) migrateFavorites(ctx context.Context) error {
+	migrated, err := app.favoriteRepo.MigrateLegacyFavorites(ctx)
+	if err != nil {
+		log.Printf("ERROR: Failed to migrate favorites after %d items: %v", migrated, err)
+		return fmt.Errorf("failed to migrate favorites: %w", err)
+	}
+
+	log.Printf("Favorites migration completed: migrated=%d", migrated)
+	return nil
+}
+
+// backfillPlaceholders computes BlurHash placeholders for photos processed before they existed
+func (app *SchedulerApp) backfillPlaceholders(ctx context.Context) error {
+	backfilled, err := app.placeholders.Run(ctx)
+	if err != nil {
+		log.Printf("ERROR: Failed to backfill placeholders after %d photos: %v", backfilled, err)
+		return fmt.Errorf("failed to backfill placeholders: %w", err)
+	}
+
+	log.Printf("Placeholder backfill completed: backfilled=%d", backfilled)
+	return nil
+}
+
+// restoreGalleries is triggered hourly to finish restores once originals are back from cold storage
+func (app *SchedulerApp) restoreGalleries(ctx context.Context) error {
+	if err := app.galleryService.ProcessRestoringGalleries(ctx, restoreBatchSize); err != nil {
+		log.Printf("ERROR: Failed to advance gallery restores: %v", err)
+		return fmt.Errorf("failed to advance gallery restores: %w", err)
+	}
+
+	log.Printf("Gallery restore check completed at %v", time.Now().UTC())
+	return nil
+}
+
+// purgeTrash is triggered daily to permanently delete galleries and photos past their trash retention
+func (app *SchedulerApp) purgeTrash(ctx context.Context) error {
+	purged, err := app.trashService.PurgeExpired(ctx, trashPurgeBatchSize)
+	if err != nil {
+		log.Printf("ERROR: Failed to purge trash after %d items: %v", purged, err)
+		return fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	log.Printf("Trash purge completed: purged=%d", purged)
+	return nil
+}
+
+// cleanupExpiredGalleries is triggered daily by EventBridge to archive expired galleries
+func (app *SchedulerApp) cleanupExpiredGalleries(ctx context.Context) error {
 	log.Printf("Starting scheduled gallery cleanup task at %v", time.Now().UTC())
 
-	// Process expired galleries (deletes galleries where expiresAt < now)
+	// Process expired galleries (archives galleries where expiresAt < now)
 	if err := app.galleryService.ProcessExpiredGalleries(ctx, 100); err != nil {
 		log.Printf("ERROR: Failed to process expired galleries: %v", err)
 		return fmt.Errorf("failed to process expired galleries: %w", err)
@@ -6,6 +6,7 @@ import (
 	"context"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -29,6 +30,13 @@ func NewS3Adapter(client S3Client) *S3Adapter {
 
 // Download downloads an object from S3 and returns a reader.
 func (a *S3Adapter) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	body, _, err := a.DownloadVersion(ctx, bucket, key)
+	return body, err
+}
+
+// DownloadVersion downloads an object from S3 and returns a reader and the version of the
+// object read, as ObjectVersion identifies it.
+func (a *S3Adapter) DownloadVersion(ctx context.Context, bucket, key string) (io.ReadCloser, string, error) {
 	log.Printf("[S3Adapter] Downloading from %s/%s", bucket, key)
 
 	output, err := a.client.GetObject(ctx, &s3.GetObjectInput{
@@ -36,10 +44,20 @@ func (a *S3Adapter) Download(ctx context.Context, bucket, key string) (io.ReadCl
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return output.Body, nil
+	return output.Body, ObjectVersion(aws.ToString(output.VersionId), aws.ToString(output.ETag)), nil
+}
+
+// ObjectVersion identifies a version of an object: its S3 version ID in versioned buckets,
+// otherwise its ETag. S3 notifications and GetObject quote ETags differently, so quotes are
+// dropped.
+func ObjectVersion(versionID, etag string) string {
+	if versionID != "" {
+		return versionID
+	}
+	return strings.Trim(etag, `"`)
 }
 
 // DownloadBytes downloads an object and returns the bytes.
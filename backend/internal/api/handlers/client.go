@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"photographer-gallery/backend/internal/domain/auth"
+	"photographer-gallery/backend/internal/domain/download"
 	"photographer-gallery/backend/internal/domain/gallery"
 	"photographer-gallery/backend/internal/domain/photo"
 	"photographer-gallery/backend/pkg/errors"
@@ -12,9 +13,10 @@ import (
 
 // ClientHandler handles client-facing HTTP requests
 type ClientHandler struct {
-	galleryService *gallery.Service
-	photoService   *photo.Service
-	sessionService *auth.SessionService
+	galleryService  *gallery.Service
+	photoService    *photo.Service
+	sessionService  *auth.SessionService
+	downloadService *download.Service
 }
 
 // NewClientHandler creates a new client handler
@@ -22,11 +24,13 @@ func NewClientHandler(
 	galleryService *gallery.Service,
 	photoService *photo.Service,
 	sessionService *auth.SessionService,
+	downloadService *download.Service,
 ) *ClientHandler {
 	return &ClientHandler{
-		galleryService: galleryService,
-		photoService:   photoService,
-		sessionService: sessionService,
+		galleryService:  galleryService,
+		photoService:    photoService,
+		sessionService:  sessionService,
+		downloadService: downloadService,
 	}
 }
 
@@ -143,9 +147,11 @@ func (h *ClientHandler) GetDownloadURL(w http.ResponseWriter, r *http.Request) {
 }
 
 // ToggleFavorite handles POST /client/photos/:photoId/favorite
+// and POST /client/session/lists/:listId/photos/:photoId/favorite
 func (h *ClientHandler) ToggleFavorite(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	photoID := getURLParam(r, "photoId")
+	listID, _ := ctx.Value("listId").(string) // empty for the default list
 
 	// Get session info from context (set by middleware)
 	galleryID, ok := ctx.Value("galleryID").(string)
@@ -159,7 +165,7 @@ func (h *ClientHandler) ToggleFavorite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	favorited, err := h.photoService.ToggleFavorite(ctx, galleryID, sessionID, photoID)
+	favorited, err := h.photoService.ToggleFavorite(ctx, galleryID, sessionID, listID, photoID)
 	if err != nil {
 		respondError(w, err)
 		return
@@ -192,7 +198,250 @@ func (h *ClientHandler) GetSessionFavorites(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	lists, err := h.photoService.ListFavoriteLists(ctx, galleryID, sessionID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	selection, err := h.photoService.GetSelection(ctx, galleryID, sessionID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"favorites": favorites,
+		"lists":     lists,
+		"selection": selection,
+	})
+}
+
+// FavoriteListRequest represents the request to create or rename a favorites list
+type FavoriteListRequest struct {
+	Name string `json:"name"`
+}
+
+// ListFavoriteLists handles GET /client/session/lists
+func (h *ClientHandler) ListFavoriteLists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	lists, err := h.photoService.ListFavoriteLists(ctx, galleryID, sessionID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"lists": lists,
 	})
 }
+
+// CreateFavoriteList handles POST /client/session/lists
+func (h *ClientHandler) CreateFavoriteList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	var req FavoriteListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	list, err := h.photoService.CreateFavoriteList(ctx, galleryID, sessionID, req.Name)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, list)
+}
+
+// RenameFavoriteList handles PUT /client/session/lists/:listId
+func (h *ClientHandler) RenameFavoriteList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	var req FavoriteListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	list, err := h.photoService.RenameFavoriteList(ctx, galleryID, sessionID, getURLParam(r, "listId"), req.Name)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
+}
+
+// DeleteFavoriteList handles DELETE /client/session/lists/:listId
+func (h *ClientHandler) DeleteFavoriteList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	if err := h.photoService.DeleteFavoriteList(ctx, galleryID, sessionID, getURLParam(r, "listId")); err != nil {
+		respondError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetListFavorites handles GET /client/session/lists/:listId/favorites
+func (h *ClientHandler) GetListFavorites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	favorites, err := h.photoService.ListFavoritesByList(ctx, galleryID, sessionID, getURLParam(r, "listId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"favorites": favorites,
+	})
+}
+
+// SubmitSelection handles POST /client/session/selection/submit
+func (h *ClientHandler) SubmitSelection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	selection, err := h.photoService.SubmitSelection(ctx, galleryID, sessionID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, selection)
+}
+
+// StartDownloadRequest represents the request to build a ZIP archive
+type StartDownloadRequest struct {
+	Scope   string `json:"scope,omitempty"`   // gallery (default) or favorites
+	Variant string `json:"variant,omitempty"` // optimized (default) or original
+}
+
+// StartDownload handles POST /client/downloads
+func (h *ClientHandler) StartDownload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	var req StartDownloadRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.NewBadRequest("Invalid request body"))
+			return
+		}
+	}
+
+	job, err := h.downloadService.Start(ctx, galleryID, sessionID, download.StartRequest{
+		Scope:   req.Scope,
+		Variant: req.Variant,
+	})
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, job)
+}
+
+// GetDownload handles GET /client/downloads/:jobId
+func (h *ClientHandler) GetDownload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	galleryID, ok := ctx.Value("galleryID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Gallery ID not found in session"))
+		return
+	}
+	sessionID, ok := ctx.Value("sessionID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("Session ID not found"))
+		return
+	}
+
+	status, err := h.downloadService.Get(ctx, galleryID, sessionID, getURLParam(r, "jobId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
@@ -109,6 +109,10 @@ func (h *DomainHandler) RequestCustomDomain(w http.ResponseWriter, r *http.Reque
 		case customdomain.ErrCustomDomainInvalid:
 			respondError(w, errors.NewBadRequest("Domain format is invalid"))
 		default:
+			if errors.IsPlanLimit(err) {
+				respondError(w, err)
+				return
+			}
 			logger.Error("Failed to request custom domain", map[string]interface{}{"error": err.Error()})
 			respondError(w, errors.NewInternalServer("Failed to request custom domain"))
 		}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
 	"photographer-gallery/backend/internal/domain/gallery"
+	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/pkg/errors"
 	"photographer-gallery/backend/pkg/logger"
 )
@@ -24,14 +26,28 @@ func NewGalleryHandler(galleryService *gallery.Service) *GalleryHandler {
 
 // CreateGalleryRequest represents the HTTP request body
 type CreateGalleryRequest struct {
-	Name              string  `json:"name"`
-	Description       string  `json:"description"`
-	CustomURL         string  `json:"customUrl"`
-	Password          string  `json:"password"`
-	ExpiresAt         *string `json:"expiresAt,omitempty"`
-	EnableWatermark   bool    `json:"enableWatermark"`
-	WatermarkText     string  `json:"watermarkText,omitempty"`
-	WatermarkPosition string  `json:"watermarkPosition,omitempty"`
+	Name                string  `json:"name"`
+	Description         string  `json:"description"`
+	CustomURL           string  `json:"customUrl"`
+	Password            string  `json:"password"`
+	ExpiresAt           *string `json:"expiresAt,omitempty"`
+	EnableWatermark     bool    `json:"enableWatermark"`
+	WatermarkText       string  `json:"watermarkText,omitempty"`
+	WatermarkPosition   string  `json:"watermarkPosition,omitempty"`
+	WatermarkFont       string  `json:"watermarkFont,omitempty"`
+	WatermarkSize       float64 `json:"watermarkSize,omitempty"`
+	WatermarkColor      string  `json:"watermarkColor,omitempty"`
+	WatermarkOpacity    float64 `json:"watermarkOpacity,omitempty"`
+	WatermarkMargin     float64 `json:"watermarkMargin,omitempty"`
+	WatermarkMode       string  `json:"watermarkMode,omitempty"`
+	WatermarkRotation   float64 `json:"watermarkRotation,omitempty"`
+	WatermarkType       string  `json:"watermarkType,omitempty"`
+	WatermarkScale      float64 `json:"watermarkScale,omitempty"`
+	ThumbnailCrop       string  `json:"thumbnailCrop,omitempty"`
+	DownloadPolicy      string  `json:"downloadPolicy,omitempty"`
+	ProofingEnabled     bool    `json:"proofingEnabled"`
+	SelectionLimit      int     `json:"selectionLimit,omitempty"`
+	ProcessingProfileID string  `json:"processingProfileId,omitempty"`
 }
 
 // CreateGallery handles POST /galleries
@@ -74,15 +90,29 @@ func (h *GalleryHandler) CreateGallery(w http.ResponseWriter, r *http.Request) {
 
 	// Create gallery
 	g, err := h.galleryService.Create(ctx, gallery.CreateGalleryRequest{
-		PhotographerID:    photographerID,
-		Name:              req.Name,
-		Description:       req.Description,
-		CustomURL:         req.CustomURL,
-		Password:          req.Password,
-		ExpiresAt:         expiresAt,
-		EnableWatermark:   req.EnableWatermark,
-		WatermarkText:     req.WatermarkText,
-		WatermarkPosition: req.WatermarkPosition,
+		PhotographerID:      photographerID,
+		Name:                req.Name,
+		Description:         req.Description,
+		CustomURL:           req.CustomURL,
+		Password:            req.Password,
+		ExpiresAt:           expiresAt,
+		EnableWatermark:     req.EnableWatermark,
+		WatermarkText:       req.WatermarkText,
+		WatermarkPosition:   req.WatermarkPosition,
+		WatermarkFont:       req.WatermarkFont,
+		WatermarkSize:       req.WatermarkSize,
+		WatermarkColor:      req.WatermarkColor,
+		WatermarkOpacity:    req.WatermarkOpacity,
+		WatermarkMargin:     req.WatermarkMargin,
+		WatermarkMode:       req.WatermarkMode,
+		WatermarkRotation:   req.WatermarkRotation,
+		WatermarkType:       req.WatermarkType,
+		WatermarkScale:      req.WatermarkScale,
+		ThumbnailCrop:       req.ThumbnailCrop,
+		DownloadPolicy:      req.DownloadPolicy,
+		ProofingEnabled:     req.ProofingEnabled,
+		SelectionLimit:      req.SelectionLimit,
+		ProcessingProfileID: req.ProcessingProfileID,
 	})
 
 	if err != nil {
@@ -96,9 +126,16 @@ func (h *GalleryHandler) CreateGallery(w http.ResponseWriter, r *http.Request) {
 // GetGallery handles GET /galleries/:id
 func (h *GalleryHandler) GetGallery(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
-	g, err := h.galleryService.GetByID(ctx, galleryID)
+	g, err := h.galleryService.GetForPhotographer(ctx, photographerID, galleryID)
 	if err != nil {
 		respondError(w, err)
 		return
@@ -136,18 +173,39 @@ func (h *GalleryHandler) ListGalleries(w http.ResponseWriter, r *http.Request) {
 
 // UpdateGalleryRequest represents the update request
 type UpdateGalleryRequest struct {
-	Name              *string `json:"name,omitempty"`
-	Description       *string `json:"description,omitempty"`
-	Password          *string `json:"password,omitempty"`
-	ExpiresAt         *string `json:"expiresAt,omitempty"`
-	EnableWatermark   *bool   `json:"enableWatermark,omitempty"`
-	WatermarkText     *string `json:"watermarkText,omitempty"`
-	WatermarkPosition *string `json:"watermarkPosition,omitempty"`
+	Name                *string  `json:"name,omitempty"`
+	Description         *string  `json:"description,omitempty"`
+	Password            *string  `json:"password,omitempty"`
+	ExpiresAt           *string  `json:"expiresAt,omitempty"`
+	EnableWatermark     *bool    `json:"enableWatermark,omitempty"`
+	WatermarkText       *string  `json:"watermarkText,omitempty"`
+	WatermarkPosition   *string  `json:"watermarkPosition,omitempty"`
+	WatermarkFont       *string  `json:"watermarkFont,omitempty"`
+	WatermarkSize       *float64 `json:"watermarkSize,omitempty"`
+	WatermarkColor      *string  `json:"watermarkColor,omitempty"`
+	WatermarkOpacity    *float64 `json:"watermarkOpacity,omitempty"`
+	WatermarkMargin     *float64 `json:"watermarkMargin,omitempty"`
+	WatermarkMode       *string  `json:"watermarkMode,omitempty"`
+	WatermarkRotation   *float64 `json:"watermarkRotation,omitempty"`
+	WatermarkType       *string  `json:"watermarkType,omitempty"`
+	WatermarkScale      *float64 `json:"watermarkScale,omitempty"`
+	ThumbnailCrop       *string  `json:"thumbnailCrop,omitempty"`
+	DownloadPolicy      *string  `json:"downloadPolicy,omitempty"`
+	ProofingEnabled     *bool    `json:"proofingEnabled,omitempty"`
+	SelectionLimit      *int     `json:"selectionLimit,omitempty"`
+	ProcessingProfileID *string  `json:"processingProfileId,omitempty"` // "" removes the gallery's profile
 }
 
 // UpdateGallery handles PUT /galleries/:id
 func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
 	var req UpdateGalleryRequest
@@ -157,12 +215,26 @@ func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 	}
 
 	updateReq := gallery.UpdateGalleryRequest{
-		Name:              req.Name,
-		Description:       req.Description,
-		Password:          req.Password,
-		EnableWatermark:   req.EnableWatermark,
-		WatermarkText:     req.WatermarkText,
-		WatermarkPosition: req.WatermarkPosition,
+		Name:                req.Name,
+		Description:         req.Description,
+		Password:            req.Password,
+		EnableWatermark:     req.EnableWatermark,
+		WatermarkText:       req.WatermarkText,
+		WatermarkPosition:   req.WatermarkPosition,
+		WatermarkFont:       req.WatermarkFont,
+		WatermarkSize:       req.WatermarkSize,
+		WatermarkColor:      req.WatermarkColor,
+		WatermarkOpacity:    req.WatermarkOpacity,
+		WatermarkMargin:     req.WatermarkMargin,
+		WatermarkMode:       req.WatermarkMode,
+		WatermarkRotation:   req.WatermarkRotation,
+		WatermarkType:       req.WatermarkType,
+		WatermarkScale:      req.WatermarkScale,
+		ThumbnailCrop:       req.ThumbnailCrop,
+		DownloadPolicy:      req.DownloadPolicy,
+		ProofingEnabled:     req.ProofingEnabled,
+		SelectionLimit:      req.SelectionLimit,
+		ProcessingProfileID: req.ProcessingProfileID,
 	}
 
 	if req.ExpiresAt != nil {
@@ -174,7 +246,7 @@ func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 		updateReq.ExpiresAt = &t
 	}
 
-	g, err := h.galleryService.Update(ctx, galleryID, updateReq)
+	g, err := h.galleryService.Update(ctx, photographerID, galleryID, updateReq)
 	if err != nil {
 		respondError(w, err)
 		return
@@ -186,9 +258,16 @@ func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 // DeleteGallery handles DELETE /galleries/:id
 func (h *GalleryHandler) DeleteGallery(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
-	if err := h.galleryService.Delete(ctx, galleryID); err != nil {
+	if err := h.galleryService.Delete(ctx, photographerID, galleryID); err != nil {
 		respondError(w, err)
 		return
 	}
@@ -204,6 +283,13 @@ type SetExpirationRequest struct {
 // SetExpiration handles POST /galleries/:id/expire
 func (h *GalleryHandler) SetExpiration(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
 	var req SetExpirationRequest
@@ -222,7 +308,7 @@ func (h *GalleryHandler) SetExpiration(w http.ResponseWriter, r *http.Request) {
 		expiresAt = &t
 	}
 
-	g, err := h.galleryService.SetExpiration(ctx, galleryID, expiresAt)
+	g, err := h.galleryService.SetExpiration(ctx, photographerID, galleryID, expiresAt)
 	if err != nil {
 		respondError(w, err)
 		return
@@ -231,6 +317,32 @@ func (h *GalleryHandler) SetExpiration(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, g)
 }
 
+// RestoreGallery starts restoring an archived gallery from cold storage
+func (h *GalleryHandler) RestoreGallery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	galleryID := getURLParam(r, "id")
+
+	g, err := h.galleryService.RestoreGallery(ctx, photographerID, galleryID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	// Originals take hours to come back from cold storage; the gallery stays "restoring" until then
+	status := http.StatusAccepted
+	if g.Status == repository.GalleryStatusActive {
+		status = http.StatusOK
+	}
+	respondJSON(w, status, g)
+}
+
 // Helper functions
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -249,6 +361,26 @@ func respondError(w http.ResponseWriter, err error) {
 	}
 }
 
+// getPageParams reads the size of the page requested and where it starts: the lastKey of the
+// previous page, as JSON
+func getPageParams(r *http.Request, defaultLimit, maxLimit int) (int, map[string]interface{}, error) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, nil, errors.NewBadRequest("Invalid limit")
+		}
+		limit = min(n, maxLimit)
+	}
+	var lastKey map[string]interface{}
+	if v := r.URL.Query().Get("lastKey"); v != "" {
+		if err := json.Unmarshal([]byte(v), &lastKey); err != nil {
+			return 0, nil, errors.NewBadRequest("Invalid lastKey")
+		}
+	}
+	return limit, lastKey, nil
+}
+
 func getURLParam(r *http.Request, param string) string {
 	// This is a placeholder - actual implementation depends on router
 	// For API Gateway Lambda, you'd get this from the path parameters
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/pkg/errors"
 )
 
@@ -24,11 +25,19 @@ func NewPhotoHandler(photoService *photo.Service) *PhotoHandler {
 type GetUploadURLRequest struct {
 	FileName string `json:"fileName"`
 	MimeType string `json:"mimeType"`
+	FileSize int64  `json:"fileSize"`
 }
 
 // GetUploadURL handles POST /galleries/:id/photos/upload-url
 func (h *PhotoHandler) GetUploadURL(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
 	var req GetUploadURLRequest
@@ -45,11 +54,17 @@ func (h *PhotoHandler) GetUploadURL(w http.ResponseWriter, r *http.Request) {
 		respondError(w, errors.NewBadRequest("mimeType is required"))
 		return
 	}
+	if req.FileSize <= 0 {
+		respondError(w, errors.NewBadRequest("fileSize is required"))
+		return
+	}
 
 	resp, err := h.photoService.GenerateUploadURL(ctx, photo.UploadURLRequest{
-		GalleryID: galleryID,
-		FileName:  req.FileName,
-		MimeType:  req.MimeType,
+		PhotographerID: photographerID,
+		GalleryID:      galleryID,
+		FileName:       req.FileName,
+		MimeType:       req.MimeType,
+		Size:           req.FileSize,
 	})
 
 	if err != nil {
@@ -63,12 +78,19 @@ func (h *PhotoHandler) GetUploadURL(w http.ResponseWriter, r *http.Request) {
 // ListPhotos handles GET /galleries/:id/photos
 func (h *PhotoHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
 	limit := 50 // default
 	var lastKey map[string]interface{}
 
-	photos, nextKey, err := h.photoService.ListByGallery(ctx, galleryID, limit, lastKey)
+	photos, nextKey, err := h.photoService.ListByGalleryForPhotographer(ctx, photographerID, galleryID, limit, lastKey)
 	if err != nil {
 		respondError(w, err)
 		return
@@ -85,9 +107,16 @@ func (h *PhotoHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
 // DeletePhoto handles DELETE /galleries/:galleryId/photos/:photoId
 func (h *PhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	photoID := getURLParam(r, "photoId")
 
-	if err := h.photoService.Delete(ctx, photoID); err != nil {
+	if err := h.photoService.Delete(ctx, photographerID, photoID); err != nil {
 		respondError(w, err)
 		return
 	}
@@ -98,15 +127,107 @@ func (h *PhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
 // GetFavorites handles GET /galleries/:id/favorites
 func (h *PhotoHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
 	galleryID := getURLParam(r, "id")
 
-	favorites, err := h.photoService.ListFavoritesByGallery(ctx, galleryID)
+	favorites, err := h.photoService.ListFavoritesByGallery(ctx, photographerID, galleryID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	selections, err := h.photoService.ListSelectionsByGallery(ctx, photographerID, galleryID)
 	if err != nil {
 		respondError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"favorites": favorites,
+		"favorites":  favorites,
+		"selections": selections,
 	})
 }
+
+// ReopenSelection handles POST /galleries/:id/selections/:sessionId/reopen
+func (h *PhotoHandler) ReopenSelection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	galleryID := getURLParam(r, "id")
+	sessionID := getURLParam(r, "sessionId")
+
+	selection, err := h.photoService.ReopenSelection(ctx, photographerID, galleryID, sessionID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, selection)
+}
+
+// FocalPointRequest is the point a photo's thumbnail is cropped around, from 0 to 1 across
+// the photo's width and height
+type FocalPointRequest struct {
+	X *float64 `json:"x"`
+	Y *float64 `json:"y"`
+}
+
+// SetFocalPoint handles PUT /galleries/:galleryId/photos/:photoId/focal-point
+func (h *PhotoHandler) SetFocalPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req FocalPointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+	if req.X == nil || req.Y == nil {
+		respondError(w, errors.NewBadRequest("x and y are required"))
+		return
+	}
+
+	updated, err := h.photoService.SetFocalPoint(ctx, photographerID, getURLParam(r, "galleryId"), getURLParam(r, "photoId"),
+		&repository.FocalPoint{X: *req.X, Y: *req.Y})
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// ClearFocalPoint handles DELETE /galleries/:galleryId/photos/:photoId/focal-point
+func (h *PhotoHandler) ClearFocalPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	updated, err := h.photoService.SetFocalPoint(ctx, photographerID, getURLParam(r, "galleryId"), getURLParam(r, "photoId"), nil)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
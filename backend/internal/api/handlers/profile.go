@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photographer-gallery/backend/internal/domain/profile"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// ProfileHandler handles the photographer's processing profiles
+type ProfileHandler struct {
+	profileService *profile.Service
+}
+
+// NewProfileHandler creates a new processing profile handler
+func NewProfileHandler(profileService *profile.Service) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// SaveProfileRequest represents the body of a profile to create or replace
+type SaveProfileRequest struct {
+	Name    string                      `json:"name"`
+	Steps   []repository.ProcessingStep `json:"steps,omitempty"`
+	Format  string                      `json:"format,omitempty"`
+	Quality int                         `json:"quality,omitempty"`
+}
+
+func (r SaveProfileRequest) toService() profile.SaveProfileRequest {
+	return profile.SaveProfileRequest{Name: r.Name, Steps: r.Steps, Format: r.Format, Quality: r.Quality}
+}
+
+// CreateProfile handles POST /processing-profiles
+func (h *ProfileHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req SaveProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	p, err := h.profileService.Create(ctx, photographerID, req.toService())
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, p)
+}
+
+// ListProfiles handles GET /processing-profiles
+func (h *ProfileHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	profiles, err := h.profileService.List(ctx, photographerID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"profiles": profiles,
+	})
+}
+
+// GetProfile handles GET /processing-profiles/:id
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	p, err := h.profileService.Get(ctx, photographerID, getURLParam(r, "id"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+// UpdateProfile handles PUT /processing-profiles/:id
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req SaveProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	result, err := h.profileService.Update(ctx, photographerID, getURLParam(r, "id"), req.toService())
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// DeleteProfile handles DELETE /processing-profiles/:id
+func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	if err := h.profileService.Delete(ctx, photographerID, getURLParam(r, "id")); err != nil {
+		respondError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
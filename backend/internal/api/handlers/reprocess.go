@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// ReprocessHandler sends photos back through the image processor
+type ReprocessHandler struct {
+	reprocessService *reprocess.Service
+}
+
+// NewReprocessHandler creates a new reprocess handler
+func NewReprocessHandler(reprocessService *reprocess.Service) *ReprocessHandler {
+	return &ReprocessHandler{
+		reprocessService: reprocessService,
+	}
+}
+
+// ReprocessPhoto handles POST /galleries/:galleryId/photos/:photoId/reprocess
+func (h *ReprocessHandler) ReprocessPhoto(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	photo, err := h.reprocessService.ReprocessPhoto(ctx, photographerID, getURLParam(r, "galleryId"), getURLParam(r, "photoId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, photo)
+}
+
+// ReprocessGallery handles POST /galleries/:id/reprocess
+func (h *ReprocessHandler) ReprocessGallery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	progress, err := h.reprocessService.ReprocessGallery(ctx, photographerID, getURLParam(r, "id"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, progress)
+}
+
+// GetGalleryProgress handles GET /galleries/:id/reprocess
+func (h *ReprocessHandler) GetGalleryProgress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	progress, err := h.reprocessService.GalleryProgress(ctx, photographerID, getURLParam(r, "id"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, progress)
+}
+
+// ReprocessAccount handles POST /reprocess
+func (h *ReprocessHandler) ReprocessAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	result, err := h.reprocessService.ReprocessAccount(ctx, photographerID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, result)
+}
+
+// ListFailedPhotos handles GET /photos/failed
+func (h *ReprocessHandler) ListFailedPhotos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	limit, lastKey, err := getPageParams(r, 50, 100)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	photos, nextKey, err := h.reprocessService.ListFailed(ctx, photographerID, limit, lastKey)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"photos":  photos,
+		"lastKey": nextKey,
+	})
+}
+
+// RetryFailedPhoto handles POST /galleries/:galleryId/photos/:photoId/retry
+func (h *ReprocessHandler) RetryFailedPhoto(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	photo, err := h.reprocessService.RetryFailed(ctx, photographerID, getURLParam(r, "galleryId"), getURLParam(r, "photoId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, photo)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"photographer-gallery/backend/internal/domain/trash"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// TrashHandler handles the photographer's trash
+type TrashHandler struct {
+	trashService *trash.Service
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *trash.Service) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// ListTrash handles GET /trash
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	items, err := h.trashService.List(ctx, photographerID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+	})
+}
+
+// RestoreTrashItem handles POST /trash/:id/restore
+func (h *TrashHandler) RestoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	itemID := getURLParam(r, "id")
+
+	item, err := h.trashService.Restore(ctx, photographerID, itemID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// WatermarkHandler handles the photographer's watermark logo
+type WatermarkHandler struct {
+	watermarkService *watermark.Service
+}
+
+// NewWatermarkHandler creates a new watermark handler
+func NewWatermarkHandler(watermarkService *watermark.Service) *WatermarkHandler {
+	return &WatermarkHandler{
+		watermarkService: watermarkService,
+	}
+}
+
+// GetLogoUploadURLRequest represents the logo upload URL request
+type GetLogoUploadURLRequest struct {
+	MimeType string `json:"mimeType"`
+	FileSize int64  `json:"fileSize,omitempty"`
+}
+
+// SetLogoRequest confirms an uploaded logo
+type SetLogoRequest struct {
+	Key        string `json:"key"`
+	Regenerate bool   `json:"regenerate"` // re-watermark photos of galleries using the logo
+}
+
+// GetLogoUploadURL handles POST /watermark/logo/upload-url
+func (h *WatermarkHandler) GetLogoUploadURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req GetLogoUploadURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	resp, err := h.watermarkService.GenerateLogoUploadURL(ctx, photographerID, req.MimeType, req.FileSize)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// SetLogo handles PUT /watermark/logo
+func (h *WatermarkHandler) SetLogo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req SetLogoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+	if req.Key == "" {
+		respondError(w, errors.NewBadRequest("key is required"))
+		return
+	}
+
+	result, err := h.watermarkService.SetLogo(ctx, photographerID, req.Key, req.Regenerate)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// DeleteLogoRequest optionally asks for photos to be re-watermarked without the logo
+type DeleteLogoRequest struct {
+	Regenerate bool `json:"regenerate"`
+}
+
+// DeleteLogo handles DELETE /watermark/logo
+func (h *WatermarkHandler) DeleteLogo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	// The body is optional
+	var req DeleteLogoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	result, err := h.watermarkService.DeleteLogo(ctx, photographerID, req.Regenerate)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
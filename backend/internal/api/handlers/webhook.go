@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photographer-gallery/backend/internal/domain/webhook"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// WebhookHandler handles webhook-related HTTP requests
+type WebhookHandler struct {
+	webhookService *webhook.Service
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the HTTP request body
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes"`
+	Description string   `json:"description,omitempty"`
+}
+
+// UpdateWebhookRequest represents the update request
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty"`
+	EventTypes  *[]string `json:"eventTypes,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// webhookWithSecret exposes the signing secret, which is only returned on create and rotate
+type webhookWithSecret struct {
+	*repository.Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	if req.URL == "" {
+		respondError(w, errors.NewBadRequest("URL is required"))
+		return
+	}
+
+	wh, err := h.webhookService.Create(ctx, photographerID, webhook.CreateWebhookRequest{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, webhookWithSecret{Webhook: wh, Secret: wh.Secret})
+}
+
+// ListWebhooks handles GET /webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	webhooks, err := h.webhookService.List(ctx, photographerID)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks":   webhooks,
+		"eventTypes": webhook.SupportedEventTypes,
+	})
+}
+
+// GetWebhook handles GET /webhooks/:id
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	wh, err := h.webhookService.Get(ctx, photographerID, getURLParam(r, "id"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wh)
+}
+
+// UpdateWebhook handles PUT /webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.NewBadRequest("Invalid request body"))
+		return
+	}
+
+	wh, err := h.webhookService.Update(ctx, photographerID, getURLParam(r, "id"), webhook.UpdateWebhookRequest{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wh)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	if err := h.webhookService.Delete(ctx, photographerID, getURLParam(r, "id")); err != nil {
+		respondError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateSecret handles POST /webhooks/:id/rotate-secret
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	wh, err := h.webhookService.RotateSecret(ctx, photographerID, getURLParam(r, "id"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, webhookWithSecret{Webhook: wh, Secret: wh.Secret})
+}
+
+// ListDeliveries handles GET /webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	limit := 50 // default limit
+	var lastKey map[string]interface{}
+
+	deliveries, nextKey, err := h.webhookService.ListDeliveries(ctx, photographerID, getURLParam(r, "id"), limit, lastKey)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"lastKey":    nextKey,
+	})
+}
+
+// GetDelivery handles GET /webhooks/:id/deliveries/:deliveryId
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(ctx, photographerID, getURLParam(r, "id"), getURLParam(r, "deliveryId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, delivery)
+}
+
+// ReplayDelivery handles POST /webhooks/:id/deliveries/:deliveryId/replay
+func (h *WebhookHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	photographerID, ok := ctx.Value("userID").(string)
+	if !ok {
+		respondError(w, errors.NewUnauthorized("User ID not found"))
+		return
+	}
+
+	delivery, err := h.webhookService.Replay(ctx, photographerID, getURLParam(r, "id"), getURLParam(r, "deliveryId"))
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, delivery)
+}
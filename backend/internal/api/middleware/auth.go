@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"photographer-gallery/backend/internal/domain/auth"
+	"photographer-gallery/backend/internal/domain/gallery"
 	cognitoAuth "photographer-gallery/backend/internal/services/auth"
 	"photographer-gallery/backend/pkg/errors"
 	"photographer-gallery/backend/pkg/logger"
@@ -63,12 +64,14 @@ func (m *AuthMiddleware) VerifyPhotographerToken(ctx context.Context, req events
 // SessionMiddleware verifies client session tokens
 type SessionMiddleware struct {
 	sessionService *auth.SessionService
+	galleryService *gallery.Service
 }
 
 // NewSessionMiddleware creates a new session middleware
-func NewSessionMiddleware(sessionService *auth.SessionService) *SessionMiddleware {
+func NewSessionMiddleware(sessionService *auth.SessionService, galleryService *gallery.Service) *SessionMiddleware {
 	return &SessionMiddleware{
 		sessionService: sessionService,
+		galleryService: galleryService,
 	}
 }
 
@@ -97,6 +100,18 @@ func (m *SessionMiddleware) VerifyClientSession(ctx context.Context, req events.
 		return ctx, err
 	}
 
+	// Sessions outlive the gallery's availability, e.g. once it has been archived
+	if m.galleryService != nil {
+		if err := m.galleryService.CheckClientAccess(ctx, claims.GalleryID); err != nil {
+			logger.Warn("Client session rejected", map[string]interface{}{
+				"sessionId": claims.SessionID,
+				"galleryId": claims.GalleryID,
+				"error":     err.Error(),
+			})
+			return ctx, err
+		}
+	}
+
 	// Add session info to context
 	ctx = context.WithValue(ctx, "galleryID", claims.GalleryID)
 	ctx = context.WithValue(ctx, "sessionID", claims.SessionID)
@@ -13,6 +13,7 @@ type Config struct {
 	S3BucketOriginal    string
 	S3BucketOptimized   string
 	S3BucketThumbnail   string
+	S3BucketDownloads   string
 	CloudFrontDomain    string
 	CloudFrontKeyPairID string
 	CloudFrontKeyPath   string
@@ -27,13 +28,18 @@ type Config struct {
 	AllowedOrigins string
 
 	// Processing
-	SQSQueueURL string
+	SQSQueueURL        string
+	DownloadQueueURL   string
+	ProcessingQueueURL string
 
 	// Session
 	SessionTTLHours int
 
 	// CloudFront signed URL expiration
 	SignedURLExpiration int // hours
+
+	// Trash
+	TrashRetentionDays int
 }
 
 // Load loads configuration from environment variables
@@ -44,6 +50,7 @@ func Load() *Config {
 		S3BucketOriginal:    getEnv("S3_BUCKET_ORIGINAL", ""),
 		S3BucketOptimized:   getEnv("S3_BUCKET_OPTIMIZED", ""),
 		S3BucketThumbnail:   getEnv("S3_BUCKET_THUMBNAIL", ""),
+		S3BucketDownloads:   getEnv("S3_BUCKET_DOWNLOADS", ""),
 		CloudFrontDomain:    getEnv("CLOUDFRONT_DOMAIN", ""),
 		CloudFrontKeyPairID: getEnv("CLOUDFRONT_KEY_PAIR_ID", ""),
 		CloudFrontKeyPath:   getEnv("CLOUDFRONT_KEY_PATH", ""),
@@ -53,8 +60,11 @@ func Load() *Config {
 		APIStage:            getEnv("API_STAGE", "dev"),
 		AllowedOrigins:      getEnv("ALLOWED_ORIGINS", "*"),
 		SQSQueueURL:         getEnv("SQS_QUEUE_URL", ""),
+		DownloadQueueURL:    getEnv("DOWNLOAD_QUEUE_URL", ""),
+		ProcessingQueueURL:  getEnv("PROCESSING_QUEUE_URL", ""),
 		SessionTTLHours:     getEnvAsInt("SESSION_TTL_HOURS", 24),
 		SignedURLExpiration: getEnvAsInt("SIGNED_URL_EXPIRATION", 24),
+		TrashRetentionDays:  getEnvAsInt("TRASH_RETENTION_DAYS", 30),
 	}
 }
 
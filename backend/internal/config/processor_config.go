@@ -4,6 +4,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+
+	"photographer-gallery/backend/internal/services/image"
 )
 
 // ProcessorConfig holds configuration for the photo processor Lambda.
@@ -13,7 +17,12 @@ type ProcessorConfig struct {
 	S3BucketOriginal    string
 	S3BucketOptimized   string
 	S3BucketThumbnail   string
+	ProcessingQueueURL  string // the processor's own queue, where it continues gallery reprocessing jobs
 	APIStage            string
+	Renditions          []image.RenditionSpec // responsive sizes generated for each photo, by width
+	Formats             []string              // formats generated next to each JPEG rendition, e.g. webp
+	MaxPixels           int                   // largest photo processed, in pixels (0 = no limit)
+	Stages              []string              // processing stages photos go through, in order (empty = the default stages)
 }
 
 // ProcessorConfigBuilder builds ProcessorConfig with validation.
@@ -25,7 +34,11 @@ type ProcessorConfigBuilder struct {
 // NewProcessorConfigBuilder creates a new builder with defaults from environment.
 func NewProcessorConfigBuilder() *ProcessorConfigBuilder {
 	return &ProcessorConfigBuilder{
-		config: &ProcessorConfig{},
+		config: &ProcessorConfig{
+			Renditions: image.DefaultRenditions,
+			Formats:    image.DefaultAlternateFormats,
+			MaxPixels:  image.DefaultMaxPixels,
+		},
 		errors: []string{},
 	}
 }
@@ -37,7 +50,40 @@ func (b *ProcessorConfigBuilder) FromEnvironment() *ProcessorConfigBuilder {
 	b.config.S3BucketOriginal = os.Getenv("S3_BUCKET_ORIGINAL")
 	b.config.S3BucketOptimized = os.Getenv("S3_BUCKET_OPTIMIZED")
 	b.config.S3BucketThumbnail = os.Getenv("S3_BUCKET_THUMBNAIL")
+	b.config.ProcessingQueueURL = os.Getenv("PROCESSING_QUEUE_URL")
 	b.config.APIStage = os.Getenv("STAGE")
+	if spec := os.Getenv("RENDITIONS"); spec != "" {
+		renditions, err := image.ParseRenditions(spec)
+		if err != nil {
+			b.errors = append(b.errors, fmt.Sprintf("RENDITIONS is invalid: %v", err))
+		} else {
+			b.config.Renditions = renditions
+		}
+	}
+	// Set but empty turns alternate formats off
+	if spec, ok := os.LookupEnv("OUTPUT_FORMATS"); ok {
+		formats, err := image.ParseFormats(spec)
+		if err != nil {
+			b.errors = append(b.errors, fmt.Sprintf("OUTPUT_FORMATS is invalid: %v", err))
+		} else {
+			b.config.Formats = formats
+		}
+	}
+	if spec := os.Getenv("PROCESSING_STAGES"); spec != "" {
+		for _, name := range strings.Split(spec, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				b.config.Stages = append(b.config.Stages, name)
+			}
+		}
+	}
+	if spec := os.Getenv("MAX_IMAGE_PIXELS"); spec != "" {
+		maxPixels, err := strconv.Atoi(spec)
+		if err != nil || maxPixels < 0 {
+			b.errors = append(b.errors, fmt.Sprintf("MAX_IMAGE_PIXELS is invalid: %q is not a pixel count", spec))
+		} else {
+			b.config.MaxPixels = maxPixels
+		}
+	}
 	return b
 }
 
@@ -67,6 +113,30 @@ func (b *ProcessorConfigBuilder) WithAPIStage(stage string) *ProcessorConfigBuil
 	return b
 }
 
+// WithRenditions sets the responsive sizes generated for each photo.
+func (b *ProcessorConfigBuilder) WithRenditions(renditions []image.RenditionSpec) *ProcessorConfigBuilder {
+	b.config.Renditions = renditions
+	return b
+}
+
+// WithFormats sets the alternate formats generated next to each JPEG rendition.
+func (b *ProcessorConfigBuilder) WithFormats(formats []string) *ProcessorConfigBuilder {
+	b.config.Formats = formats
+	return b
+}
+
+// WithMaxPixels sets the largest photo processed, in pixels (0 = no limit).
+func (b *ProcessorConfigBuilder) WithMaxPixels(maxPixels int) *ProcessorConfigBuilder {
+	b.config.MaxPixels = maxPixels
+	return b
+}
+
+// WithStages sets the processing stages photos go through, in order.
+func (b *ProcessorConfigBuilder) WithStages(stages []string) *ProcessorConfigBuilder {
+	b.config.Stages = stages
+	return b
+}
+
 // Build validates and returns the configuration.
 func (b *ProcessorConfigBuilder) Build() (*ProcessorConfig, error) {
 	b.validate()
@@ -97,6 +167,9 @@ func (b *ProcessorConfigBuilder) validate() {
 	if b.config.APIStage == "" {
 		b.errors = append(b.errors, "STAGE is required")
 	}
+	if len(b.config.Renditions) == 0 {
+		b.errors = append(b.errors, "at least one rendition is required")
+	}
 }
 
 // PhotosTableName returns the photos table name.
@@ -108,3 +181,18 @@ func (c *ProcessorConfig) PhotosTableName() string {
 func (c *ProcessorConfig) GalleriesTableName() string {
 	return fmt.Sprintf("%s-galleries-%s", c.DynamoDBTablePrefix, c.APIStage)
 }
+
+// PhotographersTableName returns the photographers table name.
+func (c *ProcessorConfig) PhotographersTableName() string {
+	return fmt.Sprintf("%s-photographers-%s", c.DynamoDBTablePrefix, c.APIStage)
+}
+
+// ProcessingProfilesTableName returns the processing profiles table name.
+func (c *ProcessorConfig) ProcessingProfilesTableName() string {
+	return fmt.Sprintf("%s-processing-profiles-%s", c.DynamoDBTablePrefix, c.APIStage)
+}
+
+// OutboxTableName returns the event outbox table name.
+func (c *ProcessorConfig) OutboxTableName() string {
+	return fmt.Sprintf("%s-outbox-%s", c.DynamoDBTablePrefix, c.APIStage)
+}
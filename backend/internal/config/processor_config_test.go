@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -85,6 +86,106 @@ func TestProcessorConfigBuilder_PartialConfig(t *testing.T) {
 	}
 }
 
+func TestProcessorConfigBuilder_Renditions(t *testing.T) {
+	os.Setenv("AWS_REGION_NAME", "us-east-1")
+	os.Setenv("DYNAMODB_TABLE_PREFIX", "photo-gallery")
+	os.Setenv("S3_BUCKET_ORIGINAL", "original-bucket")
+	os.Setenv("S3_BUCKET_OPTIMIZED", "optimized-bucket")
+	os.Setenv("S3_BUCKET_THUMBNAIL", "thumbnail-bucket")
+	os.Setenv("STAGE", "dev")
+	defer func() {
+		for _, key := range []string{"AWS_REGION_NAME", "DYNAMODB_TABLE_PREFIX", "S3_BUCKET_ORIGINAL", "S3_BUCKET_OPTIMIZED", "S3_BUCKET_THUMBNAIL", "STAGE", "RENDITIONS"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	cfg, err := NewProcessorConfigBuilder().FromEnvironment().Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(cfg.Renditions) != 5 {
+		t.Errorf("default Renditions = %v, want 5 sizes", cfg.Renditions)
+	}
+
+	os.Setenv("RENDITIONS", "large:2000,small:500")
+	cfg, err = NewProcessorConfigBuilder().FromEnvironment().Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(cfg.Renditions) != 2 || cfg.Renditions[0].Name != "small" || cfg.Renditions[1].Width != 2000 {
+		t.Errorf("Renditions = %v, want small:500 then large:2000", cfg.Renditions)
+	}
+
+	os.Setenv("RENDITIONS", "small:wide")
+	if _, err := NewProcessorConfigBuilder().FromEnvironment().Build(); err == nil || !strings.Contains(err.Error(), "RENDITIONS") {
+		t.Errorf("Build() error = %v, want RENDITIONS error", err)
+	}
+}
+
+func TestProcessorConfigBuilder_Formats(t *testing.T) {
+	b := NewProcessorConfigBuilder().
+		WithAWSRegion("us-east-1").
+		WithDynamoDBTablePrefix("photo-gallery").
+		WithS3Buckets("original-bucket", "optimized-bucket", "thumbnail-bucket").
+		WithAPIStage("dev")
+	cfg, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(cfg.Formats) != 1 || cfg.Formats[0] != "webp" {
+		t.Errorf("default Formats = %v, want [webp]", cfg.Formats)
+	}
+
+	defer os.Unsetenv("OUTPUT_FORMATS")
+	os.Setenv("OUTPUT_FORMATS", "AVIF, webp")
+	cfg = NewProcessorConfigBuilder().FromEnvironment().config
+	if len(cfg.Formats) != 2 || cfg.Formats[0] != "avif" || cfg.Formats[1] != "webp" {
+		t.Errorf("Formats = %v, want [avif webp]", cfg.Formats)
+	}
+
+	os.Setenv("OUTPUT_FORMATS", "")
+	if cfg := NewProcessorConfigBuilder().FromEnvironment().config; len(cfg.Formats) != 0 {
+		t.Errorf("empty OUTPUT_FORMATS should disable alternates, got %v", cfg.Formats)
+	}
+
+	os.Setenv("OUTPUT_FORMATS", "gif")
+	if b := NewProcessorConfigBuilder().FromEnvironment(); len(b.errors) == 0 || !strings.Contains(b.errors[0], "OUTPUT_FORMATS") {
+		t.Errorf("errors = %v, want OUTPUT_FORMATS error", b.errors)
+	}
+}
+
+func TestProcessorConfigBuilder_MaxPixels(t *testing.T) {
+	if cfg := NewProcessorConfigBuilder().FromEnvironment().config; cfg.MaxPixels != 100_000_000 {
+		t.Errorf("default MaxPixels = %d, want 100 MP", cfg.MaxPixels)
+	}
+
+	defer os.Unsetenv("MAX_IMAGE_PIXELS")
+	os.Setenv("MAX_IMAGE_PIXELS", "60000000")
+	if cfg := NewProcessorConfigBuilder().FromEnvironment().config; cfg.MaxPixels != 60_000_000 {
+		t.Errorf("MaxPixels = %d, want 60 MP", cfg.MaxPixels)
+	}
+
+	for _, spec := range []string{"lots", "-1"} {
+		os.Setenv("MAX_IMAGE_PIXELS", spec)
+		if b := NewProcessorConfigBuilder().FromEnvironment(); len(b.errors) == 0 || !strings.Contains(b.errors[0], "MAX_IMAGE_PIXELS") {
+			t.Errorf("MAX_IMAGE_PIXELS=%s: errors = %v, want MAX_IMAGE_PIXELS error", spec, b.errors)
+		}
+	}
+}
+
+func TestProcessorConfigBuilder_Stages(t *testing.T) {
+	if cfg := NewProcessorConfigBuilder().FromEnvironment().config; cfg.Stages != nil {
+		t.Errorf("default Stages = %v, want none so the pipeline's defaults apply", cfg.Stages)
+	}
+
+	defer os.Unsetenv("PROCESSING_STAGES")
+	os.Setenv("PROCESSING_STAGES", "metadata, thumbnail,,optimized")
+	cfg := NewProcessorConfigBuilder().FromEnvironment().config
+	if want := []string{"metadata", "thumbnail", "optimized"}; !reflect.DeepEqual(cfg.Stages, want) {
+		t.Errorf("Stages = %v, want %v", cfg.Stages, want)
+	}
+}
+
 func TestProcessorConfig_TableNames(t *testing.T) {
 	cfg := &ProcessorConfig{
 		DynamoDBTablePrefix: "photo-gallery",
@@ -97,4 +198,10 @@ func TestProcessorConfig_TableNames(t *testing.T) {
 	if cfg.GalleriesTableName() != "photo-gallery-galleries-dev" {
 		t.Errorf("GalleriesTableName() = %v, want photo-gallery-galleries-dev", cfg.GalleriesTableName())
 	}
+	if cfg.OutboxTableName() != "photo-gallery-outbox-dev" {
+		t.Errorf("OutboxTableName() = %v, want photo-gallery-outbox-dev", cfg.OutboxTableName())
+	}
+	if cfg.PhotographersTableName() != "photo-gallery-photographers-dev" {
+		t.Errorf("PhotographersTableName() = %v, want photo-gallery-photographers-dev", cfg.PhotographersTableName())
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
 )
 
 // PhotographerRepository defines the interface for photographer data access
@@ -25,6 +26,7 @@ type PhotographerRepository interface {
 type Service struct {
 	photographerRepo PhotographerRepository
 	baseDomain       string // e.g., "photographergallery.com"
+	plans            *plan.Service
 }
 
 // NewService creates a new domain service
@@ -35,6 +37,12 @@ func NewService(photographerRepo PhotographerRepository, baseDomain string) *Ser
 	}
 }
 
+// WithPlans restricts custom domains to photographers whose plan includes them
+func (s *Service) WithPlans(plans *plan.Service) *Service {
+	s.plans = plans
+	return s
+}
+
 // Validation patterns
 var (
 	subdomainPattern    = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{1,61}[a-z0-9])?$`)
@@ -114,6 +122,14 @@ func (s *Service) RequestSubdomain(ctx context.Context, userID, subdomain string
 
 // RequestCustomDomain initiates the custom domain setup process
 func (s *Service) RequestCustomDomain(ctx context.Context, userID, domain string) (*DomainConfig, error) {
+	entitlements, err := s.plans.For(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := entitlements.CheckCustomDomain(); err != nil {
+		return nil, err
+	}
+
 	// Normalize domain
 	domain = strings.ToLower(strings.TrimSpace(domain))
 	domain = strings.TrimPrefix(domain, "http://")
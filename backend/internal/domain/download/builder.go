@@ -0,0 +1,383 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/storage"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+const (
+	// defaultPartSize is the buffered archive size uploaded as one multipart part.
+	// S3 requires at least 5 MiB for every part but the last.
+	defaultPartSize = 16 << 20
+
+	// defaultTimeMargin is how long before the invocation deadline the worker stops
+	// adding photos and hands the job to the next invocation.
+	defaultTimeMargin = 2 * time.Minute
+
+	// photoPageSize is the page size used when listing a gallery's photos.
+	photoPageSize = 100
+)
+
+// archiveItem is a photo to add to the archive, resolved when the job starts so every
+// invocation works through the same list.
+type archiveItem struct {
+	Key      string    `json:"key"`
+	Name     string    `json:"name"`
+	Modified time.Time `json:"modified"`
+}
+
+// manifest is the checkpoint of a job in progress. It is saved next to the archive after
+// each uploaded part; everything written after the last checkpoint is written again by
+// the next invocation.
+type manifest struct {
+	Items    []archiveItem           `json:"items"`
+	Next     int                     `json:"next"` // index of the first item not yet in an uploaded part
+	UploadID string                  `json:"uploadId"`
+	Parts    []storage.CompletedPart `json:"parts"`
+	Offset   uint64                  `json:"offset"`
+	Entries  []zipEntry              `json:"entries"`
+}
+
+// Run builds a job's archive until it is complete or the invocation is about to run out
+// of time, in which case progress is checkpointed and the job is queued again. Jobs that
+// fail are marked failed rather than returning an error, so they are not retried blindly;
+// an error is returned only when the job could not be loaded or queued.
+func (s *Service) Run(ctx context.Context, jobID string) error {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get download job: %w", err)
+	}
+	if job == nil {
+		logger.Warn("Download job not found", map[string]interface{}{"jobId": jobID})
+		return nil
+	}
+	if job.Status == StatusCompleted || job.Status == StatusFailed {
+		return nil
+	}
+
+	var m *manifest
+	if job.Status == StatusPending {
+		m, err = s.prepare(ctx, job)
+	} else {
+		m, err = s.loadManifest(ctx, job)
+	}
+	if err != nil {
+		s.fail(ctx, job, m, err.Error())
+		return nil
+	}
+
+	done, err := s.build(ctx, job, m)
+	if err != nil {
+		s.fail(ctx, job, m, err.Error())
+		return nil
+	}
+	if !done {
+		logger.Info("Download job checkpointed", map[string]interface{}{
+			"jobId": job.JobID, "processed": job.ProcessedPhotos, "total": job.TotalPhotos,
+		})
+		return s.queue.Enqueue(ctx, job.JobID)
+	}
+
+	return s.complete(ctx, job, m)
+}
+
+// prepare resolves the photos to archive, starts the multipart upload and saves the
+// first checkpoint.
+func (s *Service) prepare(ctx context.Context, job *repository.DownloadJob) (*manifest, error) {
+	gallery, err := s.galleryRepo.GetByID(ctx, job.GalleryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gallery: %w", err)
+	}
+	if gallery == nil {
+		return nil, fmt.Errorf("gallery no longer exists")
+	}
+	// The policy may have changed since the job was requested
+	if err := checkPolicy(gallery, job.Variant); err != nil {
+		return nil, err
+	}
+
+	photos, err := s.listPhotos(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	items := archiveItems(photos, job.Variant)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no photos are ready for download")
+	}
+
+	uploadID, err := s.store.CreateMultipartUpload(ctx, s.buckets.Archive, job.ArchiveKey, "application/zip")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{Items: items, UploadID: uploadID}
+	if err := s.saveManifest(ctx, job, m); err != nil {
+		return m, err
+	}
+
+	job.Status = StatusRunning
+	job.TotalPhotos = len(items)
+	job.UpdatedAt = s.now()
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return m, fmt.Errorf("failed to update download job: %w", err)
+	}
+	return m, nil
+}
+
+// listPhotos returns the photos in the job's scope.
+func (s *Service) listPhotos(ctx context.Context, job *repository.DownloadJob) ([]*repository.Photo, error) {
+	if job.Scope == ScopeFavorites {
+		favorites, err := s.favoriteRepo.ListBySession(ctx, job.GalleryID, job.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list favorites: %w", err)
+		}
+		// A photo kept in several lists is archived once
+		seen := make(map[string]bool, len(favorites))
+		photos := make([]*repository.Photo, 0, len(favorites))
+		for _, favorite := range favorites {
+			if seen[favorite.PhotoID] {
+				continue
+			}
+			seen[favorite.PhotoID] = true
+			photo, err := s.photoRepo.GetByID(ctx, favorite.PhotoID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get photo: %w", err)
+			}
+			if photo != nil && photo.GalleryID == job.GalleryID {
+				photos = append(photos, photo)
+			}
+		}
+		return photos, nil
+	}
+
+	var photos []*repository.Photo
+	var lastKey map[string]interface{}
+	for {
+		page, nextKey, err := s.photoRepo.ListByGallery(ctx, job.GalleryID, photoPageSize, lastKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list photos: %w", err)
+		}
+		photos = append(photos, page...)
+		if len(nextKey) == 0 {
+			return photos, nil
+		}
+		lastKey = nextKey
+	}
+}
+
+// build streams the remaining photos into the archive, uploading a part whenever enough
+// has been buffered. It reports whether every photo has been written.
+func (s *Service) build(ctx context.Context, job *repository.DownloadJob, m *manifest) (bool, error) {
+	bucket := s.buckets.Optimized
+	if job.Variant == VariantOriginal {
+		bucket = s.buckets.Original
+	}
+
+	var buf bytes.Buffer
+	zw := newZipWriter(&buf, m.Offset, m.Entries)
+
+	for next := m.Next; next < len(m.Items); {
+		if s.outOfTime(ctx) {
+			return false, nil
+		}
+
+		item := m.Items[next]
+		body, err := s.store.GetObject(ctx, bucket, item.Key)
+		if err != nil {
+			return false, err
+		}
+		_, err = zw.WriteFile(item.Name, item.Modified, body)
+		body.Close()
+		if err != nil {
+			return false, err
+		}
+		next++
+
+		// Parts end on file boundaries so each checkpoint resumes with a whole photo
+		if buf.Len() >= s.partSize {
+			if err := s.uploadPart(ctx, job, m, buf.Bytes()); err != nil {
+				return false, err
+			}
+			buf.Reset()
+			m.Next = next
+			m.Offset = zw.Offset()
+			m.Entries = zw.Entries()
+			if err := s.checkpoint(ctx, job, m); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// Everything is written: the buffer plus the central directory form the last part
+	if err := zw.Close(); err != nil {
+		return false, err
+	}
+	if err := s.uploadPart(ctx, job, m, buf.Bytes()); err != nil {
+		return false, err
+	}
+	m.Next = len(m.Items)
+	m.Offset = zw.Offset()
+	m.Entries = zw.Entries()
+	return true, nil
+}
+
+// uploadPart uploads the next part of the archive and records it in the manifest.
+func (s *Service) uploadPart(ctx context.Context, job *repository.DownloadJob, m *manifest, body []byte) error {
+	partNumber := int32(len(m.Parts) + 1)
+	etag, err := s.store.UploadPart(ctx, s.buckets.Archive, job.ArchiveKey, m.UploadID, partNumber, body)
+	if err != nil {
+		return err
+	}
+	m.Parts = append(m.Parts, storage.CompletedPart{PartNumber: partNumber, ETag: etag})
+	return nil
+}
+
+// checkpoint saves the manifest and publishes progress on the job.
+func (s *Service) checkpoint(ctx context.Context, job *repository.DownloadJob, m *manifest) error {
+	if err := s.saveManifest(ctx, job, m); err != nil {
+		return err
+	}
+	job.ProcessedPhotos = m.Next
+	job.UpdatedAt = s.now()
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		// Progress is informational; the manifest is the source of truth
+		logger.Warn("Failed to update download progress", map[string]interface{}{"jobId": job.JobID, "error": err.Error()})
+	}
+	return nil
+}
+
+// complete assembles the uploaded parts and marks the job completed.
+func (s *Service) complete(ctx context.Context, job *repository.DownloadJob, m *manifest) error {
+	if err := s.store.CompleteMultipartUpload(ctx, s.buckets.Archive, job.ArchiveKey, m.UploadID, m.Parts); err != nil {
+		s.fail(ctx, job, m, err.Error())
+		return nil
+	}
+
+	now := s.now()
+	job.Status = StatusCompleted
+	job.ProcessedPhotos = len(m.Items)
+	job.ArchiveSize = int64(m.Offset)
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to update download job: %w", err)
+	}
+
+	s.deleteManifest(ctx, job)
+
+	logger.Info("Download job completed", map[string]interface{}{
+		"jobId": job.JobID, "photos": job.ProcessedPhotos, "size": job.ArchiveSize,
+	})
+	return nil
+}
+
+// fail marks a job failed and discards its partial archive.
+func (s *Service) fail(ctx context.Context, job *repository.DownloadJob, m *manifest, reason string) {
+	logger.Error("Download job failed", map[string]interface{}{"jobId": job.JobID, "error": reason})
+
+	if m != nil && m.UploadID != "" {
+		if err := s.store.AbortMultipartUpload(ctx, s.buckets.Archive, job.ArchiveKey, m.UploadID); err != nil {
+			logger.Warn("Failed to abort archive upload", map[string]interface{}{"jobId": job.JobID, "error": err.Error()})
+		}
+		s.deleteManifest(ctx, job)
+	}
+
+	now := s.now()
+	job.Status = StatusFailed
+	job.LastError = reason
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		logger.Error("Failed to mark download job failed", map[string]interface{}{"jobId": job.JobID, "error": err.Error()})
+	}
+}
+
+// outOfTime reports whether the invocation is too close to its deadline to add another photo.
+func (s *Service) outOfTime(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && deadline.Sub(s.now()) < s.timeMargin
+}
+
+func manifestKey(job *repository.DownloadJob) string {
+	return strings.TrimSuffix(job.ArchiveKey, ".zip") + ".manifest.json"
+}
+
+func (s *Service) saveManifest(ctx context.Context, job *repository.DownloadJob, m *manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode download manifest: %w", err)
+	}
+	return s.store.PutObject(ctx, s.buckets.Archive, manifestKey(job), data, "application/json")
+}
+
+func (s *Service) loadManifest(ctx context.Context, job *repository.DownloadJob) (*manifest, error) {
+	body, err := s.store.GetObject(ctx, s.buckets.Archive, manifestKey(job))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode download manifest: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *Service) deleteManifest(ctx context.Context, job *repository.DownloadJob) {
+	if err := s.store.DeleteObject(ctx, s.buckets.Archive, manifestKey(job)); err != nil {
+		logger.Warn("Failed to delete download manifest", map[string]interface{}{"jobId": job.JobID, "error": err.Error()})
+	}
+}
+
+// archiveItems picks the object to archive for each photo and gives it a unique file name.
+// Photos without the requested rendition (e.g. still processing) are skipped.
+func archiveItems(photos []*repository.Photo, variant string) []archiveItem {
+	items := make([]archiveItem, 0, len(photos))
+	used := make(map[string]int)
+
+	for _, photo := range photos {
+		key := photo.OptimizedKey
+		if variant == VariantOriginal {
+			key = photo.OriginalKey
+		}
+		if key == "" {
+			continue
+		}
+
+		name := path.Base(photo.FileName)
+		if name == "." || name == "/" {
+			name = photo.PhotoID
+		}
+		ext := path.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		// Optimized renditions may be re-encoded, so take the extension from the object
+		if variant == VariantOptimized && path.Ext(key) != "" {
+			ext = path.Ext(key)
+		}
+
+		name = base + ext
+		for used[strings.ToLower(name)] > 0 {
+			used[strings.ToLower(base+ext)]++
+			name = fmt.Sprintf("%s (%d)%s", base, used[strings.ToLower(base+ext)], ext)
+		}
+		used[strings.ToLower(name)]++
+
+		items = append(items, archiveItem{Key: key, Name: name, Modified: photo.UploadedAt})
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// JobMessage is the SQS message body that asks the worker to run a job.
+type JobMessage struct {
+	JobID string `json:"jobId"`
+}
+
+// SQSAPI defines the SQS operations used to queue jobs.
+type SQSAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQSQueue queues download jobs on an SQS queue consumed by the download worker.
+type SQSQueue struct {
+	client   SQSAPI
+	queueURL string
+}
+
+// NewSQSQueue creates a queue that sends job messages to queueURL.
+func NewSQSQueue(client SQSAPI, queueURL string) *SQSQueue {
+	return &SQSQueue{client: client, queueURL: queueURL}
+}
+
+// Enqueue sends a message asking the worker to run the job.
+func (q *SQSQueue) Enqueue(ctx context.Context, jobID string) error {
+	body, err := json.Marshal(JobMessage{JobID: jobID})
+	if err != nil {
+		return fmt.Errorf("failed to encode download job message: %w", err)
+	}
+
+	_, err = q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(string(body)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue download job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package download builds ZIP archives of a gallery's photos for clients in the background.
+package download
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/storage"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
+)
+
+// Job scopes
+const (
+	ScopeGallery   = "gallery"
+	ScopeFavorites = "favorites"
+)
+
+// Job variants
+const (
+	VariantOptimized = "optimized"
+	VariantOriginal  = "original"
+)
+
+// Job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// ObjectStore is the subset of storage operations needed to read photos and write archives.
+type ObjectStore interface {
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error
+	DeleteObject(ctx context.Context, bucket, key string) error
+	CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error)
+	UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int32, body []byte) (string, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []storage.CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+	GenerateDownloadURL(ctx context.Context, key string, bucket string, filename string) (string, error)
+}
+
+// Queue schedules a job to be built, or continued, by the download worker.
+type Queue interface {
+	Enqueue(ctx context.Context, jobID string) error
+}
+
+// Buckets names the buckets photos are read from and archives are written to.
+type Buckets struct {
+	Original  string
+	Optimized string
+	Archive   string
+}
+
+// Service handles download jobs.
+type Service struct {
+	jobRepo      repository.DownloadJobRepository
+	galleryRepo  repository.GalleryRepository
+	photoRepo    repository.PhotoRepository
+	favoriteRepo repository.FavoriteRepository
+	store        ObjectStore
+	queue        Queue
+	buckets      Buckets
+
+	partSize   int
+	timeMargin time.Duration
+	now        func() time.Time
+}
+
+// NewService creates a new download service.
+func NewService(
+	jobRepo repository.DownloadJobRepository,
+	galleryRepo repository.GalleryRepository,
+	photoRepo repository.PhotoRepository,
+	favoriteRepo repository.FavoriteRepository,
+	store ObjectStore,
+	queue Queue,
+	buckets Buckets,
+) *Service {
+	return &Service{
+		jobRepo:      jobRepo,
+		galleryRepo:  galleryRepo,
+		photoRepo:    photoRepo,
+		favoriteRepo: favoriteRepo,
+		store:        store,
+		queue:        queue,
+		buckets:      buckets,
+		partSize:     defaultPartSize,
+		timeMargin:   defaultTimeMargin,
+		now:          time.Now,
+	}
+}
+
+// StartRequest represents a client's request for an archive.
+type StartRequest struct {
+	Scope   string
+	Variant string
+}
+
+// JobStatus is a job as reported to the client, with a download link once it completes.
+type JobStatus struct {
+	*repository.DownloadJob
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+// Start creates a download job for the session and queues it for the worker.
+func (s *Service) Start(ctx context.Context, galleryID, sessionID string, req StartRequest) (*repository.DownloadJob, error) {
+	if req.Scope == "" {
+		req.Scope = ScopeGallery
+	}
+	if req.Variant == "" {
+		req.Variant = VariantOptimized
+	}
+	if req.Scope != ScopeGallery && req.Scope != ScopeFavorites {
+		return nil, errors.NewBadRequest("Invalid scope. Supported: gallery, favorites")
+	}
+	if req.Variant != VariantOptimized && req.Variant != VariantOriginal {
+		return nil, errors.NewBadRequest("Invalid variant. Supported: optimized, original")
+	}
+
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery == nil {
+		return nil, errors.NewNotFound("Gallery")
+	}
+	if err := checkPolicy(gallery, req.Variant); err != nil {
+		return nil, err
+	}
+
+	switch req.Scope {
+	case ScopeGallery:
+		if gallery.PhotoCount == 0 {
+			return nil, errors.NewBadRequest("Gallery has no photos to download")
+		}
+	case ScopeFavorites:
+		favorites, err := s.favoriteRepo.ListBySession(ctx, galleryID, sessionID)
+		if err != nil {
+			return nil, errors.Wrap(err, 500, "Failed to list favorites")
+		}
+		if len(favorites) == 0 {
+			return nil, errors.NewBadRequest("No favorites to download")
+		}
+	}
+
+	now := s.now()
+	jobID := utils.GenerateID("dl")
+	job := &repository.DownloadJob{
+		JobID:       jobID,
+		GalleryID:   galleryID,
+		SessionID:   sessionID,
+		Scope:       req.Scope,
+		Variant:     req.Variant,
+		Status:      StatusPending,
+		ArchiveKey:  "downloads/" + galleryID + "/" + jobID + ".zip",
+		ArchiveName: archiveName(gallery, req.Scope),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to create download job")
+	}
+
+	if err := s.queue.Enqueue(ctx, job.JobID); err != nil {
+		s.fail(ctx, job, nil, "Failed to queue download")
+		return nil, errors.Wrap(err, 500, "Failed to queue download")
+	}
+
+	logger.Info("Download job started", map[string]interface{}{
+		"jobId": job.JobID, "galleryId": galleryID, "scope": job.Scope, "variant": job.Variant,
+	})
+	return job, nil
+}
+
+// Get returns a session's download job. Completed jobs include a presigned link to the archive.
+func (s *Service) Get(ctx context.Context, galleryID, sessionID, jobID string) (*JobStatus, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get download job")
+	}
+	if job == nil || job.GalleryID != galleryID || job.SessionID != sessionID {
+		return nil, errors.NewNotFound("Download")
+	}
+
+	status := &JobStatus{DownloadJob: job}
+	if job.Status == StatusCompleted {
+		url, err := s.store.GenerateDownloadURL(ctx, job.ArchiveKey, s.buckets.Archive, job.ArchiveName)
+		if err != nil {
+			return nil, errors.Wrap(err, 500, "Failed to generate download URL")
+		}
+		status.DownloadURL = url
+	}
+	return status, nil
+}
+
+// checkPolicy enforces the gallery's download policy for the requested variant.
+func checkPolicy(gallery *repository.Gallery, variant string) error {
+	switch gallery.DownloadPolicy {
+	case repository.DownloadPolicyNone:
+		return errors.New(403, "Downloads are disabled for this gallery")
+	case repository.DownloadPolicyOriginals:
+		return nil
+	}
+	if variant == VariantOriginal {
+		return errors.New(403, "Original downloads are not enabled for this gallery")
+	}
+	return nil
+}
+
+// archiveName is the file name offered to the client when downloading the archive.
+func archiveName(gallery *repository.Gallery, scope string) string {
+	name := gallery.CustomURL
+	if name == "" {
+		name = gallery.GalleryID
+	}
+	if scope == ScopeFavorites {
+		name += "-favorites"
+	}
+	return name + ".zip"
+}
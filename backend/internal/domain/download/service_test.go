@@ -0,0 +1,479 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/storage"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	appErrors "photographer-gallery/backend/pkg/errors"
+)
+
+var testBuckets = Buckets{Original: "originals", Optimized: "optimized", Archive: "archives"}
+
+// memoryStore is an in-memory ObjectStore that assembles multipart uploads like S3.
+type memoryStore struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	uploads  map[string]map[int32][]byte
+	aborted  []string
+	uploadN  int
+	onGet    func()
+	getCalls int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}, uploads: map[string]map[int32][]byte{}}
+}
+
+func (m *memoryStore) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	m.getCalls++
+	data, ok := m.objects[bucket+"/"+key]
+	onGet := m.onGet
+	m.mu.Unlock()
+	if onGet != nil {
+		onGet()
+	}
+	if !ok {
+		return nil, fmt.Errorf("NoSuchKey: %s/%s", bucket, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStore) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[bucket+"/"+key] = append([]byte{}, body...)
+	return nil
+}
+
+func (m *memoryStore) DeleteObject(ctx context.Context, bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, bucket+"/"+key)
+	return nil
+}
+
+func (m *memoryStore) CreateMultipartUpload(ctx context.Context, bucket, key, contentType string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploadN++
+	uploadID := fmt.Sprintf("upload-%d", m.uploadN)
+	m.uploads[uploadID] = map[int32][]byte{}
+	return uploadID, nil
+}
+
+func (m *memoryStore) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int32, body []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	parts, ok := m.uploads[uploadID]
+	if !ok {
+		return "", fmt.Errorf("NoSuchUpload: %s", uploadID)
+	}
+	parts[partNumber] = append([]byte{}, body...)
+	return fmt.Sprintf("etag-%d", partNumber), nil
+}
+
+func (m *memoryStore) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []storage.CompletedPart) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	uploaded, ok := m.uploads[uploadID]
+	if !ok {
+		return fmt.Errorf("NoSuchUpload: %s", uploadID)
+	}
+	var object []byte
+	for _, part := range parts {
+		object = append(object, uploaded[part.PartNumber]...)
+	}
+	m.objects[bucket+"/"+key] = object
+	delete(m.uploads, uploadID)
+	return nil
+}
+
+func (m *memoryStore) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.uploads, uploadID)
+	m.aborted = append(m.aborted, uploadID)
+	return nil
+}
+
+func (m *memoryStore) GenerateDownloadURL(ctx context.Context, key string, bucket string, filename string) (string, error) {
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?filename=%s", bucket, key, filename), nil
+}
+
+func (m *memoryStore) object(bucket, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[bucket+"/"+key]
+	return data, ok
+}
+
+// recordingQueue remembers queued job IDs.
+type recordingQueue struct {
+	mu   sync.Mutex
+	jobs []string
+	err  error
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, jobID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		return q.err
+	}
+	q.jobs = append(q.jobs, jobID)
+	return nil
+}
+
+func (q *recordingQueue) take() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := q.jobs
+	q.jobs = nil
+	return jobs
+}
+
+// newTestService creates a service and a gallery holding the given photo files,
+// each stored in both the original and optimized buckets.
+func newTestService(policy string, files ...string) (*Service, *repository.Gallery, *memoryStore, *recordingQueue) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	store := newMemoryStore()
+	queue := &recordingQueue{}
+	service := NewService(mocks.NewMockDownloadJobRepository(), galleryRepo, photoRepo, mocks.NewMockFavoriteRepository(), store, queue, testBuckets)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{
+		CustomURL:      "smith-wedding",
+		PhotoCount:     len(files),
+		DownloadPolicy: policy,
+	})
+	galleryRepo.AddGallery(g)
+
+	for i, file := range files {
+		photo := fixtures.NewPhoto(fixtures.PhotoOptions{
+			PhotoID:   fmt.Sprintf("photo_%03d", i),
+			GalleryID: g.GalleryID,
+			FileName:  file,
+		})
+		photoRepo.AddPhoto(photo)
+		store.objects[testBuckets.Original+"/"+photo.OriginalKey] = []byte("original:" + photo.PhotoID)
+		store.objects[testBuckets.Optimized+"/"+photo.OptimizedKey] = []byte("optimized:" + photo.PhotoID)
+	}
+	return service, g, store, queue
+}
+
+// drain runs queued jobs until the queue is empty, as the worker would.
+func drain(t *testing.T, service *Service, queue *recordingQueue, ctxFn func() (context.Context, context.CancelFunc)) int {
+	t.Helper()
+	runs := 0
+	for jobs := queue.take(); len(jobs) > 0; jobs = queue.take() {
+		for _, jobID := range jobs {
+			ctx, cancel := ctxFn()
+			if err := service.Run(ctx, jobID); err != nil {
+				cancel()
+				t.Fatalf("Run() error: %v", err)
+			}
+			cancel()
+			runs++
+			if runs > 100 {
+				t.Fatal("job did not finish")
+			}
+		}
+	}
+	return runs
+}
+
+func background() (context.Context, context.CancelFunc) {
+	return context.WithCancel(context.Background())
+}
+
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*appErrors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != code {
+		t.Errorf("error code = %d, want %d", appErr.Code, code)
+	}
+}
+
+func TestStartValidatesRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		galleryID string
+		req       StartRequest
+		code      int
+	}{
+		{"unknown scope", "", StartRequest{Scope: "everything"}, 400},
+		{"unknown variant", "", StartRequest{Variant: "raw"}, 400},
+		{"missing gallery", "gal_missing", StartRequest{}, 404},
+		// Favorites scope needs at least one favorite
+		{"no favorites", "", StartRequest{Scope: ScopeFavorites}, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, g, _, _ := newTestService("", "a.jpg")
+			galleryID := tt.galleryID
+			if galleryID == "" {
+				galleryID = g.GalleryID
+			}
+			_, err := service.Start(context.Background(), galleryID, "session_1", tt.req)
+			assertErrorCode(t, err, tt.code)
+		})
+	}
+}
+
+func TestStartRespectsDownloadPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		variant string
+		code    int
+	}{
+		{"", VariantOptimized, 0},
+		{"", VariantOriginal, 403},
+		{repository.DownloadPolicyOptimized, VariantOriginal, 403},
+		{repository.DownloadPolicyOriginals, VariantOriginal, 0},
+		{repository.DownloadPolicyNone, VariantOptimized, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+tt.variant, func(t *testing.T) {
+			service, g, _, queue := newTestService(tt.policy, "a.jpg")
+			job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{Variant: tt.variant})
+			if tt.code != 0 {
+				assertErrorCode(t, err, tt.code)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Start() error: %v", err)
+			}
+			if job.Status != StatusPending {
+				t.Errorf("Status = %v, want %v", job.Status, StatusPending)
+			}
+			if queued := queue.take(); len(queued) != 1 || queued[0] != job.JobID {
+				t.Errorf("queued = %v, want [%s]", queued, job.JobID)
+			}
+		})
+	}
+}
+
+func TestStartFailsJobWhenQueueUnavailable(t *testing.T) {
+	service, g, _, queue := newTestService("", "a.jpg")
+	queue.err = errors.New("queue unavailable")
+
+	_, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{})
+	assertErrorCode(t, err, 500)
+}
+
+func TestRunBuildsGalleryArchive(t *testing.T) {
+	service, g, store, queue := newTestService("", "a.jpg", "b.png", "a.jpg")
+
+	job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{})
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	drain(t, service, queue, background)
+
+	status, err := service.Get(context.Background(), g.GalleryID, "session_1", job.JobID)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if status.Status != StatusCompleted {
+		t.Fatalf("Status = %v (%s), want %v", status.Status, status.LastError, StatusCompleted)
+	}
+	if status.ProcessedPhotos != 3 || status.TotalPhotos != 3 {
+		t.Errorf("progress = %d/%d, want 3/3", status.ProcessedPhotos, status.TotalPhotos)
+	}
+	if !strings.Contains(status.DownloadURL, "smith-wedding.zip") {
+		t.Errorf("DownloadURL = %v, want archive name smith-wedding.zip", status.DownloadURL)
+	}
+
+	data, ok := store.object(testBuckets.Archive, job.ArchiveKey)
+	if !ok {
+		t.Fatal("archive was not written")
+	}
+	if status.ArchiveSize != int64(len(data)) {
+		t.Errorf("ArchiveSize = %d, want %d", status.ArchiveSize, len(data))
+	}
+
+	files := readArchive(t, data)
+	names := make([]string, 0, len(files))
+	for name, content := range files {
+		names = append(names, name)
+		if !strings.HasPrefix(content, "optimized:") {
+			t.Errorf("%s content = %q, want optimized rendition", name, content)
+		}
+	}
+	sort.Strings(names)
+	want := []string{"a (2).jpg", "a.jpg", "b.png"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("archive files = %v, want %v", names, want)
+	}
+
+	// The checkpoint is cleaned up once the archive is complete
+	if _, ok := store.object(testBuckets.Archive, manifestKey(job)); ok {
+		t.Error("manifest should be deleted after completion")
+	}
+}
+
+func TestRunFavoritesOriginals(t *testing.T) {
+	service, g, store, queue := newTestService(repository.DownloadPolicyOriginals, "a.jpg", "b.jpg", "c.jpg")
+	service.favoriteRepo.Create(context.Background(), &repository.Favorite{
+		GalleryID: g.GalleryID, SessionID: "session_1", PhotoID: "photo_001",
+	})
+	// Another session's favorites are not included
+	service.favoriteRepo.Create(context.Background(), &repository.Favorite{
+		GalleryID: g.GalleryID, SessionID: "session_2", PhotoID: "photo_002",
+	})
+
+	job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{
+		Scope: ScopeFavorites, Variant: VariantOriginal,
+	})
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	drain(t, service, queue, background)
+
+	data, _ := store.object(testBuckets.Archive, job.ArchiveKey)
+	files := readArchive(t, data)
+	if len(files) != 1 || files["b.jpg"] != "original:photo_001" {
+		t.Errorf("archive files = %v, want only b.jpg original", files)
+	}
+	if job.ArchiveName != "smith-wedding-favorites.zip" {
+		t.Errorf("ArchiveName = %v, want smith-wedding-favorites.zip", job.ArchiveName)
+	}
+}
+
+func TestRunResumesAcrossInvocations(t *testing.T) {
+	files := make([]string, 40)
+	for i := range files {
+		files[i] = fmt.Sprintf("IMG_%04d.jpg", i)
+	}
+	service, g, store, queue := newTestService("", files...)
+
+	// Each photo takes a minute; invocations have 5 minutes and stop 2 minutes early
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return clock }
+	service.partSize = 1 // upload a part after every photo
+	store.onGet = func() { clock = clock.Add(time.Minute) }
+
+	job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{})
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	runs := drain(t, service, queue, func() (context.Context, context.CancelFunc) {
+		return context.WithDeadline(context.Background(), clock.Add(5*time.Minute))
+	})
+	if runs < 2 {
+		t.Errorf("runs = %d, want the job to span several invocations", runs)
+	}
+
+	stored, _ := service.jobRepo.GetByID(context.Background(), job.JobID)
+	if stored.Status != StatusCompleted {
+		t.Fatalf("Status = %v (%s), want %v", stored.Status, stored.LastError, StatusCompleted)
+	}
+
+	data, _ := store.object(testBuckets.Archive, job.ArchiveKey)
+	archived := readArchive(t, data)
+	if len(archived) != len(files) {
+		t.Errorf("archive has %d files, want %d", len(archived), len(files))
+	}
+	for i, name := range files {
+		if want := fmt.Sprintf("optimized:photo_%03d", i); archived[name] != want {
+			t.Errorf("%s content = %q, want %q", name, archived[name], want)
+		}
+	}
+
+	// Work after the last checkpoint is redone, but each photo is read at most twice
+	if store.getCalls > 2*len(files)+runs {
+		t.Errorf("GetObject called %d times for %d photos", store.getCalls, len(files))
+	}
+}
+
+func TestRunFailsJobAndAbortsUpload(t *testing.T) {
+	service, g, store, queue := newTestService("", "a.jpg", "b.jpg")
+	photo, _ := service.photoRepo.GetByID(context.Background(), "photo_001")
+	delete(store.objects, testBuckets.Optimized+"/"+photo.OptimizedKey)
+
+	job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{})
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	drain(t, service, queue, background)
+
+	stored, _ := service.jobRepo.GetByID(context.Background(), job.JobID)
+	if stored.Status != StatusFailed {
+		t.Fatalf("Status = %v, want %v", stored.Status, StatusFailed)
+	}
+	if !strings.Contains(stored.LastError, "NoSuchKey") {
+		t.Errorf("LastError = %v, want NoSuchKey", stored.LastError)
+	}
+	if len(store.aborted) != 1 {
+		t.Errorf("aborted uploads = %d, want 1", len(store.aborted))
+	}
+
+	// Failed jobs are not picked up again
+	if err := service.Run(context.Background(), job.JobID); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(store.aborted) != 1 {
+		t.Errorf("aborted uploads = %d, want 1", len(store.aborted))
+	}
+}
+
+func TestGetHidesOtherSessionsJobs(t *testing.T) {
+	service, g, _, _ := newTestService("", "a.jpg")
+	job, err := service.Start(context.Background(), g.GalleryID, "session_1", StartRequest{})
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	_, err = service.Get(context.Background(), g.GalleryID, "session_2", job.JobID)
+	assertErrorCode(t, err, 404)
+
+	status, err := service.Get(context.Background(), g.GalleryID, "session_1", job.JobID)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if status.DownloadURL != "" {
+		t.Errorf("DownloadURL = %v, want none before completion", status.DownloadURL)
+	}
+}
+
+func TestArchiveItemsSkipsUnprocessedPhotos(t *testing.T) {
+	photos := []*repository.Photo{
+		{PhotoID: "p1", FileName: "IMG_1.HEIC.png", OriginalKey: "g/p1/IMG_1.HEIC.png", OptimizedKey: "g/p1/optimized.jpg"},
+		{PhotoID: "p2", FileName: "IMG_2.png", OriginalKey: "g/p2/IMG_2.png"},
+		{PhotoID: "p3", FileName: "../../etc/passwd", OriginalKey: "g/p3/x", OptimizedKey: "g/p3/optimized.jpg"},
+	}
+
+	items := archiveItems(photos, VariantOptimized)
+	if len(items) != 2 {
+		t.Fatalf("items = %d, want 2", len(items))
+	}
+	if items[0].Name != "IMG_1.HEIC.jpg" {
+		t.Errorf("Name = %v, want IMG_1.HEIC.jpg", items[0].Name)
+	}
+	if items[1].Name != "passwd.jpg" {
+		t.Errorf("Name = %v, want passwd.jpg", items[1].Name)
+	}
+
+	if items := archiveItems(photos, VariantOriginal); len(items) != 3 {
+		t.Errorf("original items = %d, want 3", len(items))
+	}
+}
@@ -0,0 +1,213 @@
+package download
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	zipLocalHeaderSignature    = 0x04034b50
+	zipDataDescriptorSignature = 0x08074b50
+	zipCentralHeaderSignature  = 0x02014b50
+	zipDirectoryEndSignature   = 0x06054b50
+	zip64DirectoryEndSignature = 0x06064b50
+	zip64LocatorSignature      = 0x07064b50
+
+	zipVersion20 = 20 // stored entries with data descriptors
+	zipVersion45 = 45 // zip64 extensions
+
+	// Sizes follow in a data descriptor, names are UTF-8
+	zipFlags = 0x8 | 0x800
+
+	zip64ExtraID = 0x0001
+)
+
+// zipEntry is what the central directory needs to know about a file already written.
+// Entries are persisted between invocations, so they carry JSON tags.
+type zipEntry struct {
+	Name     string    `json:"name"`
+	Modified time.Time `json:"modified"`
+	CRC32    uint32    `json:"crc32"`
+	Size     uint64    `json:"size"`
+	Offset   uint64    `json:"offset"`
+}
+
+// zipWriter streams an uncompressed ZIP archive. Unlike archive/zip, its state is just
+// the byte offset and the entries written so far, so an archive can be continued by a
+// later invocation writing to a different destination (e.g. the next multipart part).
+// Photos are already compressed, so entries are stored rather than deflated.
+type zipWriter struct {
+	w       io.Writer
+	offset  uint64
+	entries []zipEntry
+
+	// forceZip64 writes zip64 records even for small archives; used by tests
+	forceZip64 bool
+}
+
+// newZipWriter resumes an archive at offset with the given entries already written.
+func newZipWriter(w io.Writer, offset uint64, entries []zipEntry) *zipWriter {
+	return &zipWriter{w: w, offset: offset, entries: entries}
+}
+
+// Offset returns the number of archive bytes written so far.
+func (z *zipWriter) Offset() uint64 {
+	return z.offset
+}
+
+// Entries returns the files written so far.
+func (z *zipWriter) Entries() []zipEntry {
+	return z.entries
+}
+
+// WriteFile streams r into the archive as a stored entry and returns the bytes copied.
+func (z *zipWriter) WriteFile(name string, modified time.Time, r io.Reader) (int64, error) {
+	entry := zipEntry{Name: name, Modified: modified, Offset: z.offset}
+	modTime, modDate := msDosTimeDate(modified)
+
+	header := make([]byte, 0, 30+len(name))
+	header = binary.LittleEndian.AppendUint32(header, zipLocalHeaderSignature)
+	header = binary.LittleEndian.AppendUint16(header, zipVersion20)
+	header = binary.LittleEndian.AppendUint16(header, zipFlags)
+	header = binary.LittleEndian.AppendUint16(header, 0) // stored
+	header = binary.LittleEndian.AppendUint16(header, modTime)
+	header = binary.LittleEndian.AppendUint16(header, modDate)
+	header = binary.LittleEndian.AppendUint32(header, 0) // crc, sizes: see data descriptor
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(name)))
+	header = binary.LittleEndian.AppendUint16(header, 0)
+	header = append(header, name...)
+	if err := z.write(header); err != nil {
+		return 0, err
+	}
+
+	hash := crc32.NewIEEE()
+	n, err := io.Copy(io.MultiWriter(z.w, hash), r)
+	z.offset += uint64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if n > math.MaxUint32 {
+		return n, fmt.Errorf("%s is too large for a ZIP entry (%d bytes)", name, n)
+	}
+	entry.CRC32 = hash.Sum32()
+	entry.Size = uint64(n)
+
+	descriptor := make([]byte, 0, 16)
+	descriptor = binary.LittleEndian.AppendUint32(descriptor, zipDataDescriptorSignature)
+	descriptor = binary.LittleEndian.AppendUint32(descriptor, entry.CRC32)
+	descriptor = binary.LittleEndian.AppendUint32(descriptor, uint32(entry.Size))
+	descriptor = binary.LittleEndian.AppendUint32(descriptor, uint32(entry.Size))
+	if err := z.write(descriptor); err != nil {
+		return n, err
+	}
+
+	z.entries = append(z.entries, entry)
+	return n, nil
+}
+
+// Close writes the central directory, completing the archive. It does not close the
+// underlying writer.
+func (z *zipWriter) Close() error {
+	dirStart := z.offset
+
+	for _, entry := range z.entries {
+		zip64 := z.forceZip64 || entry.Offset >= math.MaxUint32
+		modTime, modDate := msDosTimeDate(entry.Modified)
+
+		version := uint16(zipVersion20)
+		offset := uint32(entry.Offset)
+		var extra []byte
+		if zip64 {
+			version = zipVersion45
+			offset = math.MaxUint32
+			extra = binary.LittleEndian.AppendUint16(extra, zip64ExtraID)
+			extra = binary.LittleEndian.AppendUint16(extra, 8)
+			extra = binary.LittleEndian.AppendUint64(extra, entry.Offset)
+		}
+
+		header := make([]byte, 0, 46+len(entry.Name)+len(extra))
+		header = binary.LittleEndian.AppendUint32(header, zipCentralHeaderSignature)
+		header = binary.LittleEndian.AppendUint16(header, zipVersion45) // made by
+		header = binary.LittleEndian.AppendUint16(header, version)
+		header = binary.LittleEndian.AppendUint16(header, zipFlags)
+		header = binary.LittleEndian.AppendUint16(header, 0) // stored
+		header = binary.LittleEndian.AppendUint16(header, modTime)
+		header = binary.LittleEndian.AppendUint16(header, modDate)
+		header = binary.LittleEndian.AppendUint32(header, entry.CRC32)
+		header = binary.LittleEndian.AppendUint32(header, uint32(entry.Size))
+		header = binary.LittleEndian.AppendUint32(header, uint32(entry.Size))
+		header = binary.LittleEndian.AppendUint16(header, uint16(len(entry.Name)))
+		header = binary.LittleEndian.AppendUint16(header, uint16(len(extra)))
+		header = binary.LittleEndian.AppendUint16(header, 0) // comment
+		header = binary.LittleEndian.AppendUint16(header, 0) // disk
+		header = binary.LittleEndian.AppendUint16(header, 0) // internal attributes
+		header = binary.LittleEndian.AppendUint32(header, 0) // external attributes
+		header = binary.LittleEndian.AppendUint32(header, offset)
+		header = append(header, entry.Name...)
+		header = append(header, extra...)
+		if err := z.write(header); err != nil {
+			return err
+		}
+	}
+
+	dirEnd := z.offset
+	dirSize := dirEnd - dirStart
+	count := uint64(len(z.entries))
+
+	var end []byte
+	if z.forceZip64 || count >= math.MaxUint16 || dirSize >= math.MaxUint32 || dirStart >= math.MaxUint32 {
+		end = binary.LittleEndian.AppendUint32(end, zip64DirectoryEndSignature)
+		end = binary.LittleEndian.AppendUint64(end, 44) // size of the remaining record
+		end = binary.LittleEndian.AppendUint16(end, zipVersion45)
+		end = binary.LittleEndian.AppendUint16(end, zipVersion45)
+		end = binary.LittleEndian.AppendUint32(end, 0)
+		end = binary.LittleEndian.AppendUint32(end, 0)
+		end = binary.LittleEndian.AppendUint64(end, count)
+		end = binary.LittleEndian.AppendUint64(end, count)
+		end = binary.LittleEndian.AppendUint64(end, dirSize)
+		end = binary.LittleEndian.AppendUint64(end, dirStart)
+
+		end = binary.LittleEndian.AppendUint32(end, zip64LocatorSignature)
+		end = binary.LittleEndian.AppendUint32(end, 0)
+		end = binary.LittleEndian.AppendUint64(end, dirEnd)
+		end = binary.LittleEndian.AppendUint32(end, 1)
+
+		// The regular end record points readers at the zip64 record
+		count = math.MaxUint16
+		dirSize = math.MaxUint32
+		dirStart = math.MaxUint32
+	}
+
+	end = binary.LittleEndian.AppendUint32(end, zipDirectoryEndSignature)
+	end = binary.LittleEndian.AppendUint16(end, 0)
+	end = binary.LittleEndian.AppendUint16(end, 0)
+	end = binary.LittleEndian.AppendUint16(end, uint16(count))
+	end = binary.LittleEndian.AppendUint16(end, uint16(count))
+	end = binary.LittleEndian.AppendUint32(end, uint32(dirSize))
+	end = binary.LittleEndian.AppendUint32(end, uint32(dirStart))
+	end = binary.LittleEndian.AppendUint16(end, 0) // comment
+	return z.write(end)
+}
+
+func (z *zipWriter) write(p []byte) error {
+	n, err := z.w.Write(p)
+	z.offset += uint64(n)
+	return err
+}
+
+// msDosTimeDate converts t to the MS-DOS time and date fields used by ZIP headers.
+func msDosTimeDate(t time.Time) (uint16, uint16) {
+	t = t.UTC()
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	dosTime := uint16(t.Hour()<<11 | t.Minute()<<5 | t.Second()>>1)
+	dosDate := uint16((t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day())
+	return dosTime, dosDate
+}
@@ -0,0 +1,109 @@
+package download
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc) // verifies the CRC
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ReadAll(%s) error: %v", f.Name, err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestZipWriterResumesAcrossSegments(t *testing.T) {
+	for _, zip64 := range []bool{false, true} {
+		name := "zip32"
+		if zip64 {
+			name = "zip64"
+		}
+		t.Run(name, func(t *testing.T) {
+			modified := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+
+			// First invocation writes two files
+			var first bytes.Buffer
+			zw := newZipWriter(&first, 0, nil)
+			if _, err := zw.WriteFile("a.jpg", modified, strings.NewReader("first photo")); err != nil {
+				t.Fatalf("WriteFile() error: %v", err)
+			}
+			if _, err := zw.WriteFile("b.jpg", modified, strings.NewReader(strings.Repeat("x", 4096))); err != nil {
+				t.Fatalf("WriteFile() error: %v", err)
+			}
+			if zw.Offset() != uint64(first.Len()) {
+				t.Fatalf("Offset() = %d, want %d", zw.Offset(), first.Len())
+			}
+
+			// A later invocation continues from the saved offset and entries
+			var second bytes.Buffer
+			resumed := newZipWriter(&second, zw.Offset(), zw.Entries())
+			resumed.forceZip64 = zip64
+			if _, err := resumed.WriteFile("Ünïcode.jpg", modified, strings.NewReader("third photo")); err != nil {
+				t.Fatalf("WriteFile() error: %v", err)
+			}
+			if err := resumed.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			files := readArchive(t, append(first.Bytes(), second.Bytes()...))
+			want := map[string]string{
+				"a.jpg":       "first photo",
+				"b.jpg":       strings.Repeat("x", 4096),
+				"Ünïcode.jpg": "third photo",
+			}
+			if len(files) != len(want) {
+				t.Fatalf("archive has %d files, want %d", len(files), len(want))
+			}
+			for name, content := range want {
+				if files[name] != content {
+					t.Errorf("%s content = %q, want %q", name, files[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestZipWriterEmptyArchive(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newZipWriter(&buf, 0, nil).Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if files := readArchive(t, buf.Bytes()); len(files) != 0 {
+		t.Errorf("archive has %d files, want 0", len(files))
+	}
+}
+
+func TestMsDosTimeDate(t *testing.T) {
+	dosTime, dosDate := msDosTimeDate(time.Date(2024, 6, 1, 12, 30, 10, 0, time.UTC))
+	if dosTime != 12<<11|30<<5|5 {
+		t.Errorf("time = %#x", dosTime)
+	}
+	if dosDate != 44<<9|6<<5|1 {
+		t.Errorf("date = %#x", dosDate)
+	}
+
+	// Dates before the MS-DOS epoch are clamped
+	if _, dosDate := msDosTimeDate(time.Time{}); dosDate != 1<<5|1 {
+		t.Errorf("zero time date = %#x, want %#x", dosDate, 1<<5|1)
+	}
+}
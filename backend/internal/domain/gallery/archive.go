@@ -0,0 +1,234 @@
+package gallery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Processing statuses of photos in archived galleries
+const (
+	photoStatusArchived  = "archived"
+	photoStatusRestoring = "restoring"
+	photoStatusPending   = "pending"
+)
+
+// archiveGallery moves a gallery's originals to cold storage, drops their derivatives and
+// locks clients out. Photo records are kept so the gallery can be restored later. Extra
+// events are recorded in the same write as the gallery status change. If any photo fails,
+// the gallery is left as it was, so the next run retries the photos not yet archived.
+func (s *Service) archiveGallery(ctx context.Context, gallery *repository.Gallery, extra ...events.Event) error {
+	photos, err := s.fetchAllPhotos(ctx, gallery.GalleryID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to list photos for archiving")
+	}
+
+	var failed int
+	var freed int64
+	for _, photo := range photos {
+		if photo.ProcessingStatus == photoStatusArchived {
+			// Archived by an earlier run that failed on other photos
+			continue
+		}
+		if s.storageService != nil {
+			if err := s.storageService.ArchivePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey, photo.RenditionKeys()...); err != nil {
+				failed++
+				continue
+			}
+		}
+		// Originals stay counted in cold storage; derivatives are gone until the photo is reprocessed
+		derivatives := quota.PhotoBytes(photo) - photo.Size
+		photo.ProcessingStatus = photoStatusArchived
+		photo.OptimizedSize, photo.ThumbnailSize = 0, 0
+		photo.Renditions = nil
+		if err := s.photoRepo.Update(ctx, photo); err != nil {
+			failed++
+			continue
+		}
+		freed += derivatives
+	}
+	s.quota.Record(ctx, gallery.PhotographerID, -freed)
+	if failed > 0 {
+		return errors.New(500, fmt.Sprintf("Failed to archive %d of %d photos", failed, len(photos)))
+	}
+
+	now := time.Now()
+	gallery.Status = repository.GalleryStatusArchived
+	gallery.ArchivedAt = &now
+
+	writeCtx, err := repository.WithOutboxEvents(ctx, extra...)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to record gallery event")
+	}
+	if err := s.galleryRepo.Update(writeCtx, gallery); err != nil {
+		return errors.Wrap(err, 500, "Failed to archive gallery")
+	}
+	return nil
+}
+
+// RestoreGallery starts restoring an archived gallery owned by the photographer. Originals are
+// rehydrated from cold storage, which takes hours, and then reprocessed; the gallery becomes
+// active again once every photo has been requeued, expiring as a new gallery on its plan would.
+func (s *Service) RestoreGallery(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.GetForPhotographer(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	switch gallery.Status {
+	case repository.GalleryStatusArchived:
+	case repository.GalleryStatusRestoring:
+		return nil, errors.NewConflict("Gallery is already being restored")
+	default:
+		return nil, errors.NewConflict("Gallery is not archived")
+	}
+	entitlements, err := s.plans.For(ctx, gallery.PhotographerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanActivate(ctx, entitlements, gallery.PhotographerID); err != nil {
+		return nil, err
+	}
+
+	gallery.Status = repository.GalleryStatusRestoring
+	if err := s.galleryRepo.Update(ctx, gallery); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to restore gallery")
+	}
+	logger.Info("Gallery restore started", map[string]interface{}{"galleryId": gallery.GalleryID})
+
+	// Start the S3 restores now; the scheduler finishes the restore once they complete
+	if _, err := s.advanceRestore(ctx, gallery); err != nil {
+		logger.Error("Failed to advance gallery restore", map[string]interface{}{
+			"galleryId": gallery.GalleryID, "error": err.Error(),
+		})
+	}
+	return gallery, nil
+}
+
+// ProcessRestoringGalleries advances galleries that are being restored, requeueing photos whose
+// originals have been rehydrated and reactivating galleries that are done.
+func (s *Service) ProcessRestoringGalleries(ctx context.Context, limit int) error {
+	galleries, err := s.galleryRepo.ListByStatus(ctx, repository.GalleryStatusRestoring, limit)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to list restoring galleries")
+	}
+
+	var errorCount int
+	for _, gallery := range galleries {
+		if _, err := s.advanceRestore(ctx, gallery); err != nil {
+			logger.Error("Failed to advance gallery restore", map[string]interface{}{
+				"galleryId": gallery.GalleryID, "error": err.Error(),
+			})
+			errorCount++
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("completed with %d errors out of %d galleries", errorCount, len(galleries))
+	}
+	return nil
+}
+
+// advanceRestore requeues every photo whose original is readable again and reactivates the
+// gallery once none are left waiting. It reports whether the restore is complete.
+func (s *Service) advanceRestore(ctx context.Context, gallery *repository.Gallery) (bool, error) {
+	photos, err := s.fetchAllPhotos(ctx, gallery.GalleryID)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to list photos for restore")
+	}
+
+	var waiting int
+	for _, photo := range photos {
+		if photo.ProcessingStatus != photoStatusArchived && photo.ProcessingStatus != photoStatusRestoring {
+			continue
+		}
+		if !s.restorePhoto(ctx, photo) {
+			waiting++
+		}
+	}
+	if waiting > 0 {
+		logger.Info("Gallery restore in progress", map[string]interface{}{
+			"galleryId": gallery.GalleryID, "waiting": waiting,
+		})
+		return false, nil
+	}
+
+	entitlements, err := s.plans.For(ctx, gallery.PhotographerID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	gallery.Status = repository.GalleryStatusActive
+	gallery.ArchivedAt = nil
+	gallery.ExpiresAt = entitlements.DefaultExpiration(now)
+
+	restored := events.NewEvent(events.GalleryRestored, &events.GalleryRestoredPayload{
+		GalleryID:      gallery.GalleryID,
+		PhotographerID: gallery.PhotographerID,
+		PhotoCount:     len(photos),
+		RestoredAt:     now,
+	}, gallery.GalleryID)
+	writeCtx, err := repository.WithOutboxEvents(ctx, restored)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to record gallery event")
+	}
+	if err := s.galleryRepo.Update(writeCtx, gallery); err != nil {
+		return false, errors.Wrap(err, 500, "Failed to reactivate gallery")
+	}
+	logger.Info("Gallery restored", map[string]interface{}{"galleryId": gallery.GalleryID, "photos": len(photos)})
+	return true, nil
+}
+
+// restorePhoto rehydrates a photo's original and requeues it for processing once it is readable.
+// It reports whether the photo no longer needs restoring.
+func (s *Service) restorePhoto(ctx context.Context, photo *repository.Photo) bool {
+	ready := true
+	if s.storageService != nil {
+		var err error
+		ready, err = s.storageService.RestoreOriginal(ctx, photo.OriginalKey)
+		if err != nil {
+			logger.Error("Failed to restore original", map[string]interface{}{
+				"photoId": photo.PhotoID, "error": err.Error(),
+			})
+			return false
+		}
+	}
+	if !ready {
+		if photo.ProcessingStatus != photoStatusRestoring {
+			photo.ProcessingStatus = photoStatusRestoring
+			if err := s.photoRepo.Update(ctx, photo); err != nil {
+				logger.Warn("Failed to mark photo as restoring", map[string]interface{}{
+					"photoId": photo.PhotoID, "error": err.Error(),
+				})
+			}
+		}
+		return false
+	}
+
+	// Mark the photo pending before queueing it so the processor's result isn't overwritten
+	previous := photo.ProcessingStatus
+	photo.ProcessingStatus = photoStatusPending
+	if err := s.photoRepo.Update(ctx, photo); err != nil {
+		logger.Error("Failed to mark restored photo as pending", map[string]interface{}{
+			"photoId": photo.PhotoID, "error": err.Error(),
+		})
+		return false
+	}
+	if s.processingQueue != nil {
+		if err := s.processingQueue.Enqueue(ctx, photo.OriginalKey); err != nil {
+			logger.Error("Failed to queue restored photo for processing", map[string]interface{}{
+				"photoId": photo.PhotoID, "error": err.Error(),
+			})
+			photo.ProcessingStatus = previous
+			s.photoRepo.Update(ctx, photo)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,269 @@
+package gallery
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
+)
+
+// addExpiredGallery stores an expired gallery of user_owner with two processed photos
+func addExpiredGallery(galleryRepo *mocks.MockGalleryRepository, photoRepo *mocks.MockPhotoRepository) (*repository.Gallery, []*repository.Photo) {
+	expiredAt := time.Now().Add(-time.Hour)
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", ExpiresAt: &expiredAt, Status: "active"})
+	galleryRepo.AddGallery(g)
+	photos := fixtures.NewPhotoList(2, g.GalleryID)
+	for _, p := range photos {
+		photoRepo.AddPhoto(p)
+	}
+	return g, photos
+}
+
+func storedGallery(t *testing.T, galleryRepo *mocks.MockGalleryRepository, galleryID string) *repository.Gallery {
+	t.Helper()
+	g, err := galleryRepo.GetByID(context.Background(), galleryID)
+	if err != nil || g == nil {
+		t.Fatalf("GetByID() = %v, %v", g, err)
+	}
+	return g
+}
+
+func photoStatuses(t *testing.T, photoRepo *mocks.MockPhotoRepository, photos []*repository.Photo) []string {
+	t.Helper()
+	var statuses []string
+	for _, p := range photos {
+		stored, err := photoRepo.GetByID(context.Background(), p.PhotoID)
+		if err != nil || stored == nil {
+			t.Fatalf("GetByID() = %v, %v", stored, err)
+		}
+		statuses = append(statuses, stored.ProcessingStatus)
+	}
+	return statuses
+}
+
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != code {
+		t.Errorf("error code = %d, want %d", appErr.Code, code)
+	}
+}
+
+func TestProcessExpiredGalleriesArchives(t *testing.T) {
+	service, galleryRepo, photoRepo, storageService := newTestService()
+	g, photos := addExpiredGallery(galleryRepo, photoRepo)
+
+	if err := service.ProcessExpiredGalleries(context.Background(), 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	archived := storedGallery(t, galleryRepo, g.GalleryID)
+	if archived.Status != repository.GalleryStatusArchived || archived.ArchivedAt == nil {
+		t.Errorf("Status = %q, ArchivedAt = %v, want archived", archived.Status, archived.ArchivedAt)
+	}
+
+	if archived := storageService.GetArchivedOriginals(); len(archived) != 2 {
+		t.Errorf("Expected 2 originals moved to cold storage, got %d", len(archived))
+	}
+	// Derivatives are dropped; originals are kept
+	deleted := storageService.GetDeletedObjects()
+	if len(deleted) != 4 {
+		t.Errorf("Expected optimized and thumbnail objects deleted, got %v", deleted)
+	}
+	for _, key := range deleted {
+		for _, p := range photos {
+			if key == p.OriginalKey {
+				t.Errorf("Original %s should not be deleted", key)
+			}
+		}
+	}
+
+	for _, status := range photoStatuses(t, photoRepo, photos) {
+		if status != photoStatusArchived {
+			t.Errorf("Photo status = %q, want archived", status)
+		}
+	}
+}
+
+func TestProcessExpiredGalleriesRetriesFailedPhotos(t *testing.T) {
+	service, galleryRepo, photoRepo, storageService := newTestService()
+	g, photos := addExpiredGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	storageService.ArchiveErr = errors.New(500, "cold storage unavailable")
+	if err := service.ProcessExpiredGalleries(ctx, 100); err == nil {
+		t.Fatal("ProcessExpiredGalleries() should fail while photos can't be archived")
+	}
+	if got := storedGallery(t, galleryRepo, g.GalleryID); got.Status != repository.GalleryStatusActive || got.ArchivedAt != nil {
+		t.Errorf("Status = %q, ArchivedAt = %v, want the gallery left for the next run", got.Status, got.ArchivedAt)
+	}
+
+	// The next run only archives the photos an earlier run didn't
+	storageService.ArchiveErr = nil
+	photos[0].ProcessingStatus = photoStatusArchived
+	if err := service.ProcessExpiredGalleries(ctx, 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+	if got := storedGallery(t, galleryRepo, g.GalleryID); got.Status != repository.GalleryStatusArchived {
+		t.Errorf("Status = %q, want archived", got.Status)
+	}
+	if archived := storageService.GetArchivedOriginals(); len(archived) != 1 || archived[0] != photos[1].OriginalKey {
+		t.Errorf("archived originals = %v, want only %s", archived, photos[1].OriginalKey)
+	}
+}
+
+func TestArchiveReleasesDerivativeStorage(t *testing.T) {
+	service, galleryRepo, photoRepo, storageService := newTestService()
+	_, photos := addExpiredGallery(galleryRepo, photoRepo)
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_owner", StorageUsed: 10000})
+	service.WithQuota(quota.NewService(accounts, galleryRepo, photoRepo))
+	for _, p := range photos {
+		p.OptimizedSize, p.ThumbnailSize = 300, 100
+		p.Renditions = []repository.Rendition{{
+			Name: "sm", Key: p.PhotoID + "/sm.jpg", Size: 50,
+			Formats: []repository.RenditionFormat{{Format: "webp", Key: p.PhotoID + "/sm.webp", Size: 30}},
+		}}
+	}
+
+	if err := service.ProcessExpiredGalleries(context.Background(), 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	// Originals in cold storage still count against the quota
+	if got := accounts.StorageUsed("user_owner"); got != 10000-2*480 {
+		t.Errorf("StorageUsed = %d, want %d", got, 10000-2*480)
+	}
+	deleted := storageService.GetDeletedObjects()
+	for _, p := range photos {
+		stored, _ := photoRepo.GetByID(context.Background(), p.PhotoID)
+		if stored.OptimizedSize != 0 || stored.ThumbnailSize != 0 || len(stored.Renditions) != 0 {
+			t.Errorf("photo %s derivatives = %d, %d, %v; want cleared", p.PhotoID, stored.OptimizedSize, stored.ThumbnailSize, stored.Renditions)
+		}
+		if !slices.Contains(deleted, p.PhotoID+"/sm.jpg") || !slices.Contains(deleted, p.PhotoID+"/sm.webp") {
+			t.Errorf("photo %s rendition was not deleted", p.PhotoID)
+		}
+	}
+}
+
+func TestArchivedGalleryRejectsClients(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	g, _ := addExpiredGallery(galleryRepo, photoRepo)
+	if err := service.ProcessExpiredGalleries(context.Background(), 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	err := service.CheckClientAccess(context.Background(), g.GalleryID)
+	assertErrorCode(t, err, 400)
+	if appErr := err.(*errors.AppError); appErr.Message != "Gallery has been archived" {
+		t.Errorf("Message = %q, want archived message", appErr.Message)
+	}
+
+	// Access is checked before the password
+	_, err = service.VerifyPassword(context.Background(), g.CustomURL, "password123")
+	assertErrorCode(t, err, 400)
+}
+
+func TestRestoreGalleryRequiresArchived(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	g, _ := addExpiredGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	_, err := service.RestoreGallery(ctx, "user_owner", g.GalleryID)
+	assertErrorCode(t, err, 409)
+
+	if err := service.ProcessExpiredGalleries(ctx, 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	_, err = service.RestoreGallery(ctx, "user_other", g.GalleryID)
+	assertNotFound(t, err)
+
+	if _, err := service.RestoreGallery(ctx, "user_owner", g.GalleryID); err != nil {
+		t.Fatalf("RestoreGallery() error: %v", err)
+	}
+	_, err = service.RestoreGallery(ctx, "user_owner", g.GalleryID)
+	assertErrorCode(t, err, 409)
+}
+
+func TestRestoreGalleryWaitsForColdStorage(t *testing.T) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	galleryRepo.Outbox = mocks.NewMockOutboxRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	storageService := mocks.NewMockStorageService()
+	queue := mocks.NewMockProcessingQueue()
+	service := NewService(galleryRepo, photoRepo, storageService, queue)
+	g, photos := addExpiredGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	if err := service.ProcessExpiredGalleries(ctx, 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	g, err := service.RestoreGallery(ctx, "user_owner", g.GalleryID)
+	if err != nil {
+		t.Fatalf("RestoreGallery() error: %v", err)
+	}
+	if g.Status != repository.GalleryStatusRestoring {
+		t.Errorf("Status = %q, want restoring", g.Status)
+	}
+	for _, p := range photos {
+		if !storageService.RestoreStarted(p.OriginalKey) {
+			t.Errorf("Expected restore of %s to be started", p.OriginalKey)
+		}
+	}
+	for _, status := range photoStatuses(t, photoRepo, photos) {
+		if status != photoStatusRestoring {
+			t.Errorf("Photo status = %q, want restoring", status)
+		}
+	}
+	if queued := queue.Enqueued(); len(queued) != 0 {
+		t.Errorf("Expected nothing queued before originals are back, got %v", queued)
+	}
+
+	// Restores still running: nothing changes
+	if err := service.ProcessRestoringGalleries(ctx, 100); err != nil {
+		t.Fatalf("ProcessRestoringGalleries() error: %v", err)
+	}
+	if got := storedGallery(t, galleryRepo, g.GalleryID).Status; got != repository.GalleryStatusRestoring {
+		t.Errorf("Status = %q, want restoring", got)
+	}
+
+	storageService.RestoresReady = true
+	if err := service.ProcessRestoringGalleries(ctx, 100); err != nil {
+		t.Fatalf("ProcessRestoringGalleries() error: %v", err)
+	}
+
+	restored := storedGallery(t, galleryRepo, g.GalleryID)
+	if restored.Status != repository.GalleryStatusActive {
+		t.Errorf("Status = %q, want active", restored.Status)
+	}
+	if restored.ArchivedAt != nil || restored.ExpiresAt != nil {
+		t.Errorf("ArchivedAt = %v, ExpiresAt = %v, want both cleared", restored.ArchivedAt, restored.ExpiresAt)
+	}
+	if queued := queue.Enqueued(); len(queued) != 2 {
+		t.Errorf("Expected 2 originals queued for processing, got %v", queued)
+	}
+	for _, status := range photoStatuses(t, photoRepo, photos) {
+		if status != photoStatusPending {
+			t.Errorf("Photo status = %q, want pending", status)
+		}
+	}
+	if got := galleryRepo.Outbox.EventsOfType(string(events.GalleryRestored)); len(got) != 1 {
+		t.Errorf("Expected 1 gallery.restored event, got %d", len(got))
+	}
+	if err := service.CheckClientAccess(ctx, g.GalleryID); err != nil {
+		t.Errorf("CheckClientAccess() after restore error: %v", err)
+	}
+}
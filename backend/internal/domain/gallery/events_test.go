@@ -0,0 +1,114 @@
+package gallery
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/events"
+)
+
+func TestCreateGalleryRecordsEvent(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	galleryRepo.Outbox = mocks.NewMockOutboxRepository()
+
+	g, err := service.Create(context.Background(), CreateGalleryRequest{
+		PhotographerID: "user_owner",
+		Name:           "Wedding",
+		Password:       "secret123",
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	recorded := galleryRepo.Outbox.EventsOfType(string(events.GalleryCreated))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 gallery.created event, got %d", len(recorded))
+	}
+	if recorded[0].CorrelationID != g.GalleryID {
+		t.Errorf("CorrelationID = %v, want %v", recorded[0].CorrelationID, g.GalleryID)
+	}
+
+	var payload events.GalleryCreatedPayload
+	if err := json.Unmarshal([]byte(recorded[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.GalleryID != g.GalleryID || payload.PhotographerID != "user_owner" {
+		t.Errorf("Payload = %+v, want gallery %v owned by user_owner", payload, g.GalleryID)
+	}
+}
+
+func TestDeleteGalleryRecordsEvent(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	galleryRepo.Outbox = mocks.NewMockOutboxRepository()
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	for _, p := range fixtures.NewPhotoList(3, g.GalleryID) {
+		photoRepo.AddPhoto(p)
+	}
+
+	if err := service.Delete(context.Background(), "user_owner", g.GalleryID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	recorded := galleryRepo.Outbox.EventsOfType(string(events.GalleryDeleted))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 gallery.deleted event, got %d", len(recorded))
+	}
+
+	var payload events.GalleryDeletedPayload
+	if err := json.Unmarshal([]byte(recorded[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.PhotoCount != 3 {
+		t.Errorf("PhotoCount = %d, want 3", payload.PhotoCount)
+	}
+}
+
+func TestDeleteGalleryNotOwnerRecordsNoEvent(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	galleryRepo.Outbox = mocks.NewMockOutboxRepository()
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	_ = service.Delete(context.Background(), "user_other", g.GalleryID)
+
+	if recorded := galleryRepo.Outbox.Events(); len(recorded) != 0 {
+		t.Errorf("Expected no events, got %d", len(recorded))
+	}
+}
+
+func TestProcessExpiredGalleriesRecordsEvents(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	galleryRepo.Outbox = mocks.NewMockOutboxRepository()
+
+	expiredAt := time.Now().Add(-time.Hour)
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", ExpiresAt: &expiredAt, Status: "active"})
+	galleryRepo.AddGallery(g)
+
+	if err := service.ProcessExpiredGalleries(context.Background(), 100); err != nil {
+		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
+	}
+
+	recorded := galleryRepo.Outbox.EventsOfType(string(events.GalleryExpired))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 gallery.expired event, got %d", len(recorded))
+	}
+	var payload events.GalleryExpiredPayload
+	if err := json.Unmarshal([]byte(recorded[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.PhotographerID != "user_owner" || !payload.ExpiredAt.Equal(expiredAt) {
+		t.Errorf("Payload = %+v, want owner user_owner expired at %v", payload, expiredAt)
+	}
+
+	// Expired galleries are archived, not deleted
+	if deleted := galleryRepo.Outbox.EventsOfType(string(events.GalleryDeleted)); len(deleted) != 0 {
+		t.Errorf("Expected no gallery.deleted event, got %d", len(deleted))
+	}
+}
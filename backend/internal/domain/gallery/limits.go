@@ -0,0 +1,59 @@
+package gallery
+
+import (
+	"context"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// activeCountPageSize is how many galleries are read per page when counting active galleries
+const activeCountPageSize = 100
+
+// WithPlans makes the service enforce photographers' plan entitlements: how many galleries may
+// be active, how long they may stay up and whether they can be watermarked. Without it nothing
+// is limited.
+func (s *Service) WithPlans(plans *plan.Service) *Service {
+	s.plans = plans
+	return s
+}
+
+// checkCanActivate fails if the photographer already has as many active galleries as their plan
+// allows. Galleries being restored count as active since they will be shortly.
+func (s *Service) checkCanActivate(ctx context.Context, e plan.Entitlements, photographerID string) error {
+	if e.MaxActiveGalleries == 0 {
+		return nil
+	}
+
+	var active int
+	var lastKey map[string]interface{}
+	for {
+		galleries, nextKey, err := s.galleryRepo.ListByPhotographer(ctx, photographerID, activeCountPageSize, lastKey)
+		if err != nil {
+			return errors.Wrap(err, 500, "Failed to count active galleries")
+		}
+		for _, g := range galleries {
+			if isActive(g) {
+				active++
+			}
+		}
+		if nextKey == nil {
+			break
+		}
+		lastKey = nextKey
+	}
+	return e.CheckActiveGalleries(active)
+}
+
+// isActive reports whether a gallery counts towards the plan's active gallery limit
+func isActive(g *repository.Gallery) bool {
+	switch g.Status {
+	case repository.GalleryStatusActive:
+		return g.ExpiresAt == nil || g.ExpiresAt.After(time.Now())
+	case repository.GalleryStatusRestoring:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package gallery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// newPlanService creates a gallery service for a photographer on the given plan with active
+// galleries already created
+func newPlanService(planName string, active int) (*Service, *mocks.MockGalleryRepository) {
+	service, galleryRepo, _, _ := newTestService()
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_owner", Plan: planName})
+	for i := 0; i < active; i++ {
+		galleryRepo.AddGallery(fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: repository.GalleryStatusActive}))
+	}
+
+	service.WithTrash(mocks.NewMockTrashRepository(), time.Hour).WithPlans(plan.NewService(accounts))
+	return service, galleryRepo
+}
+
+func assertPlanLimit(t *testing.T, err error, entitlement string) {
+	t.Helper()
+	if !errors.IsPlanLimit(err) {
+		t.Fatalf("expected plan limit error, got %v", err)
+	}
+	if got := err.(*errors.AppError).Details["entitlement"]; got != entitlement {
+		t.Errorf("entitlement = %v, want %s", got, entitlement)
+	}
+}
+
+func TestCreateGalleryActiveLimit(t *testing.T) {
+	free := plan.For(plan.Free).MaxActiveGalleries
+
+	service, _ := newPlanService(plan.Free, free)
+	_, err := service.Create(context.Background(), CreateGalleryRequest{PhotographerID: "user_owner", Name: "One Too Many"})
+	assertPlanLimit(t, err, plan.EntitlementActiveGalleries)
+
+	service, _ = newPlanService(plan.Pro, free)
+	if _, err := service.Create(context.Background(), CreateGalleryRequest{PhotographerID: "user_owner", Name: "Pro Gallery"}); err != nil {
+		t.Errorf("Create() on pro error: %v", err)
+	}
+}
+
+func TestCreateGalleryIgnoresInactiveGalleries(t *testing.T) {
+	service, galleryRepo := newPlanService(plan.Free, 0)
+	expired := time.Now().Add(-time.Hour)
+	for _, opts := range []fixtures.GalleryOptions{
+		{Status: repository.GalleryStatusArchived},
+		{Status: repository.GalleryStatusDeleted},
+		{Status: repository.GalleryStatusActive, ExpiresAt: &expired},
+	} {
+		opts.PhotographerID = "user_owner"
+		galleryRepo.AddGallery(fixtures.NewGallery(opts))
+	}
+
+	for i := 0; i < plan.For(plan.Free).MaxActiveGalleries; i++ {
+		if _, err := service.Create(context.Background(), CreateGalleryRequest{PhotographerID: "user_owner", Name: "Gallery"}); err != nil {
+			t.Fatalf("Create() #%d error: %v", i+1, err)
+		}
+	}
+}
+
+func TestCreateGalleryExpiration(t *testing.T) {
+	service, _ := newPlanService(plan.Free, 0)
+	ctx := context.Background()
+
+	g, err := service.Create(ctx, CreateGalleryRequest{PhotographerID: "user_owner", Name: "Defaulted"})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if g.ExpiresAt == nil {
+		t.Fatal("free galleries should get a default expiration")
+	}
+	if limit := time.Now().Add(plan.For(plan.Free).MaxGalleryExpiration); g.ExpiresAt.After(limit) {
+		t.Errorf("ExpiresAt = %v, want no later than %v", g.ExpiresAt, limit)
+	}
+
+	tooLate := time.Now().Add(90 * 24 * time.Hour)
+	_, err = service.Create(ctx, CreateGalleryRequest{PhotographerID: "user_owner", Name: "Too Long", ExpiresAt: &tooLate})
+	assertPlanLimit(t, err, plan.EntitlementGalleryExpiration)
+
+	service, _ = newPlanService(plan.Pro, 0)
+	g, err = service.Create(ctx, CreateGalleryRequest{PhotographerID: "user_owner", Name: "Forever"})
+	if err != nil {
+		t.Fatalf("Create() on pro error: %v", err)
+	}
+	if g.ExpiresAt != nil {
+		t.Errorf("pro galleries should not expire by default, got %v", g.ExpiresAt)
+	}
+}
+
+func TestWatermarkRequiresPlan(t *testing.T) {
+	service, galleryRepo := newPlanService(plan.Free, 0)
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, CreateGalleryRequest{PhotographerID: "user_owner", Name: "Marked", EnableWatermark: true, WatermarkText: "© Studio"})
+	assertPlanLimit(t, err, plan.EntitlementWatermark)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(g)
+	enable := true
+	_, err = service.Update(ctx, "user_owner", g.GalleryID, UpdateGalleryRequest{EnableWatermark: &enable})
+	assertPlanLimit(t, err, plan.EntitlementWatermark)
+}
+
+func TestSetExpirationWithinPlan(t *testing.T) {
+	service, galleryRepo := newPlanService(plan.Free, 0)
+	ctx := context.Background()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(g)
+
+	_, err := service.SetExpiration(ctx, "user_owner", g.GalleryID, nil)
+	assertPlanLimit(t, err, plan.EntitlementGalleryExpiration)
+
+	soon := time.Now().Add(7 * 24 * time.Hour)
+	if _, err := service.SetExpiration(ctx, "user_owner", g.GalleryID, &soon); err != nil {
+		t.Errorf("SetExpiration() error: %v", err)
+	}
+}
+
+func TestRestoreDeletedActiveLimit(t *testing.T) {
+	service, galleryRepo := newPlanService(plan.Free, plan.For(plan.Free).MaxActiveGalleries)
+	deletedAt := time.Now()
+	trashed := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: repository.GalleryStatusDeleted})
+	trashed.DeletedAt = &deletedAt
+	galleryRepo.AddGallery(trashed)
+
+	_, err := service.RestoreDeleted(context.Background(), "user_owner", trashed.GalleryID)
+	assertPlanLimit(t, err, plan.EntitlementActiveGalleries)
+}
@@ -0,0 +1,43 @@
+package gallery
+
+import (
+	"context"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// IsOwner reports whether the gallery belongs to the given photographer.
+func IsOwner(gallery *repository.Gallery, photographerID string) bool {
+	return gallery != nil && photographerID != "" && gallery.PhotographerID == photographerID
+}
+
+// GetForPhotographer retrieves a gallery owned by the given photographer.
+// Galleries owned by someone else are reported as not found so their existence isn't revealed,
+// as are galleries in the trash, which can only be restored.
+func (s *Service) GetForPhotographer(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.getOwned(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	if gallery.Status == repository.GalleryStatusDeleted {
+		return nil, errors.NewNotFound("Gallery")
+	}
+	return gallery, nil
+}
+
+// getOwned retrieves a gallery owned by the given photographer, including one in the trash.
+func (s *Service) getOwned(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.GetByID(ctx, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsOwner(gallery, photographerID) {
+		logger.Warn("Gallery access denied", map[string]interface{}{
+			"galleryId": galleryID, "photographerId": photographerID,
+		})
+		return nil, errors.NewNotFound("Gallery")
+	}
+	return gallery, nil
+}
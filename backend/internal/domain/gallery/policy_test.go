@@ -0,0 +1,109 @@
+package gallery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != 404 {
+		t.Errorf("error code = %d, want 404", appErr.Code)
+	}
+}
+
+func TestGetForPhotographer(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	got, err := service.GetForPhotographer(context.Background(), "user_owner", g.GalleryID)
+	if err != nil {
+		t.Fatalf("GetForPhotographer() error: %v", err)
+	}
+	if got.GalleryID != g.GalleryID {
+		t.Errorf("GalleryID = %v, want %v", got.GalleryID, g.GalleryID)
+	}
+
+	_, err = service.GetForPhotographer(context.Background(), "user_other", g.GalleryID)
+	assertNotFound(t, err)
+
+	_, err = service.GetForPhotographer(context.Background(), "", g.GalleryID)
+	assertNotFound(t, err)
+
+	_, err = service.GetForPhotographer(context.Background(), "user_owner", "gal_missing")
+	assertNotFound(t, err)
+}
+
+func TestUpdateGalleryNotOwner(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Name: "Original"})
+	galleryRepo.AddGallery(g)
+
+	name := "Hijacked"
+	_, err := service.Update(context.Background(), "user_other", g.GalleryID, UpdateGalleryRequest{Name: &name})
+	assertNotFound(t, err)
+
+	stored, _ := galleryRepo.GetByID(context.Background(), g.GalleryID)
+	if stored.Name != "Original" {
+		t.Errorf("Name = %v, want Original", stored.Name)
+	}
+}
+
+func TestSetExpirationNotOwner(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, err := service.SetExpiration(context.Background(), "user_other", g.GalleryID, &expiresAt)
+	assertNotFound(t, err)
+
+	stored, _ := galleryRepo.GetByID(context.Background(), g.GalleryID)
+	if stored.ExpiresAt != nil {
+		t.Error("ExpiresAt should not be changed by a non-owner")
+	}
+}
+
+func TestDeleteGalleryNotOwner(t *testing.T) {
+	service, galleryRepo, photoRepo, storageService := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	for _, p := range fixtures.NewPhotoList(2, g.GalleryID) {
+		photoRepo.AddPhoto(p)
+	}
+
+	err := service.Delete(context.Background(), "user_other", g.GalleryID)
+	assertNotFound(t, err)
+
+	if stored, _ := galleryRepo.GetByID(context.Background(), g.GalleryID); stored == nil {
+		t.Error("Gallery should not be deleted by a non-owner")
+	}
+	if photos, _, _ := photoRepo.ListByGallery(context.Background(), g.GalleryID, 10, nil); len(photos) != 2 {
+		t.Errorf("Expected 2 photos to remain, got %d", len(photos))
+	}
+	if deleted := storageService.GetDeletedObjects(); len(deleted) != 0 {
+		t.Errorf("Expected no S3 deletions, got %d", len(deleted))
+	}
+}
+
+func TestDeleteGalleryOwner(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	if err := service.Delete(context.Background(), "user_owner", g.GalleryID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if stored, _ := galleryRepo.GetByID(context.Background(), g.GalleryID); stored != nil {
+		t.Error("Gallery should be deleted")
+	}
+}
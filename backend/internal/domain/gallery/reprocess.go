@@ -0,0 +1,82 @@
+package gallery
+
+import (
+	"context"
+
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Reprocessor sends a gallery's photos back through the image processor.
+type Reprocessor interface {
+	ReprocessGallery(ctx context.Context, photographerID, galleryID string) (*reprocess.Progress, error)
+}
+
+// WithReprocessing makes updates that change how a gallery's photos are processed, such as
+// its watermark or thumbnail crop, regenerate the derivatives of photos already processed.
+func (s *Service) WithReprocessing(r Reprocessor) *Service {
+	s.reprocessor = r
+	return s
+}
+
+// processingSettings are the gallery settings baked into processed photos.
+type processingSettings struct {
+	watermark     watermarkSettings
+	thumbnailCrop string
+	profileID     string
+}
+
+func processingOf(g *repository.Gallery) processingSettings {
+	crop := g.ThumbnailCrop
+	if crop == "" {
+		crop = repository.ThumbnailCropCenter
+	}
+	return processingSettings{watermark: watermarkOf(g), thumbnailCrop: crop, profileID: g.ProcessingProfileID}
+}
+
+// watermarkSettings are the watermark settings of a gallery.
+type watermarkSettings struct {
+	enabled                                 bool
+	kind, text, position, font, color, mode string
+	size, opacity, margin, rotation, scale  float64
+}
+
+func watermarkOf(g *repository.Gallery) watermarkSettings {
+	if !g.EnableWatermark {
+		// Settings of a disabled watermark don't show in photos
+		return watermarkSettings{}
+	}
+	return watermarkSettings{
+		enabled:  true,
+		kind:     g.WatermarkType,
+		text:     g.WatermarkText,
+		position: g.WatermarkPosition,
+		font:     g.WatermarkFont,
+		color:    g.WatermarkColor,
+		mode:     g.WatermarkMode,
+		size:     g.WatermarkSize,
+		opacity:  g.WatermarkOpacity,
+		margin:   g.WatermarkMargin,
+		rotation: g.WatermarkRotation,
+		scale:    g.WatermarkScale,
+	}
+}
+
+// reprocessChanged queues a gallery's photos after its processing settings changed. Failures
+// are logged rather than failing the update; the photographer can reprocess the gallery later.
+func (s *Service) reprocessChanged(ctx context.Context, gallery *repository.Gallery) {
+	if s.reprocessor == nil || gallery.Status != repository.GalleryStatusActive {
+		return
+	}
+	progress, err := s.reprocessor.ReprocessGallery(ctx, gallery.PhotographerID, gallery.GalleryID)
+	if err != nil {
+		logger.Error("Failed to reprocess gallery after settings change", map[string]interface{}{
+			"galleryId": gallery.GalleryID, "error": err.Error(),
+		})
+		return
+	}
+	logger.Info("Processing settings changed, reprocessing gallery", map[string]interface{}{
+		"galleryId": gallery.GalleryID, "photos": progress.Queued,
+	})
+}
@@ -0,0 +1,137 @@
+package gallery
+
+import (
+	"context"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+type fakeReprocessor struct {
+	galleries []string
+}
+
+func (f *fakeReprocessor) ReprocessGallery(ctx context.Context, photographerID, galleryID string) (*reprocess.Progress, error) {
+	f.galleries = append(f.galleries, galleryID)
+	return &reprocess.Progress{GalleryID: galleryID}, nil
+}
+
+func TestUpdateReprocessesOnWatermarkChange(t *testing.T) {
+	reprocessor := &fakeReprocessor{}
+	service := NewService(newMockGalleryRepo(), newMockPhotoRepo(), &mockStorageService{}, nil).WithReprocessing(reprocessor)
+	ctx := context.Background()
+
+	gallery, err := service.Create(ctx, CreateGalleryRequest{
+		PhotographerID: "user_123", Name: "Wedding", CustomURL: "wedding", Password: "password",
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	update := func(req UpdateGalleryRequest) {
+		t.Helper()
+		if _, err := service.Update(ctx, "user_123", gallery.GalleryID, req); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+	}
+	enabled, disabled, text, name := true, false, "© Studio", "Renamed"
+
+	update(UpdateGalleryRequest{Name: &name})
+	update(UpdateGalleryRequest{WatermarkText: &text})
+	if len(reprocessor.galleries) != 0 {
+		t.Errorf("reprocessed %v, want nothing while the watermark is off", reprocessor.galleries)
+	}
+
+	update(UpdateGalleryRequest{EnableWatermark: &enabled})
+	if len(reprocessor.galleries) != 1 {
+		t.Fatalf("reprocessed %v, want the gallery once its watermark is enabled", reprocessor.galleries)
+	}
+
+	update(UpdateGalleryRequest{WatermarkText: &text})
+	if len(reprocessor.galleries) != 1 {
+		t.Errorf("reprocessed %v, want nothing when the watermark is unchanged", reprocessor.galleries)
+	}
+
+	update(UpdateGalleryRequest{EnableWatermark: &disabled})
+	if len(reprocessor.galleries) != 2 {
+		t.Errorf("reprocessed %v, want the gallery again once its watermark is removed", reprocessor.galleries)
+	}
+}
+
+func TestUpdateReprocessesOnThumbnailCropChange(t *testing.T) {
+	reprocessor := &fakeReprocessor{}
+	service := NewService(newMockGalleryRepo(), newMockPhotoRepo(), &mockStorageService{}, nil).WithReprocessing(reprocessor)
+	ctx := context.Background()
+
+	gallery, err := service.Create(ctx, CreateGalleryRequest{
+		PhotographerID: "user_123", Name: "Wedding", CustomURL: "wedding", Password: "password",
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	center, smart := repository.ThumbnailCropCenter, repository.ThumbnailCropSmart
+	if _, err := service.Update(ctx, "user_123", gallery.GalleryID, UpdateGalleryRequest{ThumbnailCrop: &center}); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if len(reprocessor.galleries) != 0 {
+		t.Errorf("reprocessed %v, want nothing when switching to the default crop", reprocessor.galleries)
+	}
+
+	if _, err := service.Update(ctx, "user_123", gallery.GalleryID, UpdateGalleryRequest{ThumbnailCrop: &smart}); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if len(reprocessor.galleries) != 1 {
+		t.Errorf("reprocessed %v, want the gallery once its thumbnails are smart-cropped", reprocessor.galleries)
+	}
+}
+
+func TestUpdateReprocessesOnProfileChange(t *testing.T) {
+	reprocessor := &fakeReprocessor{}
+	profiles := mocks.NewMockProcessingProfileRepository()
+	profiles.AddProfile(&repository.ProcessingProfile{ProfileID: "prof_web", PhotographerID: "user_123", Name: "web"})
+	profiles.AddProfile(&repository.ProcessingProfile{ProfileID: "prof_other", PhotographerID: "user_456", Name: "print"})
+	service := NewService(newMockGalleryRepo(), newMockPhotoRepo(), &mockStorageService{}, nil).
+		WithReprocessing(reprocessor).WithProfiles(profiles)
+	ctx := context.Background()
+
+	gallery, err := service.Create(ctx, CreateGalleryRequest{
+		PhotographerID: "user_123", Name: "Wedding", CustomURL: "wedding", Password: "password",
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	update := func(profileID string) (*repository.Gallery, error) {
+		return service.Update(ctx, "user_123", gallery.GalleryID, UpdateGalleryRequest{ProcessingProfileID: &profileID})
+	}
+
+	// Galleries can only use their photographer's own profiles
+	for _, id := range []string{"prof_missing", "prof_other"} {
+		_, err = update(id)
+		assertErrorCode(t, err, 400)
+	}
+	if len(reprocessor.galleries) != 0 {
+		t.Fatalf("reprocessed %v after an unknown profile was rejected", reprocessor.galleries)
+	}
+
+	if _, err := update("prof_web"); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if _, err := update("prof_web"); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if len(reprocessor.galleries) != 1 {
+		t.Fatalf("reprocessed %v, want the gallery once, when its profile was set", reprocessor.galleries)
+	}
+
+	updated, err := update("")
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if updated.ProcessingProfileID != "" || len(reprocessor.galleries) != 2 {
+		t.Errorf("profile = %q after %d reprocesses, want it removed and the gallery reprocessed", updated.ProcessingProfileID, len(reprocessor.galleries))
+	}
+}
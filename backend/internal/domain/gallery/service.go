@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
 	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
 	"photographer-gallery/backend/pkg/logger"
 	"photographer-gallery/backend/pkg/utils"
 )
@@ -16,34 +19,73 @@ const photoDeletionBatchSize = 100
 
 // StorageService defines the interface for storage operations.
 type StorageService interface {
-	DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string) error
+	DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error
+	ArchivePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error
+	RestoreOriginal(ctx context.Context, originalKey string) (bool, error)
+}
+
+// ProcessingQueue queues photos for the image processor.
+type ProcessingQueue interface {
+	Enqueue(ctx context.Context, originalKey string) error
 }
 
 // Service handles gallery business logic.
 type Service struct {
-	galleryRepo    repository.GalleryRepository
-	photoRepo      repository.PhotoRepository
-	storageService StorageService
+	galleryRepo     repository.GalleryRepository
+	photoRepo       repository.PhotoRepository
+	storageService  StorageService
+	processingQueue ProcessingQueue
+	trashRepo       repository.TrashRepository
+	trashRetention  time.Duration
+	quota           *quota.Service
+	plans           *plan.Service
+	reprocessor     Reprocessor
+	profiles        repository.ProcessingProfileRepository
 }
 
 // NewService creates a new gallery service.
-func NewService(galleryRepo repository.GalleryRepository, photoRepo repository.PhotoRepository, storageService StorageService) *Service {
-	return &Service{galleryRepo: galleryRepo, photoRepo: photoRepo, storageService: storageService}
+func NewService(galleryRepo repository.GalleryRepository, photoRepo repository.PhotoRepository, storageService StorageService, processingQueue ProcessingQueue) *Service {
+	return &Service{galleryRepo: galleryRepo, photoRepo: photoRepo, storageService: storageService, processingQueue: processingQueue}
+}
+
+// WithQuota makes the service release photographers' storage usage as galleries are deleted
+// and archived.
+func (s *Service) WithQuota(q *quota.Service) *Service {
+	s.quota = q
+	return s
+}
+
+// WithProfiles lets galleries use the photographer's stored processing profiles.
+func (s *Service) WithProfiles(profiles repository.ProcessingProfileRepository) *Service {
+	s.profiles = profiles
+	return s
 }
 
 // CreateGalleryRequest represents the request to create a gallery.
 type CreateGalleryRequest struct {
-	PhotographerID, Name, Description, CustomURL, Password string
-	ExpiresAt                                              *time.Time
-	EnableWatermark                                        bool
-	WatermarkText, WatermarkPosition                       string
+	PhotographerID, Name, Description, CustomURL, Password              string
+	ExpiresAt                                                           *time.Time
+	EnableWatermark, ProofingEnabled                                    bool
+	WatermarkText, WatermarkPosition                                    string
+	WatermarkFont, WatermarkColor, WatermarkMode, WatermarkType         string
+	WatermarkSize, WatermarkOpacity, WatermarkMargin, WatermarkRotation float64
+	WatermarkScale                                                      float64
+	ThumbnailCrop, DownloadPolicy                                       string
+	SelectionLimit                                                      int
+	ProcessingProfileID                                                 string
 }
 
 // UpdateGalleryRequest represents the request to update a gallery.
 type UpdateGalleryRequest struct {
-	Name, Description, Password, WatermarkText, WatermarkPosition *string
-	ExpiresAt                                                     *time.Time
-	EnableWatermark                                               *bool
+	Name, Description, Password, WatermarkText, WatermarkPosition       *string
+	WatermarkFont, WatermarkColor, WatermarkMode, WatermarkType         *string
+	WatermarkSize, WatermarkOpacity, WatermarkMargin, WatermarkRotation *float64
+	WatermarkScale                                                      *float64
+	ThumbnailCrop, DownloadPolicy                                       *string
+	ExpiresAt                                                           *time.Time
+	EnableWatermark, ProofingEnabled                                    *bool
+	SelectionLimit                                                      *int
+	ProcessingProfileID                                                 *string // empty removes the gallery's profile
 }
 
 // Create creates a new gallery.
@@ -53,6 +95,40 @@ func (s *Service) Create(ctx context.Context, req CreateGalleryRequest) (*reposi
 	} else if !utils.ValidateCustomURL(req.CustomURL) {
 		return nil, errors.NewBadRequest("Invalid custom URL format")
 	}
+	if !isValidDownloadPolicy(req.DownloadPolicy) {
+		return nil, errors.NewBadRequest("Invalid download policy")
+	}
+	if !isValidThumbnailCrop(req.ThumbnailCrop) {
+		return nil, errors.NewBadRequest("Invalid thumbnail crop, expected center or smart")
+	}
+	if req.SelectionLimit < 0 {
+		return nil, errors.NewBadRequest("Selection limit cannot be negative")
+	}
+	if err := NewWatermarkValidator().Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkProfile(ctx, req.PhotographerID, req.ProcessingProfileID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entitlements, err := s.plans.For(ctx, req.PhotographerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.EnableWatermark {
+		if err := entitlements.CheckWatermark(); err != nil {
+			return nil, err
+		}
+	}
+	if req.ExpiresAt == nil {
+		req.ExpiresAt = entitlements.DefaultExpiration(now)
+	} else if err := entitlements.CheckExpiration(req.ExpiresAt, now); err != nil {
+		return nil, err
+	}
+	if err := s.checkCanActivate(ctx, entitlements, req.PhotographerID); err != nil {
+		return nil, err
+	}
 
 	if existing, _ := s.galleryRepo.GetByCustomURL(ctx, req.CustomURL); existing != nil {
 		return nil, errors.New(409, "Custom URL already exists")
@@ -64,21 +140,44 @@ func (s *Service) Create(ctx context.Context, req CreateGalleryRequest) (*reposi
 	}
 
 	gallery := &repository.Gallery{
-		GalleryID:         utils.GenerateID("gal"),
-		PhotographerID:    req.PhotographerID,
-		Name:              req.Name,
-		Description:       req.Description,
-		CustomURL:         req.CustomURL,
-		Password:          string(hashedPassword),
-		CreatedAt:         time.Now(),
-		ExpiresAt:         req.ExpiresAt,
-		Status:            "active",
-		EnableWatermark:   req.EnableWatermark,
-		WatermarkText:     req.WatermarkText,
-		WatermarkPosition: req.WatermarkPosition,
-	}
-
-	if err := s.galleryRepo.Create(ctx, gallery); err != nil {
+		GalleryID:           utils.GenerateID("gal"),
+		PhotographerID:      req.PhotographerID,
+		Name:                req.Name,
+		Description:         req.Description,
+		CustomURL:           req.CustomURL,
+		Password:            string(hashedPassword),
+		CreatedAt:           now,
+		ExpiresAt:           req.ExpiresAt,
+		Status:              repository.GalleryStatusActive,
+		EnableWatermark:     req.EnableWatermark,
+		WatermarkText:       req.WatermarkText,
+		WatermarkPosition:   req.WatermarkPosition,
+		WatermarkFont:       req.WatermarkFont,
+		WatermarkSize:       req.WatermarkSize,
+		WatermarkColor:      req.WatermarkColor,
+		WatermarkOpacity:    req.WatermarkOpacity,
+		WatermarkMargin:     req.WatermarkMargin,
+		WatermarkMode:       req.WatermarkMode,
+		WatermarkRotation:   req.WatermarkRotation,
+		WatermarkType:       req.WatermarkType,
+		WatermarkScale:      req.WatermarkScale,
+		ThumbnailCrop:       req.ThumbnailCrop,
+		DownloadPolicy:      req.DownloadPolicy,
+		ProofingEnabled:     req.ProofingEnabled,
+		SelectionLimit:      req.SelectionLimit,
+		ProcessingProfileID: req.ProcessingProfileID,
+	}
+
+	writeCtx, err := repository.WithOutboxEvents(ctx, events.NewEvent(events.GalleryCreated, &events.GalleryCreatedPayload{
+		GalleryID:      gallery.GalleryID,
+		PhotographerID: gallery.PhotographerID,
+		Name:           gallery.Name,
+	}, gallery.GalleryID))
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to record gallery event")
+	}
+
+	if err := s.galleryRepo.Create(writeCtx, gallery); err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to create gallery")
 	}
 	logger.Info("Gallery created", map[string]interface{}{"galleryId": gallery.GalleryID})
@@ -115,25 +214,78 @@ func (s *Service) ListByPhotographer(ctx context.Context, photographerID string,
 	if err != nil {
 		return nil, nil, errors.Wrap(err, 500, "Failed to list galleries")
 	}
-	return galleries, nextKey, nil
+
+	// Galleries in the trash are listed with the trash; pages may come back short
+	visible := galleries[:0]
+	for _, gallery := range galleries {
+		if gallery.Status != repository.GalleryStatusDeleted {
+			visible = append(visible, gallery)
+		}
+	}
+	return visible, nextKey, nil
 }
 
-// Update updates a gallery.
-func (s *Service) Update(ctx context.Context, galleryID string, req UpdateGalleryRequest) (*repository.Gallery, error) {
-	gallery, err := s.GetByID(ctx, galleryID)
+// Update updates a gallery owned by the photographer.
+func (s *Service) Update(ctx context.Context, photographerID, galleryID string, req UpdateGalleryRequest) (*repository.Gallery, error) {
+	gallery, err := s.GetForPhotographer(ctx, photographerID, galleryID)
 	if err != nil {
 		return nil, err
 	}
+	if req.DownloadPolicy != nil && !isValidDownloadPolicy(*req.DownloadPolicy) {
+		return nil, errors.NewBadRequest("Invalid download policy")
+	}
+	if req.ThumbnailCrop != nil && !isValidThumbnailCrop(*req.ThumbnailCrop) {
+		return nil, errors.NewBadRequest("Invalid thumbnail crop, expected center or smart")
+	}
+	if req.SelectionLimit != nil && *req.SelectionLimit < 0 {
+		return nil, errors.NewBadRequest("Selection limit cannot be negative")
+	}
+	if err := NewWatermarkValidator().Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	if req.ProcessingProfileID != nil {
+		if err := s.checkProfile(ctx, photographerID, *req.ProcessingProfileID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkUpdateEntitlements(ctx, gallery, req); err != nil {
+		return nil, err
+	}
 
+	processing := processingOf(gallery)
 	s.applyUpdates(gallery, req)
 
 	if err := s.galleryRepo.Update(ctx, gallery); err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to update gallery")
 	}
 	logger.Info("Gallery updated", map[string]interface{}{"galleryId": gallery.GalleryID})
+	if processingOf(gallery) != processing {
+		s.reprocessChanged(ctx, gallery)
+	}
 	return gallery, nil
 }
 
+// checkUpdateEntitlements checks the settings an update changes against the photographer's plan.
+// Settings left alone are not rechecked, so galleries set up under a higher plan keep working.
+func (s *Service) checkUpdateEntitlements(ctx context.Context, gallery *repository.Gallery, req UpdateGalleryRequest) error {
+	if req.ExpiresAt == nil && (req.EnableWatermark == nil || !*req.EnableWatermark) {
+		return nil
+	}
+	entitlements, err := s.plans.For(ctx, gallery.PhotographerID)
+	if err != nil {
+		return err
+	}
+	if req.EnableWatermark != nil && *req.EnableWatermark && !gallery.EnableWatermark {
+		if err := entitlements.CheckWatermark(); err != nil {
+			return err
+		}
+	}
+	if req.ExpiresAt != nil {
+		return entitlements.CheckExpiration(req.ExpiresAt, time.Now())
+	}
+	return nil
+}
+
 func (s *Service) applyUpdates(gallery *repository.Gallery, req UpdateGalleryRequest) {
 	if req.Name != nil {
 		gallery.Name = *req.Name
@@ -158,28 +310,108 @@ func (s *Service) applyUpdates(gallery *repository.Gallery, req UpdateGalleryReq
 	if req.WatermarkPosition != nil {
 		gallery.WatermarkPosition = *req.WatermarkPosition
 	}
+	if req.WatermarkFont != nil {
+		gallery.WatermarkFont = *req.WatermarkFont
+	}
+	if req.WatermarkSize != nil {
+		gallery.WatermarkSize = *req.WatermarkSize
+	}
+	if req.WatermarkColor != nil {
+		gallery.WatermarkColor = *req.WatermarkColor
+	}
+	if req.WatermarkOpacity != nil {
+		gallery.WatermarkOpacity = *req.WatermarkOpacity
+	}
+	if req.WatermarkMargin != nil {
+		gallery.WatermarkMargin = *req.WatermarkMargin
+	}
+	if req.WatermarkMode != nil {
+		gallery.WatermarkMode = *req.WatermarkMode
+	}
+	if req.WatermarkRotation != nil {
+		gallery.WatermarkRotation = *req.WatermarkRotation
+	}
+	if req.WatermarkType != nil {
+		gallery.WatermarkType = *req.WatermarkType
+	}
+	if req.WatermarkScale != nil {
+		gallery.WatermarkScale = *req.WatermarkScale
+	}
+	if req.ThumbnailCrop != nil {
+		gallery.ThumbnailCrop = *req.ThumbnailCrop
+	}
+	if req.DownloadPolicy != nil {
+		gallery.DownloadPolicy = *req.DownloadPolicy
+	}
+	if req.ProofingEnabled != nil {
+		gallery.ProofingEnabled = *req.ProofingEnabled
+	}
+	if req.SelectionLimit != nil {
+		gallery.SelectionLimit = *req.SelectionLimit
+	}
+	if req.ProcessingProfileID != nil {
+		gallery.ProcessingProfileID = *req.ProcessingProfileID
+	}
 }
 
-// Delete deletes a gallery and all its photos.
-func (s *Service) Delete(ctx context.Context, galleryID string) error {
-	gallery, err := s.GetByID(ctx, galleryID)
+// checkProfile checks that a profile a gallery is set to use is one of the photographer's.
+// An empty ID leaves the gallery on the default processing.
+func (s *Service) checkProfile(ctx context.Context, photographerID, profileID string) error {
+	if profileID == "" {
+		return nil
+	}
+	if s.profiles == nil {
+		return errors.NewBadRequest("Processing profiles are not available")
+	}
+	p, err := s.profiles.GetByID(ctx, profileID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to get processing profile")
+	}
+	if p == nil || p.PhotographerID != photographerID {
+		return errors.NewBadRequest("Unknown processing profile")
+	}
+	return nil
+}
+
+// Delete deletes a gallery owned by the photographer and all its photos.
+func (s *Service) Delete(ctx context.Context, photographerID, galleryID string) error {
+	gallery, err := s.GetForPhotographer(ctx, photographerID, galleryID)
 	if err != nil {
 		return err
 	}
+	if s.trashRepo != nil {
+		return s.trashGallery(ctx, gallery)
+	}
+	return s.deleteGallery(ctx, gallery)
+}
 
-	photos, err := s.fetchAllPhotos(ctx, galleryID)
+// deleteGallery removes a gallery and its photos. Extra events are recorded in the same
+// write as the gallery.deleted event.
+func (s *Service) deleteGallery(ctx context.Context, gallery *repository.Gallery, extra ...events.Event) error {
+	photos, err := s.fetchAllPhotos(ctx, gallery.GalleryID)
 	if err != nil {
 		return errors.Wrap(err, 500, "Failed to list photos for deletion")
 	}
 
-	failedS3, failedDB := s.deletePhotos(ctx, photos)
+	freed, failedS3, failedDB := s.deletePhotos(ctx, photos)
+	s.quota.Record(ctx, gallery.PhotographerID, -freed)
 	if failedS3 > 0 || failedDB > 0 {
 		logger.Warn("Gallery deletion completed with failures", map[string]interface{}{
 			"galleryId": gallery.GalleryID, "failedS3": failedS3, "failedDB": failedDB,
 		})
 	}
 
-	if err := s.galleryRepo.Delete(ctx, galleryID); err != nil {
+	deleted := events.NewEvent(events.GalleryDeleted, &events.GalleryDeletedPayload{
+		GalleryID:      gallery.GalleryID,
+		PhotographerID: gallery.PhotographerID,
+		PhotoCount:     len(photos),
+	}, gallery.GalleryID)
+	writeCtx, err := repository.WithOutboxEvents(ctx, append(extra, deleted)...)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to record gallery event")
+	}
+
+	if err := s.galleryRepo.Delete(writeCtx, gallery.GalleryID); err != nil {
 		return errors.Wrap(err, 500, "Failed to delete gallery")
 	}
 	logger.Info("Gallery deleted", map[string]interface{}{"galleryId": gallery.GalleryID, "photos": len(photos)})
@@ -203,16 +435,20 @@ func (s *Service) fetchAllPhotos(ctx context.Context, galleryID string) ([]*repo
 	return all, nil
 }
 
-func (s *Service) deletePhotos(ctx context.Context, photos []*repository.Photo) (failedS3, failedDB int) {
+// deletePhotos deletes photos and their files, returning the bytes freed by the photo records
+// that were removed.
+func (s *Service) deletePhotos(ctx context.Context, photos []*repository.Photo) (freed int64, failedS3, failedDB int) {
 	for _, photo := range photos {
 		if s.storageService != nil {
-			if err := s.storageService.DeletePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey); err != nil {
+			if err := s.storageService.DeletePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey, photo.RenditionKeys()...); err != nil {
 				failedS3++
 			}
 		}
 		if err := s.photoRepo.Delete(ctx, photo.PhotoID); err != nil {
 			failedDB++
+			continue
 		}
+		freed += quota.PhotoBytes(photo)
 	}
 	return
 }
@@ -224,11 +460,8 @@ func (s *Service) VerifyPassword(ctx context.Context, customURL, password string
 		return nil, err
 	}
 
-	if gallery.Status != "active" {
-		return nil, errors.NewBadRequest("Gallery is not active")
-	}
-	if gallery.ExpiresAt != nil && gallery.ExpiresAt.Before(time.Now()) {
-		return nil, errors.NewBadRequest("Gallery has expired")
+	if err := checkClientAccess(gallery); err != nil {
+		return nil, err
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(gallery.Password), []byte(password)); err != nil {
 		return nil, errors.NewUnauthorized("Invalid password")
@@ -238,12 +471,45 @@ func (s *Service) VerifyPassword(ctx context.Context, customURL, password string
 	return gallery, nil
 }
 
-// SetExpiration sets the expiration date for a gallery.
-func (s *Service) SetExpiration(ctx context.Context, galleryID string, expiresAt *time.Time) (*repository.Gallery, error) {
+// CheckClientAccess verifies that clients may still use a gallery they hold a session for.
+// Sessions outlive the gallery being archived or expiring.
+func (s *Service) CheckClientAccess(ctx context.Context, galleryID string) error {
 	gallery, err := s.GetByID(ctx, galleryID)
+	if err != nil {
+		return err
+	}
+	return checkClientAccess(gallery)
+}
+
+func checkClientAccess(gallery *repository.Gallery) error {
+	if gallery.Status == repository.GalleryStatusDeleted {
+		return errors.NewNotFound("Gallery")
+	}
+	if gallery.Status == repository.GalleryStatusArchived || gallery.Status == repository.GalleryStatusRestoring {
+		return errors.NewBadRequest("Gallery has been archived")
+	}
+	if gallery.Status != repository.GalleryStatusActive {
+		return errors.NewBadRequest("Gallery is not active")
+	}
+	if gallery.ExpiresAt != nil && gallery.ExpiresAt.Before(time.Now()) {
+		return errors.NewBadRequest("Gallery has expired")
+	}
+	return nil
+}
+
+// SetExpiration sets the expiration date for a gallery owned by the photographer.
+func (s *Service) SetExpiration(ctx context.Context, photographerID, galleryID string, expiresAt *time.Time) (*repository.Gallery, error) {
+	gallery, err := s.GetForPhotographer(ctx, photographerID, galleryID)
 	if err != nil {
 		return nil, err
 	}
+	entitlements, err := s.plans.For(ctx, gallery.PhotographerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := entitlements.CheckExpiration(expiresAt, time.Now()); err != nil {
+		return nil, err
+	}
 	gallery.ExpiresAt = expiresAt
 	if err := s.galleryRepo.Update(ctx, gallery); err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to update gallery expiration")
@@ -252,7 +518,7 @@ func (s *Service) SetExpiration(ctx context.Context, galleryID string, expiresAt
 	return gallery, nil
 }
 
-// ProcessExpiredGalleries processes galleries that have expired.
+// ProcessExpiredGalleries archives galleries that have expired.
 func (s *Service) ProcessExpiredGalleries(ctx context.Context, limit int) error {
 	galleries, err := s.galleryRepo.ListExpired(ctx, limit)
 	if err != nil {
@@ -261,14 +527,24 @@ func (s *Service) ProcessExpiredGalleries(ctx context.Context, limit int) error
 
 	var errorCount int
 	for _, gallery := range galleries {
-		if err := s.Delete(ctx, gallery.GalleryID); err != nil {
-			logger.Error("Failed to delete expired gallery", map[string]interface{}{
+		expiredAt := time.Now()
+		if gallery.ExpiresAt != nil {
+			expiredAt = *gallery.ExpiresAt
+		}
+		expired := events.NewEvent(events.GalleryExpired, &events.GalleryExpiredPayload{
+			GalleryID:      gallery.GalleryID,
+			PhotographerID: gallery.PhotographerID,
+			Name:           gallery.Name,
+			ExpiredAt:      expiredAt,
+		}, gallery.GalleryID)
+		if err := s.archiveGallery(ctx, gallery, expired); err != nil {
+			logger.Error("Failed to archive expired gallery", map[string]interface{}{
 				"galleryId": gallery.GalleryID, "error": err.Error(),
 			})
 			errorCount++
 			continue
 		}
-		logger.Info("Deleted expired gallery", map[string]interface{}{"galleryId": gallery.GalleryID})
+		logger.Info("Archived expired gallery", map[string]interface{}{"galleryId": gallery.GalleryID})
 	}
 
 	if errorCount > 0 {
@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
 	"photographer-gallery/backend/pkg/errors"
 )
 
@@ -121,6 +122,19 @@ func (m *mockGalleryRepo) ListExpired(ctx context.Context, limit int) ([]*reposi
 	return result, nil
 }
 
+func (m *mockGalleryRepo) ListByStatus(ctx context.Context, status string, limit int) ([]*repository.Gallery, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var result []*repository.Gallery
+	for _, g := range m.galleries {
+		if g.Status == status {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
 type mockPhotoRepo struct {
 	photos    map[string]*repository.Photo
 	deleteErr error
@@ -160,13 +174,16 @@ func (m *mockPhotoRepo) IncrementFavoriteCount(ctx context.Context, photoID stri
 	return nil
 }
 func (m *mockPhotoRepo) IncrementDownloadCount(ctx context.Context, photoID string) error { return nil }
+func (m *mockPhotoRepo) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	return nil, nil, nil
+}
 
 type mockStorageService struct {
 	deletePhotoErr error
 	deletedPhotos  []string
 }
 
-func (m *mockStorageService) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string) error {
+func (m *mockStorageService) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
 	if m.deletePhotoErr != nil {
 		return m.deletePhotoErr
 	}
@@ -174,12 +191,28 @@ func (m *mockStorageService) DeletePhoto(ctx context.Context, originalKey, optim
 	return nil
 }
 
+func (m *mockStorageService) ArchivePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
+	return nil
+}
+
+func (m *mockStorageService) RestoreOriginal(ctx context.Context, originalKey string) (bool, error) {
+	return true, nil
+}
+
+// newTestService creates a service backed by the shared mocks
+func newTestService() (*Service, *mocks.MockGalleryRepository, *mocks.MockPhotoRepository, *mocks.MockStorageService) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	storageService := mocks.NewMockStorageService()
+	return NewService(galleryRepo, photoRepo, storageService, mocks.NewMockProcessingQueue()), galleryRepo, photoRepo, storageService
+}
+
 // Tests
 func TestCreateGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	tests := []struct {
 		name    string
@@ -217,6 +250,37 @@ func TestCreateGallery(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid download policy",
+			req: CreateGalleryRequest{
+				PhotographerID: "user_123",
+				Name:           "Test Gallery",
+				Password:       "secure123",
+				DownloadPolicy: "everything",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid thumbnail crop",
+			req: CreateGalleryRequest{
+				PhotographerID: "user_123",
+				Name:           "Test Gallery",
+				Password:       "secure123",
+				ThumbnailCrop:  "faces",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative selection limit",
+			req: CreateGalleryRequest{
+				PhotographerID:  "user_123",
+				Name:            "Test Gallery",
+				Password:        "secure123",
+				ProofingEnabled: true,
+				SelectionLimit:  -1,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -271,7 +335,7 @@ func TestCreateGalleryDuplicateURL(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create first gallery
 	req1 := CreateGalleryRequest{
@@ -307,7 +371,7 @@ func TestVerifyPassword(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create a gallery
 	req := CreateGalleryRequest{
@@ -371,7 +435,7 @@ func TestVerifyPasswordExpiredGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create expired gallery
 	expiresAt := time.Now().Add(-24 * time.Hour)
@@ -394,7 +458,7 @@ func TestUpdateGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create a gallery
 	createReq := CreateGalleryRequest{
@@ -416,7 +480,7 @@ func TestUpdateGallery(t *testing.T) {
 		Password:    &newPass,
 	}
 
-	updated, err := service.Update(context.Background(), gallery.GalleryID, updateReq)
+	updated, err := service.Update(context.Background(), "user_123", gallery.GalleryID, updateReq)
 	if err != nil {
 		t.Fatalf("Update() error: %v", err)
 	}
@@ -433,13 +497,40 @@ func TestUpdateGallery(t *testing.T) {
 	if err := bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte(newPass)); err != nil {
 		t.Error("New password was not hashed correctly")
 	}
+
+	// Download policy must be one of the known values
+	invalid := "everything"
+	if _, err := service.Update(context.Background(), "user_123", gallery.GalleryID, UpdateGalleryRequest{DownloadPolicy: &invalid}); err == nil {
+		t.Error("Update() should reject an unknown download policy")
+	}
+	originals := "originals"
+	updated, err = service.Update(context.Background(), "user_123", gallery.GalleryID, UpdateGalleryRequest{DownloadPolicy: &originals})
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if updated.DownloadPolicy != originals {
+		t.Errorf("DownloadPolicy = %v, want %v", updated.DownloadPolicy, originals)
+	}
+
+	// Proofing mode with a selection limit
+	proofing, limit, negative := true, 40, -5
+	if _, err := service.Update(context.Background(), "user_123", gallery.GalleryID, UpdateGalleryRequest{SelectionLimit: &negative}); err == nil {
+		t.Error("Update() should reject a negative selection limit")
+	}
+	updated, err = service.Update(context.Background(), "user_123", gallery.GalleryID, UpdateGalleryRequest{ProofingEnabled: &proofing, SelectionLimit: &limit})
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if !updated.ProofingEnabled || updated.SelectionLimit != limit {
+		t.Errorf("ProofingEnabled = %v, SelectionLimit = %d, want true and %d", updated.ProofingEnabled, updated.SelectionLimit, limit)
+	}
 }
 
 func TestDeleteGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create a gallery
 	req := CreateGalleryRequest{
@@ -451,7 +542,7 @@ func TestDeleteGallery(t *testing.T) {
 	gallery, _ := service.Create(context.Background(), req)
 
 	// Delete gallery
-	err := service.Delete(context.Background(), gallery.GalleryID)
+	err := service.Delete(context.Background(), "user_123", gallery.GalleryID)
 	if err != nil {
 		t.Fatalf("Delete() error: %v", err)
 	}
@@ -467,7 +558,7 @@ func TestSetExpiration(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create a gallery
 	req := CreateGalleryRequest{
@@ -480,7 +571,7 @@ func TestSetExpiration(t *testing.T) {
 
 	// Set expiration
 	expiresAt := time.Now().Add(30 * 24 * time.Hour)
-	updated, err := service.SetExpiration(context.Background(), gallery.GalleryID, &expiresAt)
+	updated, err := service.SetExpiration(context.Background(), "user_123", gallery.GalleryID, &expiresAt)
 	if err != nil {
 		t.Fatalf("SetExpiration() error: %v", err)
 	}
@@ -498,7 +589,7 @@ func TestProcessExpiredGalleries(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create expired gallery
 	pastTime := time.Now().Add(-24 * time.Hour)
@@ -517,10 +608,13 @@ func TestProcessExpiredGalleries(t *testing.T) {
 		t.Fatalf("ProcessExpiredGalleries() error: %v", err)
 	}
 
-	// Verify gallery was deleted (ProcessExpiredGalleries deletes expired galleries)
+	// Verify gallery was archived rather than deleted
 	updated, err := service.GetByID(context.Background(), gallery.GalleryID)
-	if err == nil && updated != nil {
-		t.Errorf("Expected gallery to be deleted, but it still exists")
+	if err != nil {
+		t.Fatalf("Expected archived gallery to be kept, got error: %v", err)
+	}
+	if updated.Status != "archived" || updated.ArchivedAt == nil {
+		t.Errorf("Status = %q, ArchivedAt = %v, want archived with a timestamp", updated.Status, updated.ArchivedAt)
 	}
 }
 
@@ -528,7 +622,7 @@ func TestListByPhotographer(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	photographerID := "user_123"
 
@@ -557,7 +651,7 @@ func TestDeleteGalleryWithPhotos(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	photoRepo := newMockPhotoRepo()
 	storageService := &mockStorageService{deletedPhotos: []string{}}
-	service := NewService(galleryRepo, photoRepo, storageService)
+	service := NewService(galleryRepo, photoRepo, storageService, nil)
 
 	// Create a gallery
 	req := CreateGalleryRequest{
@@ -591,7 +685,7 @@ func TestDeleteGalleryWithPhotos(t *testing.T) {
 	}
 
 	// Delete gallery
-	err := service.Delete(context.Background(), gallery.GalleryID)
+	err := service.Delete(context.Background(), "user_123", gallery.GalleryID)
 	if err != nil {
 		t.Fatalf("Delete() error: %v", err)
 	}
@@ -0,0 +1,108 @@
+package gallery
+
+import (
+	"context"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// WithTrash makes Delete move galleries to the photographer's trash, where they are kept for
+// retention before being purged. Without it galleries are deleted immediately.
+func (s *Service) WithTrash(trashRepo repository.TrashRepository, retention time.Duration) *Service {
+	s.trashRepo = trashRepo
+	s.trashRetention = retention
+	return s
+}
+
+// trashGallery marks a gallery deleted and records it in the trash. Its photos, favorites and
+// counters are left untouched so that restoring it brings everything back.
+func (s *Service) trashGallery(ctx context.Context, gallery *repository.Gallery) error {
+	if gallery.Status == repository.GalleryStatusRestoring {
+		return errors.NewConflict("Gallery is being restored")
+	}
+
+	now := time.Now()
+	item := &repository.TrashItem{
+		PhotographerID: gallery.PhotographerID,
+		ItemID:         gallery.GalleryID,
+		ItemType:       repository.TrashItemGallery,
+		GalleryID:      gallery.GalleryID,
+		Name:           gallery.Name,
+		PhotoCount:     gallery.PhotoCount,
+		Size:           gallery.TotalSize,
+		DeletedAt:      now,
+		PurgeAt:        now.Add(s.trashRetention),
+	}
+	if err := s.trashRepo.Create(ctx, item); err != nil {
+		return errors.Wrap(err, 500, "Failed to move gallery to trash")
+	}
+
+	previous := gallery.Status
+	gallery.Status = repository.GalleryStatusDeleted
+	gallery.DeletedAt = &now
+	if err := s.galleryRepo.Update(ctx, gallery); err != nil {
+		gallery.Status, gallery.DeletedAt = previous, nil
+		if rollbackErr := s.trashRepo.Delete(ctx, gallery.PhotographerID, gallery.GalleryID); rollbackErr != nil {
+			logger.Warn("Failed to remove trash item of gallery left in place", map[string]interface{}{
+				"galleryId": gallery.GalleryID, "error": rollbackErr.Error(),
+			})
+		}
+		return errors.Wrap(err, 500, "Failed to move gallery to trash")
+	}
+
+	logger.Info("Gallery moved to trash", map[string]interface{}{
+		"galleryId": gallery.GalleryID, "purgeAt": item.PurgeAt,
+	})
+	return nil
+}
+
+// RestoreDeleted takes a gallery owned by the photographer out of the trash. Galleries that
+// were archived before being deleted are restored as archived; others only if the photographer's
+// plan allows another active gallery.
+func (s *Service) RestoreDeleted(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.getOwned(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	if gallery.Status != repository.GalleryStatusDeleted {
+		return nil, errors.NewConflict("Gallery is not in the trash")
+	}
+
+	if gallery.ArchivedAt == nil {
+		entitlements, err := s.plans.For(ctx, photographerID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkCanActivate(ctx, entitlements, photographerID); err != nil {
+			return nil, err
+		}
+	}
+
+	gallery.Status = repository.GalleryStatusActive
+	if gallery.ArchivedAt != nil {
+		gallery.Status = repository.GalleryStatusArchived
+	}
+	gallery.DeletedAt = nil
+	if err := s.galleryRepo.Update(ctx, gallery); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to restore gallery")
+	}
+
+	logger.Info("Gallery restored from trash", map[string]interface{}{"galleryId": gallery.GalleryID})
+	return gallery, nil
+}
+
+// PurgeDeleted permanently deletes a gallery in the trash along with its photos. Galleries that
+// are gone or no longer in the trash are left alone.
+func (s *Service) PurgeDeleted(ctx context.Context, galleryID string) error {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery == nil || gallery.Status != repository.GalleryStatusDeleted {
+		return nil
+	}
+	return s.deleteGallery(ctx, gallery)
+}
@@ -3,9 +3,14 @@ package gallery
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"regexp"
+	"strings"
 	"time"
 
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
 	"photographer-gallery/backend/pkg/errors"
 )
 
@@ -158,33 +163,118 @@ func NewWatermarkValidator() *WatermarkValidator {
 	return &WatermarkValidator{}
 }
 
-// Validate checks that watermark settings are valid.
+// Validate checks that watermark settings are valid. Create requests need text when a text
+// watermark is enabled; update requests are checked only for the settings they change.
 func (v *WatermarkValidator) Validate(ctx context.Context, req interface{}) error {
-	createReq, ok := req.(CreateGalleryRequest)
-	if !ok {
-		return v.ValidateNext(ctx, req)
-	}
-
-	if createReq.EnableWatermark {
-		if createReq.WatermarkText == "" {
-			return errors.NewBadRequest("Watermark text is required when watermark is enabled")
-		}
-
-		if createReq.WatermarkPosition != "" {
-			validPositions := map[string]bool{
-				"bottom-right": true,
-				"bottom-left":  true,
-				"center":       true,
+	switch r := req.(type) {
+	case CreateGalleryRequest:
+		if r.EnableWatermark {
+			if r.WatermarkText == "" && r.WatermarkType != repository.WatermarkTypeLogo {
+				return errors.NewBadRequest("Watermark text is required when watermark is enabled")
 			}
-			if !validPositions[createReq.WatermarkPosition] {
-				return errors.NewBadRequest("Invalid watermark position")
+			style := watermarkStyle{
+				kind:     &r.WatermarkType,
+				position: &r.WatermarkPosition, font: &r.WatermarkFont, color: &r.WatermarkColor, mode: &r.WatermarkMode,
+				size: &r.WatermarkSize, opacity: &r.WatermarkOpacity, margin: &r.WatermarkMargin, rotation: &r.WatermarkRotation,
+				scale: &r.WatermarkScale,
 			}
+			if err := style.validate(); err != nil {
+				return err
+			}
+		}
+	case UpdateGalleryRequest:
+		style := watermarkStyle{
+			kind:     r.WatermarkType,
+			position: r.WatermarkPosition, font: r.WatermarkFont, color: r.WatermarkColor, mode: r.WatermarkMode,
+			size: r.WatermarkSize, opacity: r.WatermarkOpacity, margin: r.WatermarkMargin, rotation: r.WatermarkRotation,
+			scale: r.WatermarkScale,
+		}
+		if err := style.validate(); err != nil {
+			return err
 		}
 	}
 
 	return v.ValidateNext(ctx, req)
 }
 
+// watermarkStyle points at the watermark settings of a request; nil settings are not being set.
+// Empty and zero values select the renderer's defaults.
+type watermarkStyle struct {
+	kind, position, font, color, mode      *string
+	size, opacity, margin, rotation, scale *float64
+}
+
+func (w watermarkStyle) validate() error {
+	if w.kind != nil {
+		switch *w.kind {
+		case "", repository.WatermarkTypeText, repository.WatermarkTypeLogo:
+		default:
+			return errors.NewBadRequest("Invalid watermark type, expected text or logo")
+		}
+	}
+	if w.position != nil && *w.position != "" {
+		validPositions := map[string]bool{
+			"bottom-right": true,
+			"bottom-left":  true,
+			"center":       true,
+		}
+		if !validPositions[*w.position] {
+			return errors.NewBadRequest("Invalid watermark position")
+		}
+	}
+	if w.font != nil && *w.font != "" && !image.HasFont(*w.font) {
+		return errors.NewBadRequest(fmt.Sprintf("Unknown watermark font, expected one of: %s", strings.Join(image.FontNames(), ", ")))
+	}
+	if w.color != nil && *w.color != "" {
+		if _, err := image.ParseHexColor(*w.color); err != nil {
+			return errors.NewBadRequest("Watermark color must be a hex color such as #ffffff")
+		}
+	}
+	if w.mode != nil {
+		switch *w.mode {
+		case "", image.WatermarkModeSingle, image.WatermarkModeDiagonal, image.WatermarkModeTiled:
+		default:
+			return errors.NewBadRequest("Invalid watermark mode, expected single, diagonal or tiled")
+		}
+	}
+	if w.size != nil && *w.size != 0 && (*w.size < image.MinWatermarkSize || *w.size > image.MaxWatermarkSize) {
+		return errors.NewBadRequest(fmt.Sprintf("Watermark size must be between %g and %g percent of the photo width", image.MinWatermarkSize, image.MaxWatermarkSize))
+	}
+	if w.opacity != nil && (*w.opacity < 0 || *w.opacity > 1) {
+		return errors.NewBadRequest("Watermark opacity must be between 0 and 1")
+	}
+	if w.margin != nil && (*w.margin < 0 || *w.margin > image.MaxWatermarkMargin) {
+		return errors.NewBadRequest(fmt.Sprintf("Watermark margin must be between 0 and %g percent of the photo width", image.MaxWatermarkMargin))
+	}
+	if w.rotation != nil && math.Abs(*w.rotation) > image.MaxWatermarkRotation {
+		return errors.NewBadRequest(fmt.Sprintf("Watermark rotation must be between -%g and %g degrees", image.MaxWatermarkRotation, image.MaxWatermarkRotation))
+	}
+	if w.scale != nil && *w.scale != 0 && (*w.scale < image.MinLogoScale || *w.scale > image.MaxLogoScale) {
+		return errors.NewBadRequest(fmt.Sprintf("Logo scale must be between %g and %g percent of the photo width", image.MinLogoScale, image.MaxLogoScale))
+	}
+	return nil
+}
+
+// isValidDownloadPolicy reports whether policy is a known download policy.
+// An empty policy keeps the default of optimized downloads only.
+func isValidDownloadPolicy(policy string) bool {
+	switch policy {
+	case "", repository.DownloadPolicyOptimized, repository.DownloadPolicyOriginals, repository.DownloadPolicyNone:
+		return true
+	}
+	return false
+}
+
+// isValidThumbnailCrop reports whether crop is a known thumbnail crop.
+// An empty crop keeps the default center crop.
+func isValidThumbnailCrop(crop string) bool {
+	switch crop {
+	case "", repository.ThumbnailCropCenter, repository.ThumbnailCropSmart:
+		return true
+	}
+	return false
+}
+
 // ValidationChain creates a complete validation chain for gallery creation.
 func NewCreateGalleryValidationChain() Validator {
 	name := NewNameValidator(1, 200)
@@ -0,0 +1,80 @@
+package gallery
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWatermarkValidatorCreate(t *testing.T) {
+	valid := CreateGalleryRequest{
+		EnableWatermark:   true,
+		WatermarkText:     "© Studio",
+		WatermarkPosition: "bottom-left",
+		WatermarkFont:     "sans-bold",
+		WatermarkColor:    "#FFCC00",
+		WatermarkMode:     "tiled",
+		WatermarkSize:     5,
+		WatermarkOpacity:  0.4,
+		WatermarkMargin:   3,
+		WatermarkRotation: -30,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *CreateGalleryRequest)
+		wantErr bool
+	}{
+		{"fully styled", func(r *CreateGalleryRequest) {}, false},
+		{"defaults", func(r *CreateGalleryRequest) {
+			*r = CreateGalleryRequest{EnableWatermark: true, WatermarkText: "© Studio"}
+		}, false},
+		{"disabled ignores style", func(r *CreateGalleryRequest) { r.EnableWatermark, r.WatermarkColor = false, "red" }, false},
+		{"missing text", func(r *CreateGalleryRequest) { r.WatermarkText = "" }, true},
+		{"unknown position", func(r *CreateGalleryRequest) { r.WatermarkPosition = "top" }, true},
+		{"unknown font", func(r *CreateGalleryRequest) { r.WatermarkFont = "comic" }, true},
+		{"named color", func(r *CreateGalleryRequest) { r.WatermarkColor = "white" }, true},
+		{"short hex color", func(r *CreateGalleryRequest) { r.WatermarkColor = "#fff" }, false},
+		{"unknown mode", func(r *CreateGalleryRequest) { r.WatermarkMode = "scattered" }, true},
+		{"size too small", func(r *CreateGalleryRequest) { r.WatermarkSize = 0.5 }, true},
+		{"size too large", func(r *CreateGalleryRequest) { r.WatermarkSize = 25 }, true},
+		{"opacity above one", func(r *CreateGalleryRequest) { r.WatermarkOpacity = 1.5 }, true},
+		{"negative margin", func(r *CreateGalleryRequest) { r.WatermarkMargin = -1 }, true},
+		{"rotation out of range", func(r *CreateGalleryRequest) { r.WatermarkRotation = 270 }, true},
+		{"logo without text", func(r *CreateGalleryRequest) { r.WatermarkType, r.WatermarkText = "logo", "" }, false},
+		{"unknown type", func(r *CreateGalleryRequest) { r.WatermarkType = "image" }, true},
+		{"logo scale", func(r *CreateGalleryRequest) { r.WatermarkType, r.WatermarkScale = "logo", 30 }, false},
+		{"logo scale too large", func(r *CreateGalleryRequest) { r.WatermarkType, r.WatermarkScale = "logo", 150 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			err := NewWatermarkValidator().Validate(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				assertErrorCode(t, err, 400)
+			}
+		})
+	}
+}
+
+func TestWatermarkValidatorUpdate(t *testing.T) {
+	opacity, mode, color := 0.8, "diagonal", "#000000"
+	if err := NewWatermarkValidator().Validate(context.Background(), UpdateGalleryRequest{
+		WatermarkOpacity: &opacity, WatermarkMode: &mode, WatermarkColor: &color,
+	}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	// Only the settings being changed are checked
+	if err := NewWatermarkValidator().Validate(context.Background(), UpdateGalleryRequest{}); err != nil {
+		t.Errorf("Validate() on empty update error = %v", err)
+	}
+
+	font := "papyrus"
+	err := NewWatermarkValidator().Validate(context.Background(), UpdateGalleryRequest{WatermarkFont: &font})
+	assertErrorCode(t, err, 400)
+}
@@ -0,0 +1,84 @@
+package photo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/events"
+)
+
+func TestCreatePhotoRecordsEvent(t *testing.T) {
+	photoRepo := mocks.NewMockPhotoRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo.Outbox = mocks.NewMockOutboxRepository()
+	service := NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	p, err := service.Create(context.Background(), CreatePhotoRequest{
+		PhotoID:     "photo_1",
+		GalleryID:   g.GalleryID,
+		FileName:    "IMG_0001.jpg",
+		OriginalKey: g.GalleryID + "/photo_1/IMG_0001.jpg",
+		MimeType:    "image/jpeg",
+		Size:        2048,
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	recorded := photoRepo.Outbox.EventsOfType(string(events.PhotoUploaded))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 photo.uploaded event, got %d", len(recorded))
+	}
+
+	var payload events.PhotoUploadedPayload
+	if err := json.Unmarshal([]byte(recorded[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.PhotoID != p.PhotoID || payload.GalleryID != g.GalleryID {
+		t.Errorf("Payload = %+v, want photo %v in gallery %v", payload, p.PhotoID, g.GalleryID)
+	}
+}
+
+func TestToggleFavoriteRecordsEvents(t *testing.T) {
+	photoRepo := mocks.NewMockPhotoRepository()
+	favoriteRepo := mocks.NewMockFavoriteRepository()
+	favoriteRepo.Outbox = mocks.NewMockOutboxRepository()
+	service := NewService(photoRepo, mocks.NewMockGalleryRepository(), favoriteRepo, mocks.NewMockSelectionRepository(), nil)
+
+	p := fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: "gal_1"})
+	photoRepo.AddPhoto(p)
+
+	ctx := context.Background()
+	if _, err := service.ToggleFavorite(ctx, "gal_1", "session_1", "", p.PhotoID); err != nil {
+		t.Fatalf("ToggleFavorite() error: %v", err)
+	}
+	if _, err := service.ToggleFavorite(ctx, "gal_1", "session_1", "", p.PhotoID); err != nil {
+		t.Fatalf("ToggleFavorite() error: %v", err)
+	}
+
+	recorded := favoriteRepo.Outbox.EventsOfType(string(events.FavoriteToggled))
+	if len(recorded) != 2 {
+		t.Fatalf("Expected 2 favorite.toggled events, got %d", len(recorded))
+	}
+
+	favorited := map[bool]int{}
+	for _, evt := range recorded {
+		var payload events.FavoriteToggledPayload
+		if err := json.Unmarshal([]byte(evt.Payload), &payload); err != nil {
+			t.Fatalf("Failed to decode payload: %v", err)
+		}
+		if payload.GalleryID != "gal_1" || payload.ClientID != "session_1" {
+			t.Errorf("Payload = %+v, want gallery gal_1 and session session_1", payload)
+		}
+		favorited[payload.Favorited]++
+	}
+	if favorited[true] != 1 || favorited[false] != 1 {
+		t.Errorf("Expected one favorite and one unfavorite event, got %v", favorited)
+	}
+}
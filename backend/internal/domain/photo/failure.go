@@ -0,0 +1,85 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	stdimage "image"
+	"image/jpeg"
+	"image/png"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+)
+
+// Categories of processing failure, shown to photographers so they know whether to upload the
+// photo again or retry it
+const (
+	FailureCorruptFile       = "corrupt_file"
+	FailureUnsupportedFormat = "unsupported_format"
+	FailureTooLarge          = "too_large"
+	FailureInternal          = "internal_error"
+)
+
+// maxFailedAttempts is how many attempts a photo's failure history keeps
+const maxFailedAttempts = 10
+
+// IsTransient reports whether a processing failure may pass if the photo is tried again:
+// throttling, timeouts, dropped connections, 5xx responses from S3 or DynamoDB, writes that
+// kept losing to concurrent changes and lookups marked Unavailable. Anything else, such as an original that can't be decoded,
+// fails the same way every time.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var unavailable unavailableError
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, repository.ErrConflict) || errors.As(err, &unavailable) {
+		return true
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// unavailableError is a failure to read something a photo's processing depends on
+type unavailableError struct{ err error }
+
+func (e unavailableError) Error() string { return e.err.Error() }
+func (e unavailableError) Unwrap() error { return e.err }
+
+// Unavailable marks err, a failure to read something a photo's processing depends on such as
+// its photographer's plan, as transient: the photo is retried rather than failed.
+func Unavailable(err error) error {
+	return unavailableError{err: err}
+}
+
+// Classify returns the category of a processing failure. Failures that aren't down to the
+// original itself are internal errors.
+func Classify(err error) string {
+	var jpegUnsupported jpeg.UnsupportedError
+	var pngUnsupported png.UnsupportedError
+	switch {
+	case errors.Is(err, image.ErrTooManyPixels):
+		return FailureTooLarge
+	case errors.Is(err, stdimage.ErrFormat), errors.As(err, &jpegUnsupported), errors.As(err, &pngUnsupported):
+		return FailureUnsupportedFormat
+	case errors.Is(err, image.ErrCorrupt):
+		return FailureCorruptFile
+	}
+	return FailureInternal
+}
+
+// RecordFailure records a failed attempt at processing a photo, making it the photo's latest
+// failure. Only the most recent attempts are kept.
+func RecordFailure(p *repository.Photo, category, message string, at time.Time) {
+	if p.Failure == nil {
+		p.Failure = &repository.ProcessingFailure{}
+	}
+	p.Failure.Category = category
+	p.Failure.Message = message
+	p.Failure.Attempts = append(p.Failure.Attempts, repository.FailedAttempt{At: at, Category: category, Message: message})
+	if n := len(p.Failure.Attempts); n > maxFailedAttempts {
+		p.Failure.Attempts = p.Failure.Attempts[n-maxFailedAttempts:]
+	}
+}
@@ -0,0 +1,94 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+
+	"photographer-gallery/backend/internal/repository"
+	imagesvc "photographer-gallery/backend/internal/services/image"
+	apperrors "photographer-gallery/backend/pkg/errors"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"S3 throttling", fmt.Errorf("S3 download failed: %w", &smithy.GenericAPIError{Code: "SlowDown"}), true},
+		{"DynamoDB throttling", &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"}, true},
+		{"wrapped in an AppError", apperrors.Wrap(&smithy.GenericAPIError{Code: "ThrottlingException"}, 500, "Failed to load logo"), true},
+		{"connection reset", &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{"timed out", fmt.Errorf("upload failed: %w", context.DeadlineExceeded), true},
+		{"lost a conditional write", fmt.Errorf("update photo failed: %w", repository.ErrConflict), true},
+		{"dependency unavailable", fmt.Errorf("failed to get plan: %w", Unavailable(&smithy.GenericAPIError{Code: "AccessDeniedException"})), true},
+		{"missing original", &smithy.GenericAPIError{Code: "NoSuchKey"}, false},
+		{"undecodable image", fmt.Errorf("decode failed: %w", image.ErrFormat), false},
+		{"too many pixels", fmt.Errorf("decode failed: %w", imagesvc.ErrTooManyPixels), false},
+		{"no error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	_, corrupt := imagesvc.NewProcessor().Open(strings.NewReader("\xff\xd8\xff\xdb truncated jpeg"))
+	_, unknown := imagesvc.NewProcessor().Open(strings.NewReader("not an image"))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"truncated jpeg", corrupt, FailureCorruptFile},
+		{"unknown format", unknown, FailureUnsupportedFormat},
+		{"unsupported jpeg feature", fmt.Errorf("decode failed: %w", fmt.Errorf("%w: %w", imagesvc.ErrCorrupt, jpeg.UnsupportedError("arithmetic coding"))), FailureUnsupportedFormat},
+		{"too many pixels", fmt.Errorf("decode failed: %w", imagesvc.ErrTooManyPixels), FailureTooLarge},
+		{"missing original", &smithy.GenericAPIError{Code: "NoSuchKey"}, FailureInternal},
+		{"throttled", &smithy.GenericAPIError{Code: "SlowDown"}, FailureInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	photo := &repository.Photo{PhotoID: "photo_1"}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < maxFailedAttempts+2; i++ {
+		RecordFailure(photo, FailureInternal, fmt.Sprintf("attempt %d", i), start.Add(time.Duration(i)*time.Minute))
+	}
+	RecordFailure(photo, FailureCorruptFile, "failed to decode image: unexpected EOF", start.Add(time.Hour))
+
+	if photo.Failure.Category != FailureCorruptFile || photo.Failure.Message != "failed to decode image: unexpected EOF" {
+		t.Errorf("failure = %s %q, want the latest attempt", photo.Failure.Category, photo.Failure.Message)
+	}
+	attempts := photo.Failure.Attempts
+	if len(attempts) != maxFailedAttempts {
+		t.Fatalf("kept %d attempts, want %d", len(attempts), maxFailedAttempts)
+	}
+	if attempts[0].Message != "attempt 3" || !attempts[len(attempts)-1].At.Equal(start.Add(time.Hour)) {
+		t.Errorf("attempts run from %q to %v, want the most recent oldest first", attempts[0].Message, attempts[len(attempts)-1].At)
+	}
+}
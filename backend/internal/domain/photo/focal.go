@@ -0,0 +1,83 @@
+package photo
+
+import (
+	"context"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Reprocessor sends a photo back through the image processor.
+type Reprocessor interface {
+	ReprocessPhoto(ctx context.Context, photographerID, galleryID, photoID string) (*repository.Photo, error)
+}
+
+// WithReprocessing makes focal point changes regenerate the photo's thumbnail. Without it the
+// new focal point is used the next time the photo is processed.
+func (s *Service) WithReprocessing(r Reprocessor) *Service {
+	s.reprocessor = r
+	return s
+}
+
+// ThumbnailStrategy returns how a photo's thumbnail is cropped: around its focal point when the
+// photographer set one, otherwise as the gallery's thumbnail crop says.
+func ThumbnailStrategy(gallery *repository.Gallery, photo *repository.Photo) image.ProcessingStrategy {
+	var mode string
+	if gallery != nil {
+		mode = gallery.ThumbnailCrop
+	}
+	var focus *image.FocalPoint
+	if photo != nil && photo.FocalPoint != nil {
+		focus = &image.FocalPoint{X: photo.FocalPoint.X, Y: photo.FocalPoint.Y}
+	}
+	return image.NewThumbnailCrop(mode, focus)
+}
+
+// SetFocalPoint sets the point a photo's thumbnail is cropped around, overriding the gallery's
+// thumbnail crop. A nil focal point goes back to the gallery's crop.
+func (s *Service) SetFocalPoint(ctx context.Context, photographerID, galleryID, photoID string, focus *repository.FocalPoint) (*repository.Photo, error) {
+	if focus != nil && !(image.FocalPoint{X: focus.X, Y: focus.Y}).Valid() {
+		return nil, errors.NewBadRequest("Focal point must be within the photo, with x and y between 0 and 1")
+	}
+	if _, err := s.authorizeGallery(ctx, photographerID, galleryID); err != nil {
+		return nil, err
+	}
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get photo")
+	}
+	if photo == nil || photo.GalleryID != galleryID || photo.DeletedAt != nil {
+		return nil, errors.NewNotFound("Photo")
+	}
+	if samePoint(photo.FocalPoint, focus) {
+		return photo, nil
+	}
+
+	photo.FocalPoint = focus
+	if err := s.photoRepo.Update(ctx, photo); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to update photo")
+	}
+	logger.Info("Photo focal point set", map[string]interface{}{"photoId": photo.PhotoID, "cleared": focus == nil})
+
+	if s.reprocessor == nil {
+		return photo, nil
+	}
+	reprocessed, err := s.reprocessor.ReprocessPhoto(ctx, photographerID, galleryID, photoID)
+	if err != nil {
+		// The focal point is saved and applies whenever the photo is processed again
+		logger.Warn("Failed to reprocess photo after focal point change", map[string]interface{}{
+			"photoId": photo.PhotoID, "error": err.Error(),
+		})
+		return photo, nil
+	}
+	return reprocessed, nil
+}
+
+func samePoint(a, b *repository.FocalPoint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
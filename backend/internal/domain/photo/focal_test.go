@@ -0,0 +1,98 @@
+package photo
+
+import (
+	"context"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+func TestSetFocalPoint(t *testing.T) {
+	ctx := context.Background()
+	photoRepo := mocks.NewMockPhotoRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	queue := mocks.NewMockProcessingQueue()
+	service := NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil).
+		WithReprocessing(reprocess.NewService(galleryRepo, photoRepo, queue))
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	p := fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID})
+	photoRepo.AddPhoto(p)
+
+	focus := &repository.FocalPoint{X: 0.25, Y: 0.4}
+	updated, err := service.SetFocalPoint(ctx, "user_owner", g.GalleryID, p.PhotoID, focus)
+	if err != nil {
+		t.Fatalf("SetFocalPoint() error: %v", err)
+	}
+	if updated.FocalPoint == nil || *updated.FocalPoint != *focus {
+		t.Errorf("FocalPoint = %v, want %v", updated.FocalPoint, focus)
+	}
+	if updated.ProcessingStatus != reprocess.StatusPending || len(queue.Enqueued()) != 1 {
+		t.Errorf("photo should be requeued for a new thumbnail, status = %s, queued = %v", updated.ProcessingStatus, queue.Enqueued())
+	}
+
+	// Setting the same point again doesn't reprocess the photo
+	if _, err := service.SetFocalPoint(ctx, "user_owner", g.GalleryID, p.PhotoID, &repository.FocalPoint{X: 0.25, Y: 0.4}); err != nil {
+		t.Fatalf("SetFocalPoint() error: %v", err)
+	}
+	if len(queue.Enqueued()) != 1 {
+		t.Errorf("queued = %v, want no new work for an unchanged focal point", queue.Enqueued())
+	}
+
+	cleared, err := service.SetFocalPoint(ctx, "user_owner", g.GalleryID, p.PhotoID, nil)
+	if err != nil {
+		t.Fatalf("SetFocalPoint(nil) error: %v", err)
+	}
+	if cleared.FocalPoint != nil {
+		t.Errorf("FocalPoint = %v, want cleared", cleared.FocalPoint)
+	}
+}
+
+func TestSetFocalPointValidation(t *testing.T) {
+	ctx := context.Background()
+	photoRepo := mocks.NewMockPhotoRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	service := NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	p := fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID})
+	photoRepo.AddPhoto(p)
+
+	if _, err := service.SetFocalPoint(ctx, "user_owner", g.GalleryID, p.PhotoID, &repository.FocalPoint{X: 1.5, Y: 0.5}); err == nil {
+		t.Error("SetFocalPoint() should reject a point outside the photo")
+	}
+
+	_, err := service.SetFocalPoint(ctx, "user_other", g.GalleryID, p.PhotoID, &repository.FocalPoint{X: 0.5, Y: 0.5})
+	assertNotFound(t, err, "Gallery")
+
+	_, err = service.SetFocalPoint(ctx, "user_owner", g.GalleryID, "photo_missing", &repository.FocalPoint{X: 0.5, Y: 0.5})
+	assertNotFound(t, err, "Photo")
+}
+
+func TestThumbnailStrategy(t *testing.T) {
+	smart := &repository.Gallery{ThumbnailCrop: repository.ThumbnailCropSmart}
+
+	tests := []struct {
+		name    string
+		gallery *repository.Gallery
+		photo   *repository.Photo
+		want    string
+	}{
+		{"default", &repository.Gallery{}, &repository.Photo{}, "thumbnail"},
+		{"smart gallery", smart, &repository.Photo{}, "smart-crop"},
+		{"focal point overrides gallery", smart, &repository.Photo{FocalPoint: &repository.FocalPoint{X: 0.1, Y: 0.9}}, "focal-crop"},
+		{"unknown photo", smart, nil, "smart-crop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ThumbnailStrategy(tt.gallery, tt.photo).Name(); got != tt.want {
+				t.Errorf("ThumbnailStrategy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package photo
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
+)
+
+// maxFavoriteLists caps how many lists a client session can keep, including the default list.
+const maxFavoriteLists = 20
+
+// ListFavoriteLists returns a client session's favorites lists with their photo counts.
+// The default list always comes first, even before it has been stored.
+func (s *Service) ListFavoriteLists(ctx context.Context, galleryID, sessionID string) ([]*repository.FavoriteList, error) {
+	lists, err := s.favoriteRepo.ListLists(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list favorites lists")
+	}
+	favorites, err := s.favoriteRepo.ListBySession(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list favorites")
+	}
+	if favorites, err = s.withoutTrashed(ctx, favorites); err != nil {
+		return nil, err
+	}
+
+	hasDefault := false
+	for _, list := range lists {
+		if list.ListID == repository.DefaultFavoriteListID {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		lists = append(lists, newDefaultList(galleryID, sessionID))
+	}
+
+	counts := make(map[string]int, len(lists))
+	for _, favorite := range favorites {
+		counts[favorite.ListID]++
+	}
+	for _, list := range lists {
+		list.PhotoCount = counts[list.ListID]
+	}
+
+	sort.SliceStable(lists, func(i, j int) bool {
+		if (lists[i].ListID == repository.DefaultFavoriteListID) != (lists[j].ListID == repository.DefaultFavoriteListID) {
+			return lists[i].ListID == repository.DefaultFavoriteListID
+		}
+		return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+	})
+	return lists, nil
+}
+
+// ListFavoritesByList lists the favorites in one of a client session's lists
+func (s *Service) ListFavoritesByList(ctx context.Context, galleryID, sessionID, listID string) ([]*repository.Favorite, error) {
+	if _, err := s.getFavoriteList(ctx, galleryID, sessionID, listID); err != nil {
+		return nil, err
+	}
+
+	favorites, err := s.favoriteRepo.ListByList(ctx, galleryID, sessionID, listID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list favorites")
+	}
+	return s.withoutTrashed(ctx, favorites)
+}
+
+// CreateFavoriteList creates a new named favorites list for a client session
+func (s *Service) CreateFavoriteList(ctx context.Context, galleryID, sessionID, name string) (*repository.FavoriteList, error) {
+	name, err := normalizeListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := s.ListFavoriteLists(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) >= maxFavoriteLists {
+		return nil, errors.NewBadRequest("Too many favorites lists")
+	}
+	if err := checkListNameAvailable(lists, "", name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := &repository.FavoriteList{
+		ListID:    utils.GenerateID("list"),
+		GalleryID: galleryID,
+		SessionID: sessionID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.favoriteRepo.CreateList(ctx, list); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to create favorites list")
+	}
+
+	logger.Info("Favorites list created", map[string]interface{}{
+		"galleryId": galleryID,
+		"listId":    list.ListID,
+	})
+
+	return list, nil
+}
+
+// RenameFavoriteList renames one of a client session's lists, including the default list
+func (s *Service) RenameFavoriteList(ctx context.Context, galleryID, sessionID, listID, name string) (*repository.FavoriteList, error) {
+	name, err := normalizeListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.getFavoriteList(ctx, galleryID, sessionID, listID)
+	if err != nil {
+		return nil, err
+	}
+	lists, err := s.ListFavoriteLists(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkListNameAvailable(lists, listID, name); err != nil {
+		return nil, err
+	}
+
+	list.Name = name
+	list.UpdatedAt = time.Now()
+	if err := s.favoriteRepo.UpdateList(ctx, list); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to rename favorites list")
+	}
+
+	return list, nil
+}
+
+// DeleteFavoriteList deletes one of a client session's named lists and the favorites in it.
+// The default list holds the proofing selection and cannot be deleted.
+func (s *Service) DeleteFavoriteList(ctx context.Context, galleryID, sessionID, listID string) error {
+	if listID == repository.DefaultFavoriteListID {
+		return errors.NewBadRequest("The default list cannot be deleted")
+	}
+	if _, err := s.getFavoriteList(ctx, galleryID, sessionID, listID); err != nil {
+		return err
+	}
+
+	favorites, err := s.favoriteRepo.ListBySession(ctx, galleryID, sessionID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to list favorites")
+	}
+
+	if err := s.favoriteRepo.DeleteList(ctx, galleryID, sessionID, listID); err != nil {
+		return errors.Wrap(err, 500, "Failed to delete favorites list")
+	}
+
+	// Photos that were only in this list are no longer favorited by the client
+	for _, photoID := range photosOnlyInList(favorites, listID) {
+		if err := s.photoRepo.IncrementFavoriteCount(ctx, photoID, -1); err != nil {
+			logger.Error("Failed to decrement favorite count", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	logger.Info("Favorites list deleted", map[string]interface{}{
+		"galleryId": galleryID,
+		"listId":    listID,
+	})
+
+	return nil
+}
+
+// getFavoriteList loads one of a session's lists. The default list exists even when it
+// has never been stored.
+func (s *Service) getFavoriteList(ctx context.Context, galleryID, sessionID, listID string) (*repository.FavoriteList, error) {
+	list, err := s.favoriteRepo.GetList(ctx, galleryID, sessionID, listID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get favorites list")
+	}
+	if list == nil {
+		if listID != repository.DefaultFavoriteListID {
+			return nil, errors.NewNotFound("Favorites list")
+		}
+		list = newDefaultList(galleryID, sessionID)
+	}
+	return list, nil
+}
+
+// isInOtherLists reports whether the session keeps the photo in any list other than listID
+func (s *Service) isInOtherLists(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error) {
+	favorites, err := s.favoriteRepo.ListBySession(ctx, galleryID, sessionID)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to list favorites")
+	}
+	for _, favorite := range favorites {
+		if favorite.PhotoID == photoID && favorite.ListID != listID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newDefaultList(galleryID, sessionID string) *repository.FavoriteList {
+	return &repository.FavoriteList{
+		ListID:    repository.DefaultFavoriteListID,
+		GalleryID: galleryID,
+		SessionID: sessionID,
+		Name:      repository.DefaultFavoriteListName,
+	}
+}
+
+// defaultListName names a list that has no stored metadata
+func defaultListName(listID string) string {
+	if listID == repository.DefaultFavoriteListID {
+		return repository.DefaultFavoriteListName
+	}
+	return listID
+}
+
+// checkListNameAvailable rejects a name already used by another of the session's lists
+func checkListNameAvailable(lists []*repository.FavoriteList, listID, name string) error {
+	for _, list := range lists {
+		if list.ListID != listID && strings.EqualFold(list.Name, name) {
+			return errors.NewConflict("A list with this name already exists")
+		}
+	}
+	return nil
+}
+
+// photosOnlyInList returns the photos that appear in listID and in no other list
+func photosOnlyInList(favorites []*repository.Favorite, listID string) []string {
+	inList := map[string]bool{}
+	inOthers := map[string]bool{}
+	for _, favorite := range favorites {
+		if favorite.ListID == listID {
+			inList[favorite.PhotoID] = true
+		} else {
+			inOthers[favorite.PhotoID] = true
+		}
+	}
+
+	var photoIDs []string
+	for photoID := range inList {
+		if !inOthers[photoID] {
+			photoIDs = append(photoIDs, photoID)
+		}
+	}
+	sort.Strings(photoIDs)
+	return photoIDs
+}
@@ -0,0 +1,154 @@
+package photo
+
+import (
+	"context"
+	"testing"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+func TestFavoriteListLifecycle(t *testing.T) {
+	service, _, _, _ := newTestService()
+	ctx := context.Background()
+	galleryID := "gal_123"
+
+	lists, err := service.ListFavoriteLists(ctx, galleryID, "session_1")
+	if err != nil {
+		t.Fatalf("ListFavoriteLists() error: %v", err)
+	}
+	if len(lists) != 1 || lists[0].ListID != repository.DefaultFavoriteListID {
+		t.Fatalf("Lists = %+v, want only the default list", lists)
+	}
+
+	prints, err := service.CreateFavoriteList(ctx, galleryID, "session_1", "  Prints ")
+	if err != nil {
+		t.Fatalf("CreateFavoriteList() error: %v", err)
+	}
+	if prints.Name != "Prints" || prints.ListID == "" {
+		t.Errorf("List = %+v, want a trimmed name and an ID", prints)
+	}
+
+	_, err = service.CreateFavoriteList(ctx, galleryID, "session_1", "prints")
+	assertErrorCode(t, err, 409)
+	_, err = service.CreateFavoriteList(ctx, galleryID, "session_1", " ")
+	assertErrorCode(t, err, 400)
+
+	// Names only need to be unique within a session
+	if _, err := service.CreateFavoriteList(ctx, galleryID, "session_2", "Prints"); err != nil {
+		t.Errorf("CreateFavoriteList() in another session error: %v", err)
+	}
+
+	renamed, err := service.RenameFavoriteList(ctx, galleryID, "session_1", prints.ListID, "Album")
+	if err != nil {
+		t.Fatalf("RenameFavoriteList() error: %v", err)
+	}
+	if renamed.Name != "Album" {
+		t.Errorf("Name = %q, want Album", renamed.Name)
+	}
+	_, err = service.RenameFavoriteList(ctx, galleryID, "session_1", prints.ListID, "favorites")
+	assertErrorCode(t, err, 409)
+
+	if _, err := service.RenameFavoriteList(ctx, galleryID, "session_1", repository.DefaultFavoriteListID, "Shortlist"); err != nil {
+		t.Fatalf("RenameFavoriteList() default list error: %v", err)
+	}
+
+	lists, _ = service.ListFavoriteLists(ctx, galleryID, "session_1")
+	if len(lists) != 2 || lists[0].Name != "Shortlist" || lists[1].Name != "Album" {
+		t.Errorf("Lists = %+v, want Shortlist then Album", lists)
+	}
+
+	assertErrorCode(t, service.DeleteFavoriteList(ctx, galleryID, "session_1", repository.DefaultFavoriteListID), 400)
+	assertNotFound(t, service.DeleteFavoriteList(ctx, galleryID, "session_1", "list_missing"), "Favorites list")
+
+	if err := service.DeleteFavoriteList(ctx, galleryID, "session_1", prints.ListID); err != nil {
+		t.Fatalf("DeleteFavoriteList() error: %v", err)
+	}
+	lists, _ = service.ListFavoriteLists(ctx, galleryID, "session_1")
+	if len(lists) != 1 {
+		t.Errorf("Expected only the default list after deleting, got %d lists", len(lists))
+	}
+}
+
+func TestToggleFavoriteInNamedList(t *testing.T) {
+	service, photoRepo, _, _ := newTestService()
+	ctx := context.Background()
+	galleryID, photoID := "gal_123", "photo_123"
+	photoRepo.photos[photoID] = &repository.Photo{PhotoID: photoID, GalleryID: galleryID}
+
+	_, err := service.ToggleFavorite(ctx, galleryID, "session_1", "list_missing", photoID)
+	assertNotFound(t, err, "Favorites list")
+
+	album, err := service.CreateFavoriteList(ctx, galleryID, "session_1", "Album")
+	if err != nil {
+		t.Fatalf("CreateFavoriteList() error: %v", err)
+	}
+
+	// The same client keeping the photo in two lists counts once
+	for _, listID := range []string{"", album.ListID} {
+		if favorited, err := service.ToggleFavorite(ctx, galleryID, "session_1", listID, photoID); err != nil || !favorited {
+			t.Fatalf("ToggleFavorite(%q) = %v, %v", listID, favorited, err)
+		}
+	}
+	if got := photoRepo.photos[photoID].FavoriteCount; got != 1 {
+		t.Errorf("FavoriteCount = %d, want 1", got)
+	}
+
+	inAlbum, err := service.ListFavoritesByList(ctx, galleryID, "session_1", album.ListID)
+	if err != nil {
+		t.Fatalf("ListFavoritesByList() error: %v", err)
+	}
+	if len(inAlbum) != 1 || inAlbum[0].ListID != album.ListID {
+		t.Errorf("Album favorites = %+v, want the photo", inAlbum)
+	}
+
+	// Removing it from one list keeps the client's favorite
+	if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", photoID); err != nil {
+		t.Fatalf("ToggleFavorite() error: %v", err)
+	}
+	if got := photoRepo.photos[photoID].FavoriteCount; got != 1 {
+		t.Errorf("FavoriteCount = %d, want 1", got)
+	}
+
+	// Deleting the last list holding it drops the count
+	if err := service.DeleteFavoriteList(ctx, galleryID, "session_1", album.ListID); err != nil {
+		t.Fatalf("DeleteFavoriteList() error: %v", err)
+	}
+	if got := photoRepo.photos[photoID].FavoriteCount; got != 0 {
+		t.Errorf("FavoriteCount = %d, want 0", got)
+	}
+}
+
+func TestListFavoritesByGalleryAggregatesLists(t *testing.T) {
+	service, photoRepo, galleryRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryID, photoID := "gal_123", "photo_123"
+	galleryRepo.galleries[galleryID] = &repository.Gallery{GalleryID: galleryID, PhotographerID: "user_owner"}
+	photoRepo.photos[photoID] = &repository.Photo{PhotoID: photoID, GalleryID: galleryID}
+
+	album, err := service.CreateFavoriteList(ctx, galleryID, "session_1", "Album")
+	if err != nil {
+		t.Fatalf("CreateFavoriteList() error: %v", err)
+	}
+	service.ToggleFavorite(ctx, galleryID, "session_1", "", photoID)
+	service.ToggleFavorite(ctx, galleryID, "session_1", album.ListID, photoID)
+	service.ToggleFavorite(ctx, galleryID, "session_2", "", photoID)
+
+	favorites, err := service.ListFavoritesByGallery(ctx, "user_owner", galleryID)
+	if err != nil {
+		t.Fatalf("ListFavoritesByGallery() error: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Fatalf("Expected one entry per client, got %d", len(favorites))
+	}
+
+	lists := map[string][]string{}
+	for _, favorite := range favorites {
+		lists[favorite.SessionID] = favorite.Lists
+	}
+	if got := lists["session_1"]; len(got) != 2 || got[0] != "Album" || got[1] != repository.DefaultFavoriteListName {
+		t.Errorf("session_1 lists = %v, want [Album Favorites]", got)
+	}
+	if got := lists["session_2"]; len(got) != 1 || got[0] != repository.DefaultFavoriteListName {
+		t.Errorf("session_2 lists = %v, want [Favorites]", got)
+	}
+}
@@ -0,0 +1,56 @@
+package photo
+
+import (
+	"context"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// authorizeGallery loads a gallery and verifies it belongs to the photographer.
+// Galleries owned by someone else are reported as not found so their existence isn't revealed,
+// as are galleries in the trash.
+func (s *Service) authorizeGallery(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.authorizeOwnedGallery(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	if gallery.Status == repository.GalleryStatusDeleted {
+		return nil, errors.NewNotFound("Gallery")
+	}
+	return gallery, nil
+}
+
+// authorizeOwnedGallery is authorizeGallery without hiding galleries in the trash.
+func (s *Service) authorizeOwnedGallery(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery == nil || photographerID == "" || gallery.PhotographerID != photographerID {
+		if gallery != nil {
+			logger.Warn("Gallery access denied", map[string]interface{}{
+				"galleryId": galleryID, "photographerId": photographerID,
+			})
+		}
+		return nil, errors.NewNotFound("Gallery")
+	}
+	return gallery, nil
+}
+
+// authorizePhoto loads a photo and verifies its gallery belongs to the photographer.
+// Photos in galleries owned by someone else are reported as not found.
+func (s *Service) authorizePhoto(ctx context.Context, photographerID, photoID string) (*repository.Photo, error) {
+	photo, err := s.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorizeGallery(ctx, photographerID, photo.GalleryID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return nil, errors.NewNotFound("Photo")
+		}
+		return nil, err
+	}
+	return photo, nil
+}
@@ -0,0 +1,120 @@
+package photo
+
+import (
+	"context"
+	"testing"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func assertNotFound(t *testing.T, err error, resource string) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != 404 {
+		t.Errorf("error code = %d, want 404", appErr.Code)
+	}
+	if appErr.Message != resource+" not found" {
+		t.Errorf("error message = %q, want %q", appErr.Message, resource+" not found")
+	}
+}
+
+func TestDeletePhotoNotOwner(t *testing.T) {
+	photoRepo := mocks.NewMockPhotoRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	service := NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	p := fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID})
+	photoRepo.AddPhoto(p)
+
+	err := service.Delete(context.Background(), "user_other", p.PhotoID)
+	assertNotFound(t, err, "Photo")
+
+	if stored, _ := photoRepo.GetByID(context.Background(), p.PhotoID); stored == nil {
+		t.Error("Photo should not be deleted by a non-owner")
+	}
+}
+
+func TestListFavoritesByGalleryNotOwner(t *testing.T) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	favoriteRepo := mocks.NewMockFavoriteRepository()
+	service := NewService(mocks.NewMockPhotoRepository(), galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	for _, fav := range fixtures.NewFavoriteList(g.GalleryID, "session_1", []string{"photo_1", "photo_2"}) {
+		favoriteRepo.Create(context.Background(), fav)
+	}
+
+	favorites, err := service.ListFavoritesByGallery(context.Background(), "user_owner", g.GalleryID)
+	if err != nil {
+		t.Fatalf("ListFavoritesByGallery() error: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Errorf("Expected 2 favorites, got %d", len(favorites))
+	}
+
+	_, err = service.ListFavoritesByGallery(context.Background(), "user_other", g.GalleryID)
+	assertNotFound(t, err, "Gallery")
+}
+
+func TestListByGalleryForPhotographer(t *testing.T) {
+	photoRepo := mocks.NewMockPhotoRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	service := NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+	for _, p := range fixtures.NewPhotoList(3, g.GalleryID) {
+		photoRepo.AddPhoto(p)
+	}
+
+	photos, _, err := service.ListByGalleryForPhotographer(context.Background(), "user_owner", g.GalleryID, 10, nil)
+	if err != nil {
+		t.Fatalf("ListByGalleryForPhotographer() error: %v", err)
+	}
+	if len(photos) != 3 {
+		t.Errorf("Expected 3 photos, got %d", len(photos))
+	}
+
+	_, _, err = service.ListByGalleryForPhotographer(context.Background(), "user_other", g.GalleryID, 10, nil)
+	assertNotFound(t, err, "Gallery")
+}
+
+func TestGenerateUploadURLNotOwner(t *testing.T) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	service := NewService(mocks.NewMockPhotoRepository(), galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner"})
+	galleryRepo.AddGallery(g)
+
+	_, err := service.GenerateUploadURL(context.Background(), UploadURLRequest{
+		PhotographerID: "user_other",
+		GalleryID:      g.GalleryID,
+		FileName:       "photo.jpg",
+		MimeType:       "image/jpeg",
+	})
+	assertNotFound(t, err, "Gallery")
+}
+
+func TestGenerateUploadURLArchivedGallery(t *testing.T) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	service := NewService(mocks.NewMockPhotoRepository(), galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil)
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: "archived"})
+	galleryRepo.AddGallery(g)
+
+	_, err := service.GenerateUploadURL(context.Background(), UploadURLRequest{
+		PhotographerID: "user_owner",
+		GalleryID:      g.GalleryID,
+		FileName:       "photo.jpg",
+		MimeType:       "image/jpeg",
+	})
+	assertErrorCode(t, err, 409)
+}
@@ -0,0 +1,93 @@
+package photo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+// newQuotaService creates a test service counting uploads to gal_123 against a free-plan
+// photographer using used bytes
+func newQuotaService(used int64) (*Service, *mockPhotoRepo, *mocks.MockPhotographerStore) {
+	service, photoRepo, galleryRepo, _ := newTestService()
+	galleryRepo.galleries["gal_123"] = &repository.Gallery{GalleryID: "gal_123", PhotographerID: "user_owner", Status: repository.GalleryStatusActive}
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_owner", Plan: plan.Free, StorageUsed: used})
+	service.WithTrash(mocks.NewMockTrashRepository(), time.Hour).
+		WithQuota(quota.NewService(accounts, galleryRepo, photoRepo))
+	return service, photoRepo, accounts
+}
+
+func TestGenerateUploadURLOverQuota(t *testing.T) {
+	limit := plan.For(plan.Free).StorageBytes
+	service, _, _ := newQuotaService(limit - 1000)
+
+	_, err := service.GenerateUploadURL(context.Background(), UploadURLRequest{
+		PhotographerID: "user_owner",
+		GalleryID:      "gal_123",
+		FileName:       "photo.jpg",
+		MimeType:       "image/jpeg",
+		Size:           1001,
+	})
+	assertErrorCode(t, err, 403)
+}
+
+func TestGenerateUploadURLRequiresSize(t *testing.T) {
+	service, _, _ := newQuotaService(0)
+
+	for _, size := range []int64{0, -1} {
+		_, err := service.GenerateUploadURL(context.Background(), UploadURLRequest{
+			PhotographerID: "user_owner",
+			GalleryID:      "gal_123",
+			FileName:       "photo.jpg",
+			MimeType:       "image/jpeg",
+			Size:           size,
+		})
+		assertErrorCode(t, err, 400)
+	}
+}
+
+func TestCreatePhotoRecordsStorage(t *testing.T) {
+	service, _, accounts := newQuotaService(500)
+
+	_, err := service.Create(context.Background(), CreatePhotoRequest{
+		PhotoID:   "photo_1",
+		GalleryID: "gal_123",
+		FileName:  "photo.jpg",
+		MimeType:  "image/jpeg",
+		Size:      2048,
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	if got := accounts.StorageUsed("user_owner"); got != 500+2048 {
+		t.Errorf("StorageUsed = %d, want %d", got, 500+2048)
+	}
+}
+
+func TestTrashedPhotoStorageFreedOnPurge(t *testing.T) {
+	service, photoRepo, accounts := newQuotaService(10000)
+	ctx := context.Background()
+	photoRepo.photos["photo_1"] = &repository.Photo{PhotoID: "photo_1", GalleryID: "gal_123", Size: 4000, OptimizedSize: 800, ThumbnailSize: 200}
+
+	if err := service.Delete(ctx, "user_owner", "photo_1"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if got := accounts.StorageUsed("user_owner"); got != 10000 {
+		t.Errorf("StorageUsed after trashing = %d, want 10000 until the photo is purged", got)
+	}
+
+	if err := service.PurgeDeleted(ctx, "photo_1"); err != nil {
+		t.Fatalf("PurgeDeleted() error: %v", err)
+	}
+	if got := accounts.StorageUsed("user_owner"); got != 5000 {
+		t.Errorf("StorageUsed after purge = %d, want 5000", got)
+	}
+}
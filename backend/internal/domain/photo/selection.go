@@ -0,0 +1,177 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// GetSelection returns the proofing selection state for a client session.
+// Sessions that have never submitted get an open selection.
+func (s *Service) GetSelection(ctx context.Context, galleryID, sessionID string) (*repository.Selection, error) {
+	selection, err := s.selectionRepo.GetByID(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get selection")
+	}
+	if selection == nil {
+		selection = &repository.Selection{
+			GalleryID: galleryID,
+			SessionID: sessionID,
+			Status:    repository.SelectionStatusOpen,
+		}
+	}
+	return selection, nil
+}
+
+// SubmitSelection submits the client's default favorites list as their final proofing selection.
+// Once submitted the list is locked until the photographer reopens it.
+func (s *Service) SubmitSelection(ctx context.Context, galleryID, sessionID string) (*repository.Selection, error) {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery == nil {
+		return nil, errors.NewNotFound("Gallery")
+	}
+	if !gallery.ProofingEnabled {
+		return nil, errors.NewBadRequest("Proofing is not enabled for this gallery")
+	}
+
+	selection, err := s.GetSelection(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if selection.Status == repository.SelectionStatusSubmitted {
+		return nil, errors.NewConflict("Selection has already been submitted")
+	}
+
+	favorites, err := s.ListFavoritesByList(ctx, galleryID, sessionID, repository.DefaultFavoriteListID)
+	if err != nil {
+		return nil, err
+	}
+	if len(favorites) == 0 {
+		return nil, errors.NewBadRequest("Select at least one photo before submitting")
+	}
+	// The limit may have been lowered after the client made their picks
+	if gallery.SelectionLimit > 0 && len(favorites) > gallery.SelectionLimit {
+		return nil, errors.NewBadRequest(fmt.Sprintf("Selection exceeds the limit of %d photos", gallery.SelectionLimit))
+	}
+
+	now := time.Now()
+	isNew := selection.SubmittedAt == nil && selection.ReopenedAt == nil
+	selection.Status = repository.SelectionStatusSubmitted
+	selection.PhotoCount = len(favorites)
+	selection.SubmittedAt = &now
+	selection.UpdatedAt = now
+
+	writeCtx, err := repository.WithOutboxEvents(ctx, events.NewEvent(events.SelectionSubmitted, &events.SelectionSubmittedPayload{
+		GalleryID:   galleryID,
+		ClientID:    sessionID,
+		PhotoCount:  selection.PhotoCount,
+		SubmittedAt: now,
+	}, galleryID))
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to record selection event")
+	}
+
+	if isNew {
+		err = s.selectionRepo.Create(writeCtx, selection)
+	} else {
+		err = s.selectionRepo.Update(writeCtx, selection)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to submit selection")
+	}
+
+	logger.Info("Selection submitted", map[string]interface{}{
+		"galleryId":  galleryID,
+		"sessionId":  sessionID,
+		"photoCount": selection.PhotoCount,
+	})
+
+	return selection, nil
+}
+
+// ReopenSelection unlocks a submitted selection so the client can change it again
+func (s *Service) ReopenSelection(ctx context.Context, photographerID, galleryID, sessionID string) (*repository.Selection, error) {
+	if _, err := s.authorizeGallery(ctx, photographerID, galleryID); err != nil {
+		return nil, err
+	}
+
+	selection, err := s.selectionRepo.GetByID(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get selection")
+	}
+	if selection == nil {
+		return nil, errors.NewNotFound("Selection")
+	}
+	if selection.Status != repository.SelectionStatusSubmitted {
+		return nil, errors.NewConflict("Selection is not submitted")
+	}
+
+	now := time.Now()
+	selection.Status = repository.SelectionStatusOpen
+	selection.ReopenedAt = &now
+	selection.UpdatedAt = now
+
+	if err := s.selectionRepo.Update(ctx, selection); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to reopen selection")
+	}
+
+	logger.Info("Selection reopened", map[string]interface{}{
+		"galleryId": galleryID,
+		"sessionId": sessionID,
+	})
+
+	return selection, nil
+}
+
+// ListSelectionsByGallery lists the proofing selections clients have submitted or had reopened (photographer view)
+func (s *Service) ListSelectionsByGallery(ctx context.Context, photographerID, galleryID string) ([]*repository.Selection, error) {
+	if _, err := s.authorizeGallery(ctx, photographerID, galleryID); err != nil {
+		return nil, err
+	}
+
+	selections, err := s.selectionRepo.ListByGallery(ctx, galleryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list selections")
+	}
+	return selections, nil
+}
+
+// checkSelectionOpen rejects changes to the default list once the session's selection has been submitted
+func (s *Service) checkSelectionOpen(ctx context.Context, galleryID, sessionID string) error {
+	selection, err := s.selectionRepo.GetByID(ctx, galleryID, sessionID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to get selection")
+	}
+	if selection != nil && selection.Status == repository.SelectionStatusSubmitted {
+		return errors.NewConflict("Selection has already been submitted")
+	}
+	return nil
+}
+
+// checkSelectionLimit rejects a new default-list favorite when a proofing gallery's selection limit is reached
+func (s *Service) checkSelectionLimit(ctx context.Context, galleryID, sessionID string) error {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery == nil || !gallery.ProofingEnabled || gallery.SelectionLimit <= 0 {
+		return nil
+	}
+
+	favorites, err := s.ListFavoritesByList(ctx, galleryID, sessionID, repository.DefaultFavoriteListID)
+	if err != nil {
+		return err
+	}
+	if len(favorites) >= gallery.SelectionLimit {
+		return errors.NewConflict(fmt.Sprintf("Selection limit of %d photos reached", gallery.SelectionLimit))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package photo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
+)
+
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != code {
+		t.Errorf("error code = %d, want %d (%s)", appErr.Code, code, appErr.Message)
+	}
+}
+
+func TestToggleFavoriteEnforcesSelectionLimit(t *testing.T) {
+	service, _, galleryRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryRepo.galleries["gal_123"] = &repository.Gallery{GalleryID: "gal_123", ProofingEnabled: true, SelectionLimit: 2}
+
+	for _, photoID := range []string{"photo_1", "photo_2"} {
+		if _, err := service.ToggleFavorite(ctx, "gal_123", "session_1", "", photoID); err != nil {
+			t.Fatalf("ToggleFavorite(%s) error: %v", photoID, err)
+		}
+	}
+	_, err := service.ToggleFavorite(ctx, "gal_123", "session_1", "", "photo_3")
+	assertErrorCode(t, err, 409)
+
+	// Removing a pick frees a slot
+	if _, err := service.ToggleFavorite(ctx, "gal_123", "session_1", "", "photo_1"); err != nil {
+		t.Fatalf("ToggleFavorite() unfavorite error: %v", err)
+	}
+	if _, err := service.ToggleFavorite(ctx, "gal_123", "session_1", "", "photo_3"); err != nil {
+		t.Fatalf("ToggleFavorite() after freeing a slot error: %v", err)
+	}
+}
+
+func TestToggleFavoriteIgnoresLimitWithoutProofing(t *testing.T) {
+	service, _, galleryRepo, _ := newTestService()
+	galleryRepo.galleries["gal_123"] = &repository.Gallery{GalleryID: "gal_123", SelectionLimit: 1}
+
+	for _, photoID := range []string{"photo_1", "photo_2"} {
+		if _, err := service.ToggleFavorite(context.Background(), "gal_123", "session_1", "", photoID); err != nil {
+			t.Fatalf("ToggleFavorite(%s) error: %v", photoID, err)
+		}
+	}
+}
+
+func TestSubmitSelectionLocksFavorites(t *testing.T) {
+	galleryRepo := newMockGalleryRepo()
+	favoriteRepo := newMockFavoriteRepo()
+	selectionRepo := mocks.NewMockSelectionRepository()
+	selectionRepo.Outbox = mocks.NewMockOutboxRepository()
+	service := NewService(newMockPhotoRepo(), galleryRepo, favoriteRepo, selectionRepo, nil)
+	ctx := context.Background()
+	galleryID := "gal_123"
+	galleryRepo.galleries[galleryID] = &repository.Gallery{GalleryID: galleryID, ProofingEnabled: true, SelectionLimit: 3}
+
+	for _, photoID := range []string{"photo_1", "photo_2"} {
+		if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", photoID); err != nil {
+			t.Fatalf("ToggleFavorite(%s) error: %v", photoID, err)
+		}
+	}
+
+	selection, err := service.SubmitSelection(ctx, galleryID, "session_1")
+	if err != nil {
+		t.Fatalf("SubmitSelection() error: %v", err)
+	}
+	if selection.Status != repository.SelectionStatusSubmitted || selection.SubmittedAt == nil {
+		t.Errorf("Selection = %+v, want submitted with a timestamp", selection)
+	}
+	if selection.PhotoCount != 2 {
+		t.Errorf("PhotoCount = %d, want 2", selection.PhotoCount)
+	}
+
+	// Adding and removing favorites are both locked
+	for _, photoID := range []string{"photo_3", "photo_1"} {
+		_, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", photoID)
+		assertErrorCode(t, err, 409)
+	}
+	if favorites, _ := favoriteRepo.ListBySession(ctx, galleryID, "session_1"); len(favorites) != 2 {
+		t.Errorf("Expected 2 favorites after locking, got %d", len(favorites))
+	}
+
+	_, err = service.SubmitSelection(ctx, galleryID, "session_1")
+	assertErrorCode(t, err, 409)
+
+	// Other sessions are unaffected
+	if _, err := service.ToggleFavorite(ctx, galleryID, "session_2", "", "photo_1"); err != nil {
+		t.Errorf("ToggleFavorite() for another session error: %v", err)
+	}
+
+	recorded := selectionRepo.Outbox.EventsOfType(string(events.SelectionSubmitted))
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 selection.submitted event, got %d", len(recorded))
+	}
+	var payload events.SelectionSubmittedPayload
+	if err := json.Unmarshal([]byte(recorded[0].Payload), &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.GalleryID != galleryID || payload.ClientID != "session_1" || payload.PhotoCount != 2 {
+		t.Errorf("Payload = %+v, want 2 photos from session_1 in %s", payload, galleryID)
+	}
+}
+
+func TestSubmitSelectionValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		gallery   *repository.Gallery
+		favorites []string
+		galleryID string
+		wantCode  int
+	}{
+		{name: "proofing disabled", gallery: &repository.Gallery{GalleryID: "gal_123"}, favorites: []string{"photo_1"}, galleryID: "gal_123", wantCode: 400},
+		{name: "empty selection", gallery: &repository.Gallery{GalleryID: "gal_123", ProofingEnabled: true}, galleryID: "gal_123", wantCode: 400},
+		{name: "limit lowered after picking", gallery: &repository.Gallery{GalleryID: "gal_123", ProofingEnabled: true, SelectionLimit: 2},
+			favorites: []string{"photo_1", "photo_2", "photo_3"}, galleryID: "gal_123", wantCode: 400},
+		{name: "unknown gallery", gallery: &repository.Gallery{GalleryID: "gal_123", ProofingEnabled: true}, galleryID: "gal_missing", wantCode: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, galleryRepo, favoriteRepo := newTestService()
+			galleryRepo.galleries[tt.gallery.GalleryID] = tt.gallery
+			// Picks are stored directly, as a limit lowered after picking lets them exceed it
+			for _, fav := range fixtures.NewFavoriteList(tt.gallery.GalleryID, "session_1", tt.favorites) {
+				favoriteRepo.Create(context.Background(), fav)
+			}
+
+			_, err := service.SubmitSelection(context.Background(), tt.galleryID, "session_1")
+			assertErrorCode(t, err, tt.wantCode)
+		})
+	}
+}
+
+func TestReopenSelection(t *testing.T) {
+	service, _, galleryRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryID := "gal_123"
+	galleryRepo.galleries[galleryID] = &repository.Gallery{GalleryID: galleryID, PhotographerID: "user_owner", ProofingEnabled: true}
+
+	_, err := service.ReopenSelection(ctx, "user_owner", galleryID, "session_1")
+	assertNotFound(t, err, "Selection")
+
+	if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", "photo_1"); err != nil {
+		t.Fatalf("ToggleFavorite() error: %v", err)
+	}
+	if _, err := service.SubmitSelection(ctx, galleryID, "session_1"); err != nil {
+		t.Fatalf("SubmitSelection() error: %v", err)
+	}
+
+	_, err = service.ReopenSelection(ctx, "user_other", galleryID, "session_1")
+	assertNotFound(t, err, "Gallery")
+
+	selection, err := service.ReopenSelection(ctx, "user_owner", galleryID, "session_1")
+	if err != nil {
+		t.Fatalf("ReopenSelection() error: %v", err)
+	}
+	if selection.Status != repository.SelectionStatusOpen || selection.ReopenedAt == nil || selection.SubmittedAt == nil {
+		t.Errorf("Selection = %+v, want open, keeping the last submission time", selection)
+	}
+
+	_, err = service.ReopenSelection(ctx, "user_owner", galleryID, "session_1")
+	assertErrorCode(t, err, 409)
+
+	// The client can change their picks and submit again
+	if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", "photo_2"); err != nil {
+		t.Fatalf("ToggleFavorite() after reopen error: %v", err)
+	}
+	resubmitted, err := service.SubmitSelection(ctx, galleryID, "session_1")
+	if err != nil {
+		t.Fatalf("SubmitSelection() after reopen error: %v", err)
+	}
+	if resubmitted.PhotoCount != 2 {
+		t.Errorf("PhotoCount = %d, want 2", resubmitted.PhotoCount)
+	}
+
+	selections, err := service.ListSelectionsByGallery(ctx, "user_owner", galleryID)
+	if err != nil {
+		t.Fatalf("ListSelectionsByGallery() error: %v", err)
+	}
+	if len(selections) != 1 || selections[0].Status != repository.SelectionStatusSubmitted {
+		t.Errorf("Selections = %+v, want one submitted selection", selections)
+	}
+}
+
+func TestGetSelectionDefaultsToOpen(t *testing.T) {
+	service, _, _, _ := newTestService()
+
+	selection, err := service.GetSelection(context.Background(), "gal_123", "session_1")
+	if err != nil {
+		t.Fatalf("GetSelection() error: %v", err)
+	}
+	if selection.Status != repository.SelectionStatusOpen || selection.SubmittedAt != nil {
+		t.Errorf("Selection = %+v, want open and never submitted", selection)
+	}
+}
@@ -2,21 +2,29 @@ package photo
 
 import (
 	"context"
+	"sort"
 	"time"
 
+	"photographer-gallery/backend/internal/domain/quota"
 	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/internal/services/storage"
 	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/events"
 	"photographer-gallery/backend/pkg/logger"
 	"photographer-gallery/backend/pkg/utils"
 )
 
 // Service handles photo business logic
 type Service struct {
-	photoRepo    repository.PhotoRepository
-	galleryRepo  repository.GalleryRepository
-	favoriteRepo repository.FavoriteRepository
+	photoRepo      repository.PhotoRepository
+	galleryRepo    repository.GalleryRepository
+	favoriteRepo   repository.FavoriteRepository
+	selectionRepo  repository.SelectionRepository
 	storageService *storage.Service
+	trashRepo      repository.TrashRepository
+	trashRetention time.Duration
+	quota          *quota.Service
+	reprocessor    Reprocessor
 }
 
 // NewService creates a new photo service
@@ -24,21 +32,32 @@ func NewService(
 	photoRepo repository.PhotoRepository,
 	galleryRepo repository.GalleryRepository,
 	favoriteRepo repository.FavoriteRepository,
+	selectionRepo repository.SelectionRepository,
 	storageService *storage.Service,
 ) *Service {
 	return &Service{
 		photoRepo:      photoRepo,
 		galleryRepo:    galleryRepo,
 		favoriteRepo:   favoriteRepo,
+		selectionRepo:  selectionRepo,
 		storageService: storageService,
 	}
 }
 
+// WithQuota makes the service enforce plan storage limits on uploads and record the storage
+// photos use as they are created and deleted.
+func (s *Service) WithQuota(q *quota.Service) *Service {
+	s.quota = q
+	return s
+}
+
 // UploadURLRequest represents a request for an upload URL
 type UploadURLRequest struct {
-	GalleryID string
-	FileName  string
-	MimeType  string
+	PhotographerID string
+	GalleryID      string
+	FileName       string
+	MimeType       string
+	Size           int64 // declared file size in bytes, checked against the storage quota and signed into the upload URL
 }
 
 // UploadURLResponse contains the upload URL and photo metadata
@@ -50,19 +69,25 @@ type UploadURLResponse struct {
 
 // GenerateUploadURL creates a presigned URL for uploading a photo
 func (s *Service) GenerateUploadURL(ctx context.Context, req UploadURLRequest) (*UploadURLResponse, error) {
-	// Verify gallery exists
-	gallery, err := s.galleryRepo.GetByID(ctx, req.GalleryID)
+	// Verify gallery exists and belongs to the photographer
+	gallery, err := s.authorizeGallery(ctx, req.PhotographerID, req.GalleryID)
 	if err != nil {
-		return nil, errors.Wrap(err, 500, "Failed to get gallery")
+		return nil, err
 	}
-	if gallery == nil {
-		return nil, errors.NewNotFound("Gallery")
+	if gallery.Status == repository.GalleryStatusArchived || gallery.Status == repository.GalleryStatusRestoring {
+		return nil, errors.NewConflict("Gallery is archived")
 	}
 
 	// Validate file type
 	if !isValidImageType(req.MimeType) {
 		return nil, errors.NewBadRequest("Invalid image type. Supported: JPEG, PNG, WebP")
 	}
+	if req.Size <= 0 {
+		return nil, errors.NewBadRequest("File size is required")
+	}
+	if err := s.quota.CheckUpload(ctx, req.PhotographerID, req.Size); err != nil {
+		return nil, err
+	}
 
 	// Generate photo ID
 	photoID := utils.GenerateID("photo")
@@ -73,6 +98,7 @@ func (s *Service) GenerateUploadURL(ctx context.Context, req UploadURLRequest) (
 		PhotoID:   photoID,
 		FileName:  req.FileName,
 		MimeType:  req.MimeType,
+		Size:      req.Size,
 	})
 
 	if err != nil {
@@ -119,24 +145,34 @@ func (s *Service) Create(ctx context.Context, req CreatePhotoRequest) (*reposito
 	}
 
 	photo := &repository.Photo{
-		PhotoID:       req.PhotoID,
-		GalleryID:     req.GalleryID,
-		FileName:      req.FileName,
-		OriginalKey:   req.OriginalKey,
-		OptimizedKey:  req.OptimizedKey,
-		ThumbnailKey:  req.ThumbnailKey,
-		MimeType:      req.MimeType,
-		Size:          req.Size,
-		Width:         req.Width,
-		Height:        req.Height,
-		UploadedAt:    time.Now(),
-		FavoriteCount: 0,
-		DownloadCount: 0,
-		Metadata:      req.Metadata,
-	}
-
-	// Create photo record
-	if err := s.photoRepo.Create(ctx, photo); err != nil {
+		PhotoID:        req.PhotoID,
+		GalleryID:      req.GalleryID,
+		PhotographerID: gallery.PhotographerID,
+		FileName:       req.FileName,
+		OriginalKey:    req.OriginalKey,
+		OptimizedKey:   req.OptimizedKey,
+		ThumbnailKey:   req.ThumbnailKey,
+		MimeType:       req.MimeType,
+		Size:           req.Size,
+		Width:          req.Width,
+		Height:         req.Height,
+		UploadedAt:     time.Now(),
+		FavoriteCount:  0,
+		DownloadCount:  0,
+		Metadata:       req.Metadata,
+	}
+
+	// Create photo record together with its upload event
+	writeCtx, err := repository.WithOutboxEvents(ctx, events.NewEvent(events.PhotoUploaded, &events.PhotoUploadedPayload{
+		PhotoID:   photo.PhotoID,
+		GalleryID: photo.GalleryID,
+		FileName:  photo.FileName,
+		Size:      photo.Size,
+	}, photo.GalleryID))
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to record photo event")
+	}
+	if err := s.photoRepo.Create(writeCtx, photo); err != nil {
 		logger.Error("Failed to create photo", map[string]interface{}{"error": err.Error()})
 		return nil, errors.Wrap(err, 500, "Failed to create photo")
 	}
@@ -148,6 +184,7 @@ func (s *Service) Create(ctx context.Context, req CreatePhotoRequest) (*reposito
 	if err := s.galleryRepo.UpdateTotalSize(ctx, req.GalleryID, req.Size); err != nil {
 		logger.Error("Failed to update total size", map[string]interface{}{"error": err.Error()})
 	}
+	s.quota.Record(ctx, gallery.PhotographerID, photo.Size)
 
 	logger.Info("Photo created", map[string]interface{}{
 		"photoId":   photo.PhotoID,
@@ -164,7 +201,7 @@ func (s *Service) GetByID(ctx context.Context, photoID string) (*repository.Phot
 	if err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to get photo")
 	}
-	if photo == nil {
+	if photo == nil || photo.DeletedAt != nil {
 		return nil, errors.NewNotFound("Photo")
 	}
 	return photo, nil
@@ -176,19 +213,38 @@ func (s *Service) ListByGallery(ctx context.Context, galleryID string, limit int
 	if err != nil {
 		return nil, nil, errors.Wrap(err, 500, "Failed to list photos")
 	}
-	return photos, nextKey, nil
+
+	// Photos in the trash are listed with the trash; pages may come back short
+	visible := photos[:0]
+	for _, photo := range photos {
+		if photo.DeletedAt == nil {
+			visible = append(visible, photo)
+		}
+	}
+	return visible, nextKey, nil
+}
+
+// ListByGalleryForPhotographer lists photos in a gallery owned by the photographer
+func (s *Service) ListByGalleryForPhotographer(ctx context.Context, photographerID, galleryID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	if _, err := s.authorizeGallery(ctx, photographerID, galleryID); err != nil {
+		return nil, nil, err
+	}
+	return s.ListByGallery(ctx, galleryID, limit, lastKey)
 }
 
-// Delete deletes a photo and all its files
-func (s *Service) Delete(ctx context.Context, photoID string) error {
+// Delete deletes a photo owned by the photographer and all its files
+func (s *Service) Delete(ctx context.Context, photographerID, photoID string) error {
 	// Get photo
-	photo, err := s.GetByID(ctx, photoID)
+	photo, err := s.authorizePhoto(ctx, photographerID, photoID)
 	if err != nil {
 		return err
 	}
+	if s.trashRepo != nil {
+		return s.trashPhoto(ctx, photographerID, photo)
+	}
 
 	// Delete from S3
-	if err := s.storageService.DeletePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey); err != nil {
+	if err := s.storageService.DeletePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey, photo.RenditionKeys()...); err != nil {
 		logger.Error("Failed to delete photo files", map[string]interface{}{"error": err.Error()})
 		// Continue with deletion even if S3 fails
 	}
@@ -200,12 +256,8 @@ func (s *Service) Delete(ctx context.Context, photoID string) error {
 	}
 
 	// Update gallery stats
-	if err := s.galleryRepo.UpdatePhotoCount(ctx, photo.GalleryID, -1); err != nil {
-		logger.Error("Failed to update photo count", map[string]interface{}{"error": err.Error()})
-	}
-	if err := s.galleryRepo.UpdateTotalSize(ctx, photo.GalleryID, -photo.Size); err != nil {
-		logger.Error("Failed to update total size", map[string]interface{}{"error": err.Error()})
-	}
+	s.updateGalleryStats(ctx, photo, -1)
+	s.quota.Record(ctx, photographerID, -quota.PhotoBytes(photo))
 
 	logger.Info("Photo deleted", map[string]interface{}{
 		"photoId":   photo.PhotoID,
@@ -222,6 +274,14 @@ func (s *Service) GetDownloadURL(ctx context.Context, photoID string) (string, e
 		return "", err
 	}
 
+	gallery, err := s.galleryRepo.GetByID(ctx, photo.GalleryID)
+	if err != nil {
+		return "", errors.Wrap(err, 500, "Failed to get gallery")
+	}
+	if gallery != nil && gallery.DownloadPolicy == repository.DownloadPolicyNone {
+		return "", errors.New(403, "Downloads are disabled for this gallery")
+	}
+
 	// Increment download count
 	if err := s.photoRepo.IncrementDownloadCount(ctx, photoID); err != nil {
 		logger.Error("Failed to increment download count", map[string]interface{}{"error": err.Error()})
@@ -241,61 +301,159 @@ func (s *Service) GetDownloadURL(ctx context.Context, photoID string) (string, e
 	return url, nil
 }
 
-// ToggleFavorite toggles a photo's favorite status for a client session
-func (s *Service) ToggleFavorite(ctx context.Context, galleryID, sessionID, photoID string) (bool, error) {
+// ToggleFavorite toggles a photo's membership in one of a client session's favorites lists.
+// An empty listID means the session's default list.
+func (s *Service) ToggleFavorite(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error) {
+	if listID == "" {
+		listID = repository.DefaultFavoriteListID
+	}
+	if listID == repository.DefaultFavoriteListID {
+		// The default list is the proofing selection, which locks once submitted
+		if err := s.checkSelectionOpen(ctx, galleryID, sessionID); err != nil {
+			return false, err
+		}
+	} else if _, err := s.getFavoriteList(ctx, galleryID, sessionID, listID); err != nil {
+		return false, err
+	}
+
 	// Check if already favorited
-	isFavorited, err := s.favoriteRepo.IsFavorited(ctx, galleryID, sessionID, photoID)
+	isFavorited, err := s.favoriteRepo.IsFavorited(ctx, galleryID, sessionID, listID, photoID)
 	if err != nil {
 		return false, errors.Wrap(err, 500, "Failed to check favorite status")
 	}
+	if !isFavorited && listID == repository.DefaultFavoriteListID {
+		if err := s.checkSelectionLimit(ctx, galleryID, sessionID); err != nil {
+			return false, err
+		}
+	}
+
+	// A photo's favorite count tracks clients, so it only changes when the photo
+	// enters the session's first list or leaves its last one
+	inOtherLists, err := s.isInOtherLists(ctx, galleryID, sessionID, listID, photoID)
+	if err != nil {
+		return false, err
+	}
+
+	writeCtx, err := repository.WithOutboxEvents(ctx, events.NewEvent(events.FavoriteToggled, &events.FavoriteToggledPayload{
+		PhotoID:   photoID,
+		GalleryID: galleryID,
+		ClientID:  sessionID,
+		ListID:    listID,
+		Favorited: !isFavorited,
+	}, galleryID))
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to record favorite event")
+	}
 
 	if isFavorited {
 		// Remove favorite
-		if err := s.favoriteRepo.Delete(ctx, galleryID, sessionID, photoID); err != nil {
+		if err := s.favoriteRepo.Delete(writeCtx, galleryID, sessionID, listID, photoID); err != nil {
 			return false, errors.Wrap(err, 500, "Failed to remove favorite")
 		}
 		// Decrement count
-		if err := s.photoRepo.IncrementFavoriteCount(ctx, photoID, -1); err != nil {
-			logger.Error("Failed to decrement favorite count", map[string]interface{}{"error": err.Error()})
+		if !inOtherLists {
+			if err := s.photoRepo.IncrementFavoriteCount(ctx, photoID, -1); err != nil {
+				logger.Error("Failed to decrement favorite count", map[string]interface{}{"error": err.Error()})
+			}
 		}
-		logger.Info("Photo unfavorited", map[string]interface{}{"photoId": photoID})
+		logger.Info("Photo unfavorited", map[string]interface{}{"photoId": photoID, "listId": listID})
 		return false, nil
 	} else {
 		// Add favorite
 		favorite := &repository.Favorite{
 			GalleryID:   galleryID,
 			SessionID:   sessionID,
+			ListID:      listID,
 			PhotoID:     photoID,
 			FavoritedAt: time.Now(),
 		}
-		if err := s.favoriteRepo.Create(ctx, favorite); err != nil {
+		if err := s.favoriteRepo.Create(writeCtx, favorite); err != nil {
 			return false, errors.Wrap(err, 500, "Failed to add favorite")
 		}
 		// Increment count
-		if err := s.photoRepo.IncrementFavoriteCount(ctx, photoID, 1); err != nil {
-			logger.Error("Failed to increment favorite count", map[string]interface{}{"error": err.Error()})
+		if !inOtherLists {
+			if err := s.photoRepo.IncrementFavoriteCount(ctx, photoID, 1); err != nil {
+				logger.Error("Failed to increment favorite count", map[string]interface{}{"error": err.Error()})
+			}
 		}
-		logger.Info("Photo favorited", map[string]interface{}{"photoId": photoID})
+		logger.Info("Photo favorited", map[string]interface{}{"photoId": photoID, "listId": listID})
 		return true, nil
 	}
 }
 
-// ListFavoritesBySession lists favorites for a client session
+// ListFavoritesBySession lists favorites for a client session across all of its lists
 func (s *Service) ListFavoritesBySession(ctx context.Context, galleryID, sessionID string) ([]*repository.Favorite, error) {
 	favorites, err := s.favoriteRepo.ListBySession(ctx, galleryID, sessionID)
 	if err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to list favorites")
 	}
-	return favorites, nil
+	return s.withoutTrashed(ctx, favorites)
 }
 
-// ListFavoritesByGallery lists all favorites for a gallery (photographer view)
-func (s *Service) ListFavoritesByGallery(ctx context.Context, galleryID string) ([]*repository.Favorite, error) {
+// GalleryFavorite is a photo a client favorited, aggregated across that client's lists
+type GalleryFavorite struct {
+	GalleryID   string    `json:"galleryId"`
+	SessionID   string    `json:"sessionId"`
+	PhotoID     string    `json:"photoId"`
+	Lists       []string  `json:"lists"`       // names of the client's lists containing the photo
+	FavoritedAt time.Time `json:"favoritedAt"` // when the photo was first added to any list
+}
+
+// ListFavoritesByGallery lists all favorites for a gallery (photographer view).
+// A photo that a client keeps in several lists is reported once for that client; photos in
+// the trash are left out, as they are for clients.
+func (s *Service) ListFavoritesByGallery(ctx context.Context, photographerID, galleryID string) ([]*GalleryFavorite, error) {
+	if _, err := s.authorizeGallery(ctx, photographerID, galleryID); err != nil {
+		return nil, err
+	}
+
 	favorites, err := s.favoriteRepo.ListByGallery(ctx, galleryID)
 	if err != nil {
 		return nil, errors.Wrap(err, 500, "Failed to list gallery favorites")
 	}
-	return favorites, nil
+	if favorites, err = s.withoutTrashed(ctx, favorites); err != nil {
+		return nil, err
+	}
+	lists, err := s.favoriteRepo.ListListsByGallery(ctx, galleryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list gallery favorites lists")
+	}
+
+	names := make(map[string]string, len(lists))
+	for _, list := range lists {
+		names[list.SessionID+"#"+list.ListID] = list.Name
+	}
+
+	byClientPhoto := make(map[string]*GalleryFavorite, len(favorites))
+	result := make([]*GalleryFavorite, 0, len(favorites))
+	for _, favorite := range favorites {
+		name, ok := names[favorite.SessionID+"#"+favorite.ListID]
+		if !ok {
+			name = defaultListName(favorite.ListID)
+		}
+
+		key := favorite.SessionID + "#" + favorite.PhotoID
+		aggregated, ok := byClientPhoto[key]
+		if !ok {
+			aggregated = &GalleryFavorite{
+				GalleryID:   favorite.GalleryID,
+				SessionID:   favorite.SessionID,
+				PhotoID:     favorite.PhotoID,
+				FavoritedAt: favorite.FavoritedAt,
+			}
+			byClientPhoto[key] = aggregated
+			result = append(result, aggregated)
+		}
+		aggregated.Lists = append(aggregated.Lists, name)
+		if favorite.FavoritedAt.Before(aggregated.FavoritedAt) {
+			aggregated.FavoritedAt = favorite.FavoritedAt
+		}
+	}
+
+	for _, aggregated := range result {
+		sort.Strings(aggregated.Lists)
+	}
+	return result, nil
 }
 
 // Helper function to validate image types
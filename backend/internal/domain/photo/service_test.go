@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
 )
 
 // Mock repositories
@@ -13,6 +15,7 @@ type mockPhotoRepo struct {
 	photos          map[string]*repository.Photo
 	createErr       error
 	getErr          error
+	updateErr       error
 	deleteErr       error
 	listErr         error
 	favoriteCount   int
@@ -54,6 +57,9 @@ func (m *mockPhotoRepo) ListByGallery(ctx context.Context, galleryID string, lim
 }
 
 func (m *mockPhotoRepo) Update(ctx context.Context, photo *repository.Photo) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
 	if existing := m.photos[photo.PhotoID]; existing != nil {
 		m.photos[photo.PhotoID] = photo
 	}
@@ -84,6 +90,10 @@ func (m *mockPhotoRepo) IncrementDownloadCount(ctx context.Context, photoID stri
 	return nil
 }
 
+func (m *mockPhotoRepo) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	return nil, nil, nil
+}
+
 type mockGalleryRepo struct {
 	galleries    map[string]*repository.Gallery
 	photoCount   int
@@ -125,43 +135,52 @@ func (m *mockGalleryRepo) IncrementClientAccessCount(ctx context.Context, galler
 func (m *mockGalleryRepo) ListExpired(ctx context.Context, limit int) ([]*repository.Gallery, error) {
 	return nil, nil
 }
+func (m *mockGalleryRepo) ListByStatus(ctx context.Context, status string, limit int) ([]*repository.Gallery, error) {
+	return nil, nil
+}
 
 type mockFavoriteRepo struct {
-	favorites   map[string]*repository.Favorite
-	favorited   bool
-	createErr   error
-	deleteErr   error
-	listErr     error
+	favorites map[string]*repository.Favorite
+	lists     map[string]*repository.FavoriteList
+	createErr error
+	deleteErr error
+	listErr   error
 }
 
 func newMockFavoriteRepo() *mockFavoriteRepo {
 	return &mockFavoriteRepo{
 		favorites: make(map[string]*repository.Favorite),
+		lists:     make(map[string]*repository.FavoriteList),
 	}
 }
 
+// favoriteKey keeps default-list favorites under galleryID#sessionID#photoID
+func favoriteKey(galleryID, sessionID, listID, photoID string) string {
+	if listID == "" || listID == repository.DefaultFavoriteListID {
+		return galleryID + "#" + sessionID + "#" + photoID
+	}
+	return galleryID + "#" + sessionID + "#" + listID + "#" + photoID
+}
+
 func (m *mockFavoriteRepo) Create(ctx context.Context, favorite *repository.Favorite) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
-	key := favorite.GalleryID + "#" + favorite.SessionID + "#" + favorite.PhotoID
-	m.favorites[key] = favorite
-	m.favorited = true
+	m.favorites[favoriteKey(favorite.GalleryID, favorite.SessionID, favorite.ListID, favorite.PhotoID)] = favorite
 	return nil
 }
 
-func (m *mockFavoriteRepo) Delete(ctx context.Context, galleryID, sessionID, photoID string) error {
+func (m *mockFavoriteRepo) Delete(ctx context.Context, galleryID, sessionID, listID, photoID string) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
-	key := galleryID + "#" + sessionID + "#" + photoID
-	delete(m.favorites, key)
-	m.favorited = false
+	delete(m.favorites, favoriteKey(galleryID, sessionID, listID, photoID))
 	return nil
 }
 
-func (m *mockFavoriteRepo) IsFavorited(ctx context.Context, galleryID, sessionID, photoID string) (bool, error) {
-	return m.favorited, nil
+func (m *mockFavoriteRepo) IsFavorited(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error) {
+	_, ok := m.favorites[favoriteKey(galleryID, sessionID, listID, photoID)]
+	return ok, nil
 }
 
 func (m *mockFavoriteRepo) ListBySession(ctx context.Context, galleryID, sessionID string) ([]*repository.Favorite, error) {
@@ -178,6 +197,62 @@ func (m *mockFavoriteRepo) ListBySession(ctx context.Context, galleryID, session
 	return result, nil
 }
 
+func (m *mockFavoriteRepo) ListByList(ctx context.Context, galleryID, sessionID, listID string) ([]*repository.Favorite, error) {
+	favorites, err := m.ListBySession(ctx, galleryID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*repository.Favorite
+	for _, fav := range favorites {
+		if favoriteKey(galleryID, sessionID, fav.ListID, fav.PhotoID) == favoriteKey(galleryID, sessionID, listID, fav.PhotoID) {
+			result = append(result, fav)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFavoriteRepo) CreateList(ctx context.Context, list *repository.FavoriteList) error {
+	m.lists[list.GalleryID+"#"+list.SessionID+"#"+list.ListID] = list
+	return nil
+}
+
+func (m *mockFavoriteRepo) GetList(ctx context.Context, galleryID, sessionID, listID string) (*repository.FavoriteList, error) {
+	return m.lists[galleryID+"#"+sessionID+"#"+listID], nil
+}
+
+func (m *mockFavoriteRepo) UpdateList(ctx context.Context, list *repository.FavoriteList) error {
+	return m.CreateList(ctx, list)
+}
+
+func (m *mockFavoriteRepo) DeleteList(ctx context.Context, galleryID, sessionID, listID string) error {
+	favorites, _ := m.ListByList(ctx, galleryID, sessionID, listID)
+	for _, fav := range favorites {
+		delete(m.favorites, favoriteKey(galleryID, sessionID, listID, fav.PhotoID))
+	}
+	delete(m.lists, galleryID+"#"+sessionID+"#"+listID)
+	return nil
+}
+
+func (m *mockFavoriteRepo) ListLists(ctx context.Context, galleryID, sessionID string) ([]*repository.FavoriteList, error) {
+	var result []*repository.FavoriteList
+	for _, list := range m.lists {
+		if list.GalleryID == galleryID && list.SessionID == sessionID {
+			result = append(result, list)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFavoriteRepo) ListListsByGallery(ctx context.Context, galleryID string) ([]*repository.FavoriteList, error) {
+	var result []*repository.FavoriteList
+	for _, list := range m.lists {
+		if list.GalleryID == galleryID {
+			result = append(result, list)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockFavoriteRepo) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Favorite, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
@@ -191,6 +266,15 @@ func (m *mockFavoriteRepo) ListByGallery(ctx context.Context, galleryID string)
 	return result, nil
 }
 
+// newTestService creates a service backed by the package's mock repositories
+func newTestService() (*Service, *mockPhotoRepo, *mockGalleryRepo, *mockFavoriteRepo) {
+	photoRepo := newMockPhotoRepo()
+	galleryRepo := newMockGalleryRepo()
+	favoriteRepo := newMockFavoriteRepo()
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
+	return service, photoRepo, galleryRepo, favoriteRepo
+}
+
 // Tests - focusing on business logic without storage service
 func TestCreatePhoto(t *testing.T) {
 	photoRepo := newMockPhotoRepo()
@@ -205,7 +289,7 @@ func TestCreatePhoto(t *testing.T) {
 	}
 	galleryRepo.galleries["gal_123"] = gallery
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	req := CreatePhotoRequest{
 		PhotoID:      "photo_123",
@@ -248,7 +332,7 @@ func TestGetPhotoByID(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	// Create a photo
 	photo := &repository.Photo{
@@ -273,7 +357,7 @@ func TestGetPhotoByIDNotFound(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	_, err := service.GetByID(context.Background(), "nonexistent")
 	if err == nil {
@@ -286,7 +370,7 @@ func TestListPhotosByGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	galleryID := "gal_123"
 
@@ -313,7 +397,7 @@ func TestToggleFavorite(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	// Create a photo
 	photo := &repository.Photo{
@@ -329,7 +413,7 @@ func TestToggleFavorite(t *testing.T) {
 
 	// Test adding favorite
 	t.Run("add favorite", func(t *testing.T) {
-		isFavorited, err := service.ToggleFavorite(context.Background(), galleryID, sessionID, photoID)
+		isFavorited, err := service.ToggleFavorite(context.Background(), galleryID, sessionID, "", photoID)
 		if err != nil {
 			t.Fatalf("ToggleFavorite() error: %v", err)
 		}
@@ -345,7 +429,7 @@ func TestToggleFavorite(t *testing.T) {
 
 	// Test removing favorite
 	t.Run("remove favorite", func(t *testing.T) {
-		isFavorited, err := service.ToggleFavorite(context.Background(), galleryID, sessionID, photoID)
+		isFavorited, err := service.ToggleFavorite(context.Background(), galleryID, sessionID, "", photoID)
 		if err != nil {
 			t.Fatalf("ToggleFavorite() error: %v", err)
 		}
@@ -365,7 +449,7 @@ func TestListFavoritesBySession(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	galleryID := "gal_123"
 	sessionID := "session_456"
@@ -397,9 +481,10 @@ func TestListFavoritesByGallery(t *testing.T) {
 	galleryRepo := newMockGalleryRepo()
 	favoriteRepo := newMockFavoriteRepo()
 
-	service := NewService(photoRepo, galleryRepo, favoriteRepo, nil)
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
 
 	galleryID := "gal_123"
+	galleryRepo.galleries[galleryID] = &repository.Gallery{GalleryID: galleryID, PhotographerID: "user_123"}
 
 	// Add favorites from different sessions
 	favorites := []*repository.Favorite{
@@ -413,7 +498,7 @@ func TestListFavoritesByGallery(t *testing.T) {
 	}
 
 	// List all favorites for gallery
-	result, err := service.ListFavoritesByGallery(context.Background(), galleryID)
+	result, err := service.ListFavoritesByGallery(context.Background(), "user_123", galleryID)
 	if err != nil {
 		t.Fatalf("ListFavoritesByGallery() error: %v", err)
 	}
@@ -423,6 +508,30 @@ func TestListFavoritesByGallery(t *testing.T) {
 	}
 }
 
+func TestGetDownloadURLDisabledByGallery(t *testing.T) {
+	photoRepo := newMockPhotoRepo()
+	galleryRepo := newMockGalleryRepo()
+	favoriteRepo := newMockFavoriteRepo()
+
+	service := NewService(photoRepo, galleryRepo, favoriteRepo, mocks.NewMockSelectionRepository(), nil)
+
+	galleryRepo.galleries["gal_123"] = &repository.Gallery{
+		GalleryID:      "gal_123",
+		DownloadPolicy: repository.DownloadPolicyNone,
+	}
+	photoRepo.photos["photo_123"] = &repository.Photo{
+		PhotoID:      "photo_123",
+		GalleryID:    "gal_123",
+		OptimizedKey: "gal_123/photo_123/optimized.jpg",
+	}
+
+	_, err := service.GetDownloadURL(context.Background(), "photo_123")
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != 403 {
+		t.Errorf("Expected 403 error, got: %v", err)
+	}
+}
+
 func TestIsValidImageType(t *testing.T) {
 	tests := []struct {
 		mimeType string
@@ -0,0 +1,158 @@
+package photo
+
+import (
+	"context"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// WithTrash makes Delete move photos to the photographer's trash, where they are kept for
+// retention before being purged. Without it photos are deleted immediately.
+func (s *Service) WithTrash(trashRepo repository.TrashRepository, retention time.Duration) *Service {
+	s.trashRepo = trashRepo
+	s.trashRetention = retention
+	return s
+}
+
+// trashPhoto hides a photo from its gallery and records it in the trash. The gallery's photo
+// count and size drop as they would on deletion; favorites and files are kept until the purge.
+func (s *Service) trashPhoto(ctx context.Context, photographerID string, photo *repository.Photo) error {
+	now := time.Now()
+	item := &repository.TrashItem{
+		PhotographerID: photographerID,
+		ItemID:         photo.PhotoID,
+		ItemType:       repository.TrashItemPhoto,
+		GalleryID:      photo.GalleryID,
+		Name:           photo.FileName,
+		PhotoCount:     1,
+		Size:           photo.Size,
+		DeletedAt:      now,
+		PurgeAt:        now.Add(s.trashRetention),
+	}
+	if err := s.trashRepo.Create(ctx, item); err != nil {
+		return errors.Wrap(err, 500, "Failed to move photo to trash")
+	}
+
+	photo.DeletedAt = &now
+	if err := s.photoRepo.Update(ctx, photo); err != nil {
+		photo.DeletedAt = nil
+		if rollbackErr := s.trashRepo.Delete(ctx, photographerID, photo.PhotoID); rollbackErr != nil {
+			logger.Warn("Failed to remove trash item of photo left in its gallery", map[string]interface{}{
+				"photoId": photo.PhotoID, "error": rollbackErr.Error(),
+			})
+		}
+		return errors.Wrap(err, 500, "Failed to move photo to trash")
+	}
+	s.updateGalleryStats(ctx, photo, -1)
+
+	logger.Info("Photo moved to trash", map[string]interface{}{
+		"photoId":   photo.PhotoID,
+		"galleryId": photo.GalleryID,
+	})
+	return nil
+}
+
+// RestoreDeleted takes a photo owned by the photographer out of the trash. A photo whose
+// gallery is itself in the trash can only come back once the gallery has been restored.
+func (s *Service) RestoreDeleted(ctx context.Context, photographerID, photoID string) (*repository.Photo, error) {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get photo")
+	}
+	if photo == nil {
+		return nil, errors.NewNotFound("Photo")
+	}
+	gallery, err := s.authorizeOwnedGallery(ctx, photographerID, photo.GalleryID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == 404 {
+			return nil, errors.NewNotFound("Photo")
+		}
+		return nil, err
+	}
+	if gallery.Status == repository.GalleryStatusDeleted {
+		return nil, errors.NewConflict("Restore the photo's gallery first")
+	}
+	if photo.DeletedAt == nil {
+		return nil, errors.NewConflict("Photo is not in the trash")
+	}
+
+	photo.DeletedAt = nil
+	if err := s.photoRepo.Update(ctx, photo); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to restore photo")
+	}
+	s.updateGalleryStats(ctx, photo, 1)
+
+	logger.Info("Photo restored from trash", map[string]interface{}{
+		"photoId":   photo.PhotoID,
+		"galleryId": photo.GalleryID,
+	})
+	return photo, nil
+}
+
+// PurgeDeleted permanently deletes a photo in the trash and its files, freeing the storage it
+// still counted against the photographer's quota. Photos that are gone or no longer in the trash
+// are left alone.
+func (s *Service) PurgeDeleted(ctx context.Context, photoID string) error {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to get photo")
+	}
+	if photo == nil || photo.DeletedAt == nil {
+		return nil
+	}
+
+	if s.storageService != nil {
+		if err := s.storageService.DeletePhoto(ctx, photo.OriginalKey, photo.OptimizedKey, photo.ThumbnailKey, photo.RenditionKeys()...); err != nil {
+			logger.Error("Failed to delete photo files", map[string]interface{}{"error": err.Error()})
+			// Continue with deletion even if S3 fails
+		}
+	}
+	if err := s.photoRepo.Delete(ctx, photoID); err != nil {
+		return errors.Wrap(err, 500, "Failed to delete photo")
+	}
+	if gallery, err := s.galleryRepo.GetByID(ctx, photo.GalleryID); err == nil && gallery != nil {
+		s.quota.Record(ctx, gallery.PhotographerID, -quota.PhotoBytes(photo))
+	}
+
+	logger.Info("Photo purged from trash", map[string]interface{}{
+		"photoId":   photo.PhotoID,
+		"galleryId": photo.GalleryID,
+	})
+	return nil
+}
+
+// withoutTrashed drops favorites of photos in the trash, which clients no longer see. The
+// favorites are kept so the photos come back to their lists if restored.
+func (s *Service) withoutTrashed(ctx context.Context, favorites []*repository.Favorite) ([]*repository.Favorite, error) {
+	trashed := make(map[string]bool, len(favorites))
+	visible := make([]*repository.Favorite, 0, len(favorites))
+	for _, favorite := range favorites {
+		inTrash, seen := trashed[favorite.PhotoID]
+		if !seen {
+			photo, err := s.photoRepo.GetByID(ctx, favorite.PhotoID)
+			if err != nil {
+				return nil, errors.Wrap(err, 500, "Failed to get photo")
+			}
+			inTrash = photo != nil && photo.DeletedAt != nil
+			trashed[favorite.PhotoID] = inTrash
+		}
+		if !inTrash {
+			visible = append(visible, favorite)
+		}
+	}
+	return visible, nil
+}
+
+// updateGalleryStats adds or removes a photo from its gallery's photo count and total size
+func (s *Service) updateGalleryStats(ctx context.Context, photo *repository.Photo, delta int) {
+	if err := s.galleryRepo.UpdatePhotoCount(ctx, photo.GalleryID, delta); err != nil {
+		logger.Error("Failed to update photo count", map[string]interface{}{"error": err.Error()})
+	}
+	if err := s.galleryRepo.UpdateTotalSize(ctx, photo.GalleryID, int64(delta)*photo.Size); err != nil {
+		logger.Error("Failed to update total size", map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -0,0 +1,90 @@
+package photo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+func TestTrashPhotoUndoesTrashItemWhenUpdateFails(t *testing.T) {
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantItems int
+	}{
+		{name: "trash item removed", wantItems: 0},
+		{name: "trash item left when removing it fails", deleteErr: fmt.Errorf("trash unavailable"), wantItems: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, photoRepo, galleryRepo, _ := newTestService()
+			ctx := context.Background()
+			trashRepo := mocks.NewMockTrashRepository()
+			service.WithTrash(trashRepo, time.Hour)
+			galleryRepo.galleries["gal_123"] = &repository.Gallery{GalleryID: "gal_123", PhotographerID: "user_owner"}
+			photo := &repository.Photo{PhotoID: "photo_1", GalleryID: "gal_123"}
+			photoRepo.photos[photo.PhotoID] = photo
+
+			photoRepo.updateErr = fmt.Errorf("photos unavailable")
+			trashRepo.DeleteErr = tt.deleteErr
+			assertErrorCode(t, service.Delete(ctx, "user_owner", photo.PhotoID), 500)
+
+			if photo.DeletedAt != nil {
+				t.Errorf("DeletedAt = %v, want the photo left out of the trash", photo.DeletedAt)
+			}
+			if items, _ := trashRepo.ListByPhotographer(ctx, "user_owner"); len(items) != tt.wantItems {
+				t.Errorf("trash items = %d, want %d", len(items), tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestFavoriteListingsHideTrashedPhotos(t *testing.T) {
+	service, photoRepo, galleryRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryID := "gal_123"
+	service.WithTrash(mocks.NewMockTrashRepository(), time.Hour)
+	galleryRepo.galleries[galleryID] = &repository.Gallery{GalleryID: galleryID, PhotographerID: "user_owner", ProofingEnabled: true, SelectionLimit: 2}
+	for _, photoID := range []string{"photo_kept", "photo_trashed"} {
+		photoRepo.photos[photoID] = &repository.Photo{PhotoID: photoID, GalleryID: galleryID}
+		if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", photoID); err != nil {
+			t.Fatalf("ToggleFavorite(%s) error: %v", photoID, err)
+		}
+	}
+	if err := service.Delete(ctx, "user_owner", "photo_trashed"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	favorites, err := service.ListFavoritesBySession(ctx, galleryID, "session_1")
+	if err != nil || len(favorites) != 1 || favorites[0].PhotoID != "photo_kept" {
+		t.Errorf("ListFavoritesBySession() = %v, %v, want only photo_kept", favorites, err)
+	}
+	favorites, err = service.ListFavoritesByList(ctx, galleryID, "session_1", repository.DefaultFavoriteListID)
+	if err != nil || len(favorites) != 1 {
+		t.Errorf("ListFavoritesByList() = %v, %v, want only photo_kept", favorites, err)
+	}
+	lists, err := service.ListFavoriteLists(ctx, galleryID, "session_1")
+	if err != nil || lists[0].PhotoCount != 1 {
+		t.Errorf("ListFavoriteLists() = %v, %v, want one photo in the default list", lists, err)
+	}
+	byGallery, err := service.ListFavoritesByGallery(ctx, "user_owner", galleryID)
+	if err != nil || len(byGallery) != 1 || byGallery[0].PhotoID != "photo_kept" {
+		t.Errorf("ListFavoritesByGallery() = %v, %v, want only photo_kept", byGallery, err)
+	}
+
+	// The trashed photo no longer counts toward the selection limit or the submitted selection
+	if _, err := service.ToggleFavorite(ctx, galleryID, "session_1", "", "photo_other"); err != nil {
+		t.Fatalf("ToggleFavorite() with a trashed pick error: %v", err)
+	}
+	selection, err := service.SubmitSelection(ctx, galleryID, "session_1")
+	if err != nil {
+		t.Fatalf("SubmitSelection() error: %v", err)
+	}
+	if selection.PhotoCount != 2 {
+		t.Errorf("PhotoCount = %d, want 2", selection.PhotoCount)
+	}
+}
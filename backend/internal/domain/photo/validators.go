@@ -238,3 +238,18 @@ func ValidateUploadRequest(ctx context.Context, req UploadRequest) error {
 	chain := NewUploadValidationChain()
 	return chain.Validate(ctx, req)
 }
+
+// maxListNameLength is the longest name a client may give a favorites list.
+const maxListNameLength = 50
+
+// normalizeListName trims a favorites list name and checks its length.
+func normalizeListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.NewBadRequest("List name is required")
+	}
+	if len([]rune(name)) > maxListNameLength {
+		return "", errors.NewBadRequest("List name must be 50 characters or fewer")
+	}
+	return name, nil
+}
@@ -23,4 +23,7 @@ type Photographer struct {
 	VerificationToken string `json:"-" dynamodbav:"verificationToken,omitempty"`
 	CertificateArn    string `json:"-" dynamodbav:"certificateArn,omitempty"`
 	DomainVerifiedAt  string `json:"domainVerifiedAt,omitempty" dynamodbav:"domainVerifiedAt,omitempty"`
+
+	// Logo used by galleries with a logo watermark, stored in the originals bucket
+	WatermarkLogoKey string `json:"watermarkLogoKey,omitempty" dynamodbav:"watermarkLogoKey,omitempty"`
 }
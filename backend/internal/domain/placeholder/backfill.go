@@ -0,0 +1,166 @@
+// Package placeholder backfills the BlurHash placeholders of photos processed before the
+// processor generated them.
+package placeholder
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// pageSize is how many photographers, galleries or photos are read per page
+const pageSize = 100
+
+// AccountLister pages through every photographer account
+type AccountLister interface {
+	List(ctx context.Context, limit int, lastKey map[string]interface{}) ([]*photographer.Photographer, map[string]interface{}, error)
+}
+
+// Downloader reads objects from S3
+type Downloader interface {
+	Download(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+// Backfill computes placeholders for processed photos that don't have one. Rather than
+// reprocessing the original, it decodes the smallest derivative already stored, which gives
+// the same placeholder for a fraction of the work.
+type Backfill struct {
+	accounts    AccountLister
+	galleryRepo repository.GalleryRepository
+	photoRepo   repository.PhotoRepository
+	downloader  Downloader
+	bucket      string // where optimized derivatives and renditions are stored
+	processor   *image.Processor
+}
+
+// NewBackfill creates a backfill reading derivatives from the optimized bucket
+func NewBackfill(accounts AccountLister, galleryRepo repository.GalleryRepository, photoRepo repository.PhotoRepository, downloader Downloader, optimizedBucket string) *Backfill {
+	return &Backfill{
+		accounts:    accounts,
+		galleryRepo: galleryRepo,
+		photoRepo:   photoRepo,
+		downloader:  downloader,
+		bucket:      optimizedBucket,
+		processor:   image.NewProcessor(),
+	}
+}
+
+// Run backfills every photographer's photos and returns how many photos got a placeholder.
+// Photos that already have one are skipped, so an interrupted run can simply be started again.
+func (b *Backfill) Run(ctx context.Context) (int, error) {
+	var backfilled, errorCount int
+	var lastKey map[string]interface{}
+	for {
+		photographers, nextKey, err := b.accounts.List(ctx, pageSize, lastKey)
+		if err != nil {
+			return backfilled, errors.Wrap(err, 500, "Failed to list photographers")
+		}
+		for _, p := range photographers {
+			n, failed, err := b.backfillPhotographer(ctx, p.UserID)
+			backfilled += n
+			errorCount += failed
+			if err != nil {
+				return backfilled, err
+			}
+		}
+		if nextKey == nil {
+			break
+		}
+		lastKey = nextKey
+	}
+
+	logger.Info("Placeholder backfill completed", map[string]interface{}{
+		"photos": backfilled, "errors": errorCount,
+	})
+	if errorCount > 0 {
+		return backfilled, fmt.Errorf("completed with %d errors", errorCount)
+	}
+	return backfilled, nil
+}
+
+// backfillPhotographer backfills the photos of all a photographer's galleries, returning how
+// many photos were backfilled and how many failed
+func (b *Backfill) backfillPhotographer(ctx context.Context, photographerID string) (int, int, error) {
+	var backfilled, failed int
+	var galleryKey map[string]interface{}
+	for {
+		galleries, nextKey, err := b.galleryRepo.ListByPhotographer(ctx, photographerID, pageSize, galleryKey)
+		if err != nil {
+			return backfilled, failed, errors.Wrap(err, 500, "Failed to list galleries")
+		}
+		for _, g := range galleries {
+			if g.Status == repository.GalleryStatusArchived || g.Status == repository.GalleryStatusRestoring {
+				// Archiving releases derivatives; restored photos are processed again anyway
+				continue
+			}
+			n, f, err := b.backfillGallery(ctx, g.GalleryID)
+			backfilled += n
+			failed += f
+			if err != nil {
+				return backfilled, failed, err
+			}
+		}
+		if nextKey == nil {
+			return backfilled, failed, nil
+		}
+		galleryKey = nextKey
+	}
+}
+
+func (b *Backfill) backfillGallery(ctx context.Context, galleryID string) (int, int, error) {
+	var backfilled, failed int
+	var photoKey map[string]interface{}
+	for {
+		photos, nextKey, err := b.photoRepo.ListByGallery(ctx, galleryID, pageSize, photoKey)
+		if err != nil {
+			return backfilled, failed, errors.Wrap(err, 500, "Failed to list photos")
+		}
+		for _, photo := range photos {
+			if photo.BlurHash != "" || photo.ProcessingStatus != "completed" || source(photo) == "" {
+				continue
+			}
+			if err := b.backfillPhoto(ctx, photo); err != nil {
+				logger.Error("Failed to backfill photo placeholder", map[string]interface{}{
+					"photoId": photo.PhotoID, "error": err.Error(),
+				})
+				failed++
+				continue
+			}
+			backfilled++
+		}
+		if nextKey == nil {
+			return backfilled, failed, nil
+		}
+		photoKey = nextKey
+	}
+}
+
+func (b *Backfill) backfillPhoto(ctx context.Context, photo *repository.Photo) error {
+	body, err := b.downloader.Download(ctx, b.bucket, source(photo))
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer body.Close()
+
+	placeholder, err := b.processor.GeneratePlaceholder(body)
+	if err != nil {
+		return err
+	}
+	photo.BlurHash = placeholder.BlurHash
+	photo.DominantColor = placeholder.DominantColor
+	return b.photoRepo.Update(ctx, photo)
+}
+
+// source returns the key of the smallest derivative of a photo that keeps its whole frame
+func source(photo *repository.Photo) string {
+	if len(photo.Renditions) > 0 {
+		return photo.Renditions[0].Key
+	}
+	return photo.OptimizedKey
+}
@@ -0,0 +1,103 @@
+package placeholder
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+const optimizedBucket = "optimized"
+
+func jpegData(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 60, 40, 255
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	accounts := mocks.NewMockPhotographerStore()
+	galleries := mocks.NewMockGalleryRepository()
+	photos := mocks.NewMockPhotoRepository()
+	storage := mocks.NewMockStorageService()
+
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1"})
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_archived", PhotographerID: "user_1", Status: repository.GalleryStatusArchived})
+
+	data := jpegData(t)
+	storage.PutObject(optimizedBucket, "renditions/gal_1/ph_rendition/sm.jpg", data)
+	storage.PutObject(optimizedBucket, "gal_1/ph_optimized/original.jpg", data)
+
+	photos.AddPhoto(&repository.Photo{
+		PhotoID: "ph_rendition", GalleryID: "gal_1", ProcessingStatus: "completed",
+		OptimizedKey: "gal_1/ph_rendition/original.jpg",
+		Renditions:   []repository.Rendition{{Name: "sm", Key: "renditions/gal_1/ph_rendition/sm.jpg"}},
+	})
+	photos.AddPhoto(&repository.Photo{
+		PhotoID: "ph_optimized", GalleryID: "gal_1", ProcessingStatus: "completed",
+		OptimizedKey: "gal_1/ph_optimized/original.jpg",
+	})
+	photos.AddPhoto(&repository.Photo{
+		PhotoID: "ph_done", GalleryID: "gal_1", ProcessingStatus: "completed",
+		OptimizedKey: "gal_1/ph_done/original.jpg", BlurHash: "existing",
+	})
+	photos.AddPhoto(&repository.Photo{PhotoID: "ph_pending", GalleryID: "gal_1", ProcessingStatus: "pending"})
+	photos.AddPhoto(&repository.Photo{
+		PhotoID: "ph_archived", GalleryID: "gal_archived", ProcessingStatus: "archived",
+		OptimizedKey: "gal_archived/ph_archived/original.jpg",
+	})
+
+	backfilled, err := NewBackfill(accounts, galleries, photos, storage, optimizedBucket).Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if backfilled != 2 {
+		t.Errorf("backfilled = %d, want 2", backfilled)
+	}
+
+	for _, id := range []string{"ph_rendition", "ph_optimized"} {
+		photo, _ := photos.GetByID(ctx, id)
+		if len(photo.BlurHash) != 28 || photo.DominantColor == "" {
+			t.Errorf("%s placeholder = %q, %q", id, photo.BlurHash, photo.DominantColor)
+		}
+	}
+	if photo, _ := photos.GetByID(ctx, "ph_done"); photo.BlurHash != "existing" {
+		t.Errorf("existing placeholder replaced with %q", photo.BlurHash)
+	}
+}
+
+func TestBackfillCountsFailures(t *testing.T) {
+	ctx := context.Background()
+	accounts := mocks.NewMockPhotographerStore()
+	galleries := mocks.NewMockGalleryRepository()
+	photos := mocks.NewMockPhotoRepository()
+
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1"})
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	photos.AddPhoto(&repository.Photo{
+		PhotoID: "ph_missing", GalleryID: "gal_1", ProcessingStatus: "completed",
+		OptimizedKey: "gal_1/ph_missing/original.jpg",
+	})
+
+	backfilled, err := NewBackfill(accounts, galleries, photos, mocks.NewMockStorageService(), optimizedBucket).Run(ctx)
+	if err == nil {
+		t.Error("Run() should report photos whose derivative can't be read")
+	}
+	if backfilled != 0 {
+		t.Errorf("backfilled = %d, want 0", backfilled)
+	}
+}
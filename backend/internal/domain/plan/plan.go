@@ -0,0 +1,134 @@
+// Package plan defines what each subscription plan entitles a photographer to. Services check
+// entitlements before acting; violations are plan-limit AppErrors that clients turn into upgrade
+// prompts.
+package plan
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// Plans
+const (
+	Free = "free"
+	Pro  = "pro"
+)
+
+// Entitlement names reported in plan-limit errors
+const (
+	EntitlementStorage           = "storage"
+	EntitlementActiveGalleries   = "active_galleries"
+	EntitlementCustomDomain      = "custom_domain"
+	EntitlementWatermark         = "watermark"
+	EntitlementGalleryExpiration = "gallery_expiration"
+)
+
+const gigabyte int64 = 1 << 30
+
+// Entitlements are the limits and features of a plan
+type Entitlements struct {
+	Plan                 string        `json:"plan"`
+	StorageBytes         int64         `json:"storageBytes"`
+	MaxActiveGalleries   int           `json:"maxActiveGalleries"` // 0 = unlimited
+	CustomDomain         bool          `json:"customDomain"`
+	Watermark            bool          `json:"watermark"`
+	MaxGalleryExpiration time.Duration `json:"maxGalleryExpiration"` // 0 = galleries may stay up indefinitely
+	RenditionMaxWidth    int           `json:"renditionMaxWidth"`    // largest processed image served to clients
+	RenditionMaxHeight   int           `json:"renditionMaxHeight"`
+}
+
+var plans = map[string]Entitlements{
+	Free: {
+		Plan:                 Free,
+		StorageBytes:         5 * gigabyte,
+		MaxActiveGalleries:   3,
+		MaxGalleryExpiration: 30 * 24 * time.Hour,
+		RenditionMaxWidth:    1920,
+		RenditionMaxHeight:   1080,
+	},
+	Pro: {
+		Plan:               Pro,
+		StorageBytes:       500 * gigabyte,
+		CustomDomain:       true,
+		Watermark:          true,
+		RenditionMaxWidth:  3840,
+		RenditionMaxHeight: 2160,
+	},
+}
+
+// unlimited is used where no plan is enforced
+var unlimited = Entitlements{
+	StorageBytes:       math.MaxInt64,
+	CustomDomain:       true,
+	Watermark:          true,
+	RenditionMaxWidth:  plans[Pro].RenditionMaxWidth,
+	RenditionMaxHeight: plans[Pro].RenditionMaxHeight,
+}
+
+// For returns a plan's entitlements. Unknown plans get the free plan.
+func For(name string) Entitlements {
+	if e, ok := plans[name]; ok {
+		return e
+	}
+	return plans[Free]
+}
+
+// CheckStorage fails if storing size more bytes on top of used would exceed the plan's storage
+func (e Entitlements) CheckStorage(used, size int64) error {
+	if used+size > e.StorageBytes {
+		return errors.NewPlanLimit(EntitlementStorage, e.Plan, "Storage quota exceeded")
+	}
+	return nil
+}
+
+// CheckActiveGalleries fails if the plan doesn't allow another active gallery
+func (e Entitlements) CheckActiveGalleries(active int) error {
+	if e.MaxActiveGalleries > 0 && active >= e.MaxActiveGalleries {
+		return errors.NewPlanLimit(EntitlementActiveGalleries, e.Plan,
+			fmt.Sprintf("Your plan allows %d active galleries", e.MaxActiveGalleries))
+	}
+	return nil
+}
+
+// CheckCustomDomain fails if the plan doesn't include custom domains
+func (e Entitlements) CheckCustomDomain() error {
+	if !e.CustomDomain {
+		return errors.NewPlanLimit(EntitlementCustomDomain, e.Plan, "Custom domains are not included in your plan")
+	}
+	return nil
+}
+
+// CheckWatermark fails if the plan doesn't include watermarks
+func (e Entitlements) CheckWatermark() error {
+	if !e.Watermark {
+		return errors.NewPlanLimit(EntitlementWatermark, e.Plan, "Watermarks are not included in your plan")
+	}
+	return nil
+}
+
+// CheckExpiration fails if a gallery expiring at expiresAt (nil for never) would stay up longer
+// than the plan allows
+func (e Entitlements) CheckExpiration(expiresAt *time.Time, now time.Time) error {
+	if e.MaxGalleryExpiration == 0 {
+		return nil
+	}
+	if expiresAt == nil || expiresAt.After(now.Add(e.MaxGalleryExpiration)) {
+		days := int(e.MaxGalleryExpiration / (24 * time.Hour))
+		return errors.NewPlanLimit(EntitlementGalleryExpiration, e.Plan,
+			fmt.Sprintf("Galleries on your plan must expire within %d days", days))
+	}
+	return nil
+}
+
+// DefaultExpiration returns when a gallery created or reactivated at now expires if the
+// photographer doesn't choose, or nil if it doesn't have to
+func (e Entitlements) DefaultExpiration(now time.Time) *time.Time {
+	if e.MaxGalleryExpiration == 0 {
+		return nil
+	}
+	expiresAt := now.Add(e.MaxGalleryExpiration)
+	return &expiresAt
+}
@@ -0,0 +1,109 @@
+package plan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func TestForUnknownPlan(t *testing.T) {
+	for _, name := range []string{"", "enterprise"} {
+		if got := For(name); got != For(Free) {
+			t.Errorf("For(%q) = %+v, want free plan", name, got)
+		}
+	}
+}
+
+func TestProExceedsFree(t *testing.T) {
+	free, pro := For(Free), For(Pro)
+	if pro.StorageBytes <= free.StorageBytes {
+		t.Errorf("pro storage %d should exceed free storage %d", pro.StorageBytes, free.StorageBytes)
+	}
+	if pro.RenditionMaxWidth <= free.RenditionMaxWidth {
+		t.Errorf("pro renditions %d should exceed free renditions %d", pro.RenditionMaxWidth, free.RenditionMaxWidth)
+	}
+	if pro.MaxActiveGalleries != 0 || pro.MaxGalleryExpiration != 0 {
+		t.Errorf("pro galleries should be unlimited, got %+v", pro)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	free, pro := For(Free), For(Pro)
+	now := time.Now()
+	within := now.Add(7 * 24 * time.Hour)
+	beyond := now.Add(60 * 24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		err         error
+		entitlement string // empty when the check should pass
+	}{
+		{"free storage within limit", free.CheckStorage(free.StorageBytes-10, 10), ""},
+		{"free storage over limit", free.CheckStorage(free.StorageBytes-10, 11), EntitlementStorage},
+		{"free below gallery limit", free.CheckActiveGalleries(free.MaxActiveGalleries - 1), ""},
+		{"free at gallery limit", free.CheckActiveGalleries(free.MaxActiveGalleries), EntitlementActiveGalleries},
+		{"pro many galleries", pro.CheckActiveGalleries(1000), ""},
+		{"free custom domain", free.CheckCustomDomain(), EntitlementCustomDomain},
+		{"pro custom domain", pro.CheckCustomDomain(), ""},
+		{"free watermark", free.CheckWatermark(), EntitlementWatermark},
+		{"pro watermark", pro.CheckWatermark(), ""},
+		{"free expiring within limit", free.CheckExpiration(&within, now), ""},
+		{"free expiring beyond limit", free.CheckExpiration(&beyond, now), EntitlementGalleryExpiration},
+		{"free never expiring", free.CheckExpiration(nil, now), EntitlementGalleryExpiration},
+		{"pro never expiring", pro.CheckExpiration(nil, now), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.entitlement == "" {
+				if tt.err != nil {
+					t.Errorf("unexpected error: %v", tt.err)
+				}
+				return
+			}
+			if !errors.IsPlanLimit(tt.err) {
+				t.Fatalf("error = %v, want plan limit", tt.err)
+			}
+			if got := tt.err.(*errors.AppError).Details["entitlement"]; got != tt.entitlement {
+				t.Errorf("entitlement = %v, want %s", got, tt.entitlement)
+			}
+		})
+	}
+}
+
+func TestDefaultExpiration(t *testing.T) {
+	now := time.Now()
+	if got := For(Free).DefaultExpiration(now); got == nil || !got.Equal(now.Add(30*24*time.Hour)) {
+		t.Errorf("free DefaultExpiration() = %v, want 30 days out", got)
+	}
+	if got := For(Pro).DefaultExpiration(now); got != nil {
+		t.Errorf("pro DefaultExpiration() = %v, want nil", got)
+	}
+}
+
+func TestServiceFor(t *testing.T) {
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_pro", Plan: Pro})
+	service := NewService(accounts)
+	ctx := context.Background()
+
+	if e, err := service.For(ctx, "user_pro"); err != nil || e.Plan != Pro {
+		t.Errorf("For(user_pro) = %+v, %v; want pro", e, err)
+	}
+	if e, err := service.For(ctx, "user_new"); err != nil || e.Plan != Free {
+		t.Errorf("For(user_new) = %+v, %v; want free", e, err)
+	}
+
+	var unenforced *Service
+	e, err := unenforced.For(ctx, "user_new")
+	if err != nil {
+		t.Fatalf("For() on nil service error: %v", err)
+	}
+	if e.CheckActiveGalleries(1000) != nil || e.CheckCustomDomain() != nil || e.CheckExpiration(nil, time.Now()) != nil {
+		t.Errorf("nil service should not limit anything, got %+v", e)
+	}
+}
@@ -0,0 +1,42 @@
+package plan
+
+import (
+	"context"
+	stderrors "errors"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// Accounts looks up the photographer whose plan applies
+type Accounts interface {
+	GetByID(ctx context.Context, userID string) (*photographer.Photographer, error)
+}
+
+// Service resolves photographers' entitlements. A nil *Service imposes no limits, so services
+// built without one behave as before plans were enforced.
+type Service struct {
+	accounts Accounts
+}
+
+// NewService creates a new plan service
+func NewService(accounts Accounts) *Service {
+	return &Service{accounts: accounts}
+}
+
+// For returns the entitlements of the photographer's plan. Photographers without a profile yet
+// are on the free plan.
+func (s *Service) For(ctx context.Context, photographerID string) (Entitlements, error) {
+	if s == nil {
+		return unlimited, nil
+	}
+
+	p, err := s.accounts.GetByID(ctx, photographerID)
+	if err != nil {
+		if stderrors.Is(err, photographer.ErrNotFound) {
+			return For(Free), nil
+		}
+		return Entitlements{}, errors.Wrap(err, 500, "Failed to get plan")
+	}
+	return For(p.Plan), nil
+}
@@ -0,0 +1,143 @@
+// Package profile stores photographers' processing profiles, named lists of steps with an
+// output format, and turns the profile a gallery uses into the strategy chain and encoder its
+// optimized photos are made with. Profiles are validated when they're saved, so the processor
+// only sees profiles it can run.
+package profile
+
+import (
+	"fmt"
+	imageType "image"
+	"math"
+	"strings"
+
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// Step types
+const (
+	StepResize    = "resize"
+	StepSharpen   = "sharpen"
+	StepBlur      = "blur"
+	StepGrayscale = "grayscale"
+	StepWatermark = "watermark" // the gallery's watermark, text or logo
+)
+
+// Limits of a profile
+const (
+	MaxNameLength  = 50
+	MaxSteps       = 10
+	MaxDimension   = 10000 // largest resize width or height, in pixels
+	MaxSigma       = 20    // strongest sharpen or blur
+	DefaultQuality = 85
+)
+
+// Validate checks that a profile can be run: it has a name, its steps are known and their
+// settings in range, and its format can be encoded. Violations are bad-request AppErrors.
+func Validate(p *repository.ProcessingProfile) error {
+	name := strings.TrimSpace(p.Name)
+	if name == "" {
+		return errors.NewBadRequest("Processing profile name is required")
+	}
+	if len(name) > MaxNameLength {
+		return errors.NewBadRequest(fmt.Sprintf("Processing profile name must be at most %d characters", MaxNameLength))
+	}
+	if len(p.Steps) > MaxSteps {
+		return errors.NewBadRequest(fmt.Sprintf("Processing profile can have at most %d steps", MaxSteps))
+	}
+
+	watermarks := 0
+	for i, step := range p.Steps {
+		if err := validateStep(step); err != nil {
+			return errors.NewBadRequest(fmt.Sprintf("Processing step %d: %s", i+1, err))
+		}
+		if step.Type == StepWatermark {
+			watermarks++
+		}
+	}
+	if watermarks > 1 {
+		return errors.NewBadRequest("Processing profile can watermark photos only once")
+	}
+
+	switch p.Format {
+	case "", image.FormatJPEG, image.FormatPNG, image.FormatWebP:
+	default:
+		return errors.NewBadRequest("Invalid processing profile format, expected jpeg, png or webp")
+	}
+	if p.Quality < 0 || p.Quality > 100 {
+		return errors.NewBadRequest("Processing profile quality must be between 1 and 100")
+	}
+	return nil
+}
+
+func validateStep(step repository.ProcessingStep) error {
+	switch step.Type {
+	case StepResize:
+		if step.Width == 0 && step.Height == 0 {
+			return fmt.Errorf("resize needs a width or height")
+		}
+		if step.Width < 0 || step.Width > MaxDimension || step.Height < 0 || step.Height > MaxDimension {
+			return fmt.Errorf("resize width and height must be between 1 and %d pixels", MaxDimension)
+		}
+	case StepSharpen, StepBlur:
+		if step.Sigma <= 0 || step.Sigma > MaxSigma {
+			return fmt.Errorf("%s sigma must be greater than 0 and at most %d", step.Type, MaxSigma)
+		}
+	case StepGrayscale, StepWatermark:
+	default:
+		return fmt.Errorf("unknown step %q, expected resize, sharpen, blur, grayscale or watermark", step.Type)
+	}
+	return nil
+}
+
+// Strategies returns the strategies a gallery's profile applies, in its order. The watermark
+// step draws the gallery's watermark; a profile without one still gets it, after its other
+// steps, so choosing a profile never drops the watermark a gallery asks for.
+func Strategies(p *repository.ProcessingProfile, gallery *repository.Gallery, logo imageType.Image) []image.ProcessingStrategy {
+	strategies := make([]image.ProcessingStrategy, 0, len(p.Steps)+1)
+	watermarked := false
+	for _, step := range p.Steps {
+		switch step.Type {
+		case StepResize:
+			strategies = append(strategies, &image.ResizeStrategy{MaxWidth: bound(step.Width), MaxHeight: bound(step.Height)})
+		case StepSharpen:
+			strategies = append(strategies, image.NewSharpenStrategy(step.Sigma))
+		case StepBlur:
+			strategies = append(strategies, image.NewBlurStrategy(step.Sigma))
+		case StepGrayscale:
+			strategies = append(strategies, image.NewGrayscaleStrategy())
+		case StepWatermark:
+			strategies = append(strategies, watermark.Strategies(gallery, logo)...)
+			watermarked = true
+		}
+	}
+	if !watermarked {
+		strategies = append(strategies, watermark.Strategies(gallery, logo)...)
+	}
+	return strategies
+}
+
+// bound returns a resize limit, where 0 leaves the dimension unbounded
+func bound(pixels int) int {
+	if pixels == 0 {
+		return math.MaxInt32
+	}
+	return pixels
+}
+
+// Encoder returns the encoder for a profile's format and quality, JPEG at the default quality
+// unless it says otherwise. It fails with image.ErrFormatUnavailable for formats this build
+// can't encode.
+func Encoder(p *repository.ProcessingProfile) (image.Encoder, error) {
+	format := p.Format
+	if format == "" {
+		format = image.FormatJPEG
+	}
+	quality := p.Quality
+	if quality == 0 {
+		quality = DefaultQuality
+	}
+	return image.NewEncoder(format, quality)
+}
@@ -0,0 +1,146 @@
+package profile
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	imageType "image"
+	"strings"
+	"testing"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+const webProfile = `{
+	"name": "web",
+	"steps": [
+		{"type": "resize", "width": 2048, "height": 2048},
+		{"type": "sharpen", "sigma": 0.5},
+		{"type": "watermark"}
+	],
+	"format": "jpeg",
+	"quality": 82
+}`
+
+func parse(t *testing.T, data string) *repository.ProcessingProfile {
+	t.Helper()
+	var p repository.ProcessingProfile
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *repository.ProcessingProfile)
+		wantErr string
+	}{
+		{"web", func(p *repository.ProcessingProfile) {}, ""},
+		{"defaults", func(p *repository.ProcessingProfile) { *p = repository.ProcessingProfile{Name: "plain"} }, ""},
+		{"width only", func(p *repository.ProcessingProfile) { p.Steps[0].Height = 0 }, ""},
+		{"png", func(p *repository.ProcessingProfile) { p.Format, p.Quality = image.FormatPNG, 0 }, ""},
+		{"missing name", func(p *repository.ProcessingProfile) { p.Name = "  " }, "name"},
+		{"long name", func(p *repository.ProcessingProfile) { p.Name = strings.Repeat("a", MaxNameLength+1) }, "name"},
+		{"unknown step", func(p *repository.ProcessingProfile) { p.Steps[1].Type = "vignette" }, "step 2"},
+		{"resize without size", func(p *repository.ProcessingProfile) { p.Steps[0].Width, p.Steps[0].Height = 0, 0 }, "step 1"},
+		{"resize too large", func(p *repository.ProcessingProfile) { p.Steps[0].Width = MaxDimension + 1 }, "step 1"},
+		{"sharpen without sigma", func(p *repository.ProcessingProfile) { p.Steps[1].Sigma = 0 }, "step 2"},
+		{"blur too strong", func(p *repository.ProcessingProfile) {
+			p.Steps[1] = repository.ProcessingStep{Type: StepBlur, Sigma: MaxSigma + 1}
+		}, "step 2"},
+		{"two watermarks", func(p *repository.ProcessingProfile) {
+			p.Steps = append(p.Steps, repository.ProcessingStep{Type: StepWatermark})
+		}, "once"},
+		{"too many steps", func(p *repository.ProcessingProfile) {
+			for len(p.Steps) <= MaxSteps {
+				p.Steps = append(p.Steps, repository.ProcessingStep{Type: StepGrayscale})
+			}
+		}, "steps"},
+		{"avif", func(p *repository.ProcessingProfile) { p.Format = image.FormatAVIF }, "format"},
+		{"quality too high", func(p *repository.ProcessingProfile) { p.Quality = 101 }, "quality"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parse(t, webProfile)
+			tt.modify(p)
+			err := Validate(p)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+			var appErr *errors.AppError
+			if !stderrors.As(err, &appErr) || appErr.Code != 400 || !strings.Contains(appErr.Message, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want a bad request about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	watermarked := &repository.Gallery{EnableWatermark: true, WatermarkText: "© Studio"}
+
+	tests := []struct {
+		name    string
+		profile *repository.ProcessingProfile
+		gallery *repository.Gallery
+		want    string
+	}{
+		{"web", parse(t, webProfile), watermarked, "resize,sharpen,watermark"},
+		{"watermark disabled", parse(t, webProfile), &repository.Gallery{}, "resize,sharpen"},
+		{"watermark step first", &repository.ProcessingProfile{Steps: []repository.ProcessingStep{
+			{Type: StepWatermark}, {Type: StepGrayscale},
+		}}, watermarked, "watermark,grayscale"},
+		{"no watermark step", &repository.ProcessingProfile{Steps: []repository.ProcessingStep{
+			{Type: StepBlur, Sigma: 2}, {Type: StepGrayscale},
+		}}, watermarked, "blur,grayscale,watermark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, s := range Strategies(tt.profile, tt.gallery, nil) {
+				names = append(names, s.Name())
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("Strategies() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrategiesResizeOneDimension(t *testing.T) {
+	p := &repository.ProcessingProfile{Steps: []repository.ProcessingStep{{Type: StepResize, Width: 300}}}
+	chain := image.NewStrategyChain(Strategies(p, nil, nil)...)
+
+	out, err := chain.Process(imageType.NewRGBA(imageType.Rect(0, 0, 600, 1200)))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if b := out.Bounds(); b.Dx() != 300 || b.Dy() != 600 {
+		t.Errorf("resized to %dx%d, want 300x600", b.Dx(), b.Dy())
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	encoder, err := Encoder(parse(t, webProfile))
+	if err != nil {
+		t.Fatalf("Encoder() error = %v", err)
+	}
+	if jpeg, ok := encoder.(*image.JPEGEncoder); !ok || jpeg.Quality != 82 {
+		t.Errorf("Encoder() = %#v, want JPEG at quality 82", encoder)
+	}
+
+	encoder, err = Encoder(&repository.ProcessingProfile{Name: "plain"})
+	if err != nil {
+		t.Fatalf("Encoder() error = %v", err)
+	}
+	if jpeg, ok := encoder.(*image.JPEGEncoder); !ok || jpeg.Quality != DefaultQuality {
+		t.Errorf("Encoder() = %#v, want JPEG at the default quality", encoder)
+	}
+}
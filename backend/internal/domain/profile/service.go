@@ -0,0 +1,212 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
+)
+
+// maxProfilesPerPhotographer limits how many profiles a photographer can save.
+const maxProfilesPerPhotographer = 20
+
+// galleryPageSize is how many galleries are read at a time when looking for a profile's users
+const galleryPageSize = 100
+
+// Reprocessor queues the photos of matching galleries to go back through the image processor
+type Reprocessor interface {
+	ReprocessGalleries(ctx context.Context, photographerID string, match func(*repository.Gallery) bool) (int, error)
+}
+
+// Service handles photographers' stored processing profiles
+type Service struct {
+	profiles    repository.ProcessingProfileRepository
+	galleries   repository.GalleryRepository
+	reprocessor Reprocessor
+}
+
+// NewService creates a new processing profile service
+func NewService(profiles repository.ProcessingProfileRepository, galleries repository.GalleryRepository, reprocessor Reprocessor) *Service {
+	return &Service{
+		profiles:    profiles,
+		galleries:   galleries,
+		reprocessor: reprocessor,
+	}
+}
+
+// SaveProfileRequest is the content of a profile, as created or replaced
+type SaveProfileRequest struct {
+	Name    string
+	Steps   []repository.ProcessingStep
+	Format  string
+	Quality int
+}
+
+// UpdateResult describes a profile after a change
+type UpdateResult struct {
+	*repository.ProcessingProfile
+	Regenerating int `json:"regenerating"` // galleries using the profile whose photos are queued to be processed again
+}
+
+// Create validates and saves a new profile
+func (s *Service) Create(ctx context.Context, photographerID string, req SaveProfileRequest) (*repository.ProcessingProfile, error) {
+	now := time.Now()
+	p := &repository.ProcessingProfile{
+		ProfileID:      utils.GenerateID("prof"),
+		PhotographerID: photographerID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	apply(p, req)
+	if err := Validate(p); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.List(ctx, photographerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxProfilesPerPhotographer {
+		return nil, errors.NewBadRequest("Processing profile limit reached")
+	}
+	if err := checkNameFree(existing, p); err != nil {
+		return nil, err
+	}
+
+	if err := s.profiles.Create(ctx, p); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to create processing profile")
+	}
+	logger.Info("Processing profile created", map[string]interface{}{"profileId": p.ProfileID, "photographerId": photographerID})
+	return p, nil
+}
+
+// List returns a photographer's profiles
+func (s *Service) List(ctx context.Context, photographerID string) ([]*repository.ProcessingProfile, error) {
+	profiles, err := s.profiles.ListByPhotographer(ctx, photographerID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list processing profiles")
+	}
+	return profiles, nil
+}
+
+// Get retrieves a profile owned by the photographer
+func (s *Service) Get(ctx context.Context, photographerID, profileID string) (*repository.ProcessingProfile, error) {
+	p, err := s.profiles.GetByID(ctx, profileID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get processing profile")
+	}
+	if p == nil || p.PhotographerID != photographerID {
+		return nil, errors.NewNotFound("Processing profile")
+	}
+	return p, nil
+}
+
+// Update replaces a profile's name, steps and output. When the steps or output change, the
+// photos of every active gallery using the profile are queued to be processed again.
+func (s *Service) Update(ctx context.Context, photographerID, profileID string, req SaveProfileRequest) (*UpdateResult, error) {
+	p, err := s.Get(ctx, photographerID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	previous := *p
+	apply(p, req)
+	if err := Validate(p); err != nil {
+		return nil, err
+	}
+	if p.Name != previous.Name {
+		existing, err := s.List(ctx, photographerID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNameFree(existing, p); err != nil {
+			return nil, err
+		}
+	}
+	p.UpdatedAt = time.Now()
+
+	if err := s.profiles.Update(ctx, p); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to update processing profile")
+	}
+	logger.Info("Processing profile updated", map[string]interface{}{"profileId": profileID})
+
+	result := &UpdateResult{ProcessingProfile: p}
+	if !sameOutput(&previous, p) {
+		result.Regenerating, err = s.reprocessor.ReprocessGalleries(ctx, photographerID, func(g *repository.Gallery) bool {
+			return g.ProcessingProfileID == profileID
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Delete removes a profile no gallery uses. Galleries in the trash don't count; if one is
+// restored, its photos are processed as if it had no profile.
+func (s *Service) Delete(ctx context.Context, photographerID, profileID string) error {
+	if _, err := s.Get(ctx, photographerID, profileID); err != nil {
+		return err
+	}
+	using, err := s.countGalleries(ctx, photographerID, profileID)
+	if err != nil {
+		return err
+	}
+	if using > 0 {
+		return errors.NewConflict(fmt.Sprintf("Processing profile is used by %d galleries", using))
+	}
+
+	if err := s.profiles.Delete(ctx, profileID); err != nil {
+		return errors.Wrap(err, 500, "Failed to delete processing profile")
+	}
+	logger.Info("Processing profile deleted", map[string]interface{}{"profileId": profileID})
+	return nil
+}
+
+// countGalleries counts the photographer's galleries, outside the trash, that use a profile
+func (s *Service) countGalleries(ctx context.Context, photographerID, profileID string) (int, error) {
+	count := 0
+	var lastKey map[string]interface{}
+	for {
+		galleries, nextKey, err := s.galleries.ListByPhotographer(ctx, photographerID, galleryPageSize, lastKey)
+		if err != nil {
+			return 0, errors.Wrap(err, 500, "Failed to list galleries")
+		}
+		for _, g := range galleries {
+			if g.ProcessingProfileID == profileID && g.Status != repository.GalleryStatusDeleted {
+				count++
+			}
+		}
+		if nextKey == nil {
+			return count, nil
+		}
+		lastKey = nextKey
+	}
+}
+
+func apply(p *repository.ProcessingProfile, req SaveProfileRequest) {
+	p.Name = strings.TrimSpace(req.Name)
+	p.Steps = req.Steps
+	p.Format = req.Format
+	p.Quality = req.Quality
+}
+
+// checkNameFree rejects a profile named like another of the photographer's profiles
+func checkNameFree(existing []*repository.ProcessingProfile, p *repository.ProcessingProfile) error {
+	for _, other := range existing {
+		if other.ProfileID != p.ProfileID && strings.EqualFold(other.Name, p.Name) {
+			return errors.NewConflict(fmt.Sprintf("A processing profile named %q already exists", p.Name))
+		}
+	}
+	return nil
+}
+
+// sameOutput reports whether two versions of a profile make the same photos
+func sameOutput(a, b *repository.ProcessingProfile) bool {
+	return a.Format == b.Format && a.Quality == b.Quality && slices.Equal(a.Steps, b.Steps)
+}
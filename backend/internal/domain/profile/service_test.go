@@ -0,0 +1,128 @@
+package profile
+
+import (
+	"context"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func newTestService() (*Service, *mocks.MockGalleryRepository, *mocks.MockProcessingQueue) {
+	galleries := mocks.NewMockGalleryRepository()
+	queue := mocks.NewMockProcessingQueue()
+	reprocessor := reprocess.NewService(galleries, mocks.NewMockPhotoRepository(), queue)
+	return NewService(mocks.NewMockProcessingProfileRepository(), galleries, reprocessor), galleries, queue
+}
+
+func assertCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != code {
+		t.Fatalf("error = %v, want code %d", err, code)
+	}
+}
+
+func webRequest() SaveProfileRequest {
+	return SaveProfileRequest{Name: "web", Quality: 82, Steps: []repository.ProcessingStep{
+		{Type: StepResize, Width: 2048}, {Type: StepSharpen, Sigma: 0.5}, {Type: StepWatermark},
+	}}
+}
+
+func TestCreate(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "user_1", webRequest())
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	got, err := service.Get(ctx, "user_1", created.ProfileID)
+	if err != nil || got.Name != "web" || len(got.Steps) != 3 {
+		t.Fatalf("Get() = %+v, %v, want the saved profile", got, err)
+	}
+
+	// Profiles are validated when saved, and names are unique per photographer
+	_, err = service.Create(ctx, "user_1", SaveProfileRequest{Name: "sepia", Steps: []repository.ProcessingStep{{Type: "sepia"}}})
+	assertCode(t, err, 400)
+	_, err = service.Create(ctx, "user_1", SaveProfileRequest{Name: "WEB"})
+	assertCode(t, err, 409)
+	if _, err := service.Create(ctx, "user_2", webRequest()); err != nil {
+		t.Errorf("Create() of another photographer's web error: %v", err)
+	}
+
+	// Other photographers' profiles are hidden
+	_, err = service.Get(ctx, "user_2", created.ProfileID)
+	assertCode(t, err, 404)
+}
+
+func TestUpdateReprocessesGalleriesUsingProfile(t *testing.T) {
+	service, galleries, queue := newTestService()
+	ctx := context.Background()
+
+	web, err := service.Create(ctx, "user_1", webRequest())
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	for _, g := range []*repository.Gallery{
+		{GalleryID: "gal_web", ProcessingProfileID: web.ProfileID, Status: repository.GalleryStatusActive},
+		{GalleryID: "gal_web2", ProcessingProfileID: web.ProfileID, Status: repository.GalleryStatusActive},
+		{GalleryID: "gal_default", Status: repository.GalleryStatusActive},
+		{GalleryID: "gal_archived", ProcessingProfileID: web.ProfileID, Status: repository.GalleryStatusArchived},
+	} {
+		g.PhotographerID = "user_1"
+		galleries.AddGallery(g)
+	}
+
+	// Renaming doesn't change any photo
+	renamed := webRequest()
+	renamed.Name = "web large"
+	result, err := service.Update(ctx, "user_1", web.ProfileID, renamed)
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if result.Name != "web large" || result.Regenerating != 0 || len(queue.GalleryJobs()) != 0 {
+		t.Fatalf("Update() = %+v with jobs %+v, want renamed without reprocessing", result, queue.GalleryJobs())
+	}
+
+	sharper := renamed
+	sharper.Steps = []repository.ProcessingStep{{Type: StepResize, Width: 2048}, {Type: StepSharpen, Sigma: 1}}
+	result, err = service.Update(ctx, "user_1", web.ProfileID, sharper)
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if result.Regenerating != 2 {
+		t.Errorf("Regenerating = %d, want the two active galleries using the profile", result.Regenerating)
+	}
+	jobs := queue.GalleryJobs()
+	if len(jobs) != 2 || jobs[0].GalleryID == "gal_default" || jobs[1].GalleryID == "gal_default" {
+		t.Errorf("gallery jobs = %+v, want gal_web and gal_web2", jobs)
+	}
+}
+
+func TestDeleteProfileInUse(t *testing.T) {
+	service, galleries, _ := newTestService()
+	ctx := context.Background()
+
+	web, err := service.Create(ctx, "user_1", webRequest())
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_web", PhotographerID: "user_1",
+		ProcessingProfileID: web.ProfileID, Status: repository.GalleryStatusActive})
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_trashed", PhotographerID: "user_1",
+		ProcessingProfileID: web.ProfileID, Status: repository.GalleryStatusDeleted})
+
+	assertCode(t, service.Delete(ctx, "user_2", web.ProfileID), 404)
+	assertCode(t, service.Delete(ctx, "user_1", web.ProfileID), 409)
+
+	// Galleries in the trash don't keep a profile alive
+	galleries.Delete(ctx, "gal_web")
+	if err := service.Delete(ctx, "user_1", web.ProfileID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	_, err = service.Get(ctx, "user_1", web.ProfileID)
+	assertCode(t, err, 404)
+}
@@ -0,0 +1,200 @@
+// Package quota tracks how much storage each photographer uses and enforces their plan's limit.
+// Usage counts every stored object: originals, derivatives, and photos still in the trash.
+package quota
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// PhotoBytes returns the bytes stored for a photo's original and derivatives
+func PhotoBytes(photo *repository.Photo) int64 {
+	total := photo.Size + photo.OptimizedSize + photo.ThumbnailSize
+	for _, r := range photo.Renditions {
+		total += r.TotalSize()
+	}
+	return total
+}
+
+// AccountStore reads and updates photographers' recorded storage usage
+type AccountStore interface {
+	GetByID(ctx context.Context, userID string) (*photographer.Photographer, error)
+	UpdateStorageUsed(ctx context.Context, userID string, deltaBytes int64) error
+	SetStorageUsed(ctx context.Context, userID string, previous, bytes int64) error
+	List(ctx context.Context, limit int, lastKey map[string]interface{}) ([]*photographer.Photographer, map[string]interface{}, error)
+}
+
+// reconcilePageSize is how many galleries, photos or photographers are read per page when reconciling
+const reconcilePageSize = 100
+
+// reconcileAttempts bounds how often Reconcile rescans a photographer whose usage keeps
+// changing while it is being recomputed
+const reconcileAttempts = 3
+
+// Service records storage usage and checks uploads against plan limits. Usage is adjusted
+// incrementally as objects are written and deleted; Reconcile recomputes it to repair drift.
+// A nil *Service does no accounting, so callers that don't track storage can skip it.
+type Service struct {
+	accounts    AccountStore
+	galleryRepo repository.GalleryRepository
+	photoRepo   repository.PhotoRepository
+}
+
+// NewService creates a new quota service
+func NewService(accounts AccountStore, galleryRepo repository.GalleryRepository, photoRepo repository.PhotoRepository) *Service {
+	return &Service{accounts: accounts, galleryRepo: galleryRepo, photoRepo: photoRepo}
+}
+
+// CheckUpload returns a plan-limit error if storing size more bytes would put the photographer
+// over their plan's storage. Photographers without a profile yet are held to the free plan.
+func (s *Service) CheckUpload(ctx context.Context, photographerID string, size int64) error {
+	if s == nil {
+		return nil
+	}
+
+	entitlements, used := plan.For(plan.Free), int64(0)
+	p, err := s.accounts.GetByID(ctx, photographerID)
+	switch {
+	case err == nil:
+		entitlements, used = plan.For(p.Plan), p.StorageUsed
+	case !stderrors.Is(err, photographer.ErrNotFound):
+		return errors.Wrap(err, 500, "Failed to get storage usage")
+	}
+
+	if err := entitlements.CheckStorage(used, size); err != nil {
+		logger.Info("Upload refused over storage quota", map[string]interface{}{
+			"photographerId": photographerID, "used": used, "size": size, "limit": entitlements.StorageBytes,
+		})
+		return err
+	}
+	return nil
+}
+
+// Record adds deltaBytes (negative when objects are deleted) to the photographer's usage.
+// Failures are logged rather than returned; the next reconciliation corrects them.
+func (s *Service) Record(ctx context.Context, photographerID string, deltaBytes int64) {
+	if s == nil || deltaBytes == 0 || photographerID == "" {
+		return
+	}
+	if err := s.accounts.UpdateStorageUsed(ctx, photographerID, deltaBytes); err != nil {
+		logger.Error("Failed to update storage used", map[string]interface{}{
+			"photographerId": photographerID, "delta": deltaBytes, "error": err.Error(),
+		})
+	}
+}
+
+// Reconcile recomputes a photographer's usage from their galleries and photos, including those
+// in the trash, and stores it if it has drifted. The correction is conditional on the usage
+// read before the scan; if an upload or delete changes it meanwhile, the usage is read and
+// scanned again rather than overwriting that change. It returns the recomputed usage.
+func (s *Service) Reconcile(ctx context.Context, p *photographer.Photographer) (int64, error) {
+	recorded := p.StorageUsed
+	for attempt := 1; ; attempt++ {
+		used, err := s.photographerBytes(ctx, p.UserID)
+		if err != nil {
+			return 0, err
+		}
+		if used == recorded {
+			return used, nil
+		}
+
+		err = s.accounts.SetStorageUsed(ctx, p.UserID, recorded, used)
+		if err == nil {
+			logger.Warn("Corrected storage usage drift", map[string]interface{}{
+				"photographerId": p.UserID, "recorded": recorded, "actual": used,
+			})
+			return used, nil
+		}
+		if !stderrors.Is(err, repository.ErrConflict) || attempt == reconcileAttempts {
+			return 0, fmt.Errorf("set storage used: %w", err)
+		}
+
+		current, err := s.accounts.GetByID(ctx, p.UserID)
+		if err != nil {
+			return 0, fmt.Errorf("get storage used: %w", err)
+		}
+		recorded = current.StorageUsed
+	}
+}
+
+func (s *Service) photographerBytes(ctx context.Context, photographerID string) (int64, error) {
+	var used int64
+	var galleryKey map[string]interface{}
+	for {
+		galleries, nextKey, err := s.galleryRepo.ListByPhotographer(ctx, photographerID, reconcilePageSize, galleryKey)
+		if err != nil {
+			return 0, fmt.Errorf("list galleries: %w", err)
+		}
+		for _, g := range galleries {
+			size, err := s.galleryBytes(ctx, g.GalleryID)
+			if err != nil {
+				return 0, err
+			}
+			used += size
+		}
+		if nextKey == nil {
+			return used, nil
+		}
+		galleryKey = nextKey
+	}
+}
+
+func (s *Service) galleryBytes(ctx context.Context, galleryID string) (int64, error) {
+	var total int64
+	var lastKey map[string]interface{}
+	for {
+		photos, nextKey, err := s.photoRepo.ListByGallery(ctx, galleryID, reconcilePageSize, lastKey)
+		if err != nil {
+			return 0, fmt.Errorf("list photos of gallery %s: %w", galleryID, err)
+		}
+		for _, photo := range photos {
+			total += PhotoBytes(photo)
+		}
+		if nextKey == nil {
+			return total, nil
+		}
+		lastKey = nextKey
+	}
+}
+
+// ReconcileAll reconciles every photographer's usage and returns how many were corrected.
+// It keeps going past individual failures and reports them together at the end.
+func (s *Service) ReconcileAll(ctx context.Context) (int, error) {
+	var corrected, errorCount int
+	var lastKey map[string]interface{}
+	for {
+		photographers, nextKey, err := s.accounts.List(ctx, reconcilePageSize, lastKey)
+		if err != nil {
+			return corrected, errors.Wrap(err, 500, "Failed to list photographers")
+		}
+		for _, p := range photographers {
+			used, err := s.Reconcile(ctx, p)
+			if err != nil {
+				logger.Error("Failed to reconcile storage usage", map[string]interface{}{
+					"photographerId": p.UserID, "error": err.Error(),
+				})
+				errorCount++
+				continue
+			}
+			if used != p.StorageUsed {
+				corrected++
+			}
+		}
+		if nextKey == nil {
+			break
+		}
+		lastKey = nextKey
+	}
+
+	if errorCount > 0 {
+		return corrected, fmt.Errorf("completed with %d errors", errorCount)
+	}
+	return corrected, nil
+}
@@ -0,0 +1,182 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func newTestService() (*Service, *mocks.MockPhotographerStore, *mocks.MockGalleryRepository, *mocks.MockPhotoRepository) {
+	accounts := mocks.NewMockPhotographerStore()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	return NewService(accounts, galleryRepo, photoRepo), accounts, galleryRepo, photoRepo
+}
+
+const gigabyte int64 = 1 << 30
+
+func TestCheckUpload(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		used    int64
+		size    int64
+		wantErr bool
+	}{
+		{"free within limit", plan.Free, 0, gigabyte, false},
+		{"free exactly at limit", plan.Free, plan.For(plan.Free).StorageBytes - gigabyte, gigabyte, false},
+		{"free over limit", plan.Free, plan.For(plan.Free).StorageBytes - gigabyte, gigabyte + 1, true},
+		{"free already full, unknown size", plan.Free, plan.For(plan.Free).StorageBytes + 1, 0, true},
+		{"pro beyond free limit", plan.Pro, plan.For(plan.Free).StorageBytes, gigabyte, false},
+		{"pro over limit", plan.Pro, plan.For(plan.Pro).StorageBytes, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, accounts, _, _ := newTestService()
+			accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", Plan: tt.plan, StorageUsed: tt.used})
+
+			err := service.CheckUpload(context.Background(), "user_1", tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckUpload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.IsPlanLimit(err) {
+				t.Errorf("CheckUpload() error = %v, want plan limit", err)
+			}
+		})
+	}
+}
+
+func TestCheckUploadWithoutProfile(t *testing.T) {
+	service, _, _, _ := newTestService()
+	ctx := context.Background()
+
+	if err := service.CheckUpload(ctx, "user_new", gigabyte); err != nil {
+		t.Errorf("CheckUpload() error: %v", err)
+	}
+	if err := service.CheckUpload(ctx, "user_new", plan.For(plan.Free).StorageBytes+1); err == nil {
+		t.Error("photographers without a profile should be held to the free limit")
+	}
+}
+
+func TestNilServiceSkipsAccounting(t *testing.T) {
+	var service *Service
+	ctx := context.Background()
+
+	if err := service.CheckUpload(ctx, "user_1", plan.For(plan.Pro).StorageBytes+1); err != nil {
+		t.Errorf("CheckUpload() on nil service error: %v", err)
+	}
+	service.Record(ctx, "user_1", 100) // must not panic
+}
+
+func TestRecord(t *testing.T) {
+	service, accounts, _, _ := newTestService()
+	ctx := context.Background()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", StorageUsed: 1000})
+
+	service.Record(ctx, "user_1", 500)
+	service.Record(ctx, "user_1", -200)
+	service.Record(ctx, "user_missing", 100)
+
+	if got := accounts.StorageUsed("user_1"); got != 1300 {
+		t.Errorf("StorageUsed = %d, want 1300", got)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	service, accounts, galleryRepo, photoRepo := newTestService()
+	ctx := context.Background()
+
+	p := &photographer.Photographer{UserID: "user_1", StorageUsed: 42}
+	accounts.AddPhotographer(p)
+
+	active := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	trashed := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1", Status: repository.GalleryStatusDeleted})
+	other := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_2"})
+	for _, g := range []*repository.Gallery{active, trashed, other} {
+		galleryRepo.AddGallery(g)
+	}
+
+	deletedAt := time.Now()
+	photos := []*repository.Photo{
+		fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: active.GalleryID, Size: 1000}),
+		fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: active.GalleryID, Size: 2000}),
+		fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: trashed.GalleryID, Size: 4000}),
+		fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: other.GalleryID, Size: 8000}),
+	}
+	photos[0].OptimizedSize, photos[0].ThumbnailSize = 300, 30
+	photos[1].DeletedAt = &deletedAt
+	for _, photo := range photos {
+		photoRepo.AddPhoto(photo)
+	}
+
+	used, err := service.Reconcile(ctx, p)
+	if err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+
+	// Trashed photos and galleries still occupy storage until purged
+	const want = 1000 + 300 + 30 + 2000 + 4000
+	if used != want {
+		t.Errorf("Reconcile() = %d, want %d", used, want)
+	}
+	if got := accounts.StorageUsed("user_1"); got != want {
+		t.Errorf("StorageUsed = %d, want %d", got, want)
+	}
+}
+
+func TestReconcileKeepsConcurrentChanges(t *testing.T) {
+	service, accounts, galleryRepo, photoRepo := newTestService()
+	ctx := context.Background()
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	photoRepo.AddPhoto(fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID, Size: 1000}))
+
+	// The listing reported 0 bytes, but an upload has been recorded since
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", StorageUsed: 0})
+	stale, _ := accounts.GetByID(ctx, "user_1")
+	service.Record(ctx, "user_1", 1000)
+	photoRepo.AddPhoto(fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID, Size: 500}))
+	service.Record(ctx, "user_1", 500)
+
+	used, err := service.Reconcile(ctx, stale)
+	if err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if used != 1500 {
+		t.Errorf("Reconcile() = %d, want 1500", used)
+	}
+	if got := accounts.StorageUsed("user_1"); got != 1500 {
+		t.Errorf("StorageUsed = %d, want 1500", got)
+	}
+}
+
+func TestReconcileAll(t *testing.T) {
+	service, accounts, galleryRepo, photoRepo := newTestService()
+
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_drifted"})
+	galleryRepo.AddGallery(g)
+	photoRepo.AddPhoto(fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: g.GalleryID, Size: 5000}))
+
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_drifted", StorageUsed: 0})
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_accurate", StorageUsed: 0})
+
+	corrected, err := service.ReconcileAll(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcileAll() error: %v", err)
+	}
+	if corrected != 1 {
+		t.Errorf("corrected = %d, want 1", corrected)
+	}
+	if got := accounts.StorageUsed("user_drifted"); got != 5000 {
+		t.Errorf("StorageUsed = %d, want 5000", got)
+	}
+}
@@ -0,0 +1,71 @@
+package reprocess
+
+import (
+	"context"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// FailedPhoto is a photo that couldn't be processed, listed with its gallery so failures
+// across galleries can be triaged together
+type FailedPhoto struct {
+	*repository.Photo
+	GalleryName string `json:"galleryName"`
+}
+
+// ListFailed returns a page of the failed photos of all the photographer's galleries, most
+// recent failure first, and the key of the next page. Photos and galleries in the trash are
+// left out, so a page can hold fewer photos than the limit.
+func (s *Service) ListFailed(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*FailedPhoto, map[string]interface{}, error) {
+	photos, nextKey, err := s.photoRepo.ListFailedByPhotographer(ctx, photographerID, limit, lastKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, 500, "Failed to list failed photos")
+	}
+
+	failed := []*FailedPhoto{}
+	galleries := map[string]*repository.Gallery{}
+	for _, photo := range photos {
+		if photo.DeletedAt != nil {
+			continue
+		}
+		g, seen := galleries[photo.GalleryID]
+		if !seen {
+			if g, err = s.galleryRepo.GetByID(ctx, photo.GalleryID); err != nil {
+				return nil, nil, errors.Wrap(err, 500, "Failed to get gallery")
+			}
+			galleries[photo.GalleryID] = g
+		}
+		if g == nil || g.Status == repository.GalleryStatusDeleted {
+			continue
+		}
+		failed = append(failed, &FailedPhoto{Photo: photo, GalleryName: g.Name})
+	}
+	return failed, nextKey, nil
+}
+
+// RetryFailed queues a failed photo to be processed again from its original. Its failure
+// history is kept until processing succeeds.
+func (s *Service) RetryFailed(ctx context.Context, photographerID, galleryID, photoID string) (*repository.Photo, error) {
+	gallery, err := s.getActive(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil || photo == nil || photo.GalleryID != gallery.GalleryID || photo.DeletedAt != nil {
+		return nil, errors.NewNotFound("Photo")
+	}
+	if !photo.IsFailed() {
+		return nil, errors.NewBadRequest("Only photos that failed processing can be retried")
+	}
+
+	if err := s.enqueue(ctx, photo); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to queue photo for processing")
+	}
+
+	logger.Info("Failed photo queued for retry", map[string]interface{}{
+		"photoId": photo.PhotoID, "galleryId": gallery.GalleryID,
+	})
+	return photo, nil
+}
@@ -0,0 +1,123 @@
+package reprocess
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func TestListFailed(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	ctx := context.Background()
+	earlier, later := time.Now().Add(-time.Hour), time.Now()
+
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", Name: "Wedding", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_2", Name: "Portraits", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_trashed", PhotographerID: "user_1", Status: repository.GalleryStatusDeleted})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_other", PhotographerID: "user_2", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_done", StatusCompleted)
+	addPhoto(photoRepo, "gal_1", "ph_corrupt", StatusFailed).ProcessedAt = &earlier
+	addPhoto(photoRepo, "gal_2", "ph_gave_up", statusFailedPermanent).ProcessedAt = &later
+	addPhoto(photoRepo, "gal_2", "ph_trashed", StatusFailed).DeletedAt = &later
+	addPhoto(photoRepo, "gal_trashed", "ph_in_trashed_gallery", StatusFailed)
+	addPhoto(photoRepo, "gal_other", "ph_not_mine", StatusFailed).PhotographerID = "user_2"
+	for _, id := range []string{"ph_done", "ph_corrupt", "ph_gave_up", "ph_trashed", "ph_in_trashed_gallery"} {
+		photo, _ := photoRepo.GetByID(ctx, id)
+		photo.PhotographerID = "user_1"
+	}
+
+	failed, nextKey, err := service.ListFailed(ctx, "user_1", 50, nil)
+	if err != nil {
+		t.Fatalf("ListFailed() error: %v", err)
+	}
+	if nextKey != nil {
+		t.Errorf("nextKey = %v, want none after the last page", nextKey)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("ListFailed() returned %d photos, want 2", len(failed))
+	}
+	if failed[0].PhotoID != "ph_gave_up" || failed[0].GalleryName != "Portraits" {
+		t.Errorf("first = %s in %q, want the most recent failure, ph_gave_up in Portraits", failed[0].PhotoID, failed[0].GalleryName)
+	}
+	if failed[1].PhotoID != "ph_corrupt" || failed[1].GalleryName != "Wedding" {
+		t.Errorf("second = %s in %q, want ph_corrupt in Wedding", failed[1].PhotoID, failed[1].GalleryName)
+	}
+}
+
+func TestListFailedPages(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	for i, id := range []string{"ph_1", "ph_2", "ph_3"} {
+		failedAt := time.Now().Add(-time.Duration(i) * time.Minute)
+		photo := addPhoto(photoRepo, "gal_1", id, StatusFailed)
+		photo.PhotographerID, photo.ProcessedAt = "user_1", &failedAt
+	}
+
+	var listed []string
+	var lastKey map[string]interface{}
+	for page := 1; ; page++ {
+		failed, nextKey, err := service.ListFailed(ctx, "user_1", 2, lastKey)
+		if err != nil {
+			t.Fatalf("ListFailed() error: %v", err)
+		}
+		for _, photo := range failed {
+			listed = append(listed, photo.PhotoID)
+		}
+		if nextKey == nil {
+			break
+		}
+		if page == 2 {
+			t.Fatal("ListFailed() should have run out of photos after two pages")
+		}
+		lastKey = nextKey
+	}
+	if strings.Join(listed, ",") != "ph_1,ph_2,ph_3" {
+		t.Errorf("listed = %v, want every failed photo once, most recent first", listed)
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	failure := &repository.ProcessingFailure{Category: "corrupt_file", Attempts: []repository.FailedAttempt{{Category: "corrupt_file"}}}
+	addPhoto(photoRepo, "gal_1", "ph_failed", statusFailedPermanent).Failure = failure
+	addPhoto(photoRepo, "gal_1", "ph_done", StatusCompleted)
+
+	photo, err := service.RetryFailed(ctx, "user_1", "gal_1", "ph_failed")
+	if err != nil {
+		t.Fatalf("RetryFailed() error: %v", err)
+	}
+	if photo.ProcessingStatus != StatusPending || photo.Failure != failure {
+		t.Errorf("status = %s, failure = %+v; want pending with its failure history kept", photo.ProcessingStatus, photo.Failure)
+	}
+	if queued := queue.Enqueued(); len(queued) != 1 || queued[0] != "gal_1/ph_failed/original.jpg" {
+		t.Errorf("queued = %v, want the original key", queued)
+	}
+
+	tests := []struct {
+		name    string
+		photoID string
+		code    int
+	}{
+		{"photo that didn't fail", "ph_done", 400},
+		{"photo being retried", "ph_failed", 400},
+		{"missing photo", "ph_missing", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RetryFailed(ctx, "user_1", "gal_1", tt.photoID)
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != tt.code {
+				t.Errorf("RetryFailed() error = %v, want %d", err, tt.code)
+			}
+		})
+	}
+	if _, err := service.RetryFailed(ctx, "user_2", "gal_1", "ph_failed"); err == nil {
+		t.Error("RetryFailed() should reject another photographer's gallery")
+	}
+}
@@ -0,0 +1,302 @@
+// Package reprocess sends processed photos back through the image processor, so derivatives
+// pick up watermark or rendition settings changed after the photos were uploaded.
+//
+// Galleries are reprocessed asynchronously: a request queues a gallery job, and the processor
+// works through the gallery a page of photos at a time with QueueGalleryPage, so large galleries
+// and accounts never hold up an API request.
+package reprocess
+
+import (
+	"context"
+	"fmt"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Processing statuses a reprocessed photo goes through
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+
+	// statusFailedPermanent is set by the DLQ reprocessor once retries are exhausted
+	statusFailedPermanent = "failed_permanent"
+)
+
+// pageSize is how many galleries or photos are read per page
+const pageSize = 100
+
+// Queue queues photos, and jobs that queue a gallery's photos a page at a time, for the image processor
+type Queue interface {
+	Enqueue(ctx context.Context, originalKey string) error
+	EnqueueGallery(ctx context.Context, galleryID string, cursor map[string]interface{}) error
+}
+
+// Service reprocesses photos, galleries and accounts
+type Service struct {
+	galleryRepo repository.GalleryRepository
+	photoRepo   repository.PhotoRepository
+	queue       Queue
+}
+
+// NewService creates a new reprocess service
+func NewService(galleryRepo repository.GalleryRepository, photoRepo repository.PhotoRepository, queue Queue) *Service {
+	return &Service{
+		galleryRepo: galleryRepo,
+		photoRepo:   photoRepo,
+		queue:       queue,
+	}
+}
+
+// Progress counts a gallery's photos by processing status. Reprocessing is done once no
+// photo is pending or processing.
+type Progress struct {
+	GalleryID  string `json:"galleryId"`
+	Queued     int    `json:"queued"` // photos the request that returned this progress is queueing
+	Pending    int    `json:"pending"`
+	Processing int    `json:"processing"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+	Done       bool   `json:"done"`
+}
+
+// AccountResult describes the galleries queued for reprocessing across a photographer's account
+type AccountResult struct {
+	Galleries int `json:"galleries"`
+}
+
+// ReprocessPhoto queues a single photo. Unlike gallery and account reprocessing it also
+// requeues photos that are still pending or processing, to unstick them.
+func (s *Service) ReprocessPhoto(ctx context.Context, photographerID, galleryID, photoID string) (*repository.Photo, error) {
+	gallery, err := s.getActive(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil || photo == nil || photo.GalleryID != gallery.GalleryID || photo.DeletedAt != nil {
+		return nil, errors.NewNotFound("Photo")
+	}
+	if !canReprocess(photo) && photo.ProcessingStatus != StatusPending && photo.ProcessingStatus != StatusProcessing {
+		return nil, errors.NewBadRequest("Photo cannot be reprocessed while its original is archived")
+	}
+
+	if err := s.enqueue(ctx, photo); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to queue photo for reprocessing")
+	}
+	return photo, nil
+}
+
+// ReprocessGallery queues a job to reprocess every processed or failed photo of a gallery.
+// The progress returned counts those photos as pending already, as the processor marks them
+// pending moments later.
+func (s *Service) ReprocessGallery(ctx context.Context, photographerID, galleryID string) (*Progress, error) {
+	gallery, err := s.getActive(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &Progress{GalleryID: gallery.GalleryID}
+	err = s.eachPhoto(ctx, gallery.GalleryID, func(photo *repository.Photo) {
+		if canReprocess(photo) {
+			progress.Queued++
+			progress.Pending++
+			return
+		}
+		progress.count(photo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if progress.Queued > 0 {
+		if err := s.queue.EnqueueGallery(ctx, gallery.GalleryID, nil); err != nil {
+			return nil, errors.Wrap(err, 500, "Failed to queue gallery for reprocessing")
+		}
+	}
+	progress.Done = progress.Pending == 0 && progress.Processing == 0
+
+	logger.Info("Gallery queued for reprocessing", map[string]interface{}{
+		"galleryId": gallery.GalleryID, "photos": progress.Queued,
+	})
+	return progress, nil
+}
+
+// ReprocessAccount queues the photos of all the photographer's active galleries
+func (s *Service) ReprocessAccount(ctx context.Context, photographerID string) (*AccountResult, error) {
+	queued, err := s.ReprocessGalleries(ctx, photographerID, func(*repository.Gallery) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return &AccountResult{Galleries: queued}, nil
+}
+
+// ReprocessGalleries queues a job for each of the photographer's active galleries that match,
+// returning how many galleries were queued. Archived and restoring galleries are skipped; they
+// are processed again when restored.
+func (s *Service) ReprocessGalleries(ctx context.Context, photographerID string, match func(*repository.Gallery) bool) (int, error) {
+	queued := 0
+	var lastKey map[string]interface{}
+	for {
+		galleries, nextKey, err := s.galleryRepo.ListByPhotographer(ctx, photographerID, pageSize, lastKey)
+		if err != nil {
+			return queued, errors.Wrap(err, 500, "Failed to list galleries")
+		}
+		for _, g := range galleries {
+			if g.Status != repository.GalleryStatusActive || !match(g) {
+				continue
+			}
+			if err := s.queue.EnqueueGallery(ctx, g.GalleryID, nil); err != nil {
+				return queued, errors.Wrap(err, 500, "Failed to queue gallery for reprocessing")
+			}
+			queued++
+		}
+		if nextKey == nil {
+			break
+		}
+		lastKey = nextKey
+	}
+
+	logger.Info("Galleries queued for reprocessing", map[string]interface{}{
+		"photographerId": photographerID, "galleries": queued,
+	})
+	return queued, nil
+}
+
+// QueueGalleryPage queues the processed and failed photos of one page of a gallery, starting
+// at cursor, then queues a job for the next page. The processor calls it for each gallery job.
+// Queued photos are marked pending, so retrying a page that failed partway skips them. It
+// returns how many photos were queued; galleries archived or deleted since are skipped.
+func (s *Service) QueueGalleryPage(ctx context.Context, galleryID string, cursor map[string]interface{}) (int, error) {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return 0, fmt.Errorf("get gallery: %w", err)
+	}
+	if gallery == nil || gallery.Status != repository.GalleryStatusActive {
+		return 0, nil
+	}
+
+	photos, nextKey, err := s.photoRepo.ListByGallery(ctx, galleryID, pageSize, cursor)
+	if err != nil {
+		return 0, fmt.Errorf("list photos: %w", err)
+	}
+	queued := 0
+	for _, photo := range photos {
+		if photo.DeletedAt != nil || !canReprocess(photo) {
+			continue
+		}
+		if err := s.enqueue(ctx, photo); err != nil {
+			return queued, fmt.Errorf("queue photo %s: %w", photo.PhotoID, err)
+		}
+		queued++
+	}
+
+	if nextKey != nil {
+		if err := s.queue.EnqueueGallery(ctx, galleryID, nextKey); err != nil {
+			return queued, fmt.Errorf("queue next page: %w", err)
+		}
+	}
+	return queued, nil
+}
+
+// GalleryProgress reports how far reprocessing a gallery has got
+func (s *Service) GalleryProgress(ctx context.Context, photographerID, galleryID string) (*Progress, error) {
+	gallery, err := s.getOwned(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &Progress{GalleryID: gallery.GalleryID}
+	if err := s.eachPhoto(ctx, gallery.GalleryID, progress.count); err != nil {
+		return nil, err
+	}
+	progress.Done = progress.Pending == 0 && progress.Processing == 0
+	return progress, nil
+}
+
+// enqueue marks a photo pending and queues it. The photo is marked first so the processor's
+// result isn't overwritten; if queueing fails, its previous status is put back.
+func (s *Service) enqueue(ctx context.Context, photo *repository.Photo) error {
+	previous := photo.ProcessingStatus
+	photo.ProcessingStatus = StatusPending
+	if err := s.photoRepo.Update(ctx, photo); err != nil {
+		photo.ProcessingStatus = previous
+		return err
+	}
+	if err := s.queue.Enqueue(ctx, photo.OriginalKey); err != nil {
+		photo.ProcessingStatus = previous
+		if rollbackErr := s.photoRepo.Update(ctx, photo); rollbackErr != nil {
+			logger.Warn("Failed to restore photo status", map[string]interface{}{
+				"photoId": photo.PhotoID, "error": rollbackErr.Error(),
+			})
+		}
+		return err
+	}
+	return nil
+}
+
+// eachPhoto calls fn for every photo of a gallery that isn't in the trash
+func (s *Service) eachPhoto(ctx context.Context, galleryID string, fn func(*repository.Photo)) error {
+	var lastKey map[string]interface{}
+	for {
+		photos, nextKey, err := s.photoRepo.ListByGallery(ctx, galleryID, pageSize, lastKey)
+		if err != nil {
+			return errors.Wrap(err, 500, "Failed to list photos")
+		}
+		for _, photo := range photos {
+			if photo.DeletedAt == nil {
+				fn(photo)
+			}
+		}
+		if nextKey == nil {
+			return nil
+		}
+		lastKey = nextKey
+	}
+}
+
+// getOwned retrieves a gallery owned by the photographer. Galleries of other photographers and
+// galleries in the trash are reported as not found.
+func (s *Service) getOwned(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil || gallery == nil || gallery.PhotographerID != photographerID || gallery.Status == repository.GalleryStatusDeleted {
+		return nil, errors.NewNotFound("Gallery")
+	}
+	return gallery, nil
+}
+
+// getActive retrieves an owned gallery whose originals can be processed
+func (s *Service) getActive(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error) {
+	gallery, err := s.getOwned(ctx, photographerID, galleryID)
+	if err != nil {
+		return nil, err
+	}
+	if gallery.Status == repository.GalleryStatusArchived || gallery.Status == repository.GalleryStatusRestoring {
+		return nil, errors.NewBadRequest("Archived galleries are reprocessed when restored")
+	}
+	return gallery, nil
+}
+
+// canReprocess reports whether a photo has finished processing, successfully or not, and its
+// original is readable
+func canReprocess(photo *repository.Photo) bool {
+	switch photo.ProcessingStatus {
+	case StatusCompleted, StatusFailed, statusFailedPermanent:
+		return true
+	}
+	return false
+}
+
+func (p *Progress) count(photo *repository.Photo) {
+	switch photo.ProcessingStatus {
+	case StatusPending:
+		p.Pending++
+	case StatusProcessing:
+		p.Processing++
+	case StatusCompleted:
+		p.Completed++
+	case StatusFailed, statusFailedPermanent:
+		p.Failed++
+	}
+}
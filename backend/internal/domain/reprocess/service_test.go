@@ -0,0 +1,272 @@
+package reprocess
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+func newTestService() (*Service, *mocks.MockGalleryRepository, *mocks.MockPhotoRepository, *mocks.MockProcessingQueue) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	queue := mocks.NewMockProcessingQueue()
+	return NewService(galleryRepo, photoRepo, queue), galleryRepo, photoRepo, queue
+}
+
+func addPhoto(photoRepo *mocks.MockPhotoRepository, galleryID, photoID, status string) *repository.Photo {
+	photo := &repository.Photo{
+		PhotoID:          photoID,
+		GalleryID:        galleryID,
+		OriginalKey:      fmt.Sprintf("%s/%s/original.jpg", galleryID, photoID),
+		ProcessingStatus: status,
+	}
+	photoRepo.AddPhoto(photo)
+	return photo
+}
+
+func TestReprocessGallery(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	ctx := context.Background()
+	now := time.Now()
+
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_done", StatusCompleted)
+	addPhoto(photoRepo, "gal_1", "ph_failed", StatusFailed)
+	addPhoto(photoRepo, "gal_1", "ph_busy", StatusProcessing)
+	addPhoto(photoRepo, "gal_1", "ph_trashed", StatusCompleted).DeletedAt = &now
+
+	progress, err := service.ReprocessGallery(ctx, "user_1", "gal_1")
+	if err != nil {
+		t.Fatalf("ReprocessGallery() error: %v", err)
+	}
+	want := Progress{GalleryID: "gal_1", Queued: 2, Pending: 2, Processing: 1}
+	if *progress != want {
+		t.Errorf("progress = %+v, want %+v", *progress, want)
+	}
+
+	// Photos are left to the processor, which works through the gallery job
+	if jobs := queue.GalleryJobs(); len(jobs) != 1 || jobs[0].GalleryID != "gal_1" || jobs[0].Cursor != nil {
+		t.Errorf("gallery jobs = %+v, want one job for gal_1 from the first page", jobs)
+	}
+	if len(queue.Enqueued()) != 0 {
+		t.Errorf("queued = %v, want no photos queued by the request", queue.Enqueued())
+	}
+	if photo, _ := photoRepo.GetByID(ctx, "ph_done"); photo.ProcessingStatus != StatusCompleted {
+		t.Errorf("status = %s, want completed until the job runs", photo.ProcessingStatus)
+	}
+}
+
+func TestReprocessGalleryWithNothingToQueue(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_busy", StatusProcessing)
+
+	progress, err := service.ReprocessGallery(context.Background(), "user_1", "gal_1")
+	if err != nil {
+		t.Fatalf("ReprocessGallery() error: %v", err)
+	}
+	if progress.Queued != 0 || len(queue.GalleryJobs()) != 0 {
+		t.Errorf("progress = %+v, jobs = %v, want nothing queued", *progress, queue.GalleryJobs())
+	}
+}
+
+// pagedPhotoRepository returns a gallery's photos one page at a time
+type pagedPhotoRepository struct {
+	*mocks.MockPhotoRepository
+	pages [][]*repository.Photo
+}
+
+func (r *pagedPhotoRepository) ListByGallery(ctx context.Context, galleryID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	page := 0
+	if lastKey != nil {
+		page = lastKey["page"].(int)
+	}
+	if page+1 < len(r.pages) {
+		return r.pages[page], map[string]interface{}{"page": page + 1}, nil
+	}
+	return r.pages[page], nil, nil
+}
+
+func TestQueueGalleryPage(t *testing.T) {
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := &pagedPhotoRepository{MockPhotoRepository: mocks.NewMockPhotoRepository()}
+	queue := mocks.NewMockProcessingQueue()
+	service := NewService(galleryRepo, photoRepo, queue)
+	ctx := context.Background()
+	now := time.Now()
+
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	done := addPhoto(photoRepo.MockPhotoRepository, "gal_1", "ph_done", StatusCompleted)
+	failed := addPhoto(photoRepo.MockPhotoRepository, "gal_1", "ph_failed", StatusFailed)
+	busy := addPhoto(photoRepo.MockPhotoRepository, "gal_1", "ph_busy", StatusProcessing)
+	trashed := addPhoto(photoRepo.MockPhotoRepository, "gal_1", "ph_trashed", StatusCompleted)
+	trashed.DeletedAt = &now
+	last := addPhoto(photoRepo.MockPhotoRepository, "gal_1", "ph_last", StatusCompleted)
+	photoRepo.pages = [][]*repository.Photo{{done, failed, busy, trashed}, {last}}
+
+	queued, err := service.QueueGalleryPage(ctx, "gal_1", nil)
+	if err != nil {
+		t.Fatalf("QueueGalleryPage() error: %v", err)
+	}
+	if queued != 2 {
+		t.Errorf("QueueGalleryPage() = %d, want 2", queued)
+	}
+	enqueued := queue.Enqueued()
+	sort.Strings(enqueued)
+	if len(enqueued) != 2 || enqueued[0] != "gal_1/ph_done/original.jpg" || enqueued[1] != "gal_1/ph_failed/original.jpg" {
+		t.Errorf("queued = %v, want the completed and failed photos of the first page", enqueued)
+	}
+	if done.ProcessingStatus != StatusPending {
+		t.Errorf("status = %s, want pending", done.ProcessingStatus)
+	}
+	jobs := queue.GalleryJobs()
+	if len(jobs) != 1 || jobs[0].GalleryID != "gal_1" || jobs[0].Cursor == nil {
+		t.Fatalf("gallery jobs = %+v, want a job for the next page", jobs)
+	}
+
+	// A retried page skips the photos it already queued
+	if queued, err := service.QueueGalleryPage(ctx, "gal_1", nil); err != nil || queued != 0 {
+		t.Errorf("retried QueueGalleryPage() = %d, %v, want 0", queued, err)
+	}
+
+	if queued, err := service.QueueGalleryPage(ctx, "gal_1", jobs[0].Cursor); err != nil || queued != 1 {
+		t.Errorf("QueueGalleryPage() for the last page = %d, %v, want 1", queued, err)
+	}
+	if len(queue.GalleryJobs()) != 2 {
+		t.Errorf("gallery jobs = %+v, want no job after the last page", queue.GalleryJobs())
+	}
+}
+
+func TestQueueGalleryPageSkipsInactiveGalleries(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_archived", PhotographerID: "user_1", Status: repository.GalleryStatusArchived})
+	addPhoto(photoRepo, "gal_archived", "ph_1", StatusCompleted)
+
+	for _, galleryID := range []string{"gal_archived", "gal_missing"} {
+		if queued, err := service.QueueGalleryPage(context.Background(), galleryID, nil); err != nil || queued != 0 {
+			t.Errorf("QueueGalleryPage(%s) = %d, %v, want 0", galleryID, queued, err)
+		}
+	}
+	if len(queue.Enqueued()) != 0 {
+		t.Errorf("queued = %v, want none", queue.Enqueued())
+	}
+}
+
+func TestReprocessGalleryAccess(t *testing.T) {
+	service, galleryRepo, _, _ := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_archived", PhotographerID: "user_1", Status: repository.GalleryStatusArchived})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_trashed", PhotographerID: "user_1", Status: repository.GalleryStatusDeleted})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_other", PhotographerID: "user_2", Status: repository.GalleryStatusActive})
+
+	tests := []struct {
+		galleryID string
+		code      int
+	}{
+		{"gal_missing", 404},
+		{"gal_other", 404},
+		{"gal_trashed", 404},
+		{"gal_archived", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.galleryID, func(t *testing.T) {
+			_, err := service.ReprocessGallery(ctx, "user_1", tt.galleryID)
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != tt.code {
+				t.Errorf("ReprocessGallery() error = %v, want %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestReprocessPhoto(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_2", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_stuck", StatusProcessing)
+	addPhoto(photoRepo, "gal_1", "ph_archived", "archived")
+	addPhoto(photoRepo, "gal_2", "ph_elsewhere", StatusCompleted)
+
+	photo, err := service.ReprocessPhoto(ctx, "user_1", "gal_1", "ph_stuck")
+	if err != nil {
+		t.Fatalf("ReprocessPhoto() error: %v", err)
+	}
+	if photo.ProcessingStatus != StatusPending || len(queue.Enqueued()) != 1 {
+		t.Errorf("stuck photo should be requeued, status = %s, queued = %v", photo.ProcessingStatus, queue.Enqueued())
+	}
+
+	if _, err := service.ReprocessPhoto(ctx, "user_1", "gal_1", "ph_archived"); err == nil {
+		t.Error("ReprocessPhoto() should reject an archived photo")
+	}
+	if _, err := service.ReprocessPhoto(ctx, "user_1", "gal_1", "ph_elsewhere"); err == nil {
+		t.Error("ReprocessPhoto() should reject a photo from another gallery")
+	}
+}
+
+func TestReprocessRestoresStatusWhenQueueFails(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_1", StatusCompleted)
+	queue.EnqueueErr = fmt.Errorf("queue unavailable")
+
+	if _, err := service.ReprocessPhoto(ctx, "user_1", "gal_1", "ph_1"); err == nil {
+		t.Fatal("ReprocessPhoto() should fail when the photo can't be queued")
+	}
+	if photo, _ := photoRepo.GetByID(ctx, "ph_1"); photo.ProcessingStatus != StatusCompleted {
+		t.Errorf("status = %s, want completed", photo.ProcessingStatus)
+	}
+}
+
+func TestReprocessAccount(t *testing.T) {
+	service, galleryRepo, photoRepo, queue := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_2", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_archived", PhotographerID: "user_1", Status: repository.GalleryStatusArchived})
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_other", PhotographerID: "user_2", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_1", StatusCompleted)
+	addPhoto(photoRepo, "gal_2", "ph_2", StatusCompleted)
+	addPhoto(photoRepo, "gal_archived", "ph_3", "archived")
+	addPhoto(photoRepo, "gal_other", "ph_4", StatusCompleted)
+
+	result, err := service.ReprocessAccount(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ReprocessAccount() error: %v", err)
+	}
+	if result.Galleries != 2 {
+		t.Errorf("result = %+v, want 2 galleries", *result)
+	}
+	jobs := queue.GalleryJobs()
+	if len(jobs) != 2 || len(queue.Enqueued()) != 0 {
+		t.Errorf("gallery jobs = %+v, queued = %v, want one job per active gallery and no photos", jobs, queue.Enqueued())
+	}
+}
+
+func TestGalleryProgress(t *testing.T) {
+	service, galleryRepo, photoRepo, _ := newTestService()
+	ctx := context.Background()
+	galleryRepo.AddGallery(&repository.Gallery{GalleryID: "gal_1", PhotographerID: "user_1", Status: repository.GalleryStatusActive})
+	addPhoto(photoRepo, "gal_1", "ph_1", StatusCompleted)
+	busy := addPhoto(photoRepo, "gal_1", "ph_2", StatusPending)
+
+	progress, err := service.GalleryProgress(ctx, "user_1", "gal_1")
+	if err != nil {
+		t.Fatalf("GalleryProgress() error: %v", err)
+	}
+	if progress.Done || progress.Pending != 1 || progress.Completed != 1 {
+		t.Errorf("progress = %+v, want one pending and one completed", *progress)
+	}
+
+	busy.ProcessingStatus = StatusFailed
+	progress, _ = service.GalleryProgress(ctx, "user_1", "gal_1")
+	if !progress.Done || progress.Failed != 1 {
+		t.Errorf("progress = %+v, want done with one failure", *progress)
+	}
+}
@@ -0,0 +1,122 @@
+// Package trash lists, restores and purges the galleries and photos photographers delete.
+// The gallery and photo services move items into the trash; this service works across both.
+package trash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// GalleryTrash restores and purges galleries in the trash.
+type GalleryTrash interface {
+	RestoreDeleted(ctx context.Context, photographerID, galleryID string) (*repository.Gallery, error)
+	PurgeDeleted(ctx context.Context, galleryID string) error
+}
+
+// PhotoTrash restores and purges photos in the trash.
+type PhotoTrash interface {
+	RestoreDeleted(ctx context.Context, photographerID, photoID string) (*repository.Photo, error)
+	PurgeDeleted(ctx context.Context, photoID string) error
+}
+
+// Service handles a photographer's trash.
+type Service struct {
+	trashRepo repository.TrashRepository
+	galleries GalleryTrash
+	photos    PhotoTrash
+	now       func() time.Time
+}
+
+// NewService creates a new trash service.
+func NewService(trashRepo repository.TrashRepository, galleries GalleryTrash, photos PhotoTrash) *Service {
+	return &Service{trashRepo: trashRepo, galleries: galleries, photos: photos, now: time.Now}
+}
+
+// List returns the photographer's trash, most recently deleted first.
+func (s *Service) List(ctx context.Context, photographerID string) ([]*repository.TrashItem, error) {
+	items, err := s.trashRepo.ListByPhotographer(ctx, photographerID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list trash")
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore takes an item out of the photographer's trash.
+func (s *Service) Restore(ctx context.Context, photographerID, itemID string) (*repository.TrashItem, error) {
+	item, err := s.trashRepo.GetByID(ctx, photographerID, itemID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get trash item")
+	}
+	if item == nil {
+		return nil, errors.NewNotFound("Trash item")
+	}
+
+	switch item.ItemType {
+	case repository.TrashItemGallery:
+		_, err = s.galleries.RestoreDeleted(ctx, photographerID, item.ItemID)
+	case repository.TrashItemPhoto:
+		_, err = s.photos.RestoreDeleted(ctx, photographerID, item.ItemID)
+	default:
+		err = errors.NewInternalServer("Unknown trash item type")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.trashRepo.Delete(ctx, photographerID, itemID); err != nil {
+		// The item is restored; a stale entry is dropped by the next purge
+		logger.Error("Failed to remove restored item from trash", map[string]interface{}{
+			"itemId": itemID, "error": err.Error(),
+		})
+	}
+	return item, nil
+}
+
+// PurgeExpired permanently deletes up to limit items whose retention has elapsed.
+// It returns how many items were purged.
+func (s *Service) PurgeExpired(ctx context.Context, limit int) (int, error) {
+	items, err := s.trashRepo.ListDue(ctx, s.now(), limit)
+	if err != nil {
+		return 0, errors.Wrap(err, 500, "Failed to list expired trash")
+	}
+
+	var purged, errorCount int
+	for _, item := range items {
+		if err := s.purge(ctx, item); err != nil {
+			logger.Error("Failed to purge trash item", map[string]interface{}{
+				"itemId": item.ItemID, "itemType": item.ItemType, "error": err.Error(),
+			})
+			errorCount++
+			continue
+		}
+		purged++
+	}
+
+	if errorCount > 0 {
+		return purged, fmt.Errorf("completed with %d errors out of %d items", errorCount, len(items))
+	}
+	return purged, nil
+}
+
+func (s *Service) purge(ctx context.Context, item *repository.TrashItem) error {
+	var err error
+	switch item.ItemType {
+	case repository.TrashItemGallery:
+		err = s.galleries.PurgeDeleted(ctx, item.ItemID)
+	case repository.TrashItemPhoto:
+		err = s.photos.PurgeDeleted(ctx, item.ItemID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.trashRepo.Delete(ctx, item.PhotographerID, item.ItemID)
+}
@@ -0,0 +1,365 @@
+package trash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/gallery"
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+const retention = 24 * time.Hour
+
+// newTestService wires the trash service to gallery and photo services sharing its repositories
+func newTestService(trashRepo *mocks.MockTrashRepository, galleryRepo *mocks.MockGalleryRepository, photoRepo *mocks.MockPhotoRepository) (*Service, *gallery.Service, *photo.Service) {
+	galleryService := gallery.NewService(galleryRepo, photoRepo, mocks.NewMockStorageService(), mocks.NewMockProcessingQueue()).
+		WithTrash(trashRepo, retention)
+	photoService := photo.NewService(photoRepo, galleryRepo, mocks.NewMockFavoriteRepository(), mocks.NewMockSelectionRepository(), nil).
+		WithTrash(trashRepo, retention)
+	return NewService(trashRepo, galleryService, photoService), galleryService, photoService
+}
+
+// addGallery stores a gallery of user_owner with two photos whose counters match its photos
+func addGallery(galleryRepo *mocks.MockGalleryRepository, photoRepo *mocks.MockPhotoRepository) (*repository.Gallery, []*repository.Photo) {
+	photos := fixtures.NewPhotoList(2, "")
+	g := fixtures.NewGallery(fixtures.GalleryOptions{
+		PhotographerID: "user_owner",
+		Status:         repository.GalleryStatusActive,
+		PhotoCount:     len(photos),
+		TotalSize:      photos[0].Size + photos[1].Size,
+	})
+	galleryRepo.AddGallery(g)
+	for _, p := range photos {
+		p.GalleryID = g.GalleryID
+		photoRepo.AddPhoto(p)
+	}
+	return g, photos
+}
+
+func storedGallery(t *testing.T, galleryRepo *mocks.MockGalleryRepository, galleryID string) *repository.Gallery {
+	t.Helper()
+	g, err := galleryRepo.GetByID(context.Background(), galleryID)
+	if err != nil || g == nil {
+		t.Fatalf("GetByID() = %v, %v", g, err)
+	}
+	return g
+}
+
+func listTrash(t *testing.T, service *Service) []*repository.TrashItem {
+	t.Helper()
+	items, err := service.List(context.Background(), "user_owner")
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	return items
+}
+
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != code {
+		t.Errorf("error code = %d, want %d", appErr.Code, code)
+	}
+}
+
+func TestDeletePhotoMovesToTrash(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, _, photoService := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, photos := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+	p := photos[0]
+
+	if err := photoService.Delete(ctx, "user_owner", p.PhotoID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	_, err := photoService.GetByID(ctx, p.PhotoID)
+	assertErrorCode(t, err, 404)
+
+	listed, _, err := photoService.ListByGallery(ctx, gal.GalleryID, 50, nil)
+	if err != nil {
+		t.Fatalf("ListByGallery() error: %v", err)
+	}
+	if len(listed) != 1 || listed[0].PhotoID != photos[1].PhotoID {
+		t.Errorf("ListByGallery() should only return the remaining photo, got %d photos", len(listed))
+	}
+
+	g := storedGallery(t, galleryRepo, gal.GalleryID)
+	if g.PhotoCount != 1 || g.TotalSize != photos[1].Size {
+		t.Errorf("gallery counters = %d photos, %d bytes; want 1, %d", g.PhotoCount, g.TotalSize, photos[1].Size)
+	}
+
+	items := listTrash(t, service)
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 trash item, got %d", len(items))
+	}
+	item := items[0]
+	if item.ItemType != repository.TrashItemPhoto || item.ItemID != p.PhotoID || item.GalleryID != gal.GalleryID {
+		t.Errorf("unexpected trash item %+v", item)
+	}
+	if got := item.PurgeAt.Sub(item.DeletedAt); got != retention {
+		t.Errorf("PurgeAt - DeletedAt = %v, want %v", got, retention)
+	}
+}
+
+func TestRestorePhotoFromTrash(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, _, photoService := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, photos := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+	p := photos[0]
+
+	if _, err := photoService.ToggleFavorite(ctx, gal.GalleryID, "session_1", "", p.PhotoID); err != nil {
+		t.Fatalf("ToggleFavorite() error: %v", err)
+	}
+
+	if err := photoService.Delete(ctx, "user_owner", p.PhotoID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	item, err := service.Restore(ctx, "user_owner", p.PhotoID)
+	if err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if item.ItemID != p.PhotoID {
+		t.Errorf("Restore() returned item %q, want %q", item.ItemID, p.PhotoID)
+	}
+
+	if _, err := photoService.GetByID(ctx, p.PhotoID); err != nil {
+		t.Errorf("GetByID() after restore error: %v", err)
+	}
+	g := storedGallery(t, galleryRepo, gal.GalleryID)
+	if g.PhotoCount != 2 || g.TotalSize != gal.TotalSize {
+		t.Errorf("gallery counters = %d photos, %d bytes; want 2, %d", g.PhotoCount, g.TotalSize, gal.TotalSize)
+	}
+	if items := listTrash(t, service); len(items) != 0 {
+		t.Errorf("Expected empty trash after restore, got %d items", len(items))
+	}
+
+	favorites, err := photoService.ListFavoritesBySession(ctx, gal.GalleryID, "session_1")
+	if err != nil {
+		t.Fatalf("ListFavoritesBySession() error: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].PhotoID != p.PhotoID {
+		t.Errorf("favorites should survive the trash, got %d", len(favorites))
+	}
+}
+
+func TestDeleteGalleryMovesToTrash(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, galleryService, _ := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, photos := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	if err := galleryService.Delete(ctx, "user_owner", gal.GalleryID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	g := storedGallery(t, galleryRepo, gal.GalleryID)
+	if g.Status != repository.GalleryStatusDeleted || g.DeletedAt == nil {
+		t.Errorf("gallery status = %q, deletedAt = %v; want deleted", g.Status, g.DeletedAt)
+	}
+	if g.PhotoCount != 2 {
+		t.Errorf("PhotoCount = %d, want counters left untouched", g.PhotoCount)
+	}
+	for _, p := range photos {
+		if stored, _ := photoRepo.GetByID(ctx, p.PhotoID); stored == nil {
+			t.Errorf("photo %s should be kept while its gallery is in the trash", p.PhotoID)
+		}
+	}
+
+	_, err := galleryService.GetForPhotographer(ctx, "user_owner", gal.GalleryID)
+	assertErrorCode(t, err, 404)
+	assertErrorCode(t, galleryService.CheckClientAccess(ctx, gal.GalleryID), 404)
+
+	galleries, _, err := galleryService.ListByPhotographer(ctx, "user_owner", 50, nil)
+	if err != nil {
+		t.Fatalf("ListByPhotographer() error: %v", err)
+	}
+	if len(galleries) != 0 {
+		t.Errorf("ListByPhotographer() returned %d galleries, want trashed gallery hidden", len(galleries))
+	}
+
+	items := listTrash(t, service)
+	if len(items) != 1 || items[0].ItemType != repository.TrashItemGallery || items[0].PhotoCount != 2 {
+		t.Errorf("unexpected trash contents %+v", items)
+	}
+}
+
+func TestRestoreGalleryFromTrash(t *testing.T) {
+	tests := []struct {
+		name       string
+		archivedAt *time.Time
+		status     string
+		want       string
+	}{
+		{"active", nil, repository.GalleryStatusActive, repository.GalleryStatusActive},
+		{"archived", func() *time.Time { t := time.Now().Add(-time.Hour); return &t }(), repository.GalleryStatusArchived, repository.GalleryStatusArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trashRepo := mocks.NewMockTrashRepository()
+			galleryRepo := mocks.NewMockGalleryRepository()
+			photoRepo := mocks.NewMockPhotoRepository()
+			service, galleryService, _ := newTestService(trashRepo, galleryRepo, photoRepo)
+			gal, _ := addGallery(galleryRepo, photoRepo)
+			ctx := context.Background()
+			gal.Status = tt.status
+			gal.ArchivedAt = tt.archivedAt
+
+			if err := galleryService.Delete(ctx, "user_owner", gal.GalleryID); err != nil {
+				t.Fatalf("Delete() error: %v", err)
+			}
+			if _, err := service.Restore(ctx, "user_owner", gal.GalleryID); err != nil {
+				t.Fatalf("Restore() error: %v", err)
+			}
+
+			g := storedGallery(t, galleryRepo, gal.GalleryID)
+			if g.Status != tt.want {
+				t.Errorf("status = %q, want %q", g.Status, tt.want)
+			}
+			if g.DeletedAt != nil {
+				t.Error("DeletedAt should be cleared on restore")
+			}
+			if len(listTrash(t, service)) != 0 {
+				t.Error("Expected empty trash after restore")
+			}
+		})
+	}
+}
+
+func TestRestorePhotoInTrashedGallery(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, galleryService, photoService := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, photos := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+	p := photos[0]
+
+	if err := photoService.Delete(ctx, "user_owner", p.PhotoID); err != nil {
+		t.Fatalf("Delete() photo error: %v", err)
+	}
+	if err := galleryService.Delete(ctx, "user_owner", gal.GalleryID); err != nil {
+		t.Fatalf("Delete() gallery error: %v", err)
+	}
+
+	_, err := service.Restore(ctx, "user_owner", p.PhotoID)
+	assertErrorCode(t, err, 409)
+
+	if item, _ := trashRepo.GetByID(ctx, "user_owner", p.PhotoID); item == nil {
+		t.Error("photo should stay in the trash when its restore is rejected")
+	}
+}
+
+func TestRestoreNotOwner(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, galleryService, _ := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, _ := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	if err := galleryService.Delete(ctx, "user_owner", gal.GalleryID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	_, err := service.Restore(ctx, "user_other", gal.GalleryID)
+	assertErrorCode(t, err, 404)
+
+	if g := storedGallery(t, galleryRepo, gal.GalleryID); g.Status != repository.GalleryStatusDeleted {
+		t.Errorf("status = %q, a non-owner must not restore the gallery", g.Status)
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, galleryService, photoService := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, photos := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	other := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_owner", Status: repository.GalleryStatusActive, PhotoCount: 1})
+	galleryRepo.AddGallery(other)
+	kept := fixtures.NewPhoto(fixtures.PhotoOptions{GalleryID: other.GalleryID})
+	photoRepo.AddPhoto(kept)
+
+	if err := galleryService.Delete(ctx, "user_owner", gal.GalleryID); err != nil {
+		t.Fatalf("Delete() gallery error: %v", err)
+	}
+	// Deleted after the gallery with a later purge time, so it is not due yet
+	photoService.WithTrash(trashRepo, 3*retention)
+	if err := photoService.Delete(ctx, "user_owner", kept.PhotoID); err != nil {
+		t.Fatalf("Delete() photo error: %v", err)
+	}
+
+	service.now = func() time.Time { return time.Now().Add(2 * retention) }
+	purged, err := service.PurgeExpired(ctx, 100)
+	if err != nil {
+		t.Fatalf("PurgeExpired() error: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged = %d, want 1", purged)
+	}
+
+	if g, _ := galleryRepo.GetByID(ctx, gal.GalleryID); g != nil {
+		t.Error("expired gallery should be purged")
+	}
+	for _, p := range photos {
+		if stored, _ := photoRepo.GetByID(ctx, p.PhotoID); stored != nil {
+			t.Errorf("photo %s should be purged with its gallery", p.PhotoID)
+		}
+	}
+	if stored, _ := photoRepo.GetByID(ctx, kept.PhotoID); stored == nil {
+		t.Error("photo within retention should not be purged")
+	}
+
+	items := listTrash(t, service)
+	if len(items) != 1 || items[0].ItemID != kept.PhotoID {
+		t.Errorf("Expected only the unexpired photo in the trash, got %+v", items)
+	}
+}
+
+func TestPurgeSkipsRestoredItems(t *testing.T) {
+	trashRepo := mocks.NewMockTrashRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	photoRepo := mocks.NewMockPhotoRepository()
+	service, _, _ := newTestService(trashRepo, galleryRepo, photoRepo)
+	gal, _ := addGallery(galleryRepo, photoRepo)
+	ctx := context.Background()
+
+	// A stale entry left behind after its gallery was restored
+	trashRepo.Create(ctx, &repository.TrashItem{
+		PhotographerID: "user_owner",
+		ItemID:         gal.GalleryID,
+		ItemType:       repository.TrashItemGallery,
+		DeletedAt:      time.Now().Add(-2 * retention),
+		PurgeAt:        time.Now().Add(-retention),
+	})
+
+	if _, err := service.PurgeExpired(ctx, 100); err != nil {
+		t.Fatalf("PurgeExpired() error: %v", err)
+	}
+
+	if g := storedGallery(t, galleryRepo, gal.GalleryID); g.Status != repository.GalleryStatusActive {
+		t.Errorf("status = %q, restored gallery must not be purged", g.Status)
+	}
+	if len(listTrash(t, service)) != 0 {
+		t.Error("stale trash entry should be removed")
+	}
+}
@@ -0,0 +1,207 @@
+// Package watermark manages photographers' logo watermarks and decides how photos of a gallery
+// are watermarked.
+package watermark
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
+)
+
+const (
+	// MaxLogoSize is the largest logo accepted, in bytes
+	MaxLogoSize = 2 << 20
+
+	// MaxLogoPixels is the largest logo accepted, in pixels. A PNG well under MaxLogoSize can
+	// still decode to gigabytes, so dimensions are checked before a logo is decoded.
+	MaxLogoPixels = 4096 * 4096
+
+	// LogoMimeType is the only logo format accepted, as logos need transparency
+	LogoMimeType = "image/png"
+
+	// logoPrefix is where logos are stored in the originals bucket. Photo processing is only
+	// triggered for gallery keys, so logo uploads aren't mistaken for photos.
+	logoPrefix = "watermarks/"
+)
+
+// Accounts reads and updates photographers' watermark logos
+type Accounts interface {
+	GetByID(ctx context.Context, userID string) (*photographer.Photographer, error)
+	SetWatermarkLogo(ctx context.Context, userID, key string) error
+}
+
+// LogoStorage stores watermark logos
+type LogoStorage interface {
+	GenerateLogoUploadURL(ctx context.Context, key, mimeType string) (string, error)
+	LogoSize(ctx context.Context, key string) (int64, error)
+	OpenLogo(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteLogo(ctx context.Context, key string) error
+}
+
+// Reprocessor queues the photos of matching galleries to go back through the image processor
+type Reprocessor interface {
+	ReprocessGalleries(ctx context.Context, photographerID string, match func(*repository.Gallery) bool) (int, error)
+}
+
+// Service handles photographers' watermark logos
+type Service struct {
+	accounts    Accounts
+	storage     LogoStorage
+	reprocessor Reprocessor
+	plans       *plan.Service
+}
+
+// NewService creates a new watermark service
+func NewService(accounts Accounts, storage LogoStorage, reprocessor Reprocessor) *Service {
+	return &Service{
+		accounts:    accounts,
+		storage:     storage,
+		reprocessor: reprocessor,
+	}
+}
+
+// WithPlans only lets photographers whose plan includes watermarks upload a logo
+func (s *Service) WithPlans(plans *plan.Service) *Service {
+	s.plans = plans
+	return s
+}
+
+// LogoUploadURLResponse contains where to upload a logo and the key to confirm it with
+type LogoUploadURLResponse struct {
+	UploadURL string `json:"uploadUrl"`
+	Key       string `json:"key"`
+}
+
+// LogoResult describes the photographer's logo after a change
+type LogoResult struct {
+	WatermarkLogoKey string `json:"watermarkLogoKey,omitempty"`
+	Regenerating     int    `json:"regenerating"` // galleries whose photos are queued to be watermarked again
+}
+
+// GenerateLogoUploadURL creates a presigned URL for uploading a new logo. The logo only takes
+// effect once confirmed with SetLogo.
+func (s *Service) GenerateLogoUploadURL(ctx context.Context, photographerID, mimeType string, size int64) (*LogoUploadURLResponse, error) {
+	if mimeType != LogoMimeType {
+		return nil, errors.NewBadRequest("Logo must be a PNG image")
+	}
+	if size < 0 || size > MaxLogoSize {
+		return nil, errors.NewBadRequest(fmt.Sprintf("Logo must be at most %d MB", MaxLogoSize>>20))
+	}
+	entitlements, err := s.plans.For(ctx, photographerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := entitlements.CheckWatermark(); err != nil {
+		return nil, err
+	}
+
+	key := logoKeyPrefix(photographerID) + utils.GenerateID("logo") + ".png"
+	url, err := s.storage.GenerateLogoUploadURL(ctx, key, mimeType)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to generate upload URL")
+	}
+	return &LogoUploadURLResponse{UploadURL: url, Key: key}, nil
+}
+
+// SetLogo makes an uploaded logo the photographer's watermark logo, replacing any previous one.
+// With regenerate, photos in galleries watermarked with the logo are queued to be processed again.
+func (s *Service) SetLogo(ctx context.Context, photographerID, key string, regenerate bool) (*LogoResult, error) {
+	if !strings.HasPrefix(key, logoKeyPrefix(photographerID)) {
+		return nil, errors.NewBadRequest("Invalid logo key")
+	}
+	size, err := s.storage.LogoSize(ctx, key)
+	if err != nil {
+		return nil, errors.NewBadRequest("Logo has not been uploaded")
+	}
+	if size > MaxLogoSize {
+		s.deleteLogo(ctx, key)
+		return nil, errors.NewBadRequest(fmt.Sprintf("Logo must be at most %d MB", MaxLogoSize>>20))
+	}
+	if err := s.checkLogoDimensions(ctx, key); err != nil {
+		s.deleteLogo(ctx, key)
+		return nil, err
+	}
+
+	return s.replaceLogo(ctx, photographerID, key, regenerate)
+}
+
+// checkLogoDimensions reads an uploaded logo's header and rejects anything that isn't a PNG
+// of at most MaxLogoPixels
+func (s *Service) checkLogoDimensions(ctx context.Context, key string) error {
+	body, err := s.storage.OpenLogo(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to read logo")
+	}
+	defer body.Close()
+
+	if _, err := logoConfig(body); err != nil {
+		if stderrors.Is(err, errLogoTooLarge) {
+			return errors.NewBadRequest(fmt.Sprintf("Logo must be at most %d pixels", MaxLogoPixels))
+		}
+		return errors.NewBadRequest("Logo must be a PNG image")
+	}
+	return nil
+}
+
+// DeleteLogo removes the photographer's watermark logo. Galleries watermarked with it are left
+// unwatermarked; with regenerate, their photos are queued to be processed again without it.
+func (s *Service) DeleteLogo(ctx context.Context, photographerID string, regenerate bool) (*LogoResult, error) {
+	return s.replaceLogo(ctx, photographerID, "", regenerate)
+}
+
+func (s *Service) replaceLogo(ctx context.Context, photographerID, key string, regenerate bool) (*LogoResult, error) {
+	account, err := s.accounts.GetByID(ctx, photographerID)
+	if err != nil {
+		if err == photographer.ErrNotFound {
+			return nil, errors.NewNotFound("Photographer")
+		}
+		return nil, errors.Wrap(err, 500, "Failed to get photographer")
+	}
+
+	if err := s.accounts.SetWatermarkLogo(ctx, photographerID, key); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to save watermark logo")
+	}
+	if previous := account.WatermarkLogoKey; previous != "" && previous != key {
+		s.deleteLogo(ctx, previous)
+	}
+	logger.Info("Watermark logo changed", map[string]interface{}{
+		"photographerId": photographerID, "key": key,
+	})
+
+	result := &LogoResult{WatermarkLogoKey: key}
+	if regenerate {
+		if result.Regenerating, err = s.regenerate(ctx, photographerID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// regenerate queues the photos of the photographer's galleries watermarked with their logo to
+// be processed again, returning how many galleries were queued
+func (s *Service) regenerate(ctx context.Context, photographerID string) (int, error) {
+	return s.reprocessor.ReprocessGalleries(ctx, photographerID, UsesLogo)
+}
+
+// deleteLogo deletes a logo that is no longer used. Failures only leave an orphaned object behind.
+func (s *Service) deleteLogo(ctx context.Context, key string) {
+	if err := s.storage.DeleteLogo(ctx, key); err != nil {
+		logger.Warn("Failed to delete watermark logo", map[string]interface{}{
+			"key": key, "error": err.Error(),
+		})
+	}
+}
+
+// logoKeyPrefix is where a photographer's logos are stored
+func logoKeyPrefix(photographerID string) string {
+	return logoPrefix + photographerID + "/"
+}
@@ -0,0 +1,197 @@
+package watermark
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/reprocess"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// newTestService creates a service for user_1, a photographer on the Pro plan
+func newTestService() (*Service, *mocks.MockPhotographerStore, *mocks.MockStorageService) {
+	accounts := mocks.NewMockPhotographerStore()
+	storage := mocks.NewMockStorageService()
+	reprocessor := reprocess.NewService(mocks.NewMockGalleryRepository(), mocks.NewMockPhotoRepository(), mocks.NewMockProcessingQueue())
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", Plan: plan.Pro})
+	return NewService(accounts, storage, reprocessor), accounts, storage
+}
+
+func TestGenerateLogoUploadURL(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	resp, err := service.GenerateLogoUploadURL(ctx, "user_1", LogoMimeType, 1024)
+	if err != nil {
+		t.Fatalf("GenerateLogoUploadURL() error: %v", err)
+	}
+	if !strings.HasPrefix(resp.Key, "watermarks/user_1/") || !strings.HasSuffix(resp.Key, ".png") {
+		t.Errorf("Key = %q, want a PNG under the photographer's prefix", resp.Key)
+	}
+	if resp.UploadURL == "" {
+		t.Error("UploadURL should be set")
+	}
+
+	for _, tt := range []struct {
+		name     string
+		mimeType string
+		size     int64
+	}{
+		{"jpeg", "image/jpeg", 1024},
+		{"too large", LogoMimeType, MaxLogoSize + 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GenerateLogoUploadURL(ctx, "user_1", tt.mimeType, tt.size)
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 400 {
+				t.Errorf("GenerateLogoUploadURL() error = %v, want bad request", err)
+			}
+		})
+	}
+}
+
+func TestGenerateLogoUploadURLRequiresWatermarkPlan(t *testing.T) {
+	service, accounts, _ := newTestService()
+	service.WithPlans(plan.NewService(accounts))
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_free", Plan: plan.Free})
+
+	_, err := service.GenerateLogoUploadURL(context.Background(), "user_free", LogoMimeType, 1024)
+	if !errors.IsPlanLimit(err) {
+		t.Errorf("GenerateLogoUploadURL() error = %v, want plan limit", err)
+	}
+}
+
+func TestSetLogo(t *testing.T) {
+	service, accounts, storage := newTestService()
+	ctx := context.Background()
+	key := "watermarks/user_1/logo_new.png"
+
+	t.Run("rejects other photographers' keys", func(t *testing.T) {
+		storage.PutObject(mocks.LogoBucket, "watermarks/user_2/logo.png", encodeLogo(t, 40, 20))
+		if _, err := service.SetLogo(ctx, "user_1", "watermarks/user_2/logo.png", false); err == nil {
+			t.Error("SetLogo() should reject a key outside the photographer's prefix")
+		}
+	})
+
+	t.Run("requires the upload", func(t *testing.T) {
+		if _, err := service.SetLogo(ctx, "user_1", key, false); err == nil {
+			t.Error("SetLogo() should fail before the logo is uploaded")
+		}
+	})
+
+	t.Run("replaces the previous logo", func(t *testing.T) {
+		accounts.AddPhotographer(&photographer.Photographer{
+			UserID: "user_1", Plan: plan.Pro, WatermarkLogoKey: "watermarks/user_1/logo_old.png",
+		})
+		storage.PutObject(mocks.LogoBucket, key, encodeLogo(t, 40, 20))
+
+		result, err := service.SetLogo(ctx, "user_1", key, false)
+		if err != nil {
+			t.Fatalf("SetLogo() error: %v", err)
+		}
+		if result.WatermarkLogoKey != key || result.Regenerating != 0 {
+			t.Errorf("SetLogo() = %+v", result)
+		}
+		account, _ := accounts.GetByID(ctx, "user_1")
+		if account.WatermarkLogoKey != key {
+			t.Errorf("WatermarkLogoKey = %q, want %q", account.WatermarkLogoKey, key)
+		}
+		deleted := storage.GetDeletedObjects()
+		if len(deleted) != 1 || deleted[0] != "watermarks/user_1/logo_old.png" {
+			t.Errorf("deleted = %v, want the previous logo", deleted)
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		key  string
+		body []byte
+	}{
+		{"deletes oversized logos", "watermarks/user_1/logo_big.png", make([]byte, MaxLogoSize+1)},
+		{"deletes logos over the pixel limit", "watermarks/user_1/logo_bomb.png", encodeLogo(t, 4097, 4096)},
+		{"deletes logos that aren't PNGs", "watermarks/user_1/logo_text.png", []byte("not an image")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			storage.ResetDeleted()
+			storage.PutObject(mocks.LogoBucket, tt.key, tt.body)
+			_, err := service.SetLogo(ctx, "user_1", tt.key, false)
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != 400 {
+				t.Fatalf("SetLogo() error = %v, want bad request", err)
+			}
+			if deleted := storage.GetDeletedObjects(); len(deleted) != 1 || deleted[0] != tt.key {
+				t.Errorf("deleted = %v, want the rejected logo", deleted)
+			}
+		})
+	}
+}
+
+func TestSetLogoRegeneratesLogoGalleries(t *testing.T) {
+	ctx := context.Background()
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", Plan: plan.Pro})
+	galleries := mocks.NewMockGalleryRepository()
+	storage := mocks.NewMockStorageService()
+	queue := mocks.NewMockProcessingQueue()
+	service := NewService(accounts, storage, reprocess.NewService(galleries, mocks.NewMockPhotoRepository(), queue))
+
+	galleries.AddGallery(&repository.Gallery{
+		GalleryID: "gal_logo", PhotographerID: "user_1", Status: repository.GalleryStatusActive,
+		EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo,
+	})
+	galleries.AddGallery(&repository.Gallery{
+		GalleryID: "gal_text", PhotographerID: "user_1", Status: repository.GalleryStatusActive,
+		EnableWatermark: true, WatermarkText: "© Studio",
+	})
+	galleries.AddGallery(&repository.Gallery{
+		GalleryID: "gal_off", PhotographerID: "user_1", Status: repository.GalleryStatusActive,
+		WatermarkType: repository.WatermarkTypeLogo,
+	})
+	galleries.AddGallery(&repository.Gallery{
+		GalleryID: "gal_archived", PhotographerID: "user_1", Status: repository.GalleryStatusArchived,
+		EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo,
+	})
+
+	key := "watermarks/user_1/logo_new.png"
+	storage.PutObject(mocks.LogoBucket, key, encodeLogo(t, 40, 20))
+	result, err := service.SetLogo(ctx, "user_1", key, true)
+	if err != nil {
+		t.Fatalf("SetLogo() error: %v", err)
+	}
+	if result.Regenerating != 1 {
+		t.Errorf("Regenerating = %d, want 1", result.Regenerating)
+	}
+	if jobs := queue.GalleryJobs(); len(jobs) != 1 || jobs[0].GalleryID != "gal_logo" {
+		t.Errorf("gallery jobs = %+v, want the active logo gallery only", jobs)
+	}
+}
+
+func TestDeleteLogo(t *testing.T) {
+	service, accounts, storage := newTestService()
+	ctx := context.Background()
+	accounts.AddPhotographer(&photographer.Photographer{
+		UserID: "user_1", Plan: plan.Pro, WatermarkLogoKey: "watermarks/user_1/logo.png",
+	})
+
+	result, err := service.DeleteLogo(ctx, "user_1", false)
+	if err != nil {
+		t.Fatalf("DeleteLogo() error: %v", err)
+	}
+	if result.WatermarkLogoKey != "" {
+		t.Errorf("WatermarkLogoKey = %q, want empty", result.WatermarkLogoKey)
+	}
+	account, _ := accounts.GetByID(ctx, "user_1")
+	if account.WatermarkLogoKey != "" {
+		t.Error("logo key should be cleared")
+	}
+	if deleted := storage.GetDeletedObjects(); len(deleted) != 1 {
+		t.Errorf("deleted = %v, want the logo", deleted)
+	}
+
+	if _, err := service.DeleteLogo(ctx, "user_missing", false); err == nil {
+		t.Error("DeleteLogo() should fail for an unknown photographer")
+	}
+}
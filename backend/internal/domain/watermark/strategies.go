@@ -0,0 +1,174 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	imageType "image"
+	_ "image/png"
+	"io"
+	"sync"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+)
+
+// maxCachedLogos bounds how many decoded logos a LogoLoader keeps
+const maxCachedLogos = 32
+
+// errLogoTooLarge is returned for logos over MaxLogoPixels
+var errLogoTooLarge = errors.New("logo exceeds pixel limit")
+
+// ErrInvalidLogo is returned by LogoLoader for a stored logo that can't be used, which
+// loading again won't change
+var ErrInvalidLogo = errors.New("invalid logo")
+
+// logoConfig reads a PNG logo's header, failing with errLogoTooLarge if decoding it would
+// exceed MaxLogoPixels
+func logoConfig(r io.Reader) (imageType.Config, error) {
+	cfg, format, err := imageType.DecodeConfig(r)
+	if err != nil {
+		return cfg, err
+	}
+	if format != "png" {
+		return cfg, fmt.Errorf("logo is %s, not png", format)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxLogoPixels {
+		return cfg, fmt.Errorf("%w: %dx%d", errLogoTooLarge, cfg.Width, cfg.Height)
+	}
+	return cfg, nil
+}
+
+// UsesLogo reports whether a gallery is watermarked with its photographer's logo
+func UsesLogo(gallery *repository.Gallery) bool {
+	return gallery != nil && gallery.EnableWatermark && gallery.WatermarkType == repository.WatermarkTypeLogo
+}
+
+// Strategies returns the processing strategies that watermark the photos of a gallery: none
+// when its watermark is disabled, otherwise its text or the logo. Logo watermarks are skipped
+// while the photographer has no logo.
+func Strategies(gallery *repository.Gallery, logo imageType.Image) []image.ProcessingStrategy {
+	if gallery == nil || !gallery.EnableWatermark {
+		return nil
+	}
+	position := gallery.WatermarkPosition
+	if position == "" {
+		position = "bottom-right"
+	}
+
+	if UsesLogo(gallery) {
+		if logo == nil {
+			return nil
+		}
+		return []image.ProcessingStrategy{image.NewLogoWatermarkStrategy(logo, image.LogoOptions{
+			Position: position,
+			Scale:    gallery.WatermarkScale,
+			Opacity:  gallery.WatermarkOpacity,
+			Margin:   gallery.WatermarkMargin,
+		})}
+	}
+
+	if gallery.WatermarkText == "" {
+		return nil
+	}
+	return []image.ProcessingStrategy{image.NewStyledWatermarkStrategy(image.WatermarkOptions{
+		Text:     gallery.WatermarkText,
+		Position: position,
+		Font:     gallery.WatermarkFont,
+		Size:     gallery.WatermarkSize,
+		Color:    gallery.WatermarkColor,
+		Opacity:  gallery.WatermarkOpacity,
+		Margin:   gallery.WatermarkMargin,
+		Mode:     gallery.WatermarkMode,
+		Rotation: gallery.WatermarkRotation,
+	})}
+}
+
+// AccountReader reads photographers
+type AccountReader interface {
+	GetByID(ctx context.Context, userID string) (*photographer.Photographer, error)
+}
+
+// Downloader reads objects from S3
+type Downloader interface {
+	Download(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+// LogoLoader loads the logos galleries are watermarked with. Decoded logos are cached by key;
+// replacing a logo gives it a new key, so the cache never serves a stale one. A nil *LogoLoader
+// loads no logos.
+type LogoLoader struct {
+	accounts   AccountReader
+	downloader Downloader
+	bucket     string
+
+	mu    sync.Mutex
+	cache map[string]imageType.Image
+}
+
+// NewLogoLoader creates a loader for logos stored in bucket
+func NewLogoLoader(accounts AccountReader, downloader Downloader, bucket string) *LogoLoader {
+	return &LogoLoader{
+		accounts:   accounts,
+		downloader: downloader,
+		bucket:     bucket,
+		cache:      make(map[string]imageType.Image),
+	}
+}
+
+// Load returns the logo a gallery is watermarked with, or nil if it doesn't use a logo
+// or its photographer hasn't uploaded one. Logos that are stored but too large or
+// undecodable fail with ErrInvalidLogo.
+func (l *LogoLoader) Load(ctx context.Context, gallery *repository.Gallery) (imageType.Image, error) {
+	if l == nil || !UsesLogo(gallery) {
+		return nil, nil
+	}
+	account, err := l.accounts.GetByID(ctx, gallery.PhotographerID)
+	if errors.Is(err, photographer.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photographer: %w", err)
+	}
+	key := account.WatermarkLogoKey
+	if key == "" {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	logo, ok := l.cache[key]
+	l.mu.Unlock()
+	if ok {
+		return logo, nil
+	}
+
+	body, err := l.downloader.Download(ctx, l.bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download logo: %w", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, MaxLogoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download logo: %w", err)
+	}
+	if len(data) > MaxLogoSize {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrInvalidLogo, key, MaxLogoSize)
+	}
+	if _, err := logoConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLogo, err)
+	}
+	logo, _, err = imageType.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLogo, err)
+	}
+
+	l.mu.Lock()
+	if len(l.cache) >= maxCachedLogos {
+		l.cache = make(map[string]imageType.Image)
+	}
+	l.cache[key] = logo
+	l.mu.Unlock()
+	return logo, nil
+}
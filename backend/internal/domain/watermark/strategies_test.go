@@ -0,0 +1,112 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	imageType "image"
+	"image/png"
+	"testing"
+
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+)
+
+func TestStrategies(t *testing.T) {
+	logo := imageType.NewRGBA(imageType.Rect(0, 0, 10, 10))
+
+	tests := []struct {
+		name    string
+		gallery *repository.Gallery
+		logo    imageType.Image
+		want    string
+	}{
+		{"disabled", &repository.Gallery{WatermarkText: "© Studio"}, nil, ""},
+		{"text", &repository.Gallery{EnableWatermark: true, WatermarkText: "© Studio"}, nil, "watermark"},
+		{"text without text", &repository.Gallery{EnableWatermark: true}, nil, ""},
+		{"logo", &repository.Gallery{EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo}, logo, "logo-watermark"},
+		{"logo not uploaded", &repository.Gallery{EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo, WatermarkText: "© Studio"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Strategies(tt.gallery, tt.logo)
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("Strategies() = %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Name() != tt.want {
+				t.Errorf("Strategies() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// encodeLogo returns a transparent PNG of the given size
+func encodeLogo(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imageType.NewNRGBA(imageType.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLogoLoader(t *testing.T) {
+	ctx := context.Background()
+	accounts := mocks.NewMockPhotographerStore()
+	storage := mocks.NewMockStorageService()
+	loader := NewLogoLoader(accounts, storage, mocks.LogoBucket)
+
+	storage.PutObject(mocks.LogoBucket, "watermarks/user_1/logo_a.png", encodeLogo(t, 40, 20))
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", WatermarkLogoKey: "watermarks/user_1/logo_a.png"})
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_2"})
+
+	logoGallery := &repository.Gallery{PhotographerID: "user_1", EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo}
+
+	logo, err := loader.Load(ctx, logoGallery)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if logo == nil || logo.Bounds().Dx() != 40 {
+		t.Fatalf("Load() = %v, want the 40x20 logo", logo)
+	}
+
+	// Served from the cache once decoded
+	storage.DownloadErr = context.DeadlineExceeded
+	if cached, err := loader.Load(ctx, logoGallery); err != nil || cached != logo {
+		t.Errorf("Load() = %v, %v, want the cached logo", cached, err)
+	}
+
+	if logo, err := loader.Load(ctx, &repository.Gallery{PhotographerID: "user_1", EnableWatermark: true, WatermarkText: "© Studio"}); logo != nil || err != nil {
+		t.Errorf("Load() for a text gallery = %v, %v, want nil", logo, err)
+	}
+	if logo, err := loader.Load(ctx, &repository.Gallery{PhotographerID: "user_2", EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo}); logo != nil || err != nil {
+		t.Errorf("Load() without an uploaded logo = %v, %v, want nil", logo, err)
+	}
+	if logo, err := loader.Load(ctx, &repository.Gallery{PhotographerID: "user_gone", EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo}); logo != nil || err != nil {
+		t.Errorf("Load() for a photographer without a profile = %v, %v, want nil", logo, err)
+	}
+
+	var nilLoader *LogoLoader
+	if logo, err := nilLoader.Load(ctx, logoGallery); logo != nil || err != nil {
+		t.Errorf("nil LogoLoader Load() = %v, %v, want nil", logo, err)
+	}
+}
+
+func TestLogoLoaderRejectsOversizedLogo(t *testing.T) {
+	accounts := mocks.NewMockPhotographerStore()
+	storage := mocks.NewMockStorageService()
+	loader := NewLogoLoader(accounts, storage, mocks.LogoBucket)
+
+	storage.PutObject(mocks.LogoBucket, "watermarks/user_1/logo_big.png", encodeLogo(t, 4097, 4096))
+	accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", WatermarkLogoKey: "watermarks/user_1/logo_big.png"})
+
+	gallery := &repository.Gallery{PhotographerID: "user_1", EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo}
+	if _, err := loader.Load(context.Background(), gallery); !errors.Is(err, errLogoTooLarge) || !errors.Is(err, ErrInvalidLogo) {
+		t.Errorf("Load() error = %v, want an invalid logo over the pixel limit", err)
+	}
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/events"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// retryDelays mirrors the DLQ reprocessor's backoff schedule: 1min, 5min, 15min, 1hr, 4hr.
+// A delivery is abandoned once every delay has been used.
+var retryDelays = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	1 * time.Hour,
+	4 * time.Hour,
+}
+
+// maxAttempts is the initial attempt plus one retry per delay.
+const maxAttempts = 6
+
+// deliveryBody is the JSON document posted to webhook endpoints.
+type deliveryBody struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Subscribe registers the service on the event bus for every supported event type.
+func (s *Service) Subscribe(bus events.EventBus) {
+	for _, eventType := range SupportedEventTypes {
+		bus.Subscribe(eventType, s.HandleEvent)
+	}
+}
+
+// HandleEvent records a pending delivery for each of the owning photographer's webhooks
+// that subscribe to the event. Nothing is sent here: HandleEvent runs inside the outbox
+// relay, so a slow endpoint would hold up every other event. RetryDue picks the deliveries
+// up on its next run. An error is returned when deliveries could not be recorded, so the
+// relay retries the event; redelivered events map to the same delivery IDs and are not
+// recorded twice.
+func (s *Service) HandleEvent(ctx context.Context, event events.Event) error {
+	photographerID, err := s.photographerFor(ctx, event)
+	if err != nil {
+		return err
+	}
+	if photographerID == "" {
+		return nil
+	}
+
+	webhooks, err := s.webhookRepo.ListByPhotographer(ctx, photographerID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Active || !subscribes(webhook.EventTypes, event.Type()) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(deliveryBody{
+				ID:        event.ID(),
+				Type:      string(event.Type()),
+				CreatedAt: event.Timestamp(),
+				Data:      event.Payload(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode webhook payload: %w", err)
+			}
+		}
+
+		now := time.Now()
+		delivery := &repository.WebhookDelivery{
+			DeliveryID:     deliveryID(webhook.WebhookID, event.ID()),
+			WebhookID:      webhook.WebhookID,
+			PhotographerID: photographerID,
+			EventID:        event.ID(),
+			EventType:      string(event.Type()),
+			Payload:        string(body),
+			Status:         DeliveryStatusPending,
+			CreatedAt:      now,
+			NextAttemptAt:  &now,
+		}
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			if stderrors.Is(err, repository.ErrAlreadyExists) {
+				continue
+			}
+			return fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RetryDue attempts pending deliveries whose next attempt time has passed, covering both
+// first attempts recorded by HandleEvent and retries. It returns the number attempted.
+func (s *Service) RetryDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.deliveryRepo.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	webhooks := make(map[string]*repository.Webhook)
+	for _, delivery := range due {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.webhookRepo.GetByID(ctx, delivery.WebhookID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get webhook: %w", err)
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		if webhook == nil || !webhook.Active {
+			s.abandon(ctx, delivery, "webhook was deleted or disabled")
+			continue
+		}
+		s.attempt(ctx, webhook, delivery)
+	}
+
+	return len(due), nil
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry on failure.
+func (s *Service) attempt(ctx context.Context, webhook *repository.Webhook, delivery *repository.WebhookDelivery) {
+	now := time.Now()
+	body := []byte(delivery.Payload)
+	headers := map[string]string{
+		HeaderDeliveryID: delivery.DeliveryID,
+		HeaderEventType:  delivery.EventType,
+		HeaderTimestamp:  strconv.FormatInt(now.Unix(), 10),
+		HeaderSignature:  Sign(webhook.Secret, now, body),
+	}
+
+	status, err := s.sender.Send(ctx, webhook.URL, headers, body)
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		delivery.Status = DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.CompletedAt = &now
+	default:
+		if err != nil {
+			delivery.LastError = err.Error()
+		} else {
+			delivery.LastError = fmt.Sprintf("endpoint responded with status %d", status)
+		}
+
+		if delivery.Attempts >= maxAttempts {
+			delivery.Status = DeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+			delivery.CompletedAt = &now
+		} else {
+			next := now.Add(backoffDelay(delivery.Attempts - 1))
+			delivery.NextAttemptAt = &next
+		}
+
+		logger.Warn("Webhook delivery failed", map[string]interface{}{
+			"deliveryId": delivery.DeliveryID, "webhookId": webhook.WebhookID,
+			"attempt": delivery.Attempts, "status": delivery.Status, "error": delivery.LastError,
+		})
+	}
+
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		logger.Error("Failed to record webhook delivery attempt", map[string]interface{}{
+			"deliveryId": delivery.DeliveryID, "error": err.Error(),
+		})
+	}
+}
+
+// abandon marks a pending delivery as failed without sending it.
+func (s *Service) abandon(ctx context.Context, delivery *repository.WebhookDelivery, reason string) {
+	now := time.Now()
+	delivery.Status = DeliveryStatusFailed
+	delivery.LastError = reason
+	delivery.NextAttemptAt = nil
+	delivery.CompletedAt = &now
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		logger.Error("Failed to abandon webhook delivery", map[string]interface{}{
+			"deliveryId": delivery.DeliveryID, "error": err.Error(),
+		})
+	}
+}
+
+// photographerFor resolves the photographer who owns the gallery an event refers to.
+// An empty ID means the event has no owner to notify, e.g. the gallery is already gone.
+func (s *Service) photographerFor(ctx context.Context, event events.Event) (string, error) {
+	var galleryID string
+	switch p := event.Payload().(type) {
+	case *events.GalleryCreatedPayload:
+		return p.PhotographerID, nil
+	case *events.GalleryDeletedPayload:
+		return p.PhotographerID, nil
+	case *events.GalleryExpiredPayload:
+		return p.PhotographerID, nil
+	case *events.GalleryRestoredPayload:
+		return p.PhotographerID, nil
+	case *events.PhotoUploadedPayload:
+		galleryID = p.GalleryID
+	case *events.PhotoProcessedPayload:
+		galleryID = p.GalleryID
+	case *events.FavoriteToggledPayload:
+		galleryID = p.GalleryID
+	case *events.SelectionSubmittedPayload:
+		galleryID = p.GalleryID
+	default:
+		return "", nil
+	}
+
+	gallery, err := s.galleryRepo.GetByID(ctx, galleryID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get gallery: %w", err)
+	}
+	if gallery == nil {
+		return "", nil
+	}
+	return gallery.PhotographerID, nil
+}
+
+// backoffDelay returns the wait before the next attempt, following calculateBackoffDelay
+// in the DLQ reprocessor.
+func backoffDelay(attemptNumber int) time.Duration {
+	if attemptNumber >= len(retryDelays) {
+		return retryDelays[len(retryDelays)-1]
+	}
+	return retryDelays[attemptNumber]
+}
+
+// deliveryID derives a stable ID so the same event is recorded once per webhook.
+func deliveryID(webhookID, eventID string) string {
+	sum := sha256.Sum256([]byte(webhookID + ":" + eventID))
+	return "dlv_" + hex.EncodeToString(sum[:12])
+}
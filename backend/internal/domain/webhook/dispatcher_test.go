@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/fixtures"
+	"photographer-gallery/backend/pkg/events"
+)
+
+func processedEvent(galleryID string) events.Event {
+	return events.NewEvent(events.PhotoProcessed, &events.PhotoProcessedPayload{
+		PhotoID: "photo_1", GalleryID: galleryID, Status: "completed", Width: 1600, Height: 1067,
+	}, galleryID)
+}
+
+func TestHandleEventDeliversSignedPayload(t *testing.T) {
+	service, webhookRepo, deliveryRepo, galleryRepo, sender := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	webhookRepo.AddWebhook(&repository.Webhook{
+		WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://studio.example.com/hooks",
+		Secret: "whsec_test", EventTypes: []string{"photo.processed"}, Active: true,
+	})
+
+	event := processedEvent(g.GalleryID)
+	if err := service.HandleEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandleEvent() error: %v", err)
+	}
+	if len(sender.sent()) != 0 {
+		t.Fatal("HandleEvent() should only record deliveries, not send them")
+	}
+	if d := deliveryRepo.Deliveries(); len(d) != 1 || d[0].Status != DeliveryStatusPending || d[0].NextAttemptAt == nil {
+		t.Fatalf("deliveries = %+v, want 1 pending delivery due now", d)
+	}
+
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+
+	sent := sender.sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(sent))
+	}
+	req := sent[0]
+	if req.url != "https://studio.example.com/hooks" {
+		t.Errorf("url = %v", req.url)
+	}
+	if req.headers[HeaderEventType] != "photo.processed" {
+		t.Errorf("%s = %v, want photo.processed", HeaderEventType, req.headers[HeaderEventType])
+	}
+	if !Verify("whsec_test", req.headers[HeaderTimestamp], req.headers[HeaderSignature], req.body) {
+		t.Error("Delivery signature does not verify")
+	}
+
+	var body struct {
+		ID   string                       `json:"id"`
+		Type string                       `json:"type"`
+		Data events.PhotoProcessedPayload `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body.ID != event.ID() || body.Data.PhotoID != "photo_1" {
+		t.Errorf("body = %+v, want event %v for photo_1", body, event.ID())
+	}
+
+	deliveries := deliveryRepo.Deliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(deliveries))
+	}
+	if deliveries[0].Status != DeliveryStatusSucceeded || deliveries[0].Attempts != 1 || deliveries[0].ResponseStatus != 200 {
+		t.Errorf("delivery = %+v, want succeeded after 1 attempt", deliveries[0])
+	}
+	if deliveries[0].NextAttemptAt != nil {
+		t.Error("Succeeded deliveries should not be scheduled again")
+	}
+}
+
+func TestHandleEventFiltersWebhooks(t *testing.T) {
+	service, webhookRepo, _, galleryRepo, sender := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_all", PhotographerID: "user_1", URL: "https://a.example.com", Active: true})
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_favorites", PhotographerID: "user_1", URL: "https://b.example.com", EventTypes: []string{"favorite.toggled"}, Active: true})
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_inactive", PhotographerID: "user_1", URL: "https://c.example.com", Active: false})
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_other", PhotographerID: "user_2", URL: "https://d.example.com", Active: true})
+
+	if err := service.HandleEvent(context.Background(), processedEvent(g.GalleryID)); err != nil {
+		t.Fatalf("HandleEvent() error: %v", err)
+	}
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+
+	sent := sender.sent()
+	if len(sent) != 1 || sent[0].url != "https://a.example.com" {
+		t.Errorf("Expected a single delivery to https://a.example.com, got %+v", sent)
+	}
+}
+
+func TestHandleEventGalleryEventsUsePayloadOwner(t *testing.T) {
+	service, webhookRepo, _, _, sender := newTestService()
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Active: true})
+
+	// The gallery no longer exists when its expiry is relayed
+	expired := events.NewEvent(events.GalleryExpired, &events.GalleryExpiredPayload{
+		GalleryID: "gal_gone", PhotographerID: "user_1", Name: "Wedding", ExpiredAt: time.Now(),
+	}, "gal_gone")
+	if err := service.HandleEvent(context.Background(), expired); err != nil {
+		t.Fatalf("HandleEvent() error: %v", err)
+	}
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+
+	if len(sender.sent()) != 1 {
+		t.Errorf("Expected 1 delivery, got %d", len(sender.sent()))
+	}
+}
+
+func TestHandleEventRedeliveryIsIdempotent(t *testing.T) {
+	service, webhookRepo, deliveryRepo, galleryRepo, sender := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Active: true})
+
+	event := processedEvent(g.GalleryID)
+	for i := 0; i < 3; i++ {
+		if err := service.HandleEvent(context.Background(), event); err != nil {
+			t.Fatalf("HandleEvent() error: %v", err)
+		}
+	}
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+
+	if len(sender.sent()) != 1 {
+		t.Errorf("Expected 1 request for a redelivered event, got %d", len(sender.sent()))
+	}
+	if len(deliveryRepo.Deliveries()) != 1 {
+		t.Errorf("Expected 1 delivery record, got %d", len(deliveryRepo.Deliveries()))
+	}
+}
+
+func TestHandleEventReturnsErrorWhenDeliveryNotRecorded(t *testing.T) {
+	service, webhookRepo, deliveryRepo, galleryRepo, _ := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Active: true})
+	deliveryRepo.CreateErr = errors.New("throttled")
+
+	if err := service.HandleEvent(context.Background(), processedEvent(g.GalleryID)); err == nil {
+		t.Error("HandleEvent() expected error so the relay retries the event")
+	}
+}
+
+func TestRetryDueFollowsBackoff(t *testing.T) {
+	service, webhookRepo, deliveryRepo, galleryRepo, sender := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Active: true})
+	sender.status = 503
+
+	start := time.Now()
+	if err := service.HandleEvent(context.Background(), processedEvent(g.GalleryID)); err != nil {
+		t.Fatalf("HandleEvent() error: %v", err)
+	}
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+
+	delivery := deliveryRepo.Deliveries()[0]
+	if delivery.Status != DeliveryStatusPending || delivery.NextAttemptAt == nil {
+		t.Fatalf("delivery = %+v, want pending with a retry scheduled", delivery)
+	}
+	if delay := delivery.NextAttemptAt.Sub(start); delay < time.Minute || delay > time.Minute+5*time.Second {
+		t.Errorf("first retry delay = %v, want about 1 minute", delay)
+	}
+	if delivery.LastError != "endpoint responded with status 503" {
+		t.Errorf("LastError = %v", delivery.LastError)
+	}
+
+	// Nothing is due yet
+	if n, _ := service.RetryDue(context.Background(), 10); n != 0 {
+		t.Errorf("RetryDue() attempted %d deliveries, want 0", n)
+	}
+
+	// Work through the remaining attempts
+	for attempt := 2; attempt <= maxAttempts; attempt++ {
+		deliveryRepo.SetNextAttempt(delivery.DeliveryID, time.Now().Add(-time.Second))
+		if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+			t.Fatalf("attempt %d: RetryDue() = %d, %v", attempt, n, err)
+		}
+	}
+
+	final, _ := deliveryRepo.GetByID(context.Background(), delivery.DeliveryID)
+	if final.Status != DeliveryStatusFailed || final.Attempts != maxAttempts {
+		t.Errorf("delivery = %+v, want failed after %d attempts", final, maxAttempts)
+	}
+	if final.NextAttemptAt != nil {
+		t.Error("Failed deliveries should not be scheduled again")
+	}
+	if len(sender.sent()) != maxAttempts {
+		t.Errorf("Expected %d requests, got %d", maxAttempts, len(sender.sent()))
+	}
+}
+
+func TestRetryDueAbandonsDisabledWebhook(t *testing.T) {
+	service, webhookRepo, deliveryRepo, _, sender := newTestService()
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Active: false})
+
+	past := time.Now().Add(-time.Minute)
+	deliveryRepo.Create(context.Background(), &repository.WebhookDelivery{
+		DeliveryID: "dlv_1", WebhookID: "whk_1", PhotographerID: "user_1",
+		Status: DeliveryStatusPending, Attempts: 1, CreatedAt: past, NextAttemptAt: &past,
+	})
+
+	if _, err := service.RetryDue(context.Background(), 10); err != nil {
+		t.Fatalf("RetryDue() error: %v", err)
+	}
+
+	if len(sender.sent()) != 0 {
+		t.Error("Deliveries for disabled webhooks should not be sent")
+	}
+	if d, _ := deliveryRepo.GetByID(context.Background(), "dlv_1"); d.Status != DeliveryStatusFailed {
+		t.Errorf("Status = %v, want failed", d.Status)
+	}
+}
+
+func TestReplayDelivery(t *testing.T) {
+	service, webhookRepo, deliveryRepo, galleryRepo, sender := newTestService()
+	g := fixtures.NewGallery(fixtures.GalleryOptions{PhotographerID: "user_1"})
+	galleryRepo.AddGallery(g)
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Secret: "whsec_test", Active: true})
+
+	if err := service.HandleEvent(context.Background(), processedEvent(g.GalleryID)); err != nil {
+		t.Fatalf("HandleEvent() error: %v", err)
+	}
+	if n, err := service.RetryDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("RetryDue() = %d, %v, want 1", n, err)
+	}
+	original := deliveryRepo.Deliveries()[0]
+
+	_, err := service.Replay(context.Background(), "user_2", "whk_1", original.DeliveryID)
+	assertErrorCode(t, err, 404)
+
+	replay, err := service.Replay(context.Background(), "user_1", "whk_1", original.DeliveryID)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+	if replay.DeliveryID == original.DeliveryID || replay.ReplayOf != original.DeliveryID {
+		t.Errorf("replay = %+v, want a new delivery referencing %v", replay, original.DeliveryID)
+	}
+	if replay.Status != DeliveryStatusSucceeded {
+		t.Errorf("Status = %v, want succeeded", replay.Status)
+	}
+
+	sent := sender.sent()
+	if len(sent) != 2 || string(sent[1].body) != string(sent[0].body) {
+		t.Error("Replay should resend the original payload")
+	}
+
+	deliveries, _, err := service.ListDeliveries(context.Background(), "user_1", "whk_1", 20, nil)
+	if err != nil {
+		t.Fatalf("ListDeliveries() error: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Errorf("Expected 2 deliveries in the log, got %d", len(deliveries))
+	}
+}
+
+func TestHTTPSender(t *testing.T) {
+	var received http.Header
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPSender(server.Client())
+	status, err := sender.Send(context.Background(), server.URL, map[string]string{HeaderEventType: "gallery.created"}, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if status != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", status, http.StatusAccepted)
+	}
+	if received.Get(HeaderEventType) != "gallery.created" || received.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", received)
+	}
+}
+
+func TestHTTPSenderRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("default sender reached a loopback endpoint")
+	}))
+	defer server.Close()
+
+	// localhost resolves to loopback at dial time even though it is sent by name
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if _, err := NewHTTPSender(nil).Send(context.Background(), url, nil, []byte(`{}`)); err == nil {
+		t.Error("Send() to a loopback address succeeded, want error")
+	}
+
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"203.0.113.10:443", false},
+		{"127.0.0.1:443", true},
+		{"10.1.2.3:443", true},
+		{"169.254.169.254:80", true},
+		{"[fd00::1]:443", true},
+	}
+	for _, tt := range tests {
+		if err := publicAddressOnly("tcp", tt.address, nil); (err != nil) != tt.wantErr {
+			t.Errorf("publicAddressOnly(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// maxResponseBytes bounds how much of an endpoint's response body is read.
+const maxResponseBytes = 64 * 1024
+
+// Sender posts a signed delivery to a webhook endpoint.
+type Sender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// HTTPSender delivers webhooks over HTTP.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender creates a sender using client. A nil client gets a 10 second timeout,
+// refuses to connect to non-public addresses and rejects redirects, so endpoints cannot
+// reach internal services through DNS or bounce deliveries elsewhere.
+func NewHTTPSender(client *http.Client) *HTTPSender {
+	if client == nil {
+		dialer := &net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: publicAddressOnly,
+		}
+		client = &http.Client{
+			Timeout: 10 * time.Second,
+			// No proxy: the dial check must see the endpoint's own address.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     30 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return fmt.Errorf("endpoint redirected to %s; redirects are not followed", req.URL.Redacted())
+			},
+		}
+	}
+	return &HTTPSender{client: client}
+}
+
+// publicAddressOnly is a net.Dialer Control hook that runs after DNS resolution, so a
+// hostname resolving to a private, loopback or link-local address is refused.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("endpoint resolves to non-public address %s", host)
+	}
+	return nil
+}
+
+// Send posts body to url and returns the response status code.
+func (s *HTTPSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PhotographerGallery-Webhooks/1.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	return resp.StatusCode, nil
+}
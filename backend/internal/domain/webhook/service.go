@@ -0,0 +1,245 @@
+// Package webhook lets photographers receive signed HTTP callbacks for domain events.
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/errors"
+	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
+)
+
+// maxWebhooksPerPhotographer limits how many endpoints a photographer can register.
+const maxWebhooksPerPhotographer = 10
+
+// Service handles webhook registration and delivery.
+type Service struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	galleryRepo  repository.GalleryRepository
+	sender       Sender
+}
+
+// NewService creates a new webhook service.
+func NewService(webhookRepo repository.WebhookRepository, deliveryRepo repository.WebhookDeliveryRepository, galleryRepo repository.GalleryRepository, sender Sender) *Service {
+	return &Service{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		galleryRepo:  galleryRepo,
+		sender:       sender,
+	}
+}
+
+// CreateWebhookRequest represents the request to register a webhook.
+type CreateWebhookRequest struct {
+	URL         string
+	EventTypes  []string
+	Description string
+}
+
+// UpdateWebhookRequest represents the request to update a webhook.
+type UpdateWebhookRequest struct {
+	URL         *string
+	EventTypes  *[]string
+	Description *string
+	Active      *bool
+}
+
+// Create registers a new webhook. The returned webhook carries its signing secret,
+// which is not exposed again afterwards.
+func (s *Service) Create(ctx context.Context, photographerID string, req CreateWebhookRequest) (*repository.Webhook, error) {
+	if !isValidEndpoint(req.URL) {
+		return nil, errors.NewBadRequest("Webhook URL must be a public HTTPS URL")
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.webhookRepo.ListByPhotographer(ctx, photographerID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list webhooks")
+	}
+	if len(existing) >= maxWebhooksPerPhotographer {
+		return nil, errors.NewBadRequest("Webhook limit reached")
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to create webhook")
+	}
+
+	now := time.Now()
+	webhook := &repository.Webhook{
+		WebhookID:      utils.GenerateID("whk"),
+		PhotographerID: photographerID,
+		URL:            req.URL,
+		Secret:         secret,
+		EventTypes:     req.EventTypes,
+		Description:    req.Description,
+		Active:         true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to create webhook")
+	}
+
+	logger.Info("Webhook created", map[string]interface{}{"webhookId": webhook.WebhookID, "photographerId": photographerID})
+	return webhook, nil
+}
+
+// List returns a photographer's webhooks.
+func (s *Service) List(ctx context.Context, photographerID string) ([]*repository.Webhook, error) {
+	webhooks, err := s.webhookRepo.ListByPhotographer(ctx, photographerID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to list webhooks")
+	}
+	return webhooks, nil
+}
+
+// Get retrieves a webhook owned by the photographer.
+func (s *Service) Get(ctx context.Context, photographerID, webhookID string) (*repository.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get webhook")
+	}
+	if webhook == nil || webhook.PhotographerID != photographerID {
+		return nil, errors.NewNotFound("Webhook")
+	}
+	return webhook, nil
+}
+
+// Update changes a webhook's endpoint, subscriptions, description, or active flag.
+func (s *Service) Update(ctx context.Context, photographerID, webhookID string, req UpdateWebhookRequest) (*repository.Webhook, error) {
+	webhook, err := s.Get(ctx, photographerID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if !isValidEndpoint(*req.URL) {
+			return nil, errors.NewBadRequest("Webhook URL must be a public HTTPS URL")
+		}
+		webhook.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		if err := validateEventTypes(*req.EventTypes); err != nil {
+			return nil, err
+		}
+		webhook.EventTypes = *req.EventTypes
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to update webhook")
+	}
+	return webhook, nil
+}
+
+// RotateSecret replaces a webhook's signing secret and returns the webhook with the new secret.
+func (s *Service) RotateSecret(ctx context.Context, photographerID, webhookID string) (*repository.Webhook, error) {
+	webhook, err := s.Get(ctx, photographerID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to rotate webhook secret")
+	}
+	webhook.Secret = secret
+	webhook.UpdatedAt = time.Now()
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to rotate webhook secret")
+	}
+	return webhook, nil
+}
+
+// Delete removes a webhook. Its delivery log expires on its own.
+func (s *Service) Delete(ctx context.Context, photographerID, webhookID string) error {
+	if _, err := s.Get(ctx, photographerID, webhookID); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, webhookID); err != nil {
+		return errors.Wrap(err, 500, "Failed to delete webhook")
+	}
+	logger.Info("Webhook deleted", map[string]interface{}{"webhookId": webhookID})
+	return nil
+}
+
+// ListDeliveries returns a webhook's delivery log, most recent first.
+func (s *Service) ListDeliveries(ctx context.Context, photographerID, webhookID string, limit int, lastKey map[string]interface{}) ([]*repository.WebhookDelivery, map[string]interface{}, error) {
+	if _, err := s.Get(ctx, photographerID, webhookID); err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, nextKey, err := s.deliveryRepo.ListByWebhook(ctx, webhookID, limit, lastKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, 500, "Failed to list webhook deliveries")
+	}
+	return deliveries, nextKey, nil
+}
+
+// GetDelivery retrieves a delivery belonging to one of the photographer's webhooks.
+func (s *Service) GetDelivery(ctx context.Context, photographerID, webhookID, deliveryID string) (*repository.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to get webhook delivery")
+	}
+	if delivery == nil || delivery.WebhookID != webhookID || delivery.PhotographerID != photographerID {
+		return nil, errors.NewNotFound("Delivery")
+	}
+	return delivery, nil
+}
+
+// Replay sends a previously delivered payload again as a new delivery, signed with the
+// webhook's current secret. The new delivery follows the normal retry schedule.
+func (s *Service) Replay(ctx context.Context, photographerID, webhookID, deliveryID string) (*repository.WebhookDelivery, error) {
+	webhook, err := s.Get(ctx, photographerID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := s.GetDelivery(ctx, photographerID, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	replay := &repository.WebhookDelivery{
+		DeliveryID:     utils.GenerateID("dlv"),
+		WebhookID:      webhook.WebhookID,
+		PhotographerID: photographerID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         DeliveryStatusPending,
+		ReplayOf:       original.DeliveryID,
+		CreatedAt:      now,
+		NextAttemptAt:  &now,
+	}
+	if err := s.deliveryRepo.Create(ctx, replay); err != nil {
+		return nil, errors.Wrap(err, 500, "Failed to replay webhook delivery")
+	}
+
+	s.attempt(ctx, webhook, replay)
+	return replay, nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, t := range eventTypes {
+		if !isSupportedEventType(t) {
+			return errors.NewBadRequest("Unsupported event type: " + t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/errors"
+)
+
+// recordingSender captures deliveries and replies with a configurable status.
+type recordingSender struct {
+	mu       sync.Mutex
+	status   int
+	err      error
+	requests []sentRequest
+}
+
+type sentRequest struct {
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+func (s *recordingSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, sentRequest{url: url, headers: headers, body: body})
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.status, nil
+}
+
+func (s *recordingSender) sent() []sentRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentRequest{}, s.requests...)
+}
+
+func newTestService() (*Service, *mocks.MockWebhookRepository, *mocks.MockWebhookDeliveryRepository, *mocks.MockGalleryRepository, *recordingSender) {
+	webhookRepo := mocks.NewMockWebhookRepository()
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository()
+	galleryRepo := mocks.NewMockGalleryRepository()
+	sender := &recordingSender{status: 200}
+	return NewService(webhookRepo, deliveryRepo, galleryRepo, sender), webhookRepo, deliveryRepo, galleryRepo, sender
+}
+
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		t.Fatalf("expected *errors.AppError, got %T (%v)", err, err)
+	}
+	if appErr.Code != code {
+		t.Errorf("error code = %d, want %d", appErr.Code, code)
+	}
+}
+
+func TestCreateWebhook(t *testing.T) {
+	service, webhookRepo, _, _, _ := newTestService()
+
+	webhook, err := service.Create(context.Background(), "user_1", CreateWebhookRequest{
+		URL:        "https://studio.example.com/hooks",
+		EventTypes: []string{"photo.processed", "favorite.toggled"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	if !strings.HasPrefix(webhook.WebhookID, "whk_") {
+		t.Errorf("WebhookID = %v, want whk_ prefix", webhook.WebhookID)
+	}
+	if !strings.HasPrefix(webhook.Secret, "whsec_") {
+		t.Errorf("Secret = %v, want whsec_ prefix", webhook.Secret)
+	}
+	if !webhook.Active {
+		t.Error("New webhooks should be active")
+	}
+	if stored, _ := webhookRepo.GetByID(context.Background(), webhook.WebhookID); stored == nil {
+		t.Error("Webhook was not stored")
+	}
+}
+
+func TestCreateWebhookValidation(t *testing.T) {
+	service, _, _, _, _ := newTestService()
+
+	_, err := service.Create(context.Background(), "user_1", CreateWebhookRequest{URL: "http://studio.example.com/hooks"})
+	assertErrorCode(t, err, 400)
+
+	_, err = service.Create(context.Background(), "user_1", CreateWebhookRequest{
+		URL:        "https://studio.example.com/hooks",
+		EventTypes: []string{"photo.exploded"},
+	})
+	assertErrorCode(t, err, 400)
+}
+
+func TestCreateWebhookLimit(t *testing.T) {
+	service, _, _, _, _ := newTestService()
+	ctx := context.Background()
+
+	for i := 0; i < maxWebhooksPerPhotographer; i++ {
+		if _, err := service.Create(ctx, "user_1", CreateWebhookRequest{URL: "https://studio.example.com/hooks"}); err != nil {
+			t.Fatalf("Create() error: %v", err)
+		}
+	}
+
+	_, err := service.Create(ctx, "user_1", CreateWebhookRequest{URL: "https://studio.example.com/hooks"})
+	assertErrorCode(t, err, 400)
+}
+
+func TestWebhookOwnership(t *testing.T) {
+	service, webhookRepo, _, _, _ := newTestService()
+	ctx := context.Background()
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_owner", URL: "https://a.example.com", Active: true})
+
+	_, err := service.Get(ctx, "user_other", "whk_1")
+	assertErrorCode(t, err, 404)
+
+	active := false
+	_, err = service.Update(ctx, "user_other", "whk_1", UpdateWebhookRequest{Active: &active})
+	assertErrorCode(t, err, 404)
+
+	err = service.Delete(ctx, "user_other", "whk_1")
+	assertErrorCode(t, err, 404)
+
+	_, _, err = service.ListDeliveries(ctx, "user_other", "whk_1", 20, nil)
+	assertErrorCode(t, err, 404)
+
+	if stored, _ := webhookRepo.GetByID(ctx, "whk_1"); stored == nil || !stored.Active {
+		t.Error("Webhook should not be changed by a non-owner")
+	}
+}
+
+func TestUpdateAndRotateSecret(t *testing.T) {
+	service, webhookRepo, _, _, _ := newTestService()
+	ctx := context.Background()
+	webhookRepo.AddWebhook(&repository.Webhook{WebhookID: "whk_1", PhotographerID: "user_1", URL: "https://a.example.com", Secret: "whsec_old", Active: true})
+
+	types := []string{"gallery.expired"}
+	active := false
+	updated, err := service.Update(ctx, "user_1", "whk_1", UpdateWebhookRequest{EventTypes: &types, Active: &active})
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if updated.Active || len(updated.EventTypes) != 1 {
+		t.Errorf("Update() = %+v, want inactive webhook subscribed to gallery.expired", updated)
+	}
+
+	badURL := "https://localhost/hook"
+	_, err = service.Update(ctx, "user_1", "whk_1", UpdateWebhookRequest{URL: &badURL})
+	assertErrorCode(t, err, 400)
+
+	rotated, err := service.RotateSecret(ctx, "user_1", "whk_1")
+	if err != nil {
+		t.Fatalf("RotateSecret() error: %v", err)
+	}
+	if rotated.Secret == "whsec_old" || rotated.Secret == "" {
+		t.Errorf("Secret was not rotated: %v", rotated.Secret)
+	}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"photographer-gallery/backend/pkg/events"
+)
+
+// SupportedEventTypes lists the events photographers can subscribe to.
+var SupportedEventTypes = []events.EventType{
+	events.GalleryCreated,
+	events.GalleryExpired,
+	events.GalleryRestored,
+	events.GalleryDeleted,
+	events.PhotoUploaded,
+	events.PhotoProcessed,
+	events.FavoriteToggled,
+	events.SelectionSubmitted,
+}
+
+// isValidEndpoint checks that an endpoint is an absolute HTTPS URL on a public host.
+func isValidEndpoint(endpoint string) bool {
+	if len(endpoint) > 2048 {
+		return false
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return false
+	}
+	return true
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is not publicly routable.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip is publicly routable. Loopback, private, link-local
+// (including the 169.254.169.254 instance metadata address), shared and unspecified
+// addresses are not.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	return !sharedAddressSpace.Contains(ip)
+}
+
+// isSupportedEventType reports whether eventType can be subscribed to.
+func isSupportedEventType(eventType string) bool {
+	for _, t := range SupportedEventTypes {
+		if string(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribes reports whether a webhook wants events of the given type.
+func subscribes(eventTypes []string, eventType events.EventType) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, t := range eventTypes {
+		if t == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,45 +2,92 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	stdimage "image"
 	"io"
 	"log"
+	"time"
 
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/profile"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/watermark"
 	"photographer-gallery/backend/internal/repository"
 	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/events"
 	"photographer-gallery/backend/pkg/utils/s3key"
 )
 
 // ProcessingContext holds all data needed during photo processing.
 type ProcessingContext struct {
 	ctx           context.Context
+	key           *s3key.Key
 	PhotoID       string
 	GalleryID     string
 	ObjectKey     string
 	BucketName    string
-	ImageData     []byte
+	SourceVersion string         // version of the original read, its S3 version ID or ETag
+	Image         stdimage.Image // the original, decoded once and shared by every derivative
+	Size          int64          // bytes in the original
 	Metadata      *image.ImageMetadata
 	Gallery       *repository.Gallery
+	WatermarkLogo stdimage.Image                // the logo the gallery is watermarked with, if any
+	Profile       *repository.ProcessingProfile // the processing profile the gallery uses, if any
 	Photo         *repository.Photo
 	ThumbnailData []byte
 	OptimizedData []byte
+	Placeholder   *image.Placeholder
+	Renditions    []image.Rendition
 	Width         int
 	Height        int
+
+	// Entitlements are the plan of the gallery's photographer, which caps the size of the
+	// optimized image and renditions
+	Entitlements plan.Entitlements
+
+	uploads []upload
+	changes []func(*repository.Photo)
+}
+
+// upload is a derivative waiting to be stored in S3
+type upload struct {
+	bucket, key, contentType string
+	data                     []byte
 }
 
 // NewProcessingContext creates a new processing context.
-func NewProcessingContext(ctx context.Context, photoID, galleryID, objectKey, bucketName string) *ProcessingContext {
+func NewProcessingContext(ctx context.Context, key *s3key.Key, objectKey, bucketName string) *ProcessingContext {
 	return &ProcessingContext{
 		ctx:        ctx,
-		PhotoID:    photoID,
-		GalleryID:  galleryID,
+		key:        key,
+		PhotoID:    key.PhotoID,
+		GalleryID:  key.GalleryID,
 		ObjectKey:  objectKey,
 		BucketName: bucketName,
 	}
 }
 
+// Context returns the context the photo is being processed in.
+func (pctx *ProcessingContext) Context() context.Context {
+	return pctx.ctx
+}
+
+// Upload queues a derivative to be stored in S3 by the upload stage.
+func (pctx *ProcessingContext) Upload(bucket, key string, data []byte, contentType string) {
+	pctx.uploads = append(pctx.uploads, upload{bucket: bucket, key: key, contentType: contentType, data: data})
+}
+
+// Record queues a change to the photo's record, made when the record stage writes it. If
+// another delivery changes the photo first, the changes are made again to a fresh read of it,
+// so they should only set fields from what the stage produced.
+func (pctx *ProcessingContext) Record(change func(p *repository.Photo)) {
+	pctx.changes = append(pctx.changes, change)
+}
+
 // ProcessingHandler defines the interface for chain of responsibility handlers.
 type ProcessingHandler interface {
 	SetNext(handler ProcessingHandler) ProcessingHandler
@@ -68,7 +115,7 @@ func (h *BaseHandler) HandleNext(pctx *ProcessingContext) error {
 
 // S3Downloader defines the interface for downloading from S3.
 type S3Downloader interface {
-	Download(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	DownloadVersion(ctx context.Context, bucket, key string) (io.ReadCloser, string, error)
 }
 
 // S3Uploader defines the interface for uploading to S3.
@@ -76,300 +123,537 @@ type S3Uploader interface {
 	Upload(ctx context.Context, bucket, key string, data []byte, contentType string) error
 }
 
-// DownloadHandler downloads the original image from S3.
+// DownloadHandler streams the original image from S3 into a single decode. Dimensions and
+// EXIF metadata are read from its header on the way, so the file itself is never buffered.
 type DownloadHandler struct {
 	BaseHandler
-	s3Client S3Downloader
+	s3Client  S3Downloader
+	processor *image.Processor
 }
 
-// NewDownloadHandler creates a new download handler.
-func NewDownloadHandler(s3Client S3Downloader) *DownloadHandler {
-	return &DownloadHandler{s3Client: s3Client}
+// NewDownloadHandler creates a new download handler. Images over the processor's pixel
+// limit fail before their pixels are downloaded.
+func NewDownloadHandler(s3Client S3Downloader, processor *image.Processor) *DownloadHandler {
+	return &DownloadHandler{s3Client: s3Client, processor: processor}
 }
 
-// Handle downloads the image from S3.
+// Handle downloads and decodes the image.
 func (h *DownloadHandler) Handle(pctx *ProcessingContext) error {
 	log.Printf("[DownloadHandler] Downloading from %s/%s", pctx.BucketName, pctx.ObjectKey)
 
-	reader, err := h.s3Client.Download(pctx.ctx, pctx.BucketName, pctx.ObjectKey)
+	reader, version, err := h.s3Client.DownloadVersion(pctx.ctx, pctx.BucketName, pctx.ObjectKey)
 	if err != nil {
 		return fmt.Errorf("failed to download image: %w", err)
 	}
 	defer reader.Close()
 
-	data, err := io.ReadAll(reader)
+	src, err := h.processor.Open(reader)
 	if err != nil {
-		return fmt.Errorf("failed to read image data: %w", err)
+		return fmt.Errorf("failed to read image: %w", err)
 	}
 
-	pctx.ImageData = data
+	pctx.SourceVersion = version
+	pctx.Image = src.Image
+	pctx.Size = src.Size
+	pctx.Metadata = src.Metadata
+	pctx.Width = src.Metadata.Width
+	pctx.Height = src.Metadata.Height
 	return h.HandleNext(pctx)
 }
 
-// DimensionsHandler extracts image dimensions.
-type DimensionsHandler struct {
+// GalleryHandler loads what the photo's derivatives depend on besides its pixels: the
+// gallery's watermark settings, logo and processing profile, the photographer's plan and the
+// photo's existing record.
+type GalleryHandler struct {
 	BaseHandler
-	processor *image.Processor
+	photoRepo   repository.PhotoRepository
+	galleryRepo repository.GalleryRepository
+	logos       *watermark.LogoLoader
+	plans       *plan.Service
+	profiles    repository.ProcessingProfileRepository
 }
 
-// NewDimensionsHandler creates a new dimensions handler.
-func NewDimensionsHandler(processor *image.Processor) *DimensionsHandler {
-	return &DimensionsHandler{processor: processor}
+// NewGalleryHandler creates a new gallery handler. Without logos, galleries watermarked
+// with a logo get none; without plans, photos are sized as on the largest plan; without
+// profiles, every gallery gets the default processing.
+func NewGalleryHandler(photoRepo repository.PhotoRepository, galleryRepo repository.GalleryRepository, logos *watermark.LogoLoader, plans *plan.Service, profiles repository.ProcessingProfileRepository) *GalleryHandler {
+	return &GalleryHandler{photoRepo: photoRepo, galleryRepo: galleryRepo, logos: logos, plans: plans, profiles: profiles}
 }
 
-// Handle extracts dimensions from the image.
-func (h *DimensionsHandler) Handle(pctx *ProcessingContext) error {
-	log.Printf("[DimensionsHandler] Extracting dimensions for photo %s", pctx.PhotoID)
+// Handle loads the gallery, logo, profile, plan and photo.
+func (h *GalleryHandler) Handle(pctx *ProcessingContext) error {
+	gallery, err := h.galleryRepo.GetByID(pctx.ctx, pctx.GalleryID)
+	if err != nil {
+		return fmt.Errorf("failed to get gallery: %w", err)
+	}
+	pctx.Gallery = gallery
+
+	// A reprocessed photo keeps the focal point its thumbnail is cropped around
+	existing, err := h.photoRepo.GetByID(pctx.ctx, pctx.PhotoID)
+	if err != nil {
+		return fmt.Errorf("failed to get photo: %w", err)
+	}
+	pctx.Photo = existing
 
-	width, height, err := h.processor.GetImageDimensions(bytes.NewReader(pctx.ImageData))
+	// Photos are never published without the watermark the gallery asks for: a logo that
+	// couldn't be read is retried, and one that can't be used fails the photo
+	logo, err := h.logos.Load(pctx.ctx, gallery)
 	if err != nil {
-		return fmt.Errorf("failed to get dimensions: %w", err)
+		if !errors.Is(err, watermark.ErrInvalidLogo) {
+			err = photo.Unavailable(err)
+		}
+		return fmt.Errorf("failed to load watermark logo: %w", err)
 	}
+	pctx.WatermarkLogo = logo
 
-	pctx.Width = width
-	pctx.Height = height
+	if pctx.Profile, err = h.profile(pctx.ctx, gallery); err != nil {
+		return fmt.Errorf("failed to get processing profile: %w", err)
+	}
+
+	// A plan that couldn't be read is retried rather than sizing the photo as on the free plan
+	if pctx.Entitlements, err = h.entitlements(pctx.ctx, gallery); err != nil {
+		return fmt.Errorf("failed to get plan: %w", photo.Unavailable(err))
+	}
 	return h.HandleNext(pctx)
 }
 
-// MetadataHandler extracts EXIF metadata.
+// entitlements returns the plan of the gallery's photographer. Photos whose gallery or
+// photographer can't be found are processed as on the free plan.
+func (h *GalleryHandler) entitlements(ctx context.Context, gallery *repository.Gallery) (plan.Entitlements, error) {
+	if gallery == nil {
+		return plan.For(plan.Free), nil
+	}
+	return h.plans.For(ctx, gallery.PhotographerID)
+}
+
+// profile returns the processing profile a gallery uses. A profile that was deleted, or isn't
+// its photographer's, leaves the gallery on the default processing.
+func (h *GalleryHandler) profile(ctx context.Context, gallery *repository.Gallery) (*repository.ProcessingProfile, error) {
+	if gallery == nil || gallery.ProcessingProfileID == "" || h.profiles == nil {
+		return nil, nil
+	}
+	p, err := h.profiles.GetByID(ctx, gallery.ProcessingProfileID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || p.PhotographerID != gallery.PhotographerID {
+		log.Printf("[GalleryHandler] Processing profile %s of gallery %s not found, using the default", gallery.ProcessingProfileID, gallery.GalleryID)
+		return nil, nil
+	}
+	return p, nil
+}
+
+// MetadataHandler stores the photo's EXIF metadata on its record.
 type MetadataHandler struct {
 	BaseHandler
-	processor *image.Processor
 }
 
 // NewMetadataHandler creates a new metadata handler.
-func NewMetadataHandler(processor *image.Processor) *MetadataHandler {
-	return &MetadataHandler{processor: processor}
+func NewMetadataHandler() *MetadataHandler {
+	return &MetadataHandler{}
 }
 
-// Handle extracts EXIF metadata from the image.
+// Handle records the camera settings, date taken and location of the photo.
 func (h *MetadataHandler) Handle(pctx *ProcessingContext) error {
-	log.Printf("[MetadataHandler] Extracting metadata for photo %s", pctx.PhotoID)
-
-	metadata, err := h.processor.ExtractEXIF(bytes.NewReader(pctx.ImageData))
-	if err != nil {
-		log.Printf("[MetadataHandler] Warning: Failed to extract EXIF: %v", err)
-		pctx.Metadata = &image.ImageMetadata{}
-	} else {
-		pctx.Metadata = metadata
+	if m := pctx.Metadata; m != nil {
+		pctx.Record(func(p *repository.Photo) { storeEXIFMetadata(p, m) })
 	}
+	return h.HandleNext(pctx)
+}
 
-	// Update dimensions if not set from EXIF
-	if pctx.Metadata.Width == 0 {
-		pctx.Metadata.Width = pctx.Width
+func storeEXIFMetadata(p *repository.Photo, m *image.ImageMetadata) {
+	if p.Metadata == nil {
+		p.Metadata = make(map[string]string)
 	}
-	if pctx.Metadata.Height == 0 {
-		pctx.Metadata.Height = pctx.Height
+	if m.CameraModel != "" {
+		p.Metadata["cameraModel"] = m.CameraModel
+	}
+	if m.DateTaken != "" {
+		p.Metadata["dateTaken"] = m.DateTaken
+	}
+	if m.ISO > 0 {
+		p.Metadata["iso"] = fmt.Sprintf("%d", m.ISO)
+	}
+	if m.Aperture != "" {
+		p.Metadata["aperture"] = m.Aperture
+	}
+	if m.ShutterSpeed != "" {
+		p.Metadata["shutterSpeed"] = m.ShutterSpeed
+	}
+	if m.FocalLength != "" {
+		p.Metadata["focalLength"] = m.FocalLength
+	}
+	if m.GPS != nil {
+		gpsData, _ := json.Marshal(m.GPS)
+		p.Metadata["gps"] = string(gpsData)
 	}
-
-	return h.HandleNext(pctx)
 }
 
 // ThumbnailHandler generates a thumbnail.
 type ThumbnailHandler struct {
 	BaseHandler
 	processor *image.Processor
+	bucket    string
 }
 
-// NewThumbnailHandler creates a new thumbnail handler.
-func NewThumbnailHandler(processor *image.Processor) *ThumbnailHandler {
-	return &ThumbnailHandler{processor: processor}
+// NewThumbnailHandler creates a new thumbnail handler storing thumbnails in bucket.
+func NewThumbnailHandler(processor *image.Processor, bucket string) *ThumbnailHandler {
+	return &ThumbnailHandler{processor: processor, bucket: bucket}
 }
 
 // Handle generates a thumbnail image.
 func (h *ThumbnailHandler) Handle(pctx *ProcessingContext) error {
 	log.Printf("[ThumbnailHandler] Generating thumbnail for photo %s", pctx.PhotoID)
 
-	data, err := h.processor.GenerateThumbnail(bytes.NewReader(pctx.ImageData))
+	data, err := h.processor.GenerateThumbnailFrom(pctx.Image, photo.ThumbnailStrategy(pctx.Gallery, pctx.Photo))
 	if err != nil {
 		return fmt.Errorf("failed to generate thumbnail: %w", err)
 	}
 
 	pctx.ThumbnailData = data
+	key := s3key.ChangeExtension(pctx.ObjectKey, ".jpg")
+	pctx.Upload(h.bucket, key, data, "image/jpeg")
+	pctx.Record(func(p *repository.Photo) {
+		p.ThumbnailKey = key
+		p.ThumbnailSize = int64(len(data))
+	})
+	return h.HandleNext(pctx)
+}
+
+// PlaceholderHandler computes the placeholder clients show while the photo loads.
+type PlaceholderHandler struct {
+	BaseHandler
+}
+
+// NewPlaceholderHandler creates a new placeholder handler.
+func NewPlaceholderHandler() *PlaceholderHandler {
+	return &PlaceholderHandler{}
+}
+
+// Handle computes the BlurHash and dominant color.
+func (h *PlaceholderHandler) Handle(pctx *ProcessingContext) error {
+	placeholder := image.NewPlaceholder(pctx.Image)
+	pctx.Placeholder = &placeholder
+	pctx.Record(func(p *repository.Photo) { setPlaceholder(p, &placeholder) })
 	return h.HandleNext(pctx)
 }
 
-// OptimizedHandler generates an optimized version with optional watermark.
+// OptimizedHandler generates an optimized version, made as the gallery's processing profile
+// says or, without one, resized and watermarked if the gallery asks.
 type OptimizedHandler struct {
 	BaseHandler
 	processor *image.Processor
+	bucket    string
 }
 
-// NewOptimizedHandler creates a new optimized handler.
-func NewOptimizedHandler(processor *image.Processor) *OptimizedHandler {
-	return &OptimizedHandler{processor: processor}
+// NewOptimizedHandler creates a new optimized handler storing optimized versions in bucket.
+func NewOptimizedHandler(processor *image.Processor, bucket string) *OptimizedHandler {
+	return &OptimizedHandler{processor: processor, bucket: bucket}
 }
 
 // Handle generates an optimized version of the image.
 func (h *OptimizedHandler) Handle(pctx *ProcessingContext) error {
 	log.Printf("[OptimizedHandler] Generating optimized version for photo %s", pctx.PhotoID)
 
-	// Build processing strategy chain
-	strategies := []image.ProcessingStrategy{
-		image.NewResizeStrategy(),
+	// The plan decides how large an image clients get
+	resize := image.NewResizeStrategy()
+	if e := pctx.Entitlements; e.RenditionMaxWidth > 0 && e.RenditionMaxHeight > 0 {
+		resize.MaxWidth, resize.MaxHeight = e.RenditionMaxWidth, e.RenditionMaxHeight
 	}
 
-	// Add watermark if gallery has watermark enabled
-	if pctx.Gallery != nil && pctx.Gallery.EnableWatermark && pctx.Gallery.WatermarkText != "" {
-		position := pctx.Gallery.WatermarkPosition
-		if position == "" {
-			position = "bottom-right"
-		}
-		strategies = append(strategies, image.NewWatermarkStrategy(pctx.Gallery.WatermarkText, position))
+	// Build the strategy chain from the gallery's profile, or add its watermark if enabled
+	strategies := []image.ProcessingStrategy{resize}
+	var encoder image.Encoder = image.NewJPEGEncoder(85)
+	if p := pctx.Profile; p != nil {
+		// A profile's own resizing can make the image smaller than the plan allows, not larger
+		strategies = append(strategies, profile.Strategies(p, pctx.Gallery, pctx.WatermarkLogo)...)
+		encoder = profileEncoder(p)
+	} else {
+		strategies = append(strategies, watermark.Strategies(pctx.Gallery, pctx.WatermarkLogo)...)
 	}
 
 	// Process using strategy chain
-	processor := image.NewImageProcessor(image.NewJPEGEncoder(85))
-	data, err := processor.ProcessWithChain(bytes.NewReader(pctx.ImageData), strategies...)
+	processor := image.NewImageProcessor(encoder)
+	data, err := processor.ProcessImage(pctx.Image, image.NewStrategyChain(strategies...))
 	if err != nil {
 		return fmt.Errorf("failed to generate optimized: %w", err)
 	}
 
 	pctx.OptimizedData = data
+	key := s3key.ChangeExtension(pctx.ObjectKey, image.Extension(encoder.Format()))
+	pctx.Upload(h.bucket, key, data, image.ContentType(encoder.Format()))
+	pctx.Record(func(p *repository.Photo) {
+		p.OptimizedKey = key
+		p.OptimizedSize = int64(len(data))
+	})
 	return h.HandleNext(pctx)
 }
 
-// UploadHandler uploads processed images to S3.
-type UploadHandler struct {
+// profileEncoder returns the encoder of a gallery's profile. Profiles are validated when
+// they're saved, but a format may have no encoder in this build, e.g. webp without cgo; those
+// photos are encoded as JPEG rather than failing.
+func profileEncoder(p *repository.ProcessingProfile) image.Encoder {
+	encoder, err := profile.Encoder(p)
+	if err != nil {
+		log.Printf("[OptimizedHandler] Encoding as JPEG, profile %q: %v", p.Name, err)
+		return image.NewJPEGEncoder(profile.DefaultQuality)
+	}
+	return encoder
+}
+
+// RenditionsHandler generates the configured responsive renditions.
+type RenditionsHandler struct {
 	BaseHandler
-	s3Client       S3Uploader
-	thumbnailBucket string
-	optimizedBucket string
+	processor *image.Processor
+	specs     []image.RenditionSpec
+	encoders  []image.Encoder
+	bucket    string
 }
 
-// NewUploadHandler creates a new upload handler.
-func NewUploadHandler(s3Client S3Uploader, thumbnailBucket, optimizedBucket string) *UploadHandler {
-	return &UploadHandler{
-		s3Client:        s3Client,
-		thumbnailBucket: thumbnailBucket,
-		optimizedBucket: optimizedBucket,
+// NewRenditionsHandler creates a new renditions handler storing renditions in bucket. Each
+// rendition is encoded with every encoder; the first should be JPEG so every browser has a
+// fallback. Without encoders, renditions are only JPEG.
+func NewRenditionsHandler(processor *image.Processor, specs []image.RenditionSpec, encoders []image.Encoder, bucket string) *RenditionsHandler {
+	if len(encoders) == 0 {
+		encoders = []image.Encoder{image.NewJPEGEncoder(85)}
 	}
+	return &RenditionsHandler{processor: processor, specs: specs, encoders: encoders, bucket: bucket}
 }
 
-// Handle uploads thumbnail and optimized images to S3.
-func (h *UploadHandler) Handle(pctx *ProcessingContext) error {
-	thumbnailKey := s3key.ChangeExtension(pctx.ObjectKey, ".jpg")
-	optimizedKey := s3key.ChangeExtension(pctx.ObjectKey, ".jpg")
+// Handle generates a rendition for each configured width the image is large enough for, up to
+// the largest the plan allows.
+func (h *RenditionsHandler) Handle(pctx *ProcessingContext) error {
+	log.Printf("[RenditionsHandler] Generating renditions for photo %s", pctx.PhotoID)
 
-	// Upload thumbnail
-	log.Printf("[UploadHandler] Uploading thumbnail to %s/%s", h.thumbnailBucket, thumbnailKey)
-	if err := h.s3Client.Upload(pctx.ctx, h.thumbnailBucket, thumbnailKey, pctx.ThumbnailData, "image/jpeg"); err != nil {
-		return fmt.Errorf("failed to upload thumbnail: %w", err)
+	renditions, err := h.processor.GenerateRenditions(pctx.Image, h.specs, pctx.Entitlements.RenditionMaxWidth,
+		h.encoders, watermark.Strategies(pctx.Gallery, pctx.WatermarkLogo)...)
+	if err != nil {
+		return fmt.Errorf("failed to generate renditions: %w", err)
 	}
 
-	// Upload optimized
-	log.Printf("[UploadHandler] Uploading optimized to %s/%s", h.optimizedBucket, optimizedKey)
-	if err := h.s3Client.Upload(pctx.ctx, h.optimizedBucket, optimizedKey, pctx.OptimizedData, "image/jpeg"); err != nil {
-		return fmt.Errorf("failed to upload optimized: %w", err)
+	// Renditions, in every format, are stored next to the optimized version
+	pctx.Renditions = renditions
+	records := renditionRecords(pctx)
+	for i, record := range records {
+		pctx.Upload(h.bucket, record.Key, renditions[i].Data, image.ContentType(renditions[i].Format))
+		for j, format := range record.Formats {
+			pctx.Upload(h.bucket, format.Key, renditions[i].Alternates[j].Data, image.ContentType(format.Format))
+		}
 	}
+	pctx.Record(func(p *repository.Photo) { p.Renditions = records })
+	return h.HandleNext(pctx)
+}
 
+// renditionRecords describes the generated renditions as stored on the photo record.
+func renditionRecords(pctx *ProcessingContext) []repository.Rendition {
+	records := make([]repository.Rendition, 0, len(pctx.Renditions))
+	for _, r := range pctx.Renditions {
+		record := repository.Rendition{
+			Name:   r.Name,
+			Key:    s3key.RenditionKey(pctx.GalleryID, pctx.PhotoID, r.Name, image.Extension(r.Format)),
+			Width:  r.Width,
+			Height: r.Height,
+			Size:   int64(len(r.Data)),
+		}
+		for _, alt := range r.Alternates {
+			record.Formats = append(record.Formats, repository.RenditionFormat{
+				Format: alt.Format,
+				Key:    s3key.RenditionKey(pctx.GalleryID, pctx.PhotoID, r.Name, image.Extension(alt.Format)),
+				Size:   int64(len(alt.Data)),
+			})
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// UploadHandler uploads the derivatives the stages before it made to S3.
+type UploadHandler struct {
+	BaseHandler
+	s3Client S3Uploader
+}
+
+// NewUploadHandler creates a new upload handler.
+func NewUploadHandler(s3Client S3Uploader) *UploadHandler {
+	return &UploadHandler{s3Client: s3Client}
+}
+
+// Handle uploads every queued derivative, in the order they were made.
+func (h *UploadHandler) Handle(pctx *ProcessingContext) error {
+	for _, u := range pctx.uploads {
+		log.Printf("[UploadHandler] Uploading to %s/%s", u.bucket, u.key)
+		if err := h.s3Client.Upload(pctx.ctx, u.bucket, u.key, u.data, u.contentType); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", u.key, err)
+		}
+	}
 	return h.HandleNext(pctx)
 }
 
-// DatabaseUpdateHandler updates the photo record in the database.
+// maxRecordAttempts is how many times a photo's record is read and written before giving up on
+// concurrent changes to it
+const maxRecordAttempts = 3
+
+// DatabaseUpdateHandler updates the photo record in the database with what the stages before
+// it recorded, and counts the bytes stored towards the photographer's quota.
 type DatabaseUpdateHandler struct {
 	BaseHandler
 	photoRepo   repository.PhotoRepository
 	galleryRepo repository.GalleryRepository
+	quota       *quota.Service
 }
 
-// NewDatabaseUpdateHandler creates a new database update handler.
-func NewDatabaseUpdateHandler(photoRepo repository.PhotoRepository, galleryRepo repository.GalleryRepository) *DatabaseUpdateHandler {
+// NewDatabaseUpdateHandler creates a new database update handler. Without a quota service the
+// bytes stored aren't counted.
+func NewDatabaseUpdateHandler(photoRepo repository.PhotoRepository, galleryRepo repository.GalleryRepository, quota *quota.Service) *DatabaseUpdateHandler {
 	return &DatabaseUpdateHandler{
 		photoRepo:   photoRepo,
 		galleryRepo: galleryRepo,
+		quota:       quota,
 	}
 }
 
-// Handle updates the database with processed photo information.
+// Handle updates the database with processed photo information. Writes are conditional, so
+// when deliveries of the same photo run concurrently only one creates the record and counts
+// the original, and only one records each version's derivatives; the others read the photo
+// again.
 func (h *DatabaseUpdateHandler) Handle(pctx *ProcessingContext) error {
 	log.Printf("[DatabaseUpdateHandler] Updating database for photo %s", pctx.PhotoID)
 
-	// Get or create photo record
-	photo, err := h.photoRepo.GetByID(pctx.ctx, pctx.PhotoID)
+	var storedDelta int64
+	var err error
+	for attempt := 1; ; attempt++ {
+		var record *repository.Photo
+		var created bool
+		record, created, err = h.getOrCreatePhoto(pctx)
+		if err == nil {
+			if created {
+				storedDelta += record.Size
+			}
+			var delta int64
+			delta, err = h.recordResults(pctx, record)
+			storedDelta += delta
+			if err == nil {
+				pctx.Photo = record
+			}
+		}
+		if !errors.Is(err, repository.ErrConflict) || attempt == maxRecordAttempts {
+			break
+		}
+		log.Printf("[DatabaseUpdateHandler] Photo %s changed while being recorded, reading it again", pctx.PhotoID)
+	}
+
+	// Bytes are counted even when recording fails part way, if this delivery created the photo
+	if pctx.Gallery != nil {
+		h.quota.Record(pctx.ctx, pctx.Gallery.PhotographerID, storedDelta)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get photo: %w", err)
+		return err
 	}
+	return h.HandleNext(pctx)
+}
 
-	if photo == nil {
-		// Create new photo record
-		key, _ := s3key.Parse(pctx.ObjectKey)
-		photo = &repository.Photo{
-			PhotoID:          pctx.PhotoID,
-			GalleryID:        pctx.GalleryID,
-			FileName:         key.FileName,
-			OriginalKey:      pctx.ObjectKey,
-			OptimizedKey:     s3key.ChangeExtension(pctx.ObjectKey, ".jpg"),
-			ThumbnailKey:     s3key.ChangeExtension(pctx.ObjectKey, ".jpg"),
-			MimeType:         s3key.GetMimeType(key.Extension),
-			Size:             int64(len(pctx.ImageData)),
-			Width:            pctx.Width,
-			Height:           pctx.Height,
-			ProcessingStatus: "completed",
-		}
+// getOrCreatePhoto returns the photo's record, creating it if this is the first delivery to see
+// the photo. Only the delivery that creates it adds it to its gallery's counts. ErrConflict
+// means another delivery created it first.
+func (h *DatabaseUpdateHandler) getOrCreatePhoto(pctx *ProcessingContext) (*repository.Photo, bool, error) {
+	record, err := h.photoRepo.GetByID(pctx.ctx, pctx.PhotoID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get photo: %w", err)
+	}
+	if record != nil {
+		return record, false, nil
+	}
 
-		if err := h.photoRepo.Create(pctx.ctx, photo); err != nil {
-			return fmt.Errorf("failed to create photo: %w", err)
+	record = &repository.Photo{
+		PhotoID:          pctx.PhotoID,
+		GalleryID:        pctx.GalleryID,
+		FileName:         pctx.key.FileName,
+		OriginalKey:      pctx.ObjectKey,
+		MimeType:         s3key.GetMimeType(pctx.key.Extension),
+		Size:             pctx.Size,
+		Width:            pctx.Width,
+		Height:           pctx.Height,
+		UploadedAt:       time.Now(),
+		ProcessingStatus: "processing",
+		Metadata:         make(map[string]string),
+	}
+	if pctx.Gallery != nil {
+		record.PhotographerID = pctx.Gallery.PhotographerID
+	}
+	writeCtx, err := repository.WithOutboxEvents(pctx.ctx, events.NewEvent(events.PhotoUploaded, &events.PhotoUploadedPayload{
+		PhotoID:   record.PhotoID,
+		GalleryID: record.GalleryID,
+		FileName:  record.FileName,
+		Size:      record.Size,
+	}, record.GalleryID))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record photo events: %w", err)
+	}
+	if err := h.photoRepo.Create(writeCtx, record); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, false, fmt.Errorf("failed to create photo: %w", repository.ErrConflict)
 		}
+		return nil, false, fmt.Errorf("failed to create photo: %w", err)
+	}
 
-		// Update gallery photo count
-		if err := h.galleryRepo.UpdatePhotoCount(pctx.ctx, pctx.GalleryID, 1); err != nil {
-			log.Printf("[DatabaseUpdateHandler] Warning: Failed to update photo count: %v", err)
-		}
-	} else {
-		// Update existing photo
-		photo.ProcessingStatus = "completed"
-		photo.Width = pctx.Width
-		photo.Height = pctx.Height
-		photo.OptimizedKey = s3key.ChangeExtension(pctx.ObjectKey, ".jpg")
-		photo.ThumbnailKey = s3key.ChangeExtension(pctx.ObjectKey, ".jpg")
-
-		if err := h.photoRepo.Update(pctx.ctx, photo); err != nil {
-			return fmt.Errorf("failed to update photo: %w", err)
-		}
+	if err := h.galleryRepo.UpdatePhotoCount(pctx.ctx, pctx.GalleryID, 1); err != nil {
+		log.Printf("[DatabaseUpdateHandler] Warning: Failed to update photo count: %v", err)
+	}
+	if err := h.galleryRepo.UpdateTotalSize(pctx.ctx, pctx.GalleryID, record.Size); err != nil {
+		log.Printf("[DatabaseUpdateHandler] Warning: Failed to update total size: %v", err)
 	}
+	return record, true, nil
+}
 
-	pctx.Photo = photo
-	return h.HandleNext(pctx)
+// recordResults makes the recorded changes to the photo, marks it completed and returns the
+// change in bytes stored for its derivatives. ErrConflict means the photo changed after it was
+// read.
+func (h *DatabaseUpdateHandler) recordResults(pctx *ProcessingContext, record *repository.Photo) (int64, error) {
+	// A concurrent delivery of the same original got there first
+	if pctx.SourceVersion != "" && record.ProcessingStatus == "completed" && record.SourceVersion == pctx.SourceVersion {
+		return 0, nil
+	}
+
+	// Reprocessing replaces derivatives, so only the difference is newly stored
+	before := quota.PhotoBytes(record)
+	for _, change := range pctx.changes {
+		change(record)
+	}
+	record.SourceVersion = pctx.SourceVersion
+	record.Width = pctx.Width
+	record.Height = pctx.Height
+	record.ProcessingStatus = "completed"
+	record.Failure = nil
+	now := time.Now()
+	record.ProcessedAt = &now
+
+	writeCtx, err := repository.WithOutboxEvents(pctx.ctx, processedEvent(record))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record photo events: %w", err)
+	}
+	if err := h.photoRepo.Update(writeCtx, record); err != nil {
+		return 0, fmt.Errorf("failed to update photo: %w", err)
+	}
+	return quota.PhotoBytes(record) - before, nil
 }
 
-// ProcessingPipeline builds the complete photo processing chain.
-type ProcessingPipeline struct {
-	firstHandler ProcessingHandler
-}
-
-// NewProcessingPipeline creates a new processing pipeline with all handlers.
-func NewProcessingPipeline(
-	s3Downloader S3Downloader,
-	s3Uploader S3Uploader,
-	processor *image.Processor,
-	photoRepo repository.PhotoRepository,
-	galleryRepo repository.GalleryRepository,
-	thumbnailBucket, optimizedBucket string,
-) *ProcessingPipeline {
-	// Build the chain
-	download := NewDownloadHandler(s3Downloader)
-	dimensions := NewDimensionsHandler(processor)
-	metadata := NewMetadataHandler(processor)
-	thumbnail := NewThumbnailHandler(processor)
-	optimized := NewOptimizedHandler(processor)
-	upload := NewUploadHandler(s3Uploader, thumbnailBucket, optimizedBucket)
-	dbUpdate := NewDatabaseUpdateHandler(photoRepo, galleryRepo)
-
-	// Chain them together
-	download.SetNext(dimensions).
-		SetNext(metadata).
-		SetNext(thumbnail).
-		SetNext(optimized).
-		SetNext(upload).
-		SetNext(dbUpdate)
-
-	return &ProcessingPipeline{firstHandler: download}
-}
-
-// Process runs the photo through the processing pipeline.
-func (p *ProcessingPipeline) Process(ctx context.Context, photoID, galleryID, objectKey, bucketName string, gallery *repository.Gallery) error {
-	pctx := NewProcessingContext(ctx, photoID, galleryID, objectKey, bucketName)
-	pctx.Gallery = gallery
-	return p.firstHandler.Handle(pctx)
+// setPlaceholder stores a generated placeholder on the photo, keeping any previous one when
+// generation failed
+func setPlaceholder(photo *repository.Photo, placeholder *image.Placeholder) {
+	if placeholder == nil {
+		return
+	}
+	photo.BlurHash = placeholder.BlurHash
+	photo.DominantColor = placeholder.DominantColor
+}
+
+// processedEvent builds the PhotoProcessed event for a photo's current state.
+func processedEvent(photo *repository.Photo) events.Event {
+	return events.NewEvent(events.PhotoProcessed, &events.PhotoProcessedPayload{
+		PhotoID:   photo.PhotoID,
+		GalleryID: photo.GalleryID,
+		Status:    photo.ProcessingStatus,
+		Width:     photo.Width,
+		Height:    photo.Height,
+	}, photo.GalleryID)
 }
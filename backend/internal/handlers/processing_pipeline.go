@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/quota"
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/pkg/utils/s3key"
+)
+
+// Stages a pipeline can be configured with. They run after the original is read and the
+// gallery loaded, and before the derivatives they make are uploaded and the photo recorded.
+const (
+	StageMetadata    = "metadata"
+	StageThumbnail   = "thumbnail"
+	StagePlaceholder = "placeholder"
+	StageOptimized   = "optimized"
+	StageRenditions  = "renditions"
+)
+
+// DefaultStages are the stages of a pipeline not configured with others, in order.
+var DefaultStages = []string{StageMetadata, StageThumbnail, StagePlaceholder, StageOptimized, StageRenditions}
+
+// StageFactory makes the handler for a stage of the given pipeline.
+type StageFactory func(p *ProcessingPipeline) ProcessingHandler
+
+var stages = map[string]StageFactory{
+	StageMetadata: func(p *ProcessingPipeline) ProcessingHandler { return NewMetadataHandler() },
+	StageThumbnail: func(p *ProcessingPipeline) ProcessingHandler {
+		return NewThumbnailHandler(p.processor, p.thumbnailBucket)
+	},
+	StagePlaceholder: func(p *ProcessingPipeline) ProcessingHandler { return NewPlaceholderHandler() },
+	StageOptimized: func(p *ProcessingPipeline) ProcessingHandler {
+		return NewOptimizedHandler(p.processor, p.optimizedBucket)
+	},
+	StageRenditions: func(p *ProcessingPipeline) ProcessingHandler {
+		return NewRenditionsHandler(p.processor, p.renditions, p.encoders, p.optimizedBucket)
+	},
+}
+
+// RegisterStage makes a stage available to pipelines by name, replacing any stage already
+// registered with it. Stages are registered when the program starts, before pipelines are
+// configured. A stage passes what it makes to the stages after it: derivatives queued with
+// ProcessingContext.Upload are uploaded, and changes queued with ProcessingContext.Record
+// are written to the photo.
+func RegisterStage(name string, factory StageFactory) {
+	stages[name] = factory
+}
+
+// ProcessingPipeline builds the complete photo processing chain: the original is downloaded,
+// its gallery loaded, the configured stages run, then their derivatives are uploaded and the
+// photo recorded.
+type ProcessingPipeline struct {
+	downloader      S3Downloader
+	uploader        S3Uploader
+	processor       *image.Processor
+	photoRepo       repository.PhotoRepository
+	galleryRepo     repository.GalleryRepository
+	thumbnailBucket string
+	optimizedBucket string
+	renditions      []image.RenditionSpec
+	encoders        []image.Encoder
+	logos           *watermark.LogoLoader
+	plans           *plan.Service
+	profiles        repository.ProcessingProfileRepository
+	quota           *quota.Service
+	stages          []StageFactory
+}
+
+// NewProcessingPipeline creates a new processing pipeline with the default stages.
+func NewProcessingPipeline(
+	s3Downloader S3Downloader,
+	s3Uploader S3Uploader,
+	processor *image.Processor,
+	photoRepo repository.PhotoRepository,
+	galleryRepo repository.GalleryRepository,
+	thumbnailBucket, optimizedBucket string,
+	renditions []image.RenditionSpec,
+	encoders []image.Encoder,
+) *ProcessingPipeline {
+	p := &ProcessingPipeline{
+		downloader:      s3Downloader,
+		uploader:        s3Uploader,
+		processor:       processor,
+		photoRepo:       photoRepo,
+		galleryRepo:     galleryRepo,
+		thumbnailBucket: thumbnailBucket,
+		optimizedBucket: optimizedBucket,
+		renditions:      renditions,
+		encoders:        encoders,
+	}
+	for _, name := range DefaultStages {
+		p.stages = append(p.stages, stages[name])
+	}
+	return p
+}
+
+// WithLogos lets the pipeline watermark photos of galleries that use their photographer's logo.
+func (p *ProcessingPipeline) WithLogos(logos *watermark.LogoLoader) *ProcessingPipeline {
+	p.logos = logos
+	return p
+}
+
+// WithPlans sizes photos' derivatives by their photographer's plan, rather than the free plan.
+func (p *ProcessingPipeline) WithPlans(plans *plan.Service) *ProcessingPipeline {
+	p.plans = plans
+	return p
+}
+
+// WithProfiles makes photos of galleries that use a processing profile with the profile's
+// steps and output.
+func (p *ProcessingPipeline) WithProfiles(profiles repository.ProcessingProfileRepository) *ProcessingPipeline {
+	p.profiles = profiles
+	return p
+}
+
+// WithQuota counts the bytes each photo stores towards its photographer's storage.
+func (p *ProcessingPipeline) WithQuota(quota *quota.Service) *ProcessingPipeline {
+	p.quota = quota
+	return p
+}
+
+// WithStages replaces the pipeline's stages with the named ones, run in the order given. It
+// fails for names no stage is registered with.
+func (p *ProcessingPipeline) WithStages(names ...string) (*ProcessingPipeline, error) {
+	factories := make([]StageFactory, 0, len(names))
+	for _, name := range names {
+		factory, ok := stages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown processing stage %q", name)
+		}
+		factories = append(factories, factory)
+	}
+	p.stages = factories
+	return p, nil
+}
+
+// chain links a new set of handlers for one photo
+func (p *ProcessingPipeline) chain() ProcessingHandler {
+	first := NewDownloadHandler(p.downloader, p.processor)
+	last := first.SetNext(NewGalleryHandler(p.photoRepo, p.galleryRepo, p.logos, p.plans, p.profiles))
+	for _, stage := range p.stages {
+		last = last.SetNext(stage(p))
+	}
+	last.SetNext(NewUploadHandler(p.uploader)).
+		SetNext(NewDatabaseUpdateHandler(p.photoRepo, p.galleryRepo, p.quota))
+	return first
+}
+
+// Process runs the photo whose original is at bucket/objectKey through the pipeline. version is
+// the version of the original a notification was for, if known; photos already processed from
+// it are skipped. Failures are recorded on the photo: one that may pass, such as throttling,
+// leaves it processing for the caller to retry, and any other marks it failed.
+func (p *ProcessingPipeline) Process(ctx context.Context, key *s3key.Key, bucket, objectKey, version string) error {
+	// Redelivered messages and duplicate notifications of an original already processed are
+	// acknowledged without doing the work again
+	if p.isProcessed(ctx, key.PhotoID, version) {
+		log.Printf("[ProcessingPipeline] Skipping %s, this version of it is already processed", objectKey)
+		return nil
+	}
+
+	// Photos already recorded, such as those being reprocessed, show they have been picked up
+	p.markProcessing(ctx, key.PhotoID)
+	err := p.chain().Handle(NewProcessingContext(ctx, key, objectKey, bucket))
+	if err != nil {
+		p.recordFailure(ctx, key.PhotoID, err, photo.IsTransient(err))
+	}
+	return err
+}
+
+// isProcessed reports whether the photo's derivatives were already made from this version of
+// its original. Photos queued for reprocessing are pending, so they're processed again.
+func (p *ProcessingPipeline) isProcessed(ctx context.Context, photoID, version string) bool {
+	if version == "" {
+		return false
+	}
+	existing, err := p.photoRepo.GetByID(ctx, photoID)
+	return err == nil && existing != nil && existing.ProcessingStatus == "completed" && existing.SourceVersion == version
+}
+
+func (p *ProcessingPipeline) markProcessing(ctx context.Context, photoID string) {
+	record, err := p.photoRepo.GetByID(ctx, photoID)
+	if err != nil || record == nil {
+		return
+	}
+	record.ProcessingStatus = "processing"
+	p.photoRepo.Update(ctx, record)
+}
+
+// recordFailure adds a failed attempt to the photo's failure history. Unless the photo is
+// being retried it's also marked failed, for the photographer to triage. A photo that kept
+// changing while being recorded and is now completed was finished by another delivery, so
+// nothing is recorded over it.
+func (p *ProcessingPipeline) recordFailure(ctx context.Context, photoID string, err error, retrying bool) {
+	record, getErr := p.photoRepo.GetByID(ctx, photoID)
+	if getErr != nil || record == nil {
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) && record.ProcessingStatus == "completed" {
+		log.Printf("[ProcessingPipeline] Not recording failure of %s, another delivery completed it", photoID)
+		return
+	}
+	if record.PhotographerID == "" {
+		// Photos stored before their owner was recorded need it to be listed as failed
+		if g, galleryErr := p.galleryRepo.GetByID(ctx, record.GalleryID); galleryErr == nil && g != nil {
+			record.PhotographerID = g.PhotographerID
+		}
+	}
+	now := time.Now()
+	photo.RecordFailure(record, photo.Classify(err), err.Error(), now)
+	if !retrying {
+		record.ProcessingStatus = "failed"
+		record.ProcessedAt = &now
+		ctx, _ = repository.WithOutboxEvents(ctx, processedEvent(record))
+	}
+	if updateErr := p.photoRepo.Update(ctx, record); updateErr != nil {
+		log.Printf("[ProcessingPipeline] Failed to record failure of %s: %v", photoID, updateErr)
+	}
+}
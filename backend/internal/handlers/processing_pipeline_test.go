@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"photographer-gallery/backend/internal/domain/photo"
+	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/domain/plan"
+	"photographer-gallery/backend/internal/domain/watermark"
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/services/image"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/utils/s3key"
+)
+
+const testObjectKey = "gal_abc123/photo_xyz789/original.jpg"
+
+// memoryS3 serves originals and keeps uploads in memory
+type memoryS3 struct {
+	objects map[string][]byte
+}
+
+func (s *memoryS3) DownloadVersion(ctx context.Context, bucket, key string) (io.ReadCloser, string, error) {
+	data, ok := s.objects[bucket+"/"+key]
+	if !ok {
+		return nil, "", fmt.Errorf("no such key %s/%s", bucket, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), "v1", nil
+}
+
+func (s *memoryS3) Upload(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	s.objects[bucket+"/"+key] = data
+	return nil
+}
+
+func newTestPipeline(t *testing.T) (*ProcessingPipeline, *memoryS3, *mocks.MockPhotoRepository) {
+	t.Helper()
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, 900, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 900; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &memoryS3{objects: map[string][]byte{"originals/" + testObjectKey: buf.Bytes()}}
+	photos := mocks.NewMockPhotoRepository()
+	galleries := mocks.NewMockGalleryRepository()
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_abc123", PhotographerID: "user_1"})
+	pipeline := NewProcessingPipeline(storage, storage, image.NewProcessor(), photos, galleries,
+		"thumbnails", "optimized", image.DefaultRenditions, nil)
+	return pipeline, storage, photos
+}
+
+func process(t *testing.T, p *ProcessingPipeline) error {
+	t.Helper()
+	key, err := s3key.Parse(testObjectKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Process(context.Background(), key, "originals", testObjectKey, "")
+}
+
+func TestProcessingPipelineRecordsPhoto(t *testing.T) {
+	pipeline, storage, photos := newTestPipeline(t)
+	if err := process(t, pipeline); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	stored, _ := photos.GetByID(context.Background(), "photo_xyz789")
+	if stored == nil || stored.ProcessingStatus != "completed" || stored.ProcessedAt == nil {
+		t.Fatalf("photo = %+v, want it completed with ProcessedAt set", stored)
+	}
+	if stored.SourceVersion != "v1" || stored.Width != 900 || stored.Height != 600 {
+		t.Errorf("photo is %dx%d from version %q, want 900x600 from v1", stored.Width, stored.Height, stored.SourceVersion)
+	}
+	if stored.BlurHash == "" || stored.DominantColor == "" {
+		t.Error("placeholder not recorded")
+	}
+	for bucket, key := range map[string]string{"thumbnails": stored.ThumbnailKey, "optimized": stored.OptimizedKey} {
+		if key == "" || storage.objects[bucket+"/"+key] == nil {
+			t.Errorf("derivative %q not uploaded to %s", key, bucket)
+		}
+	}
+	if len(stored.Renditions) == 0 {
+		t.Fatal("no renditions recorded")
+	}
+	for _, r := range stored.Renditions {
+		if int64(len(storage.objects["optimized/"+r.Key])) != r.Size {
+			t.Errorf("rendition %s recorded as %d bytes, not the %d uploaded", r.Name, r.Size, len(storage.objects["optimized/"+r.Key]))
+		}
+	}
+}
+
+func TestRecordFailureAfterConflicts(t *testing.T) {
+	pipeline, _, photos := newTestPipeline(t)
+	ctx := context.Background()
+	conflict := fmt.Errorf("failed to record photo: %w", repository.ErrConflict)
+
+	// Another delivery completed the photo while this one kept conflicting with it
+	photos.AddPhoto(&repository.Photo{PhotoID: "photo_done", ProcessingStatus: "completed"})
+	pipeline.recordFailure(ctx, "photo_done", conflict, false)
+	if stored, _ := photos.GetByID(ctx, "photo_done"); stored.ProcessingStatus != "completed" || stored.Failure != nil {
+		t.Errorf("photo = %s with failure %+v, want it left completed", stored.ProcessingStatus, stored.Failure)
+	}
+
+	photos.AddPhoto(&repository.Photo{PhotoID: "photo_busy", ProcessingStatus: "processing"})
+	pipeline.recordFailure(ctx, "photo_busy", conflict, false)
+	if stored, _ := photos.GetByID(ctx, "photo_busy"); stored.ProcessingStatus != "failed" || stored.Failure == nil {
+		t.Errorf("photo = %s with failure %+v, want it failed", stored.ProcessingStatus, stored.Failure)
+	}
+}
+
+func TestProcessingPipelineRetriesUnreadablePlan(t *testing.T) {
+	pipeline, _, photos := newTestPipeline(t)
+	ctx := context.Background()
+	photos.AddPhoto(&repository.Photo{PhotoID: "photo_xyz789", GalleryID: "gal_abc123", ProcessingStatus: "pending"})
+	accounts := mocks.NewMockPhotographerStore()
+	accounts.GetErr = &smithy.GenericAPIError{Code: "AccessDeniedException"}
+	pipeline.WithPlans(plan.NewService(accounts))
+
+	err := process(t, pipeline)
+	if !photo.IsTransient(err) {
+		t.Fatalf("Process() error = %v, want a transient error", err)
+	}
+	if stored, _ := photos.GetByID(ctx, "photo_xyz789"); stored.ProcessingStatus == "failed" || stored.OptimizedKey != "" {
+		t.Errorf("photo = %s with optimized %q, want it left for the retry", stored.ProcessingStatus, stored.OptimizedKey)
+	}
+
+	// Photographers without a profile are on the free plan
+	accounts.GetErr = nil
+	if err := process(t, pipeline); err != nil {
+		t.Fatalf("Process() for a photographer without a profile error = %v", err)
+	}
+}
+
+func TestProcessingPipelineLogoErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		getErr        error
+		logo          []byte
+		wantTransient bool
+		wantStatus    string
+	}{
+		{name: "photographer lookup fails", getErr: &smithy.GenericAPIError{Code: "AccessDeniedException"}, wantTransient: true, wantStatus: "processing"},
+		{name: "stored logo is corrupt", logo: []byte("not a png"), wantStatus: "failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, _, photos := newTestPipeline(t)
+			ctx := context.Background()
+			photos.AddPhoto(&repository.Photo{PhotoID: "photo_xyz789", GalleryID: "gal_abc123", ProcessingStatus: "pending"})
+			galleries := mocks.NewMockGalleryRepository()
+			galleries.AddGallery(&repository.Gallery{GalleryID: "gal_abc123", PhotographerID: "user_1",
+				EnableWatermark: true, WatermarkType: repository.WatermarkTypeLogo})
+			pipeline.galleryRepo = galleries
+
+			accounts := mocks.NewMockPhotographerStore()
+			accounts.AddPhotographer(&photographer.Photographer{UserID: "user_1", WatermarkLogoKey: "watermarks/user_1/logo.png"})
+			accounts.GetErr = tt.getErr
+			logos := mocks.NewMockStorageService()
+			logos.PutObject(mocks.LogoBucket, "watermarks/user_1/logo.png", tt.logo)
+			pipeline.WithLogos(watermark.NewLogoLoader(accounts, logos, mocks.LogoBucket))
+
+			err := process(t, pipeline)
+			if err == nil || photo.IsTransient(err) != tt.wantTransient {
+				t.Fatalf("Process() error = %v, want transient %v", err, tt.wantTransient)
+			}
+			// The photo is never published without its watermark
+			if stored, _ := photos.GetByID(ctx, "photo_xyz789"); stored.ProcessingStatus != tt.wantStatus || stored.OptimizedKey != "" {
+				t.Errorf("photo = %s with optimized %q, want %s and unpublished", stored.ProcessingStatus, stored.OptimizedKey, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMetadataHandlerRecordsEXIF(t *testing.T) {
+	photos := mocks.NewMockPhotoRepository()
+	key, _ := s3key.Parse(testObjectKey)
+	pctx := NewProcessingContext(context.Background(), key, testObjectKey, "originals")
+	pctx.Metadata = &image.ImageMetadata{CameraModel: "X100V", ISO: 400, Aperture: "f/2.0"}
+
+	chain := NewMetadataHandler()
+	chain.SetNext(NewDatabaseUpdateHandler(photos, mocks.NewMockGalleryRepository(), nil))
+	if err := chain.Handle(pctx); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	stored, _ := photos.GetByID(context.Background(), "photo_xyz789")
+	if stored.Metadata["cameraModel"] != "X100V" || stored.Metadata["iso"] != "400" || stored.Metadata["aperture"] != "f/2.0" {
+		t.Errorf("Metadata = %v, want the EXIF recorded", stored.Metadata)
+	}
+	if stored.ProcessedAt == nil {
+		t.Error("ProcessedAt not set")
+	}
+}
+
+func TestProcessingPipelineUsesGalleryProfile(t *testing.T) {
+	pipeline, storage, photos := newTestPipeline(t)
+	galleries := mocks.NewMockGalleryRepository()
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_abc123", PhotographerID: "user_1", ProcessingProfileID: "prof_archive"})
+	profiles := mocks.NewMockProcessingProfileRepository()
+	profiles.AddProfile(&repository.ProcessingProfile{ProfileID: "prof_archive", PhotographerID: "user_1", Name: "archive",
+		Format: image.FormatPNG, Steps: []repository.ProcessingStep{{Type: "resize", Width: 300}, {Type: "grayscale"}}})
+	pipeline.galleryRepo = galleries
+	pipeline.WithProfiles(profiles)
+	if err := process(t, pipeline); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	stored, _ := photos.GetByID(context.Background(), "photo_xyz789")
+	if !strings.HasSuffix(stored.OptimizedKey, ".png") {
+		t.Fatalf("OptimizedKey = %q, want a png", stored.OptimizedKey)
+	}
+	optimized, err := png.Decode(bytes.NewReader(storage.objects["optimized/"+stored.OptimizedKey]))
+	if err != nil {
+		t.Fatalf("optimized version isn't a png: %v", err)
+	}
+	if b := optimized.Bounds(); b.Dx() != 300 || b.Dy() != 200 {
+		t.Errorf("optimized version is %dx%d, want 300x200", b.Dx(), b.Dy())
+	}
+	if r, g, b, _ := optimized.At(150, 100).RGBA(); r != g || g != b {
+		t.Errorf("optimized version isn't grayscale: pixel is %d,%d,%d", r, g, b)
+	}
+}
+
+func TestProcessingPipelineWithoutGalleryProfile(t *testing.T) {
+	pipeline, _, photos := newTestPipeline(t)
+	galleries := mocks.NewMockGalleryRepository()
+	galleries.AddGallery(&repository.Gallery{GalleryID: "gal_abc123", PhotographerID: "user_1", ProcessingProfileID: "prof_deleted"})
+	pipeline.galleryRepo = galleries
+	pipeline.WithProfiles(mocks.NewMockProcessingProfileRepository())
+	if err := process(t, pipeline); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// A deleted profile leaves the gallery on the default processing
+	stored, _ := photos.GetByID(context.Background(), "photo_xyz789")
+	if !strings.HasSuffix(stored.OptimizedKey, ".jpg") {
+		t.Errorf("OptimizedKey = %q, want a jpg", stored.OptimizedKey)
+	}
+}
+
+func TestProcessingPipelineWithStages(t *testing.T) {
+	pipeline, _, _ := newTestPipeline(t)
+	if _, err := pipeline.WithStages(StageThumbnail, "sharpen"); err == nil || !strings.Contains(err.Error(), "sharpen") {
+		t.Errorf("WithStages() error = %v, want unknown stage sharpen", err)
+	}
+
+	// A stage added by configuration needs nothing more than registering
+	RegisterStage("checksum", func(p *ProcessingPipeline) ProcessingHandler { return &checksumHandler{} })
+	t.Cleanup(func() { delete(stages, "checksum") })
+
+	pipeline, storage, photos := newTestPipeline(t)
+	pipeline, err := pipeline.WithStages(StageThumbnail, "checksum")
+	if err != nil {
+		t.Fatalf("WithStages() error = %v", err)
+	}
+	if err := process(t, pipeline); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	stored, _ := photos.GetByID(context.Background(), "photo_xyz789")
+	if stored.Metadata["checksum"] != "abc" {
+		t.Errorf("Metadata = %v, want the checksum stage's change recorded", stored.Metadata)
+	}
+	if stored.ThumbnailKey == "" || stored.OptimizedKey != "" || len(stored.Renditions) != 0 {
+		t.Errorf("photo = %+v, want only a thumbnail", stored)
+	}
+	if len(storage.objects) != 3 {
+		t.Errorf("%d objects stored, want the original, thumbnail and checksum", len(storage.objects))
+	}
+	if string(storage.objects["optimized/checksum.txt"]) != "abc" {
+		t.Error("checksum stage's upload not stored")
+	}
+}
+
+// checksumHandler is a stage the pipeline doesn't know about
+type checksumHandler struct {
+	BaseHandler
+}
+
+func (h *checksumHandler) Handle(pctx *ProcessingContext) error {
+	pctx.Upload("optimized", "checksum.txt", []byte("abc"), "text/plain")
+	pctx.Record(func(p *repository.Photo) { p.Metadata["checksum"] = "abc" })
+	return h.HandleNext(pctx)
+}
@@ -0,0 +1,152 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// downloadJobRetention matches the lifetime of the archives themselves in S3.
+const downloadJobRetention = 7 * 24 * time.Hour
+
+type DownloadJobRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDownloadJobRepository(client *dynamodb.Client, tableName string) *DownloadJobRepository {
+	return &DownloadJobRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type downloadJobItem struct {
+	PK              string  `dynamodbav:"PK"`
+	SK              string  `dynamodbav:"SK"`
+	JobID           string  `dynamodbav:"jobId"`
+	GalleryID       string  `dynamodbav:"galleryId"`
+	SessionID       string  `dynamodbav:"sessionId"`
+	Scope           string  `dynamodbav:"scope"`
+	Variant         string  `dynamodbav:"variant"`
+	Status          string  `dynamodbav:"status"`
+	TotalPhotos     int     `dynamodbav:"totalPhotos"`
+	ProcessedPhotos int     `dynamodbav:"processedPhotos"`
+	ArchiveKey      string  `dynamodbav:"archiveKey"`
+	ArchiveName     string  `dynamodbav:"archiveName"`
+	ArchiveSize     int64   `dynamodbav:"archiveSize,omitempty"`
+	LastError       string  `dynamodbav:"lastError,omitempty"`
+	CreatedAt       string  `dynamodbav:"createdAt"`
+	UpdatedAt       string  `dynamodbav:"updatedAt"`
+	CompletedAt     *string `dynamodbav:"completedAt,omitempty"`
+	TTL             int64   `dynamodbav:"ttl"`
+}
+
+func (r *DownloadJobRepository) Create(ctx context.Context, job *repository.DownloadJob) error {
+	return r.put(ctx, job)
+}
+
+func (r *DownloadJobRepository) GetByID(ctx context.Context, jobID string) (*repository.DownloadJob, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("DOWNLOAD#%s", jobID)},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download job: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item downloadJobItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal download job: %w", err)
+	}
+
+	return itemToDownloadJob(&item), nil
+}
+
+func (r *DownloadJobRepository) Update(ctx context.Context, job *repository.DownloadJob) error {
+	return r.put(ctx, job)
+}
+
+func (r *DownloadJobRepository) put(ctx context.Context, job *repository.DownloadJob) error {
+	item := downloadJobItem{
+		PK:              fmt.Sprintf("DOWNLOAD#%s", job.JobID),
+		SK:              "METADATA",
+		JobID:           job.JobID,
+		GalleryID:       job.GalleryID,
+		SessionID:       job.SessionID,
+		Scope:           job.Scope,
+		Variant:         job.Variant,
+		Status:          job.Status,
+		TotalPhotos:     job.TotalPhotos,
+		ProcessedPhotos: job.ProcessedPhotos,
+		ArchiveKey:      job.ArchiveKey,
+		ArchiveName:     job.ArchiveName,
+		ArchiveSize:     job.ArchiveSize,
+		LastError:       job.LastError,
+		CreatedAt:       job.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       job.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		TTL:             job.CreatedAt.Add(downloadJobRetention).Unix(),
+	}
+
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+		item.CompletedAt = &completedAt
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal download job: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+
+	return err
+}
+
+func itemToDownloadJob(item *downloadJobItem) *repository.DownloadJob {
+	job := &repository.DownloadJob{
+		JobID:           item.JobID,
+		GalleryID:       item.GalleryID,
+		SessionID:       item.SessionID,
+		Scope:           item.Scope,
+		Variant:         item.Variant,
+		Status:          item.Status,
+		TotalPhotos:     item.TotalPhotos,
+		ProcessedPhotos: item.ProcessedPhotos,
+		ArchiveKey:      item.ArchiveKey,
+		ArchiveName:     item.ArchiveName,
+		ArchiveSize:     item.ArchiveSize,
+		LastError:       item.LastError,
+	}
+
+	if t, err := parseTime(item.CreatedAt); err == nil {
+		job.CreatedAt = t
+	}
+	if t, err := parseTime(item.UpdatedAt); err == nil {
+		job.UpdatedAt = t
+	}
+	if item.CompletedAt != nil {
+		if t, err := parseTime(*item.CompletedAt); err == nil {
+			job.CompletedAt = &t
+		}
+	}
+
+	return job
+}
@@ -12,9 +12,22 @@ import (
 	"photographer-gallery/backend/internal/repository"
 )
 
+// Items for one client session share a partition:
+//
+//	LIST#<listId>                   list metadata (name, timestamps)
+//	LIST#<listId>#PHOTO#<photoId>   a favorite in that list
+//	PHOTO#<photoId>                 a favorite saved before named lists, read as part of the default list
+//
+// Legacy items are rewritten into the default list by MigrateLegacyFavorites.
+const legacyFavoritePrefix = "PHOTO#"
+
+// batchWriteLimit is the maximum number of requests DynamoDB accepts in one BatchWriteItem call
+const batchWriteLimit = 25
+
 type FavoriteRepository struct {
 	client    *dynamodb.Client
 	tableName string
+	outbox    *OutboxRepository
 }
 
 func NewFavoriteRepository(client *dynamodb.Client, tableName string) *FavoriteRepository {
@@ -24,21 +37,64 @@ func NewFavoriteRepository(client *dynamodb.Client, tableName string) *FavoriteR
 	}
 }
 
+// WithOutbox makes entity writes persist any events staged on the context in the same transaction.
+func (r *FavoriteRepository) WithOutbox(outbox *OutboxRepository) *FavoriteRepository {
+	r.outbox = outbox
+	return r
+}
+
 type favoriteItem struct {
 	PK          string `dynamodbav:"PK"`
 	SK          string `dynamodbav:"SK"`
 	GalleryID   string `dynamodbav:"galleryId"`
 	SessionID   string `dynamodbav:"sessionId"`
+	ListID      string `dynamodbav:"listId,omitempty"`
 	PhotoID     string `dynamodbav:"photoId"`
 	FavoritedAt string `dynamodbav:"favoritedAt"`
 }
 
+type favoriteListItem struct {
+	PK        string `dynamodbav:"PK"`
+	SK        string `dynamodbav:"SK"`
+	ListID    string `dynamodbav:"listId"`
+	GalleryID string `dynamodbav:"galleryId"`
+	SessionID string `dynamodbav:"sessionId"`
+	Name      string `dynamodbav:"name"`
+	CreatedAt string `dynamodbav:"createdAt"`
+	UpdatedAt string `dynamodbav:"updatedAt"`
+}
+
+func favoritePK(galleryID, sessionID string) string {
+	return fmt.Sprintf("GALLERY#%s#SESSION#%s", galleryID, sessionID)
+}
+
+func favoriteSK(listID, photoID string) string {
+	return fmt.Sprintf("LIST#%s#PHOTO#%s", listID, photoID)
+}
+
+func favoriteListSK(listID string) string {
+	return fmt.Sprintf("LIST#%s", listID)
+}
+
+func favoriteKey(pk, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: sk},
+	}
+}
+
 func (r *FavoriteRepository) Create(ctx context.Context, favorite *repository.Favorite) error {
+	listID := favorite.ListID
+	if listID == "" {
+		listID = repository.DefaultFavoriteListID
+	}
+
 	item := favoriteItem{
-		PK:          fmt.Sprintf("GALLERY#%s#SESSION#%s", favorite.GalleryID, favorite.SessionID),
-		SK:          fmt.Sprintf("PHOTO#%s", favorite.PhotoID),
+		PK:          favoritePK(favorite.GalleryID, favorite.SessionID),
+		SK:          favoriteSK(listID, favorite.PhotoID),
 		GalleryID:   favorite.GalleryID,
 		SessionID:   favorite.SessionID,
+		ListID:      listID,
 		PhotoID:     favorite.PhotoID,
 		FavoritedAt: favorite.FavoritedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -48,103 +104,361 @@ func (r *FavoriteRepository) Create(ctx context.Context, favorite *repository.Fa
 		return fmt.Errorf("failed to marshal favorite: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
+	return putWithOutbox(ctx, r.client, r.outbox, r.tableName, av)
+}
+
+func (r *FavoriteRepository) Delete(ctx context.Context, galleryID, sessionID, listID, photoID string) error {
+	pk := favoritePK(galleryID, sessionID)
+	if listID == repository.DefaultFavoriteListID {
+		// The favorite may still be stored under its pre-lists key
+		if _, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.tableName),
+			Key:       favoriteKey(pk, legacyFavoritePrefix+photoID),
+		}); err != nil {
+			return fmt.Errorf("failed to delete legacy favorite: %w", err)
+		}
+	}
+
+	return deleteWithOutbox(ctx, r.client, r.outbox, r.tableName, favoriteKey(pk, favoriteSK(listID, photoID)))
+}
+
+func (r *FavoriteRepository) IsFavorited(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error) {
+	pk := favoritePK(galleryID, sessionID)
+	keys := []string{favoriteSK(listID, photoID)}
+	if listID == repository.DefaultFavoriteListID {
+		keys = append(keys, legacyFavoritePrefix+photoID)
+	}
+
+	for _, sk := range keys {
+		result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(r.tableName),
+			Key:       favoriteKey(pk, sk),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to check favorite: %w", err)
+		}
+		if result.Item != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *FavoriteRepository) ListBySession(ctx context.Context, galleryID, sessionID string) ([]*repository.Favorite, error) {
+	items, err := r.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		FilterExpression:       aws.String("attribute_exists(photoId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: favoritePK(galleryID, sessionID)},
+		},
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites by session: %w", err)
+	}
 
-	return err
+	return unmarshalFavorites(items)
 }
 
-func (r *FavoriteRepository) Delete(ctx context.Context, galleryID, sessionID, photoID string) error {
-	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(r.tableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s#SESSION#%s", galleryID, sessionID)},
-			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTO#%s", photoID)},
+func (r *FavoriteRepository) ListByList(ctx context.Context, galleryID, sessionID, listID string) ([]*repository.Favorite, error) {
+	prefixes := []string{favoriteSK(listID, "")}
+	if listID == repository.DefaultFavoriteListID {
+		prefixes = append(prefixes, legacyFavoritePrefix)
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, prefix := range prefixes {
+		page, err := r.query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":     &types.AttributeValueMemberS{Value: favoritePK(galleryID, sessionID)},
+				":prefix": &types.AttributeValueMemberS{Value: prefix},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list favorites by list: %w", err)
+		}
+		items = append(items, page...)
+	}
+
+	return unmarshalFavorites(items)
+}
+
+func (r *FavoriteRepository) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Favorite, error) {
+	// Use Scan with filter expression since we need to query across multiple partition keys
+	items, err := r.scan(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("galleryId = :galleryId AND attribute_exists(photoId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":galleryId": &types.AttributeValueMemberS{Value: galleryID},
 		},
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites by gallery: %w", err)
+	}
 
-	return err
+	return unmarshalFavorites(items)
+}
+
+func (r *FavoriteRepository) CreateList(ctx context.Context, list *repository.FavoriteList) error {
+	return r.putList(ctx, list)
 }
 
-func (r *FavoriteRepository) IsFavorited(ctx context.Context, galleryID, sessionID, photoID string) (bool, error) {
+func (r *FavoriteRepository) GetList(ctx context.Context, galleryID, sessionID, listID string) (*repository.FavoriteList, error) {
 	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s#SESSION#%s", galleryID, sessionID)},
-			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTO#%s", photoID)},
-		},
+		Key:       favoriteKey(favoritePK(galleryID, sessionID), favoriteListSK(listID)),
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorites list: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
 
+	var item favoriteListItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal favorites list: %w", err)
+	}
+
+	return itemToFavoriteList(&item), nil
+}
+
+func (r *FavoriteRepository) UpdateList(ctx context.Context, list *repository.FavoriteList) error {
+	return r.putList(ctx, list)
+}
+
+func (r *FavoriteRepository) DeleteList(ctx context.Context, galleryID, sessionID, listID string) error {
+	pk := favoritePK(galleryID, sessionID)
+
+	favorites, err := r.ListByList(ctx, galleryID, sessionID, listID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check favorite: %w", err)
+		return err
+	}
+
+	keys := []map[string]types.AttributeValue{favoriteKey(pk, favoriteListSK(listID))}
+	for _, favorite := range favorites {
+		keys = append(keys, favoriteKey(pk, favoriteSK(listID, favorite.PhotoID)))
+		if listID == repository.DefaultFavoriteListID {
+			keys = append(keys, favoriteKey(pk, legacyFavoritePrefix+favorite.PhotoID))
+		}
 	}
 
-	return result.Item != nil, nil
+	return r.batchDelete(ctx, keys)
 }
 
-func (r *FavoriteRepository) ListBySession(ctx context.Context, galleryID, sessionID string) ([]*repository.Favorite, error) {
-	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+func (r *FavoriteRepository) ListLists(ctx context.Context, galleryID, sessionID string) ([]*repository.FavoriteList, error) {
+	items, err := r.query(ctx, &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
-		KeyConditionExpression: aws.String("PK = :pk"),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("attribute_not_exists(photoId)"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s#SESSION#%s", galleryID, sessionID)},
+			":pk":     &types.AttributeValueMemberS{Value: favoritePK(galleryID, sessionID)},
+			":prefix": &types.AttributeValueMemberS{Value: "LIST#"},
 		},
 	})
-
 	if err != nil {
-		return nil, fmt.Errorf("failed to list favorites by session: %w", err)
+		return nil, fmt.Errorf("failed to list favorites lists: %w", err)
 	}
 
-	favorites := make([]*repository.Favorite, 0, len(result.Items))
-	for _, item := range result.Items {
-		var favoriteItem favoriteItem
-		if err := attributevalue.UnmarshalMap(item, &favoriteItem); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal favorite: %w", err)
-		}
-		favorites = append(favorites, itemToFavorite(&favoriteItem))
+	return unmarshalFavoriteLists(items)
+}
+
+func (r *FavoriteRepository) ListListsByGallery(ctx context.Context, galleryID string) ([]*repository.FavoriteList, error) {
+	items, err := r.scan(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("galleryId = :galleryId AND attribute_not_exists(photoId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":galleryId": &types.AttributeValueMemberS{Value: galleryID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites lists by gallery: %w", err)
 	}
 
-	return favorites, nil
+	return unmarshalFavoriteLists(items)
 }
 
-func (r *FavoriteRepository) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Favorite, error) {
-	// Use Scan with filter expression since we need to query across multiple partition keys
-	result, err := r.client.Scan(ctx, &dynamodb.ScanInput{
+// MigrateLegacyFavorites moves favorites saved before named lists into the default list.
+// Each favorite is rewritten and its old item deleted in one transaction, so the task
+// can be re-run safely; it returns the number of favorites migrated.
+func (r *FavoriteRepository) MigrateLegacyFavorites(ctx context.Context) (int, error) {
+	items, err := r.scan(ctx, &dynamodb.ScanInput{
 		TableName:        aws.String(r.tableName),
-		FilterExpression: aws.String("galleryId = :galleryId"),
+		FilterExpression: aws.String("begins_with(SK, :legacy)"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":galleryId": &types.AttributeValueMemberS{Value: galleryID},
+			":legacy": &types.AttributeValueMemberS{Value: legacyFavoritePrefix},
 		},
 	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan legacy favorites: %w", err)
+	}
+
+	migrated := 0
+	for _, av := range items {
+		var item favoriteItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return migrated, fmt.Errorf("failed to unmarshal favorite: %w", err)
+		}
+
+		legacySK := item.SK
+		item.ListID = repository.DefaultFavoriteListID
+		item.SK = favoriteSK(item.ListID, item.PhotoID)
+		updated, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to marshal favorite: %w", err)
+		}
 
+		_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String(r.tableName), Item: updated}},
+				{Delete: &types.Delete{TableName: aws.String(r.tableName), Key: favoriteKey(item.PK, legacySK)}},
+			},
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("failed to migrate favorite %s: %w", item.PhotoID, err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+func (r *FavoriteRepository) putList(ctx context.Context, list *repository.FavoriteList) error {
+	item := favoriteListItem{
+		PK:        favoritePK(list.GalleryID, list.SessionID),
+		SK:        favoriteListSK(list.ListID),
+		ListID:    list.ListID,
+		GalleryID: list.GalleryID,
+		SessionID: list.SessionID,
+		Name:      list.Name,
+		CreatedAt: list.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: list.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list favorites by gallery: %w", err)
+		return fmt.Errorf("failed to marshal favorites list: %w", err)
 	}
 
-	favorites := make([]*repository.Favorite, 0, len(result.Items))
-	for _, item := range result.Items {
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	return err
+}
+
+func (r *FavoriteRepository) query(ctx context.Context, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+func (r *FavoriteRepository) scan(ctx context.Context, input *dynamodb.ScanInput) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+func (r *FavoriteRepository) batchDelete(ctx context.Context, keys []map[string]types.AttributeValue) error {
+	for start := 0; start < len(keys); start += batchWriteLimit {
+		end := start + batchWriteLimit
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		requests := make([]types.WriteRequest, 0, end-start)
+		for _, key := range keys[start:end] {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+
+		pending := map[string][]types.WriteRequest{r.tableName: requests}
+		for len(pending) > 0 {
+			result, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return fmt.Errorf("failed to delete favorites: %w", err)
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+func unmarshalFavorites(items []map[string]types.AttributeValue) ([]*repository.Favorite, error) {
+	favorites := make([]*repository.Favorite, 0, len(items))
+	for _, item := range items {
 		var favoriteItem favoriteItem
 		if err := attributevalue.UnmarshalMap(item, &favoriteItem); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal favorite: %w", err)
 		}
 		favorites = append(favorites, itemToFavorite(&favoriteItem))
 	}
-
 	return favorites, nil
 }
 
+func unmarshalFavoriteLists(items []map[string]types.AttributeValue) ([]*repository.FavoriteList, error) {
+	lists := make([]*repository.FavoriteList, 0, len(items))
+	for _, item := range items {
+		var listItem favoriteListItem
+		if err := attributevalue.UnmarshalMap(item, &listItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorites list: %w", err)
+		}
+		lists = append(lists, itemToFavoriteList(&listItem))
+	}
+	return lists, nil
+}
+
 func itemToFavorite(item *favoriteItem) *repository.Favorite {
 	favorite := &repository.Favorite{
 		GalleryID: item.GalleryID,
 		SessionID: item.SessionID,
+		ListID:    item.ListID,
 		PhotoID:   item.PhotoID,
 	}
 
-	// Parse FavoritedAt
-	// Simple parsing - in production use proper time parsing
-	// favorite.FavoritedAt = time.Parse(...)
+	// Favorites saved before named lists have no list ID
+	if favorite.ListID == "" {
+		favorite.ListID = repository.DefaultFavoriteListID
+	}
+
+	if t, err := parseTime(item.FavoritedAt); err == nil {
+		favorite.FavoritedAt = t
+	}
 
 	return favorite
 }
+
+func itemToFavoriteList(item *favoriteListItem) *repository.FavoriteList {
+	list := &repository.FavoriteList{
+		ListID:    item.ListID,
+		GalleryID: item.GalleryID,
+		SessionID: item.SessionID,
+		Name:      item.Name,
+	}
+
+	if t, err := parseTime(item.CreatedAt); err == nil {
+		list.CreatedAt = t
+	}
+	if t, err := parseTime(item.UpdatedAt); err == nil {
+		list.UpdatedAt = t
+	}
+
+	return list
+}
@@ -16,6 +16,7 @@ import (
 type GalleryRepository struct {
 	client    *dynamodb.Client
 	tableName string
+	outbox    *OutboxRepository
 }
 
 func NewGalleryRepository(client *dynamodb.Client, tableName string) *GalleryRepository {
@@ -25,62 +26,101 @@ func NewGalleryRepository(client *dynamodb.Client, tableName string) *GalleryRep
 	}
 }
 
+// WithOutbox makes entity writes persist any events staged on the context in the same transaction.
+func (r *GalleryRepository) WithOutbox(outbox *OutboxRepository) *GalleryRepository {
+	r.outbox = outbox
+	return r
+}
+
 type galleryItem struct {
-	PK                string     `dynamodbav:"PK"`
-	SK                string     `dynamodbav:"SK"`
-	GalleryID         string     `dynamodbav:"galleryId"`
-	PhotographerID    string     `dynamodbav:"photographerId"`
-	Name              string     `dynamodbav:"name"`
-	Description       string     `dynamodbav:"description"`
-	CustomURL         string     `dynamodbav:"customUrl"`
-	Password          string     `dynamodbav:"password"`
-	CreatedAt         string     `dynamodbav:"createdAt"`
-	ExpiresAt         *string    `dynamodbav:"expiresAt,omitempty"`
-	Status            string     `dynamodbav:"status"`
-	PhotoCount        int        `dynamodbav:"photoCount"`
-	TotalSize         int64      `dynamodbav:"totalSize"`
-	ClientAccessCount int        `dynamodbav:"clientAccessCount"`
-	EnableWatermark   bool       `dynamodbav:"enableWatermark"`
-	WatermarkText     string     `dynamodbav:"watermarkText,omitempty"`
-	WatermarkPosition string     `dynamodbav:"watermarkPosition,omitempty"`
+	PK                  string  `dynamodbav:"PK"`
+	SK                  string  `dynamodbav:"SK"`
+	GalleryID           string  `dynamodbav:"galleryId"`
+	PhotographerID      string  `dynamodbav:"photographerId"`
+	Name                string  `dynamodbav:"name"`
+	Description         string  `dynamodbav:"description"`
+	CustomURL           string  `dynamodbav:"customUrl"`
+	Password            string  `dynamodbav:"password"`
+	CreatedAt           string  `dynamodbav:"createdAt"`
+	ExpiresAt           *string `dynamodbav:"expiresAt,omitempty"`
+	Status              string  `dynamodbav:"status"`
+	PhotoCount          int     `dynamodbav:"photoCount"`
+	TotalSize           int64   `dynamodbav:"totalSize"`
+	ClientAccessCount   int     `dynamodbav:"clientAccessCount"`
+	EnableWatermark     bool    `dynamodbav:"enableWatermark"`
+	WatermarkText       string  `dynamodbav:"watermarkText,omitempty"`
+	WatermarkPosition   string  `dynamodbav:"watermarkPosition,omitempty"`
+	WatermarkFont       string  `dynamodbav:"watermarkFont,omitempty"`
+	WatermarkSize       float64 `dynamodbav:"watermarkSize,omitempty"`
+	WatermarkColor      string  `dynamodbav:"watermarkColor,omitempty"`
+	WatermarkOpacity    float64 `dynamodbav:"watermarkOpacity,omitempty"`
+	WatermarkMargin     float64 `dynamodbav:"watermarkMargin,omitempty"`
+	WatermarkMode       string  `dynamodbav:"watermarkMode,omitempty"`
+	WatermarkRotation   float64 `dynamodbav:"watermarkRotation,omitempty"`
+	WatermarkType       string  `dynamodbav:"watermarkType,omitempty"`
+	WatermarkScale      float64 `dynamodbav:"watermarkScale,omitempty"`
+	ThumbnailCrop       string  `dynamodbav:"thumbnailCrop,omitempty"`
+	DownloadPolicy      string  `dynamodbav:"downloadPolicy,omitempty"`
+	ProofingEnabled     bool    `dynamodbav:"proofingEnabled"`
+	SelectionLimit      int     `dynamodbav:"selectionLimit,omitempty"`
+	ProcessingProfileID string  `dynamodbav:"processingProfileId,omitempty"`
+	ArchivedAt          *string `dynamodbav:"archivedAt,omitempty"`
+	DeletedAt           *string `dynamodbav:"deletedAt,omitempty"`
 }
 
 func (r *GalleryRepository) Create(ctx context.Context, gallery *repository.Gallery) error {
 	item := galleryItem{
-		PK:                fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID),
-		SK:                fmt.Sprintf("GALLERY#%s", gallery.GalleryID),
-		GalleryID:         gallery.GalleryID,
-		PhotographerID:    gallery.PhotographerID,
-		Name:              gallery.Name,
-		Description:       gallery.Description,
-		CustomURL:         gallery.CustomURL,
-		Password:          gallery.Password,
-		CreatedAt:         gallery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Status:            gallery.Status,
-		PhotoCount:        gallery.PhotoCount,
-		TotalSize:         gallery.TotalSize,
-		ClientAccessCount: gallery.ClientAccessCount,
-		EnableWatermark:   gallery.EnableWatermark,
-		WatermarkText:     gallery.WatermarkText,
-		WatermarkPosition: gallery.WatermarkPosition,
+		PK:                  fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID),
+		SK:                  fmt.Sprintf("GALLERY#%s", gallery.GalleryID),
+		GalleryID:           gallery.GalleryID,
+		PhotographerID:      gallery.PhotographerID,
+		Name:                gallery.Name,
+		Description:         gallery.Description,
+		CustomURL:           gallery.CustomURL,
+		Password:            gallery.Password,
+		CreatedAt:           gallery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Status:              gallery.Status,
+		PhotoCount:          gallery.PhotoCount,
+		TotalSize:           gallery.TotalSize,
+		ClientAccessCount:   gallery.ClientAccessCount,
+		EnableWatermark:     gallery.EnableWatermark,
+		WatermarkText:       gallery.WatermarkText,
+		WatermarkPosition:   gallery.WatermarkPosition,
+		WatermarkFont:       gallery.WatermarkFont,
+		WatermarkSize:       gallery.WatermarkSize,
+		WatermarkColor:      gallery.WatermarkColor,
+		WatermarkOpacity:    gallery.WatermarkOpacity,
+		WatermarkMargin:     gallery.WatermarkMargin,
+		WatermarkMode:       gallery.WatermarkMode,
+		WatermarkRotation:   gallery.WatermarkRotation,
+		WatermarkType:       gallery.WatermarkType,
+		WatermarkScale:      gallery.WatermarkScale,
+		ThumbnailCrop:       gallery.ThumbnailCrop,
+		DownloadPolicy:      gallery.DownloadPolicy,
+		ProofingEnabled:     gallery.ProofingEnabled,
+		SelectionLimit:      gallery.SelectionLimit,
+		ProcessingProfileID: gallery.ProcessingProfileID,
 	}
 
 	if gallery.ExpiresAt != nil {
 		expiresAtStr := gallery.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
 		item.ExpiresAt = &expiresAtStr
 	}
+	if gallery.ArchivedAt != nil {
+		archivedAtStr := gallery.ArchivedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.ArchivedAt = &archivedAtStr
+	}
+	if gallery.DeletedAt != nil {
+		deletedAtStr := gallery.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.DeletedAt = &deletedAtStr
+	}
 
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
 		return fmt.Errorf("failed to marshal gallery: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
-	})
-
-	return err
+	return putWithOutbox(ctx, r.client, r.outbox, r.tableName, av)
 }
 
 func (r *GalleryRepository) GetByID(ctx context.Context, galleryID string) (*repository.Gallery, error) {
@@ -185,40 +225,57 @@ func (r *GalleryRepository) ListByPhotographer(ctx context.Context, photographer
 
 func (r *GalleryRepository) Update(ctx context.Context, gallery *repository.Gallery) error {
 	item := galleryItem{
-		PK:                fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID),
-		SK:                fmt.Sprintf("GALLERY#%s", gallery.GalleryID),
-		GalleryID:         gallery.GalleryID,
-		PhotographerID:    gallery.PhotographerID,
-		Name:              gallery.Name,
-		Description:       gallery.Description,
-		CustomURL:         gallery.CustomURL,
-		Password:          gallery.Password,
-		CreatedAt:         gallery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Status:            gallery.Status,
-		PhotoCount:        gallery.PhotoCount,
-		TotalSize:         gallery.TotalSize,
-		ClientAccessCount: gallery.ClientAccessCount,
-		EnableWatermark:   gallery.EnableWatermark,
-		WatermarkText:     gallery.WatermarkText,
-		WatermarkPosition: gallery.WatermarkPosition,
+		PK:                  fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID),
+		SK:                  fmt.Sprintf("GALLERY#%s", gallery.GalleryID),
+		GalleryID:           gallery.GalleryID,
+		PhotographerID:      gallery.PhotographerID,
+		Name:                gallery.Name,
+		Description:         gallery.Description,
+		CustomURL:           gallery.CustomURL,
+		Password:            gallery.Password,
+		CreatedAt:           gallery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Status:              gallery.Status,
+		PhotoCount:          gallery.PhotoCount,
+		TotalSize:           gallery.TotalSize,
+		ClientAccessCount:   gallery.ClientAccessCount,
+		EnableWatermark:     gallery.EnableWatermark,
+		WatermarkText:       gallery.WatermarkText,
+		WatermarkPosition:   gallery.WatermarkPosition,
+		WatermarkFont:       gallery.WatermarkFont,
+		WatermarkSize:       gallery.WatermarkSize,
+		WatermarkColor:      gallery.WatermarkColor,
+		WatermarkOpacity:    gallery.WatermarkOpacity,
+		WatermarkMargin:     gallery.WatermarkMargin,
+		WatermarkMode:       gallery.WatermarkMode,
+		WatermarkRotation:   gallery.WatermarkRotation,
+		WatermarkType:       gallery.WatermarkType,
+		WatermarkScale:      gallery.WatermarkScale,
+		ThumbnailCrop:       gallery.ThumbnailCrop,
+		DownloadPolicy:      gallery.DownloadPolicy,
+		ProofingEnabled:     gallery.ProofingEnabled,
+		SelectionLimit:      gallery.SelectionLimit,
+		ProcessingProfileID: gallery.ProcessingProfileID,
 	}
 
 	if gallery.ExpiresAt != nil {
 		expiresAtStr := gallery.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
 		item.ExpiresAt = &expiresAtStr
 	}
+	if gallery.ArchivedAt != nil {
+		archivedAtStr := gallery.ArchivedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.ArchivedAt = &archivedAtStr
+	}
+	if gallery.DeletedAt != nil {
+		deletedAtStr := gallery.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.DeletedAt = &deletedAtStr
+	}
 
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
 		return fmt.Errorf("failed to marshal gallery: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
-	})
-
-	return err
+	return putWithOutbox(ctx, r.client, r.outbox, r.tableName, av)
 }
 
 func (r *GalleryRepository) Delete(ctx context.Context, galleryID string) error {
@@ -231,15 +288,10 @@ func (r *GalleryRepository) Delete(ctx context.Context, galleryID string) error
 		return nil
 	}
 
-	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(r.tableName),
-		Key: map[string]types.AttributeValue{
-			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID)},
-			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", galleryID)},
-		},
+	return deleteWithOutbox(ctx, r.client, r.outbox, r.tableName, map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", gallery.PhotographerID)},
+		"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", galleryID)},
 	})
-
-	return err
 }
 
 func (r *GalleryRepository) ListExpired(ctx context.Context, limit int) ([]*repository.Gallery, error) {
@@ -276,6 +328,39 @@ func (r *GalleryRepository) ListExpired(ctx context.Context, limit int) ([]*repo
 	return galleries, nil
 }
 
+// ListByStatus lists galleries in the given status. It reads the StatusExpirationIndex, which
+// only holds galleries with an expiration date; archived and restoring galleries always have
+// one because they were archived on expiry.
+func (r *GalleryRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*repository.Gallery, error) {
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("StatusExpirationIndex"),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		},
+		Limit: aws.Int32(int32(limit)),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list galleries by status: %w", err)
+	}
+
+	galleries := make([]*repository.Gallery, 0, len(result.Items))
+	for _, item := range result.Items {
+		var galleryItem galleryItem
+		if err := attributevalue.UnmarshalMap(item, &galleryItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal gallery: %w", err)
+		}
+		galleries = append(galleries, itemToGallery(&galleryItem))
+	}
+
+	return galleries, nil
+}
+
 func (r *GalleryRepository) UpdatePhotoCount(ctx context.Context, galleryID string, delta int) error {
 	gallery, err := r.GetByID(ctx, galleryID)
 	if err != nil {
@@ -350,19 +435,33 @@ func (r *GalleryRepository) IncrementClientAccessCount(ctx context.Context, gall
 
 func itemToGallery(item *galleryItem) *repository.Gallery {
 	gallery := &repository.Gallery{
-		GalleryID:         item.GalleryID,
-		PhotographerID:    item.PhotographerID,
-		Name:              item.Name,
-		Description:       item.Description,
-		CustomURL:         item.CustomURL,
-		Password:          item.Password,
-		Status:            item.Status,
-		PhotoCount:        item.PhotoCount,
-		TotalSize:         item.TotalSize,
-		ClientAccessCount: item.ClientAccessCount,
-		EnableWatermark:   item.EnableWatermark,
-		WatermarkText:     item.WatermarkText,
-		WatermarkPosition: item.WatermarkPosition,
+		GalleryID:           item.GalleryID,
+		PhotographerID:      item.PhotographerID,
+		Name:                item.Name,
+		Description:         item.Description,
+		CustomURL:           item.CustomURL,
+		Password:            item.Password,
+		Status:              item.Status,
+		PhotoCount:          item.PhotoCount,
+		TotalSize:           item.TotalSize,
+		ClientAccessCount:   item.ClientAccessCount,
+		EnableWatermark:     item.EnableWatermark,
+		WatermarkText:       item.WatermarkText,
+		WatermarkPosition:   item.WatermarkPosition,
+		WatermarkFont:       item.WatermarkFont,
+		WatermarkSize:       item.WatermarkSize,
+		WatermarkColor:      item.WatermarkColor,
+		WatermarkOpacity:    item.WatermarkOpacity,
+		WatermarkMargin:     item.WatermarkMargin,
+		WatermarkMode:       item.WatermarkMode,
+		WatermarkRotation:   item.WatermarkRotation,
+		WatermarkType:       item.WatermarkType,
+		WatermarkScale:      item.WatermarkScale,
+		ThumbnailCrop:       item.ThumbnailCrop,
+		DownloadPolicy:      item.DownloadPolicy,
+		ProofingEnabled:     item.ProofingEnabled,
+		SelectionLimit:      item.SelectionLimit,
+		ProcessingProfileID: item.ProcessingProfileID,
 	}
 
 	// Parse CreatedAt
@@ -379,6 +478,17 @@ func itemToGallery(item *galleryItem) *repository.Gallery {
 		}
 	}
 
+	if item.ArchivedAt != nil && *item.ArchivedAt != "" {
+		if t, err := parseTime(*item.ArchivedAt); err == nil {
+			gallery.ArchivedAt = &t
+		}
+	}
+	if item.DeletedAt != nil && *item.DeletedAt != "" {
+		if t, err := parseTime(*item.DeletedAt); err == nil {
+			gallery.DeletedAt = &t
+		}
+	}
+
 	return gallery
 }
 
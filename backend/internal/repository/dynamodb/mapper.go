@@ -16,88 +16,6 @@ type Mapper[T any] interface {
 	FromDynamoDB(attrs map[string]types.AttributeValue) (*T, error)
 }
 
-// GalleryMapper adapts between the domain Gallery model and DynamoDB representation.
-type GalleryMapper struct{}
-
-// NewGalleryMapper creates a new GalleryMapper.
-func NewGalleryMapper() *GalleryMapper {
-	return &GalleryMapper{}
-}
-
-// FromItem converts a galleryItem to a domain Gallery.
-func (m *GalleryMapper) FromItem(item *galleryItem) (*repository.Gallery, error) {
-	createdAt, err := time.Parse(time.RFC3339, item.CreatedAt)
-	if err != nil {
-		// Fallback for other formats
-		createdAt, _ = time.Parse("2006-01-02T15:04:05Z07:00", item.CreatedAt)
-	}
-
-	gallery := &repository.Gallery{
-		GalleryID:         item.GalleryID,
-		PhotographerID:    item.PhotographerID,
-		Name:              item.Name,
-		Description:       item.Description,
-		CustomURL:         item.CustomURL,
-		Password:          item.Password,
-		CreatedAt:         createdAt,
-		Status:            item.Status,
-		PhotoCount:        item.PhotoCount,
-		TotalSize:         item.TotalSize,
-		ClientAccessCount: item.ClientAccessCount,
-		EnableWatermark:   item.EnableWatermark,
-		WatermarkText:     item.WatermarkText,
-		WatermarkPosition: item.WatermarkPosition,
-	}
-
-	if item.ExpiresAt != nil && *item.ExpiresAt != "" {
-		expiresAt, err := time.Parse(time.RFC3339, *item.ExpiresAt)
-		if err != nil {
-			expiresAt, _ = time.Parse("2006-01-02T15:04:05Z07:00", *item.ExpiresAt)
-		}
-		gallery.ExpiresAt = &expiresAt
-	}
-
-	return gallery, nil
-}
-
-// FromDynamoDB converts a DynamoDB item map to a domain Gallery.
-func (m *GalleryMapper) FromDynamoDB(attrs map[string]types.AttributeValue) (*repository.Gallery, error) {
-	var item galleryItem
-	if err := attributevalue.UnmarshalMap(attrs, &item); err != nil {
-		return nil, err
-	}
-	return m.FromItem(&item)
-}
-
-// ToItem converts a domain Gallery to a galleryItem.
-func (m *GalleryMapper) ToItem(gallery *repository.Gallery, pk, sk string) *galleryItem {
-	item := &galleryItem{
-		PK:                pk,
-		SK:                sk,
-		GalleryID:         gallery.GalleryID,
-		PhotographerID:    gallery.PhotographerID,
-		Name:              gallery.Name,
-		Description:       gallery.Description,
-		CustomURL:         gallery.CustomURL,
-		Password:          gallery.Password,
-		CreatedAt:         gallery.CreatedAt.Format(time.RFC3339),
-		Status:            gallery.Status,
-		PhotoCount:        gallery.PhotoCount,
-		TotalSize:         gallery.TotalSize,
-		ClientAccessCount: gallery.ClientAccessCount,
-		EnableWatermark:   gallery.EnableWatermark,
-		WatermarkText:     gallery.WatermarkText,
-		WatermarkPosition: gallery.WatermarkPosition,
-	}
-
-	if gallery.ExpiresAt != nil {
-		expiresAtStr := gallery.ExpiresAt.Format(time.RFC3339)
-		item.ExpiresAt = &expiresAtStr
-	}
-
-	return item
-}
-
 // PhotoMapper adapts between the domain Photo model and DynamoDB representation.
 type PhotoMapper struct{}
 
@@ -0,0 +1,264 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// publishedEventRetention is how long published events are kept before DynamoDB TTL removes them.
+const publishedEventRetention = 7 * 24 * time.Hour
+
+type OutboxRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewOutboxRepository(client *dynamodb.Client, tableName string) *OutboxRepository {
+	return &OutboxRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type outboxItem struct {
+	PK            string `dynamodbav:"PK"`
+	SK            string `dynamodbav:"SK"`
+	EventID       string `dynamodbav:"eventId"`
+	EventType     string `dynamodbav:"eventType"`
+	Payload       string `dynamodbav:"payload"`
+	CorrelationID string `dynamodbav:"correlationId"`
+	Status        string `dynamodbav:"status"`
+	Attempts      int    `dynamodbav:"attempts"`
+	LastError     string `dynamodbav:"lastError,omitempty"`
+	CreatedAt     string `dynamodbav:"createdAt"`
+	PublishedAt   string `dynamodbav:"publishedAt,omitempty"`
+	TTL           int64  `dynamodbav:"ttl,omitempty"`
+}
+
+func outboxKey(eventID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("EVENT#%s", eventID)},
+		"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("EVENT#%s", eventID)},
+	}
+}
+
+// ListPending returns the oldest events that have not been published yet.
+func (r *OutboxRepository) ListPending(ctx context.Context, limit int) ([]*repository.OutboxEvent, error) {
+	// Query using GSI1 (StatusCreatedAtIndex) so events are relayed in creation order
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("StatusCreatedAtIndex"),
+		KeyConditionExpression: aws.String("#status = :status"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: repository.OutboxStatusPending},
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending events: %w", err)
+	}
+
+	pending := make([]*repository.OutboxEvent, 0, len(result.Items))
+	for _, item := range result.Items {
+		var eventItem outboxItem
+		if err := attributevalue.UnmarshalMap(item, &eventItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal outbox event: %w", err)
+		}
+		pending = append(pending, itemToOutboxEvent(&eventItem))
+	}
+
+	return pending, nil
+}
+
+// MarkPublished records a successful delivery and schedules the event for expiry.
+func (r *OutboxRepository) MarkPublished(ctx context.Context, eventID string) error {
+	now := time.Now()
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              outboxKey(eventID),
+		UpdateExpression: aws.String("SET #status = :status, publishedAt = :publishedAt, #ttl = :ttl ADD attempts :one"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+			"#ttl":    "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":      &types.AttributeValueMemberS{Value: repository.OutboxStatusPublished},
+			":publishedAt": &types.AttributeValueMemberS{Value: now.Format("2006-01-02T15:04:05Z07:00")},
+			":ttl":         &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Add(publishedEventRetention).Unix())},
+			":one":         &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+
+	return err
+}
+
+// MarkFailed records a failed delivery attempt. Permanent failures are taken out of the pending index.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, eventID, lastError string, permanent bool) error {
+	status := repository.OutboxStatusPending
+	if permanent {
+		status = repository.OutboxStatusFailed
+	}
+
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              outboxKey(eventID),
+		UpdateExpression: aws.String("SET #status = :status, lastError = :lastError ADD attempts :one"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":    &types.AttributeValueMemberS{Value: status},
+			":lastError": &types.AttributeValueMemberS{Value: lastError},
+			":one":       &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+
+	return err
+}
+
+// transactItems builds the puts that persist staged events.
+func (r *OutboxRepository) transactItems(evts []*repository.OutboxEvent) ([]types.TransactWriteItem, error) {
+	items := make([]types.TransactWriteItem, 0, len(evts))
+	for _, evt := range evts {
+		item := outboxItem{
+			PK:            fmt.Sprintf("EVENT#%s", evt.EventID),
+			SK:            fmt.Sprintf("EVENT#%s", evt.EventID),
+			EventID:       evt.EventID,
+			EventType:     evt.EventType,
+			Payload:       evt.Payload,
+			CorrelationID: evt.CorrelationID,
+			Status:        repository.OutboxStatusPending,
+			CreatedAt:     evt.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+
+		av, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal outbox event: %w", err)
+		}
+		items = append(items, types.TransactWriteItem{
+			Put: &types.Put{TableName: aws.String(r.tableName), Item: av},
+		})
+	}
+	return items, nil
+}
+
+// putWithOutbox puts an entity item, together with any events staged on ctx, in a single transaction.
+// Without staged events or a configured outbox it falls back to a plain PutItem.
+func putWithOutbox(ctx context.Context, client *dynamodb.Client, outbox *OutboxRepository, tableName string, item map[string]types.AttributeValue) error {
+	return putWithOutboxIf(ctx, client, outbox, tableName, item, nil)
+}
+
+// putWithOutboxIf is putWithOutbox made only if cond holds for the stored item. When it doesn't,
+// neither the item nor the events are written and isConditionFailed reports the error.
+func putWithOutboxIf(ctx context.Context, client *dynamodb.Client, outbox *OutboxRepository, tableName string, item map[string]types.AttributeValue, cond *expression.Expression) error {
+	evts := repository.OutboxEventsFromContext(ctx)
+	if outbox == nil || len(evts) == 0 {
+		input := &dynamodb.PutItemInput{
+			TableName: aws.String(tableName),
+			Item:      item,
+		}
+		if cond != nil {
+			input.ConditionExpression = cond.Condition()
+			input.ExpressionAttributeNames = cond.Names()
+			input.ExpressionAttributeValues = cond.Values()
+		}
+		_, err := client.PutItem(ctx, input)
+		return err
+	}
+
+	put := &types.Put{TableName: aws.String(tableName), Item: item}
+	if cond != nil {
+		put.ConditionExpression = cond.Condition()
+		put.ExpressionAttributeNames = cond.Names()
+		put.ExpressionAttributeValues = cond.Values()
+	}
+	return outbox.transact(ctx, types.TransactWriteItem{Put: put}, evts)
+}
+
+// isConditionFailed reports whether a write failed because its condition didn't hold, whether
+// it was written alone or in a transaction
+func isConditionFailed(err error) bool {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return true
+	}
+	var canceledErr *types.TransactionCanceledException
+	if errors.As(err, &canceledErr) {
+		for _, reason := range canceledErr.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteWithOutbox deletes an entity item, together with any events staged on ctx, in a single transaction.
+func deleteWithOutbox(ctx context.Context, client *dynamodb.Client, outbox *OutboxRepository, tableName string, key map[string]types.AttributeValue) error {
+	evts := repository.OutboxEventsFromContext(ctx)
+	if outbox == nil || len(evts) == 0 {
+		_, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(tableName),
+			Key:       key,
+		})
+		return err
+	}
+
+	return outbox.transact(ctx, types.TransactWriteItem{
+		Delete: &types.Delete{TableName: aws.String(tableName), Key: key},
+	}, evts)
+}
+
+func (r *OutboxRepository) transact(ctx context.Context, write types.TransactWriteItem, evts []*repository.OutboxEvent) error {
+	eventItems, err := r.transactItems(evts)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: append([]types.TransactWriteItem{write}, eventItems...),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write with outbox events: %w", err)
+	}
+	return nil
+}
+
+func itemToOutboxEvent(item *outboxItem) *repository.OutboxEvent {
+	evt := &repository.OutboxEvent{
+		EventID:       item.EventID,
+		EventType:     item.EventType,
+		Payload:       item.Payload,
+		CorrelationID: item.CorrelationID,
+		Status:        item.Status,
+		Attempts:      item.Attempts,
+		LastError:     item.LastError,
+	}
+
+	if item.CreatedAt != "" {
+		if t, err := parseTime(item.CreatedAt); err == nil {
+			evt.CreatedAt = t
+		}
+	}
+	if item.PublishedAt != "" {
+		if t, err := parseTime(item.PublishedAt); err == nil {
+			evt.PublishedAt = &t
+		}
+	}
+
+	return evt
+}
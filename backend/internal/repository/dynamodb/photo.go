@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
@@ -15,6 +16,7 @@ import (
 type PhotoRepository struct {
 	client    *dynamodb.Client
 	tableName string
+	outbox    *OutboxRepository
 }
 
 func NewPhotoRepository(client *dynamodb.Client, tableName string) *PhotoRepository {
@@ -24,25 +26,43 @@ func NewPhotoRepository(client *dynamodb.Client, tableName string) *PhotoReposit
 	}
 }
 
+// WithOutbox makes entity writes persist any events staged on the context in the same transaction.
+func (r *PhotoRepository) WithOutbox(outbox *OutboxRepository) *PhotoRepository {
+	r.outbox = outbox
+	return r
+}
+
 type photoItem struct {
-	PK               string            `dynamodbav:"PK"`
-	SK               string            `dynamodbav:"SK"`
-	PhotoID          string            `dynamodbav:"photoId"`
-	GalleryID        string            `dynamodbav:"galleryId"`
-	FileName         string            `dynamodbav:"fileName"`
-	OriginalKey      string            `dynamodbav:"originalKey"`
-	OptimizedKey     string            `dynamodbav:"optimizedKey,omitempty"`
-	ThumbnailKey     string            `dynamodbav:"thumbnailKey,omitempty"`
-	MimeType         string            `dynamodbav:"mimeType"`
-	Size             int64             `dynamodbav:"size"`
-	Width            int               `dynamodbav:"width,omitempty"`
-	Height           int               `dynamodbav:"height,omitempty"`
-	ProcessingStatus string            `dynamodbav:"processingStatus"`
-	UploadedAt       string            `dynamodbav:"uploadedAt"`
-	ProcessedAt      string            `dynamodbav:"processedAt,omitempty"`
-	FavoriteCount    int               `dynamodbav:"favoriteCount"`
-	DownloadCount    int               `dynamodbav:"downloadCount"`
-	Metadata         map[string]string `dynamodbav:"metadata,omitempty"`
+	PK               string                        `dynamodbav:"PK"`
+	SK               string                        `dynamodbav:"SK"`
+	PhotoID          string                        `dynamodbav:"photoId"`
+	GalleryID        string                        `dynamodbav:"galleryId"`
+	PhotographerID   string                        `dynamodbav:"photographerId,omitempty"`
+	FileName         string                        `dynamodbav:"fileName"`
+	OriginalKey      string                        `dynamodbav:"originalKey"`
+	OptimizedKey     string                        `dynamodbav:"optimizedKey,omitempty"`
+	ThumbnailKey     string                        `dynamodbav:"thumbnailKey,omitempty"`
+	MimeType         string                        `dynamodbav:"mimeType"`
+	Size             int64                         `dynamodbav:"size"`
+	OptimizedSize    int64                         `dynamodbav:"optimizedSize,omitempty"`
+	ThumbnailSize    int64                         `dynamodbav:"thumbnailSize,omitempty"`
+	Width            int                           `dynamodbav:"width,omitempty"`
+	Height           int                           `dynamodbav:"height,omitempty"`
+	ProcessingStatus string                        `dynamodbav:"processingStatus"`
+	UploadedAt       string                        `dynamodbav:"uploadedAt"`
+	ProcessedAt      string                        `dynamodbav:"processedAt,omitempty"`
+	FavoriteCount    int                           `dynamodbav:"favoriteCount"`
+	DownloadCount    int                           `dynamodbav:"downloadCount"`
+	Metadata         map[string]string             `dynamodbav:"metadata,omitempty"`
+	DeletedAt        string                        `dynamodbav:"deletedAt,omitempty"`
+	Renditions       []repository.Rendition        `dynamodbav:"renditions,omitempty"`
+	FocalPoint       *repository.FocalPoint        `dynamodbav:"focalPoint,omitempty"`
+	BlurHash         string                        `dynamodbav:"blurHash,omitempty"`
+	DominantColor    string                        `dynamodbav:"dominantColor,omitempty"`
+	Failure          *repository.ProcessingFailure `dynamodbav:"failure,omitempty"`
+	SourceVersion    string                        `dynamodbav:"sourceVersion,omitempty"`
+	Version          int                           `dynamodbav:"version"`
+	FailedAt         string                        `dynamodbav:"failedAt,omitempty"` // only set on failed photos, keeping PhotographerFailedIndex sparse
 }
 
 func (r *PhotoRepository) Create(ctx context.Context, photo *repository.Photo) error {
@@ -51,12 +71,15 @@ func (r *PhotoRepository) Create(ctx context.Context, photo *repository.Photo) e
 		SK:               fmt.Sprintf("PHOTO#%s", photo.PhotoID),
 		PhotoID:          photo.PhotoID,
 		GalleryID:        photo.GalleryID,
+		PhotographerID:   photo.PhotographerID,
 		FileName:         photo.FileName,
 		OriginalKey:      photo.OriginalKey,
 		OptimizedKey:     photo.OptimizedKey,
 		ThumbnailKey:     photo.ThumbnailKey,
 		MimeType:         photo.MimeType,
 		Size:             photo.Size,
+		OptimizedSize:    photo.OptimizedSize,
+		ThumbnailSize:    photo.ThumbnailSize,
 		Width:            photo.Width,
 		Height:           photo.Height,
 		ProcessingStatus: photo.ProcessingStatus,
@@ -64,23 +87,45 @@ func (r *PhotoRepository) Create(ctx context.Context, photo *repository.Photo) e
 		FavoriteCount:    photo.FavoriteCount,
 		DownloadCount:    photo.DownloadCount,
 		Metadata:         photo.Metadata,
+		Renditions:       photo.Renditions,
+		FocalPoint:       photo.FocalPoint,
+		BlurHash:         photo.BlurHash,
+		DominantColor:    photo.DominantColor,
+		Failure:          photo.Failure,
+		SourceVersion:    photo.SourceVersion,
+		Version:          photo.Version,
 	}
 
 	if photo.ProcessedAt != nil {
 		item.ProcessedAt = photo.ProcessedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	if photo.DeletedAt != nil {
+		item.DeletedAt = photo.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if photo.IsFailed() {
+		item.FailedAt = item.ProcessedAt
+		if item.FailedAt == "" {
+			item.FailedAt = item.UploadedAt
+		}
+	}
 
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
 		return fmt.Errorf("failed to marshal photo: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
-	})
-
-	return err
+	// Duplicate deliveries of an upload may both try to create its photo; only the first succeeds
+	cond, err := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("PK"))).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build condition: %w", err)
+	}
+	if err := putWithOutboxIf(ctx, r.client, r.outbox, r.tableName, av, &cond); err != nil {
+		if isConditionFailed(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
 }
 
 func (r *PhotoRepository) GetByID(ctx context.Context, photoID string) (*repository.Photo, error) {
@@ -155,18 +200,66 @@ func (r *PhotoRepository) ListByGallery(ctx context.Context, galleryID string, l
 	return photos, nextKey, nil
 }
 
+// ListFailedByPhotographer queries PhotographerFailedIndex, which only holds failed photos
+func (r *PhotoRepository) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastEvaluatedKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("PhotographerFailedIndex"),
+		KeyConditionExpression: aws.String("photographerId = :photographerId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":photographerId": &types.AttributeValueMemberS{Value: photographerID},
+		},
+		Limit:            aws.Int32(int32(limit)),
+		ScanIndexForward: aws.Bool(false), // Most recent failure first
+	}
+
+	if lastEvaluatedKey != nil {
+		exclusiveStartKey, err := attributevalue.MarshalMap(lastEvaluatedKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal last evaluated key: %w", err)
+		}
+		input.ExclusiveStartKey = exclusiveStartKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list failed photos: %w", err)
+	}
+
+	photos := make([]*repository.Photo, 0, len(result.Items))
+	for _, item := range result.Items {
+		var photoItem photoItem
+		if err := attributevalue.UnmarshalMap(item, &photoItem); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal photo: %w", err)
+		}
+		photos = append(photos, itemToPhoto(&photoItem))
+	}
+
+	var nextKey map[string]interface{}
+	if result.LastEvaluatedKey != nil {
+		if err := attributevalue.UnmarshalMap(result.LastEvaluatedKey, &nextKey); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal last evaluated key: %w", err)
+		}
+	}
+
+	return photos, nextKey, nil
+}
+
 func (r *PhotoRepository) Update(ctx context.Context, photo *repository.Photo) error {
 	item := photoItem{
 		PK:               fmt.Sprintf("GALLERY#%s", photo.GalleryID),
 		SK:               fmt.Sprintf("PHOTO#%s", photo.PhotoID),
 		PhotoID:          photo.PhotoID,
 		GalleryID:        photo.GalleryID,
+		PhotographerID:   photo.PhotographerID,
 		FileName:         photo.FileName,
 		OriginalKey:      photo.OriginalKey,
 		OptimizedKey:     photo.OptimizedKey,
 		ThumbnailKey:     photo.ThumbnailKey,
 		MimeType:         photo.MimeType,
 		Size:             photo.Size,
+		OptimizedSize:    photo.OptimizedSize,
+		ThumbnailSize:    photo.ThumbnailSize,
 		Width:            photo.Width,
 		Height:           photo.Height,
 		ProcessingStatus: photo.ProcessingStatus,
@@ -174,23 +267,49 @@ func (r *PhotoRepository) Update(ctx context.Context, photo *repository.Photo) e
 		FavoriteCount:    photo.FavoriteCount,
 		DownloadCount:    photo.DownloadCount,
 		Metadata:         photo.Metadata,
+		Renditions:       photo.Renditions,
+		FocalPoint:       photo.FocalPoint,
+		BlurHash:         photo.BlurHash,
+		DominantColor:    photo.DominantColor,
+		Failure:          photo.Failure,
+		SourceVersion:    photo.SourceVersion,
+		Version:          photo.Version + 1,
 	}
 
 	if photo.ProcessedAt != nil {
 		item.ProcessedAt = photo.ProcessedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	if photo.DeletedAt != nil {
+		item.DeletedAt = photo.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if photo.IsFailed() {
+		item.FailedAt = item.ProcessedAt
+		if item.FailedAt == "" {
+			item.FailedAt = item.UploadedAt
+		}
+	}
 
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
 		return fmt.Errorf("failed to marshal photo: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      av,
-	})
-
-	return err
+	// Only overwrite the version that was read; photos stored before versioning have none
+	version := expression.Name("version")
+	cond, err := expression.NewBuilder().WithCondition(
+		expression.AttributeNotExists(version).Or(version.Equal(expression.Value(photo.Version))),
+	).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build condition: %w", err)
+	}
+	if err := putWithOutboxIf(ctx, r.client, r.outbox, r.tableName, av, &cond); err != nil {
+		if isConditionFailed(err) {
+			return repository.ErrConflict
+		}
+		return err
+	}
+	photo.Version = item.Version
+	return nil
 }
 
 func (r *PhotoRepository) Delete(ctx context.Context, photoID string) error {
@@ -229,9 +348,12 @@ func (r *PhotoRepository) IncrementFavoriteCount(ctx context.Context, photoID st
 			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", photo.GalleryID)},
 			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTO#%s", photoID)},
 		},
-		UpdateExpression: aws.String("SET favoriteCount = favoriteCount + :delta"),
+		// Bumping the version makes a concurrent Update of the whole photo fail rather than lose the count
+		UpdateExpression: aws.String("SET favoriteCount = favoriteCount + :delta, version = if_not_exists(version, :zero) + :one"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", delta)},
+			":zero":  &types.AttributeValueMemberN{Value: "0"},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
 		},
 	})
 
@@ -253,9 +375,10 @@ func (r *PhotoRepository) IncrementDownloadCount(ctx context.Context, photoID st
 			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", photo.GalleryID)},
 			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTO#%s", photoID)},
 		},
-		UpdateExpression: aws.String("SET downloadCount = downloadCount + :one"),
+		UpdateExpression: aws.String("SET downloadCount = downloadCount + :one, version = if_not_exists(version, :zero) + :one"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":one": &types.AttributeValueMemberN{Value: "1"},
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":one":  &types.AttributeValueMemberN{Value: "1"},
 		},
 	})
 
@@ -266,23 +389,43 @@ func itemToPhoto(item *photoItem) *repository.Photo {
 	photo := &repository.Photo{
 		PhotoID:          item.PhotoID,
 		GalleryID:        item.GalleryID,
+		PhotographerID:   item.PhotographerID,
 		FileName:         item.FileName,
 		OriginalKey:      item.OriginalKey,
 		OptimizedKey:     item.OptimizedKey,
 		ThumbnailKey:     item.ThumbnailKey,
 		MimeType:         item.MimeType,
 		Size:             item.Size,
+		OptimizedSize:    item.OptimizedSize,
+		ThumbnailSize:    item.ThumbnailSize,
 		Width:            item.Width,
 		Height:           item.Height,
 		ProcessingStatus: item.ProcessingStatus,
 		FavoriteCount:    item.FavoriteCount,
 		DownloadCount:    item.DownloadCount,
 		Metadata:         item.Metadata,
+		Renditions:       item.Renditions,
+		FocalPoint:       item.FocalPoint,
+		BlurHash:         item.BlurHash,
+		DominantColor:    item.DominantColor,
+		Failure:          item.Failure,
+		SourceVersion:    item.SourceVersion,
+		Version:          item.Version,
 	}
 
-	// Parse UploadedAt
-	// Simple parsing - in production use proper time parsing
-	// photo.UploadedAt = time.Parse(...)
+	if t, err := parseTime(item.UploadedAt); err == nil {
+		photo.UploadedAt = t
+	}
+	if item.ProcessedAt != "" {
+		if t, err := parseTime(item.ProcessedAt); err == nil {
+			photo.ProcessedAt = &t
+		}
+	}
+	if item.DeletedAt != "" {
+		if t, err := parseTime(item.DeletedAt); err == nil {
+			photo.DeletedAt = &t
+		}
+	}
 
 	return photo
 }
@@ -0,0 +1,81 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// newSinglePhotoTable serves a DynamoDB table holding one photo: PutItem replaces it and
+// Query returns it, ignoring conditions and key expressions
+func newSinglePhotoTable(t *testing.T) (*PhotoRepository, func() map[string]json.RawMessage) {
+	t.Helper()
+	var stored map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Item map[string]json.RawMessage
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		switch target := r.Header.Get("X-Amz-Target"); {
+		case strings.HasSuffix(target, ".PutItem"):
+			stored = body.Item
+			w.Write([]byte("{}"))
+		case strings.HasSuffix(target, ".Query"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"Items": []map[string]json.RawMessage{stored}})
+		default:
+			t.Errorf("unexpected operation %s", target)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	return NewPhotoRepository(client, "photos"), func() map[string]json.RawMessage { return stored }
+}
+
+func TestPhotoFailedAtSurvivesUpdates(t *testing.T) {
+	repo, stored := newSinglePhotoTable(t)
+	ctx := context.Background()
+	uploaded := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	failed := uploaded.Add(time.Minute)
+
+	if err := repo.Create(ctx, &repository.Photo{PhotoID: "photo_1", GalleryID: "gal_1", PhotographerID: "user_1",
+		ProcessingStatus: "failed", UploadedAt: uploaded, ProcessedAt: &failed}); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	// Photos read back and written again, as moving one to the trash does, keep their times
+	for i := 0; i < 2; i++ {
+		photo, err := repo.GetByID(ctx, "photo_1")
+		if err != nil {
+			t.Fatalf("GetByID() error: %v", err)
+		}
+		if !photo.UploadedAt.Equal(uploaded) || photo.ProcessedAt == nil || !photo.ProcessedAt.Equal(failed) {
+			t.Fatalf("photo uploaded %v and processed %v, want %v and %v", photo.UploadedAt, photo.ProcessedAt, uploaded, failed)
+		}
+		deleted := failed.Add(time.Hour)
+		photo.DeletedAt = &deleted
+		if err := repo.Update(ctx, photo); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+	}
+
+	if got, want := string(stored()["failedAt"]), `{"S":"2026-03-01T09:01:00Z"}`; got != want {
+		t.Errorf("failedAt = %s, want %s", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"photographer-gallery/backend/internal/domain/photographer"
+	"photographer-gallery/backend/internal/repository"
 )
 
 type PhotographerRepository struct {
@@ -145,6 +147,115 @@ func (r *PhotographerRepository) Update(ctx context.Context, p *photographer.Pho
 	return nil
 }
 
+// UpdateStorageUsed atomically adds deltaBytes (which may be negative) to a photographer's storage usage
+func (r *PhotographerRepository) UpdateStorageUsed(ctx context.Context, userID string, deltaBytes int64) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       photographerKey(userID),
+		// ADD would otherwise create a bare item for photographers who haven't signed in yet
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("ADD storageUsed :delta"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", deltaBytes)},
+		},
+	})
+
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return photographer.ErrNotFound
+		}
+		return fmt.Errorf("failed to update storage used: %w", err)
+	}
+
+	return nil
+}
+
+// SetStorageUsed overwrites a photographer's storage usage when reconciling drift. The write
+// only applies while usage is still previous, so increments recorded since it was read are not
+// lost; otherwise it returns repository.ErrConflict.
+func (r *PhotographerRepository) SetStorageUsed(ctx context.Context, userID string, previous, bytes int64) error {
+	condition := "attribute_exists(PK) AND storageUsed = :previous"
+	if previous == 0 {
+		condition = "attribute_exists(PK) AND (attribute_not_exists(storageUsed) OR storageUsed = :previous)"
+	}
+
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 photographerKey(userID),
+		ConditionExpression: aws.String(condition),
+		UpdateExpression:    aws.String("SET storageUsed = :storageUsed"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":storageUsed": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", bytes)},
+			":previous":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", previous)},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			if len(conditionErr.Item) == 0 {
+				return photographer.ErrNotFound
+			}
+			return repository.ErrConflict
+		}
+		return fmt.Errorf("failed to set storage used: %w", err)
+	}
+
+	return nil
+}
+
+// List scans photographers page by page
+func (r *PhotographerRepository) List(ctx context.Context, limit int, lastEvaluatedKey map[string]interface{}) ([]*photographer.Photographer, map[string]interface{}, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("SK = :sk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sk": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		Limit: aws.Int32(int32(limit)),
+	}
+
+	if lastEvaluatedKey != nil {
+		exclusiveStartKey, err := attributevalue.MarshalMap(lastEvaluatedKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal last evaluated key: %w", err)
+		}
+		input.ExclusiveStartKey = exclusiveStartKey
+	}
+
+	result, err := r.client.Scan(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list photographers: %w", err)
+	}
+
+	photographers := make([]*photographer.Photographer, 0, len(result.Items))
+	for _, item := range result.Items {
+		var p photographer.Photographer
+		if err := attributevalue.UnmarshalMap(item, &p); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal photographer: %w", err)
+		}
+		photographers = append(photographers, &p)
+	}
+
+	var nextKey map[string]interface{}
+	if result.LastEvaluatedKey != nil {
+		if err := attributevalue.UnmarshalMap(result.LastEvaluatedKey, &nextKey); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal last evaluated key: %w", err)
+		}
+	}
+
+	return photographers, nextKey, nil
+}
+
+func photographerKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+		"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+	}
+}
+
 // GetBySubdomain retrieves a photographer by their subdomain
 func (r *PhotographerRepository) GetBySubdomain(ctx context.Context, subdomain string) (*photographer.Photographer, error) {
 	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
@@ -293,3 +404,30 @@ func (r *PhotographerRepository) ClearDomain(ctx context.Context, userID string)
 
 	return nil
 }
+
+// SetWatermarkLogo records the key of a photographer's watermark logo, or removes it when key is empty
+func (r *PhotographerRepository) SetWatermarkLogo(ctx context.Context, userID, key string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 photographerKey(userID),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("SET updatedAt = :updatedAt REMOVE watermarkLogoKey"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	}
+	if key != "" {
+		input.UpdateExpression = aws.String("SET updatedAt = :updatedAt, watermarkLogoKey = :key")
+		input.ExpressionAttributeValues[":key"] = &types.AttributeValueMemberS{Value: key}
+	}
+
+	if _, err := r.client.UpdateItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return photographer.ErrNotFound
+		}
+		return fmt.Errorf("failed to set watermark logo: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+type ProcessingProfileRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewProcessingProfileRepository(client *dynamodb.Client, tableName string) *ProcessingProfileRepository {
+	return &ProcessingProfileRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type processingProfileItem struct {
+	PK             string                      `dynamodbav:"PK"`
+	SK             string                      `dynamodbav:"SK"`
+	ProfileID      string                      `dynamodbav:"profileId"`
+	PhotographerID string                      `dynamodbav:"photographerId"`
+	Name           string                      `dynamodbav:"name"`
+	Steps          []repository.ProcessingStep `dynamodbav:"steps,omitempty"`
+	Format         string                      `dynamodbav:"format,omitempty"`
+	Quality        int                         `dynamodbav:"quality,omitempty"`
+	CreatedAt      string                      `dynamodbav:"createdAt"`
+	UpdatedAt      string                      `dynamodbav:"updatedAt"`
+}
+
+func (r *ProcessingProfileRepository) Create(ctx context.Context, profile *repository.ProcessingProfile) error {
+	return r.put(ctx, profile)
+}
+
+func (r *ProcessingProfileRepository) GetByID(ctx context.Context, profileID string) (*repository.ProcessingProfile, error) {
+	// Query using GSI1 (ProfileIdIndex)
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("ProfileIdIndex"),
+		KeyConditionExpression: aws.String("profileId = :profileId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":profileId": &types.AttributeValueMemberS{Value: profileID},
+		},
+		Limit: aws.Int32(1),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processing profile: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var item processingProfileItem
+	if err := attributevalue.UnmarshalMap(result.Items[0], &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal processing profile: %w", err)
+	}
+
+	return itemToProcessingProfile(&item), nil
+}
+
+func (r *ProcessingProfileRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.ProcessingProfile, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", photographerID)},
+			":sk": &types.AttributeValueMemberS{Value: "PROFILE#"},
+		},
+	}
+
+	var profiles []*repository.ProcessingProfile
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processing profiles: %w", err)
+		}
+		for _, item := range page.Items {
+			var profileItem processingProfileItem
+			if err := attributevalue.UnmarshalMap(item, &profileItem); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal processing profile: %w", err)
+			}
+			profiles = append(profiles, itemToProcessingProfile(&profileItem))
+		}
+	}
+
+	return profiles, nil
+}
+
+func (r *ProcessingProfileRepository) Update(ctx context.Context, profile *repository.ProcessingProfile) error {
+	return r.put(ctx, profile)
+}
+
+func (r *ProcessingProfileRepository) Delete(ctx context.Context, profileID string) error {
+	// First get the profile to get the photographer ID
+	profile, err := r.GetByID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return nil
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", profile.PhotographerID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PROFILE#%s", profileID)},
+		},
+	})
+
+	return err
+}
+
+func (r *ProcessingProfileRepository) put(ctx context.Context, profile *repository.ProcessingProfile) error {
+	item := processingProfileItem{
+		PK:             fmt.Sprintf("PHOTOGRAPHER#%s", profile.PhotographerID),
+		SK:             fmt.Sprintf("PROFILE#%s", profile.ProfileID),
+		ProfileID:      profile.ProfileID,
+		PhotographerID: profile.PhotographerID,
+		Name:           profile.Name,
+		Steps:          profile.Steps,
+		Format:         profile.Format,
+		Quality:        profile.Quality,
+		CreatedAt:      profile.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      profile.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal processing profile: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+
+	return err
+}
+
+func itemToProcessingProfile(item *processingProfileItem) *repository.ProcessingProfile {
+	profile := &repository.ProcessingProfile{
+		ProfileID:      item.ProfileID,
+		PhotographerID: item.PhotographerID,
+		Name:           item.Name,
+		Steps:          item.Steps,
+		Format:         item.Format,
+		Quality:        item.Quality,
+	}
+
+	if t, err := parseTime(item.CreatedAt); err == nil {
+		profile.CreatedAt = t
+	}
+	if t, err := parseTime(item.UpdatedAt); err == nil {
+		profile.UpdatedAt = t
+	}
+
+	return profile
+}
@@ -0,0 +1,156 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+type SelectionRepository struct {
+	client    *dynamodb.Client
+	tableName string
+	outbox    *OutboxRepository
+}
+
+func NewSelectionRepository(client *dynamodb.Client, tableName string) *SelectionRepository {
+	return &SelectionRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// WithOutbox makes entity writes persist any events staged on the context in the same transaction.
+func (r *SelectionRepository) WithOutbox(outbox *OutboxRepository) *SelectionRepository {
+	r.outbox = outbox
+	return r
+}
+
+type selectionItem struct {
+	PK          string  `dynamodbav:"PK"`
+	SK          string  `dynamodbav:"SK"`
+	GalleryID   string  `dynamodbav:"galleryId"`
+	SessionID   string  `dynamodbav:"sessionId"`
+	Status      string  `dynamodbav:"status"`
+	PhotoCount  int     `dynamodbav:"photoCount"`
+	SubmittedAt *string `dynamodbav:"submittedAt,omitempty"`
+	ReopenedAt  *string `dynamodbav:"reopenedAt,omitempty"`
+	UpdatedAt   string  `dynamodbav:"updatedAt"`
+}
+
+func (r *SelectionRepository) Create(ctx context.Context, selection *repository.Selection) error {
+	return r.put(ctx, selection)
+}
+
+func (r *SelectionRepository) GetByID(ctx context.Context, galleryID, sessionID string) (*repository.Selection, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", galleryID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("SESSION#%s", sessionID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get selection: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item selectionItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal selection: %w", err)
+	}
+
+	return itemToSelection(&item), nil
+}
+
+func (r *SelectionRepository) Update(ctx context.Context, selection *repository.Selection) error {
+	return r.put(ctx, selection)
+}
+
+func (r *SelectionRepository) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Selection, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("GALLERY#%s", galleryID)},
+		},
+	})
+
+	var selections []*repository.Selection
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list selections by gallery: %w", err)
+		}
+		for _, av := range page.Items {
+			var item selectionItem
+			if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal selection: %w", err)
+			}
+			selections = append(selections, itemToSelection(&item))
+		}
+	}
+
+	return selections, nil
+}
+
+func (r *SelectionRepository) put(ctx context.Context, selection *repository.Selection) error {
+	item := selectionItem{
+		PK:         fmt.Sprintf("GALLERY#%s", selection.GalleryID),
+		SK:         fmt.Sprintf("SESSION#%s", selection.SessionID),
+		GalleryID:  selection.GalleryID,
+		SessionID:  selection.SessionID,
+		Status:     selection.Status,
+		PhotoCount: selection.PhotoCount,
+		UpdatedAt:  selection.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if selection.SubmittedAt != nil {
+		submittedAt := selection.SubmittedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.SubmittedAt = &submittedAt
+	}
+	if selection.ReopenedAt != nil {
+		reopenedAt := selection.ReopenedAt.Format("2006-01-02T15:04:05Z07:00")
+		item.ReopenedAt = &reopenedAt
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal selection: %w", err)
+	}
+
+	return putWithOutbox(ctx, r.client, r.outbox, r.tableName, av)
+}
+
+func itemToSelection(item *selectionItem) *repository.Selection {
+	selection := &repository.Selection{
+		GalleryID:  item.GalleryID,
+		SessionID:  item.SessionID,
+		Status:     item.Status,
+		PhotoCount: item.PhotoCount,
+	}
+
+	if t, err := parseTime(item.UpdatedAt); err == nil {
+		selection.UpdatedAt = t
+	}
+	if item.SubmittedAt != nil {
+		if t, err := parseTime(*item.SubmittedAt); err == nil {
+			selection.SubmittedAt = &t
+		}
+	}
+	if item.ReopenedAt != nil {
+		if t, err := parseTime(*item.ReopenedAt); err == nil {
+			selection.ReopenedAt = &t
+		}
+	}
+
+	return selection
+}
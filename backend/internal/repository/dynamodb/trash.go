@@ -0,0 +1,194 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// trashStatus partitions the purge index; every trash item shares it
+const trashStatus = "trashed"
+
+type TrashRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewTrashRepository(client *dynamodb.Client, tableName string) *TrashRepository {
+	return &TrashRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type trashItem struct {
+	PK             string `dynamodbav:"PK"`
+	SK             string `dynamodbav:"SK"`
+	PhotographerID string `dynamodbav:"photographerId"`
+	ItemID         string `dynamodbav:"itemId"`
+	ItemType       string `dynamodbav:"itemType"`
+	GalleryID      string `dynamodbav:"galleryId"`
+	Name           string `dynamodbav:"name"`
+	PhotoCount     int    `dynamodbav:"photoCount"`
+	Size           int64  `dynamodbav:"size"`
+	Status         string `dynamodbav:"status"`
+	DeletedAt      string `dynamodbav:"deletedAt"`
+	PurgeAt        string `dynamodbav:"purgeAt"`
+}
+
+func trashKey(photographerID, itemID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", photographerID)},
+		"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("ITEM#%s", itemID)},
+	}
+}
+
+func (r *TrashRepository) Create(ctx context.Context, trash *repository.TrashItem) error {
+	item := trashItem{
+		PK:             fmt.Sprintf("PHOTOGRAPHER#%s", trash.PhotographerID),
+		SK:             fmt.Sprintf("ITEM#%s", trash.ItemID),
+		PhotographerID: trash.PhotographerID,
+		ItemID:         trash.ItemID,
+		ItemType:       trash.ItemType,
+		GalleryID:      trash.GalleryID,
+		Name:           trash.Name,
+		PhotoCount:     trash.PhotoCount,
+		Size:           trash.Size,
+		Status:         trashStatus,
+		DeletedAt:      trash.DeletedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		PurgeAt:        trash.PurgeAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash item: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create trash item: %w", err)
+	}
+	return nil
+}
+
+func (r *TrashRepository) GetByID(ctx context.Context, photographerID, itemID string) (*repository.TrashItem, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       trashKey(photographerID, itemID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash item: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item trashItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trash item: %w", err)
+	}
+
+	return itemToTrash(&item), nil
+}
+
+func (r *TrashRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.TrashItem, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", photographerID)},
+		},
+	})
+
+	var items []*repository.TrashItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list trash: %w", err)
+		}
+		trash, err := unmarshalTrash(page.Items)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, trash...)
+	}
+
+	return items, nil
+}
+
+func (r *TrashRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*repository.TrashItem, error) {
+	// Query using GSI1 (StatusPurgeAtIndex) for items whose retention has elapsed
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("StatusPurgeAtIndex"),
+		KeyConditionExpression: aws.String("#status = :status AND purgeAt <= :before"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: trashStatus},
+			":before": &types.AttributeValueMemberS{Value: before.UTC().Format("2006-01-02T15:04:05Z07:00")},
+		},
+		Limit: aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due trash items: %w", err)
+	}
+
+	return unmarshalTrash(result.Items)
+}
+
+func (r *TrashRepository) Delete(ctx context.Context, photographerID, itemID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       trashKey(photographerID, itemID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete trash item: %w", err)
+	}
+	return nil
+}
+
+func unmarshalTrash(items []map[string]types.AttributeValue) ([]*repository.TrashItem, error) {
+	trash := make([]*repository.TrashItem, 0, len(items))
+	for _, av := range items {
+		var item trashItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trash item: %w", err)
+		}
+		trash = append(trash, itemToTrash(&item))
+	}
+	return trash, nil
+}
+
+func itemToTrash(item *trashItem) *repository.TrashItem {
+	trash := &repository.TrashItem{
+		PhotographerID: item.PhotographerID,
+		ItemID:         item.ItemID,
+		ItemType:       item.ItemType,
+		GalleryID:      item.GalleryID,
+		Name:           item.Name,
+		PhotoCount:     item.PhotoCount,
+		Size:           item.Size,
+	}
+
+	if t, err := parseTime(item.DeletedAt); err == nil {
+		trash.DeletedAt = t
+	}
+	if t, err := parseTime(item.PurgeAt); err == nil {
+		trash.PurgeAt = t
+	}
+
+	return trash
+}
@@ -0,0 +1,174 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+type WebhookRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewWebhookRepository(client *dynamodb.Client, tableName string) *WebhookRepository {
+	return &WebhookRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type webhookItem struct {
+	PK             string   `dynamodbav:"PK"`
+	SK             string   `dynamodbav:"SK"`
+	WebhookID      string   `dynamodbav:"webhookId"`
+	PhotographerID string   `dynamodbav:"photographerId"`
+	URL            string   `dynamodbav:"url"`
+	Secret         string   `dynamodbav:"secret"`
+	EventTypes     []string `dynamodbav:"eventTypes,omitempty"`
+	Description    string   `dynamodbav:"description,omitempty"`
+	Active         bool     `dynamodbav:"active"`
+	CreatedAt      string   `dynamodbav:"createdAt"`
+	UpdatedAt      string   `dynamodbav:"updatedAt"`
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *repository.Webhook) error {
+	return r.put(ctx, webhook)
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, webhookID string) (*repository.Webhook, error) {
+	// Query using GSI1 (WebhookIdIndex)
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("WebhookIdIndex"),
+		KeyConditionExpression: aws.String("webhookId = :webhookId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":webhookId": &types.AttributeValueMemberS{Value: webhookID},
+		},
+		Limit: aws.Int32(1),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	var item webhookItem
+	if err := attributevalue.UnmarshalMap(result.Items[0], &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook: %w", err)
+	}
+
+	return itemToWebhook(&item), nil
+}
+
+func (r *WebhookRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.Webhook, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", photographerID)},
+			":sk": &types.AttributeValueMemberS{Value: "WEBHOOK#"},
+		},
+	}
+
+	var webhooks []*repository.Webhook
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+		for _, item := range page.Items {
+			var webhookItem webhookItem
+			if err := attributevalue.UnmarshalMap(item, &webhookItem); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal webhook: %w", err)
+			}
+			webhooks = append(webhooks, itemToWebhook(&webhookItem))
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook *repository.Webhook) error {
+	return r.put(ctx, webhook)
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, webhookID string) error {
+	// First get the webhook to get the photographer ID
+	webhook, err := r.GetByID(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+	if webhook == nil {
+		return nil
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("PHOTOGRAPHER#%s", webhook.PhotographerID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("WEBHOOK#%s", webhookID)},
+		},
+	})
+
+	return err
+}
+
+func (r *WebhookRepository) put(ctx context.Context, webhook *repository.Webhook) error {
+	item := webhookItem{
+		PK:             fmt.Sprintf("PHOTOGRAPHER#%s", webhook.PhotographerID),
+		SK:             fmt.Sprintf("WEBHOOK#%s", webhook.WebhookID),
+		WebhookID:      webhook.WebhookID,
+		PhotographerID: webhook.PhotographerID,
+		URL:            webhook.URL,
+		Secret:         webhook.Secret,
+		EventTypes:     webhook.EventTypes,
+		Description:    webhook.Description,
+		Active:         webhook.Active,
+		CreatedAt:      webhook.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      webhook.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+
+	return err
+}
+
+func itemToWebhook(item *webhookItem) *repository.Webhook {
+	webhook := &repository.Webhook{
+		WebhookID:      item.WebhookID,
+		PhotographerID: item.PhotographerID,
+		URL:            item.URL,
+		Secret:         item.Secret,
+		EventTypes:     item.EventTypes,
+		Description:    item.Description,
+		Active:         item.Active,
+	}
+
+	if t, err := parseTime(item.CreatedAt); err == nil {
+		webhook.CreatedAt = t
+	}
+	if t, err := parseTime(item.UpdatedAt); err == nil {
+		webhook.UpdatedAt = t
+	}
+
+	return webhook
+}
@@ -0,0 +1,253 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// deliveryRetention is how long delivery log entries are kept before DynamoDB TTL removes them.
+const deliveryRetention = 30 * 24 * time.Hour
+
+type WebhookDeliveryRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewWebhookDeliveryRepository(client *dynamodb.Client, tableName string) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+type webhookDeliveryItem struct {
+	PK             string  `dynamodbav:"PK"`
+	SK             string  `dynamodbav:"SK"`
+	DeliveryID     string  `dynamodbav:"deliveryId"`
+	WebhookID      string  `dynamodbav:"webhookId"`
+	PhotographerID string  `dynamodbav:"photographerId"`
+	EventID        string  `dynamodbav:"eventId"`
+	EventType      string  `dynamodbav:"eventType"`
+	Payload        string  `dynamodbav:"payload"`
+	Status         string  `dynamodbav:"status"`
+	Attempts       int     `dynamodbav:"attempts"`
+	ResponseStatus int     `dynamodbav:"responseStatus,omitempty"`
+	LastError      string  `dynamodbav:"lastError,omitempty"`
+	ReplayOf       string  `dynamodbav:"replayOf,omitempty"`
+	CreatedAt      string  `dynamodbav:"createdAt"`
+	NextAttemptAt  *string `dynamodbav:"nextAttemptAt,omitempty"` // only set while pending, keeps the due index sparse
+	CompletedAt    *string `dynamodbav:"completedAt,omitempty"`
+	TTL            int64   `dynamodbav:"ttl"`
+}
+
+func deliveryKey(deliveryID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("DELIVERY#%s", deliveryID)},
+		"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+	}
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *repository.WebhookDelivery) error {
+	av, err := attributevalue.MarshalMap(deliveryToItem(delivery))
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+		// Deliveries derived from the same event reuse their ID, so redelivered events are recorded once
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return repository.ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, deliveryID string) (*repository.WebhookDelivery, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       deliveryKey(deliveryID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item webhookDeliveryItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook delivery: %w", err)
+	}
+
+	return itemToDelivery(&item), nil
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *repository.WebhookDelivery) error {
+	av, err := attributevalue.MarshalMap(deliveryToItem(delivery))
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+
+	return err
+}
+
+func (r *WebhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID string, limit int, lastEvaluatedKey map[string]interface{}) ([]*repository.WebhookDelivery, map[string]interface{}, error) {
+	// Query using GSI1 (WebhookCreatedAtIndex)
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("WebhookCreatedAtIndex"),
+		KeyConditionExpression: aws.String("webhookId = :webhookId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":webhookId": &types.AttributeValueMemberS{Value: webhookID},
+		},
+		Limit:            aws.Int32(int32(limit)),
+		ScanIndexForward: aws.Bool(false), // Most recent first
+	}
+
+	if lastEvaluatedKey != nil {
+		exclusiveStartKey, err := attributevalue.MarshalMap(lastEvaluatedKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal last evaluated key: %w", err)
+		}
+		input.ExclusiveStartKey = exclusiveStartKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries, err := unmarshalDeliveries(result.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nextKey map[string]interface{}
+	if result.LastEvaluatedKey != nil {
+		if err := attributevalue.UnmarshalMap(result.LastEvaluatedKey, &nextKey); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal last evaluated key: %w", err)
+		}
+	}
+
+	return deliveries, nextKey, nil
+}
+
+func (r *WebhookDeliveryRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*repository.WebhookDelivery, error) {
+	// Query using GSI2 (StatusNextAttemptIndex) for pending deliveries whose retry time has passed
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("StatusNextAttemptIndex"),
+		KeyConditionExpression: aws.String("#status = :status AND nextAttemptAt <= :before"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: "pending"},
+			":before": &types.AttributeValueMemberS{Value: before.UTC().Format("2006-01-02T15:04:05Z07:00")},
+		},
+		Limit: aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	return unmarshalDeliveries(result.Items)
+}
+
+func unmarshalDeliveries(items []map[string]types.AttributeValue) ([]*repository.WebhookDelivery, error) {
+	deliveries := make([]*repository.WebhookDelivery, 0, len(items))
+	for _, item := range items {
+		var deliveryItem webhookDeliveryItem
+		if err := attributevalue.UnmarshalMap(item, &deliveryItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, itemToDelivery(&deliveryItem))
+	}
+	return deliveries, nil
+}
+
+func deliveryToItem(delivery *repository.WebhookDelivery) webhookDeliveryItem {
+	item := webhookDeliveryItem{
+		PK:             fmt.Sprintf("DELIVERY#%s", delivery.DeliveryID),
+		SK:             "METADATA",
+		DeliveryID:     delivery.DeliveryID,
+		WebhookID:      delivery.WebhookID,
+		PhotographerID: delivery.PhotographerID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		ReplayOf:       delivery.ReplayOf,
+		CreatedAt:      delivery.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		TTL:            delivery.CreatedAt.Add(deliveryRetention).Unix(),
+	}
+
+	if delivery.NextAttemptAt != nil {
+		nextAttemptAt := delivery.NextAttemptAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+		item.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.CompletedAt != nil {
+		completedAt := delivery.CompletedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+		item.CompletedAt = &completedAt
+	}
+
+	return item
+}
+
+func itemToDelivery(item *webhookDeliveryItem) *repository.WebhookDelivery {
+	delivery := &repository.WebhookDelivery{
+		DeliveryID:     item.DeliveryID,
+		WebhookID:      item.WebhookID,
+		PhotographerID: item.PhotographerID,
+		EventID:        item.EventID,
+		EventType:      item.EventType,
+		Payload:        item.Payload,
+		Status:         item.Status,
+		Attempts:       item.Attempts,
+		ResponseStatus: item.ResponseStatus,
+		LastError:      item.LastError,
+		ReplayOf:       item.ReplayOf,
+	}
+
+	if t, err := parseTime(item.CreatedAt); err == nil {
+		delivery.CreatedAt = t
+	}
+	if item.NextAttemptAt != nil {
+		if t, err := parseTime(*item.NextAttemptAt); err == nil {
+			delivery.NextAttemptAt = &t
+		}
+	}
+	if item.CompletedAt != nil {
+		if t, err := parseTime(*item.CompletedAt); err == nil {
+			delivery.CompletedAt = &t
+		}
+	}
+
+	return delivery
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "errors"
+
+// ErrAlreadyExists is returned by conditional creates when the item is already stored.
+var ErrAlreadyExists = errors.New("item already exists")
+
+// ErrConflict is returned by conditional updates when the item changed after it was read.
+// Callers read the item again and reapply their change.
+var ErrConflict = errors.New("item was changed concurrently")
@@ -27,43 +27,223 @@ type Gallery struct {
 	Password          string    `dynamodbav:"password" json:"-"` // bcrypt hash, never expose in JSON
 	CreatedAt         time.Time `dynamodbav:"createdAt" json:"createdAt"`
 	ExpiresAt         *time.Time `dynamodbav:"expiresAt,omitempty" json:"expiresAt,omitempty"`
-	Status            string    `dynamodbav:"status" json:"status"` // active, expired, archived
+	Status            string    `dynamodbav:"status" json:"status"` // active, expired, archived, restoring, deleted
 	PhotoCount        int       `dynamodbav:"photoCount" json:"photoCount"`
 	TotalSize         int64     `dynamodbav:"totalSize" json:"totalSize"`
 	ClientAccessCount int       `dynamodbav:"clientAccessCount" json:"clientAccessCount"`
 	EnableWatermark   bool      `dynamodbav:"enableWatermark" json:"enableWatermark"`
 	WatermarkText     string    `dynamodbav:"watermarkText,omitempty" json:"watermarkText,omitempty"`
 	WatermarkPosition string    `dynamodbav:"watermarkPosition,omitempty" json:"watermarkPosition,omitempty"` // bottom-right, bottom-left, center
+	WatermarkFont     string    `dynamodbav:"watermarkFont,omitempty" json:"watermarkFont,omitempty"` // sans (default), sans-bold, sans-italic, mono
+	WatermarkSize     float64   `dynamodbav:"watermarkSize,omitempty" json:"watermarkSize,omitempty"` // font size as a percent of photo width, default 3
+	WatermarkColor    string    `dynamodbav:"watermarkColor,omitempty" json:"watermarkColor,omitempty"` // #rrggbb, default white
+	WatermarkOpacity  float64   `dynamodbav:"watermarkOpacity,omitempty" json:"watermarkOpacity,omitempty"` // 0-1, default 0.6
+	WatermarkMargin   float64   `dynamodbav:"watermarkMargin,omitempty" json:"watermarkMargin,omitempty"` // percent of photo width, default 2
+	WatermarkMode     string    `dynamodbav:"watermarkMode,omitempty" json:"watermarkMode,omitempty"` // single (default), diagonal, tiled
+	WatermarkRotation float64   `dynamodbav:"watermarkRotation,omitempty" json:"watermarkRotation,omitempty"` // degrees counter-clockwise
+	WatermarkType     string    `dynamodbav:"watermarkType,omitempty" json:"watermarkType,omitempty"` // text (default) or logo, the photographer's uploaded logo
+	WatermarkScale    float64   `dynamodbav:"watermarkScale,omitempty" json:"watermarkScale,omitempty"` // logo width as a percent of photo width, default 15
+	ThumbnailCrop     string    `dynamodbav:"thumbnailCrop,omitempty" json:"thumbnailCrop,omitempty"` // center (default) or smart, content-aware
+	DownloadPolicy    string    `dynamodbav:"downloadPolicy,omitempty" json:"downloadPolicy,omitempty"` // optimized (default), originals, none
+	ProofingEnabled   bool      `dynamodbav:"proofingEnabled" json:"proofingEnabled"`
+	SelectionLimit    int       `dynamodbav:"selectionLimit,omitempty" json:"selectionLimit,omitempty"` // max favorites per client in proofing mode, 0 = unlimited
+	ProcessingProfileID string   `dynamodbav:"processingProfileId,omitempty" json:"processingProfileId,omitempty"` // the stored profile the optimized version is made with, empty for the default
+	ArchivedAt        *time.Time `dynamodbav:"archivedAt,omitempty" json:"archivedAt,omitempty"`
+	DeletedAt         *time.Time `dynamodbav:"deletedAt,omitempty" json:"deletedAt,omitempty"` // set while the gallery is in the trash
+}
+
+// Gallery statuses. Expired galleries are archived: originals move to cold storage and
+// clients lose access until the photographer restores the gallery.
+const (
+	GalleryStatusActive    = "active"
+	GalleryStatusArchived  = "archived"
+	GalleryStatusRestoring = "restoring"
+	GalleryStatusDeleted   = "deleted" // in the trash
+)
+
+// Gallery watermark types
+const (
+	WatermarkTypeText = "text"
+	WatermarkTypeLogo = "logo" // the photographer's uploaded logo
+)
+
+// Gallery thumbnail crops
+const (
+	ThumbnailCropCenter = "center"
+	ThumbnailCropSmart  = "smart" // around the most detailed, colorful part of the photo
+)
+
+// Gallery download policies control what clients may download
+const (
+	DownloadPolicyOptimized = "optimized"
+	DownloadPolicyOriginals = "originals"
+	DownloadPolicyNone      = "none"
+)
+
+// ProcessingProfile is a photographer's named recipe for optimized photos: the steps applied
+// to the original, in order, and the format and quality they're encoded with. Galleries use a
+// profile by its ID, so several galleries can share one.
+type ProcessingProfile struct {
+	ProfileID      string           `dynamodbav:"profileId" json:"profileId"`
+	PhotographerID string           `dynamodbav:"photographerId" json:"photographerId"`
+	Name           string           `dynamodbav:"name" json:"name"`
+	Steps          []ProcessingStep `dynamodbav:"steps,omitempty" json:"steps,omitempty"`
+	Format         string           `dynamodbav:"format,omitempty" json:"format,omitempty"`   // jpeg (default), png or webp
+	Quality        int              `dynamodbav:"quality,omitempty" json:"quality,omitempty"` // 1-100 for lossy formats, default 85
+	CreatedAt      time.Time        `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time        `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// ProcessingStep is one step of a processing profile. Which fields apply depends on Type.
+type ProcessingStep struct {
+	Type   string  `dynamodbav:"type" json:"type"`                         // resize, sharpen, blur, grayscale or watermark
+	Width  int     `dynamodbav:"width,omitempty" json:"width,omitempty"`   // resize: largest width
+	Height int     `dynamodbav:"height,omitempty" json:"height,omitempty"` // resize: largest height
+	Sigma  float64 `dynamodbav:"sigma,omitempty" json:"sigma,omitempty"`   // sharpen and blur: strength
 }
 
 // Photo represents a photo in a gallery
 type Photo struct {
 	PhotoID          string            `dynamodbav:"photoId" json:"photoId"`
 	GalleryID        string            `dynamodbav:"galleryId" json:"galleryId"`
+	PhotographerID   string            `dynamodbav:"photographerId,omitempty" json:"-"` // owner of the photo's gallery, for listing failed photos across galleries
 	FileName         string            `dynamodbav:"fileName" json:"fileName"`
 	OriginalKey      string            `dynamodbav:"originalKey" json:"originalKey"`
 	OptimizedKey     string            `dynamodbav:"optimizedKey,omitempty" json:"optimizedKey,omitempty"`
 	ThumbnailKey     string            `dynamodbav:"thumbnailKey,omitempty" json:"thumbnailKey,omitempty"`
 	MimeType         string            `dynamodbav:"mimeType" json:"mimeType"`
 	Size             int64             `dynamodbav:"size" json:"size"`
+	OptimizedSize    int64             `dynamodbav:"optimizedSize,omitempty" json:"-"` // bytes stored for the optimized derivative
+	ThumbnailSize    int64             `dynamodbav:"thumbnailSize,omitempty" json:"-"` // bytes stored for the thumbnail
 	Width            int               `dynamodbav:"width,omitempty" json:"width,omitempty"`
 	Height           int               `dynamodbav:"height,omitempty" json:"height,omitempty"`
-	ProcessingStatus string            `dynamodbav:"processingStatus" json:"processingStatus"` // pending, processing, completed, failed
+	ProcessingStatus string            `dynamodbav:"processingStatus" json:"processingStatus"` // pending, processing, completed, failed, archived, restoring
 	UploadedAt       time.Time         `dynamodbav:"uploadedAt" json:"uploadedAt"`
 	ProcessedAt      *time.Time        `dynamodbav:"processedAt,omitempty" json:"processedAt,omitempty"`
 	FavoriteCount    int               `dynamodbav:"favoriteCount" json:"favoriteCount"`
 	DownloadCount    int               `dynamodbav:"downloadCount" json:"downloadCount"`
 	Metadata         map[string]string `dynamodbav:"metadata,omitempty" json:"metadata,omitempty"` // EXIF data
+	DeletedAt        *time.Time        `dynamodbav:"deletedAt,omitempty" json:"deletedAt,omitempty"` // set while the photo is in the trash
+	Renditions       []Rendition       `dynamodbav:"renditions,omitempty" json:"renditions,omitempty"` // ordered by width, for srcset
+	FocalPoint       *FocalPoint       `dynamodbav:"focalPoint,omitempty" json:"focalPoint,omitempty"` // set by the photographer, overrides the gallery's thumbnail crop
+	BlurHash         string            `dynamodbav:"blurHash,omitempty" json:"blurHash,omitempty"` // shown blurred while the photo loads
+	DominantColor    string            `dynamodbav:"dominantColor,omitempty" json:"dominantColor,omitempty"` // #rrggbb, placeholder for clients without BlurHash
+	Failure          *ProcessingFailure `dynamodbav:"failure,omitempty" json:"failure,omitempty"` // why processing last failed; cleared once it succeeds
+	SourceVersion    string            `dynamodbav:"sourceVersion,omitempty" json:"-"` // S3 version ID, or ETag, of the original the derivatives were made from
+	Version          int               `dynamodbav:"version" json:"-"` // incremented by every write, for optimistic locking
+}
+
+// ProcessingFailure records why a photo couldn't be processed. Category and Message describe
+// the latest failure; Attempts keeps the most recent ones, oldest first.
+type ProcessingFailure struct {
+	Category string          `dynamodbav:"category" json:"category"` // corrupt_file, unsupported_format, too_large or internal_error
+	Message  string          `dynamodbav:"message" json:"message"`
+	Attempts []FailedAttempt `dynamodbav:"attempts,omitempty" json:"attempts,omitempty"`
+}
+
+// FailedAttempt is one failed attempt at processing a photo
+type FailedAttempt struct {
+	At       time.Time `dynamodbav:"at" json:"at"`
+	Category string    `dynamodbav:"category" json:"category"`
+	Message  string    `dynamodbav:"message" json:"message"`
 }
 
-// Favorite represents a client's favorite photo
+// FocalPoint is the point of a photo its thumbnail is cropped around, relative to the photo's
+// width and height: (0, 0) is the top-left corner, (1, 1) the bottom-right.
+type FocalPoint struct {
+	X float64 `dynamodbav:"x" json:"x"`
+	Y float64 `dynamodbav:"y" json:"y"`
+}
+
+// Rendition is one of the sizes a photo is processed into for responsive display.
+// Key is a JPEG; Formats lists the same image in smaller formats browsers may accept.
+type Rendition struct {
+	Name    string            `dynamodbav:"name" json:"name"`
+	Key     string            `dynamodbav:"key" json:"key"`
+	Width   int               `dynamodbav:"width" json:"width"`
+	Height  int               `dynamodbav:"height" json:"height"`
+	Size    int64             `dynamodbav:"size" json:"-"`
+	Formats []RenditionFormat `dynamodbav:"formats,omitempty" json:"formats,omitempty"`
+}
+
+// RenditionFormat is a rendition encoded in an alternate format such as webp
+type RenditionFormat struct {
+	Format string `dynamodbav:"format" json:"format"`
+	Key    string `dynamodbav:"key" json:"key"`
+	Size   int64  `dynamodbav:"size" json:"-"`
+}
+
+// TotalSize returns the bytes stored for the rendition across all its formats
+func (r Rendition) TotalSize() int64 {
+	total := r.Size
+	for _, f := range r.Formats {
+		total += f.Size
+	}
+	return total
+}
+
+// IsFailed reports whether the photo's processing failed, whether or not retries are exhausted
+func (p *Photo) IsFailed() bool {
+	return p.ProcessingStatus == "failed" || p.ProcessingStatus == "failed_permanent"
+}
+
+// RenditionKeys returns the storage keys of the photo's renditions in every format
+func (p *Photo) RenditionKeys() []string {
+	keys := make([]string, 0, len(p.Renditions))
+	for _, r := range p.Renditions {
+		keys = append(keys, r.Key)
+		for _, f := range r.Formats {
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys
+}
+
+// Favorite represents a photo in one of a client's favorites lists
 type Favorite struct {
 	GalleryID   string    `dynamodbav:"galleryId" json:"galleryId"`
 	SessionID   string    `dynamodbav:"sessionId" json:"sessionId"`
+	ListID      string    `dynamodbav:"listId" json:"listId"`
 	PhotoID     string    `dynamodbav:"photoId" json:"photoId"`
 	FavoritedAt time.Time `dynamodbav:"favoritedAt" json:"favoritedAt"`
 }
 
+// FavoriteList is a named list of favorites kept by a client session
+type FavoriteList struct {
+	ListID     string    `dynamodbav:"listId" json:"listId"`
+	GalleryID  string    `dynamodbav:"galleryId" json:"galleryId"`
+	SessionID  string    `dynamodbav:"sessionId" json:"sessionId"`
+	Name       string    `dynamodbav:"name" json:"name"`
+	PhotoCount int       `dynamodbav:"-" json:"photoCount"` // computed on read
+	CreatedAt  time.Time `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// DefaultFavoriteListID identifies the list every client session starts with.
+// Favorites saved before named lists existed belong to it, and it holds the proofing selection.
+const DefaultFavoriteListID = "default"
+
+// DefaultFavoriteListName is the name of the default list until the client renames it
+const DefaultFavoriteListName = "Favorites"
+
+// Selection records a client session's proofing selection and whether it has been submitted.
+// The selected photos themselves are the favorites in the session's default list.
+type Selection struct {
+	GalleryID   string     `dynamodbav:"galleryId" json:"galleryId"`
+	SessionID   string     `dynamodbav:"sessionId" json:"sessionId"`
+	Status      string     `dynamodbav:"status" json:"status"` // open, submitted
+	PhotoCount  int        `dynamodbav:"photoCount" json:"photoCount"`
+	SubmittedAt *time.Time `dynamodbav:"submittedAt,omitempty" json:"submittedAt,omitempty"`
+	ReopenedAt  *time.Time `dynamodbav:"reopenedAt,omitempty" json:"reopenedAt,omitempty"`
+	UpdatedAt   time.Time  `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// Selection statuses
+const (
+	SelectionStatusOpen      = "open"
+	SelectionStatusSubmitted = "submitted"
+)
+
 // ClientSession represents a client's session for gallery access
 type ClientSession struct {
 	SessionID     string    `dynamodbav:"sessionId" json:"sessionId"`
@@ -76,6 +256,89 @@ type ClientSession struct {
 	TTL           int64     `dynamodbav:"ttl" json:"-"` // Unix timestamp for DynamoDB TTL
 }
 
+// OutboxEvent represents a domain event persisted alongside the entity write that produced it
+type OutboxEvent struct {
+	EventID       string     `dynamodbav:"eventId" json:"eventId"`
+	EventType     string     `dynamodbav:"eventType" json:"eventType"`
+	Payload       string     `dynamodbav:"payload" json:"payload"` // JSON-encoded event payload
+	CorrelationID string     `dynamodbav:"correlationId" json:"correlationId"`
+	Status        string     `dynamodbav:"status" json:"status"` // pending, published, failed
+	Attempts      int        `dynamodbav:"attempts" json:"attempts"`
+	LastError     string     `dynamodbav:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt     time.Time  `dynamodbav:"createdAt" json:"createdAt"`
+	PublishedAt   *time.Time `dynamodbav:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+}
+
+// Webhook represents a photographer's HTTPS endpoint subscribed to domain events
+type Webhook struct {
+	WebhookID      string    `dynamodbav:"webhookId" json:"webhookId"`
+	PhotographerID string    `dynamodbav:"photographerId" json:"photographerId"`
+	URL            string    `dynamodbav:"url" json:"url"`
+	Secret         string    `dynamodbav:"secret" json:"-"` // HMAC signing key, only returned when created
+	EventTypes     []string  `dynamodbav:"eventTypes,omitempty" json:"eventTypes"` // empty means all events
+	Description    string    `dynamodbav:"description,omitempty" json:"description,omitempty"`
+	Active         bool      `dynamodbav:"active" json:"active"`
+	CreatedAt      time.Time `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// WebhookDelivery records one event delivered (or being delivered) to a webhook
+type WebhookDelivery struct {
+	DeliveryID     string     `dynamodbav:"deliveryId" json:"deliveryId"`
+	WebhookID      string     `dynamodbav:"webhookId" json:"webhookId"`
+	PhotographerID string     `dynamodbav:"photographerId" json:"photographerId"`
+	EventID        string     `dynamodbav:"eventId" json:"eventId"`
+	EventType      string     `dynamodbav:"eventType" json:"eventType"`
+	Payload        string     `dynamodbav:"payload" json:"payload"` // JSON request body sent to the endpoint
+	Status         string     `dynamodbav:"status" json:"status"` // pending, succeeded, failed
+	Attempts       int        `dynamodbav:"attempts" json:"attempts"`
+	ResponseStatus int        `dynamodbav:"responseStatus,omitempty" json:"responseStatus,omitempty"`
+	LastError      string     `dynamodbav:"lastError,omitempty" json:"lastError,omitempty"`
+	ReplayOf       string     `dynamodbav:"replayOf,omitempty" json:"replayOf,omitempty"`
+	CreatedAt      time.Time  `dynamodbav:"createdAt" json:"createdAt"`
+	NextAttemptAt  *time.Time `dynamodbav:"nextAttemptAt,omitempty" json:"nextAttemptAt,omitempty"`
+	CompletedAt    *time.Time `dynamodbav:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// DownloadJob tracks an asynchronous "download all" archive built for a client session
+type DownloadJob struct {
+	JobID           string     `dynamodbav:"jobId" json:"jobId"`
+	GalleryID       string     `dynamodbav:"galleryId" json:"galleryId"`
+	SessionID       string     `dynamodbav:"sessionId" json:"-"`
+	Scope           string     `dynamodbav:"scope" json:"scope"`     // gallery, favorites
+	Variant         string     `dynamodbav:"variant" json:"variant"` // optimized, original
+	Status          string     `dynamodbav:"status" json:"status"`   // pending, running, completed, failed
+	TotalPhotos     int        `dynamodbav:"totalPhotos" json:"totalPhotos"`
+	ProcessedPhotos int        `dynamodbav:"processedPhotos" json:"processedPhotos"`
+	ArchiveKey      string     `dynamodbav:"archiveKey" json:"-"`
+	ArchiveName     string     `dynamodbav:"archiveName" json:"archiveName"`
+	ArchiveSize     int64      `dynamodbav:"archiveSize,omitempty" json:"archiveSize,omitempty"`
+	LastError       string     `dynamodbav:"lastError,omitempty" json:"error,omitempty"`
+	CreatedAt       time.Time  `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time  `dynamodbav:"updatedAt" json:"updatedAt"`
+	CompletedAt     *time.Time `dynamodbav:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// TrashItem records a gallery or photo a photographer deleted. The gallery or photo itself is
+// kept, marked deleted, until the item is restored or purged after PurgeAt.
+type TrashItem struct {
+	PhotographerID string    `dynamodbav:"photographerId" json:"-"`
+	ItemID         string    `dynamodbav:"itemId" json:"itemId"`     // gallery or photo ID
+	ItemType       string    `dynamodbav:"itemType" json:"itemType"` // gallery, photo
+	GalleryID      string    `dynamodbav:"galleryId" json:"galleryId"`
+	Name           string    `dynamodbav:"name" json:"name"` // gallery name or photo file name
+	PhotoCount     int       `dynamodbav:"photoCount" json:"photoCount"`
+	Size           int64     `dynamodbav:"size" json:"size"`
+	DeletedAt      time.Time `dynamodbav:"deletedAt" json:"deletedAt"`
+	PurgeAt        time.Time `dynamodbav:"purgeAt" json:"purgeAt"`
+}
+
+// Trash item types
+const (
+	TrashItemGallery = "gallery"
+	TrashItemPhoto   = "photo"
+)
+
 // PhotographerRepository defines methods for photographer data operations
 type PhotographerRepository interface {
 	Create(ctx context.Context, photographer *Photographer) error
@@ -95,12 +358,16 @@ type GalleryRepository interface {
 	Update(ctx context.Context, gallery *Gallery) error
 	Delete(ctx context.Context, galleryID string) error
 	ListExpired(ctx context.Context, limit int) ([]*Gallery, error)
+	ListByStatus(ctx context.Context, status string, limit int) ([]*Gallery, error)
 	UpdatePhotoCount(ctx context.Context, galleryID string, delta int) error
 	UpdateTotalSize(ctx context.Context, galleryID string, deltaBytes int64) error
 	IncrementClientAccessCount(ctx context.Context, galleryID string) error
 }
 
-// PhotoRepository defines methods for photo data operations
+// PhotoRepository defines methods for photo data operations. Create returns ErrAlreadyExists
+// for a photo that's already stored. Update only succeeds if the photo's Version is still the
+// one stored, returning ErrConflict otherwise, and increments it. ListFailedByPhotographer
+// returns the failed photos of all a photographer's galleries, most recent failure first.
 type PhotoRepository interface {
 	Create(ctx context.Context, photo *Photo) error
 	GetByID(ctx context.Context, photoID string) (*Photo, error)
@@ -109,15 +376,35 @@ type PhotoRepository interface {
 	Delete(ctx context.Context, photoID string) error
 	IncrementFavoriteCount(ctx context.Context, photoID string, delta int) error
 	IncrementDownloadCount(ctx context.Context, photoID string) error
+	ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastEvaluatedKey map[string]interface{}) ([]*Photo, map[string]interface{}, error)
 }
 
-// FavoriteRepository defines methods for favorite data operations
+// FavoriteRepository defines methods for favorite and favorites list data operations.
+// ListBySession and ListByGallery return favorites from every list; GetList returns nil
+// for a list that has never been stored, which includes an unrenamed default list.
 type FavoriteRepository interface {
 	Create(ctx context.Context, favorite *Favorite) error
-	Delete(ctx context.Context, galleryID, sessionID, photoID string) error
-	IsFavorited(ctx context.Context, galleryID, sessionID, photoID string) (bool, error)
+	Delete(ctx context.Context, galleryID, sessionID, listID, photoID string) error
+	IsFavorited(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error)
 	ListBySession(ctx context.Context, galleryID, sessionID string) ([]*Favorite, error)
+	ListByList(ctx context.Context, galleryID, sessionID, listID string) ([]*Favorite, error)
 	ListByGallery(ctx context.Context, galleryID string) ([]*Favorite, error)
+
+	CreateList(ctx context.Context, list *FavoriteList) error
+	GetList(ctx context.Context, galleryID, sessionID, listID string) (*FavoriteList, error)
+	UpdateList(ctx context.Context, list *FavoriteList) error
+	DeleteList(ctx context.Context, galleryID, sessionID, listID string) error // also removes the list's favorites
+	ListLists(ctx context.Context, galleryID, sessionID string) ([]*FavoriteList, error)
+	ListListsByGallery(ctx context.Context, galleryID string) ([]*FavoriteList, error)
+}
+
+// SelectionRepository defines methods for proofing selection operations.
+// GetByID returns nil when the session has never submitted a selection.
+type SelectionRepository interface {
+	Create(ctx context.Context, selection *Selection) error
+	GetByID(ctx context.Context, galleryID, sessionID string) (*Selection, error)
+	Update(ctx context.Context, selection *Selection) error
+	ListByGallery(ctx context.Context, galleryID string) ([]*Selection, error)
 }
 
 // ClientSessionRepository defines methods for client session operations
@@ -127,3 +414,56 @@ type ClientSessionRepository interface {
 	Update(ctx context.Context, session *ClientSession) error
 	Delete(ctx context.Context, galleryID, sessionID string) error
 }
+
+// OutboxRepository defines methods for relaying outbox events.
+// Events are written by the entity repositories (see WithOutboxEvents), never directly.
+type OutboxRepository interface {
+	ListPending(ctx context.Context, limit int) ([]*OutboxEvent, error)
+	MarkPublished(ctx context.Context, eventID string) error
+	MarkFailed(ctx context.Context, eventID, lastError string, permanent bool) error
+}
+
+// ProcessingProfileRepository defines methods for photographers' processing profiles
+type ProcessingProfileRepository interface {
+	Create(ctx context.Context, profile *ProcessingProfile) error
+	GetByID(ctx context.Context, profileID string) (*ProcessingProfile, error)
+	ListByPhotographer(ctx context.Context, photographerID string) ([]*ProcessingProfile, error)
+	Update(ctx context.Context, profile *ProcessingProfile) error
+	Delete(ctx context.Context, profileID string) error
+}
+
+// WebhookRepository defines methods for webhook endpoint operations
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	GetByID(ctx context.Context, webhookID string) (*Webhook, error)
+	ListByPhotographer(ctx context.Context, photographerID string) ([]*Webhook, error)
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, webhookID string) error
+}
+
+// WebhookDeliveryRepository defines methods for the webhook delivery log.
+// Create returns ErrAlreadyExists when a delivery with the same ID was already recorded.
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	GetByID(ctx context.Context, deliveryID string) (*WebhookDelivery, error)
+	Update(ctx context.Context, delivery *WebhookDelivery) error
+	ListByWebhook(ctx context.Context, webhookID string, limit int, lastEvaluatedKey map[string]interface{}) ([]*WebhookDelivery, map[string]interface{}, error)
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*WebhookDelivery, error)
+}
+
+// TrashRepository defines methods for a photographer's trash.
+// GetByID returns nil when the item is not in the photographer's trash.
+type TrashRepository interface {
+	Create(ctx context.Context, item *TrashItem) error
+	GetByID(ctx context.Context, photographerID, itemID string) (*TrashItem, error)
+	ListByPhotographer(ctx context.Context, photographerID string) ([]*TrashItem, error)
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*TrashItem, error)
+	Delete(ctx context.Context, photographerID, itemID string) error
+}
+
+// DownloadJobRepository defines methods for client download jobs
+type DownloadJobRepository interface {
+	Create(ctx context.Context, job *DownloadJob) error
+	GetByID(ctx context.Context, jobID string) (*DownloadJob, error)
+	Update(ctx context.Context, job *DownloadJob) error
+}
@@ -82,6 +82,14 @@ func (r *LoggingGalleryRepository) ListExpired(ctx context.Context, limit int) (
 	return galleries, err
 }
 
+// ListByStatus logs gallery listing by status operations.
+func (r *LoggingGalleryRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*Gallery, error) {
+	start := time.Now()
+	galleries, err := r.repo.ListByStatus(ctx, status, limit)
+	r.logOperation("ListByStatus", status, start, err)
+	return galleries, err
+}
+
 // UpdateTotalSize logs total size update operations.
 func (r *LoggingGalleryRepository) UpdateTotalSize(ctx context.Context, galleryID string, deltaBytes int64) error {
 	start := time.Now()
@@ -182,6 +190,14 @@ func (r *LoggingPhotoRepository) IncrementDownloadCount(ctx context.Context, pho
 	return err
 }
 
+// ListFailedByPhotographer logs failed photo listing operations.
+func (r *LoggingPhotoRepository) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*Photo, map[string]interface{}, error) {
+	start := time.Now()
+	photos, nextKey, err := r.repo.ListFailedByPhotographer(ctx, photographerID, limit, lastKey)
+	r.logOperation("ListFailedByPhotographer", photographerID, start, err)
+	return photos, nextKey, err
+}
+
 func (r *LoggingPhotoRepository) logOperation(operation, identifier string, start time.Time, err error) {
 	duration := time.Since(start)
 	fields := map[string]interface{}{
@@ -16,6 +16,7 @@ type MockGalleryRepository struct {
 	ListByPhotographerFunc         func(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*Gallery, map[string]interface{}, error)
 	UpdatePhotoCountFunc           func(ctx context.Context, galleryID string, delta int) error
 	ListExpiredFunc                func(ctx context.Context, limit int) ([]*Gallery, error)
+	ListByStatusFunc               func(ctx context.Context, status string, limit int) ([]*Gallery, error)
 	UpdateTotalSizeFunc            func(ctx context.Context, galleryID string, deltaBytes int64) error
 	IncrementClientAccessCountFunc func(ctx context.Context, galleryID string) error
 }
@@ -76,6 +77,13 @@ func (m *MockGalleryRepository) ListExpired(ctx context.Context, limit int) ([]*
 	return []*Gallery{}, nil
 }
 
+func (m *MockGalleryRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*Gallery, error) {
+	if m.ListByStatusFunc != nil {
+		return m.ListByStatusFunc(ctx, status, limit)
+	}
+	return []*Gallery{}, nil
+}
+
 func (m *MockGalleryRepository) UpdateTotalSize(ctx context.Context, galleryID string, deltaBytes int64) error {
 	if m.UpdateTotalSizeFunc != nil {
 		return m.UpdateTotalSizeFunc(ctx, galleryID, deltaBytes)
@@ -267,6 +275,10 @@ func (m *MockPhotoRepository) IncrementDownloadCount(ctx context.Context, photoI
 	return nil
 }
 
+func (m *MockPhotoRepository) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*Photo, map[string]interface{}, error) {
+	return []*Photo{}, nil, nil
+}
+
 func TestLoggingPhotoRepositoryCreate(t *testing.T) {
 	mock := &MockPhotoRepository{}
 	logged := NewLoggingPhotoRepository(mock)
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"photographer-gallery/backend/pkg/events"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusFailed    = "failed"
+)
+
+type outboxContextKey struct{}
+
+// NewOutboxEvent converts a domain event into a pending outbox record.
+func NewOutboxEvent(event events.Event) (*OutboxEvent, error) {
+	payload, err := events.EncodePayload(event)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxEvent{
+		EventID:       event.ID(),
+		EventType:     string(event.Type()),
+		Payload:       string(payload),
+		CorrelationID: event.CorrelationID(),
+		Status:        OutboxStatusPending,
+		CreatedAt:     event.Timestamp(),
+	}, nil
+}
+
+// ToEvent rebuilds the domain event from an outbox record.
+func (e *OutboxEvent) ToEvent() (events.Event, error) {
+	payload, err := events.DecodePayload(events.EventType(e.EventType), []byte(e.Payload))
+	if err != nil {
+		return nil, err
+	}
+	return events.RestoreEvent(e.EventID, events.EventType(e.EventType), payload, e.CreatedAt, e.CorrelationID), nil
+}
+
+// WithOutboxEvents stages domain events on the context. The next entity write made
+// with the returned context persists them in the same transaction as the entity itself.
+func WithOutboxEvents(ctx context.Context, evts ...events.Event) (context.Context, error) {
+	staged := append([]*OutboxEvent{}, OutboxEventsFromContext(ctx)...)
+	for _, evt := range evts {
+		record, err := NewOutboxEvent(evt)
+		if err != nil {
+			return ctx, err
+		}
+		staged = append(staged, record)
+	}
+	return context.WithValue(ctx, outboxContextKey{}, staged), nil
+}
+
+// OutboxEventsFromContext returns the events staged on the context, if any.
+func OutboxEventsFromContext(ctx context.Context) []*OutboxEvent {
+	staged, _ := ctx.Value(outboxContextKey{}).([]*OutboxEvent)
+	return staged
+}
@@ -0,0 +1,49 @@
+package image
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Watermarks are composited with image/draw, which has fast paths for drawing RGBA, NRGBA,
+// YCbCr and Gray images onto an RGBA without a mask. Everything here is arranged to stay on
+// them: photos are converted to RGBA once, and opacity is folded into the overlay's alpha
+// rather than applied through a mask.
+
+// InPlaceStrategy is a strategy that can draw directly onto an image. StrategyChain uses it
+// for images an earlier strategy in the chain allocated, so stacked watermarks don't each
+// copy the whole photo.
+type InPlaceStrategy interface {
+	ProcessingStrategy
+	ProcessInPlace(img *image.RGBA) error
+}
+
+// cloneRGBA copies img into a new RGBA with its origin at 0,0
+func cloneRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// compositeOver alpha-blends src over dst with its top-left corner at x, y, clipped to dst
+func compositeOver(dst *image.RGBA, src image.Image, x, y int) {
+	r := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x, y).Add(src.Bounds().Size())}
+	draw.Draw(dst, r, src, src.Bounds().Min, draw.Over)
+}
+
+// fade scales the alpha of every pixel of img by opacity (0-1)
+func fade(img *image.NRGBA, opacity float64) {
+	if opacity >= 1 {
+		return
+	}
+	scale := uint32(math.Round(math.Max(opacity, 0) * 255))
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+b.Dx()*4]
+		for i := 3; i < len(row); i += 4 {
+			row[i] = uint8((uint32(row[i])*scale + 127) / 255)
+		}
+	}
+}
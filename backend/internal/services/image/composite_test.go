@@ -0,0 +1,129 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestStrategyChainDrawsInPlace(t *testing.T) {
+	p := NewProcessor()
+	src := solidImage(600, 300)
+	text := WatermarkOptions{Text: "© Studio", Mode: WatermarkModeTiled}
+	logo := LogoOptions{Position: "center", Scale: 30, Opacity: 0.5}
+
+	got, err := NewStrategyChain(
+		NewStyledWatermarkStrategy(text),
+		NewLogoWatermarkStrategy(whiteLogo(), logo),
+	).Process(src)
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+
+	if _, count := changedBounds(src); count != 0 {
+		t.Errorf("chain modified %d pixels of its input", count)
+	}
+	want := p.ApplyLogoWatermark(p.ApplyWatermark(src, text), whiteLogo(), logo)
+	if !bytes.Equal(got.(*image.RGBA).Pix, want.(*image.RGBA).Pix) {
+		t.Error("drawing in place gave a different result to applying each watermark to a copy")
+	}
+}
+
+func TestApplyWatermarkCopiesSource(t *testing.T) {
+	// A sub-image, so the source doesn't start at the origin
+	src := solidImage(800, 400).(*image.RGBA).SubImage(image.Rect(200, 100, 800, 400))
+
+	got := NewProcessor().ApplyWatermark(src, WatermarkOptions{Text: "© Studio", Mode: WatermarkModeDiagonal})
+	if got.Bounds() != image.Rect(0, 0, 600, 300) {
+		t.Errorf("bounds = %v, want 600x300 at the origin", got.Bounds())
+	}
+	if _, count := changedBounds(src); count != 0 {
+		t.Errorf("ApplyWatermark() modified %d pixels of its input", count)
+	}
+	if _, count := changedBounds(got); count == 0 {
+		t.Error("ApplyWatermark() drew nothing")
+	}
+}
+
+func TestFade(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 200})
+	img.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 255})
+
+	fade(img, 0.5)
+	if a := img.NRGBAAt(0, 0).A; a != 100 {
+		t.Errorf("alpha 200 at 50%% = %d, want 100", a)
+	}
+	if c := img.NRGBAAt(1, 0); c.G != 255 || c.A != 128 {
+		t.Errorf("opaque pixel at 50%% = %v, want its color with alpha 128", c)
+	}
+}
+
+// setLoopWatermark is how watermarks used to be composited, a pixel at a time through the
+// image.Image interface. It's the baseline for the benchmarks.
+func setLoopWatermark(img, stamp image.Image, x0, y0 int) *image.RGBA {
+	b := img.Bounds()
+	rgba := image.NewRGBA(b)
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			rgba.Set(px, py, img.At(px, py))
+		}
+	}
+	sb := stamp.Bounds()
+	for y := sb.Min.Y; y < sb.Max.Y; y++ {
+		for x := sb.Min.X; x < sb.Max.X; x++ {
+			s := color.NRGBAModel.Convert(stamp.At(x, y)).(color.NRGBA)
+			if s.A == 0 {
+				continue
+			}
+			d := rgba.RGBAAt(x0+x, y0+y)
+			a := uint32(s.A)
+			rgba.SetRGBA(x0+x, y0+y, color.RGBA{
+				R: uint8((uint32(s.R)*a + uint32(d.R)*(255-a)) / 255),
+				G: uint8((uint32(s.G)*a + uint32(d.G)*(255-a)) / 255),
+				B: uint8((uint32(s.B)*a + uint32(d.B)*(255-a)) / 255),
+				A: 255,
+			})
+		}
+	}
+	return rgba
+}
+
+// BenchmarkWatermark24MP composites watermarks onto a 24 MP photo. Throughput is in bytes of
+// RGBA output, so the sub-benchmarks compare directly.
+func BenchmarkWatermark24MP(b *testing.B) {
+	const width, height = 6000, 4000
+	p := NewProcessor()
+	rgba := solidImage(width, height)
+	ycbcr := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+	single := WatermarkOptions{Text: "© Studio"}
+	tiled := WatermarkOptions{Text: "© Studio", Mode: WatermarkModeTiled}
+	logo := LogoOptions{Opacity: 0.5}
+
+	run := func(name string, watermark func() image.Image) {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(width * height * 4)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				watermark()
+			}
+		})
+	}
+
+	stamp, err := renderWatermarkText(single.withDefaults(), width)
+	if err != nil {
+		b.Fatal(err)
+	}
+	run("set-loop", func() image.Image { return setLoopWatermark(rgba, stamp, width-1000, height-300) })
+	run("single", func() image.Image { return p.ApplyWatermark(rgba, single) })
+	run("single-ycbcr", func() image.Image { return p.ApplyWatermark(ycbcr, single) })
+	run("tiled", func() image.Image { return p.ApplyWatermark(rgba, tiled) })
+	run("logo", func() image.Image { return p.ApplyLogoWatermark(rgba, whiteLogo(), logo) })
+
+	chain := NewStrategyChain(NewStyledWatermarkStrategy(single), NewLogoWatermarkStrategy(whiteLogo(), logo))
+	run("text-and-logo", func() image.Image {
+		img, _ := chain.Process(rgba)
+		return img
+	})
+}
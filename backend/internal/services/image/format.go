@@ -0,0 +1,96 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Output formats an Encoder can produce
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+// DefaultAlternateFormats are generated next to the JPEG renditions for browsers that accept them
+var DefaultAlternateFormats = []string{FormatWebP}
+
+// ErrFormatUnavailable is returned for formats this build has no encoder for
+var ErrFormatUnavailable = errors.New("image format not available in this build")
+
+// ContentType returns the MIME type for an output format
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+// Extension returns the file extension, including the dot, for an output format
+func Extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// NewEncoder returns an encoder for format. quality is ignored by lossless formats.
+func NewEncoder(format string, quality int) (Encoder, error) {
+	switch format {
+	case FormatJPEG:
+		return NewJPEGEncoder(quality), nil
+	case FormatPNG:
+		return NewPNGEncoder(), nil
+	case FormatWebP:
+		if !webpAvailable {
+			return nil, fmt.Errorf("%w: webp requires a cgo build", ErrFormatUnavailable)
+		}
+		return NewWebPEncoder(quality), nil
+	case FormatAVIF:
+		// No AVIF encoder is linked in yet; configuring it is harmless and takes
+		// effect once one is.
+		return nil, fmt.Errorf("%w: avif", ErrFormatUnavailable)
+	default:
+		return nil, fmt.Errorf("unknown image format %q", format)
+	}
+}
+
+// ParseFormats parses a comma-separated list of alternate output formats, e.g. "avif,webp".
+// An empty string means no alternates.
+func ParseFormats(s string) ([]string, error) {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		format := strings.ToLower(strings.TrimSpace(part))
+		if format == "" {
+			continue
+		}
+		if format != FormatWebP && format != FormatAVIF {
+			return nil, fmt.Errorf("unsupported alternate format %q: expected webp or avif", format)
+		}
+		if seen[format] {
+			return nil, fmt.Errorf("duplicate format %q", format)
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// RenditionEncoders returns a JPEG encoder, which every browser can display, followed by
+// encoders for the alternate formats this build supports. Formats without an encoder are
+// returned separately so callers can report them.
+func RenditionEncoders(alternates []string, quality int) (encoders []Encoder, unavailable []string, err error) {
+	encoders = []Encoder{NewJPEGEncoder(quality)}
+	for _, format := range alternates {
+		encoder, err := NewEncoder(format, quality)
+		if errors.Is(err, ErrFormatUnavailable) {
+			unavailable = append(unavailable, format)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		encoders = append(encoders, encoder)
+	}
+	return encoders, unavailable, nil
+}
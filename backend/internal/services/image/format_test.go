@@ -0,0 +1,103 @@
+package image
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestParseFormats(t *testing.T) {
+	formats, err := ParseFormats(" AVIF,webp ")
+	if err != nil {
+		t.Fatalf("ParseFormats() error: %v", err)
+	}
+	if want := []string{FormatAVIF, FormatWebP}; !reflect.DeepEqual(formats, want) {
+		t.Errorf("ParseFormats() = %v, want %v", formats, want)
+	}
+
+	if formats, err := ParseFormats(""); err != nil || len(formats) != 0 {
+		t.Errorf("ParseFormats(\"\") = %v, %v; want no formats", formats, err)
+	}
+
+	for _, invalid := range []string{"gif", "jpeg", "webp,webp"} {
+		if _, err := ParseFormats(invalid); err == nil {
+			t.Errorf("ParseFormats(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestExtensionAndContentType(t *testing.T) {
+	tests := []struct {
+		format, ext, contentType string
+	}{
+		{FormatJPEG, ".jpg", "image/jpeg"},
+		{FormatWebP, ".webp", "image/webp"},
+		{FormatAVIF, ".avif", "image/avif"},
+	}
+	for _, tt := range tests {
+		if got := Extension(tt.format); got != tt.ext {
+			t.Errorf("Extension(%s) = %s, want %s", tt.format, got, tt.ext)
+		}
+		if got := ContentType(tt.format); got != tt.contentType {
+			t.Errorf("ContentType(%s) = %s, want %s", tt.format, got, tt.contentType)
+		}
+	}
+}
+
+func TestRenditionEncoders(t *testing.T) {
+	encoders, unavailable, err := RenditionEncoders([]string{FormatWebP, FormatAVIF}, 80)
+	if err != nil {
+		t.Fatalf("RenditionEncoders() error: %v", err)
+	}
+
+	var formats []string
+	for _, e := range encoders {
+		formats = append(formats, e.Format())
+	}
+	wantFormats, wantUnavailable := []string{FormatJPEG, FormatWebP}, []string{FormatAVIF}
+	if !webpAvailable {
+		wantFormats, wantUnavailable = []string{FormatJPEG}, []string{FormatWebP, FormatAVIF}
+	}
+	if !reflect.DeepEqual(formats, wantFormats) || !reflect.DeepEqual(unavailable, wantUnavailable) {
+		t.Errorf("RenditionEncoders() = %v, unavailable %v; want %v, unavailable %v", formats, unavailable, wantFormats, wantUnavailable)
+	}
+
+	if _, err := NewEncoder(FormatAVIF, 80); !errors.Is(err, ErrFormatUnavailable) {
+		t.Errorf("NewEncoder(avif) error = %v, want ErrFormatUnavailable", err)
+	}
+	if _, _, err := RenditionEncoders([]string{"gif"}, 80); err == nil {
+		t.Error("RenditionEncoders() should reject unknown formats")
+	}
+}
+
+func TestGenerateRenditionsAlternates(t *testing.T) {
+	encoders, _, err := RenditionEncoders([]string{FormatWebP}, 80)
+	if err != nil {
+		t.Fatalf("RenditionEncoders() error: %v", err)
+	}
+
+	img := createTestImage(900, 600)
+	renditions, err := NewProcessor().GenerateRenditions(img, DefaultRenditions, 0, encoders)
+	if err != nil {
+		t.Fatalf("GenerateRenditions() error: %v", err)
+	}
+
+	for _, r := range renditions {
+		if r.Format != FormatJPEG {
+			t.Errorf("rendition %s format = %s, want jpeg", r.Name, r.Format)
+		}
+		if len(r.Alternates) != len(encoders)-1 {
+			t.Fatalf("rendition %s has %d alternates, want %d", r.Name, len(r.Alternates), len(encoders)-1)
+		}
+		for _, alt := range r.Alternates {
+			if alt.Format != FormatWebP || len(alt.Data) == 0 {
+				t.Errorf("rendition %s alternate = %s (%d bytes)", r.Name, alt.Format, len(alt.Data))
+			}
+		}
+	}
+
+	if _, err := NewProcessor().GenerateRenditions(image.NewRGBA(image.Rect(0, 0, 10, 10)), DefaultRenditions, 0, nil); err == nil {
+		t.Error("GenerateRenditions() without encoders should fail")
+	}
+}
@@ -0,0 +1,67 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"io"
+
+	"github.com/disintegration/imaging"
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// Orientation reads the EXIF Orientation tag (1-8) from encoded image data.
+// Images without EXIF data or with an invalid tag are reported as 1, upright.
+func Orientation(data []byte) int {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 1
+	}
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 1
+	}
+	orientation, err := tag.Int(0)
+	if err != nil || orientation < 1 || orientation > 8 {
+		return 1
+	}
+	return orientation
+}
+
+// swapsDimensions reports whether displaying an image with the given orientation turns it on its side
+func swapsDimensions(orientation int) bool {
+	return orientation >= 5
+}
+
+// Orient transforms img, as stored by the camera, into how it should be displayed for an EXIF orientation
+func Orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
+
+// DecodeOriented decodes an image and applies its EXIF orientation, so the result is upright
+// the way the camera intended. Prefer it over image.Decode for anything shown to people.
+// Images over DefaultMaxPixels fail with ErrTooManyPixels.
+func DecodeOriented(r io.Reader) (image.Image, error) {
+	return NewProcessor().decode(r)
+}
+
+// DecodeOrientedConfig returns the displayed width and height of an image without decoding its pixels
+func DecodeOrientedConfig(data []byte) (int, int, error) {
+	return NewProcessor().GetImageDimensions(bytes.NewReader(data))
+}
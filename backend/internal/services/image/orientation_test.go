@@ -0,0 +1,144 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/disintegration/imaging"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+)
+
+// uprightTestImage is 60x40 and blue, with a red block marking its top-left corner
+func uprightTestImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if x < 20 && y < 20 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// storedAs returns how a camera would store the upright image under an EXIF orientation,
+// i.e. the inverse of Orient
+func storedAs(upright image.Image, orientation int) image.Image {
+	switch orientation {
+	case 6:
+		return imaging.Rotate90(upright)
+	case 8:
+		return imaging.Rotate270(upright)
+	default:
+		// The remaining transforms are their own inverse
+		return Orient(upright, orientation)
+	}
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && r < 0x4000 && g < 0x4000
+}
+
+func assertUpright(t *testing.T, img image.Image, width, height int) {
+	t.Helper()
+	b := img.Bounds()
+	if b.Dx() != width || b.Dy() != height {
+		t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), width, height)
+	}
+	// Sample well inside the marker and the opposite corner to stay clear of JPEG ringing
+	if !isRed(img.At(b.Min.X+width/12, b.Min.Y+height/8)) {
+		t.Errorf("top-left = %v, want red", img.At(b.Min.X+width/12, b.Min.Y+height/8))
+	}
+	if !isBlue(img.At(b.Max.X-1-width/12, b.Max.Y-1-height/8)) {
+		t.Errorf("bottom-right = %v, want blue", img.At(b.Max.X-1-width/12, b.Max.Y-1-height/8))
+	}
+}
+
+func TestOrientationAllEight(t *testing.T) {
+	p := NewProcessor()
+	upright := uprightTestImage()
+
+	for orientation := 1; orientation <= 8; orientation++ {
+		data := fixtures.JPEGWithOrientation(storedAs(upright, orientation), orientation)
+
+		t.Run(orientationName(orientation), func(t *testing.T) {
+			if got := Orientation(data); got != orientation {
+				t.Fatalf("Orientation() = %d, want %d", got, orientation)
+			}
+
+			img, err := DecodeOriented(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeOriented() error: %v", err)
+			}
+			assertUpright(t, img, 60, 40)
+
+			width, height, err := p.GetImageDimensions(bytes.NewReader(data))
+			if err != nil || width != 60 || height != 40 {
+				t.Errorf("GetImageDimensions() = %dx%d, %v; want 60x40", width, height, err)
+			}
+
+			optimized, err := p.GenerateOptimized(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("GenerateOptimized() error: %v", err)
+			}
+			decoded, err := jpeg.Decode(bytes.NewReader(optimized))
+			if err != nil {
+				t.Fatalf("decode optimized: %v", err)
+			}
+			assertUpright(t, decoded, 60, 40)
+
+			thumbnail, err := p.GenerateThumbnail(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("GenerateThumbnail() error: %v", err)
+			}
+			decoded, err = jpeg.Decode(bytes.NewReader(thumbnail))
+			if err != nil {
+				t.Fatalf("decode thumbnail: %v", err)
+			}
+			// The square crop keeps the middle of the image, so only the red corner is checked
+			if !isRed(decoded.At(10, 10)) {
+				t.Errorf("thumbnail top-left = %v, want red", decoded.At(10, 10))
+			}
+
+			processed, err := NewImageProcessor(NewPNGEncoder()).Process(bytes.NewReader(data), NewGrayscaleStrategy())
+			if err != nil {
+				t.Fatalf("ImageProcessor.Process() error: %v", err)
+			}
+			if cfg, _, err := image.DecodeConfig(bytes.NewReader(processed)); err != nil || cfg.Width != 60 || cfg.Height != 40 {
+				t.Errorf("ImageProcessor.Process() size = %dx%d, %v; want 60x40", cfg.Width, cfg.Height, err)
+			}
+		})
+	}
+}
+
+func TestOrientationWithoutEXIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, uprightTestImage(), nil); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if got := Orientation(buf.Bytes()); got != 1 {
+		t.Errorf("Orientation() = %d, want 1", got)
+	}
+	if got := Orientation([]byte("not an image")); got != 1 {
+		t.Errorf("Orientation(garbage) = %d, want 1", got)
+	}
+	if got := Orientation(fixtures.JPEGWithOrientation(uprightTestImage(), 9)); got != 1 {
+		t.Errorf("Orientation(invalid tag) = %d, want 1", got)
+	}
+}
+
+func orientationName(orientation int) string {
+	return [...]string{"", "normal", "mirrored", "rotated 180", "flipped", "transposed", "rotated 90 cw", "transversed", "rotated 90 ccw"}[orientation]
+}
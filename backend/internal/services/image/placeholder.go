@@ -0,0 +1,188 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// BlurHash components. 4x3 suits the landscape photos most galleries hold and gives a
+// 28 character hash.
+const (
+	blurHashComponentsX = 4
+	blurHashComponentsY = 3
+
+	// placeholderSize is the size photos are shrunk to before encoding; BlurHash only keeps
+	// the lowest frequencies, so more pixels add time without changing the result
+	placeholderSize = 32
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Placeholder is what clients show while a photo loads: a BlurHash of the photo and its
+// dominant color for clients that don't decode BlurHash.
+type Placeholder struct {
+	BlurHash      string
+	DominantColor string // #rrggbb
+}
+
+// GeneratePlaceholder decodes an image, upright, and computes its placeholder
+func (p *Processor) GeneratePlaceholder(imageData io.Reader) (*Placeholder, error) {
+	img, err := p.decode(imageData)
+	if err != nil {
+		return nil, err
+	}
+	placeholder := NewPlaceholder(img)
+	return &placeholder, nil
+}
+
+// NewPlaceholder computes the placeholder of an image
+func NewPlaceholder(img image.Image) Placeholder {
+	small := imaging.Fit(img, placeholderSize, placeholderSize, imaging.Box)
+	return Placeholder{
+		BlurHash:      BlurHash(small, blurHashComponentsX, blurHashComponentsY),
+		DominantColor: DominantColor(small),
+	}
+}
+
+// BlurHash encodes an image as a BlurHash (https://blurha.sh) with the given number of
+// horizontal and vertical components, each from 1 to 9. Encoding time grows with the pixel
+// count, so large images should be shrunk first.
+func BlurHash(img image.Image, componentsX, componentsY int) string {
+	componentsX = clamp(componentsX, 1, 9)
+	componentsY = clamp(componentsY, 1, 9)
+
+	nrgba := imaging.Clone(img)
+	width, height := nrgba.Bounds().Dx(), nrgba.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	// Convert to linear light once rather than per component
+	linear := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*nrgba.Stride + x*4
+			linear[y*width+x] = [3]float64{
+				sRGBToLinear(nrgba.Pix[i]), sRGBToLinear(nrgba.Pix[i+1]), sRGBToLinear(nrgba.Pix[i+2]),
+			}
+		}
+	}
+
+	factors := make([][3]float64, 0, componentsX*componentsY)
+	for j := 0; j < componentsY; j++ {
+		for i := 0; i < componentsX; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				basisY := math.Cos(math.Pi * float64(j) * float64(y) / float64(height))
+				for x := 0; x < width; x++ {
+					basis := basisY * math.Cos(math.Pi*float64(i)*float64(x)/float64(width))
+					px := linear[y*width+x]
+					factor[0] += basis * px[0]
+					factor[1] += basis * px[1]
+					factor[2] += basis * px[2]
+				}
+			}
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((componentsX-1)+(componentsY-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+	maxValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		hash.WriteString(encode83(quantisedMax, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	hash.WriteString(encode83(linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4))
+	for _, f := range ac {
+		hash.WriteString(encode83(quantiseAC(f[0], maxValue)*19*19+quantiseAC(f[1], maxValue)*19+quantiseAC(f[2], maxValue), 2))
+	}
+	return hash.String()
+}
+
+// DominantColor returns the most common color of an image as #rrggbb. Colors are grouped
+// into 4 bits per channel, and the pixels of the largest group averaged, so noise and
+// gradients don't split one color into many.
+func DominantColor(img image.Image) string {
+	nrgba := imaging.Clone(img)
+	width, height := nrgba.Bounds().Dx(), nrgba.Bounds().Dy()
+
+	var counts [4096]int
+	var sums [4096][3]int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*nrgba.Stride + x*4
+			r, g, b := int(nrgba.Pix[i]), int(nrgba.Pix[i+1]), int(nrgba.Pix[i+2])
+			bucket := (r>>4)<<8 | (g>>4)<<4 | b>>4
+			counts[bucket]++
+			sums[bucket][0] += r
+			sums[bucket][1] += g
+			sums[bucket][2] += b
+		}
+	}
+
+	best := 0
+	for bucket, n := range counts {
+		if n > counts[best] {
+			best = bucket
+		}
+	}
+	n := counts[best]
+	if n == 0 {
+		return "#000000"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", sums[best][0]/n, sums[best][1]/n, sums[best][2]/n)
+}
+
+func encode83(value, length int) string {
+	var b strings.Builder
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		b.WriteByte(base83Chars[digit])
+	}
+	return b.String()
+}
+
+func quantiseAC(value, maxValue float64) int {
+	return int(math.Max(0, math.Min(18, math.Floor(signPow(value/maxValue, 0.5)*9+9.5))))
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
+
+func sRGBToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
@@ -0,0 +1,102 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+func filledImage(width, height int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func decode83(s string) int {
+	value := 0
+	for _, c := range s {
+		value = value*83 + strings.IndexRune(base83Chars, c)
+	}
+	return value
+}
+
+func TestBlurHash(t *testing.T) {
+	t.Run("solid color", func(t *testing.T) {
+		hash := BlurHash(filledImage(32, 24, color.RGBA{R: 255, G: 128, B: 0, A: 255}), 4, 3)
+		if len(hash) != 28 {
+			t.Fatalf("len(%q) = %d, want 28", hash, len(hash))
+		}
+		if size := decode83(hash[:1]); size != 3+2*9 {
+			t.Errorf("size flag = %d, want 4x3 components", size)
+		}
+		if dc := decode83(hash[2:6]); dc != 0xff8000 {
+			t.Errorf("DC = %06x, want ff8000", dc)
+		}
+	})
+
+	t.Run("gradient has detail", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				img.Set(x, y, color.RGBA{R: uint8(x * 8), G: uint8(y * 8), B: 64, A: 255})
+			}
+		}
+		hash := BlurHash(img, 4, 3)
+		flat := BlurHash(filledImage(32, 32, color.RGBA{R: 128, G: 128, B: 64, A: 255}), 4, 3)
+		if decode83(hash[1:2]) <= decode83(flat[1:2]) {
+			t.Errorf("BlurHash(%q) has no more AC range than a flat image (%q)", hash, flat)
+		}
+	})
+
+	t.Run("single component", func(t *testing.T) {
+		if hash := BlurHash(filledImage(8, 8, color.White), 1, 1); hash != "00"+encode83(0xffffff, 4) {
+			t.Errorf("BlurHash() = %q", hash)
+		}
+	})
+}
+
+func TestDominantColor(t *testing.T) {
+	// Three quarters blue with a red corner
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			c := color.RGBA{R: 20, G: 40, B: 200, A: 255}
+			if x < 10 && y < 10 {
+				c = color.RGBA{R: 230, G: 10, B: 10, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	if got := DominantColor(img); got != "#1428c8" {
+		t.Errorf("DominantColor() = %s, want #1428c8", got)
+	}
+}
+
+func TestGeneratePlaceholder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, filledImage(640, 480, color.RGBA{R: 40, G: 160, B: 80, A: 255}), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	placeholder, err := NewProcessor().GeneratePlaceholder(&buf)
+	if err != nil {
+		t.Fatalf("GeneratePlaceholder() error: %v", err)
+	}
+	if len(placeholder.BlurHash) != 28 {
+		t.Errorf("BlurHash = %q, want 28 characters", placeholder.BlurHash)
+	}
+	if !strings.HasPrefix(placeholder.DominantColor, "#") || len(placeholder.DominantColor) != 7 {
+		t.Errorf("DominantColor = %q, want #rrggbb", placeholder.DominantColor)
+	}
+
+	if _, err := NewProcessor().GeneratePlaceholder(strings.NewReader("not an image")); err == nil {
+		t.Error("GeneratePlaceholder() should fail for data that isn't an image")
+	}
+}
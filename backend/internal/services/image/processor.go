@@ -4,14 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"image"
-	"image/color"
 	"io"
 
 	"github.com/disintegration/imaging"
 	"github.com/rwcarlsen/goexif/exif"
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
 )
 
 const (
@@ -24,10 +20,13 @@ const (
 	OptimizedMaxHeight = 1080
 )
 
-type Processor struct{}
+type Processor struct {
+	maxPixels int
+}
 
+// NewProcessor creates a processor that decodes images of up to DefaultMaxPixels
 func NewProcessor() *Processor {
-	return &Processor{}
+	return &Processor{maxPixels: DefaultMaxPixels}
 }
 
 // ImageMetadata holds extracted metadata from an image
@@ -50,14 +49,25 @@ type GPSData struct {
 
 // GenerateThumbnail creates a 200x200 thumbnail from the image
 func (p *Processor) GenerateThumbnail(imageData io.Reader) ([]byte, error) {
-	// Decode the image
-	img, _, err := image.Decode(imageData)
+	return p.GenerateThumbnailWith(imageData, NewThumbnailStrategy())
+}
+
+// GenerateThumbnailWith creates a thumbnail from the image using the given crop strategy
+func (p *Processor) GenerateThumbnailWith(imageData io.Reader, crop ProcessingStrategy) ([]byte, error) {
+	// Decode the image, upright
+	img, err := p.decode(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	return p.GenerateThumbnailFrom(img, crop)
+}
 
-	// Create thumbnail with smart cropping (center)
-	thumbnail := imaging.Fill(img, ThumbnailWidth, ThumbnailHeight, imaging.Center, imaging.Lanczos)
+// GenerateThumbnailFrom creates a thumbnail from a decoded image using the given crop strategy
+func (p *Processor) GenerateThumbnailFrom(img image.Image, crop ProcessingStrategy) ([]byte, error) {
+	thumbnail, err := crop.Process(img)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", crop.Name(), err)
+	}
 
 	// Encode to JPEG
 	var buf bytes.Buffer
@@ -70,12 +80,16 @@ func (p *Processor) GenerateThumbnail(imageData io.Reader) ([]byte, error) {
 
 // GenerateOptimized creates an optimized version of the image (max 1920x1080)
 func (p *Processor) GenerateOptimized(imageData io.Reader) ([]byte, error) {
-	// Decode the image
-	img, _, err := image.Decode(imageData)
+	// Decode the image, upright
+	img, err := p.decode(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	return p.GenerateOptimizedFrom(img)
+}
 
+// GenerateOptimizedFrom creates an optimized version of a decoded image (max 1920x1080)
+func (p *Processor) GenerateOptimizedFrom(img image.Image) ([]byte, error) {
 	// Get original dimensions
 	bounds := img.Bounds()
 	width := bounds.Dx()
@@ -99,10 +113,14 @@ func (p *Processor) GenerateOptimized(imageData io.Reader) ([]byte, error) {
 }
 
 // ConvertToWebP converts an image to WebP format
-// NOTE: WebP conversion disabled - requires CGO which complicates cross-compilation
-// Can be re-enabled later with a pure Go WebP library or by using CGO in Docker build
+// NOTE: WebP encoding uses libwebp through CGO; builds without it return ErrFormatUnavailable
 func (p *Processor) ConvertToWebP(imageData io.Reader) ([]byte, error) {
-	return nil, fmt.Errorf("WebP conversion not currently supported")
+	img, err := p.decode(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWebPEncoder(85).Encode(img)
 }
 
 // ExtractEXIF extracts EXIF metadata from an image
@@ -174,100 +192,15 @@ func (p *Processor) ExtractEXIF(imageData io.Reader) (*ImageMetadata, error) {
 	return metadata, nil
 }
 
-// GetImageDimensions returns the width and height of an image
+// GetImageDimensions returns the width and height of an image as displayed,
+// i.e. swapped for photos whose EXIF orientation turns them on their side.
+// Only the header is read; the pixels aren't decoded.
 func (p *Processor) GetImageDimensions(imageData io.Reader) (int, int, error) {
-	img, _, err := image.Decode(imageData)
+	h, err := readHeader(imageData)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to decode image: %w", err)
 	}
 
-	bounds := img.Bounds()
-	return bounds.Dx(), bounds.Dy(), nil
-}
-
-// WatermarkOptions configures watermark application
-type WatermarkOptions struct {
-	Text     string
-	Position string // "bottom-right", "bottom-left", "center"
-}
-
-// ApplyWatermark adds a text watermark to an image
-func (p *Processor) ApplyWatermark(img image.Image, opts WatermarkOptions) image.Image {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Calculate text position based on watermark position
-	// Note: x,y represent the TOP-LEFT corner of the background box
-	var x, y int
-	textWidth := len(opts.Text) * 7 // Approximate width (basic font is ~7px per char)
-	textHeight := 13                // Basic font height (including ascent and descent)
-	padding := 5                    // Padding around text in background box
-
-	// Total height of background box (text + padding on both sides)
-	boxHeight := textHeight + padding*2
-
-	switch opts.Position {
-	case "bottom-left":
-		x = 0
-		y = height - boxHeight
-	case "center":
-		x = (width - textWidth) / 2
-		y = (height - textHeight) / 2
-	case "bottom-right":
-		fallthrough
-	default:
-		x = width - textWidth - padding*2
-		y = height - boxHeight
-	}
-
-	// Draw the watermark text with a semi-transparent background for readability
-	return p.drawText(img, opts.Text, x, y)
-}
-
-// drawText draws text on an image with a semi-transparent background
-// x, y represent the TOP-LEFT corner of the background box
-func (p *Processor) drawText(img image.Image, text string, x, y int) image.Image {
-	// Convert image to RGBA for drawing
-	rgba := image.NewRGBA(img.Bounds())
-	for py := 0; py < img.Bounds().Dy(); py++ {
-		for px := 0; px < img.Bounds().Dx(); px++ {
-			rgba.Set(px, py, img.At(px, py))
-		}
-	}
-
-	// Font metrics for basicfont.Face7x13
-	textWidth := len(text) * 7
-	textHeight := 13
-	padding := 5
-
-	// Draw semi-transparent background rectangle
-	// Background starts at (x-padding, y-padding) and extends to text dimensions
-	for py := y - padding; py < y+textHeight+padding; py++ {
-		for px := x - padding; px < x+textWidth+padding; px++ {
-			if px >= 0 && px < rgba.Bounds().Dx() && py >= 0 && py < rgba.Bounds().Dy() {
-				// Semi-transparent black background
-				rgba.Set(px, py, color.RGBA{0, 0, 0, 128})
-			}
-		}
-	}
-
-	// Draw white text
-	// The font drawer's Y coordinate is the baseline, which is about 11 pixels down from the top
-	// of a 13-pixel tall character for basicfont.Face7x13
-	baseline := y + 11 // 11 is the ascent for Face7x13
-	point := fixed.Point26_6{
-		X: fixed.Int26_6(x * 64),
-		Y: fixed.Int26_6(baseline * 64),
-	}
-
-	d := &font.Drawer{
-		Dst:  rgba,
-		Src:  image.NewUniform(color.White),
-		Face: basicfont.Face7x13,
-		Dot:  point,
-	}
-	d.DrawString(text)
-
-	return rgba
+	width, height := h.displayed()
+	return width, height, nil
 }
@@ -2,6 +2,7 @@ package image
 
 import (
 	"bytes"
+	"errors"
 	"image"
 	"image/color"
 	"image/jpeg"
@@ -212,15 +213,21 @@ func TestConvertToWebP(t *testing.T) {
 		t.Fatalf("Failed to encode test image: %v", err)
 	}
 
-	// Attempt WebP conversion (should fail as it's not supported)
-	_, err := p.ConvertToWebP(&buf)
-	if err == nil {
-		t.Error("ConvertToWebP() should return error (not supported)")
+	data, err := p.ConvertToWebP(&buf)
+	if !webpAvailable {
+		// Pure Go builds have no libwebp
+		if !errors.Is(err, ErrFormatUnavailable) {
+			t.Errorf("ConvertToWebP() error = %v, want ErrFormatUnavailable", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("ConvertToWebP() error = %v", err)
 	}
 
-	// Verify error message mentions not supported
-	if err.Error() != "WebP conversion not currently supported" {
-		t.Errorf("ConvertToWebP() error = %v, want 'WebP conversion not currently supported'", err)
+	// WebP files are RIFF containers with a WEBP form type
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		t.Errorf("ConvertToWebP() output is not a WebP file")
 	}
 }
 
@@ -0,0 +1,138 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// RenditionSpec names a width to generate for responsive images
+type RenditionSpec struct {
+	Name  string
+	Width int
+}
+
+// DefaultRenditions cover phones through 4K displays
+var DefaultRenditions = []RenditionSpec{
+	{Name: "xs", Width: 400},
+	{Name: "sm", Width: 800},
+	{Name: "md", Width: 1600},
+	{Name: "lg", Width: 2560},
+	{Name: "xl", Width: 3840},
+}
+
+// ParseRenditions parses a comma-separated list of name:width pairs, e.g. "sm:800,lg:2560".
+// The result is sorted by width.
+func ParseRenditions(s string) ([]RenditionSpec, error) {
+	var specs []RenditionSpec
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, width, ok := strings.Cut(part, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rendition %q: expected name:width", part)
+		}
+		w, err := strconv.Atoi(width)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid rendition %q: width must be a positive integer", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate rendition %q", name)
+		}
+		seen[name] = true
+		specs = append(specs, RenditionSpec{Name: name, Width: w})
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no renditions configured")
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Width < specs[j].Width })
+	return specs, nil
+}
+
+// Rendition is an encoded image generated for a RenditionSpec. Data holds the first
+// encoder's output; the same pixels in other formats are in Alternates.
+type Rendition struct {
+	Name       string
+	Width      int
+	Height     int
+	Format     string
+	Data       []byte
+	Alternates []EncodedImage
+}
+
+// EncodedImage is a rendition encoded in an alternate format such as WebP
+type EncodedImage struct {
+	Format string
+	Data   []byte
+}
+
+// SelectRenditions returns the specs worth generating for an image srcWidth pixels wide. Images
+// are never upscaled: the first spec at least as wide as the source is generated at the source
+// width and larger ones are dropped. Specs wider than maxWidth are dropped too (0 = no limit).
+func SelectRenditions(specs []RenditionSpec, srcWidth, maxWidth int) []RenditionSpec {
+	sorted := append([]RenditionSpec(nil), specs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Width < sorted[j].Width })
+
+	var selected []RenditionSpec
+	for _, spec := range sorted {
+		if maxWidth > 0 && spec.Width > maxWidth {
+			break
+		}
+		if spec.Width >= srcWidth {
+			selected = append(selected, RenditionSpec{Name: spec.Name, Width: srcWidth})
+			break
+		}
+		selected = append(selected, spec)
+	}
+	return selected
+}
+
+// GenerateRenditions resizes img to each selected spec, applies strategies such as watermarking
+// to each size, and encodes the results in order of width with every encoder. The first encoder
+// produces the rendition's Data and should be a format all browsers support.
+func (p *Processor) GenerateRenditions(img image.Image, specs []RenditionSpec, maxWidth int, encoders []Encoder, strategies ...ProcessingStrategy) ([]Rendition, error) {
+	if len(encoders) == 0 {
+		return nil, fmt.Errorf("no rendition encoders")
+	}
+	chain := NewStrategyChain(strategies...)
+
+	var renditions []Rendition
+	for _, spec := range SelectRenditions(specs, img.Bounds().Dx(), maxWidth) {
+		resized := img
+		if spec.Width < img.Bounds().Dx() {
+			resized = imaging.Resize(img, spec.Width, 0, imaging.Lanczos)
+		}
+		processed, err := chain.Process(resized)
+		if err != nil {
+			return nil, fmt.Errorf("rendition %s: %w", spec.Name, err)
+		}
+		data, err := encoders[0].Encode(processed)
+		if err != nil {
+			return nil, fmt.Errorf("encode rendition %s: %w", spec.Name, err)
+		}
+		bounds := processed.Bounds()
+		rendition := Rendition{
+			Name:   spec.Name,
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+			Format: encoders[0].Format(),
+			Data:   data,
+		}
+		for _, encoder := range encoders[1:] {
+			alt, err := encoder.Encode(processed)
+			if err != nil {
+				return nil, fmt.Errorf("encode rendition %s as %s: %w", spec.Name, encoder.Format(), err)
+			}
+			rendition.Alternates = append(rendition.Alternates, EncodedImage{Format: encoder.Format(), Data: alt})
+		}
+		renditions = append(renditions, rendition)
+	}
+	return renditions, nil
+}
@@ -0,0 +1,82 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"reflect"
+	"testing"
+)
+
+func TestParseRenditions(t *testing.T) {
+	specs, err := ParseRenditions(" lg:2560, xs:400 ,md:1600")
+	if err != nil {
+		t.Fatalf("ParseRenditions() error: %v", err)
+	}
+	want := []RenditionSpec{{"xs", 400}, {"md", 1600}, {"lg", 2560}}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("ParseRenditions() = %v, want %v", specs, want)
+	}
+
+	for _, invalid := range []string{"", "xs", "xs:0", "xs:-5", "xs:wide", ":400", "xs:400,xs:800"} {
+		if _, err := ParseRenditions(invalid); err == nil {
+			t.Errorf("ParseRenditions(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestSelectRenditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		srcWidth int
+		maxWidth int
+		want     []RenditionSpec
+	}{
+		{"large source gets every size", 6000, 0, DefaultRenditions},
+		{"never upscales", 1000, 0, []RenditionSpec{{"xs", 400}, {"sm", 800}, {"md", 1000}}},
+		{"exact width is not duplicated", 800, 0, []RenditionSpec{{"xs", 400}, {"sm", 800}}},
+		{"tiny source", 300, 0, []RenditionSpec{{"xs", 300}}},
+		{"capped by max width", 6000, 1920, []RenditionSpec{{"xs", 400}, {"sm", 800}, {"md", 1600}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectRenditions(DefaultRenditions, tt.srcWidth, tt.maxWidth)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectRenditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRenditions(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1200, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 1200; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+
+	renditions, err := NewProcessor().GenerateRenditions(img, DefaultRenditions, 0, []Encoder{NewJPEGEncoder(85)})
+	if err != nil {
+		t.Fatalf("GenerateRenditions() error: %v", err)
+	}
+
+	wantWidths := []int{400, 800, 1200}
+	if len(renditions) != len(wantWidths) {
+		t.Fatalf("got %d renditions, want %d", len(renditions), len(wantWidths))
+	}
+	for i, r := range renditions {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(r.Data))
+		if err != nil {
+			t.Fatalf("rendition %s is not a JPEG: %v", r.Name, err)
+		}
+		if r.Width != wantWidths[i] || cfg.Width != r.Width || cfg.Height != r.Height {
+			t.Errorf("rendition %s = %dx%d (encoded %dx%d), want width %d", r.Name, r.Width, r.Height, cfg.Width, cfg.Height, wantWidths[i])
+		}
+		if diff := r.Height*3 - r.Width*2; diff < -3 || diff > 3 {
+			t.Errorf("rendition %s height = %d, want aspect ratio kept", r.Name, r.Height)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package image
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// Thumbnail crop modes a gallery can choose
+const (
+	CropCenter = "center" // crop the middle of the photo (default)
+	CropSmart  = "smart"  // crop the most interesting part of the photo
+)
+
+// Smart crop scoring. Photos are shrunk before scoring, which is plenty to find the subject
+// and keeps scoring a 24 MP photo in the low milliseconds.
+const (
+	analysisSize = 256
+
+	edgeWeight       = 1.0 // detail: subjects are usually sharper and busier than backgrounds
+	skinWeight       = 1.5 // skin tones, so portraits keep their faces
+	saturationWeight = 0.3 // colorful areas over washed-out sky and walls
+)
+
+// FocalPoint is the point of a photo a crop should keep, relative to its width and height:
+// (0, 0) is the top-left corner and (1, 1) the bottom-right.
+type FocalPoint struct {
+	X, Y float64
+}
+
+// Valid reports whether the focal point lies within the photo
+func (f FocalPoint) Valid() bool {
+	return f.X >= 0 && f.X <= 1 && f.Y >= 0 && f.Y <= 1
+}
+
+// NewThumbnailCrop returns the strategy cropping thumbnails for a gallery crop mode. A focal
+// point, when set, overrides the mode.
+func NewThumbnailCrop(mode string, focus *FocalPoint) ProcessingStrategy {
+	if focus != nil && focus.Valid() {
+		return NewFocalCropStrategy(ThumbnailWidth, ThumbnailHeight, *focus)
+	}
+	if mode == CropSmart {
+		return NewSmartCropStrategy(ThumbnailWidth, ThumbnailHeight)
+	}
+	return NewThumbnailStrategy()
+}
+
+// SmartCropStrategy crops to a fixed size around the part of the image with the most detail,
+// skin tones and color, instead of its center.
+type SmartCropStrategy struct {
+	Width  int
+	Height int
+}
+
+// NewSmartCropStrategy creates a new smart crop strategy.
+func NewSmartCropStrategy(width, height int) *SmartCropStrategy {
+	return &SmartCropStrategy{Width: width, Height: height}
+}
+
+// Process crops and resizes the image around its most interesting area.
+func (s *SmartCropStrategy) Process(img image.Image) (image.Image, error) {
+	crop := SmartCrop(img, s.Width, s.Height)
+	return imaging.Resize(imaging.Crop(img, crop), s.Width, s.Height, imaging.Lanczos), nil
+}
+
+// Name returns the strategy name.
+func (s *SmartCropStrategy) Name() string {
+	return "smart-crop"
+}
+
+// FocalCropStrategy crops to a fixed size keeping a chosen point as close to the center as the
+// image allows.
+type FocalCropStrategy struct {
+	Width  int
+	Height int
+	Focus  FocalPoint
+}
+
+// NewFocalCropStrategy creates a new focal point crop strategy.
+func NewFocalCropStrategy(width, height int, focus FocalPoint) *FocalCropStrategy {
+	return &FocalCropStrategy{Width: width, Height: height, Focus: focus}
+}
+
+// Process crops and resizes the image around the focal point.
+func (s *FocalCropStrategy) Process(img image.Image) (image.Image, error) {
+	b := img.Bounds()
+	cw, ch := cropSize(b.Dx(), b.Dy(), s.Width, s.Height)
+	x := clamp(int(math.Round(s.Focus.X*float64(b.Dx())))-cw/2, 0, b.Dx()-cw)
+	y := clamp(int(math.Round(s.Focus.Y*float64(b.Dy())))-ch/2, 0, b.Dy()-ch)
+	crop := image.Rect(x, y, x+cw, y+ch).Add(b.Min)
+	return imaging.Resize(imaging.Crop(img, crop), s.Width, s.Height, imaging.Lanczos), nil
+}
+
+// Name returns the strategy name.
+func (s *FocalCropStrategy) Name() string {
+	return "focal-crop"
+}
+
+// SmartCrop returns the largest rectangle with the aspect ratio of width x height that covers
+// the most interesting part of the image. Like a center crop it only trims one axis, sliding
+// the window along it to where detail, skin tones and saturation score highest.
+func SmartCrop(img image.Image, width, height int) image.Rectangle {
+	b := img.Bounds()
+	cw, ch := cropSize(b.Dx(), b.Dy(), width, height)
+	if cw == b.Dx() && ch == b.Dy() {
+		return b
+	}
+
+	small := imaging.Fit(img, analysisSize, analysisSize, imaging.Box)
+	sw, sh := small.Bounds().Dx(), small.Bounds().Dy()
+	scores := scoreImage(small)
+
+	// Sum the scores along the axis the crop keeps whole, then slide the window along the other
+	horizontal := cw < b.Dx()
+	var sums []float64
+	var window, limit int
+	if horizontal {
+		sums = make([]float64, sw)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				sums[x] += scores[y*sw+x]
+			}
+		}
+		window = clamp(int(math.Round(float64(cw)*float64(sw)/float64(b.Dx()))), 1, sw)
+		limit = b.Dx() - cw
+	} else {
+		sums = make([]float64, sh)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				sums[y] += scores[y*sw+x]
+			}
+		}
+		window = clamp(int(math.Round(float64(ch)*float64(sh)/float64(b.Dy()))), 1, sh)
+		limit = b.Dy() - ch
+	}
+
+	best := bestWindow(sums, window)
+	offset := clamp(int(math.Round(float64(best)*float64(limit)/math.Max(1, float64(len(sums)-window)))), 0, limit)
+	if horizontal {
+		return image.Rect(offset, 0, offset+cw, ch).Add(b.Min)
+	}
+	return image.Rect(0, offset, cw, offset+ch).Add(b.Min)
+}
+
+// bestWindow returns where a window of n consecutive sums adds up highest. Ties go to the
+// window nearest the middle, so featureless images are center-cropped.
+func bestWindow(sums []float64, n int) int {
+	var total float64
+	for _, s := range sums[:n] {
+		total += s
+	}
+	middle := float64(len(sums)-n) / 2
+	best, bestTotal := 0, total
+	for start := 1; start+n <= len(sums); start++ {
+		total += sums[start+n-1] - sums[start-1]
+		better := total > bestTotal+1e-9
+		tied := math.Abs(total-bestTotal) <= 1e-9 && math.Abs(float64(start)-middle) < math.Abs(float64(best)-middle)
+		if better || tied {
+			best, bestTotal = start, total
+		}
+	}
+	return best
+}
+
+// scoreImage rates how interesting each pixel is
+func scoreImage(img *image.NRGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	luma := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*img.Stride + x*4
+			r, g, b := float64(img.Pix[i]), float64(img.Pix[i+1]), float64(img.Pix[i+2])
+			luma[y*w+x] = (0.299*r + 0.587*g + 0.114*b) / 255
+		}
+	}
+
+	scores := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*img.Stride + x*4
+			r, g, b := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+
+			// Laplacian edge strength, using the pixel itself past the borders
+			l := luma[y*w+x]
+			edge := math.Abs(4*l - luma[y*w+clamp(x-1, 0, w-1)] - luma[y*w+clamp(x+1, 0, w-1)] -
+				luma[clamp(y-1, 0, h-1)*w+x] - luma[clamp(y+1, 0, h-1)*w+x])
+
+			score := edgeWeight*math.Min(edge, 1) + saturationWeight*saturation(r, g, b)
+			if isSkin(r, g, b) {
+				score += skinWeight
+			}
+			scores[y*w+x] = score
+		}
+	}
+	return scores
+}
+
+// isSkin is the classic RGB skin tone rule for daylight photos
+func isSkin(r, g, b uint8) bool {
+	hi, lo := maxOf(r, g, b), minOf(r, g, b)
+	return r > 95 && g > 40 && b > 20 && hi-lo > 15 && r > g && r > b && int(r)-int(g) > 15
+}
+
+// saturation returns the HSV saturation of a color, 0 to 1
+func saturation(r, g, b uint8) float64 {
+	hi, lo := maxOf(r, g, b), minOf(r, g, b)
+	if hi == 0 {
+		return 0
+	}
+	return float64(hi-lo) / float64(hi)
+}
+
+// cropSize returns the largest size with the aspect ratio of width x height that fits the image
+func cropSize(imageWidth, imageHeight, width, height int) (int, int) {
+	cw, ch := imageWidth, int(math.Round(float64(imageWidth)*float64(height)/float64(width)))
+	if ch > imageHeight {
+		cw, ch = int(math.Round(float64(imageHeight)*float64(width)/float64(height))), imageHeight
+	}
+	return clamp(cw, 1, imageWidth), clamp(ch, 1, imageHeight)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func maxOf(r, g, b uint8) uint8 {
+	m := r
+	if g > m {
+		m = g
+	}
+	if b > m {
+		m = b
+	}
+	return m
+}
+
+func minOf(r, g, b uint8) uint8 {
+	m := r
+	if g < m {
+		m = g
+	}
+	if b < m {
+		m = b
+	}
+	return m
+}
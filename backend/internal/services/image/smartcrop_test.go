@@ -0,0 +1,150 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// subjectImage is a flat gray backdrop with a busy, skin-toned checkerboard patch in it
+func subjectImage(width, height int, patch image.Rectangle) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+			if (image.Point{X: x, Y: y}).In(patch) {
+				c = color.RGBA{R: 224, G: 172, B: 140, A: 255}
+				if (x/4+y/4)%2 == 0 {
+					c = color.RGBA{R: 120, G: 70, B: 50, A: 255}
+				}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSmartCrop(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int
+		height  int
+		subject image.Rectangle
+	}{
+		{"subject on the left of a landscape", 900, 300, image.Rect(20, 80, 180, 220)},
+		{"subject on the right of a landscape", 900, 300, image.Rect(720, 80, 880, 220)},
+		{"subject at the top of a portrait", 300, 900, image.Rect(80, 40, 220, 200)},
+		{"subject at the bottom of a portrait", 300, 900, image.Rect(60, 700, 240, 860)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SmartCrop(subjectImage(tt.width, tt.height, tt.subject), 200, 200)
+			if got.Dx() != 300 || got.Dy() != 300 {
+				t.Fatalf("SmartCrop() = %v, want a 300x300 crop", got)
+			}
+			if !tt.subject.In(got) {
+				t.Errorf("SmartCrop() = %v, want it to contain the subject at %v", got, tt.subject)
+			}
+		})
+	}
+}
+
+func TestSmartCropWithoutSubject(t *testing.T) {
+	// A featureless image falls back to a center crop
+	got := SmartCrop(subjectImage(900, 300, image.Rectangle{}), 200, 200)
+	if got.Min.X < 297 || got.Min.X > 303 || got.Dx() != 300 {
+		t.Errorf("SmartCrop() = %v, want about (300,0)-(600,300)", got)
+	}
+
+	// An image with the target aspect ratio isn't cropped at all
+	square := subjectImage(400, 400, image.Rect(0, 0, 50, 50))
+	if got := SmartCrop(square, 200, 200); got != square.Bounds() {
+		t.Errorf("SmartCrop() = %v, want the whole image", got)
+	}
+}
+
+func TestSmartCropStrategy(t *testing.T) {
+	img := subjectImage(900, 300, image.Rect(20, 80, 180, 220))
+	out, err := NewSmartCropStrategy(ThumbnailWidth, ThumbnailHeight).Process(img)
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+	if b := out.Bounds(); b.Dx() != ThumbnailWidth || b.Dy() != ThumbnailHeight {
+		t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), ThumbnailWidth, ThumbnailHeight)
+	}
+	// The subject is in the left third of the crop rather than cut off
+	if r, _, _, _ := out.At(ThumbnailWidth/6, ThumbnailHeight/2).RGBA(); r>>8 < 100 {
+		t.Errorf("pixel at the subject = %v, want the subject", out.At(ThumbnailWidth/6, ThumbnailHeight/2))
+	}
+}
+
+func TestFocalCropStrategy(t *testing.T) {
+	// A red column at x = 100..109 of a 900x300 gray image
+	img := image.NewRGBA(image.Rect(0, 0, 900, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 900; x++ {
+			c := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+			if x >= 100 && x < 110 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		focus FocalPoint
+		redX  int // where the red column lands in the 200x200 thumbnail
+	}{
+		{"centered on the point", FocalPoint{X: 500.0 / 900, Y: 0.5}, -1},
+		{"clamped at the left edge", FocalPoint{X: 105.0 / 900, Y: 0.5}, 67},
+		{"clamped at the right edge", FocalPoint{X: 1, Y: 0}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewFocalCropStrategy(ThumbnailWidth, ThumbnailHeight, tt.focus).Process(img)
+			if err != nil {
+				t.Fatalf("Process() error: %v", err)
+			}
+			if b := out.Bounds(); b.Dx() != ThumbnailWidth || b.Dy() != ThumbnailHeight {
+				t.Fatalf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), ThumbnailWidth, ThumbnailHeight)
+			}
+			found := -1
+			for x := 0; x < ThumbnailWidth; x++ {
+				if isRed(out.At(x, ThumbnailHeight/2)) {
+					found = x
+					break
+				}
+			}
+			if tt.redX < 0 && found >= 0 {
+				t.Errorf("red column at x = %d, want it cropped out", found)
+			}
+			if tt.redX >= 0 && (found < tt.redX-2 || found > tt.redX+2) {
+				t.Errorf("red column at x = %d, want about %d", found, tt.redX)
+			}
+		})
+	}
+}
+
+func TestNewThumbnailCrop(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		focus *FocalPoint
+		want  string
+	}{
+		{"default", "", nil, "thumbnail"},
+		{"center", CropCenter, nil, "thumbnail"},
+		{"smart", CropSmart, nil, "smart-crop"},
+		{"focal point overrides mode", CropSmart, &FocalPoint{X: 0.2, Y: 0.3}, "focal-crop"},
+		{"invalid focal point is ignored", CropCenter, &FocalPoint{X: 2, Y: 0.3}, "thumbnail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewThumbnailCrop(tt.mode, tt.focus).Name(); got != tt.want {
+				t.Errorf("NewThumbnailCrop() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
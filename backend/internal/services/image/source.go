@@ -0,0 +1,131 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+)
+
+// DefaultMaxPixels is the largest image decoded unless a processor is configured otherwise.
+// 100 MP covers medium format cameras while keeping a decoded photo to a few hundred MB.
+const DefaultMaxPixels = 100_000_000
+
+// ErrTooManyPixels is returned for images with more pixels than the processor's limit, which
+// includes decompression bombs: small files declaring dimensions that would exhaust memory.
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// ErrCorrupt wraps the error of an image that couldn't be decoded. Images in a format no
+// decoder recognises also wrap image.ErrFormat.
+var ErrCorrupt = errors.New("failed to decode image")
+
+// Source is a photo decoded once so every derivative can be made from the same pixels.
+// Strategies return new images, so Image is never modified.
+type Source struct {
+	Image    image.Image    // upright, per the EXIF orientation
+	Format   string         // the decoder that read it, e.g. "jpeg"
+	Metadata *ImageMetadata // EXIF metadata, with Width and Height as displayed
+	Size     int64          // bytes read
+}
+
+// header is the start of an encoded image: everything an image decoder read to learn the
+// dimensions, which for JPEG includes the EXIF segment
+type header struct {
+	config      image.Config
+	format      string
+	data        []byte
+	orientation int
+}
+
+// readHeader reads an image's header from r, keeping the bytes so the full decode can start
+// from them and continue with the rest of r
+func readHeader(r io.Reader) (*header, error) {
+	var buf bytes.Buffer
+	cfg, format, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, err
+	}
+	return &header{config: cfg, format: format, data: buf.Bytes(), orientation: Orientation(buf.Bytes())}, nil
+}
+
+// displayed returns the width and height of the image as shown, turned on its side if the
+// orientation says so
+func (h *header) displayed() (int, int) {
+	if swapsDimensions(h.orientation) {
+		return h.config.Height, h.config.Width
+	}
+	return h.config.Width, h.config.Height
+}
+
+// WithMaxPixels sets the largest image, in pixels, the processor decodes (0 = no limit).
+// Larger images fail with ErrTooManyPixels before their pixels are read.
+func (p *Processor) WithMaxPixels(maxPixels int) *Processor {
+	p.maxPixels = maxPixels
+	return p
+}
+
+// Open reads an image from r in one pass, decoding its pixels once and reading its dimensions
+// and EXIF metadata from the header. Only the header is buffered, so memory is bounded by the
+// decoded image rather than the file plus every decode of it.
+func (p *Processor) Open(r io.Reader) (*Source, error) {
+	counter := &countingReader{r: r}
+	h, err := readHeader(counter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	img, err := p.decodeRest(h, counter)
+	if err != nil {
+		return nil, err
+	}
+	// Decoders may stop before trailing data; read it so Size is the whole file
+	if _, err := io.Copy(io.Discard, counter); err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	metadata, _ := p.ExtractEXIF(bytes.NewReader(h.data))
+	metadata.Width, metadata.Height = h.displayed()
+	return &Source{Image: img, Format: h.format, Metadata: metadata, Size: counter.n}, nil
+}
+
+// decode decodes an image, upright, within the processor's pixel limit
+func (p *Processor) decode(r io.Reader) (image.Image, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.decodeRest(h, r)
+}
+
+// decodeRest decodes the image whose header has been read from r
+func (p *Processor) decodeRest(h *header, r io.Reader) (image.Image, error) {
+	if err := p.checkPixels(h.config); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(io.MultiReader(bytes.NewReader(h.data), r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	return Orient(img, h.orientation), nil
+}
+
+func (p *Processor) checkPixels(cfg image.Config) error {
+	pixels := int64(cfg.Width) * int64(cfg.Height)
+	if p.maxPixels > 0 && pixels > int64(p.maxPixels) {
+		return fmt.Errorf("%w: %dx%d is %.1f MP, the limit is %.1f MP",
+			ErrTooManyPixels, cfg.Width, cfg.Height, float64(pixels)/1e6, float64(p.maxPixels)/1e6)
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,189 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"photographer-gallery/backend/internal/testing/fixtures"
+)
+
+// pngBomb returns a PNG that declares the given dimensions but holds no pixel data, as a
+// decompression bomb would before inflating
+func pngBomb(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	chunk := func(kind string, data []byte) {
+		binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+		buf.WriteString(kind)
+		buf.Write(data)
+		binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(kind), data...)))
+	}
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 2 // 8-bit RGB
+	chunk("IHDR", ihdr)
+	chunk("IEND", nil)
+	return buf.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	// Stored sideways, displayed 60x40
+	data := fixtures.JPEGWithOrientation(storedAs(uprightTestImage(), 6), 6)
+
+	src, err := NewProcessor().Open(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	assertUpright(t, src.Image, 60, 40)
+	if src.Metadata.Width != 60 || src.Metadata.Height != 40 {
+		t.Errorf("Metadata dimensions = %dx%d, want 60x40", src.Metadata.Width, src.Metadata.Height)
+	}
+	if src.Format != "jpeg" {
+		t.Errorf("Format = %q, want jpeg", src.Format)
+	}
+	if src.Size != int64(len(data)) {
+		t.Errorf("Size = %d, want %d", src.Size, len(data))
+	}
+
+	if _, err := NewProcessor().Open(bytes.NewReader([]byte("not an image"))); err == nil {
+		t.Error("Open() should fail for data that isn't an image")
+	}
+}
+
+func TestPixelLimit(t *testing.T) {
+	t.Run("decompression bomb", func(t *testing.T) {
+		p := NewProcessor()
+		if _, err := p.Open(bytes.NewReader(pngBomb(50000, 50000))); !errors.Is(err, ErrTooManyPixels) {
+			t.Errorf("Open() error = %v, want ErrTooManyPixels", err)
+		}
+		if _, err := p.GenerateThumbnail(bytes.NewReader(pngBomb(50000, 50000))); !errors.Is(err, ErrTooManyPixels) {
+			t.Errorf("GenerateThumbnail() error = %v, want ErrTooManyPixels", err)
+		}
+		if _, err := DecodeOriented(bytes.NewReader(pngBomb(50000, 50000))); !errors.Is(err, ErrTooManyPixels) {
+			t.Errorf("DecodeOriented() error = %v, want ErrTooManyPixels", err)
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, createTestImage(40, 30), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	t.Run("configured limit", func(t *testing.T) {
+		if _, err := NewProcessor().WithMaxPixels(1000).Open(bytes.NewReader(data)); !errors.Is(err, ErrTooManyPixels) {
+			t.Errorf("Open() error = %v, want ErrTooManyPixels for 1200 pixels", err)
+		}
+		if _, err := NewProcessor().WithMaxPixels(1200).Open(bytes.NewReader(data)); err != nil {
+			t.Errorf("Open() error = %v, want an image at the limit decoded", err)
+		}
+		if _, err := NewProcessor().WithMaxPixels(0).Open(bytes.NewReader(data)); err != nil {
+			t.Errorf("Open() error = %v, want no limit", err)
+		}
+	})
+
+	t.Run("dimensions of oversized images", func(t *testing.T) {
+		width, height, err := NewProcessor().WithMaxPixels(1000).GetImageDimensions(bytes.NewReader(data))
+		if err != nil || width != 40 || height != 30 {
+			t.Errorf("GetImageDimensions() = %dx%d, %v; want 40x30", width, height, err)
+		}
+	})
+}
+
+func TestGetImageDimensionsReadsHeaderOnly(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, createTestImage(1600, 1200), nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() <= 16<<10 {
+		t.Fatalf("test image is only %d bytes", buf.Len())
+	}
+
+	width, height, err := NewProcessor().GetImageDimensions(io.LimitReader(&buf, 16<<10))
+	if err != nil || width != 1600 || height != 1200 {
+		t.Errorf("GetImageDimensions() = %dx%d, %v; want 1600x1200 from the first 16 KB", width, height, err)
+	}
+}
+
+// largeJPEG encodes a 24 MP photo, about what a full frame camera takes
+func largeJPEG(b *testing.B) []byte {
+	b.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, createTestImage(6000, 4000), &jpeg.Options{Quality: 90}); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// BenchmarkProcessDecodingPerDerivative is how photos used to be processed: the file read
+// into memory, then decoded again for every derivative.
+func BenchmarkProcessDecodingPerDerivative(b *testing.B) {
+	p := NewProcessor()
+	file := largeJPEG(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := io.ReadAll(bytes.NewReader(file))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, _, err := p.GetImageDimensions(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := p.ExtractEXIF(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := p.GenerateThumbnail(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := p.GeneratePlaceholder(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := p.GenerateOptimized(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProcessSharedDecode streams the file through a single decode shared by every derivative.
+func BenchmarkProcessSharedDecode(b *testing.B) {
+	p := NewProcessor()
+	file := largeJPEG(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src, err := p.Open(bytes.NewReader(file))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := p.GenerateThumbnailFrom(src.Image, NewThumbnailStrategy()); err != nil {
+			b.Fatal(err)
+		}
+		NewPlaceholder(src.Image)
+		if _, err := p.GenerateOptimizedFrom(src.Image); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRejectDecompressionBomb shows an oversized image costs a header read, not a decode.
+func BenchmarkRejectDecompressionBomb(b *testing.B) {
+	p := NewProcessor()
+	bomb := pngBomb(50000, 50000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Open(bytes.NewReader(bomb)); !errors.Is(err, ErrTooManyPixels) {
+			b.Fatal(err)
+		}
+	}
+}
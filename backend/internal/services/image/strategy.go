@@ -12,7 +12,8 @@ import (
 
 // ProcessingStrategy defines the interface for image processing strategies.
 type ProcessingStrategy interface {
-	// Process applies the processing strategy to an image and returns the result.
+	// Process applies the processing strategy to an image and returns the result: a new
+	// image, or img itself if there is nothing to do. img is never modified.
 	Process(img image.Image) (image.Image, error)
 	// Name returns the strategy name for logging and identification.
 	Name() string
@@ -34,15 +35,30 @@ func (sc *StrategyChain) Add(strategy ProcessingStrategy) *StrategyChain {
 	return sc
 }
 
-// Process applies all strategies in sequence.
+// Process applies all strategies in sequence. Once a strategy has returned a new RGBA image,
+// later strategies that can draw in place do so rather than copying it again.
 func (sc *StrategyChain) Process(img image.Image) (image.Image, error) {
 	result := img
+	owned := false // whether result is an RGBA allocated within the chain, never the caller's img
 	for _, strategy := range sc.strategies {
-		var err error
-		result, err = strategy.Process(result)
+		if rgba, ok := result.(*image.RGBA); ok && owned {
+			if inPlace, ok := strategy.(InPlaceStrategy); ok {
+				if err := inPlace.ProcessInPlace(rgba); err != nil {
+					return nil, fmt.Errorf("strategy %s failed: %w", strategy.Name(), err)
+				}
+				continue
+			}
+		}
+
+		next, err := strategy.Process(result)
 		if err != nil {
 			return nil, fmt.Errorf("strategy %s failed: %w", strategy.Name(), err)
 		}
+		previous, _ := result.(*image.RGBA)
+		if rgba, ok := next.(*image.RGBA); ok && rgba != previous {
+			owned = true
+		}
+		result = next
 	}
 	return result, nil
 }
@@ -124,6 +140,12 @@ func NewWatermarkStrategy(text, position string) *WatermarkStrategy {
 	}
 }
 
+// NewStyledWatermarkStrategy creates a watermark strategy with full control over
+// font, size, color, opacity, margin, mode and rotation.
+func NewStyledWatermarkStrategy(opts WatermarkOptions) *WatermarkStrategy {
+	return &WatermarkStrategy{processor: NewProcessor(), Options: opts}
+}
+
 // Process applies a watermark to the image.
 func (s *WatermarkStrategy) Process(img image.Image) (image.Image, error) {
 	if s.Options.Text == "" {
@@ -132,11 +154,45 @@ func (s *WatermarkStrategy) Process(img image.Image) (image.Image, error) {
 	return s.processor.ApplyWatermark(img, s.Options), nil
 }
 
+// ProcessInPlace draws the watermark directly onto img.
+func (s *WatermarkStrategy) ProcessInPlace(img *image.RGBA) error {
+	s.processor.DrawWatermark(img, s.Options)
+	return nil
+}
+
 // Name returns the strategy name.
 func (s *WatermarkStrategy) Name() string {
 	return "watermark"
 }
 
+// LogoWatermarkStrategy composites a logo image onto an image.
+type LogoWatermarkStrategy struct {
+	processor *Processor
+	Logo      image.Image
+	Options   LogoOptions
+}
+
+// NewLogoWatermarkStrategy creates a new logo watermark strategy.
+func NewLogoWatermarkStrategy(logo image.Image, opts LogoOptions) *LogoWatermarkStrategy {
+	return &LogoWatermarkStrategy{processor: NewProcessor(), Logo: logo, Options: opts}
+}
+
+// Process applies the logo to the image.
+func (s *LogoWatermarkStrategy) Process(img image.Image) (image.Image, error) {
+	return s.processor.ApplyLogoWatermark(img, s.Logo, s.Options), nil
+}
+
+// ProcessInPlace draws the logo directly onto img.
+func (s *LogoWatermarkStrategy) ProcessInPlace(img *image.RGBA) error {
+	s.processor.DrawLogoWatermark(img, s.Logo, s.Options)
+	return nil
+}
+
+// Name returns the strategy name.
+func (s *LogoWatermarkStrategy) Name() string {
+	return "logo-watermark"
+}
+
 // GrayscaleStrategy converts an image to grayscale.
 type GrayscaleStrategy struct{}
 
@@ -252,18 +308,38 @@ func (e *PNGEncoder) Format() string {
 	return "png"
 }
 
+// WebPEncoder encodes images as lossy WebP. It needs libwebp, so Encode
+// fails with ErrFormatUnavailable in builds without cgo.
+type WebPEncoder struct {
+	Quality int
+}
+
+// NewWebPEncoder creates a new WebP encoder with the given quality.
+func NewWebPEncoder(quality int) *WebPEncoder {
+	return &WebPEncoder{Quality: quality}
+}
+
+// Format returns the output format.
+func (e *WebPEncoder) Format() string {
+	return "webp"
+}
+
 // NewImageProcessor creates a new image processor with the given encoder.
 func NewImageProcessor(encoder Encoder) *ImageProcessor {
 	return &ImageProcessor{encoder: encoder}
 }
 
-// Process decodes an image, applies the strategy, and encodes the result.
+// Process decodes an image, rotates it upright per its EXIF orientation, applies the strategy, and encodes the result.
 func (p *ImageProcessor) Process(imageData io.Reader, strategy ProcessingStrategy) ([]byte, error) {
-	img, _, err := image.Decode(imageData)
+	img, err := DecodeOriented(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
+	return p.ProcessImage(img, strategy)
+}
 
+// ProcessImage applies the strategy to an already decoded image and encodes the result.
+func (p *ImageProcessor) ProcessImage(img image.Image, strategy ProcessingStrategy) ([]byte, error) {
 	processed, err := strategy.Process(img)
 	if err != nil {
 		return nil, fmt.Errorf("processing failed: %w", err)
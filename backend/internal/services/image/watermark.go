@@ -0,0 +1,346 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark modes
+const (
+	WatermarkModeSingle   = "single"   // one watermark at Position
+	WatermarkModeDiagonal = "diagonal" // one watermark across the middle, along the image diagonal
+	WatermarkModeTiled    = "tiled"    // repeated across the whole image
+)
+
+// Watermark defaults, used for options left at their zero value
+const (
+	DefaultWatermarkFont    = "sans"
+	DefaultWatermarkSize    = 3.0 // percent of image width
+	DefaultWatermarkColor   = "#ffffff"
+	DefaultWatermarkOpacity = 0.6
+	DefaultWatermarkMargin  = 2.0 // percent of image width
+)
+
+// Bounds for watermark options, shared with request validation
+const (
+	MinWatermarkSize     = 1.0
+	MaxWatermarkSize     = 20.0
+	MaxWatermarkMargin   = 25.0
+	MaxWatermarkRotation = 180.0
+)
+
+// WatermarkOptions configures watermark application
+type WatermarkOptions struct {
+	Text     string
+	Position string  // "bottom-right", "bottom-left", "center"; single mode only
+	Font     string  // a registered font, see FontNames
+	Size     float64 // font size as a percentage of image width
+	Color    string  // hex, e.g. "#ffffff"
+	Opacity  float64 // 0-1
+	Margin   float64 // distance from the image edges as a percentage of image width
+	Mode     string  // single, diagonal or tiled
+	Rotation float64 // degrees counter-clockwise; ignored in diagonal mode
+}
+
+// withDefaults fills in options left unset
+func (o WatermarkOptions) withDefaults() WatermarkOptions {
+	if !HasFont(o.Font) {
+		o.Font = DefaultWatermarkFont
+	}
+	if o.Size <= 0 {
+		o.Size = DefaultWatermarkSize
+	}
+	if _, err := ParseHexColor(o.Color); err != nil {
+		o.Color = DefaultWatermarkColor
+	}
+	if o.Opacity <= 0 || o.Opacity > 1 {
+		o.Opacity = DefaultWatermarkOpacity
+	}
+	if o.Margin <= 0 {
+		o.Margin = DefaultWatermarkMargin
+	}
+	if o.Mode == "" {
+		o.Mode = WatermarkModeSingle
+	}
+	return o
+}
+
+// ApplyWatermark adds a text watermark to a copy of an image
+func (p *Processor) ApplyWatermark(img image.Image, opts WatermarkOptions) image.Image {
+	if opts.Text == "" {
+		return img
+	}
+	dst := cloneRGBA(img)
+	p.DrawWatermark(dst, opts)
+	return dst
+}
+
+// DrawWatermark composites a text watermark directly onto dst. Images whose font can't be
+// loaded are left as they are.
+func (p *Processor) DrawWatermark(dst *image.RGBA, opts WatermarkOptions) {
+	if opts.Text == "" {
+		return
+	}
+	opts = opts.withDefaults()
+
+	bounds := dst.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	stamp, err := renderWatermarkText(opts, width)
+	if err != nil {
+		return
+	}
+	overlay := func(src image.Image, x, y int) {
+		compositeOver(dst, src, bounds.Min.X+x, bounds.Min.Y+y)
+	}
+
+	switch opts.Mode {
+	case WatermarkModeDiagonal:
+		// Run the text from the bottom-left corner towards the top-right one
+		angle := math.Atan2(float64(height), float64(width)) * 180 / math.Pi
+		rotated := rotateStamp(stamp, angle)
+		size := rotated.Bounds().Size()
+		overlay(rotated, (width-size.X)/2, (height-size.Y)/2)
+
+	case WatermarkModeTiled:
+		rotated := rotateStamp(stamp, opts.Rotation)
+		size := rotated.Bounds().Size()
+		stepX := size.X + size.X/2
+		stepY := size.Y + stamp.Bounds().Dy()*2
+		for row, y := 0, 0; y < height; row, y = row+1, y+stepY {
+			// Stagger alternate rows so the text doesn't form columns
+			x := 0
+			if row%2 == 1 {
+				x = -stepX / 2
+			}
+			for ; x < width; x += stepX {
+				overlay(rotated, x, y)
+			}
+		}
+
+	default:
+		rotated := rotateStamp(stamp, opts.Rotation)
+		x, y := place(opts.Position, rotated.Bounds().Size(), width, height, int(float64(width)*opts.Margin/100))
+		overlay(rotated, x, y)
+	}
+}
+
+// LogoOptions configures a logo watermark
+type LogoOptions struct {
+	Position string  // "bottom-right", "bottom-left", "center"
+	Scale    float64 // logo width as a percentage of image width
+	Opacity  float64 // 0-1, on top of the logo's own transparency
+	Margin   float64 // distance from the image edges as a percentage of image width
+}
+
+// Logo watermark defaults and bounds
+const (
+	DefaultLogoScale = 15.0
+	MinLogoScale     = 1.0
+	MaxLogoScale     = 100.0
+)
+
+// withDefaults fills in options left unset
+func (o LogoOptions) withDefaults() LogoOptions {
+	if o.Scale <= 0 || o.Scale > MaxLogoScale {
+		o.Scale = DefaultLogoScale
+	}
+	if o.Opacity <= 0 || o.Opacity > 1 {
+		o.Opacity = DefaultWatermarkOpacity
+	}
+	if o.Margin <= 0 {
+		o.Margin = DefaultWatermarkMargin
+	}
+	return o
+}
+
+// ApplyLogoWatermark composites a logo, typically a PNG with transparency, onto a copy of an
+// image. The logo keeps its aspect ratio and is shrunk further if it would be taller than the image.
+func (p *Processor) ApplyLogoWatermark(img, logo image.Image, opts LogoOptions) image.Image {
+	if logo == nil || logo.Bounds().Empty() {
+		return img
+	}
+	dst := cloneRGBA(img)
+	p.DrawLogoWatermark(dst, logo, opts)
+	return dst
+}
+
+// DrawLogoWatermark composites a logo directly onto dst
+func (p *Processor) DrawLogoWatermark(dst *image.RGBA, logo image.Image, opts LogoOptions) {
+	if logo == nil || logo.Bounds().Empty() {
+		return
+	}
+	opts = opts.withDefaults()
+
+	bounds := dst.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	logoBounds := logo.Bounds()
+	logoWidth := int(math.Round(float64(width) * opts.Scale / 100))
+	logoHeight := int(math.Round(float64(logoWidth) * float64(logoBounds.Dy()) / float64(logoBounds.Dx())))
+	if logoHeight > height {
+		logoWidth, logoHeight = logoWidth*height/logoHeight, height
+	}
+	if logoWidth < 1 || logoHeight < 1 {
+		return
+	}
+	scaled := imaging.Resize(logo, logoWidth, logoHeight, imaging.Lanczos)
+	fade(scaled, opts.Opacity)
+
+	x, y := place(opts.Position, scaled.Bounds().Size(), width, height, int(float64(width)*opts.Margin/100))
+	compositeOver(dst, scaled, bounds.Min.X+x, bounds.Min.Y+y)
+}
+
+// place returns the top-left corner for a watermark of the given size at a position
+func place(position string, size image.Point, width, height, margin int) (int, int) {
+	switch position {
+	case "bottom-left":
+		return margin, height - size.Y - margin
+	case "center":
+		return (width - size.X) / 2, (height - size.Y) / 2
+	default: // bottom-right
+		return width - size.X - margin, height - size.Y - margin
+	}
+}
+
+// renderWatermarkText draws the text, with a soft shadow for legibility on light photos, onto
+// a transparent image just large enough to hold it
+func renderWatermarkText(opts WatermarkOptions, imageWidth int) (image.Image, error) {
+	f, err := loadFont(opts.Font)
+	if err != nil {
+		return nil, err
+	}
+	size := math.Max(float64(imageWidth)*opts.Size/100, 8)
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("font face: %w", err)
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	ascent, descent := metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	shadow := int(math.Max(size/24, 1))
+	advance := font.MeasureString(face, opts.Text).Ceil()
+	stamp := image.NewRGBA(image.Rect(0, 0, advance+shadow, ascent+descent+shadow))
+
+	c, _ := ParseHexColor(opts.Color)
+	alpha := uint8(math.Round(opts.Opacity * 255))
+	d := &font.Drawer{Dst: stamp, Face: face}
+
+	d.Src = image.NewUniform(color.NRGBA{A: alpha / 2})
+	d.Dot = fixed.P(shadow, ascent+shadow)
+	d.DrawString(opts.Text)
+
+	d.Src = image.NewUniform(color.NRGBA{R: c.R, G: c.G, B: c.B, A: alpha})
+	d.Dot = fixed.P(0, ascent)
+	d.DrawString(opts.Text)
+
+	return stamp, nil
+}
+
+// rotateStamp rotates a watermark counter-clockwise, growing it to fit
+func rotateStamp(stamp image.Image, degrees float64) image.Image {
+	if math.Mod(degrees, 360) == 0 {
+		return stamp
+	}
+	return imaging.Rotate(stamp, degrees, color.Transparent)
+}
+
+// ParseHexColor parses a "#rgb" or "#rrggbb" color
+func ParseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 || !strings.HasPrefix(s, "#") {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: expected #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: expected #rrggbb", s)
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+var fonts = struct {
+	sync.RWMutex
+	data   map[string][]byte
+	parsed map[string]*opentype.Font
+}{
+	data: map[string][]byte{
+		"sans":        goregular.TTF,
+		"sans-bold":   gobold.TTF,
+		"sans-italic": goitalic.TTF,
+		"mono":        gomono.TTF,
+	},
+	parsed: map[string]*opentype.Font{},
+}
+
+// RegisterFont makes a TrueType or OpenType font available to watermarks under name
+func RegisterFont(name string, data []byte) error {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("parse font %s: %w", name, err)
+	}
+	fonts.Lock()
+	defer fonts.Unlock()
+	fonts.data[name] = data
+	fonts.parsed[name] = f
+	return nil
+}
+
+// HasFont reports whether a watermark font is registered under name
+func HasFont(name string) bool {
+	fonts.RLock()
+	defer fonts.RUnlock()
+	_, ok := fonts.data[name]
+	return ok
+}
+
+// FontNames returns the registered watermark fonts in alphabetical order
+func FontNames() []string {
+	fonts.RLock()
+	defer fonts.RUnlock()
+	names := make([]string, 0, len(fonts.data))
+	for name := range fonts.data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadFont returns a registered font, parsing embedded ones on first use
+func loadFont(name string) (*opentype.Font, error) {
+	fonts.RLock()
+	f, ok := fonts.parsed[name]
+	data := fonts.data[name]
+	fonts.RUnlock()
+	if ok {
+		return f, nil
+	}
+	if data == nil {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font %s: %w", name, err)
+	}
+	fonts.Lock()
+	fonts.parsed[name] = f
+	fonts.Unlock()
+	return f, nil
+}
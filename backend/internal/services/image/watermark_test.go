@@ -0,0 +1,291 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomedium"
+)
+
+var watermarkBackground = color.RGBA{R: 40, G: 40, B: 40, A: 255}
+
+func solidImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 40, 40, 40, 255
+	}
+	return img
+}
+
+// changedBounds returns the smallest rectangle containing every pixel the watermark touched
+// and how many pixels that is
+func changedBounds(img image.Image) (image.Rectangle, int) {
+	var r image.Rectangle
+	count := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == watermarkBackground {
+				continue
+			}
+			r = r.Union(image.Rect(x, y, x+1, y+1))
+			count++
+		}
+	}
+	return r, count
+}
+
+func TestApplyWatermarkEmptyText(t *testing.T) {
+	img := solidImage(200, 100)
+	if got := NewProcessor().ApplyWatermark(img, WatermarkOptions{}); got != img {
+		t.Error("ApplyWatermark() without text should return the image unchanged")
+	}
+}
+
+func TestApplyWatermarkPosition(t *testing.T) {
+	p := NewProcessor()
+
+	tests := []struct {
+		position string
+		inside   func(r image.Rectangle) bool
+	}{
+		{"bottom-right", func(r image.Rectangle) bool { return r.Min.X > 300 && r.Min.Y > 150 }},
+		{"bottom-left", func(r image.Rectangle) bool { return r.Max.X < 300 && r.Min.Y > 150 }},
+		{"center", func(r image.Rectangle) bool { return r.Min.X > 100 && r.Max.X < 500 && r.Min.Y > 50 && r.Max.Y < 250 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.position, func(t *testing.T) {
+			got := p.ApplyWatermark(solidImage(600, 300), WatermarkOptions{Text: "© Studio", Position: tt.position})
+			if got.Bounds() != image.Rect(0, 0, 600, 300) {
+				t.Fatalf("bounds = %v, want 600x300", got.Bounds())
+			}
+			r, count := changedBounds(got)
+			if count == 0 {
+				t.Fatal("watermark drew nothing")
+			}
+			if !tt.inside(r) {
+				t.Errorf("watermark drawn at %v", r)
+			}
+		})
+	}
+}
+
+func TestApplyWatermarkMargin(t *testing.T) {
+	// 10% of 600px keeps the text 60px clear of the right and bottom edges
+	got := NewProcessor().ApplyWatermark(solidImage(600, 300), WatermarkOptions{Text: "© Studio", Margin: 10})
+	r, _ := changedBounds(got)
+	if r.Max.X > 540 || r.Max.Y > 240 || r.Max.X < 520 {
+		t.Errorf("watermark drawn at %v, want it to end near (540, 240)", r)
+	}
+}
+
+func TestApplyWatermarkScalesWithWidth(t *testing.T) {
+	p := NewProcessor()
+	opts := WatermarkOptions{Text: "© Studio", Size: 5}
+
+	small, _ := changedBounds(p.ApplyWatermark(solidImage(400, 400), opts))
+	large, _ := changedBounds(p.ApplyWatermark(solidImage(1600, 400), opts))
+
+	ratio := float64(large.Dx()) / float64(small.Dx())
+	if ratio < 3.5 || ratio > 4.5 {
+		t.Errorf("text width %d at 1600px vs %d at 400px, want about 4x", large.Dx(), small.Dx())
+	}
+
+	bigger, _ := changedBounds(p.ApplyWatermark(solidImage(400, 400), WatermarkOptions{Text: "© Studio", Size: 10}))
+	if bigger.Dx() <= small.Dx() {
+		t.Errorf("size 10 width %d should exceed size 5 width %d", bigger.Dx(), small.Dx())
+	}
+}
+
+func TestApplyWatermarkColorAndOpacity(t *testing.T) {
+	p := NewProcessor()
+	brightest := func(img image.Image) color.RGBA {
+		var best color.RGBA
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				if c.R > best.R {
+					best = c
+				}
+			}
+		}
+		return best
+	}
+
+	opaque := brightest(p.ApplyWatermark(solidImage(400, 200), WatermarkOptions{Text: "MMMM", Color: "#ff0000", Opacity: 1, Size: 10}))
+	if opaque.R < 240 || opaque.G > 60 || opaque.B > 60 {
+		t.Errorf("opaque red watermark brightest pixel = %v, want red", opaque)
+	}
+
+	faint := brightest(p.ApplyWatermark(solidImage(400, 200), WatermarkOptions{Text: "MMMM", Color: "#ff0000", Opacity: 0.25, Size: 10}))
+	// 25% red over a 40 gray background
+	if faint.R < 80 || faint.R > 110 {
+		t.Errorf("faint red watermark brightest pixel = %v, want R about 94", faint)
+	}
+}
+
+func TestApplyWatermarkModes(t *testing.T) {
+	p := NewProcessor()
+
+	diagonal, _ := changedBounds(p.ApplyWatermark(solidImage(600, 400), WatermarkOptions{Text: "© Studio", Mode: WatermarkModeDiagonal, Size: 8}))
+	if diagonal.Dy() < 100 || diagonal.Min.X < 50 || diagonal.Max.X > 550 {
+		t.Errorf("diagonal watermark drawn at %v, want it slanted across the middle", diagonal)
+	}
+
+	single, singleCount := changedBounds(p.ApplyWatermark(solidImage(600, 400), WatermarkOptions{Text: "© Studio"}))
+	tiled, tiledCount := changedBounds(p.ApplyWatermark(solidImage(600, 400), WatermarkOptions{Text: "© Studio", Mode: WatermarkModeTiled}))
+	if tiledCount < 10*singleCount {
+		t.Errorf("tiled watermark changed %d pixels, single %d; want many repeats", tiledCount, singleCount)
+	}
+	if tiled.Min.X > 20 || tiled.Min.Y > 20 || tiled.Max.X < 580 || tiled.Max.Y < 340 {
+		t.Errorf("tiled watermark covers %v, want the whole image (single covered %v)", tiled, single)
+	}
+}
+
+func TestApplyWatermarkRotation(t *testing.T) {
+	p := NewProcessor()
+	flat, _ := changedBounds(p.ApplyWatermark(solidImage(600, 600), WatermarkOptions{Text: "© Studio", Position: "center", Size: 6}))
+	upright, _ := changedBounds(p.ApplyWatermark(solidImage(600, 600), WatermarkOptions{Text: "© Studio", Position: "center", Size: 6, Rotation: 90}))
+	if upright.Dy() <= upright.Dx() || flat.Dx() <= flat.Dy() {
+		t.Errorf("rotated by 90 drawn at %v, unrotated at %v; want the text turned on its side", upright, flat)
+	}
+}
+
+func TestApplyWatermarkFonts(t *testing.T) {
+	p := NewProcessor()
+	regular, _ := changedBounds(p.ApplyWatermark(solidImage(600, 200), WatermarkOptions{Text: "iiiiii", Size: 6}))
+	mono, _ := changedBounds(p.ApplyWatermark(solidImage(600, 200), WatermarkOptions{Text: "iiiiii", Size: 6, Font: "mono"}))
+	if mono.Dx() <= regular.Dx() {
+		t.Errorf("monospaced text width %d should exceed proportional width %d", mono.Dx(), regular.Dx())
+	}
+
+	// Unknown fonts fall back to the default rather than dropping the watermark
+	_, count := changedBounds(p.ApplyWatermark(solidImage(600, 200), WatermarkOptions{Text: "© Studio", Font: "papyrus"}))
+	if count == 0 {
+		t.Error("watermark with an unknown font drew nothing")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{"#ffffff", color.NRGBA{255, 255, 255, 255}, false},
+		{"#FFCC00", color.NRGBA{255, 204, 0, 255}, false},
+		{"#f00", color.NRGBA{255, 0, 0, 255}, false},
+		{"ffffff", color.NRGBA{}, true},
+		{"#fffff", color.NRGBA{}, true},
+		{"#gggggg", color.NRGBA{}, true},
+		{"white", color.NRGBA{}, true},
+		{"", color.NRGBA{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseHexColor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHexColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterFont(t *testing.T) {
+	if err := RegisterFont("broken", []byte("not a font")); err == nil {
+		t.Error("RegisterFont() with invalid data should fail")
+	}
+	if HasFont("broken") {
+		t.Error("invalid font should not be registered")
+	}
+
+	if err := RegisterFont("studio", gomedium.TTF); err != nil {
+		t.Fatalf("RegisterFont() error = %v", err)
+	}
+	_, count := changedBounds(NewProcessor().ApplyWatermark(solidImage(600, 200), WatermarkOptions{Text: "© Studio", Font: "studio"}))
+	if !HasFont("studio") || count == 0 {
+		t.Error("registered font should be usable for watermarks")
+	}
+
+	for _, name := range []string{"sans", "sans-bold", "sans-italic", "mono"} {
+		if !HasFont(name) {
+			t.Errorf("built-in font %q missing from %v", name, FontNames())
+		}
+	}
+}
+
+// whiteLogo is an opaque white logo twice as wide as it is tall
+func whiteLogo() image.Image {
+	logo := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for i := range logo.Pix {
+		logo.Pix[i] = 255
+	}
+	return logo
+}
+
+func TestApplyLogoWatermarkNoLogo(t *testing.T) {
+	img := solidImage(200, 100)
+	if got := NewProcessor().ApplyLogoWatermark(img, nil, LogoOptions{}); got != img {
+		t.Error("ApplyLogoWatermark() without a logo should return the image unchanged")
+	}
+}
+
+func TestApplyLogoWatermarkPositionAndScale(t *testing.T) {
+	p := NewProcessor()
+
+	tests := []struct {
+		name string
+		opts LogoOptions
+		want image.Rectangle
+	}{
+		{"default bottom-right at 15%", LogoOptions{Margin: 2}, image.Rect(498, 243, 588, 288)},
+		{"bottom-left at 20%", LogoOptions{Position: "bottom-left", Scale: 20, Margin: 2}, image.Rect(12, 228, 132, 288)},
+		{"center at 50%", LogoOptions{Position: "center", Scale: 50}, image.Rect(150, 75, 450, 225)},
+		{"capped to the image height", LogoOptions{Position: "center", Scale: 100}, image.Rect(0, 0, 600, 300)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.ApplyLogoWatermark(solidImage(600, 300), whiteLogo(), tt.opts)
+			r, _ := changedBounds(got)
+			// Resampling may soften the logo's edges by a pixel
+			if abs(r.Min.X-tt.want.Min.X) > 1 || abs(r.Min.Y-tt.want.Min.Y) > 1 ||
+				abs(r.Max.X-tt.want.Max.X) > 1 || abs(r.Max.Y-tt.want.Max.Y) > 1 {
+				t.Errorf("logo drawn at %v, want %v", r, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyLogoWatermarkOpacity(t *testing.T) {
+	got := NewProcessor().ApplyLogoWatermark(solidImage(400, 200), whiteLogo(), LogoOptions{Position: "center", Scale: 50, Opacity: 0.5})
+	c := color.RGBAModel.Convert(got.At(200, 100)).(color.RGBA)
+	// 50% white over a 40 gray background
+	if c.R < 140 || c.R > 155 {
+		t.Errorf("center pixel = %v, want about 147", c)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func BenchmarkApplyWatermark(b *testing.B) {
+	p := NewProcessor()
+	img := solidImage(2048, 1365)
+	opts := WatermarkOptions{Text: "© Studio", Mode: WatermarkModeTiled}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.ApplyWatermark(img, opts)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build cgo
+
+package image
+
+import (
+	"image"
+
+	"github.com/chai2010/webp"
+)
+
+const webpAvailable = true
+
+// Encode encodes the image as lossy WebP. Photos carry no alpha, so the
+// image is encoded as RGB.
+func (e *WebPEncoder) Encode(img image.Image) ([]byte, error) {
+	return webp.EncodeRGB(img, float32(e.Quality))
+}
@@ -0,0 +1,34 @@
+//go:build cgo
+
+package image
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chai2010/webp"
+)
+
+func TestWebPEncoder(t *testing.T) {
+	img := createTestImage(640, 480)
+
+	data, err := NewWebPEncoder(80).Encode(img)
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	cfg, err := webp.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a WebP image: %v", err)
+	}
+	if cfg.Width != 640 || cfg.Height != 480 {
+		t.Errorf("decoded size = %dx%d, want 640x480", cfg.Width, cfg.Height)
+	}
+
+	jpegData, err := NewJPEGEncoder(80).Encode(img)
+	if err != nil {
+		t.Fatalf("JPEG Encode() error: %v", err)
+	}
+	if len(data) >= len(jpegData) {
+		t.Errorf("WebP is %d bytes, want smaller than the %d byte JPEG", len(data), len(jpegData))
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package image
+
+import (
+	"fmt"
+	"image"
+)
+
+// libwebp is linked through cgo, so pure Go builds cannot encode WebP
+const webpAvailable = false
+
+// Encode reports that WebP is unavailable in this build.
+func (e *WebPEncoder) Encode(img image.Image) ([]byte, error) {
+	return nil, fmt.Errorf("%w: webp requires a cgo build", ErrFormatUnavailable)
+}
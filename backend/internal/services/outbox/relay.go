@@ -0,0 +1,106 @@
+// Package outbox relays domain events persisted in the transactional outbox to the event bus.
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/pkg/events"
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// DefaultMaxAttempts is how many times an event is published before it is marked as failed.
+const DefaultMaxAttempts = 10
+
+// Relay publishes pending outbox events to an event bus.
+//
+// Delivery is at-least-once: an event is marked published only after every
+// subscriber has handled it, so a crash in between causes it to be published
+// again. Subscribers should deduplicate on Event.ID, which is stable across
+// redeliveries.
+type Relay struct {
+	outboxRepo  repository.OutboxRepository
+	bus         events.EventBus
+	maxAttempts int
+}
+
+// NewRelay creates a new outbox relay.
+func NewRelay(outboxRepo repository.OutboxRepository, bus events.EventBus, maxAttempts int) *Relay {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &Relay{
+		outboxRepo:  outboxRepo,
+		bus:         bus,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Result summarizes a relay run.
+type Result struct {
+	Published int
+	Retrying  int
+	Failed    int
+}
+
+// RelayPending publishes up to limit pending events in creation order.
+func (r *Relay) RelayPending(ctx context.Context, limit int) (*Result, error) {
+	pending, err := r.outboxRepo.ListPending(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending events: %w", err)
+	}
+
+	result := &Result{}
+	for _, record := range pending {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if err := r.publish(ctx, record); err != nil {
+			permanent := record.Attempts+1 >= r.maxAttempts
+			if _, decodeErr := record.ToEvent(); decodeErr != nil {
+				permanent = true
+			}
+			if markErr := r.outboxRepo.MarkFailed(ctx, record.EventID, err.Error(), permanent); markErr != nil {
+				logger.Error("Failed to record outbox delivery failure", map[string]interface{}{
+					"eventId": record.EventID, "error": markErr.Error(),
+				})
+			}
+			if permanent {
+				result.Failed++
+			} else {
+				result.Retrying++
+			}
+			logger.Warn("Outbox event delivery failed", map[string]interface{}{
+				"eventId": record.EventID, "eventType": record.EventType,
+				"attempt": record.Attempts + 1, "permanent": permanent, "error": err.Error(),
+			})
+			continue
+		}
+
+		if err := r.outboxRepo.MarkPublished(ctx, record.EventID); err != nil {
+			// The event stays pending and will be published again on the next run
+			logger.Error("Failed to mark outbox event published", map[string]interface{}{
+				"eventId": record.EventID, "error": err.Error(),
+			})
+			continue
+		}
+		result.Published++
+	}
+
+	if len(pending) > 0 {
+		logger.Info("Outbox relay completed", map[string]interface{}{
+			"published": result.Published, "retrying": result.Retrying, "failed": result.Failed,
+		})
+	}
+	return result, nil
+}
+
+func (r *Relay) publish(ctx context.Context, record *repository.OutboxEvent) error {
+	event, err := record.ToEvent()
+	if err != nil {
+		return err
+	}
+	return r.bus.Publish(ctx, event)
+}
@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"photographer-gallery/backend/internal/repository"
+	"photographer-gallery/backend/internal/testing/mocks"
+	"photographer-gallery/backend/pkg/events"
+)
+
+func addEvent(t *testing.T, outboxRepo *mocks.MockOutboxRepository, event events.Event) *repository.OutboxEvent {
+	t.Helper()
+	record, err := repository.NewOutboxEvent(event)
+	if err != nil {
+		t.Fatalf("NewOutboxEvent() error: %v", err)
+	}
+	outboxRepo.AddEvent(record)
+	return record
+}
+
+func TestRelayPublishesPendingEvents(t *testing.T) {
+	outboxRepo := mocks.NewMockOutboxRepository()
+	bus := events.NewEventBus()
+	relay := NewRelay(outboxRepo, bus, 3)
+
+	record := addEvent(t, outboxRepo, events.NewEvent(events.GalleryCreated, &events.GalleryCreatedPayload{
+		GalleryID: "gal_1", PhotographerID: "user_1", Name: "Wedding",
+	}, "gal_1"))
+
+	var received events.Event
+	bus.Subscribe(events.GalleryCreated, func(ctx context.Context, event events.Event) error {
+		received = event
+		return nil
+	})
+
+	result, err := relay.RelayPending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("RelayPending() error: %v", err)
+	}
+	if result.Published != 1 {
+		t.Errorf("Published = %d, want 1", result.Published)
+	}
+
+	if received == nil {
+		t.Fatal("subscriber did not receive the event")
+	}
+	if received.ID() != record.EventID {
+		t.Errorf("ID() = %v, want %v", received.ID(), record.EventID)
+	}
+	payload, ok := received.Payload().(*events.GalleryCreatedPayload)
+	if !ok {
+		t.Fatalf("Payload type = %T, want *events.GalleryCreatedPayload", received.Payload())
+	}
+	if payload.Name != "Wedding" {
+		t.Errorf("Payload.Name = %v, want Wedding", payload.Name)
+	}
+
+	if pending, _ := outboxRepo.ListPending(context.Background(), 10); len(pending) != 0 {
+		t.Errorf("Expected no pending events, got %d", len(pending))
+	}
+}
+
+func TestRelayRetriesFailedDelivery(t *testing.T) {
+	outboxRepo := mocks.NewMockOutboxRepository()
+	bus := events.NewEventBus()
+	relay := NewRelay(outboxRepo, bus, 2)
+
+	addEvent(t, outboxRepo, events.NewEvent(events.PhotoProcessed, &events.PhotoProcessedPayload{
+		PhotoID: "photo_1", GalleryID: "gal_1", Status: "completed",
+	}, "gal_1"))
+
+	calls := 0
+	bus.Subscribe(events.PhotoProcessed, func(ctx context.Context, event events.Event) error {
+		calls++
+		return errors.New("subscriber unavailable")
+	})
+
+	// First failure leaves the event pending for another attempt
+	result, _ := relay.RelayPending(context.Background(), 10)
+	if result.Retrying != 1 {
+		t.Errorf("Retrying = %d, want 1", result.Retrying)
+	}
+	if pending, _ := outboxRepo.ListPending(context.Background(), 10); len(pending) != 1 {
+		t.Fatalf("Expected event to remain pending, got %d pending", len(pending))
+	}
+
+	// Reaching maxAttempts marks the event as permanently failed
+	result, _ = relay.RelayPending(context.Background(), 10)
+	if result.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", result.Failed)
+	}
+	if pending, _ := outboxRepo.ListPending(context.Background(), 10); len(pending) != 0 {
+		t.Errorf("Expected no pending events, got %d", len(pending))
+	}
+
+	stored := outboxRepo.Events()[0]
+	if stored.Status != repository.OutboxStatusFailed {
+		t.Errorf("Status = %v, want %v", stored.Status, repository.OutboxStatusFailed)
+	}
+	if stored.LastError != "subscriber unavailable" {
+		t.Errorf("LastError = %v, want subscriber unavailable", stored.LastError)
+	}
+	if calls != 2 {
+		t.Errorf("subscriber called %d times, want 2", calls)
+	}
+}
+
+func TestRelayRedeliversWhenMarkFails(t *testing.T) {
+	outboxRepo := mocks.NewMockOutboxRepository()
+	bus := events.NewEventBus()
+	relay := NewRelay(outboxRepo, bus, 3)
+
+	addEvent(t, outboxRepo, events.NewEvent(events.FavoriteToggled, &events.FavoriteToggledPayload{
+		PhotoID: "photo_1", GalleryID: "gal_1", ClientID: "session_1", Favorited: true,
+	}, "gal_1"))
+
+	seen := map[string]int{}
+	bus.Subscribe(events.FavoriteToggled, func(ctx context.Context, event events.Event) error {
+		seen[event.ID()]++
+		return nil
+	})
+
+	outboxRepo.MarkErr = errors.New("throttled")
+	result, _ := relay.RelayPending(context.Background(), 10)
+	if result.Published != 0 {
+		t.Errorf("Published = %d, want 0", result.Published)
+	}
+
+	outboxRepo.MarkErr = nil
+	result, _ = relay.RelayPending(context.Background(), 10)
+	if result.Published != 1 {
+		t.Errorf("Published = %d, want 1", result.Published)
+	}
+
+	// At-least-once: the same event ID is delivered twice
+	for id, count := range seen {
+		if count != 2 {
+			t.Errorf("event %s delivered %d times, want 2", id, count)
+		}
+	}
+}
+
+func TestRelayUndecodableEventFailsPermanently(t *testing.T) {
+	outboxRepo := mocks.NewMockOutboxRepository()
+	relay := NewRelay(outboxRepo, events.NewEventBus(), 5)
+
+	outboxRepo.AddEvent(&repository.OutboxEvent{
+		EventID:   "evt_bad",
+		EventType: string(events.PhotoUploaded),
+		Payload:   "{not json",
+		Status:    repository.OutboxStatusPending,
+	})
+
+	result, err := relay.RelayPending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("RelayPending() error: %v", err)
+	}
+	if result.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", result.Failed)
+	}
+}
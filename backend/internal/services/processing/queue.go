@@ -0,0 +1,91 @@
+// Package processing queues photos, and gallery reprocessing jobs, for the image processor Lambda.
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// SQSAPI defines the SQS operations used to queue photos.
+type SQSAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// Queue sends photos to the processing queue. Messages have the same shape as the S3
+// notifications the originals bucket sends on upload, so the processor handles both alike.
+type Queue struct {
+	client         SQSAPI
+	queueURL       string
+	originalBucket string
+}
+
+// NewQueue creates a queue that sends photos stored in originalBucket to queueURL.
+func NewQueue(client SQSAPI, queueURL, originalBucket string) *Queue {
+	return &Queue{client: client, queueURL: queueURL, originalBucket: originalBucket}
+}
+
+// JobReprocessGallery is the type of GalleryJob messages.
+const JobReprocessGallery = "reprocess-gallery"
+
+// GalleryJob asks the processor to queue one page of a gallery's photos for reprocessing.
+// Cursor is where the page starts; nil starts at the first photo.
+type GalleryJob struct {
+	Type      string                 `json:"type"`
+	GalleryID string                 `json:"galleryId"`
+	Cursor    map[string]interface{} `json:"cursor,omitempty"`
+}
+
+// ParseGalleryJob decodes a gallery job, reporting false for any other message, such as an
+// S3 notification.
+func ParseGalleryJob(body string) (*GalleryJob, bool) {
+	var job GalleryJob
+	if err := json.Unmarshal([]byte(body), &job); err != nil || job.Type != JobReprocessGallery || job.GalleryID == "" {
+		return nil, false
+	}
+	return &job, true
+}
+
+// Enqueue asks the processor to (re)generate the derivatives of an original.
+func (q *Queue) Enqueue(ctx context.Context, originalKey string) error {
+	err := q.send(ctx, events.S3Event{
+		Records: []events.S3EventRecord{{
+			EventSource: "aws:s3",
+			S3: events.S3Entity{
+				Bucket: events.S3Bucket{Name: q.originalBucket},
+				Object: events.S3Object{Key: originalKey},
+			},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue photo for processing: %w", err)
+	}
+	return nil
+}
+
+// EnqueueGallery asks the processor to queue a gallery's photos for reprocessing, starting
+// at cursor.
+func (q *Queue) EnqueueGallery(ctx context.Context, galleryID string, cursor map[string]interface{}) error {
+	err := q.send(ctx, GalleryJob{Type: JobReprocessGallery, GalleryID: galleryID, Cursor: cursor})
+	if err != nil {
+		return fmt.Errorf("failed to queue gallery for reprocessing: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) send(ctx context.Context, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode processing message: %w", err)
+	}
+
+	_, err = q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(string(body)),
+	})
+	return err
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"photographer-gallery/backend/pkg/logger"
+)
+
+const (
+	// archiveStorageClass is where originals of archived galleries are kept
+	archiveStorageClass = types.StorageClassGlacier
+
+	// restoredStorageClass is where rehydrated originals are copied back to, matching new uploads
+	restoredStorageClass = types.StorageClassIntelligentTiering
+
+	// restoreDays is how long S3 keeps the temporary restored copy. Rehydration copies it to
+	// restoredStorageClass well before then.
+	restoreDays = 7
+)
+
+// ArchivePhoto moves a photo's original to cold storage and deletes its optimized, thumbnail
+// and rendition derivatives, which can be regenerated from the original on restore.
+func (s *Service) ArchivePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.originalBucket),
+		Key:    aws.String(originalKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get original: %w", err)
+	}
+
+	// Originals older than the bucket lifecycle transition may already be cold
+	if !isColdStorageClass(head.StorageClass) {
+		if err := s.copyInPlace(ctx, originalKey, archiveStorageClass); err != nil {
+			return err
+		}
+	}
+
+	if optimizedKey != "" {
+		if err := s.DeleteObject(ctx, s.optimizedBucket, optimizedKey); err != nil {
+			return err
+		}
+	}
+	if thumbnailKey != "" {
+		if err := s.DeleteObject(ctx, s.thumbnailBucket, thumbnailKey); err != nil {
+			return err
+		}
+	}
+
+	return s.deleteRenditions(ctx, renditionKeys)
+}
+
+// RestoreOriginal rehydrates an archived original and reports whether it is readable again.
+// The first call starts an S3 restore, which takes hours; once it completes a later call copies
+// the restored object back to a warm storage class. Calling it repeatedly is safe.
+func (s *Service) RestoreOriginal(ctx context.Context, originalKey string) (bool, error) {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.originalBucket),
+		Key:    aws.String(originalKey),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get original: %w", err)
+	}
+
+	if !isColdStorageClass(head.StorageClass) {
+		return true, nil
+	}
+
+	restore := aws.ToString(head.Restore)
+	switch {
+	case restore == "":
+		_, err := s.client.RestoreObject(ctx, &s3.RestoreObjectInput{
+			Bucket: aws.String(s.originalBucket),
+			Key:    aws.String(originalKey),
+			RestoreRequest: &types.RestoreRequest{
+				Days: aws.Int32(restoreDays),
+				GlacierJobParameters: &types.GlacierJobParameters{
+					Tier: types.TierStandard,
+				},
+			},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to start restore: %w", err)
+		}
+		logger.Info("Started original restore", map[string]interface{}{"key": originalKey})
+		return false, nil
+	case strings.Contains(restore, `ongoing-request="true"`):
+		return false, nil
+	}
+
+	if err := s.copyInPlace(ctx, originalKey, restoredStorageClass); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// copyInPlace rewrites an original with a new storage class
+func (s *Service) copyInPlace(ctx context.Context, key string, storageClass types.StorageClass) error {
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.originalBucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(fmt.Sprintf("%s/%s", s.originalBucket, key)),
+		StorageClass:      storageClass,
+		MetadataDirective: types.MetadataDirectiveCopy,
+	})
+	if err != nil {
+		logger.Error("Failed to change storage class", map[string]interface{}{
+			"error":        err.Error(),
+			"key":          key,
+			"storageClass": string(storageClass),
+		})
+		return fmt.Errorf("failed to change storage class: %w", err)
+	}
+
+	logger.Info("Changed storage class", map[string]interface{}{
+		"key":          key,
+		"storageClass": string(storageClass),
+	})
+	return nil
+}
+
+func isColdStorageClass(storageClass types.StorageClass) bool {
+	return storageClass == types.StorageClassGlacier || storageClass == types.StorageClassDeepArchive
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"photographer-gallery/backend/pkg/logger"
+)
+
+// Watermark logos live in the originals bucket next to the galleries, under their own prefix
+// so uploading one doesn't trigger photo processing.
+
+// GenerateLogoUploadURL creates a presigned URL for uploading a watermark logo to key
+func (s *Service) GenerateLogoUploadURL(ctx context.Context, key, mimeType string) (string, error) {
+	presignedReq, err := s.presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.originalBucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(mimeType),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = s.presignExpiration
+	})
+	if err != nil {
+		logger.Error("Failed to generate presigned logo upload URL", map[string]interface{}{
+			"error": err.Error(),
+			"key":   key,
+		})
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+	return presignedReq.URL, nil
+}
+
+// LogoSize returns the size of an uploaded watermark logo, failing if it hasn't been uploaded
+func (s *Service) LogoSize(ctx context.Context, key string) (int64, error) {
+	_, size, err := s.GetObjectMetadata(ctx, s.originalBucket, key)
+	return size, err
+}
+
+// OpenLogo opens an uploaded watermark logo for reading. The caller must close the returned body.
+func (s *Service) OpenLogo(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.GetObject(ctx, s.originalBucket, key)
+}
+
+// DeleteLogo deletes a watermark logo
+func (s *Service) DeleteLogo(ctx context.Context, key string) error {
+	return s.DeleteObject(ctx, s.originalBucket, key)
+}
@@ -45,6 +45,7 @@ type UploadURLRequest struct {
 	PhotoID   string
 	FileName  string
 	MimeType  string
+	Size      int64 // when set, S3 rejects uploads whose Content-Length differs
 }
 
 // UploadURLResponse contains the presigned upload URL
@@ -63,6 +64,9 @@ func (s *Service) GenerateUploadURL(ctx context.Context, req UploadURLRequest) (
 		Key:         aws.String(key),
 		ContentType: aws.String(req.MimeType),
 	}
+	if req.Size > 0 {
+		putObjectInput.ContentLength = aws.Int64(req.Size)
+	}
 
 	presignedReq, err := s.presignClient.PresignPutObject(ctx, putObjectInput, func(opts *s3.PresignOptions) {
 		opts.Expires = s.presignExpiration
@@ -147,8 +151,8 @@ func (s *Service) DeleteObject(ctx context.Context, bucket, key string) error {
 	return nil
 }
 
-// DeletePhoto deletes all versions of a photo (original, optimized, thumbnail)
-func (s *Service) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string) error {
+// DeletePhoto deletes all versions of a photo (original, optimized, thumbnail, renditions)
+func (s *Service) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
 	// Delete original
 	if err := s.DeleteObject(ctx, s.originalBucket, originalKey); err != nil {
 		return err
@@ -164,6 +168,16 @@ func (s *Service) DeletePhoto(ctx context.Context, originalKey, optimizedKey, th
 		return err
 	}
 
+	return s.deleteRenditions(ctx, renditionKeys)
+}
+
+// deleteRenditions deletes a photo's renditions, which live in the optimized bucket
+func (s *Service) deleteRenditions(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := s.DeleteObject(ctx, s.optimizedBucket, key); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
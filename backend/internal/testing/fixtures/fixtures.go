@@ -97,6 +97,9 @@ type GalleryOptions struct {
 	EnableWatermark   bool
 	WatermarkText     string
 	WatermarkPosition string
+	DownloadPolicy    string
+	ProofingEnabled   bool
+	SelectionLimit    int
 }
 
 // NewGallery creates a new gallery with sensible defaults.
@@ -152,6 +155,9 @@ func NewGallery(opts ...GalleryOptions) *repository.Gallery {
 		EnableWatermark:   opt.EnableWatermark,
 		WatermarkText:     opt.WatermarkText,
 		WatermarkPosition: opt.WatermarkPosition,
+		DownloadPolicy:    opt.DownloadPolicy,
+		ProofingEnabled:   opt.ProofingEnabled,
+		SelectionLimit:    opt.SelectionLimit,
 	}
 }
 
@@ -321,6 +327,7 @@ func NewPhotoList(count int, galleryID string) []*repository.Photo {
 type FavoriteOptions struct {
 	GalleryID string
 	SessionID string
+	ListID    string
 	PhotoID   string
 }
 
@@ -346,9 +353,15 @@ func NewFavorite(opts ...FavoriteOptions) *repository.Favorite {
 		photoID = GenerateID("photo")
 	}
 
+	listID := opt.ListID
+	if listID == "" {
+		listID = repository.DefaultFavoriteListID
+	}
+
 	return &repository.Favorite{
 		GalleryID:   galleryID,
 		SessionID:   sessionID,
+		ListID:      listID,
 		PhotoID:     photoID,
 		FavoritedAt: time.Now(),
 	}
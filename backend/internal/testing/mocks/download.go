@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// MockDownloadJobRepository is a mock implementation of DownloadJobRepository.
+type MockDownloadJobRepository struct {
+	mu        sync.RWMutex
+	jobs      map[string]*repository.DownloadJob
+	CreateErr error
+}
+
+// NewMockDownloadJobRepository creates a new mock download job repository.
+func NewMockDownloadJobRepository() *MockDownloadJobRepository {
+	return &MockDownloadJobRepository{
+		jobs: make(map[string]*repository.DownloadJob),
+	}
+}
+
+func (m *MockDownloadJobRepository) Create(ctx context.Context, job *repository.DownloadJob) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *job
+	m.jobs[job.JobID] = &stored
+	return nil
+}
+
+func (m *MockDownloadJobRepository) GetByID(ctx context.Context, jobID string) (*repository.DownloadJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return nil, nil
+	}
+	// Return a copy so callers behave like they would against DynamoDB
+	copied := *job
+	return &copied, nil
+}
+
+func (m *MockDownloadJobRepository) Update(ctx context.Context, job *repository.DownloadJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *job
+	m.jobs[job.JobID] = &stored
+	return nil
+}
+
+// AddJob directly adds a job for test setup.
+func (m *MockDownloadJobRepository) AddJob(job *repository.DownloadJob) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.JobID] = job
+}
@@ -0,0 +1,130 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// MockOutboxRepository is a mock implementation of OutboxRepository.
+// Mock entity repositories with an Outbox set record staged events here on
+// successful writes, mirroring the transactional write of the DynamoDB repositories.
+type MockOutboxRepository struct {
+	mu      sync.RWMutex
+	events  map[string]*repository.OutboxEvent
+	ListErr error
+	MarkErr error
+}
+
+// NewMockOutboxRepository creates a new mock outbox repository.
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{
+		events: make(map[string]*repository.OutboxEvent),
+	}
+}
+
+func (m *MockOutboxRepository) ListPending(ctx context.Context, limit int) ([]*repository.OutboxEvent, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.OutboxEvent
+	for _, evt := range m.sorted() {
+		if evt.Status == repository.OutboxStatusPending {
+			copied := *evt
+			result = append(result, &copied)
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, eventID string) error {
+	if m.MarkErr != nil {
+		return m.MarkErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if evt := m.events[eventID]; evt != nil {
+		now := time.Now()
+		evt.Status = repository.OutboxStatusPublished
+		evt.PublishedAt = &now
+		evt.Attempts++
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, eventID, lastError string, permanent bool) error {
+	if m.MarkErr != nil {
+		return m.MarkErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if evt := m.events[eventID]; evt != nil {
+		evt.Attempts++
+		evt.LastError = lastError
+		if permanent {
+			evt.Status = repository.OutboxStatusFailed
+		}
+	}
+	return nil
+}
+
+// AddEvent directly adds an outbox event for test setup.
+func (m *MockOutboxRepository) AddEvent(evt *repository.OutboxEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[evt.EventID] = evt
+}
+
+// Events returns all recorded events in creation order.
+func (m *MockOutboxRepository) Events() []*repository.OutboxEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sorted()
+}
+
+// EventsOfType returns recorded events of the given type in creation order.
+func (m *MockOutboxRepository) EventsOfType(eventType string) []*repository.OutboxEvent {
+	var result []*repository.OutboxEvent
+	for _, evt := range m.Events() {
+		if evt.EventType == eventType {
+			result = append(result, evt)
+		}
+	}
+	return result
+}
+
+// capture records events staged on ctx. It is safe to call on a nil receiver.
+func (m *MockOutboxRepository) capture(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	staged := repository.OutboxEventsFromContext(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, evt := range staged {
+		copied := *evt
+		m.events[evt.EventID] = &copied
+	}
+}
+
+func (m *MockOutboxRepository) sorted() []*repository.OutboxEvent {
+	result := make([]*repository.OutboxEvent, 0, len(m.events))
+	for _, evt := range m.events {
+		result = append(result, evt)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].EventID < result[j].EventID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
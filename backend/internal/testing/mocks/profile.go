@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// MockProcessingProfileRepository is a mock implementation of ProcessingProfileRepository.
+type MockProcessingProfileRepository struct {
+	mu       sync.RWMutex
+	profiles map[string]*repository.ProcessingProfile
+	GetErr   error
+}
+
+// NewMockProcessingProfileRepository creates a new mock processing profile repository.
+func NewMockProcessingProfileRepository() *MockProcessingProfileRepository {
+	return &MockProcessingProfileRepository{
+		profiles: make(map[string]*repository.ProcessingProfile),
+	}
+}
+
+func (m *MockProcessingProfileRepository) Create(ctx context.Context, profile *repository.ProcessingProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *profile
+	m.profiles[profile.ProfileID] = &copied
+	return nil
+}
+
+func (m *MockProcessingProfileRepository) GetByID(ctx context.Context, profileID string) (*repository.ProcessingProfile, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.profiles[profileID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *MockProcessingProfileRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.ProcessingProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.ProcessingProfile
+	for _, p := range m.profiles {
+		if p.PhotographerID == photographerID {
+			copied := *p
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ProfileID < result[j].ProfileID })
+	return result, nil
+}
+
+func (m *MockProcessingProfileRepository) Update(ctx context.Context, profile *repository.ProcessingProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *profile
+	m.profiles[profile.ProfileID] = &copied
+	return nil
+}
+
+func (m *MockProcessingProfileRepository) Delete(ctx context.Context, profileID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.profiles, profileID)
+	return nil
+}
+
+// AddProfile directly adds a processing profile for test setup.
+func (m *MockProcessingProfileRepository) AddProfile(profile *repository.ProcessingProfile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[profile.ProfileID] = profile
+}
@@ -3,9 +3,11 @@ package mocks
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
+	"photographer-gallery/backend/internal/domain/photographer"
 	"photographer-gallery/backend/internal/repository"
 )
 
@@ -20,6 +22,7 @@ type MockGalleryRepository struct {
 	UpdateErr       error
 	DeleteErr       error
 	ListErr         error
+	Outbox          *MockOutboxRepository // receives events staged on write contexts
 }
 
 // NewMockGalleryRepository creates a new mock gallery repository.
@@ -38,6 +41,7 @@ func (m *MockGalleryRepository) Create(ctx context.Context, gallery *repository.
 	defer m.mu.Unlock()
 	m.galleries[gallery.GalleryID] = gallery
 	m.customURLIndex[gallery.CustomURL] = gallery
+	m.Outbox.capture(ctx)
 	return nil
 }
 
@@ -85,6 +89,7 @@ func (m *MockGalleryRepository) Update(ctx context.Context, gallery *repository.
 		m.galleries[gallery.GalleryID] = gallery
 		m.customURLIndex[gallery.CustomURL] = gallery
 	}
+	m.Outbox.capture(ctx)
 	return nil
 }
 
@@ -98,6 +103,7 @@ func (m *MockGalleryRepository) Delete(ctx context.Context, galleryID string) er
 		delete(m.customURLIndex, gallery.CustomURL)
 		delete(m.galleries, galleryID)
 	}
+	m.Outbox.capture(ctx)
 	return nil
 }
 
@@ -117,6 +123,21 @@ func (m *MockGalleryRepository) ListExpired(ctx context.Context, limit int) ([]*
 	return result, nil
 }
 
+func (m *MockGalleryRepository) ListByStatus(ctx context.Context, status string, limit int) ([]*repository.Gallery, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.Gallery
+	for _, g := range m.galleries {
+		if g.Status == status {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockGalleryRepository) UpdatePhotoCount(ctx context.Context, galleryID string, delta int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -161,6 +182,7 @@ type MockPhotoRepository struct {
 	UpdateErr error
 	DeleteErr error
 	ListErr   error
+	Outbox    *MockOutboxRepository // receives events staged on write contexts
 }
 
 // NewMockPhotoRepository creates a new mock photo repository.
@@ -176,7 +198,11 @@ func (m *MockPhotoRepository) Create(ctx context.Context, photo *repository.Phot
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if _, exists := m.photos[photo.PhotoID]; exists {
+		return repository.ErrAlreadyExists
+	}
 	m.photos[photo.PhotoID] = photo
+	m.Outbox.capture(ctx)
 	return nil
 }
 
@@ -204,13 +230,56 @@ func (m *MockPhotoRepository) ListByGallery(ctx context.Context, galleryID strin
 	return result, nil, nil
 }
 
+// ListFailedByPhotographer pages through failed photos, most recent failure first. Keys hold
+// the ID of the last photo returned.
+func (m *MockPhotoRepository) ListFailedByPhotographer(ctx context.Context, photographerID string, limit int, lastKey map[string]interface{}) ([]*repository.Photo, map[string]interface{}, error) {
+	if m.ListErr != nil {
+		return nil, nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var failed []*repository.Photo
+	for _, p := range m.photos {
+		if p.PhotographerID == photographerID && p.IsFailed() {
+			failed = append(failed, p)
+		}
+	}
+	failedAt := func(p *repository.Photo) time.Time {
+		if p.ProcessedAt != nil {
+			return *p.ProcessedAt
+		}
+		return p.UploadedAt
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		if a, b := failedAt(failed[i]), failedAt(failed[j]); !a.Equal(b) {
+			return a.After(b)
+		}
+		return failed[i].PhotoID > failed[j].PhotoID
+	})
+	for i, p := range failed {
+		if lastKey != nil && p.PhotoID == lastKey["photoId"] {
+			failed = failed[i+1:]
+			break
+		}
+	}
+	if limit > 0 && len(failed) > limit {
+		return failed[:limit], map[string]interface{}{"photoId": failed[limit-1].PhotoID}, nil
+	}
+	return failed, nil, nil
+}
+
 func (m *MockPhotoRepository) Update(ctx context.Context, photo *repository.Photo) error {
 	if m.UpdateErr != nil {
 		return m.UpdateErr
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if stored := m.photos[photo.PhotoID]; stored != nil && stored != photo && stored.Version != photo.Version {
+		return repository.ErrConflict
+	}
+	photo.Version++
 	m.photos[photo.PhotoID] = photo
+	m.Outbox.capture(ctx)
 	return nil
 }
 
@@ -252,21 +321,31 @@ func (m *MockPhotoRepository) AddPhoto(photo *repository.Photo) {
 // MockFavoriteRepository is a mock implementation of FavoriteRepository.
 type MockFavoriteRepository struct {
 	mu        sync.RWMutex
-	favorites map[string]*repository.Favorite // key: galleryID:sessionID:photoID
+	favorites map[string]*repository.Favorite     // key: galleryID:sessionID:listID:photoID
+	lists     map[string]*repository.FavoriteList // key: galleryID:sessionID:listID
 	CreateErr error
 	DeleteErr error
 	ListErr   error
+	Outbox    *MockOutboxRepository // receives events staged on write contexts
 }
 
 // NewMockFavoriteRepository creates a new mock favorite repository.
 func NewMockFavoriteRepository() *MockFavoriteRepository {
 	return &MockFavoriteRepository{
 		favorites: make(map[string]*repository.Favorite),
+		lists:     make(map[string]*repository.FavoriteList),
+	}
+}
+
+func (m *MockFavoriteRepository) key(galleryID, sessionID, listID, photoID string) string {
+	if listID == "" {
+		listID = repository.DefaultFavoriteListID
 	}
+	return galleryID + ":" + sessionID + ":" + listID + ":" + photoID
 }
 
-func (m *MockFavoriteRepository) key(galleryID, sessionID, photoID string) string {
-	return galleryID + ":" + sessionID + ":" + photoID
+func (m *MockFavoriteRepository) listKey(galleryID, sessionID, listID string) string {
+	return galleryID + ":" + sessionID + ":" + listID
 }
 
 func (m *MockFavoriteRepository) Create(ctx context.Context, favorite *repository.Favorite) error {
@@ -275,26 +354,31 @@ func (m *MockFavoriteRepository) Create(ctx context.Context, favorite *repositor
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	key := m.key(favorite.GalleryID, favorite.SessionID, favorite.PhotoID)
+	if favorite.ListID == "" {
+		favorite.ListID = repository.DefaultFavoriteListID
+	}
+	key := m.key(favorite.GalleryID, favorite.SessionID, favorite.ListID, favorite.PhotoID)
 	m.favorites[key] = favorite
+	m.Outbox.capture(ctx)
 	return nil
 }
 
-func (m *MockFavoriteRepository) Delete(ctx context.Context, galleryID, sessionID, photoID string) error {
+func (m *MockFavoriteRepository) Delete(ctx context.Context, galleryID, sessionID, listID, photoID string) error {
 	if m.DeleteErr != nil {
 		return m.DeleteErr
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	key := m.key(galleryID, sessionID, photoID)
+	key := m.key(galleryID, sessionID, listID, photoID)
 	delete(m.favorites, key)
+	m.Outbox.capture(ctx)
 	return nil
 }
 
-func (m *MockFavoriteRepository) IsFavorited(ctx context.Context, galleryID, sessionID, photoID string) (bool, error) {
+func (m *MockFavoriteRepository) IsFavorited(ctx context.Context, galleryID, sessionID, listID, photoID string) (bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	key := m.key(galleryID, sessionID, photoID)
+	key := m.key(galleryID, sessionID, listID, photoID)
 	_, ok := m.favorites[key]
 	return ok, nil
 }
@@ -314,6 +398,21 @@ func (m *MockFavoriteRepository) ListBySession(ctx context.Context, galleryID, s
 	return result, nil
 }
 
+func (m *MockFavoriteRepository) ListByList(ctx context.Context, galleryID, sessionID, listID string) ([]*repository.Favorite, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.Favorite
+	for _, f := range m.favorites {
+		if f.GalleryID == galleryID && f.SessionID == sessionID && f.ListID == listID {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockFavoriteRepository) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Favorite, error) {
 	if m.ListErr != nil {
 		return nil, m.ListErr
@@ -329,6 +428,219 @@ func (m *MockFavoriteRepository) ListByGallery(ctx context.Context, galleryID st
 	return result, nil
 }
 
+func (m *MockFavoriteRepository) CreateList(ctx context.Context, list *repository.FavoriteList) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	return m.UpdateList(ctx, list)
+}
+
+func (m *MockFavoriteRepository) GetList(ctx context.Context, galleryID, sessionID, listID string) (*repository.FavoriteList, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list, ok := m.lists[m.listKey(galleryID, sessionID, listID)]
+	if !ok {
+		return nil, nil
+	}
+	copied := *list
+	return &copied, nil
+}
+
+func (m *MockFavoriteRepository) UpdateList(ctx context.Context, list *repository.FavoriteList) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *list
+	m.lists[m.listKey(list.GalleryID, list.SessionID, list.ListID)] = &copied
+	return nil
+}
+
+func (m *MockFavoriteRepository) DeleteList(ctx context.Context, galleryID, sessionID, listID string) error {
+	if m.DeleteErr != nil {
+		return m.DeleteErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.lists, m.listKey(galleryID, sessionID, listID))
+	for key, f := range m.favorites {
+		if f.GalleryID == galleryID && f.SessionID == sessionID && f.ListID == listID {
+			delete(m.favorites, key)
+		}
+	}
+	return nil
+}
+
+func (m *MockFavoriteRepository) ListLists(ctx context.Context, galleryID, sessionID string) ([]*repository.FavoriteList, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.FavoriteList
+	for _, list := range m.lists {
+		if list.GalleryID == galleryID && list.SessionID == sessionID {
+			copied := *list
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockFavoriteRepository) ListListsByGallery(ctx context.Context, galleryID string) ([]*repository.FavoriteList, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.FavoriteList
+	for _, list := range m.lists {
+		if list.GalleryID == galleryID {
+			copied := *list
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// MockSelectionRepository is a mock implementation of SelectionRepository.
+type MockSelectionRepository struct {
+	mu         sync.RWMutex
+	selections map[string]*repository.Selection // key: galleryID:sessionID
+	GetErr     error
+	UpdateErr  error
+	Outbox     *MockOutboxRepository // receives events staged on write contexts
+}
+
+// NewMockSelectionRepository creates a new mock selection repository.
+func NewMockSelectionRepository() *MockSelectionRepository {
+	return &MockSelectionRepository{
+		selections: make(map[string]*repository.Selection),
+	}
+}
+
+func (m *MockSelectionRepository) key(galleryID, sessionID string) string {
+	return galleryID + ":" + sessionID
+}
+
+func (m *MockSelectionRepository) Create(ctx context.Context, selection *repository.Selection) error {
+	return m.Update(ctx, selection)
+}
+
+func (m *MockSelectionRepository) GetByID(ctx context.Context, galleryID, sessionID string) (*repository.Selection, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	selection, ok := m.selections[m.key(galleryID, sessionID)]
+	if !ok {
+		return nil, nil
+	}
+	copied := *selection
+	return &copied, nil
+}
+
+func (m *MockSelectionRepository) Update(ctx context.Context, selection *repository.Selection) error {
+	if m.UpdateErr != nil {
+		return m.UpdateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *selection
+	m.selections[m.key(selection.GalleryID, selection.SessionID)] = &copied
+	m.Outbox.capture(ctx)
+	return nil
+}
+
+func (m *MockSelectionRepository) ListByGallery(ctx context.Context, galleryID string) ([]*repository.Selection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.Selection
+	for _, selection := range m.selections {
+		if selection.GalleryID == galleryID {
+			copied := *selection
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+// MockTrashRepository is a mock implementation of TrashRepository.
+type MockTrashRepository struct {
+	mu        sync.RWMutex
+	items     map[string]*repository.TrashItem // key: photographerID:itemID
+	CreateErr error
+	DeleteErr error
+}
+
+// NewMockTrashRepository creates a new mock trash repository.
+func NewMockTrashRepository() *MockTrashRepository {
+	return &MockTrashRepository{
+		items: make(map[string]*repository.TrashItem),
+	}
+}
+
+func (m *MockTrashRepository) key(photographerID, itemID string) string {
+	return photographerID + ":" + itemID
+}
+
+func (m *MockTrashRepository) Create(ctx context.Context, item *repository.TrashItem) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *item
+	m.items[m.key(item.PhotographerID, item.ItemID)] = &copied
+	return nil
+}
+
+func (m *MockTrashRepository) GetByID(ctx context.Context, photographerID, itemID string) (*repository.TrashItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	item, ok := m.items[m.key(photographerID, itemID)]
+	if !ok {
+		return nil, nil
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (m *MockTrashRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.TrashItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.TrashItem
+	for _, item := range m.items {
+		if item.PhotographerID == photographerID {
+			copied := *item
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockTrashRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*repository.TrashItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.TrashItem
+	for _, item := range m.items {
+		if !item.PurgeAt.After(before) && len(result) < limit {
+			copied := *item
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockTrashRepository) Delete(ctx context.Context, photographerID, itemID string) error {
+	if m.DeleteErr != nil {
+		return m.DeleteErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, m.key(photographerID, itemID))
+	return nil
+}
+
 // MockClientSessionRepository is a mock implementation of ClientSessionRepository.
 type MockClientSessionRepository struct {
 	mu        sync.RWMutex
@@ -474,6 +786,20 @@ func (m *MockPhotographerRepository) UpdateStorageUsed(ctx context.Context, user
 	return nil
 }
 
+func (m *MockPhotographerStore) SetWatermarkLogo(ctx context.Context, userID, key string) error {
+	if m.UpdateErr != nil {
+		return m.UpdateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.photographers[userID]
+	if !ok {
+		return photographer.ErrNotFound
+	}
+	p.WatermarkLogoKey = key
+	return nil
+}
+
 // AddPhotographer directly adds a photographer for test setup.
 func (m *MockPhotographerRepository) AddPhotographer(photographer *repository.Photographer) {
 	m.mu.Lock()
@@ -481,3 +807,93 @@ func (m *MockPhotographerRepository) AddPhotographer(photographer *repository.Ph
 	m.photographers[photographer.UserID] = photographer
 	m.emailIndex[photographer.Email] = photographer
 }
+
+// MockPhotographerStore is a mock of the DynamoDB photographer repository, which works with
+// photographer.Photographer rather than repository.Photographer.
+type MockPhotographerStore struct {
+	mu            sync.RWMutex
+	photographers map[string]*photographer.Photographer
+	GetErr        error
+	UpdateErr     error
+}
+
+// NewMockPhotographerStore creates a new mock photographer store.
+func NewMockPhotographerStore() *MockPhotographerStore {
+	return &MockPhotographerStore{
+		photographers: make(map[string]*photographer.Photographer),
+	}
+}
+
+func (m *MockPhotographerStore) GetByID(ctx context.Context, userID string) (*photographer.Photographer, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.photographers[userID]
+	if !ok {
+		return nil, photographer.ErrNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (m *MockPhotographerStore) UpdateStorageUsed(ctx context.Context, userID string, deltaBytes int64) error {
+	if m.UpdateErr != nil {
+		return m.UpdateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.photographers[userID]
+	if !ok {
+		return photographer.ErrNotFound
+	}
+	p.StorageUsed += deltaBytes
+	return nil
+}
+
+func (m *MockPhotographerStore) SetStorageUsed(ctx context.Context, userID string, previous, bytes int64) error {
+	if m.UpdateErr != nil {
+		return m.UpdateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.photographers[userID]
+	if !ok {
+		return photographer.ErrNotFound
+	}
+	if p.StorageUsed != previous {
+		return repository.ErrConflict
+	}
+	p.StorageUsed = bytes
+	return nil
+}
+
+// List returns every photographer in a single page.
+func (m *MockPhotographerStore) List(ctx context.Context, limit int, lastKey map[string]interface{}) ([]*photographer.Photographer, map[string]interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*photographer.Photographer
+	for _, p := range m.photographers {
+		copied := *p
+		result = append(result, &copied)
+	}
+	return result, nil, nil
+}
+
+// AddPhotographer directly adds a photographer for test setup.
+func (m *MockPhotographerStore) AddPhotographer(p *photographer.Photographer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.photographers[p.UserID] = p
+}
+
+// StorageUsed returns the photographer's recorded storage usage.
+func (m *MockPhotographerStore) StorageUsed(userID string) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.photographers[userID]; ok {
+		return p.StorageUsed
+	}
+	return 0
+}
@@ -14,7 +14,12 @@ type MockStorageService struct {
 	mu             sync.RWMutex
 	objects        map[string][]byte
 	deletedObjects []string
+	archived       []string
+	restoreStarted map[string]bool
 	DeletePhotoErr error
+	ArchiveErr     error
+	RestoreErr     error
+	RestoresReady  bool // whether started restores have completed
 	UploadErr      error
 	DownloadErr    error
 	GenerateURLErr error
@@ -25,23 +30,68 @@ func NewMockStorageService() *MockStorageService {
 	return &MockStorageService{
 		objects:        make(map[string][]byte),
 		deletedObjects: make([]string, 0),
+		restoreStarted: make(map[string]bool),
 	}
 }
 
 // DeletePhoto mocks deleting a photo from S3.
-func (m *MockStorageService) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string) error {
+func (m *MockStorageService) DeletePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
 	if m.DeletePhotoErr != nil {
 		return m.DeletePhotoErr
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.deletedObjects = append(m.deletedObjects, originalKey, optimizedKey, thumbnailKey)
-	delete(m.objects, originalKey)
-	delete(m.objects, optimizedKey)
-	delete(m.objects, thumbnailKey)
+	m.deletedObjects = append(m.deletedObjects, renditionKeys...)
+	for _, key := range append([]string{originalKey, optimizedKey, thumbnailKey}, renditionKeys...) {
+		delete(m.objects, key)
+	}
 	return nil
 }
 
+// ArchivePhoto mocks moving an original to cold storage and deleting its derivatives.
+func (m *MockStorageService) ArchivePhoto(ctx context.Context, originalKey, optimizedKey, thumbnailKey string, renditionKeys ...string) error {
+	if m.ArchiveErr != nil {
+		return m.ArchiveErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archived = append(m.archived, originalKey)
+	m.deletedObjects = append(m.deletedObjects, optimizedKey, thumbnailKey)
+	m.deletedObjects = append(m.deletedObjects, renditionKeys...)
+	for _, key := range append([]string{optimizedKey, thumbnailKey}, renditionKeys...) {
+		delete(m.objects, key)
+	}
+	return nil
+}
+
+// RestoreOriginal mocks rehydrating an archived original. Restores complete once RestoresReady is set.
+func (m *MockStorageService) RestoreOriginal(ctx context.Context, originalKey string) (bool, error) {
+	if m.RestoreErr != nil {
+		return false, m.RestoreErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restoreStarted[originalKey] = true
+	return m.RestoresReady, nil
+}
+
+// GetArchivedOriginals returns the keys of originals moved to cold storage.
+func (m *MockStorageService) GetArchivedOriginals() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]string, len(m.archived))
+	copy(result, m.archived)
+	return result
+}
+
+// RestoreStarted reports whether a restore was requested for the original.
+func (m *MockStorageService) RestoreStarted(originalKey string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.restoreStarted[originalKey]
+}
+
 // Upload mocks uploading an object to S3.
 func (m *MockStorageService) Upload(ctx context.Context, bucket, key string, body io.Reader, contentType string) error {
 	if m.UploadErr != nil {
@@ -102,6 +152,42 @@ func (m *MockStorageService) ResetDeleted() {
 	m.deletedObjects = make([]string, 0)
 }
 
+// LogoBucket is the bucket the mock stores watermark logos in.
+const LogoBucket = "originals"
+
+// GenerateLogoUploadURL mocks presigning a watermark logo upload.
+func (m *MockStorageService) GenerateLogoUploadURL(ctx context.Context, key, mimeType string) (string, error) {
+	if m.GenerateURLErr != nil {
+		return "", m.GenerateURLErr
+	}
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s?signature=mock", LogoBucket, key), nil
+}
+
+// LogoSize mocks reading the size of an uploaded watermark logo.
+func (m *MockStorageService) LogoSize(ctx context.Context, key string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.objects[LogoBucket+"/"+key]
+	if !ok {
+		return 0, fmt.Errorf("object not found: %s/%s", LogoBucket, key)
+	}
+	return int64(len(data)), nil
+}
+
+// OpenLogo mocks opening an uploaded watermark logo.
+func (m *MockStorageService) OpenLogo(ctx context.Context, key string) (io.ReadCloser, error) {
+	return m.Download(ctx, LogoBucket, key)
+}
+
+// DeleteLogo mocks deleting a watermark logo.
+func (m *MockStorageService) DeleteLogo(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletedObjects = append(m.deletedObjects, key)
+	delete(m.objects, LogoBucket+"/"+key)
+	return nil
+}
+
 // MockSQSClient is a mock implementation for SQS operations.
 type MockSQSClient struct {
 	mu           sync.RWMutex
@@ -239,3 +325,62 @@ func (m *MockImageProcessor) ResetProcessCount() {
 	defer m.mu.Unlock()
 	m.processCount = 0
 }
+
+// MockProcessingQueue is a mock implementation of the photo processing queue.
+type MockProcessingQueue struct {
+	mu         sync.RWMutex
+	enqueued   []string
+	jobs       []GalleryJob
+	EnqueueErr error
+}
+
+// GalleryJob is a gallery reprocessing job sent to a MockProcessingQueue.
+type GalleryJob struct {
+	GalleryID string
+	Cursor    map[string]interface{}
+}
+
+// NewMockProcessingQueue creates a new mock processing queue.
+func NewMockProcessingQueue() *MockProcessingQueue {
+	return &MockProcessingQueue{}
+}
+
+// Enqueue mocks queueing an original for processing.
+func (m *MockProcessingQueue) Enqueue(ctx context.Context, originalKey string) error {
+	if m.EnqueueErr != nil {
+		return m.EnqueueErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enqueued = append(m.enqueued, originalKey)
+	return nil
+}
+
+// EnqueueGallery mocks queueing a page of a gallery for reprocessing.
+func (m *MockProcessingQueue) EnqueueGallery(ctx context.Context, galleryID string, cursor map[string]interface{}) error {
+	if m.EnqueueErr != nil {
+		return m.EnqueueErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs = append(m.jobs, GalleryJob{GalleryID: galleryID, Cursor: cursor})
+	return nil
+}
+
+// GalleryJobs returns the gallery reprocessing jobs queued, oldest first.
+func (m *MockProcessingQueue) GalleryJobs() []GalleryJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]GalleryJob, len(m.jobs))
+	copy(result, m.jobs)
+	return result
+}
+
+// Enqueued returns the originals queued for processing.
+func (m *MockProcessingQueue) Enqueued() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]string, len(m.enqueued))
+	copy(result, m.enqueued)
+	return result
+}
@@ -0,0 +1,177 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"photographer-gallery/backend/internal/repository"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository.
+type MockWebhookRepository struct {
+	mu       sync.RWMutex
+	webhooks map[string]*repository.Webhook
+	ListErr  error
+}
+
+// NewMockWebhookRepository creates a new mock webhook repository.
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		webhooks: make(map[string]*repository.Webhook),
+	}
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *repository.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks[webhook.WebhookID] = webhook
+	return nil
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, webhookID string) (*repository.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.webhooks[webhookID], nil
+}
+
+func (m *MockWebhookRepository) ListByPhotographer(ctx context.Context, photographerID string) ([]*repository.Webhook, error) {
+	if m.ListErr != nil {
+		return nil, m.ListErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*repository.Webhook
+	for _, w := range m.webhooks {
+		if w.PhotographerID == photographerID {
+			result = append(result, w)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].WebhookID < result[j].WebhookID })
+	return result, nil
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *repository.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks[webhook.WebhookID] = webhook
+	return nil
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, webhookID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.webhooks, webhookID)
+	return nil
+}
+
+// AddWebhook directly adds a webhook for test setup.
+func (m *MockWebhookRepository) AddWebhook(webhook *repository.Webhook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks[webhook.WebhookID] = webhook
+}
+
+// MockWebhookDeliveryRepository is a mock implementation of WebhookDeliveryRepository.
+type MockWebhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[string]*repository.WebhookDelivery
+	CreateErr  error
+}
+
+// NewMockWebhookDeliveryRepository creates a new mock webhook delivery repository.
+func NewMockWebhookDeliveryRepository() *MockWebhookDeliveryRepository {
+	return &MockWebhookDeliveryRepository{
+		deliveries: make(map[string]*repository.WebhookDelivery),
+	}
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *repository.WebhookDelivery) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.deliveries[delivery.DeliveryID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	copied := *delivery
+	m.deliveries[delivery.DeliveryID] = &copied
+	return nil
+}
+
+func (m *MockWebhookDeliveryRepository) GetByID(ctx context.Context, deliveryID string) (*repository.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if d, ok := m.deliveries[deliveryID]; ok {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *repository.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *delivery
+	m.deliveries[delivery.DeliveryID] = &copied
+	return nil
+}
+
+func (m *MockWebhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID string, limit int, lastKey map[string]interface{}) ([]*repository.WebhookDelivery, map[string]interface{}, error) {
+	var result []*repository.WebhookDelivery
+	for _, d := range m.Deliveries() {
+		if d.WebhookID == webhookID {
+			result = append(result, d)
+		}
+	}
+	// Most recent first
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil, nil
+}
+
+func (m *MockWebhookDeliveryRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*repository.WebhookDelivery, error) {
+	var result []*repository.WebhookDelivery
+	for _, d := range m.Deliveries() {
+		if d.Status == "pending" && d.NextAttemptAt != nil && !d.NextAttemptAt.After(before) {
+			result = append(result, d)
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Deliveries returns copies of all recorded deliveries in creation order.
+func (m *MockWebhookDeliveryRepository) Deliveries() []*repository.WebhookDelivery {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*repository.WebhookDelivery, 0, len(m.deliveries))
+	for _, d := range m.deliveries {
+		copied := *d
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].DeliveryID < result[j].DeliveryID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// SetNextAttempt moves a delivery's next attempt time, e.g. to make a retry due.
+func (m *MockWebhookDeliveryRepository) SetNextAttempt(deliveryID string, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deliveries[deliveryID]; ok {
+		d.NextAttemptAt = &at
+	}
+}
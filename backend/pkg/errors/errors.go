@@ -7,11 +7,19 @@ import (
 
 // AppError represents an application error with HTTP status code
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Type    string                 `json:"type,omitempty"`    // machine-readable kind for errors clients act on
+	Details map[string]interface{} `json:"details,omitempty"` // structured context for Type
+	Err     error                  `json:"-"`
 }
 
+// Error types
+const (
+	// TypePlanLimit marks actions the photographer's plan doesn't include; clients offer an upgrade
+	TypePlanLimit = "plan_limit"
+)
+
 func (e *AppError) Error() string {
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %v", e.Message, e.Err)
@@ -19,6 +27,11 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the wrapped error, so errors.Is and errors.As see through AppErrors
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
 // New creates a new AppError
 func New(code int, message string) *AppError {
 	return &AppError{
@@ -71,3 +84,23 @@ func NewInternalServer(message string) *AppError {
 func NewConflict(message string) *AppError {
 	return New(http.StatusConflict, message)
 }
+
+// NewPlanLimit creates a forbidden error for an action the photographer's plan doesn't include.
+// Details name the entitlement and the current plan so clients can prompt an upgrade.
+func NewPlanLimit(entitlement, plan, message string) *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Message: message,
+		Type:    TypePlanLimit,
+		Details: map[string]interface{}{
+			"entitlement": entitlement,
+			"plan":        plan,
+		},
+	}
+}
+
+// IsPlanLimit reports whether err is a plan-limit error
+func IsPlanLimit(err error) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Type == TypePlanLimit
+}
@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewPlanLimitJSON(t *testing.T) {
+	err := NewPlanLimit("custom_domain", "free", "Custom domains are not included in your plan")
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("Marshal() error: %v", marshalErr)
+	}
+
+	var body struct {
+		Code    int               `json:"code"`
+		Message string            `json:"message"`
+		Type    string            `json:"type"`
+		Details map[string]string `json:"details"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if body.Code != http.StatusForbidden || body.Type != TypePlanLimit {
+		t.Errorf("code/type = %d/%q, want %d/%q", body.Code, body.Type, http.StatusForbidden, TypePlanLimit)
+	}
+	if body.Details["entitlement"] != "custom_domain" || body.Details["plan"] != "free" {
+		t.Errorf("details = %v", body.Details)
+	}
+}
+
+func TestPlainErrorsOmitTypeAndDetails(t *testing.T) {
+	data, _ := json.Marshal(NewBadRequest("Invalid"))
+	if got, want := string(data), `{"code":400,"message":"Invalid"}`; got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestIsPlanLimit(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{NewPlanLimit("watermark", "free", "no"), true},
+		{New(http.StatusForbidden, "Forbidden"), false},
+		{fmt.Errorf("plain"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsPlanLimit(tt.err); got != tt.want {
+			t.Errorf("IsPlanLimit(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
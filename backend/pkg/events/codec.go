@@ -0,0 +1,50 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EncodePayload serializes an event payload for persistence.
+func EncodePayload(event Event) ([]byte, error) {
+	data, err := json.Marshal(event.Payload())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", event.Type(), err)
+	}
+	return data, nil
+}
+
+// DecodePayload deserializes a persisted payload into its typed struct.
+// Unknown event types are decoded into a generic map.
+func DecodePayload(eventType EventType, data []byte) (interface{}, error) {
+	var payload interface{}
+	switch eventType {
+	case PhotoUploaded:
+		payload = &PhotoUploadedPayload{}
+	case PhotoProcessed:
+		payload = &PhotoProcessedPayload{}
+	case GalleryCreated:
+		payload = &GalleryCreatedPayload{}
+	case GalleryDeleted:
+		payload = &GalleryDeletedPayload{}
+	case GalleryExpired:
+		payload = &GalleryExpiredPayload{}
+	case GalleryRestored:
+		payload = &GalleryRestoredPayload{}
+	case FavoriteToggled:
+		payload = &FavoriteToggledPayload{}
+	case SelectionSubmitted:
+		payload = &SelectionSubmittedPayload{}
+	default:
+		generic := map[string]interface{}{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to decode %s payload: %w", eventType, err)
+		}
+		return generic, nil
+	}
+
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", eventType, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,50 @@
+package events
+
+import "testing"
+
+func TestPayloadRoundTrip(t *testing.T) {
+	original := NewEvent(FavoriteToggled, &FavoriteToggledPayload{
+		PhotoID:   "photo_123",
+		GalleryID: "gal_456",
+		ClientID:  "session_789",
+		Favorited: true,
+	}, "gal_456")
+
+	data, err := EncodePayload(original)
+	if err != nil {
+		t.Fatalf("EncodePayload() error: %v", err)
+	}
+
+	decoded, err := DecodePayload(FavoriteToggled, data)
+	if err != nil {
+		t.Fatalf("DecodePayload() error: %v", err)
+	}
+
+	restored := RestoreEvent(original.ID(), original.Type(), decoded, original.Timestamp(), original.CorrelationID())
+	if restored.ID() != original.ID() {
+		t.Errorf("ID() = %v, want %v", restored.ID(), original.ID())
+	}
+
+	p, ok := restored.Payload().(*FavoriteToggledPayload)
+	if !ok {
+		t.Fatalf("Payload type = %T, want *FavoriteToggledPayload", restored.Payload())
+	}
+	if *p != *original.Payload().(*FavoriteToggledPayload) {
+		t.Errorf("Payload = %+v, want %+v", p, original.Payload())
+	}
+}
+
+func TestDecodePayloadUnknownType(t *testing.T) {
+	decoded, err := DecodePayload(EventType("custom.event"), []byte(`{"key":"value"}`))
+	if err != nil {
+		t.Fatalf("DecodePayload() error: %v", err)
+	}
+	m, ok := decoded.(map[string]interface{})
+	if !ok || m["key"] != "value" {
+		t.Errorf("DecodePayload() = %v, want map with key=value", decoded)
+	}
+
+	if _, err := DecodePayload(PhotoUploaded, []byte("{bad")); err == nil {
+		t.Error("DecodePayload() expected error for malformed payload")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"photographer-gallery/backend/pkg/logger"
+	"photographer-gallery/backend/pkg/utils"
 )
 
 // EventType represents the type of event.
@@ -14,17 +15,21 @@ type EventType string
 
 // Event types
 const (
-	PhotoUploaded   EventType = "photo.uploaded"
-	PhotoProcessed  EventType = "photo.processed"
-	PhotoDeleted    EventType = "photo.deleted"
-	GalleryCreated  EventType = "gallery.created"
-	GalleryUpdated  EventType = "gallery.updated"
-	GalleryDeleted  EventType = "gallery.deleted"
-	FavoriteToggled EventType = "favorite.toggled"
+	PhotoUploaded      EventType = "photo.uploaded"
+	PhotoProcessed     EventType = "photo.processed"
+	PhotoDeleted       EventType = "photo.deleted"
+	GalleryCreated     EventType = "gallery.created"
+	GalleryUpdated     EventType = "gallery.updated"
+	GalleryDeleted     EventType = "gallery.deleted"
+	GalleryExpired     EventType = "gallery.expired"
+	GalleryRestored    EventType = "gallery.restored"
+	FavoriteToggled    EventType = "favorite.toggled"
+	SelectionSubmitted EventType = "selection.submitted"
 )
 
 // Event represents an event in the system.
 type Event interface {
+	ID() string
 	Type() EventType
 	Payload() interface{}
 	Timestamp() time.Time
@@ -33,6 +38,7 @@ type Event interface {
 
 // BaseEvent provides a base implementation of Event.
 type BaseEvent struct {
+	id            string
 	eventType     EventType
 	payload       interface{}
 	timestamp     time.Time
@@ -42,6 +48,7 @@ type BaseEvent struct {
 // NewEvent creates a new event.
 func NewEvent(eventType EventType, payload interface{}, correlationID string) Event {
 	return &BaseEvent{
+		id:            utils.GenerateID("evt"),
 		eventType:     eventType,
 		payload:       payload,
 		timestamp:     time.Now(),
@@ -49,6 +56,20 @@ func NewEvent(eventType EventType, payload interface{}, correlationID string) Ev
 	}
 }
 
+// RestoreEvent rebuilds a previously persisted event, keeping its original ID and timestamp.
+func RestoreEvent(id string, eventType EventType, payload interface{}, timestamp time.Time, correlationID string) Event {
+	return &BaseEvent{
+		id:            id,
+		eventType:     eventType,
+		payload:       payload,
+		timestamp:     timestamp,
+		correlationID: correlationID,
+	}
+}
+
+// ID returns the unique event ID, stable across redeliveries.
+func (e *BaseEvent) ID() string { return e.id }
+
 // Type returns the event type.
 func (e *BaseEvent) Type() EventType { return e.eventType }
 
@@ -163,38 +184,64 @@ func (bus *InMemoryEventBus) PublishAsync(ctx context.Context, event Event) {
 
 // PhotoUploadedPayload contains data for photo upload events.
 type PhotoUploadedPayload struct {
-	PhotoID   string
-	GalleryID string
-	FileName  string
-	Size      int64
+	PhotoID   string `json:"photoId"`
+	GalleryID string `json:"galleryId"`
+	FileName  string `json:"fileName"`
+	Size      int64  `json:"size"`
 }
 
 // PhotoProcessedPayload contains data for photo processed events.
 type PhotoProcessedPayload struct {
-	PhotoID   string
-	GalleryID string
-	Status    string
-	Width     int
-	Height    int
+	PhotoID   string `json:"photoId"`
+	GalleryID string `json:"galleryId"`
+	Status    string `json:"status"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
 }
 
 // GalleryCreatedPayload contains data for gallery created events.
 type GalleryCreatedPayload struct {
-	GalleryID      string
-	PhotographerID string
-	Name           string
+	GalleryID      string `json:"galleryId"`
+	PhotographerID string `json:"photographerId"`
+	Name           string `json:"name"`
 }
 
 // GalleryDeletedPayload contains data for gallery deleted events.
 type GalleryDeletedPayload struct {
-	GalleryID      string
-	PhotographerID string
-	PhotoCount     int
+	GalleryID      string `json:"galleryId"`
+	PhotographerID string `json:"photographerId"`
+	PhotoCount     int    `json:"photoCount"`
+}
+
+// GalleryExpiredPayload contains data for gallery expired events.
+type GalleryExpiredPayload struct {
+	GalleryID      string    `json:"galleryId"`
+	PhotographerID string    `json:"photographerId"`
+	Name           string    `json:"name"`
+	ExpiredAt      time.Time `json:"expiredAt"`
+}
+
+// GalleryRestoredPayload contains data for events sent when an archived gallery is available again.
+type GalleryRestoredPayload struct {
+	GalleryID      string    `json:"galleryId"`
+	PhotographerID string    `json:"photographerId"`
+	PhotoCount     int       `json:"photoCount"`
+	RestoredAt     time.Time `json:"restoredAt"`
 }
 
 // FavoriteToggledPayload contains data for favorite toggle events.
 type FavoriteToggledPayload struct {
-	PhotoID   string
-	ClientID  string
-	Favorited bool
+	PhotoID   string `json:"photoId"`
+	GalleryID string `json:"galleryId"`
+	ClientID  string `json:"clientId"`
+	ListID    string `json:"listId,omitempty"`
+	Favorited bool   `json:"favorited"`
+}
+
+// SelectionSubmittedPayload contains data for proofing selection submitted events.
+type SelectionSubmittedPayload struct {
+	GalleryID   string    `json:"galleryId"`
+	ClientID    string    `json:"clientId"`
+	PhotoCount  int       `json:"photoCount"`
+	SubmittedAt time.Time `json:"submittedAt"`
 }
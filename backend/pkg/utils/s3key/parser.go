@@ -74,6 +74,11 @@ func BuildWithVariant(galleryID, photoID, variant, fileName string) string {
 	return fmt.Sprintf("%s/%s/%s/%s", galleryID, photoID, variant, fileName)
 }
 
+// RenditionKey returns the key of a named rendition of a photo, stored alongside its optimized version.
+func RenditionKey(galleryID, photoID, name, ext string) string {
+	return BuildWithVariant(galleryID, photoID, "renditions", name+ext)
+}
+
 // ChangeExtension changes the file extension of an S3 key.
 func ChangeExtension(key, newExt string) string {
 	lastDot := strings.LastIndex(key, ".")
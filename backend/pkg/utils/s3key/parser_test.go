@@ -171,6 +171,14 @@ func TestBuildWithVariant(t *testing.T) {
 	}
 }
 
+func TestRenditionKey(t *testing.T) {
+	got := RenditionKey("gal_123", "photo_456", "md", ".jpg")
+	want := "gal_123/photo_456/renditions/md.jpg"
+	if got != want {
+		t.Errorf("RenditionKey() = %v, want %v", got, want)
+	}
+}
+
 func TestChangeExtension(t *testing.T) {
 	tests := []struct {
 		name   string
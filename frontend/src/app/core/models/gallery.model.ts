@@ -1,3 +1,40 @@
+export type WatermarkFont = 'sans' | 'sans-bold' | 'sans-italic' | 'mono';
+export type WatermarkMode = 'single' | 'diagonal' | 'tiled';
+export type WatermarkType = 'text' | 'logo';
+export type ThumbnailCrop = 'center' | 'smart';
+export type ProcessingStepType = 'resize' | 'sharpen' | 'blur' | 'grayscale' | 'watermark';
+
+export interface ProcessingStep {
+  type: ProcessingStepType;
+  width?: number; // resize: largest width in px
+  height?: number; // resize: largest height in px
+  sigma?: number; // sharpen and blur: strength, up to 20
+}
+
+// A photographer's stored recipe for optimized photos, shared by the galleries that use it;
+// watermark steps draw each gallery's own watermark
+export interface ProcessingProfile {
+  profileId: string;
+  photographerId: string;
+  name: string;
+  steps?: ProcessingStep[];
+  format?: 'jpeg' | 'png' | 'webp'; // default jpeg
+  quality?: number; // 1-100, default 85
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface SaveProcessingProfileRequest {
+  name: string;
+  steps?: ProcessingStep[];
+  format?: 'jpeg' | 'png' | 'webp';
+  quality?: number;
+}
+
+export interface ProcessingProfileUpdateResult extends ProcessingProfile {
+  regenerating: number; // galleries using the profile whose photos are queued to be processed again
+}
+
 export interface Gallery {
   galleryId: string;
   photographerId: string;
@@ -12,8 +49,19 @@ export interface Gallery {
   totalSize: number;
   clientAccessCount: number;
   enableWatermark: boolean;
+  watermarkType?: WatermarkType; // logo uses the photographer's uploaded logo
   watermarkText?: string;
   watermarkPosition?: 'bottom-right' | 'bottom-left' | 'center';
+  watermarkFont?: WatermarkFont;
+  watermarkSize?: number; // percent of image width
+  watermarkColor?: string; // #rrggbb
+  watermarkOpacity?: number; // 0-1
+  watermarkMargin?: number; // percent of image width
+  watermarkMode?: WatermarkMode;
+  watermarkRotation?: number; // degrees counter-clockwise
+  watermarkScale?: number; // logo width, percent of image width
+  thumbnailCrop?: ThumbnailCrop; // smart crops around the most detailed part of each photo
+  processingProfileId?: string;
 }
 
 export interface CreateGalleryRequest {
@@ -23,8 +71,19 @@ export interface CreateGalleryRequest {
   password: string;
   expiresAt?: string;
   enableWatermark?: boolean;
+  watermarkType?: WatermarkType; // logo uses the photographer's uploaded logo
   watermarkText?: string;
   watermarkPosition?: 'bottom-right' | 'bottom-left' | 'center';
+  watermarkFont?: WatermarkFont;
+  watermarkSize?: number; // percent of image width
+  watermarkColor?: string; // #rrggbb
+  watermarkOpacity?: number; // 0-1
+  watermarkMargin?: number; // percent of image width
+  watermarkMode?: WatermarkMode;
+  watermarkRotation?: number; // degrees counter-clockwise
+  watermarkScale?: number; // logo width, percent of image width
+  thumbnailCrop?: ThumbnailCrop; // smart crops around the most detailed part of each photo
+  processingProfileId?: string;
 }
 
 export interface UpdateGalleryRequest {
@@ -33,6 +92,38 @@ export interface UpdateGalleryRequest {
   password?: string;
   expiresAt?: string;
   enableWatermark?: boolean;
+  watermarkType?: WatermarkType; // logo uses the photographer's uploaded logo
   watermarkText?: string;
   watermarkPosition?: 'bottom-right' | 'bottom-left' | 'center';
+  watermarkFont?: WatermarkFont;
+  watermarkSize?: number; // percent of image width
+  watermarkColor?: string; // #rrggbb
+  watermarkOpacity?: number; // 0-1
+  watermarkMargin?: number; // percent of image width
+  watermarkMode?: WatermarkMode;
+  watermarkRotation?: number; // degrees counter-clockwise
+  watermarkScale?: number; // logo width, percent of image width
+  thumbnailCrop?: ThumbnailCrop; // smart crops around the most detailed part of each photo
+  processingProfileId?: string; // '' removes the profile
+}
+
+export interface LogoUploadUrlResponse {
+  uploadUrl: string;
+  key: string;
+}
+
+export interface WatermarkLogoResult {
+  watermarkLogoKey?: string;
+  regenerating: number; // galleries whose photos are queued to be watermarked again
+}
+
+// Processing status counts of a gallery's photos while they are reprocessed
+export interface ReprocessProgress {
+  galleryId: string;
+  queued: number; // photos being queued by the request that started reprocessing
+  pending: number;
+  processing: number;
+  completed: number;
+  failed: number;
+  done: boolean;
 }
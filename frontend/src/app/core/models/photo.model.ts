@@ -13,6 +13,54 @@ export interface Photo {
   favoriteCount: number;
   downloadCount: number;
   metadata?: Record<string, string>;
+  renditions?: Rendition[];
+  focalPoint?: FocalPoint;
+  blurHash?: string; // placeholder shown while the image loads
+  dominantColor?: string; // #rrggbb
+  processingStatus?: string;
+  processedAt?: string;
+  failure?: ProcessingFailure; // why processing last failed
+}
+
+export type FailureCategory = 'corrupt_file' | 'unsupported_format' | 'too_large' | 'internal_error';
+
+/** Why a photo couldn't be processed; attempts are oldest first */
+export interface ProcessingFailure {
+  category: FailureCategory;
+  message: string;
+  attempts?: FailedAttempt[];
+}
+
+export interface FailedAttempt {
+  at: string;
+  category: FailureCategory;
+  message: string;
+}
+
+/** A failed photo listed across galleries */
+export interface FailedPhoto extends Photo {
+  galleryName: string;
+}
+
+/** The point a photo's thumbnail is cropped around, 0-1 across its width and height */
+export interface FocalPoint {
+  x: number;
+  y: number;
+}
+
+/** A responsive size of a photo; renditions are ordered by width */
+export interface Rendition {
+  name: string;
+  key: string;
+  width: number;
+  height: number;
+  /** The same size in formats smaller than the JPEG at `key`, for browsers that support them */
+  formats?: RenditionFormat[];
+}
+
+export interface RenditionFormat {
+  format: 'webp' | 'avif';
+  key: string;
 }
 
 export interface UploadUrlResponse {
@@ -148,14 +148,14 @@ describe('ApiService', () => {
         key: 'original/photo.jpg'
       };
 
-      service.getUploadUrl('gal_123', 'photo.jpg', 'image/jpeg').subscribe(response => {
+      service.getUploadUrl('gal_123', 'photo.jpg', 'image/jpeg', 2048).subscribe(response => {
         expect(response).toEqual(mockResponse);
         done();
       });
 
       const req = httpMock.expectOne(`${environment.apiUrl}/galleries/gal_123/photos/upload-url`);
       expect(req.request.method).toBe('POST');
-      expect(req.request.body).toEqual({ fileName: 'photo.jpg', mimeType: 'image/jpeg' });
+      expect(req.request.body).toEqual({ fileName: 'photo.jpg', mimeType: 'image/jpeg', fileSize: 2048 });
       req.flush(mockResponse);
     });
 
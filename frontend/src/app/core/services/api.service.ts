@@ -2,8 +2,8 @@ import { Injectable, inject } from '@angular/core';
 import { HttpClient, HttpParams } from '@angular/common/http';
 import { Observable } from 'rxjs';
 import { environment } from '../../../environments/environment';
-import { Gallery, CreateGalleryRequest, UpdateGalleryRequest } from '../models/gallery.model';
-import { Photo, UploadUrlResponse } from '../models/photo.model';
+import { Gallery, CreateGalleryRequest, UpdateGalleryRequest, ReprocessProgress } from '../models/gallery.model';
+import { FailedPhoto, FocalPoint, Photo, UploadUrlResponse } from '../models/photo.model';
 import { User } from '../models/user.model';
 
 @Injectable({
@@ -47,11 +47,21 @@ export class ApiService {
     return this.http.post<Gallery>(`${this.baseUrl}/galleries/${galleryId}/expire`, { expiresAt });
   }
 
+  // Regenerate derivatives after watermark or rendition settings change
+  reprocessGallery(galleryId: string): Observable<ReprocessProgress> {
+    return this.http.post<ReprocessProgress>(`${this.baseUrl}/galleries/${galleryId}/reprocess`, {});
+  }
+
+  getReprocessProgress(galleryId: string): Observable<ReprocessProgress> {
+    return this.http.get<ReprocessProgress>(`${this.baseUrl}/galleries/${galleryId}/reprocess`);
+  }
+
   // Photo endpoints
-  getUploadUrl(galleryId: string, fileName: string, mimeType: string): Observable<UploadUrlResponse> {
+  // fileSize is signed into the upload URL, so S3 rejects a body of any other length
+  getUploadUrl(galleryId: string, fileName: string, mimeType: string, fileSize: number): Observable<UploadUrlResponse> {
     return this.http.post<UploadUrlResponse>(
       `${this.baseUrl}/galleries/${galleryId}/photos/upload-url`,
-      { fileName, mimeType }
+      { fileName, mimeType, fileSize }
     );
   }
 
@@ -70,6 +80,25 @@ export class ApiService {
     return this.http.delete<void>(`${this.baseUrl}/galleries/${galleryId}/photos/${photoId}`);
   }
 
+  // Crops the photo's thumbnail around a point; null goes back to the gallery's thumbnail crop
+  setFocalPoint(galleryId: string, photoId: string, focalPoint: FocalPoint | null): Observable<Photo> {
+    const url = `${this.baseUrl}/galleries/${galleryId}/photos/${photoId}/focal-point`;
+    return focalPoint ? this.http.put<Photo>(url, focalPoint) : this.http.delete<Photo>(url);
+  }
+
+  // Photos that failed processing, across all galleries
+  getFailedPhotos(limit = 50, lastKey?: any): Observable<{ photos: FailedPhoto[], lastKey?: any }> {
+    let params = new HttpParams().set('limit', limit.toString());
+    if (lastKey) {
+      params = params.set('lastKey', JSON.stringify(lastKey));
+    }
+    return this.http.get<{ photos: FailedPhoto[], lastKey?: any }>(`${this.baseUrl}/photos/failed`, { params });
+  }
+
+  retryFailedPhoto(galleryId: string, photoId: string): Observable<Photo> {
+    return this.http.post<Photo>(`${this.baseUrl}/galleries/${galleryId}/photos/${photoId}/retry`, {});
+  }
+
   getGalleryFavorites(galleryId: string): Observable<{ favorites: any[] }> {
     return this.http.get<{ favorites: any[] }>(`${this.baseUrl}/galleries/${galleryId}/favorites`);
   }
@@ -0,0 +1,72 @@
+const BASE83 = '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~';
+
+function decode83(str: string): number {
+  let value = 0;
+  for (const c of str) {
+    value = value * 83 + BASE83.indexOf(c);
+  }
+  return value;
+}
+
+function sRGBToLinear(value: number): number {
+  const v = value / 255;
+  return v <= 0.04045 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4);
+}
+
+function linearToSRGB(value: number): number {
+  const v = Math.max(0, Math.min(1, value));
+  return v <= 0.0031308
+    ? Math.round(v * 12.92 * 255)
+    : Math.round((1.055 * Math.pow(v, 1 / 2.4) - 0.055) * 255);
+}
+
+function signPow(value: number, exp: number): number {
+  return Math.sign(value) * Math.pow(Math.abs(value), exp);
+}
+
+/**
+ * Decode a BlurHash (https://blurha.sh) into RGBA pixels, or null if the hash is malformed
+ */
+export function decodeBlurHash(hash: string, width: number, height: number): Uint8ClampedArray | null {
+  if (!hash || hash.length < 6) return null;
+
+  const size = decode83(hash[0]);
+  const componentsX = (size % 9) + 1;
+  const componentsY = Math.floor(size / 9) + 1;
+  if (hash.length !== 4 + 2 * componentsX * componentsY) return null;
+
+  const maxValue = (decode83(hash[1]) + 1) / 166;
+  const colors: number[][] = [];
+  const dc = decode83(hash.substring(2, 6));
+  colors.push([sRGBToLinear(dc >> 16), sRGBToLinear((dc >> 8) & 255), sRGBToLinear(dc & 255)]);
+  for (let i = 1; i < componentsX * componentsY; i++) {
+    const ac = decode83(hash.substring(4 + i * 2, 6 + i * 2));
+    colors.push([
+      signPow((Math.floor(ac / (19 * 19)) - 9) / 9, 2) * maxValue,
+      signPow((Math.floor(ac / 19) % 19 - 9) / 9, 2) * maxValue,
+      signPow((ac % 19 - 9) / 9, 2) * maxValue
+    ]);
+  }
+
+  const pixels = new Uint8ClampedArray(width * height * 4);
+  for (let y = 0; y < height; y++) {
+    for (let x = 0; x < width; x++) {
+      let r = 0, g = 0, b = 0;
+      for (let j = 0; j < componentsY; j++) {
+        for (let i = 0; i < componentsX; i++) {
+          const basis = Math.cos((Math.PI * x * i) / width) * Math.cos((Math.PI * y * j) / height);
+          const color = colors[i + j * componentsX];
+          r += color[0] * basis;
+          g += color[1] * basis;
+          b += color[2] * basis;
+        }
+      }
+      const p = 4 * (x + y * width);
+      pixels[p] = linearToSRGB(r);
+      pixels[p + 1] = linearToSRGB(g);
+      pixels[p + 2] = linearToSRGB(b);
+      pixels[p + 3] = 255;
+    }
+  }
+  return pixels;
+}
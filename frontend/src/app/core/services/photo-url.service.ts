@@ -1,12 +1,17 @@
 import { Injectable } from '@angular/core';
 import { environment } from '../../../environments/environment';
 import { Photo } from '../models/photo.model';
+import { decodeBlurHash } from './blurhash';
+
+// Placeholders are decoded this small and scaled up by the browser; they're blurry anyway
+const PLACEHOLDER_SIZE = 32;
 
 @Injectable({
   providedIn: 'root'
 })
 export class PhotoUrlService {
   private cdnUrl = environment.cdnUrl;
+  private placeholders = new Map<string, string>();
 
   /**
    * Generate thumbnail URL from S3 key
@@ -41,6 +46,68 @@ export class PhotoUrlService {
     return '';
   }
 
+  /**
+   * Build a srcset from the photo's renditions, or an empty string before they are generated
+   */
+  getSrcset(photo: Photo): string {
+    return (photo.renditions ?? [])
+      .map(r => `${this.cdnUrl}/${r.key} ${r.width}w`)
+      .join(', ');
+  }
+
+  /**
+   * Build <picture> sources for the photo's alternate formats, smallest format first,
+   * so the browser picks the first type it supports and falls back to the JPEG srcset
+   */
+  getSources(photo: Photo): { type: string; srcset: string }[] {
+    const order = ['avif', 'webp'];
+    const byFormat = new Map<string, string[]>();
+    for (const r of photo.renditions ?? []) {
+      for (const f of r.formats ?? []) {
+        const entries = byFormat.get(f.format) ?? [];
+        entries.push(`${this.cdnUrl}/${f.key} ${r.width}w`);
+        byFormat.set(f.format, entries);
+      }
+    }
+    return order
+      .filter(format => byFormat.has(format))
+      .map(format => ({ type: `image/${format}`, srcset: byFormat.get(format)!.join(', ') }));
+  }
+
+  /**
+   * CSS background showing the photo's placeholder until its image loads: the decoded
+   * BlurHash over its dominant color, or nothing for photos processed without one
+   */
+  getPlaceholderStyle(photo: Photo): Record<string, string> {
+    const style: Record<string, string> = {};
+    if (photo.dominantColor) {
+      style['background-color'] = photo.dominantColor;
+    }
+    const url = photo.blurHash ? this.getPlaceholderUrl(photo.blurHash) : '';
+    if (url) {
+      style['background-image'] = `url(${url})`;
+      style['background-size'] = 'cover';
+    }
+    return style;
+  }
+
+  private getPlaceholderUrl(hash: string): string {
+    const cached = this.placeholders.get(hash);
+    if (cached !== undefined) return cached;
+
+    let url = '';
+    const pixels = decodeBlurHash(hash, PLACEHOLDER_SIZE, PLACEHOLDER_SIZE);
+    const canvas = pixels ? document.createElement('canvas') : null;
+    const context = canvas?.getContext('2d');
+    if (pixels && canvas && context) {
+      canvas.width = canvas.height = PLACEHOLDER_SIZE;
+      context.putImageData(new ImageData(pixels, PLACEHOLDER_SIZE, PLACEHOLDER_SIZE), 0, 0);
+      url = canvas.toDataURL();
+    }
+    this.placeholders.set(hash, url);
+    return url;
+  }
+
   /**
    * Get the best available URL for a photo
    * Prioritizes: thumbnail -> optimized -> original
@@ -167,6 +167,10 @@ export class GalleryViewComponent implements OnInit {
     return this.photoUrlService.getThumbnailUrl(photo);
   }
 
+  getPlaceholderStyle(photo: Photo): Record<string, string> {
+    return this.photoUrlService.getPlaceholderStyle(photo);
+  }
+
   getOptimizedUrl(photo: Photo): string {
     return this.photoUrlService.getOptimizedUrl(photo);
   }
@@ -1,9 +1,10 @@
 import { Component, OnInit, inject, signal, computed } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { ActivatedRoute, Router, RouterModule } from '@angular/router';
+import { switchMap, takeWhile, timer } from 'rxjs';
 import { ApiService } from '../../../core/services/api.service';
 import { PhotoUrlService } from '../../../core/services/photo-url.service';
-import { Gallery } from '../../../core/models/gallery.model';
+import { Gallery, ReprocessProgress } from '../../../core/models/gallery.model';
 import { Photo } from '../../../core/models/photo.model';
 
 @Component({
@@ -28,6 +29,7 @@ export class GalleryDetailComponent implements OnInit {
   urlCopied = signal(false);
   error = signal<string | null>(null);
   selectedPhoto = signal<Photo | null>(null);
+  reprocessProgress = signal<ReprocessProgress | null>(null);
 
   favoritePhotos = computed(() => {
     const favs = this.favorites();
@@ -126,6 +128,38 @@ export class GalleryDetailComponent implements OnInit {
     }
   }
 
+  reprocessPhotos(): void {
+    if (!confirm('Regenerate all photos with the current watermark settings? This may take a few minutes.')) {
+      return;
+    }
+
+    this.apiService.reprocessGallery(this.galleryId).subscribe({
+      next: (progress) => {
+        this.reprocessProgress.set(progress);
+        this.pollReprocessProgress();
+      },
+      error: (err) => {
+        console.error('Failed to reprocess gallery:', err);
+        alert(err.error?.message || 'Failed to reprocess photos');
+      }
+    });
+  }
+
+  private pollReprocessProgress(): void {
+    timer(3000, 3000).pipe(
+      switchMap(() => this.apiService.getReprocessProgress(this.galleryId)),
+      takeWhile(progress => !progress.done, true)
+    ).subscribe({
+      next: (progress) => {
+        this.reprocessProgress.set(progress);
+        if (progress.done) {
+          this.loadPhotos();
+        }
+      },
+      error: (err) => console.error('Failed to load reprocessing progress:', err)
+    });
+  }
+
   viewPhoto(photo: Photo): void {
     this.selectedPhoto.set(photo);
   }
@@ -192,6 +226,10 @@ export class GalleryDetailComponent implements OnInit {
     return this.photoUrlService.getThumbnailUrl(photo);
   }
 
+  getPlaceholderStyle(photo: Photo): Record<string, string> {
+    return this.photoUrlService.getPlaceholderStyle(photo);
+  }
+
   formatFileSize(bytes: number): string {
     if (bytes < 1024) return bytes + ' B';
     if (bytes < 1024 * 1024) return (bytes / 1024).toFixed(1) + ' KB';
@@ -48,20 +48,31 @@ export class GalleryFormComponent implements OnInit {
       password: ['', this.isEditMode() ? [] : [Validators.required, Validators.minLength(6)]],
       expiresAt: [''],
       enableWatermark: [false],
+      watermarkType: ['text'],
       watermarkText: [''],
-      watermarkPosition: ['bottom-right']
+      watermarkPosition: ['bottom-right'],
+      watermarkMode: ['single'],
+      watermarkFont: ['sans'],
+      watermarkSize: [3, [Validators.min(1), Validators.max(20)]],
+      watermarkColor: ['#ffffff'],
+      watermarkOpacity: [0.6, [Validators.min(0.05), Validators.max(1)]],
+      watermarkRotation: [0, [Validators.min(-180), Validators.max(180)]],
+      watermarkScale: [15, [Validators.min(1), Validators.max(100)]],
+      thumbnailCrop: ['center']
     });
 
-    // Add conditional validation for watermark text
-    this.galleryForm.get('enableWatermark')?.valueChanges.subscribe(enabled => {
+    // Watermark text is only required for text watermarks
+    const updateTextValidators = () => {
       const watermarkTextControl = this.galleryForm.get('watermarkText');
-      if (enabled) {
+      if (this.galleryForm.get('enableWatermark')?.value && this.galleryForm.get('watermarkType')?.value === 'text') {
         watermarkTextControl?.setValidators([Validators.required, Validators.minLength(1)]);
       } else {
         watermarkTextControl?.clearValidators();
       }
       watermarkTextControl?.updateValueAndValidity();
-    });
+    };
+    this.galleryForm.get('enableWatermark')?.valueChanges.subscribe(updateTextValidators);
+    this.galleryForm.get('watermarkType')?.valueChanges.subscribe(updateTextValidators);
   }
 
   private loadGallery(): void {
@@ -75,8 +86,17 @@ export class GalleryFormComponent implements OnInit {
           customUrl: gallery.customUrl,
           expiresAt: gallery.expiresAt ? this.formatDateForInput(gallery.expiresAt) : '',
           enableWatermark: gallery.enableWatermark === true || gallery.enableWatermark === false ? gallery.enableWatermark : false,
+          watermarkType: gallery.watermarkType ?? 'text',
           watermarkText: gallery.watermarkText ?? '',
-          watermarkPosition: gallery.watermarkPosition ?? 'bottom-right'
+          watermarkPosition: gallery.watermarkPosition ?? 'bottom-right',
+          watermarkMode: gallery.watermarkMode ?? 'single',
+          watermarkFont: gallery.watermarkFont ?? 'sans',
+          watermarkSize: gallery.watermarkSize ?? 3,
+          watermarkColor: gallery.watermarkColor ?? '#ffffff',
+          watermarkOpacity: gallery.watermarkOpacity ?? 0.6,
+          watermarkRotation: gallery.watermarkRotation ?? 0,
+          watermarkScale: gallery.watermarkScale ?? 15,
+          thumbnailCrop: gallery.thumbnailCrop ?? 'center'
         });
         // Don't populate password on edit
       },
@@ -111,7 +131,9 @@ export class GalleryFormComponent implements OnInit {
       expiresAt: formValue.expiresAt ? this.formatDateToISO(formValue.expiresAt) : undefined,
       enableWatermark: formValue.enableWatermark || false,
       watermarkText: formValue.enableWatermark ? formValue.watermarkText : undefined,
-      watermarkPosition: formValue.enableWatermark ? formValue.watermarkPosition : undefined
+      watermarkPosition: formValue.enableWatermark ? formValue.watermarkPosition : undefined,
+      thumbnailCrop: formValue.thumbnailCrop,
+      ...this.watermarkStyle(formValue)
     };
 
     this.apiService.createGallery(request).subscribe({
@@ -128,6 +150,26 @@ export class GalleryFormComponent implements OnInit {
     });
   }
 
+  private watermarkStyle(formValue: any): Partial<CreateGalleryRequest> {
+    if (!formValue.enableWatermark) return {};
+    if (formValue.watermarkType === 'logo') {
+      return {
+        watermarkType: 'logo',
+        watermarkScale: Number(formValue.watermarkScale),
+        watermarkOpacity: Number(formValue.watermarkOpacity)
+      };
+    }
+    return {
+      watermarkType: 'text',
+      watermarkMode: formValue.watermarkMode,
+      watermarkFont: formValue.watermarkFont,
+      watermarkSize: Number(formValue.watermarkSize),
+      watermarkColor: formValue.watermarkColor,
+      watermarkOpacity: Number(formValue.watermarkOpacity),
+      watermarkRotation: Number(formValue.watermarkRotation) || undefined
+    };
+  }
+
   private updateGallery(formValue: any): void {
     if (!this.galleryId) return;
 
@@ -137,7 +179,9 @@ export class GalleryFormComponent implements OnInit {
       expiresAt: formValue.expiresAt ? this.formatDateToISO(formValue.expiresAt) : undefined,
       enableWatermark: formValue.enableWatermark || false,
       watermarkText: formValue.enableWatermark ? formValue.watermarkText : undefined,
-      watermarkPosition: formValue.enableWatermark ? formValue.watermarkPosition : undefined
+      watermarkPosition: formValue.enableWatermark ? formValue.watermarkPosition : undefined,
+      thumbnailCrop: formValue.thumbnailCrop,
+      ...this.watermarkStyle(formValue)
     };
 
     // Only include password if it was changed
@@ -126,7 +126,8 @@ export class PhotoUploadComponent implements OnInit {
       const urlResponse = await this.apiService.getUploadUrl(
         this.galleryId,
         uploadFile.file.name,
-        uploadFile.file.type
+        uploadFile.file.type,
+        uploadFile.file.size
       ).toPromise();
 
       if (!urlResponse) throw new Error('No upload URL received');
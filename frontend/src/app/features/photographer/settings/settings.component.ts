@@ -2,7 +2,9 @@ import { Component, OnInit, inject, signal } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { FormsModule } from '@angular/forms';
 import { HttpClient } from '@angular/common/http';
+import { switchMap } from 'rxjs';
 import { environment } from '../../../../environments/environment';
+import { LogoUploadUrlResponse, WatermarkLogoResult } from '../../../core/models/gallery.model';
 
 interface DomainConfig {
   type?: 'subdomain' | 'custom';
@@ -143,6 +145,38 @@ interface DNSRecord {
           }
         }
       </section>
+
+      <section class="settings-section">
+        <h2>Watermark Logo</h2>
+        <p class="section-description">
+          Galleries can be watermarked with your logo instead of text. Use a PNG with a transparent
+          background, up to 2 MB.
+        </p>
+
+        <div class="input-group">
+          <input type="file" accept="image/png" (change)="onLogoSelected($event)" [disabled]="uploadingLogo()">
+        </div>
+        <label class="logo-option">
+          <input type="checkbox" [(ngModel)]="regenerateLogo">
+          Re-watermark existing photos in galleries using the logo
+        </label>
+
+        <div class="input-group">
+          <button class="btn btn-primary" (click)="uploadLogo()" [disabled]="!logoFile || uploadingLogo()">
+            {{ uploadingLogo() ? 'Uploading...' : 'Upload Logo' }}
+          </button>
+          <button class="btn btn-danger" (click)="removeLogo()" [disabled]="uploadingLogo()">
+            Remove Logo
+          </button>
+        </div>
+
+        @if (logoError()) {
+          <p class="error-text">{{ logoError() }}</p>
+        }
+        @if (logoMessage()) {
+          <div class="success-message">{{ logoMessage() }}</div>
+        }
+      </section>
     </div>
   `,
   styles: [`
@@ -313,6 +347,14 @@ interface DNSRecord {
       margin: 0.5rem 0;
     }
 
+    .logo-option {
+      display: flex;
+      align-items: center;
+      gap: 0.5rem;
+      margin-bottom: 1rem;
+      color: #666;
+    }
+
     .success-message {
       background: #d4edda;
       color: #155724;
@@ -374,6 +416,12 @@ export class SettingsComponent implements OnInit {
   verifying = signal(false);
   removing = signal(false);
 
+  logoFile: File | null = null;
+  regenerateLogo = true;
+  uploadingLogo = signal(false);
+  logoError = signal<string | null>(null);
+  logoMessage = signal<string | null>(null);
+
   ngOnInit() {
     this.loadDomainConfig();
   }
@@ -477,4 +525,69 @@ export class SettingsComponent implements OnInit {
         }
       });
   }
+
+  onLogoSelected(event: Event) {
+    const input = event.target as HTMLInputElement;
+    this.logoFile = input.files?.[0] ?? null;
+  }
+
+  uploadLogo() {
+    const file = this.logoFile;
+    if (!file) return;
+
+    this.uploadingLogo.set(true);
+    this.logoError.set(null);
+    this.logoMessage.set(null);
+
+    this.http.post<LogoUploadUrlResponse>(`${environment.apiUrl}/watermark/logo/upload-url`, {
+      mimeType: file.type,
+      fileSize: file.size
+    }).pipe(
+      switchMap(({ uploadUrl, key }) =>
+        this.http.put(uploadUrl, file, { headers: { 'Content-Type': file.type } }).pipe(
+          switchMap(() => this.http.put<WatermarkLogoResult>(`${environment.apiUrl}/watermark/logo`, {
+            key,
+            regenerate: this.regenerateLogo
+          }))
+        )
+      )
+    ).subscribe({
+      next: (result) => {
+        this.logoMessage.set(this.logoResultMessage('Logo uploaded', result));
+        this.logoFile = null;
+        this.uploadingLogo.set(false);
+      },
+      error: (err) => {
+        this.logoError.set(err.error?.message || 'Failed to upload logo');
+        this.uploadingLogo.set(false);
+      }
+    });
+  }
+
+  removeLogo() {
+    if (!confirm('Remove your watermark logo? Galleries using it will no longer be watermarked.')) {
+      return;
+    }
+
+    this.uploadingLogo.set(true);
+    this.logoError.set(null);
+    this.http.delete<WatermarkLogoResult>(`${environment.apiUrl}/watermark/logo`, {
+      body: { regenerate: this.regenerateLogo }
+    }).subscribe({
+      next: (result) => {
+        this.logoMessage.set(this.logoResultMessage('Logo removed', result));
+        this.uploadingLogo.set(false);
+      },
+      error: (err) => {
+        this.logoError.set(err.error?.message || 'Failed to remove logo');
+        this.uploadingLogo.set(false);
+      }
+    });
+  }
+
+  private logoResultMessage(done: string, result: WatermarkLogoResult): string {
+    return result.regenerating > 0
+      ? `${done}. Photos in ${result.regenerating} ${result.regenerating === 1 ? 'gallery are' : 'galleries are'} being re-watermarked.`
+      : `${done}.`;
+  }
 }
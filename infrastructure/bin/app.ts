@@ -5,6 +5,7 @@ import { DatabaseStack } from '../lib/stacks/database-stack';
 import { DomainStack } from '../lib/stacks/domain-stack';
 import { StorageStack } from '../lib/stacks/storage-stack';
 import { AuthStack } from '../lib/stacks/auth-stack';
+import { DownloadStack } from '../lib/stacks/download-stack';
 import { ApiStack } from '../lib/stacks/api-stack';
 import { SchedulerStack } from '../lib/stacks/scheduler-stack';
 
@@ -53,6 +54,14 @@ const authStack = new AuthStack(app, `PhotographerGalleryAuth-${stage}`, {
   stage,
 });
 
+// Download Stack (async ZIP archives for client galleries)
+const downloadStack = new DownloadStack(app, `PhotographerGalleryDownload-${stage}`, {
+  env,
+  stage,
+  databaseStack,
+  storageStack,
+});
+
 // API Stack (depends on all other stacks)
 const apiStack = new ApiStack(app, `PhotographerGalleryApi-${stage}`, {
   env,
@@ -60,6 +69,7 @@ const apiStack = new ApiStack(app, `PhotographerGalleryApi-${stage}`, {
   databaseStack,
   storageStack,
   authStack,
+  downloadStack,
 });
 
 // Scheduler Stack (for daily cleanup of expired galleries)
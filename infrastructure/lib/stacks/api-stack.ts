@@ -7,12 +7,14 @@ import * as logs from 'aws-cdk-lib/aws-logs';
 import { DatabaseStack } from './database-stack';
 import { StorageStack } from './storage-stack';
 import { AuthStack } from './auth-stack';
+import { DownloadStack } from './download-stack';
 
 interface ApiStackProps extends cdk.StackProps {
   stage: string;
   databaseStack: DatabaseStack;
   storageStack: StorageStack;
   authStack: AuthStack;
+  downloadStack: DownloadStack;
 }
 
 export class ApiStack extends cdk.Stack {
@@ -22,7 +24,7 @@ export class ApiStack extends cdk.Stack {
   constructor(scope: Construct, id: string, props: ApiStackProps) {
     super(scope, id, props);
 
-    const { stage, databaseStack, storageStack, authStack } = props;
+    const { stage, databaseStack, storageStack, authStack, downloadStack } = props;
 
     // Lambda function for API
     // CDK will automatically build the Go binary during deployment
@@ -54,9 +56,13 @@ export class ApiStack extends cdk.Stack {
         S3_BUCKET_ORIGINAL: storageStack.originalBucket.bucketName,
         S3_BUCKET_OPTIMIZED: storageStack.optimizedBucket.bucketName,
         S3_BUCKET_THUMBNAIL: storageStack.thumbnailBucket.bucketName,
+        S3_BUCKET_DOWNLOADS: downloadStack.downloadsBucket.bucketName,
+        DOWNLOAD_QUEUE_URL: downloadStack.downloadQueue.queueUrl,
+        PROCESSING_QUEUE_URL: storageStack.processingQueue.queueUrl,
         COGNITO_USER_POOL_ID: authStack.userPool.userPoolId,
         COGNITO_CLIENT_ID: authStack.userPoolClient.userPoolClientId,
         SIGNED_URL_EXPIRATION: '24',
+        TRASH_RETENTION_DAYS: '30',
         JWT_SECRET: process.env.JWT_SECRET || 'dev-secret-change-in-production',
         ALLOWED_ORIGINS: stage === 'prod'
           ? 'https://your-production-domain.com'
@@ -70,12 +76,33 @@ export class ApiStack extends cdk.Stack {
     databaseStack.galleriesTable.grantReadWriteData(this.apiHandler);
     databaseStack.photosTable.grantReadWriteData(this.apiHandler);
     databaseStack.favoritesTable.grantReadWriteData(this.apiHandler);
+    databaseStack.selectionsTable.grantReadWriteData(this.apiHandler);
     databaseStack.clientSessionsTable.grantReadWriteData(this.apiHandler);
+    databaseStack.outboxTable.grantWriteData(this.apiHandler);
+    databaseStack.webhooksTable.grantReadWriteData(this.apiHandler);
+    databaseStack.webhookDeliveriesTable.grantReadWriteData(this.apiHandler);
+    databaseStack.downloadJobsTable.grantReadWriteData(this.apiHandler);
+    databaseStack.trashTable.grantReadWriteData(this.apiHandler);
+    databaseStack.processingProfilesTable.grantReadWriteData(this.apiHandler);
 
     // Grant permissions to S3 buckets
     storageStack.originalBucket.grantReadWrite(this.apiHandler);
     storageStack.optimizedBucket.grantReadWrite(this.apiHandler);
     storageStack.thumbnailBucket.grantReadWrite(this.apiHandler);
+    downloadStack.downloadsBucket.grantRead(this.apiHandler);
+    // Replaced watermark logos are deleted
+    storageStack.originalBucket.grantDelete(this.apiHandler, 'watermarks/*');
+
+    // Grant permission to queue client download jobs
+    downloadStack.downloadQueue.grantSendMessages(this.apiHandler);
+
+    // Gallery restores rehydrate archived originals and queue them for reprocessing
+    storageStack.processingQueue.grantSendMessages(this.apiHandler);
+    this.apiHandler.addToRolePolicy(new iam.PolicyStatement({
+      effect: iam.Effect.ALLOW,
+      actions: ['s3:RestoreObject'],
+      resources: [storageStack.originalBucket.arnForObjects('*')],
+    }));
 
     // Grant permissions to Cognito
     this.apiHandler.addToRolePolicy(new iam.PolicyStatement({
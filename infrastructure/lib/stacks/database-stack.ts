@@ -11,7 +11,14 @@ export class DatabaseStack extends cdk.Stack {
   public readonly galleriesTable: dynamodb.Table;
   public readonly photosTable: dynamodb.Table;
   public readonly favoritesTable: dynamodb.Table;
+  public readonly selectionsTable: dynamodb.Table;
   public readonly clientSessionsTable: dynamodb.Table;
+  public readonly outboxTable: dynamodb.Table;
+  public readonly webhooksTable: dynamodb.Table;
+  public readonly webhookDeliveriesTable: dynamodb.Table;
+  public readonly downloadJobsTable: dynamodb.Table;
+  public readonly trashTable: dynamodb.Table;
+  public readonly processingProfilesTable: dynamodb.Table;
 
   constructor(scope: Construct, id: string, props: DatabaseStackProps) {
     super(scope, id, props);
@@ -99,6 +106,15 @@ export class DatabaseStack extends cdk.Stack {
       projectionType: dynamodb.ProjectionType.ALL,
     });
 
+    // GSI2: Failed photos across a photographer's galleries, most recent first. Sparse: only
+    // failed photos have failedAt
+    this.photosTable.addGlobalSecondaryIndex({
+      indexName: 'PhotographerFailedIndex',
+      partitionKey: { name: 'photographerId', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'failedAt', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
     // Favorites Table
     this.favoritesTable = new dynamodb.Table(this, 'FavoritesTable', {
       tableName: `photographer-gallery-favorites-${props.stage}`,
@@ -116,6 +132,15 @@ export class DatabaseStack extends cdk.Stack {
       projectionType: dynamodb.ProjectionType.ALL,
     });
 
+    // Selections Table (proofing submissions, one item per gallery and client session)
+    this.selectionsTable = new dynamodb.Table(this, 'SelectionsTable', {
+      tableName: `photographer-gallery-selections-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
     // Client Sessions Table
     this.clientSessionsTable = new dynamodb.Table(this, 'ClientSessionsTable', {
       tableName: `photographer-gallery-sessions-${props.stage}`,
@@ -126,6 +151,109 @@ export class DatabaseStack extends cdk.Stack {
       removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
     });
 
+    // Outbox Table (domain events written in the same transaction as entity changes)
+    this.outboxTable = new dynamodb.Table(this, 'OutboxTable', {
+      tableName: `photographer-gallery-outbox-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      timeToLiveAttribute: 'ttl',
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // GSI1: Pending events in creation order for the relay
+    this.outboxTable.addGlobalSecondaryIndex({
+      indexName: 'StatusCreatedAtIndex',
+      partitionKey: { name: 'status', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'createdAt', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
+    // Webhooks Table
+    this.webhooksTable = new dynamodb.Table(this, 'WebhooksTable', {
+      tableName: `photographer-gallery-webhooks-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // GSI1: Lookup by webhook ID
+    this.webhooksTable.addGlobalSecondaryIndex({
+      indexName: 'WebhookIdIndex',
+      partitionKey: { name: 'webhookId', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
+    // Webhook Deliveries Table (delivery log, expired by TTL)
+    this.webhookDeliveriesTable = new dynamodb.Table(this, 'WebhookDeliveriesTable', {
+      tableName: `photographer-gallery-webhook-deliveries-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      timeToLiveAttribute: 'ttl',
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // GSI1: Deliveries for a webhook, most recent first
+    this.webhookDeliveriesTable.addGlobalSecondaryIndex({
+      indexName: 'WebhookCreatedAtIndex',
+      partitionKey: { name: 'webhookId', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'createdAt', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
+    // GSI2: Pending deliveries due for retry
+    this.webhookDeliveriesTable.addGlobalSecondaryIndex({
+      indexName: 'StatusNextAttemptIndex',
+      partitionKey: { name: 'status', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'nextAttemptAt', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
+    // Download Jobs Table (client ZIP archive jobs, expired by TTL with the archives)
+    this.downloadJobsTable = new dynamodb.Table(this, 'DownloadJobsTable', {
+      tableName: `photographer-gallery-download-jobs-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      timeToLiveAttribute: 'ttl',
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // Trash Table (deleted galleries and photos kept until their retention elapses)
+    this.trashTable = new dynamodb.Table(this, 'TrashTable', {
+      tableName: `photographer-gallery-trash-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // GSI1: Trashed items due for purging
+    this.trashTable.addGlobalSecondaryIndex({
+      indexName: 'StatusPurgeAtIndex',
+      partitionKey: { name: 'status', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'purgeAt', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
+    // Processing Profiles Table (photographers' named processing profiles, used by galleries)
+    this.processingProfilesTable = new dynamodb.Table(this, 'ProcessingProfilesTable', {
+      tableName: `photographer-gallery-processing-profiles-${props.stage}`,
+      partitionKey: { name: 'PK', type: dynamodb.AttributeType.STRING },
+      sortKey: { name: 'SK', type: dynamodb.AttributeType.STRING },
+      billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+      removalPolicy: props.stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+    });
+
+    // GSI1: Lookup by profile ID
+    this.processingProfilesTable.addGlobalSecondaryIndex({
+      indexName: 'ProfileIdIndex',
+      partitionKey: { name: 'profileId', type: dynamodb.AttributeType.STRING },
+      projectionType: dynamodb.ProjectionType.ALL,
+    });
+
     // Outputs
     new cdk.CfnOutput(this, 'PhotographersTableName', {
       value: this.photographersTable.tableName,
@@ -151,5 +279,40 @@ export class DatabaseStack extends cdk.Stack {
       value: this.clientSessionsTable.tableName,
       exportName: `ClientSessionsTable-${props.stage}`,
     });
+
+    new cdk.CfnOutput(this, 'OutboxTableName', {
+      value: this.outboxTable.tableName,
+      exportName: `OutboxTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'WebhooksTableName', {
+      value: this.webhooksTable.tableName,
+      exportName: `WebhooksTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'WebhookDeliveriesTableName', {
+      value: this.webhookDeliveriesTable.tableName,
+      exportName: `WebhookDeliveriesTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'SelectionsTableName', {
+      value: this.selectionsTable.tableName,
+      exportName: `SelectionsTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'DownloadJobsTableName', {
+      value: this.downloadJobsTable.tableName,
+      exportName: `DownloadJobsTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'TrashTableName', {
+      value: this.trashTable.tableName,
+      exportName: `TrashTable-${props.stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'ProcessingProfilesTableName', {
+      value: this.processingProfilesTable.tableName,
+      exportName: `ProcessingProfilesTable-${props.stage}`,
+    });
   }
 }
@@ -0,0 +1,126 @@
+import * as cdk from 'aws-cdk-lib';
+import * as lambda from 'aws-cdk-lib/aws-lambda';
+import * as s3 from 'aws-cdk-lib/aws-s3';
+import * as sqs from 'aws-cdk-lib/aws-sqs';
+import * as lambdaEventSources from 'aws-cdk-lib/aws-lambda-event-sources';
+import * as logs from 'aws-cdk-lib/aws-logs';
+import { Construct } from 'constructs';
+import { DatabaseStack } from './database-stack';
+import { StorageStack } from './storage-stack';
+
+interface DownloadStackProps extends cdk.StackProps {
+  stage: string;
+  databaseStack: DatabaseStack;
+  storageStack: StorageStack;
+}
+
+export class DownloadStack extends cdk.Stack {
+  public readonly downloadsBucket: s3.Bucket;
+  public readonly downloadQueue: sqs.Queue;
+  public readonly downloaderFunction: lambda.Function;
+
+  constructor(scope: Construct, id: string, props: DownloadStackProps) {
+    super(scope, id, props);
+
+    const { stage, databaseStack, storageStack } = props;
+
+    // Downloads Bucket - ZIP archives built for clients, served only via presigned URLs.
+    // Kept separate from the originals bucket, whose uploads trigger image processing.
+    this.downloadsBucket = new s3.Bucket(this, 'DownloadsBucket', {
+      bucketName: `photographer-gallery-downloads-${stage}-${this.account}`,
+      encryption: s3.BucketEncryption.S3_MANAGED,
+      blockPublicAccess: s3.BlockPublicAccess.BLOCK_ALL,
+      lifecycleRules: [
+        {
+          id: 'ExpireArchivesRule',
+          enabled: true,
+          expiration: cdk.Duration.days(7),
+          abortIncompleteMultipartUploadAfter: cdk.Duration.days(1),
+        },
+      ],
+      removalPolicy: stage === 'prod' ? cdk.RemovalPolicy.RETAIN : cdk.RemovalPolicy.DESTROY,
+      autoDeleteObjects: stage !== 'prod',
+    });
+
+    // Dead Letter Queue for download jobs that could not be run
+    const deadLetterQueue = new sqs.Queue(this, 'DownloadDLQ', {
+      queueName: `photographer-gallery-downloads-dlq-${stage}`,
+      retentionPeriod: cdk.Duration.days(14),
+    });
+
+    // Download job queue; long jobs re-queue themselves before the Lambda times out
+    this.downloadQueue = new sqs.Queue(this, 'DownloadQueue', {
+      queueName: `photographer-gallery-downloads-${stage}`,
+      visibilityTimeout: cdk.Duration.minutes(15), // Must be >= Lambda timeout
+      deadLetterQueue: {
+        queue: deadLetterQueue,
+        maxReceiveCount: 3,
+      },
+    });
+
+    this.downloaderFunction = new lambda.Function(this, 'DownloaderFunction', {
+      functionName: `photographer-gallery-downloader-${stage}`,
+      runtime: lambda.Runtime.PROVIDED_AL2,
+      architecture: lambda.Architecture.ARM_64,
+      handler: 'bootstrap',
+      code: lambda.Code.fromAsset('../backend', {
+        bundling: {
+          image: lambda.Runtime.PROVIDED_AL2.bundlingImage,
+          command: [
+            'bash', '-c', [
+              'yum install -y golang',
+              'export GOPATH=/tmp/go',
+              'export GOCACHE=/tmp/go-cache',
+              'cd /asset-input',
+              'GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -tags lambda.norpc -o /asset-output/bootstrap cmd/downloader/main.go',
+            ].join(' && '),
+          ],
+          user: 'root',
+        },
+      }),
+      timeout: cdk.Duration.minutes(15),
+      memorySize: 1024, // Buffers one 16 MiB part plus the photo being streamed
+      environment: {
+        DYNAMODB_TABLE_PREFIX: 'photographer-gallery',
+        STAGE: stage,
+        S3_BUCKET_ORIGINAL: storageStack.originalBucket.bucketName,
+        S3_BUCKET_OPTIMIZED: storageStack.optimizedBucket.bucketName,
+        S3_BUCKET_THUMBNAIL: storageStack.thumbnailBucket.bucketName,
+        S3_BUCKET_DOWNLOADS: this.downloadsBucket.bucketName,
+        DOWNLOAD_QUEUE_URL: this.downloadQueue.queueUrl,
+      },
+      logRetention: logs.RetentionDays.ONE_WEEK,
+    });
+
+    // Grant DynamoDB permissions
+    databaseStack.downloadJobsTable.grantReadWriteData(this.downloaderFunction);
+    databaseStack.galleriesTable.grantReadData(this.downloaderFunction);
+    databaseStack.photosTable.grantReadData(this.downloaderFunction);
+    databaseStack.favoritesTable.grantReadData(this.downloaderFunction);
+
+    // Grant S3 permissions
+    storageStack.originalBucket.grantRead(this.downloaderFunction);
+    storageStack.optimizedBucket.grantRead(this.downloaderFunction);
+    this.downloadsBucket.grantReadWrite(this.downloaderFunction);
+    this.downloadsBucket.grantDelete(this.downloaderFunction);
+
+    // Consume jobs and re-queue unfinished ones
+    this.downloadQueue.grantSendMessages(this.downloaderFunction);
+    this.downloaderFunction.addEventSource(
+      new lambdaEventSources.SqsEventSource(this.downloadQueue, {
+        batchSize: 1,
+      })
+    );
+
+    // Outputs
+    new cdk.CfnOutput(this, 'DownloadsBucketName', {
+      value: this.downloadsBucket.bucketName,
+      exportName: `DownloadsBucket-${stage}`,
+    });
+
+    new cdk.CfnOutput(this, 'DownloadQueueUrl', {
+      value: this.downloadQueue.queueUrl,
+      exportName: `DownloadQueueUrl-${stage}`,
+    });
+  }
+}
@@ -59,6 +59,7 @@ export class SchedulerStack extends cdk.Stack {
         S3_BUCKET_ORIGINAL: storageStack.originalBucket.bucketName,
         S3_BUCKET_OPTIMIZED: storageStack.optimizedBucket.bucketName,
         S3_BUCKET_THUMBNAIL: storageStack.thumbnailBucket.bucketName,
+        PROCESSING_QUEUE_URL: storageStack.processingQueue.queueUrl,
       },
       logGroup,
     });
@@ -66,16 +67,32 @@ export class SchedulerStack extends cdk.Stack {
     // Grant DynamoDB permissions
     databaseStack.galleriesTable.grantReadWriteData(this.schedulerFunction);
     databaseStack.photosTable.grantReadWriteData(this.schedulerFunction);
+    databaseStack.outboxTable.grantReadWriteData(this.schedulerFunction);
+    databaseStack.webhooksTable.grantReadData(this.schedulerFunction);
+    databaseStack.webhookDeliveriesTable.grantReadWriteData(this.schedulerFunction);
+    databaseStack.favoritesTable.grantReadWriteData(this.schedulerFunction);
+    databaseStack.trashTable.grantReadWriteData(this.schedulerFunction);
+    databaseStack.photographersTable.grantReadWriteData(this.schedulerFunction);
 
-    // Grant S3 delete permissions
+    // Grant S3 permissions: archiving copies originals to cold storage and drops derivatives,
+    // restoring rehydrates originals and queues them for reprocessing
+    storageStack.originalBucket.grantReadWrite(this.schedulerFunction);
     storageStack.originalBucket.grantDelete(this.schedulerFunction);
     storageStack.optimizedBucket.grantDelete(this.schedulerFunction);
+    // Placeholder backfill reads the smallest rendition of each photo
+    storageStack.optimizedBucket.grantRead(this.schedulerFunction);
     storageStack.thumbnailBucket.grantDelete(this.schedulerFunction);
+    this.schedulerFunction.addToRolePolicy(new iam.PolicyStatement({
+      effect: iam.Effect.ALLOW,
+      actions: ['s3:RestoreObject'],
+      resources: [storageStack.originalBucket.arnForObjects('*')],
+    }));
+    storageStack.processingQueue.grantSendMessages(this.schedulerFunction);
 
     // Create EventBridge rule to run daily at 2 AM UTC
     const rule = new events.Rule(this, 'DailyCleanupRule', {
       ruleName: `photographer-gallery-daily-cleanup-${stage}`,
-      description: 'Runs daily to clean up expired galleries',
+      description: 'Runs daily to archive expired galleries',
       schedule: events.Schedule.cron({
         minute: '0',
         hour: '2',
@@ -87,6 +104,79 @@ export class SchedulerStack extends cdk.Stack {
 
     // Add Lambda as target
     rule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'cleanup-expired' }),
+      retryAttempts: 2,
+    }));
+
+    // Create EventBridge rule to relay pending outbox events every minute
+    const outboxRule = new events.Rule(this, 'OutboxRelayRule', {
+      ruleName: `photographer-gallery-outbox-relay-${stage}`,
+      description: 'Publishes pending domain events from the outbox',
+      schedule: events.Schedule.rate(cdk.Duration.minutes(1)),
+    });
+
+    outboxRule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'relay-outbox' }),
+      retryAttempts: 0,
+    }));
+
+    // Create EventBridge rule to send due webhook deliveries (new and retried) every minute
+    const webhookRetryRule = new events.Rule(this, 'WebhookRetryRule', {
+      ruleName: `photographer-gallery-webhook-retry-${stage}`,
+      description: 'Sends new webhook deliveries and retries those whose backoff has elapsed',
+      schedule: events.Schedule.rate(cdk.Duration.minutes(1)),
+    });
+
+    webhookRetryRule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'retry-webhooks' }),
+      retryAttempts: 0,
+    }));
+
+    // Create EventBridge rule to finish gallery restores once originals are out of cold storage
+    const restoreRule = new events.Rule(this, 'GalleryRestoreRule', {
+      ruleName: `photographer-gallery-restore-${stage}`,
+      description: 'Requeues rehydrated originals of galleries being restored',
+      schedule: events.Schedule.rate(cdk.Duration.hours(1)),
+    });
+
+    restoreRule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'restore-galleries' }),
+      retryAttempts: 2,
+    }));
+
+    // Create EventBridge rule to purge trashed galleries and photos daily at 3 AM UTC
+    const trashPurgeRule = new events.Rule(this, 'TrashPurgeRule', {
+      ruleName: `photographer-gallery-trash-purge-${stage}`,
+      description: 'Permanently deletes trashed galleries and photos past their retention',
+      schedule: events.Schedule.cron({
+        minute: '0',
+        hour: '3',
+        day: '*',
+        month: '*',
+        year: '*',
+      }),
+    });
+
+    trashPurgeRule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'purge-trash' }),
+      retryAttempts: 2,
+    }));
+
+    // Create EventBridge rule to recompute storage usage daily at 4 AM UTC, after the trash purge
+    const storageReconcileRule = new events.Rule(this, 'StorageReconcileRule', {
+      ruleName: `photographer-gallery-storage-reconcile-${stage}`,
+      description: 'Recomputes photographer storage usage to correct drift',
+      schedule: events.Schedule.cron({
+        minute: '0',
+        hour: '4',
+        day: '*',
+        month: '*',
+        year: '*',
+      }),
+    });
+
+    storageReconcileRule.addTarget(new targets.LambdaFunction(this.schedulerFunction, {
+      event: events.RuleTargetInput.fromObject({ task: 'reconcile-storage' }),
       retryAttempts: 2,
     }));
 
@@ -100,5 +190,10 @@ export class SchedulerStack extends cdk.Stack {
       value: rule.ruleName,
       description: 'EventBridge rule name for daily cleanup',
     });
+
+    new cdk.CfnOutput(this, 'OutboxRelayRuleName', {
+      value: outboxRule.ruleName,
+      description: 'EventBridge rule name for the outbox relay',
+    });
   }
 }
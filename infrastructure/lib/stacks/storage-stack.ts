@@ -25,6 +25,7 @@ export class StorageStack extends cdk.Stack {
   public readonly optimizedBucket: s3.Bucket;
   public readonly thumbnailBucket: s3.Bucket;
   public readonly distribution: cloudfront.Distribution;
+  public readonly processingQueue: sqs.Queue;
 
   constructor(scope: Construct, id: string, props: StorageStackProps) {
     super(scope, id, props);
@@ -193,6 +194,7 @@ export class StorageStack extends cdk.Stack {
         maxReceiveCount: 3,
       },
     });
+    this.processingQueue = processingQueue;
 
     // Lambda function for processing photos
     const processorFunction = new lambda.Function(this, 'ProcessorFunction', {
@@ -201,13 +203,15 @@ export class StorageStack extends cdk.Stack {
       code: lambda.Code.fromAsset('../backend', {
         bundling: {
           image: lambda.Runtime.PROVIDED_AL2.bundlingImage,
+          // WebP encoding links libwebp through cgo, so build natively on arm64 instead of cross-compiling
+          platform: 'linux/arm64',
           command: [
             'bash', '-c', [
-              'yum install -y golang zip',
+              'yum install -y golang gcc zip',
               'export GOPATH=/tmp/go',
               'export GOCACHE=/tmp/go-cache',
               'cd /asset-input',
-              'GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -tags lambda.norpc -o /asset-output/bootstrap cmd/processor/main.go',
+              'GOOS=linux GOARCH=arm64 CGO_ENABLED=1 go build -tags lambda.norpc -o /asset-output/bootstrap cmd/processor/main.go',
             ].join(' && '),
           ],
           user: 'root',
@@ -223,6 +227,8 @@ export class StorageStack extends cdk.Stack {
         S3_BUCKET_ORIGINAL: this.originalBucket.bucketName,
         S3_BUCKET_OPTIMIZED: this.optimizedBucket.bucketName,
         S3_BUCKET_THUMBNAIL: this.thumbnailBucket.bucketName,
+        // Gallery reprocessing jobs queue the next page of photos on the processor's own queue
+        PROCESSING_QUEUE_URL: processingQueue.queueUrl,
       },
       reservedConcurrentExecutions: 10,
       logRetention: logs.RetentionDays.ONE_WEEK, // Keep logs for 7 days
@@ -231,9 +237,15 @@ export class StorageStack extends cdk.Stack {
     // Grant permissions
     props.databaseStack.photosTable.grantReadWriteData(processorFunction);
     props.databaseStack.galleriesTable.grantReadWriteData(processorFunction);
+    // Photos are sized by their photographer's plan and watermarked with their logo, and
+    // derivatives count towards their storage usage
+    props.databaseStack.photographersTable.grantReadWriteData(processorFunction);
+    props.databaseStack.outboxTable.grantWriteData(processorFunction);
+    props.databaseStack.processingProfilesTable.grantReadData(processorFunction);
     this.originalBucket.grantRead(processorFunction);
     this.optimizedBucket.grantWrite(processorFunction);
     this.thumbnailBucket.grantWrite(processorFunction);
+    processingQueue.grantSendMessages(processorFunction);
 
     // Connect Lambda to SQS queue
     processorFunction.addEventSource(
@@ -244,11 +256,16 @@ export class StorageStack extends cdk.Stack {
     );
 
     // Add S3 event notification to trigger processing
-    // Trigger on any file created in the originals bucket (all files need processing)
-    this.originalBucket.addEventNotification(
-      s3.EventType.OBJECT_CREATED,
-      new s3n.SqsDestination(processingQueue)
-    );
+    // Trigger on uploads only: archiving and restoring galleries copy originals in place to change
+    // their storage class, and restored originals are queued for processing explicitly.
+    // Only gallery photos are processed; watermark logos share the bucket under watermarks/
+    for (const eventType of [
+      s3.EventType.OBJECT_CREATED_PUT,
+      s3.EventType.OBJECT_CREATED_POST,
+      s3.EventType.OBJECT_CREATED_COMPLETE_MULTIPART_UPLOAD,
+    ]) {
+      this.originalBucket.addEventNotification(eventType, new s3n.SqsDestination(processingQueue), { prefix: 'gal_' });
+    }
 
     // DLQ Reprocessor Lambda - handles automatic retry with exponential backoff
     const dlqReprocessorFunction = new lambda.Function(this, 'DLQReprocessorFunction', {